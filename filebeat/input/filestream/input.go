@@ -37,6 +37,7 @@ import (
 	"github.com/elastic/beats/v7/libbeat/feature"
 	"github.com/elastic/beats/v7/libbeat/reader"
 	"github.com/elastic/beats/v7/libbeat/reader/debug"
+	"github.com/elastic/beats/v7/libbeat/reader/multiline"
 	"github.com/elastic/beats/v7/libbeat/reader/parser"
 	"github.com/elastic/beats/v7/libbeat/reader/readfile"
 	"github.com/elastic/beats/v7/libbeat/reader/readfile/encoding"
@@ -700,6 +701,14 @@ func (inp *filestream) readFromSource(
 						metrics.MessagesGZIPTruncated.Add(1)
 					}
 				}
+				if slices.Contains(flags, multiline.StackTraceFlag) { //nolint:typecheck,nolintlint // linter fails to infer generics
+					metrics.StackTraces.Inc()
+					for language, counter := range metrics.StackTracesByLanguage {
+						if slices.Contains(flags, multiline.StackTraceFlag+"_"+language) { //nolint:typecheck,nolintlint // linter fails to infer generics
+							counter.Inc()
+						}
+					}
+				}
 			}
 		}
 
@@ -20,6 +20,7 @@ package input_logfile
 import (
 	"github.com/rcrowley/go-metrics"
 
+	"github.com/elastic/beats/v7/libbeat/reader/multiline"
 	"github.com/elastic/elastic-agent-libs/monitoring"
 	"github.com/elastic/elastic-agent-libs/monitoring/adapter"
 )
@@ -37,6 +38,10 @@ type Metrics struct {
 	ProcessingErrors  *monitoring.Uint // Number of processing errors.
 	ProcessingTime    metrics.Sample   // Histogram of the elapsed time for processing an event.
 
+	// Stack traces detected by the auto multiline mode
+	StackTraces           *monitoring.Uint            // Number of stack traces detected.
+	StackTracesByLanguage map[string]*monitoring.Uint // Number of stack traces detected per language.
+
 	// GZIP only metrics
 	FilesGZIPOpened       *monitoring.Uint // Number of files that have been opened.
 	FilesGZIPClosed       *monitoring.Uint // Number of files closed.
@@ -80,6 +85,9 @@ func NewMetrics(reg *monitoring.Registry) *Metrics {
 		ProcessingErrors:  monitoring.NewUint(reg, "processing_errors_total"),
 		ProcessingTime:    metrics.NewUniformSample(1024),
 
+		StackTraces:           monitoring.NewUint(reg, "stack_traces_total"),
+		StackTracesByLanguage: make(map[string]*monitoring.Uint, len(multiline.StackTraceLanguages)),
+
 		FilesGZIPOpened:       monitoring.NewUint(reg, "gzip_files_opened_total"),
 		FilesGZIPClosed:       monitoring.NewUint(reg, "gzip_files_closed_total"),
 		FilesGZIPActive:       monitoring.NewUint(reg, "gzip_files_active"),
@@ -100,6 +108,9 @@ func NewMetrics(reg *monitoring.Registry) *Metrics {
 		HarvesterGZIPRunning:   monitoring.NewInt(harvesterMetrics, "gzip_running"),
 		HarvesterOpenGZIPFiles: monitoring.NewInt(harvesterMetrics, "gzip_open_files"),
 	}
+	for _, language := range multiline.StackTraceLanguages {
+		m.StackTracesByLanguage[language] = monitoring.NewUint(reg, "stack_traces_"+language+"_total")
+	}
 	_ = adapter.NewGoMetrics(reg, "processing_time", adapter.Accept).
 		Register("histogram", metrics.NewHistogram(m.ProcessingTime))
 	_ = adapter.NewGoMetrics(reg, "gzip_processing_time", adapter.Accept).
@@ -0,0 +1,232 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package multiline
+
+import (
+	"io"
+
+	"github.com/elastic/beats/v7/libbeat/reader"
+	"github.com/elastic/beats/v7/libbeat/reader/readfile"
+	"github.com/elastic/elastic-agent-libs/logp"
+)
+
+// autoReader combines the lines of stack traces into one multi-line event
+// without requiring user provided patterns. Lines that are not part of a
+// recognised stack trace are returned as they are.
+//
+// Messages holding a stack trace are flagged with StackTraceFlag and the
+// language of the trace in log.flags.
+//
+// Errors will force the multiline reader to return the currently active
+// multiline event first and finally return the actual error on next call to Next.
+type autoReader struct {
+	reader    reader.Reader
+	detector  traceDetector
+	logger    *logp.Logger
+	msgBuffer *messageBuffer
+	state     func(*autoReader) (reader.Message, error)
+}
+
+func newMultilineAutoReader(
+	r reader.Reader,
+	separator string,
+	maxBytes int,
+	config *Config,
+	logger *logp.Logger,
+) (reader.Reader, error) {
+	maxLines := defaultMaxLines
+	if config.MaxLines != nil {
+		maxLines = *config.MaxLines
+	}
+
+	tout := defaultMultilineTimeout
+	if config.Timeout != nil {
+		tout = *config.Timeout
+	}
+
+	if tout > 0 {
+		r = readfile.NewTimeoutReader(r, sigMultilineTimeout, tout)
+	}
+
+	ar := &autoReader{
+		reader:    r,
+		msgBuffer: newMessageBuffer(maxBytes, maxLines, []byte(separator), config.SkipNewLine),
+		logger:    logger.Named("reader_multiline"),
+		state:     (*autoReader).readFirst,
+	}
+	return ar, nil
+}
+
+// Next returns next multi-line event.
+func (ar *autoReader) Next() (reader.Message, error) {
+	return ar.state(ar)
+}
+
+func (ar *autoReader) readFirst() (reader.Message, error) {
+	for {
+		message, err := ar.reader.Next()
+		if err != nil {
+			// no lines buffered -> ignore timeout
+			if err == sigMultilineTimeout {
+				continue
+			}
+
+			// pass error to caller (next layer) for handling
+			return message, err
+		}
+
+		if message.Bytes == 0 {
+			continue
+		}
+
+		// not the beginning of a stack trace, return message
+		if !ar.detector.start(message.Content) {
+			return message, nil
+		}
+
+		// Start new multiline event
+		ar.msgBuffer.startNewMessage(message)
+		ar.setState((*autoReader).readNext)
+		return ar.readNext()
+	}
+}
+
+func (ar *autoReader) readNext() (reader.Message, error) {
+	for {
+		message, err := ar.reader.Next()
+		if err != nil {
+			// handle multiline timeout signal
+			if err == sigMultilineTimeout {
+				// no lines buffered -> ignore timeout
+				if ar.msgBuffer.isEmpty() {
+					continue
+				}
+
+				ar.logger.Debug("Multiline event flushed because timeout reached.")
+
+				// return collected multiline event and
+				// empty buffer for new multiline event
+				msg := ar.finalize()
+				ar.resetState()
+				return msg, nil
+			}
+
+			// handle error without any bytes returned from reader
+			if message.Bytes == 0 {
+				// no lines buffered -> return error
+				if ar.msgBuffer.isEmpty() {
+					return reader.Message{}, err
+				}
+
+				// lines buffered, return multiline and error on next read
+				return ar.collectMessageAfterError(err)
+			}
+
+			// handle error with some content being returned by reader and
+			// line continuing the stack trace
+			if ar.detector.next(message.Content) {
+				ar.msgBuffer.addLine(message)
+
+				// return multiline and error on next read
+				return ar.collectMessageAfterError(err)
+			}
+
+			// the line is not part of the stack trace, return current
+			// multiline and the current line on next call
+			msg := ar.finalize()
+			ar.msgBuffer.load(message)
+			ar.setState((*autoReader).notMatchedMessageLoad)
+			return msg, nil
+		}
+
+		if ar.detector.next(message.Content) {
+			// add line to current multiline event
+			ar.msgBuffer.addLine(message)
+			if ar.detector.done() {
+				msg := ar.finalize()
+				ar.resetState()
+				return msg, nil
+			}
+			continue
+		}
+
+		// The line ends the current event. It may start a new stack trace,
+		// otherwise it is returned on the next call.
+		msg := ar.finalize()
+		if ar.detector.start(message.Content) {
+			ar.msgBuffer.load(message)
+			return msg, nil
+		}
+		ar.msgBuffer.load(message)
+		ar.setState((*autoReader).notMatchedMessageLoad)
+		return msg, nil
+	}
+}
+
+// finalize returns the buffered event, flagged with the detected stack trace
+// language if any.
+func (ar *autoReader) finalize() reader.Message {
+	language := ar.detector.detected()
+	msg := ar.msgBuffer.finalize()
+	if language != "" {
+		msg.AddFlagsWithKey("log.flags", StackTraceFlag, StackTraceFlag+"_"+language) //nolint:errcheck // It is safe to ignore the error.
+	}
+	ar.detector.reset()
+	return msg
+}
+
+func (ar *autoReader) collectMessageAfterError(err error) (reader.Message, error) {
+	msg := ar.finalize()
+	ar.msgBuffer.setErr(err)
+	ar.setState((*autoReader).readFailed)
+	return msg, nil
+}
+
+// readFailed returns empty message and error and resets line reader
+func (ar *autoReader) readFailed() (reader.Message, error) {
+	err := ar.msgBuffer.err
+	ar.msgBuffer.setErr(nil)
+	ar.resetState()
+	return reader.Message{}, err
+}
+
+// notMatchedMessageLoad returns not matched message from buffer
+func (ar *autoReader) notMatchedMessageLoad() (reader.Message, error) {
+	msg := ar.msgBuffer.finalize()
+	ar.resetState()
+	return msg, nil
+}
+
+// resetState sets state of the reader to readFirst
+func (ar *autoReader) resetState() {
+	ar.setState((*autoReader).readFirst)
+}
+
+// setState sets state to the given function
+func (ar *autoReader) setState(next func(ar *autoReader) (reader.Message, error)) {
+	ar.state = next
+}
+
+func (ar *autoReader) Close() error {
+	ar.setState((*autoReader).readClosed)
+	return ar.reader.Close()
+}
+
+func (ar *autoReader) readClosed() (reader.Message, error) {
+	return reader.Message{}, io.EOF
+}
@@ -40,6 +40,8 @@ func New(
 		return newMultilineCountReader(r, separator, maxBytes, config)
 	case whilePatternMode:
 		return newMultilineWhilePatternReader(r, separator, maxBytes, config, logger)
+	case autoMode:
+		return newMultilineAutoReader(r, separator, maxBytes, config, logger)
 	default:
 		return nil, fmt.Errorf("unknown multiline type %d", config.Type)
 	}
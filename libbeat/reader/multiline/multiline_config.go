@@ -31,10 +31,12 @@ const (
 	patternMode multilineType = iota
 	countMode
 	whilePatternMode
+	autoMode
 
 	patternStr      = "pattern"
 	countStr        = "count"
 	whilePatternStr = "while_pattern"
+	autoStr         = "auto"
 )
 
 var (
@@ -42,6 +44,7 @@ var (
 		patternStr:      patternMode,
 		countStr:        countMode,
 		whilePatternStr: whilePatternMode,
+		autoStr:         autoMode,
 	}
 
 	ErrMissingPattern = errors.New("multiline.pattern cannot be empty when pattern based matching is selected")
@@ -80,6 +83,8 @@ func (c *Config) Validate() error {
 		if c.Pattern == nil {
 			return ErrMissingPattern
 		}
+	} else if c.Type == autoMode {
+		// stack traces are detected without user provided patterns
 	} else {
 		return fmt.Errorf("unknown multiline type %d", c.Type)
 	}
@@ -92,6 +92,11 @@ func TestValidConfiguration(t *testing.T) {
 				"count_lines": 5,
 			},
 		},
+		"correct auto multiline": {
+			config: map[string]interface{}{
+				"type": "auto",
+			},
+		},
 	}
 
 	for name, test := range testcases {
@@ -285,6 +285,122 @@ func TestMultilineWhilePattern(t *testing.T) {
 	)
 }
 
+func TestMultilineAuto(t *testing.T) {
+	testCases := map[string]struct {
+		input    []string
+		language []string // expected language per event, empty if no stack trace
+	}{
+		"java": {
+			input: []string{
+				"2024-01-01 12:00:00 ERROR request failed\n",
+				"java.lang.IllegalStateException: boom\n" +
+					"\tat com.example.Service.run(Service.java:42)\n" +
+					"\tat java.base/java.lang.Thread.run(Thread.java:833)\n" +
+					"Caused by: java.io.IOException: closed\n" +
+					"\tat com.example.Client.read(Client.java:7)\n" +
+					"\t... 2 more\n",
+				"2024-01-01 12:00:01 INFO recovered\n",
+			},
+			language: []string{"", LanguageJava, ""},
+		},
+		"python": {
+			input: []string{
+				"Traceback (most recent call last):\n" +
+					"  File \"app.py\", line 3, in <module>\n" +
+					"    main()\n" +
+					"ValueError: invalid value\n",
+				"next line\n",
+			},
+			language: []string{LanguagePython, ""},
+		},
+		"go": {
+			input: []string{
+				"panic: runtime error: index out of range [3] with length 3\n" +
+					"\n" +
+					"goroutine 1 [running]:\n" +
+					"main.main()\n" +
+					"\t/tmp/main.go:8 +0x1d\n",
+				"exit status 2\n",
+			},
+			language: []string{LanguageGo, ""},
+		},
+		"dotnet": {
+			input: []string{
+				"System.InvalidOperationException: Operation is not valid\n" +
+					"   at Example.Program.Run() in C:\\src\\Program.cs:line 12\n" +
+					"   at Example.Program.Main(String[] args)\n",
+			},
+			language: []string{LanguageDotNet},
+		},
+		"nodejs": {
+			input: []string{
+				"TypeError: Cannot read properties of undefined (reading 'x')\n" +
+					"    at handler (/app/index.js:10:15)\n" +
+					"    at /app/node_modules/express/lib/router/layer.js:95:5\n",
+				"listening on 8080\n",
+			},
+			language: []string{LanguageNodeJS, ""},
+		},
+		"ruby": {
+			input: []string{
+				"app.rb:3:in `divide': divided by 0 (ZeroDivisionError)\n" +
+					"\tfrom app.rb:3:in `<main>'\n",
+			},
+			language: []string{LanguageRuby},
+		},
+		"single lines": {
+			input: []string{
+				"line1\n",
+				"Error: not a stack trace\n",
+				"line2\n",
+			},
+			language: []string{"", "", ""},
+		},
+		"consecutive traces": {
+			input: []string{
+				"java.lang.RuntimeException: first\n" +
+					"\tat com.example.A.a(A.java:1)\n",
+				"java.lang.RuntimeException: second\n" +
+					"\tat com.example.B.b(B.java:2)\n",
+			},
+			language: []string{LanguageJava, LanguageJava},
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			_, buf := createLineBuffer(tc.input...)
+			r := createMultilineTestReader(t, buf, Config{Type: autoMode})
+
+			var messages []reader.Message
+			for {
+				message, err := r.Next()
+				if err != nil {
+					break
+				}
+				messages = append(messages, message)
+			}
+
+			if !assert.Len(t, messages, len(tc.input)) {
+				return
+			}
+			for i, message := range messages {
+				assert.Equal(t, strings.TrimRight(tc.input[i], "\r\n "), string(message.Content))
+				assert.Equal(t, len(tc.input[i]), message.Bytes)
+
+				flags, _ := message.Fields.GetValue("log.flags")
+				if tc.language[i] == "" {
+					if flags != nil {
+						assert.NotContains(t, flags, StackTraceFlag)
+					}
+					continue
+				}
+				assert.Equal(t, []string{"multiline", StackTraceFlag, StackTraceFlag + "_" + tc.language[i]}, flags)
+			}
+		})
+	}
+}
+
 func testMultilineOK(t *testing.T, cfg Config, events int, expected ...string) {
 	_, buf := createLineBuffer(expected...)
 	r := createMultilineTestReader(t, buf, cfg)
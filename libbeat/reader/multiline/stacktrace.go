@@ -0,0 +1,221 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package multiline
+
+import (
+	"bytes"
+
+	"github.com/elastic/beats/v7/libbeat/common/match"
+)
+
+// Languages of the stack traces recognised by the auto mode.
+const (
+	LanguageJava   = "java"
+	LanguagePython = "python"
+	LanguageGo     = "go"
+	LanguageDotNet = "dotnet"
+	LanguageNodeJS = "nodejs"
+	LanguageRuby   = "ruby"
+)
+
+// StackTraceLanguages lists all languages reported by the auto mode.
+var StackTraceLanguages = []string{
+	LanguageJava,
+	LanguagePython,
+	LanguageGo,
+	LanguageDotNet,
+	LanguageNodeJS,
+	LanguageRuby,
+}
+
+// StackTraceFlag is added to log.flags of messages holding a stack trace,
+// together with StackTraceFlag + "_" + language.
+const StackTraceFlag = "stack_trace"
+
+type traceState uint8
+
+const (
+	stateNone traceState = iota
+	// Java, .NET and Node.js traces share the same shape: an exception
+	// message followed by indented "at" frames.
+	stateAfterException
+	stateAtFrames
+	statePython
+	stateGoAfterPanic
+	stateGoAfterSignal
+	stateGoGoroutine
+	stateGoFrame
+	stateGoFrameLocation
+	stateRuby
+	// stateEnd marks the last line of a trace.
+	stateEnd
+)
+
+type traceRule struct {
+	pattern match.Matcher
+	next    traceState
+}
+
+func rule(pattern string, next traceState) traceRule {
+	return traceRule{pattern: match.MustCompile(pattern), next: next}
+}
+
+// traceRules are the transitions of the stack trace state machine. The rules
+// of a state are tried in order and the first match wins. A line that matches
+// no rule of the current state ends the trace.
+var traceRules = map[traceState][]traceRule{
+	stateNone: {
+		rule(`^Traceback \(most recent call last\):$`, statePython),
+		rule(`(?:Exception|Error|Throwable|V8 errors stack trace)(?::|$)`, stateAfterException),
+		rule(`^(?:panic: |fatal error: )`, stateGoAfterPanic),
+		rule("^[^\\s:]+:\\d+:in [`'][^']*'", stateRuby),
+	},
+	stateAfterException: {
+		rule(`^[\t ]+(?:eval )?at `, stateAtFrames),
+		rule(`^[\t ]*(?:Caused by|Suppressed):`, stateAfterException),
+		rule(`^[\t ]*--- End of inner exception stack trace ---`, stateAtFrames),
+	},
+	stateAtFrames: {
+		rule(`^[\t ]+(?:eval )?at `, stateAtFrames),
+		rule(`^[\t ]*(?:Caused by|Suppressed):`, stateAfterException),
+		rule(`^[\t ]*\.\.\. \d+ (?:more|common frames omitted)`, stateAtFrames),
+		rule(`^[\t ]*--- End of (?:inner exception stack trace|stack trace from previous location)`, stateAtFrames),
+	},
+	statePython: {
+		rule(`^[\t ]+`, statePython),
+		rule(`^[^\s]`, stateEnd),
+	},
+	stateGoAfterPanic: {
+		rule(`^$`, stateGoGoroutine),
+		rule(`^\[signal `, stateGoAfterSignal),
+	},
+	stateGoAfterSignal: {
+		rule(`^$`, stateGoGoroutine),
+	},
+	stateGoGoroutine: {
+		rule(`^goroutine \d+ \[[^\]]+\]:$`, stateGoFrame),
+	},
+	stateGoFrame: {
+		rule(`^(?:[^\s.:]+\.)*[^\s.():]+\(`, stateGoFrameLocation),
+		rule(`^created by `, stateGoFrameLocation),
+		rule(`^$`, stateGoGoroutine),
+	},
+	stateGoFrameLocation: {
+		rule(`^\s`, stateGoFrame),
+	},
+	stateRuby: {
+		rule(`^[\t ]+from `, stateRuby),
+		rule("^[^\\s:]+:\\d+:in [`'][^']*'", stateRuby),
+	},
+}
+
+var (
+	javaFrame = match.MustCompile(`\.(?:java|kt|scala|groovy|clj)(?::\d+)?\)$|\((?:Native Method|Unknown Source)\)$`)
+	nodeFrame = match.MustCompile(`:\d+:\d+\)?$`)
+)
+
+// traceDetector recognises common stack trace shapes line by line.
+type traceDetector struct {
+	state    traceState
+	language string
+	frames   int // number of lines matched after the first line.
+}
+
+// start checks whether the line can be the first line of a stack trace and
+// resets the detector accordingly.
+func (d *traceDetector) start(line []byte) bool {
+	d.reset()
+	next := d.match(stateNone, line)
+	if next == stateNone {
+		return false
+	}
+	d.transition(next, line)
+	return true
+}
+
+// next checks whether the line continues the current stack trace.
+func (d *traceDetector) next(line []byte) bool {
+	if d.state == stateNone || d.state == stateEnd {
+		return false
+	}
+	next := d.match(d.state, line)
+	if next == stateNone {
+		return false
+	}
+	d.frames++
+	d.transition(next, line)
+	return true
+}
+
+// done reports whether the last line of the trace has been seen.
+func (d *traceDetector) done() bool {
+	return d.state == stateEnd
+}
+
+// detected returns the language of the stack trace, or an empty string if
+// no line following the first one was part of a trace.
+func (d *traceDetector) detected() string {
+	if d.frames == 0 {
+		return ""
+	}
+	return d.language
+}
+
+func (d *traceDetector) reset() {
+	*d = traceDetector{}
+}
+
+func (d *traceDetector) match(state traceState, line []byte) traceState {
+	line = bytes.TrimRight(line, "\r\n")
+	for _, r := range traceRules[state] {
+		if r.pattern.Match(line) {
+			return r.next
+		}
+	}
+	return stateNone
+}
+
+func (d *traceDetector) transition(next traceState, line []byte) {
+	switch next {
+	case statePython:
+		d.language = LanguagePython
+	case stateGoAfterPanic:
+		d.language = LanguageGo
+	case stateRuby:
+		d.language = LanguageRuby
+	case stateAtFrames:
+		if d.state == stateAfterException && d.language == "" {
+			d.language = atFrameLanguage(bytes.TrimRight(line, "\r\n"))
+		}
+	}
+	d.state = next
+}
+
+// atFrameLanguage tells apart the languages using "at" frames from the
+// first frame of the trace. Java frames always reference a source file or
+// native method, Node.js frames end with line and column numbers.
+func atFrameLanguage(frame []byte) string {
+	switch {
+	case javaFrame.Match(frame):
+		return LanguageJava
+	case nodeFrame.Match(frame):
+		return LanguageNodeJS
+	default:
+		return LanguageDotNet
+	}
+}
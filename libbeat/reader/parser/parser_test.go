@@ -163,6 +163,47 @@ func TestParsersConfigAndReading(t *testing.T) {
 				"[log] In total there should be 3 events\n",
 			},
 		},
+		"xml parser aggregates documents": {
+			lines: "<a>\n<b>1</b>\n</a>\n<a><b>2</b></a>\n",
+			parsers: map[string]interface{}{
+				"parsers": []map[string]interface{}{
+					{
+						"xml": map[string]interface{}{},
+					},
+				},
+			},
+			expectedMessages: []string{
+				"<a>\n\n<b>1</b>\n\n</a>\n",
+				"<a><b>2</b></a>\n",
+			},
+		},
+		"csv parser with header": {
+			lines: "user,action\nalice,login\n",
+			parsers: map[string]interface{}{
+				"parsers": []map[string]interface{}{
+					{
+						"csv": map[string]interface{}{
+							"header": true,
+						},
+					},
+				},
+			},
+			expectedMessages: []string{
+				"alice,login\n",
+			},
+		},
+		"invalid csv parser configuration is caught before parser creation": {
+			parsers: map[string]interface{}{
+				"parsers": []map[string]interface{}{
+					{
+						"csv": map[string]interface{}{
+							"separator": ";;",
+						},
+					},
+				},
+			},
+			expectedError: "separator must be a single character",
+		},
 		"non existent parser configuration": {
 			parsers: map[string]interface{}{
 				"parsers": []map[string]interface{}{
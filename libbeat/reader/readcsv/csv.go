@@ -0,0 +1,224 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package readcsv
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+
+	"github.com/elastic/beats/v7/libbeat/beat"
+	"github.com/elastic/beats/v7/libbeat/common/jsontransform"
+	"github.com/elastic/beats/v7/libbeat/reader"
+	"github.com/elastic/elastic-agent-libs/logp"
+	"github.com/elastic/elastic-agent-libs/mapstr"
+)
+
+// Parser decodes each message as a single CSV record.
+//
+// When header is enabled the first line of a file provides the column names
+// and is not returned. If reading starts past the beginning of a file, for
+// example after a restart, the header is read from the file referenced by
+// the log.file.path field of the message.
+type Parser struct {
+	reader   reader.Reader
+	cfg      *Config
+	maxBytes int
+	logger   *logp.Logger
+
+	columns []string
+}
+
+// NewParser creates a new CSV parser. maxBytes limits the size of a header
+// line read from a file.
+func NewParser(r reader.Reader, cfg *Config, maxBytes int, logger *logp.Logger) *Parser {
+	return &Parser{
+		reader:   r,
+		cfg:      cfg,
+		maxBytes: maxBytes,
+		logger:   logger.Named("parser_csv"),
+		columns:  cfg.Columns,
+	}
+}
+
+// Next reads the next message and decodes its content as CSV.
+func (p *Parser) Next() (message reader.Message, err error) {
+	// discardedOffset accounts for the bytes of the header line, so that
+	// inputs can track the file offset correctly.
+	var discardedOffset int
+	defer func() {
+		message.Offset += discardedOffset
+	}()
+
+	for {
+		message, err = p.reader.Next()
+		if err != nil {
+			return message, err
+		}
+		if len(message.Content) == 0 {
+			return message, nil
+		}
+
+		if p.cfg.Header && p.columns == nil {
+			if offset, ok := lineOffset(message); !ok || offset == 0 {
+				record, err := p.decode(message.Content)
+				if err != nil {
+					p.logger.Errorf("Error decoding CSV header: %v", err)
+					p.columns = []string{}
+					return p.withError(message, err), nil
+				}
+				p.columns = record
+				discardedOffset += message.Bytes
+				continue
+			}
+			p.columns = p.readHeader(message)
+		}
+
+		record, err := p.decode(message.Content)
+		if err != nil {
+			p.logger.Debugf("Error decoding CSV: %v", err)
+			return p.withError(message, err), nil
+		}
+		p.addFields(&message, record)
+		return message, nil
+	}
+}
+
+func (p *Parser) decode(content []byte) ([]string, error) {
+	r := csv.NewReader(bytes.NewReader(content))
+	r.Comma = p.cfg.separator()
+	r.TrimLeadingSpace = p.cfg.TrimLeadingSpace
+	// LazyQuotes makes the parser more tolerant to bad string formatting.
+	r.LazyQuotes = true
+	r.FieldsPerRecord = -1
+
+	return r.Read()
+}
+
+func (p *Parser) addFields(message *reader.Message, record []string) {
+	if len(p.columns) == 0 {
+		fields := mapstr.M{}
+		fields.Put(p.cfg.Target, record) //nolint:errcheck // An empty target is rejected by Validate in this case.
+		message.AddFields(fields)
+		return
+	}
+
+	values := make(mapstr.M, len(record))
+	for i, v := range record {
+		values[p.columnName(i)] = v
+	}
+
+	if p.cfg.Target != "" {
+		fields := mapstr.M{}
+		fields.Put(p.cfg.Target, values) //nolint:errcheck // Put cannot fail on an empty map.
+		message.AddFields(fields)
+		return
+	}
+
+	event := &beat.Event{
+		Timestamp: message.Ts,
+		Meta:      message.Meta,
+		Fields:    message.Fields,
+	}
+	if event.Fields == nil {
+		event.Fields = mapstr.M{}
+	}
+	jsontransform.WriteJSONKeys(event, values, false, p.cfg.OverwriteKeys, p.cfg.AddErrorKey)
+	message.Ts = event.Timestamp
+	message.Fields = event.Fields
+	message.Meta = event.Meta
+}
+
+// columnName returns the name of the i-th column. Values that have no
+// column name are named after their 1-based position.
+func (p *Parser) columnName(i int) string {
+	if i < len(p.columns) && p.columns[i] != "" {
+		return p.columns[i]
+	}
+	return "column" + strconv.Itoa(i+1)
+}
+
+// readHeader reads the column names from the first line of the file the
+// message was read from. On failure positional column names are used.
+func (p *Parser) readHeader(message reader.Message) []string {
+	var path string
+	if v, err := message.Fields.GetValue("log.file.path"); err == nil {
+		path, _ = v.(string)
+	}
+	if path == "" {
+		p.logger.Warn("Cannot read CSV header, the file path is unknown. Using positional column names.")
+		return []string{}
+	}
+
+	line, err := readFirstLine(path, p.maxBytes)
+	if err == nil {
+		var record []string
+		if record, err = p.decode(line); err == nil {
+			return record
+		}
+	}
+	p.logger.Warnf("Cannot read CSV header from %s, using positional column names: %v", path, err)
+	return []string{}
+}
+
+func (p *Parser) withError(message reader.Message, err error) reader.Message {
+	if p.cfg.AddErrorKey {
+		if message.Fields == nil {
+			message.Fields = mapstr.M{}
+		}
+		message.Fields.Put("error.message", "Error decoding CSV: "+err.Error()) //nolint:errcheck // Put only fails on type conflicts with existing keys.
+	}
+	return message
+}
+
+// Close closes the underlying reader.
+func (p *Parser) Close() error {
+	return p.reader.Close()
+}
+
+// lineOffset returns the log.offset of the message if set.
+func lineOffset(message reader.Message) (int64, bool) {
+	v, err := message.Fields.GetValue("log.offset")
+	if err != nil {
+		return 0, false
+	}
+	offset, ok := v.(int64)
+	return offset, ok
+}
+
+func readFirstLine(path string, maxBytes int) ([]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	r := bufio.NewReader(io.LimitReader(f, int64(maxBytes)))
+	line, err := r.ReadBytes('\n')
+	if err != nil && err != io.EOF { //nolint:errorlint // io.EOF is never wrapped by bufio.
+		return nil, err
+	}
+	if len(line) == 0 {
+		return nil, fmt.Errorf("file is empty")
+	}
+	return bytes.TrimRight(line, "\r\n"), nil
+}
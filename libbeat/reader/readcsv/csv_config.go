@@ -0,0 +1,72 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package readcsv
+
+import (
+	"errors"
+	"fmt"
+	"unicode/utf8"
+)
+
+// Config holds the options of the CSV parser.
+type Config struct {
+	// Separator is the field delimiter. It must be a single character.
+	Separator string `config:"separator"`
+	// TrimLeadingSpace ignores leading white space in a field.
+	TrimLeadingSpace bool `config:"trim_leading_space"`
+	// Columns are the names given to the decoded values, in order.
+	Columns []string `config:"columns"`
+	// Header causes the first line of each file to be used as column names.
+	Header bool `config:"header"`
+	// Target is the field the decoded values are written to. If empty the
+	// values are written to the root of the event.
+	Target string `config:"target"`
+	// OverwriteKeys allows decoded values to overwrite existing keys when
+	// writing to the root of the event.
+	OverwriteKeys bool `config:"overwrite_keys"`
+	// AddErrorKey adds decoding errors to the error.message field.
+	AddErrorKey bool `config:"add_error_key"`
+}
+
+// DefaultConfig returns a Config with default values.
+func DefaultConfig() Config {
+	return Config{
+		Separator:   ",",
+		Target:      "csv",
+		AddErrorKey: true,
+	}
+}
+
+// Validate validates the Config options of the CSV parser.
+func (c *Config) Validate() error {
+	if utf8.RuneCountInString(c.Separator) != 1 {
+		return fmt.Errorf("separator must be a single character, got %q", c.Separator)
+	}
+	if c.Header && len(c.Columns) > 0 {
+		return errors.New("header and columns cannot be used together")
+	}
+	if c.Target == "" && !c.Header && len(c.Columns) == 0 {
+		return errors.New("target cannot be empty unless column names are set with header or columns")
+	}
+	return nil
+}
+
+func (c *Config) separator() rune {
+	r, _ := utf8.DecodeRuneInString(c.Separator)
+	return r
+}
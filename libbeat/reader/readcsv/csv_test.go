@@ -0,0 +1,214 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package readcsv
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/elastic/beats/v7/libbeat/reader"
+	"github.com/elastic/elastic-agent-libs/config"
+	"github.com/elastic/elastic-agent-libs/logp/logptest"
+	"github.com/elastic/elastic-agent-libs/mapstr"
+)
+
+func TestConfig(t *testing.T) {
+	tests := map[string]struct {
+		config      map[string]interface{}
+		expectedErr string
+	}{
+		"defaults": {
+			config: map[string]interface{}{},
+		},
+		"multi character separator": {
+			config:      map[string]interface{}{"separator": ";;"},
+			expectedErr: "separator must be a single character",
+		},
+		"header and columns": {
+			config:      map[string]interface{}{"header": true, "columns": []string{"a"}},
+			expectedErr: "header and columns cannot be used together",
+		},
+		"empty target without column names": {
+			config:      map[string]interface{}{"target": ""},
+			expectedErr: "target cannot be empty",
+		},
+		"empty target with columns": {
+			config: map[string]interface{}{"target": "", "columns": []string{"a"}},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			c := DefaultConfig()
+			err := config.MustNewConfigFrom(test.config).Unpack(&c)
+			if test.expectedErr != "" {
+				require.ErrorContains(t, err, test.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestParser(t *testing.T) {
+	tests := map[string]struct {
+		config   map[string]interface{}
+		input    []reader.Message
+		expected []mapstr.M
+	}{
+		"without column names": {
+			config: map[string]interface{}{},
+			input: []reader.Message{
+				{Content: []byte(`a,"b,c",d`)},
+			},
+			expected: []mapstr.M{
+				{"csv": []string{"a", "b,c", "d"}},
+			},
+		},
+		"columns": {
+			config: map[string]interface{}{
+				"columns":            []string{"user", "action"},
+				"separator":          "|",
+				"trim_leading_space": true,
+			},
+			input: []reader.Message{
+				{Content: []byte("alice| login")},
+				{Content: []byte("bob| logout| extra")},
+			},
+			expected: []mapstr.M{
+				{"csv": mapstr.M{"user": "alice", "action": "login"}},
+				{"csv": mapstr.M{"user": "bob", "action": "logout", "column3": "extra"}},
+			},
+		},
+		"columns under root": {
+			config: map[string]interface{}{
+				"columns": []string{"user", "action"},
+				"target":  "",
+			},
+			input: []reader.Message{
+				{Content: []byte("alice,login"), Fields: mapstr.M{"log": mapstr.M{"offset": int64(0)}}},
+			},
+			expected: []mapstr.M{
+				{"user": "alice", "action": "login", "log": mapstr.M{"offset": int64(0)}},
+			},
+		},
+		"header": {
+			config: map[string]interface{}{
+				"header": true,
+			},
+			input: []reader.Message{
+				{Content: []byte("user,action"), Bytes: 12, Fields: mapstr.M{"log": mapstr.M{"offset": int64(0)}}},
+				{Content: []byte("alice,login"), Bytes: 12, Fields: mapstr.M{"log": mapstr.M{"offset": int64(12)}}},
+			},
+			expected: []mapstr.M{
+				{"csv": mapstr.M{"user": "alice", "action": "login"}, "log": mapstr.M{"offset": int64(12)}},
+			},
+		},
+		"lazy quotes": {
+			config: map[string]interface{}{},
+			input: []reader.Message{
+				{Content: []byte(`a,"b`)},
+				{Content: []byte(``)},
+			},
+			expected: []mapstr.M{
+				{"csv": []string{"a", "b"}},
+				nil,
+			},
+		},
+	}
+
+	logger := logptest.NewTestingLogger(t, "")
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			c := DefaultConfig()
+			require.NoError(t, config.MustNewConfigFrom(test.config).Unpack(&c))
+			p := NewParser(newTestReader(test.input), &c, 1024, logger)
+
+			var fields []mapstr.M
+			msg, err := p.Next()
+			for err == nil {
+				fields = append(fields, msg.Fields)
+				msg, err = p.Next()
+			}
+			require.ErrorIs(t, err, io.EOF)
+			assert.Equal(t, test.expected, fields)
+		})
+	}
+}
+
+func TestParserHeaderOffset(t *testing.T) {
+	c := DefaultConfig()
+	c.Header = true
+	p := NewParser(newTestReader([]reader.Message{
+		{Content: []byte("user,action"), Bytes: 12},
+		{Content: []byte("alice,login"), Bytes: 12},
+	}), &c, 1024, logptest.NewTestingLogger(t, ""))
+
+	msg, err := p.Next()
+	require.NoError(t, err)
+	assert.Equal(t, 12, msg.Offset, "header bytes must be reported as discarded")
+	assert.Equal(t, mapstr.M{"csv": mapstr.M{"user": "alice", "action": "login"}}, msg.Fields)
+}
+
+func TestParserHeaderFromFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "users.csv")
+	require.NoError(t, os.WriteFile(path, []byte("user,action\r\nalice,login\nbob,logout\n"), 0o600))
+
+	fields := func(offset int64) mapstr.M {
+		return mapstr.M{"log": mapstr.M{"offset": offset, "file": mapstr.M{"path": path}}}
+	}
+
+	c := DefaultConfig()
+	c.Header = true
+	p := NewParser(newTestReader([]reader.Message{
+		// Reading resumes after the first data line.
+		{Content: []byte("bob,logout"), Bytes: 11, Fields: fields(25)},
+	}), &c, 1024, logptest.NewTestingLogger(t, ""))
+
+	msg, err := p.Next()
+	require.NoError(t, err)
+	v, err := msg.Fields.GetValue("csv")
+	require.NoError(t, err)
+	assert.Equal(t, mapstr.M{"user": "bob", "action": "logout"}, v)
+}
+
+type testReader struct {
+	msgs []reader.Message
+}
+
+func newTestReader(msgs []reader.Message) reader.Reader {
+	return &testReader{msgs: msgs}
+}
+
+func (r *testReader) Next() (reader.Message, error) {
+	if len(r.msgs) == 0 {
+		return reader.Message{}, io.EOF
+	}
+	msg := r.msgs[0]
+	r.msgs = r.msgs[1:]
+	return msg, nil
+}
+
+func (r *testReader) Close() error {
+	return nil
+}
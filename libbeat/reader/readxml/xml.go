@@ -0,0 +1,212 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package readxml
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/elastic/beats/v7/libbeat/beat"
+	xmldecode "github.com/elastic/beats/v7/libbeat/common/encoding/xml"
+	"github.com/elastic/beats/v7/libbeat/common/jsontransform"
+	"github.com/elastic/beats/v7/libbeat/reader"
+	"github.com/elastic/elastic-agent-libs/logp"
+	"github.com/elastic/elastic-agent-libs/mapstr"
+)
+
+var errContentOutsideRoot = errors.New("unexpected content outside of the root element")
+
+// Parser aggregates consecutive lines into XML documents and decodes them.
+// A document is complete once its root element is closed. Lines that cannot
+// be part of a well-formed document are returned with an error annotation.
+type Parser struct {
+	reader   reader.Reader
+	cfg      *Config
+	maxBytes int
+	logger   *logp.Logger
+
+	err error // err is the read error to return after a buffered document.
+}
+
+// NewParser creates a new XML parser. maxBytes limits the size of an
+// aggregated document.
+func NewParser(r reader.Reader, cfg *Config, maxBytes int, logger *logp.Logger) *Parser {
+	return &Parser{
+		reader:   r,
+		cfg:      cfg,
+		maxBytes: maxBytes,
+		logger:   logger.Named("parser_xml"),
+	}
+}
+
+// Next returns the next XML document.
+func (p *Parser) Next() (reader.Message, error) {
+	if p.err != nil {
+		err := p.err
+		p.err = nil
+		return reader.Message{}, err
+	}
+
+	var doc reader.Message
+	var lines int
+	for {
+		message, err := p.reader.Next()
+		if err != nil {
+			if lines == 0 {
+				return message, err
+			}
+			// Return the incomplete document first, the error is returned
+			// by the following call.
+			p.err = err
+			return p.withError(doc, fmt.Errorf("incomplete document: %w", err)), nil
+		}
+
+		if lines == 0 {
+			// Blank lines between documents are passed through untouched.
+			if len(bytes.TrimSpace(message.Content)) == 0 {
+				return message, nil
+			}
+			doc = message
+			doc.Content = append([]byte(nil), message.Content...)
+		} else {
+			doc.Content = append(doc.Content, '\n')
+			doc.Content = append(doc.Content, message.Content...)
+			doc.Bytes += message.Bytes
+			doc.Offset += message.Offset
+		}
+		lines++
+
+		complete, err := scanDocument(doc.Content)
+		switch {
+		case err != nil:
+			return p.withError(doc, err), nil
+		case complete:
+			if lines > 1 {
+				doc.AddFlagsWithKey("log.flags", "multiline") //nolint:errcheck // It is safe to ignore the error.
+			}
+			return p.decode(doc), nil
+		case lines >= p.cfg.MaxLines:
+			return p.withError(doc, fmt.Errorf("document exceeds max_lines (%d)", p.cfg.MaxLines)), nil
+		case p.maxBytes > 0 && len(doc.Content) >= p.maxBytes:
+			return p.withError(doc, fmt.Errorf("document exceeds max_bytes (%d)", p.maxBytes)), nil
+		}
+	}
+}
+
+// scanDocument reports whether content holds a complete XML document. An
+// error is returned if content cannot be the beginning of a well-formed
+// document.
+func scanDocument(content []byte) (bool, error) {
+	dec := xml.NewDecoder(bytes.NewReader(content))
+	// Assume all text has already been converted to UTF-8, so ignore encoding declarations.
+	dec.CharsetReader = func(_ string, input io.Reader) (io.Reader, error) { return input, nil }
+
+	var depth int
+	for {
+		tok, err := dec.RawToken()
+		if err != nil {
+			var syntaxErr *xml.SyntaxError
+			if err == io.EOF || (errors.As(err, &syntaxErr) && syntaxErr.Msg == "unexpected EOF") { //nolint:errorlint // io.EOF is never wrapped by xml.Decoder.
+				return false, nil
+			}
+			return false, err
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			depth++
+		case xml.EndElement:
+			depth--
+			if depth == 0 {
+				return true, nil
+			}
+			if depth < 0 {
+				return false, fmt.Errorf("unexpected end element </%s>", t.Name.Local)
+			}
+		case xml.CharData:
+			if depth == 0 && len(bytes.TrimSpace(t)) > 0 {
+				return false, errContentOutsideRoot
+			}
+		}
+	}
+}
+
+func (p *Parser) decode(message reader.Message) reader.Message {
+	dec := xmldecode.NewDecoder(bytes.NewReader(message.Content))
+	if p.cfg.ToLower {
+		dec.LowercaseKeys()
+	}
+	out, err := dec.Decode()
+	if err != nil {
+		return p.withError(message, err)
+	}
+	doc := mapstr.M(out)
+
+	if key := p.cfg.DocumentID; key != "" {
+		if tmp, err := doc.GetValue(key); err == nil {
+			if id, ok := tmp.(string); ok {
+				doc.Delete(key) //nolint:errcheck // The key is known to exist.
+
+				if message.Meta == nil {
+					message.Meta = mapstr.M{}
+				}
+				message.Meta["_id"] = id
+			}
+		}
+	}
+
+	if p.cfg.Target != "" {
+		fields := mapstr.M{}
+		fields.Put(p.cfg.Target, doc) //nolint:errcheck // Put cannot fail on an empty map.
+		message.AddFields(fields)
+		return message
+	}
+
+	event := &beat.Event{
+		Timestamp: message.Ts,
+		Meta:      message.Meta,
+		Fields:    message.Fields,
+	}
+	if event.Fields == nil {
+		event.Fields = mapstr.M{}
+	}
+	jsontransform.WriteJSONKeys(event, doc, false, p.cfg.OverwriteKeys, p.cfg.AddErrorKey)
+	message.Ts = event.Timestamp
+	message.Fields = event.Fields
+	message.Meta = event.Meta
+	return message
+}
+
+func (p *Parser) withError(message reader.Message, err error) reader.Message {
+	p.logger.Debugf("Error decoding XML: %v", err)
+	if p.cfg.AddErrorKey {
+		if message.Fields == nil {
+			message.Fields = mapstr.M{}
+		}
+		message.Fields.Put("error.message", "Error decoding XML: "+err.Error()) //nolint:errcheck // Put only fails on type conflicts with existing keys.
+	}
+	return message
+}
+
+// Close closes the underlying reader.
+func (p *Parser) Close() error {
+	return p.reader.Close()
+}
@@ -0,0 +1,46 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package readxml
+
+// Config holds the options of the XML parser.
+type Config struct {
+	// Target is the field the decoded document is written to. If empty the
+	// document is written to the root of the event.
+	Target string `config:"target"`
+	// ToLower converts all keys to lowercase.
+	ToLower bool `config:"to_lower"`
+	// DocumentID is the key of the decoded document whose value is used as
+	// the event ID.
+	DocumentID string `config:"document_id"`
+	// MaxLines is the maximum number of lines aggregated into a document.
+	MaxLines int `config:"max_lines" validate:"min=1"`
+	// OverwriteKeys allows decoded values to overwrite existing keys when
+	// writing to the root of the event.
+	OverwriteKeys bool `config:"overwrite_keys"`
+	// AddErrorKey adds decoding errors to the error.message field.
+	AddErrorKey bool `config:"add_error_key"`
+}
+
+// DefaultConfig returns a Config with default values.
+func DefaultConfig() Config {
+	return Config{
+		Target:      "xml",
+		MaxLines:    500,
+		AddErrorKey: true,
+	}
+}
@@ -0,0 +1,182 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package readxml
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/elastic/beats/v7/libbeat/reader"
+	"github.com/elastic/elastic-agent-libs/config"
+	"github.com/elastic/elastic-agent-libs/logp/logptest"
+	"github.com/elastic/elastic-agent-libs/mapstr"
+)
+
+func TestParser(t *testing.T) {
+	tests := map[string]struct {
+		config           map[string]interface{}
+		lines            string
+		expectedContents []string
+		expectedFields   []mapstr.M
+	}{
+		"single line documents": {
+			config: map[string]interface{}{},
+			lines:  "<a><b>1</b></a>\n<a><b>2</b></a>",
+			expectedContents: []string{
+				"<a><b>1</b></a>",
+				"<a><b>2</b></a>",
+			},
+			expectedFields: []mapstr.M{
+				{"xml": mapstr.M{"a": map[string]interface{}{"b": "1"}}},
+				{"xml": mapstr.M{"a": map[string]interface{}{"b": "2"}}},
+			},
+		},
+		"multi-line documents": {
+			config: map[string]interface{}{"to_lower": true},
+			lines: `<?xml version="1.0" encoding="UTF-8"?>
+<Order id="1">
+  <!-- comment with </Order> -->
+  <Item><![CDATA[x > y]]></Item>
+</Order>
+
+<Order id="2"
+  status="open">
+</Order>`,
+			expectedContents: []string{
+				"<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n<Order id=\"1\">\n  <!-- comment with </Order> -->\n  <Item><![CDATA[x > y]]></Item>\n</Order>",
+				"",
+				"<Order id=\"2\"\n  status=\"open\">\n</Order>",
+			},
+			expectedFields: []mapstr.M{
+				{
+					"xml": mapstr.M{"order": map[string]interface{}{"id": "1", "item": "x > y"}},
+					"log": mapstr.M{"flags": []string{"multiline"}},
+				},
+				nil,
+				{
+					"xml": mapstr.M{"order": map[string]interface{}{"id": "2", "status": "open"}},
+					"log": mapstr.M{"flags": []string{"multiline"}},
+				},
+			},
+		},
+		"target root with document id": {
+			config: map[string]interface{}{"target": "", "document_id": "event.id"},
+			lines:  `<event><id>abc</id><name>x</name></event>`,
+			expectedContents: []string{
+				`<event><id>abc</id><name>x</name></event>`,
+			},
+			expectedFields: []mapstr.M{
+				{"event": mapstr.M{"name": "x"}},
+			},
+		},
+		"content outside of root": {
+			config: map[string]interface{}{},
+			lines:  "not xml\n<a>1</a>",
+			expectedContents: []string{
+				"not xml",
+				"<a>1</a>",
+			},
+			expectedFields: []mapstr.M{
+				{"error": mapstr.M{"message": "Error decoding XML: unexpected content outside of the root element"}},
+				{"xml": mapstr.M{"a": "1"}},
+			},
+		},
+		"max lines": {
+			config: map[string]interface{}{"max_lines": 2},
+			lines:  "<a>\n<b/>\n<c/>\n</a>",
+			expectedContents: []string{
+				"<a>\n<b/>",
+				"<c/>",
+				"</a>",
+			},
+			expectedFields: []mapstr.M{
+				{"error": mapstr.M{"message": "Error decoding XML: document exceeds max_lines (2)"}},
+				{"xml": mapstr.M{"c": ""}},
+				{"error": mapstr.M{"message": "Error decoding XML: unexpected end element </a>"}},
+			},
+		},
+		"incomplete document at end of input": {
+			config: map[string]interface{}{},
+			lines:  "<a>\n<b>1</b>",
+			expectedContents: []string{
+				"<a>\n<b>1</b>",
+			},
+			expectedFields: []mapstr.M{
+				{"error": mapstr.M{"message": "Error decoding XML: incomplete document: EOF"}},
+			},
+		},
+	}
+
+	logger := logptest.NewTestingLogger(t, "")
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			c := DefaultConfig()
+			require.NoError(t, config.MustNewConfigFrom(test.config).Unpack(&c))
+			p := NewParser(newTestReader(test.lines), &c, 1024, logger)
+
+			var contents []string
+			var fields []mapstr.M
+			msg, err := p.Next()
+			for err == nil {
+				contents = append(contents, string(msg.Content))
+				fields = append(fields, msg.Fields)
+				msg, err = p.Next()
+			}
+			require.ErrorIs(t, err, io.EOF)
+			assert.Equal(t, test.expectedContents, contents)
+			assert.Equal(t, test.expectedFields, fields)
+		})
+	}
+}
+
+func TestParserDocumentID(t *testing.T) {
+	c := DefaultConfig()
+	c.DocumentID = "event.id"
+	p := NewParser(newTestReader(`<event><id>abc</id></event>`), &c, 1024, logptest.NewTestingLogger(t, ""))
+
+	msg, err := p.Next()
+	require.NoError(t, err)
+	assert.Equal(t, mapstr.M{"_id": "abc"}, msg.Meta)
+	assert.Equal(t, mapstr.M{"xml": mapstr.M{"event": map[string]interface{}{}}}, msg.Fields)
+}
+
+// testReader returns one message per line, without the line terminator.
+type testReader struct {
+	lines []string
+}
+
+func newTestReader(lines string) reader.Reader {
+	return &testReader{lines: strings.Split(lines, "\n")}
+}
+
+func (r *testReader) Next() (reader.Message, error) {
+	if len(r.lines) == 0 {
+		return reader.Message{}, io.EOF
+	}
+	line := r.lines[0]
+	r.lines = r.lines[1:]
+	return reader.Message{Content: []byte(line), Bytes: len(line) + 1}, nil
+}
+
+func (r *testReader) Close() error {
+	return nil
+}
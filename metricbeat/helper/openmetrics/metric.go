@@ -270,6 +270,11 @@ func (m *commonMetric) GetValue(metric *prometheus.OpenMetric) interface{} {
 	}
 
 	histogram := metric.GetHistogram()
+	if histogram == nil {
+		// Native histograms are reported as classic ones, with a cumulative
+		// bucket at the upper bound of each native bucket.
+		histogram = metric.GetNativeHistogram().ToHistogram()
+	}
 	if histogram != nil {
 		value := mapstr.M{}
 		if !math.IsNaN(histogram.GetSampleSum()) && !math.IsInf(histogram.GetSampleSum(), 0) {
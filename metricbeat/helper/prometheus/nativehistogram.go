@@ -0,0 +1,121 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package prometheus
+
+import (
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/model/histogram"
+)
+
+const (
+	// ProtobufType is the media type of the protobuf exposition format.
+	ProtobufType = `application/vnd.google.protobuf`
+	// ContentTypeProtobuf is the Content-Type of the protobuf exposition format,
+	// the only one that supports native histograms.
+	ContentTypeProtobuf = ProtobufType + `; proto=io.prometheus.client.MetricFamily; encoding=delimited`
+
+	// ProtobufAcceptHeader negotiates the protobuf exposition format, falling
+	// back to the text format for the endpoints that do not support it.
+	ProtobufAcceptHeader = ProtobufType + `;proto=io.prometheus.client.MetricFamily;encoding=delimited;q=0.6,` + acceptHeader
+)
+
+// NativeHistogram is a Prometheus native histogram. Native histograms have
+// sparse buckets of exponentially growing width instead of the fixed buckets of
+// classic histograms, and are only exposed in the protobuf format.
+type NativeHistogram struct {
+	model.SampleHistogram
+	IsGaugeHistogram bool
+}
+
+func (m *NativeHistogram) GetSampleCount() float64 {
+	if m != nil {
+		return float64(m.Count)
+	}
+	return 0
+}
+
+func (m *NativeHistogram) GetSampleSum() float64 {
+	if m != nil {
+		return float64(m.Sum)
+	}
+	return 0
+}
+
+// ToHistogram returns the native histogram as a classic histogram, with a
+// cumulative bucket at the upper bound of each native bucket.
+func (m *NativeHistogram) ToHistogram() *Histogram {
+	if m == nil {
+		return nil
+	}
+
+	count, sum := m.GetSampleCount(), m.GetSampleSum()
+	h := &Histogram{
+		SampleCount:      &count,
+		SampleSum:        &sum,
+		IsGaugeHistogram: m.IsGaugeHistogram,
+	}
+	var cumulative float64
+	for _, b := range m.Buckets {
+		cumulative += float64(b.Count)
+		upper, count := float64(b.Upper), cumulative
+		h.Bucket = append(h.Bucket, &Bucket{
+			UpperBound:      &upper,
+			CumulativeCount: &count,
+		})
+	}
+	return h
+}
+
+// ToSampleHistogram converts a histogram as decoded from the protobuf format
+// or remote write requests to a sample histogram with its non-empty buckets
+// in ascending order.
+func ToSampleHistogram(fh *histogram.FloatHistogram) *model.SampleHistogram {
+	sh := &model.SampleHistogram{
+		Count: model.FloatString(fh.Count),
+		Sum:   model.FloatString(fh.Sum),
+	}
+	it := fh.AllBucketIterator()
+	for it.Next() {
+		b := it.At()
+		if b.Count == 0 {
+			continue
+		}
+		sh.Buckets = append(sh.Buckets, &model.HistogramBucket{
+			Boundaries: boundaries(b),
+			Lower:      model.FloatString(b.Lower),
+			Upper:      model.FloatString(b.Upper),
+			Count:      model.FloatString(b.Count),
+		})
+	}
+	return sh
+}
+
+// boundaries returns the boundaries rule of a bucket as defined in
+// model.HistogramBucket.
+func boundaries(b histogram.Bucket[float64]) int32 {
+	switch {
+	case b.LowerInclusive && b.UpperInclusive:
+		return 3
+	case b.LowerInclusive:
+		return 1
+	case b.UpperInclusive:
+		return 0
+	default:
+		return 2
+	}
+}
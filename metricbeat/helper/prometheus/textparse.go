@@ -29,6 +29,7 @@ import (
 
 	"github.com/prometheus/common/model"
 	"github.com/prometheus/prometheus/model/exemplar"
+	"github.com/prometheus/prometheus/model/histogram"
 	"github.com/prometheus/prometheus/model/labels"
 	"github.com/prometheus/prometheus/model/textparse"
 	"github.com/prometheus/prometheus/model/timestamp"
@@ -202,17 +203,18 @@ func (m *Histogram) GetBucket() []*Bucket {
 }
 
 type OpenMetric struct {
-	Label       []*labels.Label
-	Exemplar    *exemplar.Exemplar
-	Name        *string
-	Gauge       *Gauge
-	Counter     *Counter
-	Info        *Info
-	Stateset    *Stateset
-	Summary     *Summary
-	Unknown     *Unknown
-	Histogram   *Histogram
-	TimestampMs *int64
+	Label           []*labels.Label
+	Exemplar        *exemplar.Exemplar
+	Name            *string
+	Gauge           *Gauge
+	Counter         *Counter
+	Info            *Info
+	Stateset        *Stateset
+	Summary         *Summary
+	Unknown         *Unknown
+	Histogram       *Histogram
+	NativeHistogram *NativeHistogram
+	TimestampMs     *int64
 }
 
 func (m *OpenMetric) GetName() *string {
@@ -285,6 +287,20 @@ func (m *OpenMetric) GetGaugeHistogram() *Histogram {
 	return nil
 }
 
+func (m *OpenMetric) GetNativeHistogram() *NativeHistogram {
+	if m != nil && m.NativeHistogram != nil && !m.NativeHistogram.IsGaugeHistogram {
+		return m.NativeHistogram
+	}
+	return nil
+}
+
+func (m *OpenMetric) GetGaugeNativeHistogram() *NativeHistogram {
+	if m != nil && m.NativeHistogram != nil && m.NativeHistogram.IsGaugeHistogram {
+		return m.NativeHistogram
+	}
+	return nil
+}
+
 func (m *OpenMetric) GetTimestampMs() int64 {
 	if m != nil && m.TimestampMs != nil {
 		return *m.TimestampMs
@@ -557,6 +573,21 @@ func ParseMetricFamilies(b []byte, contentType string, ts time.Time, logger *log
 			continue
 		case textparse.EntryComment:
 			continue
+		case textparse.EntryHistogram:
+			metricName, metric := nativeHistogramMetric(parser)
+			if metric == nil {
+				continue
+			}
+			fam, ok = metricFamiliesByName[metricName]
+			if !ok {
+				fam = &MetricFamily{Name: &metricName, Type: model.MetricTypeHistogram}
+				metricFamiliesByName[metricName] = fam
+			}
+			if fam.Type == model.MetricTypeGaugeHistogram {
+				metric.NativeHistogram.IsGaugeHistogram = true
+			}
+			fam.Metric = append(fam.Metric, metric)
+			continue
 		default:
 		}
 
@@ -741,6 +772,46 @@ func ParseMetricFamilies(b []byte, contentType string, ts time.Time, logger *log
 	return families, nil
 }
 
+// nativeHistogramMetric returns the metric name and the native histogram of
+// the current entry of the parser.
+func nativeHistogramMetric(parser textparse.Parser) (string, *OpenMetric) {
+	_, tp, h, fh := parser.Histogram()
+	if h != nil {
+		fh = h.ToFloat(nil)
+	}
+	if fh == nil {
+		return "", nil
+	}
+
+	var lset labels.Labels
+	parser.Labels(&lset)
+	if !lset.Has(labels.MetricName) {
+		return "", nil
+	}
+
+	var labelPairs = []*labels.Label{}
+	lset.Range(func(l labels.Label) {
+		if l.Name != labels.MetricName {
+			labelPairs = append(labelPairs, &labels.Label{Name: l.Name, Value: l.Value})
+		}
+	})
+
+	metricName := lset.Get(labels.MetricName)
+	metric := &OpenMetric{
+		Name:  &metricName,
+		Label: labelPairs,
+		NativeHistogram: &NativeHistogram{
+			SampleHistogram:  *ToSampleHistogram(fh),
+			IsGaugeHistogram: fh.CounterResetHint == histogram.GaugeType,
+		},
+	}
+	if tp != nil {
+		t := *tp
+		metric.TimestampMs = &t
+	}
+	return metricName, metric
+}
+
 func GetContentType(h http.Header) string {
 	ct := h.Get(hdrContentType)
 
@@ -758,6 +829,12 @@ func GetContentType(h http.Header) string {
 		}
 		return OpenMetricsType
 
+	case ProtobufType:
+		if params["proto"] != "io.prometheus.client.MetricFamily" || params["encoding"] != "delimited" {
+			return ""
+		}
+		return ContentTypeProtobuf
+
 	case textType:
 		if v, ok := params["version"]; ok && v != TextVersion {
 			return ""
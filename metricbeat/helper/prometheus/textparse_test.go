@@ -18,9 +18,12 @@
 package prometheus
 
 import (
+	"net/http"
+	"os"
 	"testing"
 	"time"
 
+	"github.com/prometheus/common/model"
 	"github.com/prometheus/prometheus/model/labels"
 	"github.com/stretchr/testify/require"
 
@@ -895,3 +898,73 @@ redis_connected_clients{instance="rough-snowflake-web"} 10.0`
 	}
 	require.ElementsMatch(t, expected, result)
 }
+
+func TestNativeHistogramProtobuf(t *testing.T) {
+	input, err := os.ReadFile("testdata/native-histogram.pb")
+	require.NoError(t, err)
+
+	contentType := GetContentType(http.Header{"Content-Type": []string{ContentTypeProtobuf}})
+	require.Equal(t, ContentTypeProtobuf, contentType)
+
+	result, err := ParseMetricFamilies(input, contentType, time.Now(), nil)
+	require.NoError(t, err)
+	require.Len(t, result, 2)
+
+	// The families aren't returned in a stable order.
+	families := make(map[string]*MetricFamily, len(result))
+	for _, family := range result {
+		families[family.GetName()] = family
+	}
+
+	family := families["http_request_duration_seconds"]
+	require.NotNil(t, family)
+	require.Equal(t, model.MetricTypeHistogram, family.Type)
+	require.Len(t, family.Metric, 1)
+
+	metric := family.Metric[0]
+	require.Equal(t, []*labels.Label{{Name: "handler", Value: "/metrics"}}, metric.Label)
+	require.Nil(t, metric.GetHistogram())
+	require.Nil(t, metric.GetGaugeNativeHistogram())
+
+	nh := metric.GetNativeHistogram()
+	require.NotNil(t, nh)
+	require.Equal(t, 6.0, nh.GetSampleCount())
+	require.Equal(t, 4.5, nh.GetSampleSum())
+	require.Equal(t, model.HistogramBuckets{
+		{Boundaries: 3, Lower: -0.001, Upper: 0.001, Count: 1},
+		{Boundaries: 0, Lower: 0.5, Upper: 1, Count: 2},
+		{Boundaries: 0, Lower: 1, Upper: 2, Count: 1},
+		{Boundaries: 0, Lower: 4, Upper: 8, Count: 2},
+	}, nh.Buckets)
+
+	expected := &Histogram{
+		SampleCount: float64p(6),
+		SampleSum:   float64p(4.5),
+		Bucket: []*Bucket{
+			{UpperBound: float64p(0.001), CumulativeCount: float64p(1)},
+			{UpperBound: float64p(1), CumulativeCount: float64p(3)},
+			{UpperBound: float64p(2), CumulativeCount: float64p(4)},
+			{UpperBound: float64p(8), CumulativeCount: float64p(6)},
+		},
+	}
+	require.Equal(t, expected, nh.ToHistogram())
+
+	up := families["up"]
+	require.NotNil(t, up)
+	require.Equal(t, 1.0, up.Metric[0].GetGauge().GetValue())
+}
+
+func TestGetContentTypeProtobuf(t *testing.T) {
+	tests := map[string]string{
+		ContentTypeProtobuf: ContentTypeProtobuf,
+		ProtobufType + ";proto=io.prometheus.client.MetricFamily;encoding=delimited": ContentTypeProtobuf,
+		ProtobufType + "; proto=io.prometheus.client.MetricFamily; encoding=text":    "",
+		ProtobufType: "",
+	}
+	for header, expected := range tests {
+		t.Run(header, func(t *testing.T) {
+			contentType := GetContentType(http.Header{"Content-Type": []string{header}})
+			require.Equal(t, expected, contentType)
+		})
+	}
+}
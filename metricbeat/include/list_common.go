@@ -142,7 +142,10 @@ import (
 	_ "github.com/elastic/beats/v7/metricbeat/module/postgresql/activity"
 	_ "github.com/elastic/beats/v7/metricbeat/module/postgresql/bgwriter"
 	_ "github.com/elastic/beats/v7/metricbeat/module/postgresql/database"
+	_ "github.com/elastic/beats/v7/metricbeat/module/postgresql/locks"
+	_ "github.com/elastic/beats/v7/metricbeat/module/postgresql/replication"
 	_ "github.com/elastic/beats/v7/metricbeat/module/postgresql/statement"
+	_ "github.com/elastic/beats/v7/metricbeat/module/postgresql/vacuum"
 	_ "github.com/elastic/beats/v7/metricbeat/module/prometheus"
 	_ "github.com/elastic/beats/v7/metricbeat/module/prometheus/collector"
 	_ "github.com/elastic/beats/v7/metricbeat/module/prometheus/query"
@@ -154,6 +157,7 @@ import (
 	_ "github.com/elastic/beats/v7/metricbeat/module/rabbitmq/queue"
 	_ "github.com/elastic/beats/v7/metricbeat/module/rabbitmq/shovel"
 	_ "github.com/elastic/beats/v7/metricbeat/module/redis"
+	_ "github.com/elastic/beats/v7/metricbeat/module/redis/cluster"
 	_ "github.com/elastic/beats/v7/metricbeat/module/redis/info"
 	_ "github.com/elastic/beats/v7/metricbeat/module/redis/key"
 	_ "github.com/elastic/beats/v7/metricbeat/module/redis/keyspace"
@@ -0,0 +1 @@
+SELECT pg_create_physical_replication_slot('metricbeat_test', true);
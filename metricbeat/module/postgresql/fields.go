@@ -32,5 +32,5 @@ func init() {
 // AssetPostgresql returns asset data.
 // This is the base64 encoded zlib format compressed contents of module/postgresql.
 func AssetPostgresql() string {
-	return "eJzUWk+P47oNv8+nIN7l7RbZoL3OoUCxr0AX6O7bh92ix4CR6ViILHklOZn00xeU5D+xncwkY8+2Oc0kFvkTSZE/Uv4Aezo9QmWc31lyP9QDgJde0SP88jV++e2Pf/7yAJCRE1ZWXhr9CH99AAD4TN5K4UAYpUh4yiC3poRuHTiyB7Ju/QDgCmP9Rhidy90j5KgcPQBYUoSOHmGHDwC5JJW5xyD8A2gsaQCNP/5U8fPW1FX6ZgIaf3o4yoh0nX7r6+nrQuHlQfpT+8OUtisa+fO7JsiMqEvSHiqyyQZQWSPIuRUb4ij1DqTOjS2RZbAZkO3nDfiCQNTWkvZnchtsYHLwBfqewFoUgA6cR0+AOmvWw4+a7GkNH1v/bE9nMsPvjKXabXj1plGy7j127qLmMzRh34wZetyio7WR2dkDjTmV0bvBD1csGqz66be4cWqlgy+kgy2KPekMJIeh1nGb3qyvA+N/J5Ht6XQ0doj6GXBfsKQZ0FWzWetrDA1ojNYhmdZcO7LrJXzFgkGZ3Y4ykDpE94uwTPjnBh/coRWrSkkRDuPmdcp7kuI5Hfj+BWCEkqT9GrPMknO3Qfn0FdK6BlCUdieGwjh/uz3+YZwPcloMrfIod8X5ylJlbMxKgGCJKwXBb1++gTJmX1e8OD6+4S1dxcmSZgrf7x+/AosDXZdbstGJPUNKB7XjpJkbC8KUZa0bfx+lL4JtR0KTrVdgLHz4C8gcEP6l5RM4I/aUhNIFX6TFG97RjXs5VZ0PUlFo3M710cmtomApB2gJsPbmgKKuS1BYa1GQXfW/PBq7J7sa6VFmJwUqdmkb/J2AqV+TJKjQolKk2i8YHpdbPUxHAEcrPT+SHNHaVBQk9pWROvzqPFpfVys4orIkSB742yMTDp2RDQXyiCoKW4+U/P3Jk3bSaAclnsDSTjpPNuFz0ceYZZK3gapN8cGI1/0XkE1GaYb+Vs/KkuBYkI5nOZEBOEYewMdqBXJN61Xz0GQiGInl5yJhmd6Kt6gdswSj32A7v7ZB29Pb3+M0yEBrFoPXniR1iqSMosJz2xvLhzxSMulAmyGUxOio5yCFzo9lTe8xSN6IAvVumsm8dpMROuMIsKKmC2COKL0c5dmIY2uMItQ3QrE1sf1GJKq1fFIJRgOCMmJ/xUy36f6YQs4ciFNTMsSQR3XZ84CqjumzY8MjoQB/Sv5+hO8F9fdETyTqsBdMhH1ytczUeG1jBS5FCJqO3SEvSxzW9r4okLp/qEaSJdu198AKtrW/FMn86Vxzw4YGKOAdbgMleM94pOvOj5OlVGiZu6R1kyDOANOToMqD0W0JDOK4MQv742/6ygVyCZ6UixrIWjNRLngnOTpfoS8gr3UjSqmrjuYlH87WTIvOpMOtomxoj5Y78SGxKPZN6ybJ8e/NurjP58he8NKNJ5Se/PBQ/OqgZObHVbfrPj/10mDKl2FR6CBHcrk7doMs2xmuEamBT6bxBffXLNytQPpu8ZigdKk18DnOa1HstZy2ocOwDX/WMP9G6SGsiwxYxtC7lsSeA3AP8SsirWMXRTCcIo6FFMUQzghES1t2kSO9ZhryzaOXzkvhALem9q3ySPESpWvrvWvHHP2pRaTbQ7c2M4sG5jjX3D656JikWztRUFarUUa4t6/4EtsJk0Mrua8vxmWBB4ItkYaKbG5seSk8+0gt/ajJ+QWQtpJnQuplSW4d/LUuh11ShJsrgzceue/GowIsTa1jTmIik0C6iNFVoXWLSZ9TJ2dp7i9bcCOpKSY59I4FWYJcqtQm8QY88yTDiXa/YsGlVEo6EkZnF9qAsSHcSYv/Zzsw/sIaLf8Txwo3GGNb5zlZt+4ZZfboTTpad2W15V30/XAd2wRxnR/V9jSdFF+AbZPXSs0OMMTmhUTtvKkqygAhAGBzOoEathTYE8hx/BSY9Q6MgRL1qd3G1T2mIrV8XEhLgutxGERdZUoDaJucj8BCHmihBBN60xAWkN6BOWoIygPXhHfa2BKVGrI4uODHAnWmgpeNo8AQus6v0ZoZ5pJR1/Qs5v1VG6FSRuASZalxYKvhcvPnNpYczToHYPrYECkXm4pEcY6cHgPVDErHlKq5HXgNpfpdE1hzDHdLjbzuVqn55sNRZn1s57dA7c3PJKOaQHk7lfp5dz9h+PLnQHhdgZYy9oaprbg0n/vJt0EroLLyp1sAh5OwMfkmiVygdibBvW5lfIPQ3qc9Nyt0a2HKclQcZsiUPR1tr9uz+hlRjRguposzvNYoxTb4uYgZBR9YvDTf2ioj9twB4PxZltlcUgCsYIT2KqRiCW+HutjHFTJuHiobKgZ5ashtrBEgUBScHqdG3ejDvRPzEwyDLdDEVBftCd6FnRqtWKBQdUYOCtkNjrqXC0aCzzWzWF5gKrIYhhju5DyVv7pApNN/8en3Vy3Kuw+evtQyZKbeqnsqWuwKWHRTQiK2ZOTtqUsHwxhYTY3iXkD/e1u62g6+ck8s+632ZM2RT6KvrV6iFTdHPoZRelPCw7TvRacygMvJi2IpbEn4ndCkdmQXGWEwtkb6neDqKluExwZsSfid0DJStBi0JPx2aMLoXEmxQE/f4BCoBXFtzGpiPtJqjBe0loQ5kD01eEcSn6EuVFbGoj2tw7Bj/kLWyE/DFGHp2RiAv416fRgJQksgTK3DHaSlHVpu88K7IMciDhrOl3DpG0lt4Lyj9W7NxdPGuzbuG10h9e79KlyjnysIV5hmt2EFmym7ATjylwfendG3Jz+b0YdDsVAJegO8oQXHLrgcO+ySO1zwjBcDG0kuWMLOGWEWiuD8jLGRDBn52Cq8LF/873Tqo5a9vTN8bc9+9lJovIriv8LLatO9/MQbomdSpT6Y9DpN81JokNu9EiqqGmqHu/haqA9HIXCuG94J7S5NX3e38pYvGXZDrGAVi5G0T9xGv/k7q/23RWytYXjze3YnO5+9OiytP6/p9fR04+XDH/FydbjuXKxApRaopu3AOpq2ve2w9VXjMseZtcro62z9Qugli/OxDCd09huhDla/iWm6vlrr7mr9OYCl1DPC+yy1LOtyVoD4NCdAfJodIOFFE94ed58J9ZzonM8yOsyH76upahVLlPOoM7QZZHSQXdXqzR/6SF94jRihl1Qae1rH4emMk6fh8UnT2TBCiBObOBNKV3jPmvgc54xDuxcADXOu+4Bm0no5W4/5AqxJ4Z1wE7l/O7iDm9yXwlVGoFowWoP8VwdrRLlgrI5h3hOqEeaykTpGemegRrDLxukY7J1hym3mkv5n+a92fwC5rEFHOKft+d8AAAD//5VSM/M="
+	return "eJzUXV+z27aOfz+fAnNf2u44mntn3/KwM5m2O9uZtMm9Sfc+OrQEW9xDkSpJHR/30++AfyRakuV/kpNOzkOOLQE/gCAIgCDPG3jGw1uolbE7jeYP8QRguRX4Fv720X/46Z/v//YEUKDJNa8tV/It/NcTAMCvaDXPDeRKCMwtFrDVqoLuPTCoX1Cb7AnAlErbda7klu/ewpYJg08AGgUyg29hx54AthxFYd464m9Asgp70OgLe6jpea2aOnwyAo1+EhyVR5qF71I+KS+WW/7C7aH9YozbBEf6+SARCpU3FUoLNeqgA6i1ytGYFSliz+UOuNwqXTFSKKmBkf6sAlsi5I3WKO0R3YgN1BZsyWxCsMlLYAaMZRaBySK+D380qA8Z/NiOzyYVDfz3hKXerentdWQSFQXQHyKAcRWmaiyYZRtmMFO8OHogqlMouet9MaFR+vnwy09ecGypgy25gQ3Ln1EWwMkMpfRmaFU2DYx+7fHwyJ7xsFe6uA7cb6zCGdDVs2nrozcNiErrkIxzbgzqbImxIsIg1G6HBXBp1aVYRsbnijG4gSura8FzNxnX9zFPKPl52hv7C8DkgqO0GSsKjcZcB+WXjxDei4A8tRsxlMrY6/XxP8pYkIlSOuae7or8lcZaafpscwAGGmmlQPjpt08glHpuahLAP74mkSZxEqWZzPfzjx+ByIFsqg1qP4iJIrmBxpDT3CoNuaqqRsbx3nNbuvEdEA26XoHS8OYfwLfA4HfJX8Go/BkDUTwxFuHlNbmoK2U51N0YhEUhUMtonTZ8I9BpygDTCKyx6oXlTVOBYI3MS9Sr9MO90s+oVwM+Qu14zgRo7Iy/IzD2baAENdNMCBTtBwSPllvZd0cAe80tvRMGIgiygrzE/LlWXLpvjWXaNvUK9kxozJG/0Kd7CjhkgdotkHsmPLFjhdO/n18tSsOVNFCxA2jccWNRB3zGjzErCk46ZyLOIq/E6fFzyHoM6TW3MF07srxC2Jconb3FYAD2Pg6gabUCnmG2ig+NOoIBWXrOByzjoljNpKEoQckHiPNda7QJ31TGcZAurFkMXjuTxAFIEy/o46hj3StNk5yCKqTJLVUfSojoMBkgwYwd0hqX0VFe5yWTO1xESMfAyeRgeU4nFL5n3PKBn/U4NkoJZPJKKLpB0l+6TpEaO80HlqAkMBAqf55Q03W8fwwmp16QXFNQRD+O6rznCxONd59dNDwgCvAfYbzfwucSU5nwFfOG1AcsBOyjb/NCDN+NWqCliIHEfTfJq4rJ4jQp4DKdzAPKnPSaPLCCTWNPWTL9dENzhUA9FPA927iQ4AfCw2NKQ//hFRdMU+wS3hsFcQQYX3OsLSjZLoGOHCVmxjEu8Yh5zhqDw1WH/jEJqLUaWS5In1tmbM1sCdtGRlJCTA40vfLm6J1x0gU3bCOw6OujjZ1okmiWP8fUjaOh7+N7Xs7EbkdniRul62bJZ3y1/eTiOwMVRX606nbZ5y+JGwz+0r3kMsgBXcqOTc/LdoqLJCXQzFS2pPyadGJWwG338oBs4lpdPEd+zZOd8mlrfOmn4WcV82/GLbj3nHK9Fzuyg4ETOwfglsCPbDsEfx4MuYh9yfMS7KgPyZ76ADY7HyPdUw35ZJnlxlKViG1UY1vmPsQLIV273gcL4faoauHD7f6wxppFhBmM467KRRdJmszkJRaNwGKmvOI3n06oLbSUk8iVbJ5ZKNkLwgZRUumI6kOnzDNFqvGPBo1dAGlLeSaklldoMjdeWdXPbMnK38JWKHbllPusLBPAKtXQsr0F4hJBGo/R1DQHgtMn10leWm0TcAOqwSbJ9PYlaoQtF36dd1ZrKWhTUHDzvCIvW3EhuMFcycJcqghzkPlfWQ+Ev9RK8j+xuFIZm2a7pcpwopTZrTfwaIeraDRJkbA8g20kcJ0f1eYw7hQvwLbeNiKWxecDSNPHnHDUxqq6xgIYOACkTpMzCRt00RPwof2UrGhltUpBxeShFWNSxrBIzS5gfwQKrjGn9dgVogLXi6CttzQFZgfoR6CF4lRoVQxYgFsDai/BMXexJnwvaS9BiMNoRj8cx5LJgmaxLZVBF650mV/kWiiKJT2vAVnSHf4wqSMmhMrZEstS4AAth/HBorDTrDUatHOmyMx2gZTxiXIIcfbkHl2o6ZhmT31EcXfgnpDqg0TQak8BQkuv21WKn7zZ8yLFdrwL1O78jEZUkcZdodTX2/txxZe/u4DXlExjARqNanR+qj73lXeDVoBVbQ/XAHYzYa2260DSzKTqZIoFwkkalmL2AkXxxlEmubXJclVV3M4OM+XR5rqJ1o8CVY/hpLs4wquVEKTcr4uYUNAGCTtV39pQ6YsyAFbMjpSiucAAiMEA7SSkconRdutiist53K1b2ajaz4pDDG79GgE5y0tcgVEDsi4wpn0nik+YK9GCRAp1mT7A905SJQURzEVToIGSd4WjrrlgQPiYM5ElPKpGzSjRBnMwFqvvjEsowm/+6R8mNUpRgRvpUylDoZqNwOuU61Y0nxUQ6biEeGxByZtD5w76NrAaK8VdEP4nIk2mg3fKRLQfJZNWe5qJttFyiVSc6mKRelzCOQ7RT4DboqXSwzLYAvEboXFpUC9SwiBskfqN4Jq6WCSOJeIQiN8IrUCBi0ELxK+HRm1egucL5PQRR85kjlRCKxqk0kPL0W/Qasxp/yasBiMb8tP4LVa10kwfMvKD80vR0g/FlFzjWRuAd4NcHwaEqCqTUx2M9iA17pimNM8Qz33pCw3Hr9DSN6Aa4XyP2S6jxVO7hUtR3mhKLnc/rNw2+jEDIi7Ubk0M1mN6AzBoTxe8W2DZ5mBnU3q/KEb6TOoRPXWYkSE4bTs0JDcMwYDgMQkakjgES+i5QFa45XcmDXdm3VKGAq1PFQZmPArp28nUn/rQ+pq6vyE0xB29zVvi40c7fcZFK27u0V6XWXW9tkc8XN9tvVvT08YTCbl8u58ylsxv0F6azi/XnNgWnE5Y66OaSfFUfn4G10OqB1eCe2RDZwttAspyOroe0JlWz5lwpW2g6Xidxxc6Gse7P+9Ad2ln6Bl4Yx01d6CK3TZJl00LwHWJfnEeDL9QUe/LSM/Il8tbGu6A2bU74LDHoQf7lu38e6AdbfOPb+7TGnMO1nyIfr4JydJde+eG7XzPXWJ1y3cXptyuRlo0VGtSMnOCzl9b4TLHmZTaQk3kXRDwHNoNprxEu+OpKRMCRChRuP0b3/oUnj1Rj6JgMFvG1xBQIk+9aaA2/4f51Hz3ftydcSKH7Tx5on9enPDhxCGrVDGjAO9JiUQyaW3ZHM57bwclSrBMKBqpTyjyEmzLhTQ3A3RJDBbrzSGreWFmUl2XR7QBTQBlkh39mD8lX2fwUWPtNgYTK/QdLRprykJlsIu/nxVIK2XXD5IqnEMpaS8mfJeXjEszKubKbyaRVLQHEUfJllgZFC+n9hYdxazA2pYzidTVB1pJwiYKDTr65nIux8dqFGN88uHmlJj6FShdQW4mmCPKjKBalHT2QLa/ncQacSbHcmatcxjLZLEZ7BvHAyZJLaOtX1AWsE7wHJ80orqG6nNJngYjlB0jmzyzpmfMvdWQINromPZVdsGgfprWVIr2FLLpWs2kxV0A8HiCxPnx73fvIRyqCk2C2UlM4xWJOXCdq05cjXJkBT23il4INV1N78Z6po6xAOS0phE+ChPhNMrJasZMGIeVjUthnTjeOhOuwTHYAGtFsf7IeVc6IyIppC7OAh857zrHXOqffW1MFx+f1WlYYEZT48n0+FJwR2myuc5nThewZhrwT2kRq5vLPv8xViOruNz5UlbObF429ZcJoNQdviTa0L2tmniwqG+mXxh1mH5ZwZdaWZSWM0G/uA+dEH80SjfVORlqzZU+vlZjLoP9GEj3oFPWCRukqCinDldJI2CO5Z02ZmFkZoZVsZlU/556RMlAXIcs+VPi1VrwJeBc18yj0CX7pb776kKXQEC3ojHlo4A6ZljcBJSiRHZ4FFLP7Qr3KtjOWeTo7vjZuURniZh9C6devkAGgh+nmfOxrmWOIMEBrzBetgsngL4tQaKNkyzXjIkzuW9sUOI0uFYWb5TfmDDtTLlWmhONhWfqyRdidXVlFKx2IRLaPfXrOr3TqhNK4nHGC7orwDXM0H0U9IBUlm9jMN1eMRLEojab1h550p0wJqXj+ZeVMloqtxdZ5l9VzNaG+3JGGaksMVc54V+9ksjxnLq2mvCY3LJfxslO4qlFs+NyGUQfGls3NrAgaKy9vGYa1Mg2y0yQ0i0XwkDBeHkwPGfCJxIB4EQQ3raqDPctznr16wowbcfKFapr0T3G0G6B2PbnjVCf2va7EF66/UdAaFOlZXkaFe0Nv+DjIPl8anhN1RDT+lEVSEMHtJUMF3JFsKfhUT6icbpAcZelfRAF9iN934JIl0kZy4WADXm7Pxquu+A/V9I0VegNvkgK6nfmusJi/dD8qmU7hvzS6UTreQ//NxZydmImMectou6ZoCMhjEssvqqMEUQU5jxsKgk1ZhnDevfCuGAbLpLSDdlZf14Qytg94IanoPoT0hVthf9/I9tvwmpo7MRS6ARjW1wb/ufXTUBdNOyPzreh/ga3SmMrebiHqaCbt5xrEMr5uVZLRIc6xpNdgihoe9XR07mA8potPd/iQ/9z2xfjR5BHLrY9osrlS3Qn8S5bR7e7yTavG2gM2/nbbK3r4HdHxY4OMR8RHVxl2ypgfLPv4nPMX6WV1mtFM0qacHixTYfugd3R3cV0upGg5Dgg99R8+uqwtOM5xdfiq72OwT8JLwzeOyZLOZ+ZSaKxezYcl+6SFt1MKpeOZs0Exh+OkcenvvqHDA+TGqdp6Wbo7BfZdLDSs5fhYKdupOxuBDsHsOJyRni/csmrppoVIHudEyB7nR0gspMqvN7ufkUm50RnbFHgy3z4Pqq6Cc1urorDdAEFvvBu1UqOTadIL7z9KLTlYqX0IfOXVMx4YL4/fcKlEq4fyR8090fZQ5B1VsXHOGe8a+ACoMTtRqAF15bjA7EGhjfCDUHg4+C2Ued1cCkXFAtaq6N/t7E6KnPei3Ee5i2m6qgsbKlDpDcaqiO0sJ0Owd5oplRAW3L8if7dw09EFlboAOe0PiNMfwv8rMkjZVJgqb+pn19M5o60sUwOYIDIN4QyycThz5AfnLjlqk0KCcLaQbi7BTRiX7DhPrKIvzvg2Sga/9UiSeBZrnSdacVGGd+tAk/7YijLjcUEa1dK8t+P1YRODsFUIekMsp+oCcfULMejFj0HYgX4ShcWkd1zWeDrqU5/j5vcxTeCm8sebjfBP3949+kznapjJ8TImTSZoeM8rkPtUjEuztM70u6uS6rJccvdfR1KnjUOeiNz8swOzFG9AZNtaqoZiuHu0a2YfjaWV457t/AQ+chqCkYx3xo9BoPIXwpj7Y4iZnU+HtuanAm34TJMyOOcqFHnw67BM7D/RVx7WIGur6ELLr2Bhc8SX0R3RQB6gUNSSqvkhq5PnhS0UgXfcizW7lDkOqyfC45AAB/5JocxnSDd35gIUIpJ+PEqpwB/JCpYBH17g9RJ9B4JFlnc3RDx3Gnyh+j+8Z/jwvmXMyL0NNrcMuwCPiOLa+IMN0CnWCsmG9epE/Guwo5XI915zf999+Pvv/8K//37+/eTUJc51NTVRD3itiY6gD0OrvtbRY/QZcQS17HkLyUVDCslz4J8sBbvB2xLjaZUohhX7VgP1sWwU/fnNkCs5rudu+i3w5kubyt32Rf9WQHam+UFuvu9a9RvvNzGKk27SPRHpiq0Jy+cTuSrURaLne2Wo6LS38XYqBfsj0ir6hOgvbd8qM+Y9tDh26/mGs6ga6x6oM4imBum2lfS442It1r9iXL9youM7XAmuO92/Xte2uhH+RabRnrGRzc5DLLW/x8AD/W+Hw=="
 }
@@ -0,0 +1,158 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package locks
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/elastic/elastic-agent-libs/mapstr"
+)
+
+// blockingChains are the chains of backends blocking each other, as returned
+// by pg_blocking_pids. Prepared transactions holding locks are represented by
+// the pid 0.
+type blockingChains struct {
+	// blockers are the backends directly blocking each backend.
+	blockers map[int64][]int64
+	// blocked are the backends directly blocked by each backend.
+	blocked map[int64][]int64
+}
+
+func newBlockingChains(results []map[string]interface{}) (*blockingChains, error) {
+	chains := &blockingChains{
+		blockers: map[int64][]int64{},
+		blocked:  map[int64][]int64{},
+	}
+	for _, result := range results {
+		pid, err := strconv.ParseInt(fmt.Sprint(result["pid"]), 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse pid: %w", err)
+		}
+		pids, _ := result["blocking_pids"].(string)
+		if pids == "" {
+			continue
+		}
+		for _, p := range strings.Split(pids, ",") {
+			blocker, err := strconv.ParseInt(p, 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse blocking pid of %d: %w", pid, err)
+			}
+			chains.blockers[pid] = append(chains.blockers[pid], blocker)
+			chains.blocked[blocker] = append(chains.blocked[blocker], pid)
+		}
+	}
+	return chains, nil
+}
+
+// fields returns the fields describing the position of a backend in the
+// blocking chains.
+func (c *blockingChains) fields(pid int64) mapstr.M {
+	fields := mapstr.M{
+		"waiting": len(c.blockers[pid]) > 0,
+	}
+
+	if blockers := c.blockers[pid]; len(blockers) > 0 {
+		roots := map[int64]struct{}{}
+		c.roots(pid, map[int64]bool{}, roots)
+		blockedBy := mapstr.M{
+			"pids": sortPids(blockers),
+		}
+		if len(roots) > 0 {
+			blockedBy["root_pids"] = sortedPids(roots)
+		}
+		fields["blocked_by"] = blockedBy
+		fields["chain"] = mapstr.M{
+			"depth": c.depth(pid, map[int64]bool{}),
+		}
+	}
+
+	if len(c.blocked[pid]) > 0 {
+		blocked := map[int64]struct{}{}
+		c.transitivelyBlocked(pid, blocked)
+		delete(blocked, pid)
+		fields["blocking"] = mapstr.M{
+			"pids":  sortPids(c.blocked[pid]),
+			"count": len(blocked),
+		}
+	}
+
+	return fields
+}
+
+// roots collects the backends at the head of the chains blocking pid, the
+// ones that are not waiting themselves.
+func (c *blockingChains) roots(pid int64, visiting map[int64]bool, roots map[int64]struct{}) {
+	if visiting[pid] {
+		// Deadlock, the backends in the cycle wait for each other.
+		return
+	}
+	visiting[pid] = true
+	for _, blocker := range c.blockers[pid] {
+		if len(c.blockers[blocker]) == 0 {
+			roots[blocker] = struct{}{}
+			continue
+		}
+		c.roots(blocker, visiting, roots)
+	}
+}
+
+// depth returns the number of backends in the longest chain blocking pid.
+func (c *blockingChains) depth(pid int64, visiting map[int64]bool) int {
+	if visiting[pid] {
+		return 0
+	}
+	visiting[pid] = true
+	defer delete(visiting, pid)
+
+	depth := 0
+	for _, blocker := range c.blockers[pid] {
+		if d := 1 + c.depth(blocker, visiting); d > depth {
+			depth = d
+		}
+	}
+	return depth
+}
+
+// transitivelyBlocked collects the backends waiting directly or indirectly
+// for pid.
+func (c *blockingChains) transitivelyBlocked(pid int64, blocked map[int64]struct{}) {
+	if _, found := blocked[pid]; found {
+		return
+	}
+	blocked[pid] = struct{}{}
+	for _, waiter := range c.blocked[pid] {
+		c.transitivelyBlocked(waiter, blocked)
+	}
+}
+
+func sortPids(pids []int64) []int64 {
+	sorted := append([]int64(nil), pids...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	return sorted
+}
+
+func sortedPids(pids map[int64]struct{}) []int64 {
+	sorted := make([]int64, 0, len(pids))
+	for pid := range pids {
+		sorted = append(sorted, pid)
+	}
+	return sortPids(sorted)
+}
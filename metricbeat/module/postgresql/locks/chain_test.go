@@ -0,0 +1,109 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+//go:build !integration && !requirefips
+
+package locks
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/elastic/elastic-agent-libs/mapstr"
+)
+
+func TestBlockingChains(t *testing.T) {
+	chains, err := newBlockingChains([]map[string]interface{}{
+		{"pid": "100", "blocking_pids": ""},
+		{"pid": "101", "blocking_pids": "100"},
+		{"pid": "102", "blocking_pids": "101,100"},
+		{"pid": "103", "blocking_pids": "0"},
+		{"pid": "200", "blocking_pids": "201"},
+		{"pid": "201", "blocking_pids": "200"},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, mapstr.M{
+		"waiting": false,
+		"blocking": mapstr.M{
+			"pids":  []int64{101, 102},
+			"count": 2,
+		},
+	}, chains.fields(100))
+
+	assert.Equal(t, mapstr.M{
+		"waiting": true,
+		"blocked_by": mapstr.M{
+			"pids":      []int64{100},
+			"root_pids": []int64{100},
+		},
+		"chain": mapstr.M{
+			"depth": 1,
+		},
+		"blocking": mapstr.M{
+			"pids":  []int64{102},
+			"count": 1,
+		},
+	}, chains.fields(101))
+
+	assert.Equal(t, mapstr.M{
+		"waiting": true,
+		"blocked_by": mapstr.M{
+			"pids":      []int64{100, 101},
+			"root_pids": []int64{100},
+		},
+		"chain": mapstr.M{
+			"depth": 2,
+		},
+	}, chains.fields(102))
+
+	// Lock held by a prepared transaction.
+	assert.Equal(t, mapstr.M{
+		"waiting": true,
+		"blocked_by": mapstr.M{
+			"pids":      []int64{0},
+			"root_pids": []int64{0},
+		},
+		"chain": mapstr.M{
+			"depth": 1,
+		},
+	}, chains.fields(103))
+
+	// Deadlock, no backend is at the head of the chain.
+	assert.Equal(t, mapstr.M{
+		"waiting": true,
+		"blocked_by": mapstr.M{
+			"pids": []int64{201},
+		},
+		"chain": mapstr.M{
+			"depth": 2,
+		},
+		"blocking": mapstr.M{
+			"pids":  []int64{201},
+			"count": 1,
+		},
+	}, chains.fields(200))
+}
+
+func TestBlockingChainsInvalidPid(t *testing.T) {
+	_, err := newBlockingChains([]map[string]interface{}{
+		{"pid": "100", "blocking_pids": "abc"},
+	})
+	assert.Error(t, err)
+}
@@ -0,0 +1,62 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package locks
+
+import (
+	"time"
+
+	s "github.com/elastic/beats/v7/libbeat/common/schema"
+	c "github.com/elastic/beats/v7/libbeat/common/schema/mapstrstr"
+)
+
+// Based on: https://www.postgresql.org/docs/13/monitoring-stats.html#MONITORING-PG-STAT-ACTIVITY-VIEW
+var schema = s.Schema{
+	"pid": c.Int("pid"),
+	"database": s.Object{
+		"oid":  c.Int("datid", s.Optional),
+		"name": c.Str("datname"),
+	},
+	"user": s.Object{
+		"id":   c.Int("usesysid", s.Optional),
+		"name": c.Str("usename"),
+	},
+	"application_name": c.Str("application_name"),
+	"client": s.Object{
+		"address": c.Str("client_addr"),
+	},
+	"state":             c.Str("state"),
+	"query":             c.Str("query"),
+	"wait_event_type":   c.Str("wait_event_type"),
+	"wait_event":        c.Str("wait_event"),
+	"query_start":       c.Time(time.RFC3339Nano, "query_start", s.Optional),
+	"transaction_start": c.Time(time.RFC3339Nano, "xact_start", s.Optional),
+	"duration": s.Object{
+		"query":       s.Object{"ms": c.Float("query_duration_ms", s.Optional)},
+		"transaction": s.Object{"ms": c.Float("transaction_duration_ms", s.Optional)},
+	},
+}
+
+// Based on: https://www.postgresql.org/docs/13/view-pg-locks.html
+var lockSchema = s.Schema{
+	"type": c.Str("locktype"),
+	"mode": c.Str("mode"),
+	"relation": s.Object{
+		"oid":  c.Int("relation", s.Optional),
+		"name": c.Str("relation_name"),
+	},
+}
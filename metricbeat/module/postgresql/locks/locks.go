@@ -0,0 +1,102 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+//go:build !requirefips
+
+package locks
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/elastic/beats/v7/metricbeat/mb"
+	"github.com/elastic/beats/v7/metricbeat/module/postgresql"
+)
+
+// init registers the MetricSet with the central registry.
+// The New method will be called after the setup of the module and before starting to fetch data
+func init() {
+	mb.Registry.MustAddMetricSet("postgresql", "locks", New,
+		mb.WithHostParser(postgresql.ParseURL),
+	)
+}
+
+// Backends waiting for a lock, with the lock they wait for, and backends
+// blocking them. pg_blocking_pids is available since PostgreSQL 9.6.
+const query = `SELECT a.pid, a.datid, a.datname, a.usesysid, a.usename, a.application_name, a.client_addr,
+	a.state, a.query, a.wait_event_type, a.wait_event, a.query_start, a.xact_start,
+	EXTRACT(EPOCH FROM now() - a.query_start) * 1000 AS query_duration_ms,
+	EXTRACT(EPOCH FROM now() - a.xact_start) * 1000 AS transaction_duration_ms,
+	array_to_string(pg_blocking_pids(a.pid), ',') AS blocking_pids,
+	l.locktype, l.mode, l.relation, l.relation::regclass AS relation_name
+	FROM pg_stat_activity a
+	LEFT JOIN pg_locks l ON l.pid = a.pid AND NOT l.granted
+	WHERE a.pid <> pg_backend_pid() AND (
+		cardinality(pg_blocking_pids(a.pid)) > 0 OR
+		a.pid IN (SELECT unnest(pg_blocking_pids(pid)) FROM pg_stat_activity)
+	)`
+
+// MetricSet type defines all fields of the Postgresql MetricSet
+type MetricSet struct {
+	*postgresql.MetricSet
+}
+
+// New create a new instance of the MetricSet
+// Part of new is also setting up the configuration by processing additional
+// configuration entries if needed.
+func New(base mb.BaseMetricSet) (mb.MetricSet, error) {
+	ms, err := postgresql.NewMetricSet(base)
+	if err != nil {
+		return nil, err
+	}
+	return &MetricSet{MetricSet: ms}, nil
+}
+
+// Fetch methods implements the data gathering and data conversion to the right
+// format. It publishes an event per backend waiting for a lock, and per
+// backend blocking others, with the chain of backends blocking them.
+func (m *MetricSet) Fetch(reporter mb.ReporterV2) error {
+	ctx := context.Background()
+
+	results, err := m.QueryStats(ctx, query)
+	if err != nil {
+		return fmt.Errorf("error in QueryStats: %w", err)
+	}
+
+	chains, err := newBlockingChains(results)
+	if err != nil {
+		return err
+	}
+
+	for _, result := range results {
+		data, _ := schema.Apply(result)
+		pid, ok := data["pid"].(int64)
+		if !ok {
+			continue
+		}
+		data.DeepUpdate(chains.fields(pid))
+		if waiting, _ := data["waiting"].(bool); waiting {
+			lock, _ := lockSchema.Apply(result)
+			data["lock"] = lock
+		}
+		reporter.Event(mb.Event{
+			MetricSetFields: data,
+		})
+	}
+
+	return nil
+}
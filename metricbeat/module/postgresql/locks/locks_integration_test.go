@@ -0,0 +1,127 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+//go:build integration && !requirefips
+
+package locks
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/elastic/beats/v7/libbeat/tests/compose"
+	mbtest "github.com/elastic/beats/v7/metricbeat/mb/testing"
+	"github.com/elastic/beats/v7/metricbeat/module/postgresql"
+	"github.com/elastic/elastic-agent-libs/mapstr"
+)
+
+func TestFetch(t *testing.T) {
+	service := compose.EnsureUp(t, "postgresql")
+	blocker, waiter := lockWait(t, service.Host())
+
+	f := mbtest.NewReportingMetricSetV2Error(t, getConfig(service.Host()))
+	events, errs := mbtest.ReportingFetchV2Error(f)
+	if len(errs) > 0 {
+		t.Fatalf("Expected 0 error, had %d. %v\n", len(errs), errs)
+	}
+
+	byPid := map[int64]mapstr.M{}
+	for _, event := range events {
+		t.Logf("%s/%s event: %+v", f.Module().Name(), f.Name(), event.MetricSetFields)
+		byPid[event.MetricSetFields["pid"].(int64)] = event.MetricSetFields
+	}
+	require.Contains(t, byPid, blocker)
+	require.Contains(t, byPid, waiter)
+
+	assert.Equal(t, false, byPid[blocker]["waiting"])
+	count, _ := byPid[blocker].GetValue("blocking.count")
+	assert.Equal(t, 1, count)
+
+	assert.Equal(t, true, byPid[waiter]["waiting"])
+	roots, _ := byPid[waiter].GetValue("blocked_by.root_pids")
+	assert.Equal(t, []int64{blocker}, roots)
+	mode, _ := byPid[waiter].GetValue("lock.mode")
+	assert.Equal(t, "AccessExclusiveLock", mode)
+	relation, _ := byPid[waiter].GetValue("lock.relation.name")
+	assert.Equal(t, "metricbeat_locks_test", relation)
+}
+
+func TestData(t *testing.T) {
+	service := compose.EnsureUp(t, "postgresql")
+	lockWait(t, service.Host())
+
+	f := mbtest.NewFetcher(t, getConfig(service.Host()))
+	f.WriteEventsCond(t, "", func(event mapstr.M) bool {
+		waiting, _ := event.GetValue("postgresql.locks.waiting")
+		return waiting == true
+	})
+}
+
+// lockWait makes a backend wait for a lock held by another one, it returns
+// their pids.
+func lockWait(t *testing.T, host string) (blocker, waiter int64) {
+	dsn := fmt.Sprintf("postgres://%s:%s@%s/postgres?sslmode=disable",
+		postgresql.GetEnvUsername(), postgresql.GetEnvPassword(), host)
+	db, err := sql.Open("postgres", dsn)
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	// Cancelling the context rolls back the transactions.
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	_, err = db.ExecContext(ctx, "CREATE TABLE IF NOT EXISTS metricbeat_locks_test (id int)")
+	require.NoError(t, err)
+
+	blockerTx, err := db.BeginTx(ctx, nil)
+	require.NoError(t, err)
+	require.NoError(t, blockerTx.QueryRowContext(ctx, "SELECT pg_backend_pid()").Scan(&blocker))
+	_, err = blockerTx.ExecContext(ctx, "LOCK TABLE metricbeat_locks_test IN ACCESS EXCLUSIVE MODE")
+	require.NoError(t, err)
+
+	waiterTx, err := db.BeginTx(ctx, nil)
+	require.NoError(t, err)
+	require.NoError(t, waiterTx.QueryRowContext(ctx, "SELECT pg_backend_pid()").Scan(&waiter))
+	go func() {
+		// Blocks until the context is cancelled when the test finishes.
+		waiterTx.ExecContext(ctx, "LOCK TABLE metricbeat_locks_test IN ACCESS EXCLUSIVE MODE")
+	}()
+
+	require.Eventually(t, func() bool {
+		var waiting bool
+		err := db.QueryRowContext(ctx, "SELECT cardinality(pg_blocking_pids($1)) > 0", waiter).Scan(&waiting)
+		return err == nil && waiting
+	}, 10*time.Second, 100*time.Millisecond)
+
+	return blocker, waiter
+}
+
+func getConfig(host string) map[string]interface{} {
+	return map[string]interface{}{
+		"module":     "postgresql",
+		"metricsets": []string{"locks"},
+		"hosts":      []string{postgresql.GetDSN(host)},
+		"username":   postgresql.GetEnvUsername(),
+		"password":   postgresql.GetEnvPassword(),
+	}
+}
@@ -22,7 +22,9 @@ package postgresql
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
+	"strconv"
 
 	"github.com/elastic/beats/v7/metricbeat/mb"
 
@@ -95,6 +97,23 @@ func (ms *MetricSet) QueryStats(ctx context.Context, query string) ([]map[string
 	return results, nil
 }
 
+// ServerVersionNum returns the version of the server as an integer, as
+// 130011 for 13.11, to select the queries supported by the server
+func (ms *MetricSet) ServerVersionNum(ctx context.Context) (int, error) {
+	results, err := ms.QueryStats(ctx, "SHOW server_version_num")
+	if err != nil {
+		return 0, err
+	}
+	if len(results) == 0 {
+		return 0, errors.New("server version not found")
+	}
+	version, err := strconv.Atoi(fmt.Sprint(results[0]["server_version_num"]))
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse server version: %w", err)
+	}
+	return version, nil
+}
+
 // Close closes the metricset and its connections
 func (ms *MetricSet) Close() error {
 	if ms.db == nil {
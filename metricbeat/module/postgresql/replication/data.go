@@ -0,0 +1,171 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package replication
+
+import (
+	"fmt"
+	"time"
+
+	s "github.com/elastic/beats/v7/libbeat/common/schema"
+	c "github.com/elastic/beats/v7/libbeat/common/schema/mapstrstr"
+	"github.com/elastic/elastic-agent-libs/mapstr"
+)
+
+// walNames are the names of the functions and columns about the WAL, they
+// were renamed from xlog and location in PostgreSQL 10.
+type walNames struct {
+	lsnDiffFunc    string
+	currentLSNFunc string
+	receiveLSNFunc string
+	lsnColumn      string
+	hasLag         bool
+}
+
+func walNamesFor(version int) walNames {
+	if version < 100000 {
+		return walNames{
+			lsnDiffFunc:    "pg_xlog_location_diff",
+			currentLSNFunc: "pg_current_xlog_location",
+			receiveLSNFunc: "pg_last_xlog_receive_location",
+			lsnColumn:      "location",
+		}
+	}
+	return walNames{
+		lsnDiffFunc:    "pg_wal_lsn_diff",
+		currentLSNFunc: "pg_current_wal_lsn",
+		receiveLSNFunc: "pg_last_wal_receive_lsn",
+		lsnColumn:      "lsn",
+		hasLag:         true,
+	}
+}
+
+// currentLSN is the position of the WAL of the server, the last position
+// received if it is a standby itself.
+func (n walNames) currentLSN() string {
+	return fmt.Sprintf("(CASE WHEN pg_is_in_recovery() THEN %s() ELSE %s() END)", n.receiveLSNFunc, n.currentLSNFunc)
+}
+
+// Based on: https://www.postgresql.org/docs/13/monitoring-stats.html#MONITORING-PG-STAT-REPLICATION-VIEW
+func standbysQuery(n walNames) string {
+	lag := "NULL AS write_lag_ms, NULL AS flush_lag_ms, NULL AS replay_lag_ms"
+	if n.hasLag {
+		lag = "EXTRACT(EPOCH FROM write_lag) * 1000 AS write_lag_ms, " +
+			"EXTRACT(EPOCH FROM flush_lag) * 1000 AS flush_lag_ms, " +
+			"EXTRACT(EPOCH FROM replay_lag) * 1000 AS replay_lag_ms"
+	}
+	return fmt.Sprintf(`SELECT pid, usesysid, usename, application_name, client_addr, client_hostname, client_port,
+		backend_start, state, sync_state, sync_priority,
+		sent_%[2]s AS sent_lsn, write_%[2]s AS write_lsn, flush_%[2]s AS flush_lsn, replay_%[2]s AS replay_lsn,
+		%[1]s(%[3]s, sent_%[2]s) AS sent_lag_bytes,
+		%[1]s(%[3]s, write_%[2]s) AS write_lag_bytes,
+		%[1]s(%[3]s, flush_%[2]s) AS flush_lag_bytes,
+		%[1]s(%[3]s, replay_%[2]s) AS replay_lag_bytes,
+		%[4]s
+		FROM pg_stat_replication`, n.lsnDiffFunc, n.lsnColumn, n.currentLSN(), lag)
+}
+
+// Based on: https://www.postgresql.org/docs/13/view-pg-replication-slots.html
+func slotsQuery(n walNames) string {
+	return fmt.Sprintf(`SELECT *,
+		%[1]s(%[2]s, restart_lsn) AS retained_bytes,
+		%[1]s(%[2]s, confirmed_flush_lsn) AS confirmed_flush_lag_bytes
+		FROM pg_replication_slots`, n.lsnDiffFunc, n.currentLSN())
+}
+
+var standbySchema = s.Schema{
+	"pid": c.Int("pid"),
+	"user": s.Object{
+		"id":   c.Int("usesysid", s.Optional),
+		"name": c.Str("usename"),
+	},
+	"application_name": c.Str("application_name"),
+	"client": s.Object{
+		"address":  c.Str("client_addr"),
+		"hostname": c.Str("client_hostname"),
+		"port":     c.Int("client_port", s.Optional),
+	},
+	"backend_start": c.Time(time.RFC3339Nano, "backend_start", s.Optional),
+	"state":         c.Str("state"),
+	"sync": s.Object{
+		"state":    c.Str("sync_state"),
+		"priority": c.Int("sync_priority", s.Optional),
+	},
+	"lsn": s.Object{
+		"sent":   c.Str("sent_lsn"),
+		"write":  c.Str("write_lsn"),
+		"flush":  c.Str("flush_lsn"),
+		"replay": c.Str("replay_lsn"),
+	},
+	"lag": s.Object{
+		"sent":   s.Object{"bytes": c.Int("sent_lag_bytes", s.Optional)},
+		"write":  s.Object{"bytes": c.Int("write_lag_bytes", s.Optional), "ms": c.Float("write_lag_ms", s.Optional)},
+		"flush":  s.Object{"bytes": c.Int("flush_lag_bytes", s.Optional), "ms": c.Float("flush_lag_ms", s.Optional)},
+		"replay": s.Object{"bytes": c.Int("replay_lag_bytes", s.Optional), "ms": c.Float("replay_lag_ms", s.Optional)},
+	},
+}
+
+var slotSchema = s.Schema{
+	"name":   c.Str("slot_name"),
+	"plugin": c.Str("plugin"),
+	"type":   c.Str("slot_type"),
+	"database": s.Object{
+		"oid":  c.Int("datoid", s.Optional),
+		"name": c.Str("database"),
+	},
+	"temporary":  c.Bool("temporary", s.Optional),
+	"active":     c.Bool("active"),
+	"active_pid": c.Int("active_pid", s.Optional),
+	"lsn": s.Object{
+		"restart":         c.Str("restart_lsn"),
+		"confirmed_flush": c.Str("confirmed_flush_lsn"),
+	},
+	"lag": s.Object{
+		"confirmed_flush": s.Object{"bytes": c.Int("confirmed_flush_lag_bytes", s.Optional)},
+	},
+	"wal": s.Object{
+		"retained":  s.Object{"bytes": c.Int("retained_bytes", s.Optional)},
+		"status":    c.Str("wal_status", s.Optional),
+		"safe_size": s.Object{"bytes": c.Int("safe_wal_size", s.Optional)},
+	},
+}
+
+func mapStandby(data mapstr.M) mapstr.M {
+	return mapstr.M{"standby": removeEmpty(data)}
+}
+
+func mapSlot(data mapstr.M) mapstr.M {
+	return mapstr.M{"slot": removeEmpty(data)}
+}
+
+// removeEmpty removes the string fields of columns that are NULL, as the
+// LSN of a physical slot that was never used, and the objects left empty.
+func removeEmpty(data mapstr.M) mapstr.M {
+	for key, value := range data {
+		switch v := value.(type) {
+		case string:
+			if v == "" {
+				delete(data, key)
+			}
+		case mapstr.M:
+			if len(removeEmpty(v)) == 0 {
+				delete(data, key)
+			}
+		}
+	}
+	return data
+}
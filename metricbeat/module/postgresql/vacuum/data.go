@@ -0,0 +1,74 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package vacuum
+
+import (
+	"time"
+
+	s "github.com/elastic/beats/v7/libbeat/common/schema"
+	c "github.com/elastic/beats/v7/libbeat/common/schema/mapstrstr"
+)
+
+// Based on: https://www.postgresql.org/docs/13/monitoring-stats.html#MONITORING-PG-STAT-ALL-TABLES-VIEW
+var schema = s.Schema{
+	"database": s.Object{
+		"name": c.Str("datname"),
+	},
+	"table": s.Object{
+		"oid":    c.Int("relid"),
+		"schema": c.Str("schemaname"),
+		"name":   c.Str("relname"),
+	},
+	"size": s.Object{
+		"table": s.Object{"bytes": c.Int("table_size", s.Optional)},
+		"total": s.Object{"bytes": c.Int("total_size", s.Optional)},
+	},
+	"scans": s.Object{
+		"sequential": c.Int("seq_scan"),
+		"index":      c.Int("idx_scan", s.Optional),
+	},
+	"tuples": s.Object{
+		"live": c.Int("n_live_tup"),
+		"dead": c.Int("n_dead_tup"),
+		"dead_ratio": s.Object{
+			"pct": c.Float("dead_tuples_pct", s.Optional),
+		},
+		"modified_since_analyze": c.Int("n_mod_since_analyze", s.Optional),
+		"inserted_since_vacuum":  c.Int("n_ins_since_vacuum", s.Optional),
+	},
+	"vacuum": s.Object{
+		"last":  c.Time(time.RFC3339Nano, "last_vacuum", s.Optional),
+		"count": c.Int("vacuum_count"),
+	},
+	"autovacuum": s.Object{
+		"last":      c.Time(time.RFC3339Nano, "last_autovacuum", s.Optional),
+		"count":     c.Int("autovacuum_count"),
+		"threshold": c.Float("autovacuum_threshold", s.Optional),
+	},
+	"analyze": s.Object{
+		"last":  c.Time(time.RFC3339Nano, "last_analyze", s.Optional),
+		"count": c.Int("analyze_count"),
+	},
+	"autoanalyze": s.Object{
+		"last":  c.Time(time.RFC3339Nano, "last_autoanalyze", s.Optional),
+		"count": c.Int("autoanalyze_count"),
+	},
+	"frozen_xid": s.Object{
+		"age": c.Int("frozenxid_age", s.Optional),
+	},
+}
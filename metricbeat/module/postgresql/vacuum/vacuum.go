@@ -0,0 +1,93 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+//go:build !requirefips
+
+package vacuum
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/elastic/beats/v7/metricbeat/mb"
+	"github.com/elastic/beats/v7/metricbeat/module/postgresql"
+)
+
+// init registers the MetricSet with the central registry.
+// The New method will be called after the setup of the module and before starting to fetch data
+func init() {
+	mb.Registry.MustAddMetricSet("postgresql", "vacuum", New,
+		mb.WithHostParser(postgresql.ParseURL),
+	)
+}
+
+// Statistics of the user tables of the database, with their size, the age of
+// their oldest unfrozen transaction ID, and the number of dead tuples that
+// triggers autovacuum, without considering per-table storage parameters.
+const query = `SELECT t.*, current_database() AS datname,
+	pg_table_size(t.relid) AS table_size,
+	pg_total_relation_size(t.relid) AS total_size,
+	age(c.relfrozenxid) AS frozenxid_age,
+	CASE WHEN t.n_live_tup + t.n_dead_tup > 0
+		THEN t.n_dead_tup::float / (t.n_live_tup + t.n_dead_tup) END AS dead_tuples_pct,
+	current_setting('autovacuum_vacuum_threshold')::float +
+		current_setting('autovacuum_vacuum_scale_factor')::float * GREATEST(c.reltuples, 0) AS autovacuum_threshold
+	FROM pg_stat_user_tables t
+	JOIN pg_class c ON c.oid = t.relid`
+
+// MetricSet type defines all fields of the Postgresql MetricSet
+type MetricSet struct {
+	*postgresql.MetricSet
+}
+
+// New create a new instance of the MetricSet
+// Part of new is also setting up the configuration by processing additional
+// configuration entries if needed.
+func New(base mb.BaseMetricSet) (mb.MetricSet, error) {
+	ms, err := postgresql.NewMetricSet(base)
+	if err != nil {
+		return nil, err
+	}
+	return &MetricSet{MetricSet: ms}, nil
+}
+
+// Fetch methods implements the data gathering and data conversion to the right
+// format. It publishes an event per user table of the database.
+func (m *MetricSet) Fetch(reporter mb.ReporterV2) error {
+	ctx := context.Background()
+
+	results, err := m.QueryStats(ctx, query)
+	if err != nil {
+		return fmt.Errorf("error in QueryStats: %w", err)
+	}
+
+	for _, result := range results {
+		data, _ := schema.Apply(result)
+		dead, _ := data.GetValue("tuples.dead")
+		threshold, _ := data.GetValue("autovacuum.threshold")
+		if dead, ok := dead.(int64); ok {
+			if threshold, ok := threshold.(float64); ok {
+				data.Put("autovacuum.pending", float64(dead) > threshold)
+			}
+		}
+		reporter.Event(mb.Event{
+			MetricSetFields: data,
+		})
+	}
+
+	return nil
+}
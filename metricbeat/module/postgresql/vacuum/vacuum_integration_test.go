@@ -0,0 +1,109 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+//go:build integration && !requirefips
+
+package vacuum
+
+import (
+	"database/sql"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/elastic/beats/v7/libbeat/tests/compose"
+	mbtest "github.com/elastic/beats/v7/metricbeat/mb/testing"
+	"github.com/elastic/beats/v7/metricbeat/module/postgresql"
+	"github.com/elastic/elastic-agent-libs/mapstr"
+)
+
+func TestFetch(t *testing.T) {
+	service := compose.EnsureUp(t, "postgresql")
+	createTable(t, service.Host())
+
+	f := mbtest.NewReportingMetricSetV2Error(t, getConfig(service.Host()))
+	events, errs := mbtest.ReportingFetchV2Error(f)
+	if len(errs) > 0 {
+		t.Fatalf("Expected 0 error, had %d. %v\n", len(errs), errs)
+	}
+
+	var table mapstr.M
+	for _, event := range events {
+		t.Logf("%s/%s event: %+v", f.Module().Name(), f.Name(), event.MetricSetFields)
+		if name, _ := event.MetricSetFields.GetValue("table.name"); name == "metricbeat_vacuum_test" {
+			table = event.MetricSetFields
+		}
+	}
+	require.NotNil(t, table, "table not found")
+
+	assert.Equal(t, "postgres", table["database"].(mapstr.M)["name"])
+	assert.Equal(t, "public", table["table"].(mapstr.M)["schema"])
+
+	count, _ := table.GetValue("analyze.count")
+	assert.GreaterOrEqual(t, count.(int64), int64(1))
+	assert.Contains(t, table["analyze"], "last")
+
+	size, _ := table.GetValue("size.total.bytes")
+	assert.Greater(t, size.(int64), int64(0))
+
+	age, _ := table.GetValue("frozen_xid.age")
+	assert.GreaterOrEqual(t, age.(int64), int64(0))
+
+	assert.Contains(t, table["autovacuum"], "pending")
+}
+
+func TestData(t *testing.T) {
+	service := compose.EnsureUp(t, "postgresql")
+	createTable(t, service.Host())
+
+	f := mbtest.NewFetcher(t, getConfig(service.Host()))
+	f.WriteEventsCond(t, "", func(event mapstr.M) bool {
+		name, _ := event.GetValue("postgresql.vacuum.table.name")
+		return name == "metricbeat_vacuum_test"
+	})
+}
+
+// createTable creates a table with some dead tuples and analyzes it.
+func createTable(t *testing.T, host string) {
+	dsn := fmt.Sprintf("postgres://%s:%s@%s/postgres?sslmode=disable",
+		postgresql.GetEnvUsername(), postgresql.GetEnvPassword(), host)
+	db, err := sql.Open("postgres", dsn)
+	require.NoError(t, err)
+	defer db.Close()
+
+	for _, query := range []string{
+		"CREATE TABLE IF NOT EXISTS metricbeat_vacuum_test (id int)",
+		"INSERT INTO metricbeat_vacuum_test SELECT generate_series(1, 1000)",
+		"DELETE FROM metricbeat_vacuum_test WHERE id % 2 = 0",
+		"ANALYZE metricbeat_vacuum_test",
+	} {
+		_, err := db.Exec(query)
+		require.NoError(t, err)
+	}
+}
+
+func getConfig(host string) map[string]interface{} {
+	return map[string]interface{}{
+		"module":     "postgresql",
+		"metricsets": []string{"vacuum"},
+		"hosts":      []string{postgresql.GetDSN(host)},
+		"username":   postgresql.GetEnvUsername(),
+		"password":   postgresql.GetEnvPassword(),
+	}
+}
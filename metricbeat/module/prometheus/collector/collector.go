@@ -99,6 +99,13 @@ func MetricSetBuilder(namespace string, genFactory PromEventsGeneratorFactory) f
 		if err != nil {
 			return nil, err
 		}
+		if config.NativeHistograms {
+			http, err := prometheus.GetHttp()
+			if err != nil {
+				return nil, err
+			}
+			http.SetHeader("Accept", p.ProtobufAcceptHeader)
+		}
 
 		promEventsGen, err := genFactory(base)
 		if err != nil {
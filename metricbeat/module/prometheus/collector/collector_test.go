@@ -22,6 +22,7 @@ package collector
 import (
 	"net/http"
 	"net/http/httptest"
+	"os"
 	"sort"
 	"strings"
 	"testing"
@@ -29,6 +30,7 @@ import (
 	"github.com/prometheus/common/model"
 	pl "github.com/prometheus/prometheus/model/labels"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"google.golang.org/protobuf/proto"
 
 	"github.com/elastic/elastic-agent-libs/mapstr"
@@ -446,6 +448,56 @@ func TestFetchEventForCountingMetrics(t *testing.T) {
 		})
 	}
 }
+func TestFetchNativeHistograms(t *testing.T) {
+	data, err := os.ReadFile("../../../helper/prometheus/testdata/native-histogram.pb")
+	require.NoError(t, err)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.Header.Get("Accept"), p.ProtobufType) {
+			w.WriteHeader(http.StatusNotAcceptable)
+			return
+		}
+		w.Header().Set("Content-Type", p.ContentTypeProtobuf)
+		w.WriteHeader(http.StatusOK)
+		w.Write(data)
+	}))
+	defer server.Close()
+
+	config := map[string]interface{}{
+		"module":            "prometheus",
+		"metricsets":        []string{"collector"},
+		"hosts":             []string{server.URL},
+		"native_histograms": true,
+	}
+
+	f := mbtest.NewReportingMetricSetV2Error(t, config)
+	events, errs := mbtest.ReportingFetchV2Error(f)
+	require.Empty(t, errs)
+
+	buckets := map[string]interface{}{}
+	for _, event := range events {
+		labels, _ := event.RootFields.GetValue("prometheus.labels")
+		metrics, _ := event.RootFields.GetValue("prometheus.metrics")
+		if handler, _ := labels.(mapstr.M).GetValue("handler"); handler == nil {
+			continue
+		}
+		if le, _ := labels.(mapstr.M).GetValue("le"); le != nil {
+			buckets[le.(string)] = metrics.(mapstr.M)["http_request_duration_seconds_bucket"]
+			continue
+		}
+		assert.Equal(t, mapstr.M{
+			"http_request_duration_seconds_count": uint64(6),
+			"http_request_duration_seconds_sum":   4.5,
+		}, metrics)
+	}
+	assert.Equal(t, map[string]interface{}{
+		"0.001": uint64(1),
+		"1":     uint64(3),
+		"2":     uint64(4),
+		"8":     uint64(6),
+	}, buckets)
+}
+
 func validateEvent(t *testing.T, event mb.Event, expectedLabels mapstr.M, expectedMetricsCount int) {
 	t.Helper()
 
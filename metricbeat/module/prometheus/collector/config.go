@@ -20,6 +20,9 @@ package collector
 type metricsetConfig struct {
 	MetricsCount   bool          `config:"metrics_count"`
 	MetricsFilters MetricFilters `config:"metrics_filters" yaml:"metrics_filters,omitempty"`
+	// NativeHistograms negotiates the protobuf exposition format, the only
+	// one that exposes native histograms.
+	NativeHistograms bool `config:"native_histograms"`
 }
 
 type MetricFilters struct {
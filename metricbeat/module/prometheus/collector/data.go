@@ -129,6 +129,11 @@ func (p *promEventGenerator) GeneratePromEvents(mf *p.MetricFamily) []PromEvent
 		}
 
 		histogram := metric.GetHistogram()
+		if histogram == nil {
+			// Report native histograms as classic ones, with a cumulative
+			// bucket at the upper bound of each native bucket.
+			histogram = metric.GetNativeHistogram().ToHistogram()
+		}
 		if histogram != nil {
 			if !math.IsNaN(histogram.GetSampleSum()) && !math.IsInf(histogram.GetSampleSum(), 0) {
 				events = append(events, PromEvent{
@@ -19,9 +19,11 @@ package remote_write
 
 import (
 	"math"
+	"strconv"
 
 	"github.com/prometheus/common/model"
 
+	"github.com/elastic/beats/v7/metricbeat/helper/prometheus"
 	"github.com/elastic/beats/v7/metricbeat/mb"
 	"github.com/elastic/elastic-agent-libs/mapstr"
 )
@@ -61,40 +63,38 @@ func (p *RemoteWriteEventGenerator) GenerateEvents(metrics model.Samples) map[st
 			continue
 		}
 		val := float64(metric.Value)
-		if math.IsNaN(val) || math.IsInf(val, 0) {
+		if metric.Histogram == nil && (math.IsNaN(val) || math.IsInf(val, 0)) {
 			continue
 		}
 
 		//nolint:typecheck,nolintlint // 'name' is being used in as a key in mapstr.M below
 		name := string(metric.Metric["__name__"])
 		delete(metric.Metric, "__name__")
+		delete(metric.Metric, TypeLabel)
 
 		for k, v := range metric.Metric {
 			labels[string(k)] = v
 		}
 
-		// join metrics with same labels and same timestamp in a single event
-		labelsHash := labels.String() + metric.Timestamp.Time().String()
-		if _, ok := eventList[labelsHash]; !ok {
-			eventList[labelsHash] = mb.Event{
-				RootFields: mapstr.M{},
-				ModuleFields: mapstr.M{
-					"metrics": mapstr.M{},
-				},
-				Timestamp: metric.Timestamp.Time(),
-			}
-
-			// Add labels
-			if len(labels) > 0 {
-				eventList[labelsHash].ModuleFields["labels"] = labels
+		if metric.Histogram != nil {
+			// Report native histograms as classic ones, with a cumulative
+			// bucket at the upper bound of each native bucket.
+			h := (&prometheus.NativeHistogram{SampleHistogram: *metric.Histogram}).ToHistogram()
+			addMetric(eventList, labels, metric.Timestamp, mapstr.M{
+				name + "_sum":   h.GetSampleSum(),
+				name + "_count": h.GetSampleCount(),
+			})
+			for _, bucket := range h.GetBucket() {
+				bucketLabels := labels.Clone()
+				bucketLabels["le"] = model.LabelValue(strconv.FormatFloat(bucket.GetUpperBound(), 'f', -1, 64))
+				addMetric(eventList, bucketLabels, metric.Timestamp, mapstr.M{
+					name + "_bucket": bucket.GetCumulativeCount(),
+				})
 			}
+			continue
 		}
 
-		// Not checking anything here because we create these maps some lines before
-		e := eventList[labelsHash]
-
-		data := mapstr.M{name: val}
-		e.ModuleFields["metrics"].(mapstr.M).Update(data)
+		addMetric(eventList, labels, metric.Timestamp, mapstr.M{name: val})
 	}
 
 	if p.metricsCount {
@@ -112,3 +112,27 @@ func (p *RemoteWriteEventGenerator) GenerateEvents(metrics model.Samples) map[st
 
 	return eventList
 }
+
+// addMetric adds the metrics to the event of the labels and timestamp, metrics
+// with the same labels and timestamp are joined in a single event.
+func addMetric(eventList map[string]mb.Event, labels mapstr.M, timestamp model.Time, data mapstr.M) {
+	labelsHash := labels.String() + timestamp.Time().String()
+	if _, ok := eventList[labelsHash]; !ok {
+		eventList[labelsHash] = mb.Event{
+			RootFields: mapstr.M{},
+			ModuleFields: mapstr.M{
+				"metrics": mapstr.M{},
+			},
+			Timestamp: timestamp.Time(),
+		}
+
+		// Add labels
+		if len(labels) > 0 {
+			eventList[labelsHash].ModuleFields["labels"] = labels
+		}
+	}
+
+	// Not checking anything here because we create these maps some lines before
+	e := eventList[labelsHash]
+	e.ModuleFields["metrics"].(mapstr.M).Update(data)
+}
@@ -24,14 +24,26 @@ import (
 	"github.com/gogo/protobuf/proto"
 	"github.com/golang/snappy"
 	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/model/histogram"
 	"github.com/prometheus/prometheus/prompb"
 
+	p "github.com/elastic/beats/v7/metricbeat/helper/prometheus"
 	serverhelper "github.com/elastic/beats/v7/metricbeat/helper/server"
 	httpserver "github.com/elastic/beats/v7/metricbeat/helper/server/http"
 	"github.com/elastic/beats/v7/metricbeat/mb"
 	"github.com/elastic/beats/v7/metricbeat/mb/parse"
 )
 
+const (
+	// TypeLabel is the reserved label holding the type of a metric, as set by
+	// Prometheus with type and unit labels enabled. Generators must remove it
+	// from the labels of the events.
+	TypeLabel = "__type__"
+	// GaugeHistogramType is the type of native gauge histograms. protoToSamples
+	// sets it for the histograms sent with the gauge reset hint.
+	GaugeHistogramType = "gaugehistogram"
+)
+
 func init() {
 	mb.Registry.MustAddMetricSet("prometheus", "remote_write",
 		MetricSetBuilder(DefaultRemoteWriteEventsGeneratorFactory),
@@ -199,6 +211,25 @@ func protoToSamples(req *prompb.WriteRequest) model.Samples {
 				Timestamp: model.Time(s.Timestamp),
 			})
 		}
+
+		for _, h := range ts.Histograms {
+			var fh *histogram.FloatHistogram
+			if h.IsFloatHistogram() {
+				fh = h.ToFloatHistogram()
+			} else {
+				fh = h.ToIntHistogram().ToFloat(nil)
+			}
+			histogramMetric := metric
+			if fh.CounterResetHint == histogram.GaugeType {
+				histogramMetric = metric.Clone()
+				histogramMetric[TypeLabel] = GaugeHistogramType
+			}
+			samples = append(samples, &model.Sample{
+				Metric:    histogramMetric,
+				Histogram: p.ToSampleHistogram(fh),
+				Timestamp: model.Time(h.Timestamp),
+			})
+		}
 	}
 	return samples
 }
@@ -18,10 +18,16 @@
 package remote_write
 
 import (
+	"os"
 	"testing"
 
+	"github.com/gogo/protobuf/proto"
+	"github.com/golang/snappy"
 	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/model/histogram"
+	"github.com/prometheus/prometheus/prompb"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/elastic/elastic-agent-libs/mapstr"
 )
@@ -263,3 +269,105 @@ func TestMetricsCount(t *testing.T) {
 		})
 	}
 }
+
+// TestGenerateEventsNativeHistograms tests that native histograms of a recorded
+// remote write request are reported as classic histograms
+func TestGenerateEventsNativeHistograms(t *testing.T) {
+	compressed, err := os.ReadFile("_meta/testdata/native-histogram.snappy")
+	require.NoError(t, err)
+	reqBuf, err := snappy.Decode(nil, compressed)
+	require.NoError(t, err)
+	var req prompb.WriteRequest
+	require.NoError(t, proto.Unmarshal(reqBuf, &req))
+
+	g := RemoteWriteEventGenerator{}
+	events := g.GenerateEvents(protoToSamples(&req))
+
+	timestamp := model.Time(424242)
+	expected := []mapstr.M{
+		{
+			"metrics": mapstr.M{"up": float64(1), "temperature_delta_sum": float64(-3), "temperature_delta_count": float64(3)},
+		},
+		{
+			"metrics": mapstr.M{"temperature_delta_bucket": float64(1)},
+			"labels":  mapstr.M{"le": model.LabelValue("-1")},
+		},
+		{
+			"metrics": mapstr.M{"temperature_delta_bucket": float64(3)},
+			"labels":  mapstr.M{"le": model.LabelValue("1")},
+		},
+		{
+			"metrics": mapstr.M{"http_request_duration_seconds_sum": float64(4.5), "http_request_duration_seconds_count": float64(6)},
+			"labels":  mapstr.M{"handler": model.LabelValue("/metrics")},
+		},
+		{
+			"metrics": mapstr.M{"http_request_duration_seconds_bucket": float64(1)},
+			"labels":  mapstr.M{"handler": model.LabelValue("/metrics"), "le": model.LabelValue("0.001")},
+		},
+		{
+			"metrics": mapstr.M{"http_request_duration_seconds_bucket": float64(3)},
+			"labels":  mapstr.M{"handler": model.LabelValue("/metrics"), "le": model.LabelValue("1")},
+		},
+		{
+			"metrics": mapstr.M{"http_request_duration_seconds_bucket": float64(4)},
+			"labels":  mapstr.M{"handler": model.LabelValue("/metrics"), "le": model.LabelValue("2")},
+		},
+		{
+			"metrics": mapstr.M{"http_request_duration_seconds_bucket": float64(6)},
+			"labels":  mapstr.M{"handler": model.LabelValue("/metrics"), "le": model.LabelValue("8")},
+		},
+	}
+
+	assert.Equal(t, len(expected), len(events))
+	for _, fields := range expected {
+		labels, _ := fields["labels"].(mapstr.M)
+		if labels == nil {
+			labels = mapstr.M{}
+		}
+		e, found := events[labels.String()+timestamp.Time().String()]
+		if assert.True(t, found, "no event for labels %v", labels) {
+			assert.EqualValues(t, fields, e.ModuleFields)
+			assert.EqualValues(t, timestamp.Time(), e.Timestamp)
+		}
+	}
+}
+
+// TestProtoToSamplesGaugeHistogram tests that native gauge histograms are
+// marked with the type label, which is not reported
+func TestProtoToSamplesGaugeHistogram(t *testing.T) {
+	fh := &histogram.FloatHistogram{
+		CounterResetHint: histogram.GaugeType,
+		Schema:           0,
+		Count:            3,
+		Sum:              -3,
+		PositiveSpans:    []histogram.Span{{Offset: 0, Length: 1}},
+		PositiveBuckets:  []float64{1},
+		NegativeSpans:    []histogram.Span{{Offset: 0, Length: 1}},
+		NegativeBuckets:  []float64{2},
+	}
+	req := prompb.WriteRequest{
+		Timeseries: []prompb.TimeSeries{
+			{
+				Labels:     []prompb.Label{{Name: "__name__", Value: "temperature_delta"}},
+				Histograms: []prompb.Histogram{prompb.FromFloatHistogram(424242, fh)},
+			},
+			{
+				Labels:     []prompb.Label{{Name: "__name__", Value: "http_request_duration_seconds"}},
+				Histograms: []prompb.Histogram{prompb.FromFloatHistogram(424242, &histogram.FloatHistogram{Count: 1, Sum: 1})},
+			},
+		},
+	}
+
+	samples := protoToSamples(&req)
+	require.Len(t, samples, 2)
+	assert.Equal(t, model.LabelValue(GaugeHistogramType), samples[0].Metric[TypeLabel])
+	assert.NotContains(t, samples[1].Metric, model.LabelName(TypeLabel))
+
+	g := RemoteWriteEventGenerator{}
+	events := g.GenerateEvents(samples)
+	require.NotEmpty(t, events)
+	for _, e := range events {
+		labels, _ := e.ModuleFields["labels"].(mapstr.M)
+		assert.NotContains(t, labels, TypeLabel)
+	}
+}
@@ -0,0 +1,72 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package cluster
+
+import (
+	"fmt"
+
+	"github.com/elastic/beats/v7/metricbeat/mb"
+	"github.com/elastic/beats/v7/metricbeat/mb/parse"
+	"github.com/elastic/beats/v7/metricbeat/module/redis"
+)
+
+var hostParser = parse.URLHostParserBuilder{DefaultScheme: "redis"}.Build()
+
+func init() {
+	mb.Registry.MustAddMetricSet("redis", "cluster", New,
+		mb.WithHostParser(hostParser),
+	)
+}
+
+// MetricSet for fetching the state of a Redis Cluster.
+type MetricSet struct {
+	*redis.MetricSet
+}
+
+// New creates new instance of MetricSet
+func New(base mb.BaseMetricSet) (mb.MetricSet, error) {
+	ms, err := redis.NewMetricSet(base)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create 'cluster' metricset: %w", err)
+	}
+	return &MetricSet{ms}, nil
+}
+
+// Fetch fetches the state of the cluster as seen by the configured host, by
+// issuing the CLUSTER INFO and CLUSTER NODES commands.
+func (m *MetricSet) Fetch(r mb.ReporterV2) error {
+	conn := m.Connection()
+	defer func() {
+		if err := conn.Close(); err != nil {
+			m.Logger().Debug(fmt.Errorf("failed to release connection: %w", err))
+		}
+	}()
+
+	info, err := redis.FetchClusterInfo(conn)
+	if err != nil {
+		return fmt.Errorf("failed to fetch cluster info: %w", err)
+	}
+
+	nodes, err := redis.FetchClusterNodes(conn)
+	if err != nil {
+		return fmt.Errorf("failed to fetch cluster nodes: %w", err)
+	}
+
+	m.Logger().Debugf("Redis CLUSTER INFO from %s: %+v", m.Host(), info)
+	return eventMapping(r, info, nodes)
+}
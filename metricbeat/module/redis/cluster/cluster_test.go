@@ -0,0 +1,119 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+//go:build !integration
+
+package cluster
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	mbtest "github.com/elastic/beats/v7/metricbeat/mb/testing"
+	"github.com/elastic/beats/v7/metricbeat/module/redis/redistest"
+	"github.com/elastic/elastic-agent-libs/mapstr"
+)
+
+const clusterInfo = "cluster_state:ok\r\n" +
+	"cluster_slots_assigned:16384\r\n" +
+	"cluster_slots_ok:10923\r\n" +
+	"cluster_slots_pfail:0\r\n" +
+	"cluster_slots_fail:5461\r\n" +
+	"cluster_known_nodes:6\r\n" +
+	"cluster_size:3\r\n" +
+	"cluster_current_epoch:6\r\n" +
+	"cluster_my_epoch:2\r\n" +
+	"cluster_stats_messages_sent:1483972\r\n" +
+	"cluster_stats_messages_received:1483968\r\n"
+
+const clusterNodes = `07c37dfeb235213a872192d90877d0cd55635b91 127.0.0.1:30004@31004 slave,fail? e7d1eecce10fd6bb5eb35b9f99a514335d9ba9ca 0 1426238317239 4 connected
+67ed2db8d677e59ec4a4cefb06858cf2a1a89fa1 127.0.0.1:30002@31002 master - 0 1426238316232 2 connected 5461-10922 [5461->-292f8b365bb7edb5e285caf0b7e6ddc7265d2f4f] [5462->-292f8b365bb7edb5e285caf0b7e6ddc7265d2f4f]
+292f8b365bb7edb5e285caf0b7e6ddc7265d2f4f 127.0.0.1:30003@31003 master,fail - 0 1426238318243 3 connected 10923-16383 [5461-<-67ed2db8d677e59ec4a4cefb06858cf2a1a89fa1] [5462-<-67ed2db8d677e59ec4a4cefb06858cf2a1a89fa1]
+6ec23923021cf3ffec47632106199cb7f496ce01 127.0.0.1:30005@31005 slave 67ed2db8d677e59ec4a4cefb06858cf2a1a89fa1 0 1426238316232 5 connected
+824fe116063bc5fcf9f4ffd895bc17aee7731ac3 127.0.0.1:30006@31006 slave 292f8b365bb7edb5e285caf0b7e6ddc7265d2f4f 0 1426238317741 6 connected
+e7d1eecce10fd6bb5eb35b9f99a514335d9ba9ca 127.0.0.1:30001@31001 myself,master - 0 0 1 connected 0-5460
+`
+
+func TestFetch(t *testing.T) {
+	server := redistest.NewServer(t, func(args []string) interface{} {
+		if len(args) == 2 && args[0] == "CLUSTER" {
+			switch args[1] {
+			case "INFO":
+				return clusterInfo
+			case "NODES":
+				return clusterNodes
+			}
+		}
+		return redistest.ErrUnknownCommand(args)
+	})
+
+	ms := mbtest.NewReportingMetricSetV2Error(t, map[string]interface{}{
+		"module":     "redis",
+		"metricsets": []string{"cluster"},
+		"hosts":      []string{server.Addr()},
+	})
+
+	events, errs := mbtest.ReportingFetchV2Error(ms)
+	require.Empty(t, errs)
+	require.Len(t, events, 1)
+
+	assert.Equal(t, mapstr.M{
+		"state":         "ok",
+		"size":          int64(3),
+		"current_epoch": int64(6),
+		"slots": mapstr.M{
+			"assigned": int64(16384),
+			"ok":       int64(10923),
+			"pfail":    int64(0),
+			"fail":     int64(5461),
+			"coverage": mapstr.M{
+				"pct": 10923.0 / 16384,
+			},
+		},
+		"nodes": mapstr.M{
+			"known":             int64(6),
+			"masters":           3,
+			"replicas":          3,
+			"failing":           1,
+			"pfailing":          1,
+			"failing_addresses": []string{"127.0.0.1:30003", "127.0.0.1:30004"},
+		},
+		"migrations": mapstr.M{
+			"count": 2,
+			"slots": []int{5461, 5462},
+		},
+	}, events[0].MetricSetFields)
+}
+
+func TestFetchClusterDisabled(t *testing.T) {
+	server := redistest.NewServer(t, func(args []string) interface{} {
+		return redistest.ErrUnknownCommand(args)
+	})
+
+	ms := mbtest.NewReportingMetricSetV2Error(t, map[string]interface{}{
+		"module":     "redis",
+		"metricsets": []string{"cluster"},
+		"hosts":      []string{server.Addr()},
+	})
+
+	events, errs := mbtest.ReportingFetchV2Error(ms)
+	assert.Empty(t, events)
+	require.Len(t, errs, 1)
+	assert.ErrorContains(t, errs[0], "failed to fetch cluster info")
+}
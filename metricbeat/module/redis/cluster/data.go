@@ -0,0 +1,112 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package cluster
+
+import (
+	"fmt"
+	"sort"
+
+	s "github.com/elastic/beats/v7/libbeat/common/schema"
+	c "github.com/elastic/beats/v7/libbeat/common/schema/mapstrstr"
+	"github.com/elastic/beats/v7/metricbeat/mb"
+	"github.com/elastic/beats/v7/metricbeat/module/redis"
+	"github.com/elastic/elastic-agent-libs/mapstr"
+)
+
+var schema = s.Schema{
+	"state":         c.Str("cluster_state"),
+	"size":          c.Int("cluster_size"),
+	"current_epoch": c.Int("cluster_current_epoch"),
+	"slots": s.Object{
+		"assigned": c.Int("cluster_slots_assigned"),
+		"ok":       c.Int("cluster_slots_ok"),
+		"pfail":    c.Int("cluster_slots_pfail"),
+		"fail":     c.Int("cluster_slots_fail"),
+	},
+	"nodes": s.Object{
+		"known": c.Int("cluster_known_nodes"),
+	},
+}
+
+// eventMapping maps the cluster info and nodes to an event.
+func eventMapping(r mb.ReporterV2, info map[string]string, nodes []redis.ClusterNode) error {
+	source := map[string]interface{}{}
+	for k, v := range info {
+		source[k] = v
+	}
+	data, err := schema.Apply(source)
+	if err != nil {
+		return fmt.Errorf("failed to apply schema: %w", err)
+	}
+
+	// Share of the hash slots served by nodes that are not failing.
+	if ok, err := data.GetValue("slots.ok"); err == nil {
+		if ok, isInt := ok.(int64); isInt {
+			data.Put("slots.coverage.pct", float64(ok)/redis.ClusterSlots)
+		}
+	}
+
+	var masters, replicas, failing, pfailing int
+	failingAddresses := []string{}
+	migratingSlots := []int{}
+	for _, node := range nodes {
+		switch {
+		case node.HasFlag("master"):
+			masters++
+		case node.HasFlag("slave"):
+			replicas++
+		}
+		switch {
+		case node.HasFlag("fail"):
+			failing++
+		case node.HasFlag("fail?"):
+			pfailing++
+		}
+		if node.IsFailing() && node.Address != "" {
+			failingAddresses = append(failingAddresses, node.Address)
+		}
+		for _, migration := range node.Migrating {
+			migratingSlots = append(migratingSlots, migration.Slot)
+		}
+	}
+	sort.Strings(failingAddresses)
+	sort.Ints(migratingSlots)
+
+	data.DeepUpdate(mapstr.M{
+		"nodes": mapstr.M{
+			"masters":  masters,
+			"replicas": replicas,
+			"failing":  failing,
+			"pfailing": pfailing,
+		},
+		"migrations": mapstr.M{
+			"count": len(migratingSlots),
+		},
+	})
+	if len(failingAddresses) > 0 {
+		data.Put("nodes.failing_addresses", failingAddresses)
+	}
+	if len(migratingSlots) > 0 {
+		data.Put("migrations.slots", migratingSlots)
+	}
+
+	r.Event(mb.Event{
+		MetricSetFields: data,
+	})
+	return nil
+}
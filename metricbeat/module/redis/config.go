@@ -19,6 +19,7 @@ package redis
 
 import (
 	"crypto/tls"
+	"fmt"
 	"time"
 
 	"github.com/elastic/elastic-agent-libs/transport/tlscommon"
@@ -29,10 +30,39 @@ type Config struct {
 	Network     string            `config:"network"`
 	MaxConn     int               `config:"maxconn" validate:"min=1"`
 	TLS         *tlscommon.Config `config:"ssl"`
+	Discovery   DiscoveryConfig   `config:"discovery"`
 
 	UseTLSConfig *tls.Config
 }
 
+// DiscoveryConfig configures the discovery of the nodes of a Redis Cluster, or
+// of the masters and replicas monitored by Sentinel, from the configured host.
+type DiscoveryConfig struct {
+	// Mode is the discovery mode, cluster or sentinel. Discovery is
+	// disabled by default.
+	Mode string `config:"mode"`
+	// Masters are the names of the masters monitored by Sentinel to collect
+	// from. All masters are collected by default.
+	Masters []string `config:"masters"`
+	// Username and Password used to connect to the discovered nodes, the
+	// ones of the configured host are used by default.
+	Username string `config:"username"`
+	Password string `config:"password"`
+}
+
+// Validate validates the discovery configuration.
+func (c *DiscoveryConfig) Validate() error {
+	switch c.Mode {
+	case "", DiscoveryCluster, DiscoverySentinel:
+	default:
+		return fmt.Errorf("invalid discovery mode %q, must be %s or %s", c.Mode, DiscoveryCluster, DiscoverySentinel)
+	}
+	if len(c.Masters) > 0 && c.Mode != DiscoverySentinel {
+		return fmt.Errorf("discovery masters can only be set with %s discovery mode", DiscoverySentinel)
+	}
+	return nil
+}
+
 // DefaultConfig return default config for the redis module.
 func DefaultConfig() Config {
 	return Config{Network: "tcp", MaxConn: 10}
@@ -0,0 +1,316 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package redis
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"slices"
+	"strconv"
+	"strings"
+
+	rd "github.com/gomodule/redigo/redis"
+)
+
+// Discovery modes
+const (
+	DiscoveryCluster  = "cluster"
+	DiscoverySentinel = "sentinel"
+)
+
+// Node roles
+const (
+	RoleMaster  = "master"
+	RoleReplica = "replica"
+)
+
+// ClusterSlots is the number of hash slots of a Redis Cluster.
+const ClusterSlots = 16384
+
+// Node is a data node discovered from the topology of a Redis Cluster or of
+// the masters monitored by Sentinel.
+type Node struct {
+	ID      string
+	Address string
+	Role    string
+	// Shard identifies the master and replicas holding the same data, it
+	// is the ID of the master in a cluster and the master name in Sentinel.
+	Shard string
+}
+
+// ClusterNode is a node as listed by CLUSTER NODES.
+type ClusterNode struct {
+	ID        string
+	Address   string
+	Hostname  string
+	Flags     []string
+	MasterID  string
+	LinkState string
+	Slots     []SlotRange
+	Migrating []SlotMigration
+	Importing []SlotMigration
+}
+
+// SlotRange is a range of hash slots served by a cluster node.
+type SlotRange struct {
+	Start, End int
+}
+
+// SlotMigration is a hash slot being migrated to or imported from NodeID.
+type SlotMigration struct {
+	Slot   int
+	NodeID string
+}
+
+// HasFlag returns true if the node has the flag.
+func (n ClusterNode) HasFlag(flag string) bool {
+	return slices.Contains(n.Flags, flag)
+}
+
+// IsFailing returns true if the node is failing, or possibly failing.
+func (n ClusterNode) IsFailing() bool {
+	return n.HasFlag("fail") || n.HasFlag("fail?")
+}
+
+// FetchClusterInfo returns the values returned by CLUSTER INFO.
+func FetchClusterInfo(c rd.Conn) (map[string]string, error) {
+	out, err := rd.String(c.Do("CLUSTER", "INFO"))
+	if err != nil {
+		return nil, err
+	}
+	return ParseRedisInfo(out), nil
+}
+
+// FetchClusterNodes returns the nodes returned by CLUSTER NODES.
+func FetchClusterNodes(c rd.Conn) ([]ClusterNode, error) {
+	out, err := rd.String(c.Do("CLUSTER", "NODES"))
+	if err != nil {
+		return nil, err
+	}
+	return ParseClusterNodes(out)
+}
+
+// ParseClusterNodes parses the output of CLUSTER NODES, one node per line:
+// <id> <ip:port@cport[,hostname]> <flags> <master> <ping-sent> <pong-recv> <config-epoch> <link-state> <slot> ... <slot>
+func ParseClusterNodes(s string) ([]ClusterNode, error) {
+	var nodes []ClusterNode
+	for _, line := range strings.Split(s, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		if len(fields) < 8 {
+			return nil, fmt.Errorf("invalid cluster node line %q", line)
+		}
+
+		node := ClusterNode{
+			ID:        fields[0],
+			Flags:     strings.Split(fields[2], ","),
+			LinkState: fields[7],
+		}
+		if fields[3] != "-" {
+			node.MasterID = fields[3]
+		}
+
+		address, hostname, _ := strings.Cut(fields[1], ",")
+		address, _, _ = strings.Cut(address, "@")
+		if host, port, err := net.SplitHostPort(address); err == nil && host != "" && port != "0" {
+			node.Address = address
+		}
+		node.Hostname = hostname
+
+		for _, slot := range fields[8:] {
+			if err := node.addSlot(slot); err != nil {
+				return nil, fmt.Errorf("invalid slot %q of cluster node %s: %w", slot, node.ID, err)
+			}
+		}
+		nodes = append(nodes, node)
+	}
+	return nodes, nil
+}
+
+// addSlot adds a slot, a range of slots or a slot migration to the node.
+func (n *ClusterNode) addSlot(slot string) error {
+	if migration, ok := strings.CutPrefix(slot, "["); ok {
+		migration = strings.TrimSuffix(migration, "]")
+		if s, nodeID, ok := strings.Cut(migration, "->-"); ok {
+			slot, err := strconv.Atoi(s)
+			if err != nil {
+				return err
+			}
+			n.Migrating = append(n.Migrating, SlotMigration{Slot: slot, NodeID: nodeID})
+			return nil
+		}
+		if s, nodeID, ok := strings.Cut(migration, "-<-"); ok {
+			slot, err := strconv.Atoi(s)
+			if err != nil {
+				return err
+			}
+			n.Importing = append(n.Importing, SlotMigration{Slot: slot, NodeID: nodeID})
+			return nil
+		}
+		return errors.New("unknown slot migration format")
+	}
+
+	start, end, isRange := strings.Cut(slot, "-")
+	first, err := strconv.Atoi(start)
+	if err != nil {
+		return err
+	}
+	last := first
+	if isRange {
+		last, err = strconv.Atoi(end)
+		if err != nil {
+			return err
+		}
+	}
+	n.Slots = append(n.Slots, SlotRange{Start: first, End: last})
+	return nil
+}
+
+// ClusterTopology returns the data nodes of a cluster that can be connected
+// to, skipping the failing nodes and the nodes without address or still in
+// handshake.
+func ClusterTopology(nodes []ClusterNode) []Node {
+	var topology []Node
+	for _, n := range nodes {
+		if n.Address == "" || n.IsFailing() || n.HasFlag("handshake") || n.HasFlag("noaddr") {
+			continue
+		}
+		node := Node{ID: n.ID, Address: n.Address}
+		if n.HasFlag("master") {
+			node.Role = RoleMaster
+			node.Shard = n.ID
+		} else {
+			node.Role = RoleReplica
+			node.Shard = n.MasterID
+		}
+		topology = append(topology, node)
+	}
+	return topology
+}
+
+// FetchSentinelTopology returns the masters monitored by the Sentinel, and
+// their replicas, skipping the ones that are down. If masters is not empty
+// only the masters with these names are returned.
+func FetchSentinelTopology(c rd.Conn, masters []string) ([]Node, error) {
+	replies, err := rd.Values(c.Do("SENTINEL", "MASTERS"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch sentinel masters: %w", err)
+	}
+
+	var topology []Node
+	for _, reply := range replies {
+		master, err := rd.StringMap(reply, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse sentinel master: %w", err)
+		}
+		name := master["name"]
+		if len(masters) > 0 && !slices.Contains(masters, name) {
+			continue
+		}
+		if node, ok := sentinelNode(master, RoleMaster, name); ok {
+			topology = append(topology, node)
+		}
+
+		replicas, err := rd.Values(c.Do("SENTINEL", "REPLICAS", name))
+		if err != nil {
+			// SENTINEL REPLICAS was added in Redis 5.0.
+			replicas, err = rd.Values(c.Do("SENTINEL", "SLAVES", name))
+			if err != nil {
+				return nil, fmt.Errorf("failed to fetch replicas of sentinel master %s: %w", name, err)
+			}
+		}
+		for _, reply := range replicas {
+			replica, err := rd.StringMap(reply, nil)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse replica of sentinel master %s: %w", name, err)
+			}
+			if node, ok := sentinelNode(replica, RoleReplica, name); ok {
+				topology = append(topology, node)
+			}
+		}
+	}
+	return topology, nil
+}
+
+// sentinelNode returns the node of a master or replica as returned by
+// Sentinel, it returns false if the node is down.
+func sentinelNode(values map[string]string, role, shard string) (Node, bool) {
+	for _, flag := range strings.Split(values["flags"], ",") {
+		switch flag {
+		case "s_down", "o_down", "disconnected":
+			return Node{}, false
+		}
+	}
+	if values["ip"] == "" || values["port"] == "" {
+		return Node{}, false
+	}
+	return Node{
+		ID:      values["runid"],
+		Address: net.JoinHostPort(values["ip"], values["port"]),
+		Role:    role,
+		Shard:   shard,
+	}, true
+}
+
+// nodePools keeps a connection pool per discovered node, following the
+// changes of the topology.
+type nodePools struct {
+	pools   map[string]*Pool
+	newPool func(address string) *Pool
+}
+
+func newNodePools(newPool func(address string) *Pool) *nodePools {
+	return &nodePools{
+		pools:   map[string]*Pool{},
+		newPool: newPool,
+	}
+}
+
+// update creates the pools of the new nodes and closes the ones of the nodes
+// no longer in the topology.
+func (p *nodePools) update(nodes []Node) error {
+	current := make(map[string]struct{}, len(nodes))
+	for _, node := range nodes {
+		current[node.Address] = struct{}{}
+		if _, ok := p.pools[node.Address]; !ok {
+			p.pools[node.Address] = p.newPool(node.Address)
+		}
+	}
+
+	var errs []error
+	for address, pool := range p.pools {
+		if _, ok := current[address]; !ok {
+			errs = append(errs, pool.Close())
+			delete(p.pools, address)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// get returns a connection to the node.
+func (p *nodePools) get(address string) rd.Conn {
+	return p.pools[address].Get()
+}
+
+// close closes the pools of all nodes.
+func (p *nodePools) close() error {
+	return p.update(nil)
+}
@@ -0,0 +1,169 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+//go:build !integration
+
+package redis
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/elastic/beats/v7/metricbeat/module/redis/redistest"
+)
+
+const clusterNodes = `07c37dfeb235213a872192d90877d0cd55635b91 127.0.0.1:30004@31004,replica-1 slave e7d1eecce10fd6bb5eb35b9f99a514335d9ba9ca 0 1426238317239 4 connected
+67ed2db8d677e59ec4a4cefb06858cf2a1a89fa1 127.0.0.1:30002@31002 master - 0 1426238316232 2 connected 5461-10922 [5461->-292f8b365bb7edb5e285caf0b7e6ddc7265d2f4f]
+292f8b365bb7edb5e285caf0b7e6ddc7265d2f4f 127.0.0.1:30003@31003 master - 0 1426238318243 3 connected 10923-16383 [5461-<-67ed2db8d677e59ec4a4cefb06858cf2a1a89fa1]
+6ec23923021cf3ffec47632106199cb7f496ce01 127.0.0.1:30005@31005 slave 67ed2db8d677e59ec4a4cefb06858cf2a1a89fa1 0 1426238316232 5 connected
+824fe116063bc5fcf9f4ffd895bc17aee7731ac3 127.0.0.1:30006@31006 slave,fail 292f8b365bb7edb5e285caf0b7e6ddc7265d2f4f 0 1426238317741 6 disconnected
+e7d1eecce10fd6bb5eb35b9f99a514335d9ba9ca 127.0.0.1:30001@31001 myself,master - 0 0 1 connected 0-5460 12000
+a1b2c3d4e5f60718293a4b5c6d7e8f9012345678 :0@0 master,noaddr - 1426238316232 1426238316232 0 disconnected
+`
+
+func TestParseClusterNodes(t *testing.T) {
+	nodes, err := ParseClusterNodes(clusterNodes)
+	require.NoError(t, err)
+	require.Len(t, nodes, 7)
+
+	assert.Equal(t, ClusterNode{
+		ID:        "07c37dfeb235213a872192d90877d0cd55635b91",
+		Address:   "127.0.0.1:30004",
+		Hostname:  "replica-1",
+		Flags:     []string{"slave"},
+		MasterID:  "e7d1eecce10fd6bb5eb35b9f99a514335d9ba9ca",
+		LinkState: "connected",
+	}, nodes[0])
+
+	assert.Equal(t, []SlotRange{{Start: 5461, End: 10922}}, nodes[1].Slots)
+	assert.Equal(t, []SlotMigration{{Slot: 5461, NodeID: "292f8b365bb7edb5e285caf0b7e6ddc7265d2f4f"}}, nodes[1].Migrating)
+	assert.Equal(t, []SlotMigration{{Slot: 5461, NodeID: "67ed2db8d677e59ec4a4cefb06858cf2a1a89fa1"}}, nodes[2].Importing)
+	assert.True(t, nodes[4].IsFailing())
+	assert.Equal(t, []SlotRange{{Start: 0, End: 5460}, {Start: 12000, End: 12000}}, nodes[5].Slots)
+	assert.Empty(t, nodes[6].Address)
+
+	_, err = ParseClusterNodes("e7d1eecce10fd6bb5eb35b9f99a514335d9ba9ca 127.0.0.1:30001@31001 master")
+	assert.Error(t, err)
+
+	_, err = ParseClusterNodes("e7d1eecce10fd6bb5eb35b9f99a514335d9ba9ca 127.0.0.1:30001@31001 master - 0 0 1 connected a-b")
+	assert.Error(t, err)
+}
+
+func TestClusterTopology(t *testing.T) {
+	nodes, err := ParseClusterNodes(clusterNodes)
+	require.NoError(t, err)
+
+	assert.Equal(t, []Node{
+		{ID: "07c37dfeb235213a872192d90877d0cd55635b91", Address: "127.0.0.1:30004", Role: RoleReplica, Shard: "e7d1eecce10fd6bb5eb35b9f99a514335d9ba9ca"},
+		{ID: "67ed2db8d677e59ec4a4cefb06858cf2a1a89fa1", Address: "127.0.0.1:30002", Role: RoleMaster, Shard: "67ed2db8d677e59ec4a4cefb06858cf2a1a89fa1"},
+		{ID: "292f8b365bb7edb5e285caf0b7e6ddc7265d2f4f", Address: "127.0.0.1:30003", Role: RoleMaster, Shard: "292f8b365bb7edb5e285caf0b7e6ddc7265d2f4f"},
+		{ID: "6ec23923021cf3ffec47632106199cb7f496ce01", Address: "127.0.0.1:30005", Role: RoleReplica, Shard: "67ed2db8d677e59ec4a4cefb06858cf2a1a89fa1"},
+		{ID: "e7d1eecce10fd6bb5eb35b9f99a514335d9ba9ca", Address: "127.0.0.1:30001", Role: RoleMaster, Shard: "e7d1eecce10fd6bb5eb35b9f99a514335d9ba9ca"},
+	}, ClusterTopology(nodes))
+}
+
+func TestFetchSentinelTopology(t *testing.T) {
+	server := redistest.NewServer(t, func(args []string) interface{} {
+		switch {
+		case len(args) == 2 && args[0] == "SENTINEL" && args[1] == "MASTERS":
+			return []interface{}{
+				[]string{"name", "cache", "ip", "10.0.0.1", "port", "6379", "runid", "m1", "flags", "master"},
+				[]string{"name", "sessions", "ip", "10.0.1.1", "port", "6379", "runid", "m2", "flags", "master"},
+				[]string{"name", "queue", "ip", "10.0.2.1", "port", "6379", "runid", "m3", "flags", "master,o_down"},
+			}
+		case len(args) == 3 && args[0] == "SENTINEL" && args[1] == "SLAVES":
+			// Redis before 5.0 doesn't support SENTINEL REPLICAS.
+			switch args[2] {
+			case "cache":
+				return []interface{}{
+					[]string{"name", "10.0.0.2:6379", "ip", "10.0.0.2", "port", "6379", "runid", "r1", "flags", "slave"},
+					[]string{"name", "10.0.0.3:6379", "ip", "10.0.0.3", "port", "6379", "runid", "r2", "flags", "slave,s_down,disconnected"},
+				}
+			case "queue":
+				return []interface{}{
+					[]string{"name", "10.0.2.2:6379", "ip", "10.0.2.2", "port", "6379", "runid", "r3", "flags", "slave"},
+				}
+			}
+			return []interface{}{}
+		}
+		return redistest.ErrUnknownCommand(args)
+	})
+
+	pool := CreatePool(server.Addr(), "", "", 0, &Config{Network: "tcp", MaxConn: 1}, 0)
+	defer pool.Close()
+	conn := pool.Get()
+	defer conn.Close()
+
+	nodes, err := FetchSentinelTopology(conn, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []Node{
+		{ID: "m1", Address: "10.0.0.1:6379", Role: RoleMaster, Shard: "cache"},
+		{ID: "r1", Address: "10.0.0.2:6379", Role: RoleReplica, Shard: "cache"},
+		{ID: "m2", Address: "10.0.1.1:6379", Role: RoleMaster, Shard: "sessions"},
+		{ID: "r3", Address: "10.0.2.2:6379", Role: RoleReplica, Shard: "queue"},
+	}, nodes)
+
+	nodes, err = FetchSentinelTopology(conn, []string{"sessions"})
+	require.NoError(t, err)
+	assert.Equal(t, []Node{
+		{ID: "m2", Address: "10.0.1.1:6379", Role: RoleMaster, Shard: "sessions"},
+	}, nodes)
+}
+
+func TestNodePools(t *testing.T) {
+	config := DefaultConfig()
+	var created []string
+	pools := newNodePools(func(address string) *Pool {
+		created = append(created, address)
+		return CreatePool(address, "", "", 0, &config, 0)
+	})
+
+	require.NoError(t, pools.update([]Node{{Address: "10.0.0.1:6379"}, {Address: "10.0.0.2:6379"}}))
+	require.NoError(t, pools.update([]Node{{Address: "10.0.0.2:6379"}, {Address: "10.0.0.3:6379"}}))
+	assert.Equal(t, []string{"10.0.0.1:6379", "10.0.0.2:6379", "10.0.0.3:6379"}, created)
+	assert.Len(t, pools.pools, 2)
+	assert.Contains(t, pools.pools, "10.0.0.2:6379")
+	assert.Contains(t, pools.pools, "10.0.0.3:6379")
+
+	require.NoError(t, pools.close())
+	assert.Empty(t, pools.pools)
+}
+
+func TestDiscoveryConfigValidate(t *testing.T) {
+	cases := []struct {
+		config DiscoveryConfig
+		err    error
+	}{
+		{DiscoveryConfig{}, nil},
+		{DiscoveryConfig{Mode: DiscoveryCluster}, nil},
+		{DiscoveryConfig{Mode: DiscoverySentinel, Masters: []string{"cache"}}, nil},
+		{DiscoveryConfig{Mode: "proxy"}, errors.New(`invalid discovery mode "proxy", must be cluster or sentinel`)},
+		{DiscoveryConfig{Mode: DiscoveryCluster, Masters: []string{"cache"}}, errors.New("discovery masters can only be set with sentinel discovery mode")},
+	}
+
+	for _, c := range cases {
+		err := c.config.Validate()
+		if c.err == nil {
+			assert.NoError(t, err)
+		} else {
+			assert.EqualError(t, err, c.err.Error())
+		}
+	}
+}
@@ -32,5 +32,5 @@ func init() {
 // AssetRedis returns asset data.
 // This is the base64 encoded zlib format compressed contents of module/redis.
 func AssetRedis() string {
-	return "eJzsXN+P2zbyf89fMdjvQ7dFVvlegbuHRVEgP3tB02Sxm+DQJy1FjWzWFKmSlDfuX38gKcmyLEry2nK2wPkhwdoS5zPD+UkOeQUr3FyDwpTpZwCGGY7XcHFr/754BpCipooVhklxDT8/AwBwv0GORjGqgUrOkRpMIVMy9z9GzwAUciQar2FBngFkDHmqr937VyBIjlua9mM2hX1UybKovukhbD/37q17oFIYwoQGs0RgIpMqJ/ZZICIFbYhh2lh4u6Dspw2lDccO0nzZh2gAlUNmB5gOTKEplcAUko179P79x3ef7Ot5TkQatYbelWT96bLRZoVyhsLond9CHI1wtZ1wP6hjQUedZ/rA7ACSQjgl2XuihsWlWPT8OILMfj6WeYIKZFYjrIgxKTRc4lfKy5SJxc7XTis0J2vU33d52aLOyddYlqYoTZyUWYZqBvQfpFigNuDpAGfaAMmlxVsq5djZ42oYMROzAn7FFg6wIwOezChiuJTC2zv8M/r/AZEnXNLVWdREQ4HCKYa1TU/YqQnhHC5ffbj5dPMcXt1u//tw8+Xu3y3oAcsrtdmT+9GW5wZte5NDDRAFSfiAXBMpORLxONG+FymjxKD1ecQ4b9YBrmsAY+IrytOK7vXNF++xDpRXqTGN9Kb72haRpoRjGmdcEvM4qd1ttMHcIaRS6DLfxgKPXaNaowrbSo0xpkvGU4V9s3cGsAmhKzs/IoVCSYpa44CHcqBLPeCajgf7RaM6Vq4W4jkEG8Q6LNZe68kxl2pzWgPyYz4u6jtBrgkv8VB/7v3cNSQbg302OEGwn6UhHETj9d1QQDiX1lU5Me+khQH4Sod9wHzgP3ZgO7/qJ2TLAbGqjKJWGFmgIsaGMO2N9ZJEq4iAQs1Sl7mhAc3+woHw61gukKy+Ac83SFa1urWNYcos8ZJ8A8RfNKY14moSPpQEUCyYwBHEKTFEY5/TmN0wlujUAJiotEtmDnsX0lCW+c3M+jcvbs5yZgbz4KiQnNGuN9xCXOHmQaq+jGgCirdr5hJb8ETASDur8LBEUSuEQ2gzH4WELoOZTxt1psgiR2F8hmeNWQbhHxFybu3AkKB5sN7DamPsMcdKa1emtr6bCDY0o3OrwyunwIfyEmSKUMPWGKdouYuYjlUpBOsFf4K8+R0nC2A+ebZ+m2UVAPAAGvFaNfK/TGDBxwcZTrCO0vzK/hoqE9S6eTbuy8RhMEeBgTSjh0ZvhQNjeggTdBGmicd+XjYROmBCsKdxTwG117wJkOuE4gmAvq1zmwmwp3lXGPWwB8B7t2PEYZKH+VQ4s5BrLlw6FhqtUQ6tzyPbZurHxWoxPRVhNrB3x+qt5wpUmmmDgnYdxKlWRQ6t6Lgk6TnDoU1LLU2bohJIy7yAjHG08VCKq4Xsx/J/8Fm+kZDLNcJ9Bfne5mj1H1G1GnXvMgSSpiDNElXNnpcNJG6qfO11qQ1RBgzL8TkYV1q6CXzu3qkt4zlEUfT9eERUaXJwFJxSQSm5Zinq3W2HRJYGbt+8GlAnmBhlOdEm1mSNEV0SsUAda9Y/Gkwxq4km01q59VTBUfXKQbRxejERt53AmeG+LSRdXiXEFoeWnDYkLyx6h1WXlKLWWcndnFhQYX1p85AsHANMxIWSC4W96xIwwRIPYKVrkaTBPGKBe7D9DBhiymHY4dz0ANh3jk5d1jqxN7irVRLZL5k+1HYOI4302OCRlgOEJ/P2phplhDtb3GukUqTD4bpitNq5eeK81grXy28GRAxUeLCzG1lsYiniB8VMrZrsr6eQhPeuzVi4V1JcObh1peN21dJSWXFs9eDVm5CWh6szmZ05Fr389M7HomNCUXhDC+b1gRY9l4uFy1Cqsnyn8BypnbzSfWM3bpmooLRtaKJPr5nQdIlp+U1mgYgADw+Mc0gQGmwg61xh330wDVTmBUfTs1zbx/HfJSAE5ndaTKiZ/ZsFhQDPu3FhiF/fyxA9kVBwZ8NAxWObtU5jx0h031Hcb5h/9U3ONB6eBv6uWhlZT8z/Uo7JKUdbIE/b2A4xMstC7SufACsWft2I5dZKgiLeYcDWq08EfYMapABODGrXdahMWYBUtRuZNjmZ3ggaVZ1eZ1uncFSb/rI/ZKKddW07Ot6/+AR/lti7g9oFnyInm8dvbEwNq55KBZ3KUpiQAWzjZsEZ7QvpRyxPhoYcW5lUkoe3o4/a8bqVvPENTGhDbD55SYmwaeZFTrRBdfHcauaF6yi9CPUIQl9DbOy7UIPQT9LwWBODDrEgPKuqXC6i4O7UseC6+XxLl2riPZueIZgBF3eeFqPeXoo+fgItuV1mMqa0ie1oscyyR/SITOwB8ZoLnsYJcC+ZNnygV+7xaO9C3Sr2y4mog/C9GKJZRe07Dr/TTWBuAw5Q7uDzhdu8GlGpQlnY9PZhyehyB+j7NxqIQiCUYtG31d6BzJlYhXP2E3jmTp7OxAouy+JFKh/E96PgbArOZFwVxDFZhHttRjzIQF17kJuuoHR3N5iNxIRWqxtmWXEwqjA2dxheZjrBnl3T++3wVi6FabDUXZ3g6yQXdyYh5ph55/cU2i4tGEgwkwobjlprRtMYeuqK5pTMKCJ0hsplplWNR+Du94+vD1lLdtM8ryvd95y1/TviTYY2grFQTCpmwu2Bx6Gsh9/LG4kGApSIlKXWaDKpICOMy/WAQXvETMcKSSoFD4OeY/u9EqvrY0yvdsj3VgU+1p2qILhzo3XP5sGEgmCNSvfbigdDOCN9bqIgZum5YBSj8Cg5W3izuAajegu5NmvjtrNgJtZL8o8jQ+U0QilTA7p/KkpJyXgas/Cpo1MRymV6bPE3TkSGI9KwLkkdZSXnZ9AhougyTthAj+XJJF5ywwqOX5lYxKRg82stpfGYSZ+KVnXeZkhzh2e8GiAqekc49ayrUpzDyAwt4kKqowL7OJWyCLTonJDG8q95x+eqjCmX9OBTNIeRoVJkbBFn7OiVr5GI3tO1fWQD4jGHyN0JaIUU2fq4g8L92Vvn2Fj7EHNNNHzuZBfiH0cfeX8ERE900hKku2lAx/XxwnMg9SSbE41TcAo0kTts/rhDJlNBonmQalUda68Xm8IzbVH5M/tngVVdD7CPKwjQlxyGCJSljmSh4wJV3L+Nf8ql5u4MQ4GqqjgnYvUXGKySYs7T17ZKq4T7na9tQNmKbIvWCvvXVy/6JBaQsb8q4qzA/QbtOPJwcbkRNJSenqIS3hq/JQIKLUHtF7ICWx870AqiDCM8kkcF00kA63VNqGhWYEHhnyXqnqS6FyiqOW7d2EWaomATcAYBr3CjI/xaMDXLVRtdt7/CDThqftkG1z2X03TBrdns18VUNBxBSEsEIyEnX9vnN6eJsiAUo+VQ2XUKuK3OcS7lqiwq0ep6FyQnTEDqD6aSgZObDeScaT3z1mNGGMf0QMDhwqxMdJm4wwcC+RzIf+Ey2dHdokxe6DKBmqb3XPVdSGXSjBhW6Ap1QYxB1fPcjKgrmhNAh4sZ14ARZ1Kt4nKe9GG/hdH1fGQu8Wl3L+aMKtltYQyvUbg6G2Pqzl/HWtIVzmKju865omORC7fA+9v7X25ffn4LRakKqadsv7vAGHsHrWOjCF1hGlvTmR29s8+KokPvUGwa8HBJCrcCn3AEKbg7+26zEPdFdaXWOIe7B61n953utgKb5bU62QpUmVTVJRPVuWu3sdw5e11lshNZOYNPJYlUNm71MeX6pLaXyASOkx/G0go38ewz5PVuSQw8oKqB800L+sCG8z7eM0xDB7FesaJ4lOS3Vi8fuFxErvmqd72lB/YI5Nd2LOeVuHzYOtORg6d1FXf6hZ9q5NZFiAcuAl38EFHCububsvs5rSd0ZPwEV1d4NOjxK9LSzeelkKZZdBm4WOfih8gGz7lQ+xOpr2++uBOH3bukNDYzOorQrRJY1sNQjyxeyRoVWeDupVe2eN2T7iDYWujxmdShplfpxfZCw39FP14p+uPw5Ps8+FxYq6x7HGmrJHjWBXPI1acr3LRuPt0/8uVqutYrh19pav/tdUX9K9wjIvsVN27EkQvV9nZVjiD5RbA/SwTmsyqzZNrVxZf/sam5jRhWZvBTXZz9fP2TxfDz2IWTFtFp5WJfd3eLLeWDu1zs/vPvN297bqbtxcNRLMzyRNHrgxusLgucuFrlO8fc3eNp5cmZNvp5Rd19o41iYqGfAyUqZYJwZjb+BzR6TKo+9Y6M6S6LPZaTu6rlxsh67D4bdDN/rCG6QYbuIXYWWQnUPTzvZcSnNKJfK8Tg7tBgGcPQBURNarhexKebxpdV7DKGj9DtKdYeS7STclaLJ80qzhRNfiyU/wYAAP//elnqRA=="
+	return "eJzknW2P2zbywN/7UxD7f9FNsav827srDouiQB7boGkS7CY49JVMSWObNU2qJLUb99MfhqRkWRYl+UHeLe5ucZesZc5vhsMhZ0Qy12QJ6xuiIGN6QohhhsMNubjFv19MCMlAp4rlhklxQ36aEEKI/YyswCiWapJKziE1kJGZkiv3YTQhRAEHquGGzOmEkBkDnukb+/1rIugKNjLxv2ad46NKFrn/TYtg/Jnab01JKoWhTGhiFkCYmEm1oghJqMiINtQwbRBvG4qQbZQ6jpAZVL9sI+qgwp8PMgNLA/cgDHmgTdtckYcFCCtHE6qAZEyn8h5UaTvqTfuKF9qAIlKROxCGCeAlfJsCdSVYtvXrUo0lrB+kan7WoQz+vHtN5MxqhMiECfvn1MFdIZ0qRPMpqomCXCpUOlnX+FtxleRwOuBbyaEOc0WmK4qwU4SdKsg5S+k0wKIXVGWng7nD5rZp8E8bczk0okHdMzG3FtR0BWRB9YJoLo1Gk9Mtg+ND2NGNNlZSMCNV0+RNDX1Lxzj51LfRGIA44Kzpmy5MNdEAgiRr+1wqxYzNCyRdSG3qXl3FiwQMHejtVuwJu6zUYsvPp3I5JWzTjySlwvYakD8LUAz0FZnOKOPlUwrs6C4E1ZrNBWTYc/gAdvOmdzfKt+vG/mofGlyK+X56fShWCYaTWekvqIauPI8agqY3RIqa9/XgpYVSIEwMuUwXJ+J85dokts2dfig0xklvSgybvRZEK7eiNR1+ANsvVb81wDYQYX+tU5VOsfNAh90G8G3388bLKnnESEJtjA6YrQ4pl4+HR8yCGsI0EdKUw2YAco6PPgY1Mmsy43Q+h4xQTXKpNUv4eg/4x2L3hNYt9ABOu1yhc4jy1AR5dUo5ZPGMS9r2kFuq3ZAcVArCHKYZzq5VqP7uh3/8+591FTGyucnQKuY8Cr/Q71KlpvaLkzb1DggeuDQ8Nm4shXwQQZufwkesBOcKV4SJlBdZ5Ru4FFlQkekFXULAdHVaN9HoUXnrk9kAJL/8G5fJCxkM5Z1xVKadANU9Aup4+aPwHRhAmZjHNMsUaA06SNy+MhwI/aJsvhzMXq7NOpvYnU5Qgq/YXNnE9WTBprZSSQDZnAiMh2AeAESTa1j8SWUhzKh+sAiBB0xYp2tb652C7pd+phIBixCTvv7rEDnFBoYXNhSYQuG6zqdW03cf3n7Er69WVGStmdV8aF6VcgbidGtnlxK6Rq0KBzifELaQsvPE8V28cUBP6IXhmCSX8LWcB+u/xnIO0Zzeg34WBalX9GssC5MXJk6K2Wwr8z4V/Xsp5qANcXIIZ9oQupJiXmZoLVp1EzMxKvBLNrfAVgxxYnqJyaUUrl5I/hX9f4fJEy7T5VncRJMchF0g4dh0gvFvKeWcXL58/+njpyvy8nbzf+8/fbn7pYY+aeP3i8NJG/sRI882Wo8m+w5AEDThHXZNpORAxWGmfScyllJTLtRxXm2A6xKgz3x5MWnDO9h0rz59cRFrT3thsSLSax00WE+SNMBqd2ttYGUJUyl0sdrMBc56NgtSUS9jnC4YzxSIx4FNaLrE/sEVlJKpXb31QBca1IiwXzSoY+2KiOcwbJC126yTNvAVrKRaT9pADx5Ars3DZn3b1/eUFxA0YCCelzWGZG1AH2bYz9JQTkQV9W1ThHIuMVRZM2+9VgrgK60fAX4zWTlsWwCxuDUNGrV5mQNmImJOtIsslzRaRpQo0CzD+ViDsVXpjunXqpwDXT6Czp+ALkt3qw+GIb3Ei/rS+FzEX7Ci7Yl9J7wvKAExZ6JWZGklzqihGswjUH/G9z3sL3zr6r3L58JNpKACK/r10Yb1b87cnK2YiToJc8lZug4iHlVHeHPPUqQkTgiWYgsN7v2wdwhLiGVwBTRdBFc+deqZovMVCMwRpYhwMNcT0vI/Dv+IKecWG66KCeiNsWOOldY2Ta39biBsqEfHdoeX+NW9dQkqRVPD7iHOALsiYjpWhRBd9bOj1s1vOZ0T5hbPmHqwmQcgGWyZF93IfVK1E1bBzQ9SjeP5fvxVUqI9iOK2lXj3GqVrmdEiozXD6fXDIb440Dz486KaoQNDaAt8u1sfkdqC+OjViVwuKJ4A9G25thmAPSy69kfYPfDebg3isMh2xC77nNPIpRaQdbRWqqC0Po9tq67vNysyPRVjVtjbbU3auHNQmmkDIoXJ0IC5X1UkmjQU7cnouKTZOadDLIagTMyEKMmKVU5mjAPOh1Jcz2U7y/+Rz/K1JCt5D2Tqkae4Riv/Evlq1NSuEGiWEWkWoIj/2NmGJLarXE3rUhuqDDFsBVfE2NTSduCV/U45Mq5IFEXPKqKgGVWWBE0YmgUHGPCTkvcM3xVuvXZIZGHI7euXHe40dJblVJtY03uI0gXFunWsWXtrg4bVAJUalVsnlVipNmdBIusXA7mxA0fGfYO7n64TiskhitOGrnKkt6y6SLGWMyu47ROEqlrq1CGZ47MRE3Gu5BzfYnbqER6Je6jSHJG0Yu4ZgTvYqLtd/hXd2OG16R7Yd1ZO+YoXRW+4fZVEisHU2IeRhvRQtyknj6zoEDxYt9e+lR7tMLnXkEqR6SGK+jc3T1zX0uFa9Z0RKjYLwE6lU5mvYyniB8VM6Zq7uzX3VvoEq4PW2gziXktxbXHLTMe+3cwKVW49Ri3I7cvXDbtUkiYhY1A5m4SUHmcuevHxrZuLjpmK/Aze2WejxECk53I+R8OXaflW4tmJrcD24mOHcVTCo9TH0MCYXiqhsa5VPEovUBHQ4YFxThIgFRuR5VphN3zgu1W5yjkYiAZp/HeZEAL9O2xOKJX9m00KAZ2354Uufd1ehuiJTAV3WKL3OtZVa2zs6NQsmZe6Pfb6q61zqu936vA0+JtuZWTZMVUrnVr8jy853JKjbpCnPdj2GWRovDJWPgFVEN/TuFpJ0MRbCmC++kToK2oiBeG4r8hgRUaZIseTWD6MVE106jbTa5FGfqfXofrtXaewUqv9ZX/IBJfB9R0d755/xMNnBQyAz4DTNWQjw792UpxMt1k4NAAaG/JbpvRw9tBDcxtsMpQTdJxEHTIrDLBO/VQqE9pQXE9e4iHCBMiFO8RwYY/TXtgdpRehPYJ1XL83ErLYfkdP9uzZAdy1slkpzG95rR4P4qGrcjmPGi8dTwfXXM/XfKkU3vLSM4QZCHGdkH1Ba4ASbovRzuSJjtKmT2NSCSkzY0qbGFuL5Wx2wB6RIeSbs8dOxgm4F0wbDmIE2rs2C+NulfJM+wDqIL4bwNGopnY7Ob/R1cRcBw5IbvC5xG1cj/CuUOT4ruRhwdLFlmXfvXbXHtA0hbxtL38DmTOxDK/ZTxCZG+t0JpbkssifZ/JBPJs0nt2Bw4SIydgnxDGdy32tOiCv3StMe5Tm2w0mDCgMhLa6YRZegz4FcR7vKTP11WcG0Fd7v+vXGeAe27VIMa77PMnOO4OIOcxc8NOHdscRAX3TF7YRgjAkgZlUUGlUqxkNU+ipO5pN44yiQs9A2ZWpz/Eoufv9w6shiV2pt+3mcUPpbuQsx78VXq3QehhzxaRiZj0SZdn8zrqRakLxAoqMZXhXRf06hB5i3IwGNJOCr8cZyoHX796sdh9jdr0lftJKa+e6SRveAQnBnW2teTZvSEKAF0y0jxVnK8oZbQsTOTULpwVLIQq3Uh38vCFGFdBj8+rjIO+cmVgv6HdB4GFTZfVxp6CMKbMeXVJSMJ7FLBtd0Gr76qdxhEh9oC9JHc0Kzs/gQ1SlizhhRo9ujFXBDcs5fLWHtnM2usB5msZ9Q/pUsvx5my7P7e5x30CUs+wMva4KcY5BZtI8xovBgoIGTJnVx0EpRR7YonNCGYu/xm2fqyJO8TzpuGLcZVwxlkuDgo7p/FJO267tIyp8eIjpoM2HvmaGryEiBSmwe8hOYuGuY2M1oaQUGg1E/OPoI+8HIDqhG8QOVnvTgI59tDoLqRNZBshBnAJMZA+bB/fwngQSzINUS2IlVcWmaNJ4eIvKndk/C5a/HmCXKwjoUg5DBchCRzLXcQ4qbn+NP5i0N7Xc7WHc6uMzzoGstgviZZLrEc/dYpbmjfuNy20I3gtSo0Un+PXl8zaLBWxcmLOD21dhA8iDKtgaRWB5eqxbWBtXroFC8DDqWqTaFbICrz620HKqDKM8ksvRAcu6JvEyPSxR8GcB2gwEBaVGJ81AsAGcQeAlrHUEX3OmIBsDthH2l7AmVprNddz9umFrOjg8YAnZqLHKy8AURZOsAKx6r+jX+vnNqoVO2pymEC260q5T4NZ2jnMpl/gyfGbFl29BVpQJkrmDqVSt+5FXrPOmrVNAY3ELsj2Bg+R5kegisYcPBPAxyH/mMtny3bxInusiIaVMF7n8DTe6SKoWdR91To0BJc5K7WUOgA7Suw0Y8UyqZVy0TYbH4+9uYUSRWNBfbqrP6DQrlirpq9dVS0Fyf9VXnNrz17HGm4WMHj04ezlILlAH8tu7n29ffH5D8kLlsj7gguR2YoxtyAQdG0XxTqQYh87o9CiEeImW3lKsK3hySXNbgU9wV4Tga4yauArB7dh+Rn+270Hr0WOnva0AV3m1nWw5KHyxYs9ueR5b8G6evfYr2YGqnCGm0sRdz96mlN0ntblEJnCcfD+VlrCOR+8h53cLasgDqBKcr2vokO3Be4ZuaBDrJcvzgyxfaqG5fMANIG03NQaxe5BfYVsYV7HxTTDdALSSlFnc6Qs/vuXaRYh7FoEuvo3whjZ9sfNEh5EOioRWjOtgf4VHRQ9fIS1QUXKJNxKXRZeOi3Uuvo1w8hyL2p1IxSuvsHravEtKQ0muewltlQBVD6MembxSdwX19qVXmLzuWLcTtjR6fCZ3KOURK692oeEP0ffXKv2+u/PdOvhcrH7V3U9aEi5hPekb4x0U0yWsazef7h75wlh53JWm+L+TNpu1V7h7TPYrrK3mG6ZWoSw7ncgvgv1ZAGHu30AwC6axHXL5H1ya44yBNiM/lsnZTzc/IuBPtc5qRcS+Oh0k2gVbxFuc9QKvE0/WZPr5909vWm6mbeXhIObmVP+gxHvbWJkWWHNt5l/ggIs0be2J96bqKy/d/kYb3MSir0hKVcYE5cys3Qdg6pe+tmphF70QGcNPpMmd33JjZNn2pCmz7PljB6LN7bvuIbYXAniD2ofHvYz4lIPoV09M7K0vbMbq9zi2Sqf38/h03fjCz13G1P55nla5LcnaoUIbS04mtjqvh8MnkQei/HcAVhgQrA=="
 }
@@ -20,6 +20,8 @@ package keyspace
 import (
 	"fmt"
 
+	rd "github.com/gomodule/redigo/redis"
+
 	"github.com/elastic/beats/v7/metricbeat/mb"
 	"github.com/elastic/beats/v7/metricbeat/mb/parse"
 	"github.com/elastic/beats/v7/metricbeat/module/redis"
@@ -48,15 +50,13 @@ func New(base mb.BaseMetricSet) (mb.MetricSet, error) {
 	return &MetricSet{ms}, nil
 }
 
-// Fetch fetches metrics from Redis by issuing the INFO command.
+// Fetch fetches metrics from Redis by issuing the INFO command, from each
+// discovered node if discovery is enabled.
 func (m *MetricSet) Fetch(r mb.ReporterV2) error {
-	conn := m.Connection()
-	defer func() {
-		if err := conn.Close(); err != nil {
-			m.Logger().Debug(fmt.Errorf("failed to release connection: %w", err))
-		}
-	}()
+	return m.FetchNodes(r, m.fetch)
+}
 
+func (m *MetricSet) fetch(conn rd.Conn, r mb.ReporterV2) error {
 	// Fetch default INFO.
 	info, err := redis.FetchRedisInfo("keyspace", conn, m.Logger())
 	if err != nil {
@@ -0,0 +1,112 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+//go:build !integration
+
+package keyspace
+
+import (
+	"fmt"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	mbtest "github.com/elastic/beats/v7/metricbeat/mb/testing"
+	"github.com/elastic/beats/v7/metricbeat/module/redis/redistest"
+	"github.com/elastic/elastic-agent-libs/mapstr"
+)
+
+func TestFetchClusterDiscovery(t *testing.T) {
+	keyspace := func(keys int) redistest.Handler {
+		return func(args []string) interface{} {
+			if len(args) == 2 && args[0] == "INFO" && args[1] == "keyspace" {
+				return fmt.Sprintf("# Keyspace\r\ndb0:keys=%d,expires=0,avg_ttl=0\r\n", keys)
+			}
+			return redistest.ErrUnknownCommand(args)
+		}
+	}
+	master := redistest.NewServer(t, keyspace(10))
+	replica := redistest.NewServer(t, keyspace(20))
+
+	// The replica is failed over after the first fetch.
+	var failover atomic.Bool
+	seed := redistest.NewServer(t, func(args []string) interface{} {
+		if len(args) == 2 && args[0] == "CLUSTER" && args[1] == "NODES" {
+			if failover.Load() {
+				return fmt.Sprintf("m1 %s@1 master,fail - 0 0 1 connected\nr1 %s@1 master - 0 0 2 connected 0-16383\n", master.Addr(), replica.Addr())
+			}
+			return fmt.Sprintf("m1 %s@1 master - 0 0 1 connected 0-16383\nr1 %s@1 slave m1 0 0 1 connected\n", master.Addr(), replica.Addr())
+		}
+		return redistest.ErrUnknownCommand(args)
+	})
+
+	ms := mbtest.NewReportingMetricSetV2Error(t, map[string]interface{}{
+		"module":         "redis",
+		"metricsets":     []string{"keyspace"},
+		"hosts":          []string{seed.Addr()},
+		"discovery.mode": "cluster",
+	})
+
+	events, errs := mbtest.ReportingFetchV2Error(ms)
+	require.Empty(t, errs)
+	require.Len(t, events, 2)
+
+	assert.Equal(t, master.Addr(), events[0].Host)
+	assert.Equal(t, mapstr.M{"node": mapstr.M{"id": "m1", "role": "master", "shard": "m1"}}, events[0].ModuleFields)
+	assert.Equal(t, int64(10), events[0].MetricSetFields["keys"])
+	assert.Equal(t, replica.Addr(), events[1].Host)
+	assert.Equal(t, mapstr.M{"node": mapstr.M{"id": "r1", "role": "replica", "shard": "m1"}}, events[1].ModuleFields)
+	assert.Equal(t, int64(20), events[1].MetricSetFields["keys"])
+
+	failover.Store(true)
+	events, errs = mbtest.ReportingFetchV2Error(ms)
+	require.Empty(t, errs)
+	require.Len(t, events, 1)
+	assert.Equal(t, replica.Addr(), events[0].Host)
+	assert.Equal(t, mapstr.M{"node": mapstr.M{"id": "r1", "role": "master", "shard": "r1"}}, events[0].ModuleFields)
+}
+
+func TestFetchNodeError(t *testing.T) {
+	node := redistest.NewServer(t, func(args []string) interface{} {
+		return redistest.ErrUnknownCommand(args)
+	})
+	seed := redistest.NewServer(t, func(args []string) interface{} {
+		if len(args) == 2 && args[0] == "SENTINEL" && args[1] == "MASTERS" {
+			return []interface{}{
+				[]string{"name", "cache", "ip", node.Host(), "port", node.Port(), "runid", "m1", "flags", "master"},
+			}
+		}
+		if len(args) == 3 && args[0] == "SENTINEL" && args[1] == "REPLICAS" {
+			return []interface{}{}
+		}
+		return redistest.ErrUnknownCommand(args)
+	})
+
+	ms := mbtest.NewReportingMetricSetV2Error(t, map[string]interface{}{
+		"module":         "redis",
+		"metricsets":     []string{"keyspace"},
+		"hosts":          []string{seed.Addr()},
+		"discovery.mode": "sentinel",
+	})
+
+	events, errs := mbtest.ReportingFetchV2Error(ms)
+	assert.Empty(t, events)
+	require.Len(t, errs, 1)
+	assert.ErrorContains(t, errs[0], "failed to fetch node "+node.Addr())
+}
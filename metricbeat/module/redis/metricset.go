@@ -18,6 +18,7 @@
 package redis
 
 import (
+	"errors"
 	"fmt"
 	"net/url"
 	"strconv"
@@ -26,6 +27,7 @@ import (
 	rd "github.com/gomodule/redigo/redis"
 
 	"github.com/elastic/beats/v7/metricbeat/mb"
+	"github.com/elastic/elastic-agent-libs/mapstr"
 	"github.com/elastic/elastic-agent-libs/transport/tlscommon"
 )
 
@@ -33,6 +35,11 @@ import (
 type MetricSet struct {
 	mb.BaseMetricSet
 	pool *Pool
+
+	// discovery and nodes are set when the nodes are discovered from the
+	// topology of a cluster or from Sentinel.
+	discovery DiscoveryConfig
+	nodes     *nodePools
 }
 
 // NewMetricSet creates the base for Redis metricsets.
@@ -58,7 +65,7 @@ func NewMetricSet(base mb.BaseMetricSet) (*MetricSet, error) {
 		config.UseTLSConfig = tlsConfig.ToConfig()
 	}
 
-	return &MetricSet{
+	ms := &MetricSet{
 		BaseMetricSet: base,
 		pool: CreatePool(
 			base.Host(),
@@ -68,7 +75,25 @@ func NewMetricSet(base mb.BaseMetricSet) (*MetricSet, error) {
 			&config,
 			base.Module().Config().Timeout,
 		),
-	}, nil
+		discovery: config.Discovery,
+	}
+
+	if config.Discovery.Mode != "" {
+		nodeUsername, nodePassword := username, password
+		if config.Discovery.Username != "" || config.Discovery.Password != "" {
+			nodeUsername, nodePassword = config.Discovery.Username, config.Discovery.Password
+		}
+		// Redis Cluster only supports the database 0.
+		nodeDBNumber := dbNumber
+		if config.Discovery.Mode == DiscoveryCluster {
+			nodeDBNumber = 0
+		}
+		ms.nodes = newNodePools(func(address string) *Pool {
+			return CreatePool(address, nodeUsername, nodePassword, nodeDBNumber, &config, base.Module().Config().Timeout)
+		})
+	}
+
+	return ms, nil
 }
 
 // Connection returns a redis connection from the pool
@@ -78,7 +103,91 @@ func (m *MetricSet) Connection() rd.Conn {
 
 // Close redis connections
 func (m *MetricSet) Close() error {
-	return m.pool.Close()
+	err := m.pool.Close()
+	if m.nodes != nil {
+		err = errors.Join(err, m.nodes.close())
+	}
+	return err
+}
+
+// DiscoverNodes returns the nodes discovered from the configured host, as
+// listed by CLUSTER NODES or by Sentinel depending on the discovery mode.
+func (m *MetricSet) DiscoverNodes() ([]Node, error) {
+	conn := m.Connection()
+	defer func() {
+		if err := conn.Close(); err != nil {
+			m.Logger().Debug(fmt.Errorf("failed to release connection: %w", err))
+		}
+	}()
+
+	switch m.discovery.Mode {
+	case DiscoveryCluster:
+		nodes, err := FetchClusterNodes(conn)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch cluster nodes: %w", err)
+		}
+		return ClusterTopology(nodes), nil
+	case DiscoverySentinel:
+		return FetchSentinelTopology(conn, m.discovery.Masters)
+	default:
+		return nil, fmt.Errorf("unknown discovery mode %q", m.discovery.Mode)
+	}
+}
+
+// FetchNodes calls fetch with a connection to each node to collect from. If
+// discovery is disabled this is only the configured host. Otherwise the nodes
+// are discovered on each call, so changes in the topology are followed, and
+// the events reported for each node are tagged with its address, role and
+// shard. Errors fetching a node are reported without stopping the collection
+// from the others.
+func (m *MetricSet) FetchNodes(r mb.ReporterV2, fetch func(conn rd.Conn, r mb.ReporterV2) error) error {
+	if m.nodes == nil {
+		conn := m.Connection()
+		defer func() {
+			if err := conn.Close(); err != nil {
+				m.Logger().Debug(fmt.Errorf("failed to release connection: %w", err))
+			}
+		}()
+		return fetch(conn, r)
+	}
+
+	nodes, err := m.DiscoverNodes()
+	if err != nil {
+		return fmt.Errorf("failed to discover nodes from %s: %w", m.Host(), err)
+	}
+	if err := m.nodes.update(nodes); err != nil {
+		m.Logger().Debug(fmt.Errorf("failed to close connections to removed nodes: %w", err))
+	}
+
+	for _, node := range nodes {
+		conn := m.nodes.get(node.Address)
+		if err := fetch(conn, nodeReporter{ReporterV2: r, node: node}); err != nil {
+			r.Error(fmt.Errorf("failed to fetch node %s: %w", node.Address, err))
+		}
+		if err := conn.Close(); err != nil {
+			m.Logger().Debug(fmt.Errorf("failed to release connection: %w", err))
+		}
+	}
+	return nil
+}
+
+// nodeReporter tags the events reported for a discovered node.
+type nodeReporter struct {
+	mb.ReporterV2
+	node Node
+}
+
+func (r nodeReporter) Event(event mb.Event) bool {
+	event.Host = r.node.Address
+	if event.ModuleFields == nil {
+		event.ModuleFields = mapstr.M{}
+	}
+	event.ModuleFields["node"] = mapstr.M{
+		"id":    r.node.ID,
+		"role":  r.node.Role,
+		"shard": r.node.Shard,
+	}
+	return r.ReporterV2.Event(event)
 }
 
 // OriginalDBNumber returns the originally configured database number, this can be used by
@@ -31,6 +31,7 @@ import (
 	"github.com/elastic/elastic-agent-libs/logp"
 	"github.com/elastic/elastic-agent-libs/mapstr"
 
+	"github.com/elastic/beats/v7/packetbeat/capture"
 	"github.com/elastic/beats/v7/packetbeat/config"
 	"github.com/elastic/beats/v7/packetbeat/flows"
 	"github.com/elastic/beats/v7/packetbeat/procs"
@@ -45,15 +46,17 @@ type processor struct {
 	publisher       *publish.TransactionPublisher
 	flows           *flows.Flows
 	sniffer         *sniffer.Sniffer
+	capture         *capture.Capture
 	shutdownTimeout time.Duration
 	err             chan error
 }
 
-func newProcessor(shutdownTimeout time.Duration, publisher *publish.TransactionPublisher, flows *flows.Flows, sniffer *sniffer.Sniffer, err chan error) *processor {
+func newProcessor(shutdownTimeout time.Duration, publisher *publish.TransactionPublisher, flows *flows.Flows, sniffer *sniffer.Sniffer, capture *capture.Capture, err chan error) *processor {
 	return &processor{
 		publisher:       publisher,
 		flows:           flows,
 		sniffer:         sniffer,
+		capture:         capture,
 		err:             err,
 		shutdownTimeout: shutdownTimeout,
 	}
@@ -92,6 +95,11 @@ func (p *processor) Stop() {
 		time.Sleep(p.shutdownTimeout)
 	}
 	p.publisher.Stop()
+	if p.capture != nil {
+		if err := p.capture.Close(); err != nil {
+			logp.Warn("Failed to close capture file: %v", err)
+		}
+	}
 }
 
 // processorFactory controls construction of modules runners.
@@ -142,12 +150,21 @@ func (p *processorFactory) Create(pipeline beat.PipelineConnector, cfg *conf.C)
 		}
 	}
 
+	var capt *capture.Capture
+	if config.Capture.IsEnabled() {
+		capt, err = capture.New(config.Capture)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	publisher, err := publish.NewTransactionPublisher(
 		p.beat.Info.Name,
 		p.beat.Publisher,
 		config.IgnoreOutgoing,
 		config.Interfaces[0].File == "",
 		config.Interfaces[0].InternalNetworks,
+		capt,
 	)
 	if err != nil {
 		return nil, err
@@ -169,12 +186,12 @@ func (p *processorFactory) Create(pipeline beat.PipelineConnector, cfg *conf.C)
 	if err != nil {
 		return nil, err
 	}
-	sniffer, err := setupSniffer(id, config, publisher, &watch, flows)
+	sniffer, err := setupSniffer(id, config, publisher, &watch, flows, capt)
 	if err != nil {
 		return nil, err
 	}
 
-	return newProcessor(config.ShutdownTimeout, publisher, flows, sniffer, p.err), nil
+	return newProcessor(config.ShutdownTimeout, publisher, flows, sniffer, capt, p.err), nil
 }
 
 // setupFlows returns a *flows.Flows that will publish to the provided pipeline,
@@ -208,7 +225,7 @@ func setupFlows(pipeline beat.Pipeline, watch *procs.ProcessesWatcher, cfg confi
 	return flows.NewFlows(client.PublishAll, watch, cfg.Flows)
 }
 
-func setupSniffer(id string, cfg config.Config, pub *publish.TransactionPublisher, watch *procs.ProcessesWatcher, flows *flows.Flows) (*sniffer.Sniffer, error) {
+func setupSniffer(id string, cfg config.Config, pub *publish.TransactionPublisher, watch *procs.ProcessesWatcher, flows *flows.Flows, capture *capture.Capture) (*sniffer.Sniffer, error) {
 	icmp, err := cfg.ICMP()
 	if err != nil {
 		return nil, err
@@ -246,7 +263,7 @@ func setupSniffer(id string, cfg config.Config, pub *publish.TransactionPublishe
 		interfaces[i].BpfFilter = protocols.BpfFilter(iface.WithVlans, icmp.Enabled())
 	}
 
-	return sniffer.New(id, false, "", decoders, interfaces)
+	return sniffer.New(id, false, "", decoders, interfaces, capture)
 }
 
 // CheckConfig performs a dry-run creation of a Packetbeat pipeline based
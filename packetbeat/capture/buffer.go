@@ -0,0 +1,162 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package capture
+
+import (
+	"net/netip"
+	"sync"
+	"time"
+
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/layers"
+)
+
+// packet is a packet kept in memory.
+type packet struct {
+	ci   gopacket.CaptureInfo
+	data []byte
+
+	// flow is the flow of the packet, decoded when the packets are first
+	// searched.
+	flow    flow
+	decoded bool
+}
+
+// endpoint is an IP address and port. The port is 0 for the transports
+// without ports.
+type endpoint struct {
+	ip   netip.Addr
+	port uint16
+}
+
+// flow identifies the packets exchanged by two endpoints, in either
+// direction.
+type flow struct {
+	transport string
+	src, dst  endpoint
+}
+
+// matches returns whether the packets of f belong to other.
+func (f flow) matches(other flow) bool {
+	if f.transport != other.transport {
+		return false
+	}
+	return (f.src == other.src && f.dst == other.dst) || (f.src == other.dst && f.dst == other.src)
+}
+
+// buffer keeps the packets of the last seconds, in order of arrival.
+type buffer struct {
+	mu      sync.Mutex
+	packets []*packet
+	size    int
+
+	window  time.Duration
+	maxSize int
+}
+
+func newBuffer(window time.Duration, maxSize int) *buffer {
+	return &buffer{window: window, maxSize: maxSize}
+}
+
+// add adds a packet to the buffer, evicting the packets older than the
+// window or exceeding the size of the buffer. The buffer takes ownership of
+// data.
+func (b *buffer) add(ci gopacket.CaptureInfo, data []byte) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.packets = append(b.packets, &packet{ci: ci, data: data})
+	b.size += len(data)
+
+	oldest := ci.Timestamp.Add(-b.window)
+	n := 0
+	for n < len(b.packets)-1 && (b.size > b.maxSize || b.packets[n].ci.Timestamp.Before(oldest)) {
+		b.size -= len(b.packets[n].data)
+		b.packets[n] = nil
+		n++
+	}
+	b.packets = b.packets[n:]
+}
+
+// snapshot returns the packets of the buffer. The packets are not modified by
+// the buffer once added, so they can be searched without holding its lock.
+func (b *buffer) snapshot() []*packet {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	packets := make([]*packet, len(b.packets))
+	copy(packets, b.packets)
+	return packets
+}
+
+// findFlow returns the packets of a flow, with their interface index in the
+// link types given. The flows of the packets are decoded the first time they
+// are searched, so concurrent searches of the same packets must be
+// serialized by the caller.
+func findFlow(packets []*packet, f flow, linkTypes []layers.LinkType) []*packet {
+	var found []*packet
+	for _, p := range packets {
+		if !p.decoded {
+			if p.ci.InterfaceIndex < len(linkTypes) {
+				p.flow, _ = decodeFlow(p.data, linkTypes[p.ci.InterfaceIndex])
+			}
+			p.decoded = true
+		}
+		if p.flow.matches(f) {
+			found = append(found, p)
+		}
+	}
+	return found
+}
+
+// decodeFlow decodes the IP addresses and ports of a packet.
+func decodeFlow(data []byte, linkType layers.LinkType) (flow, bool) {
+	pkt := gopacket.NewPacket(data, linkType, gopacket.DecodeOptions{Lazy: true, NoCopy: true})
+
+	var f flow
+	switch ip := pkt.NetworkLayer().(type) {
+	case *layers.IPv4:
+		f.src.ip, _ = netip.AddrFromSlice(ip.SrcIP)
+		f.dst.ip, _ = netip.AddrFromSlice(ip.DstIP)
+	case *layers.IPv6:
+		f.src.ip, _ = netip.AddrFromSlice(ip.SrcIP)
+		f.dst.ip, _ = netip.AddrFromSlice(ip.DstIP)
+	default:
+		return f, false
+	}
+	f.src.ip, f.dst.ip = f.src.ip.Unmap(), f.dst.ip.Unmap()
+
+	switch l := pkt.TransportLayer().(type) {
+	case *layers.TCP:
+		f.transport = "tcp"
+		f.src.port, f.dst.port = uint16(l.SrcPort), uint16(l.DstPort)
+	case *layers.UDP:
+		f.transport = "udp"
+		f.src.port, f.dst.port = uint16(l.SrcPort), uint16(l.DstPort)
+	default:
+		switch {
+		case pkt.Layer(layers.LayerTypeICMPv4) != nil:
+			f.transport = "icmp"
+		case pkt.Layer(layers.LayerTypeICMPv6) != nil:
+			f.transport = "ipv6-icmp"
+		default:
+			return f, false
+		}
+	}
+	return f, true
+}
@@ -0,0 +1,255 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+// Package capture records the packets of the flows of the transactions
+// matching a condition in pcapng files. The packets of the last seconds are
+// kept in memory, so that the packets preceding the transaction are
+// recorded as well.
+package capture
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/netip"
+	"runtime"
+	"sync"
+	"time"
+
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/layers"
+	"github.com/google/gopacket/pcapgo"
+
+	"github.com/elastic/beats/v7/libbeat/beat"
+	"github.com/elastic/beats/v7/libbeat/conditions"
+	"github.com/elastic/beats/v7/packetbeat/config"
+	"github.com/elastic/beats/v7/packetbeat/pb"
+	"github.com/elastic/elastic-agent-libs/logp"
+	"github.com/elastic/elastic-agent-libs/monitoring"
+	"github.com/elastic/elastic-agent-libs/paths"
+)
+
+const (
+	defaultWindow      = 30 * time.Second
+	defaultBufferSize  = 64 * 1024 * 1024
+	defaultMaxFileSize = 100 * 1024 * 1024
+	defaultMaxFiles    = 10
+)
+
+var (
+	triggers    = monitoring.NewInt(nil, "capture.triggers")
+	writeErrors = monitoring.NewInt(nil, "capture.write_errors")
+)
+
+// Capture keeps the packets of the last seconds and writes the packets of
+// the flows of the transactions matching its condition.
+type Capture struct {
+	condition conditions.Condition
+	buffer    *buffer
+
+	// mu protects the interfaces, the flows of the buffered packets and the
+	// files.
+	mu         sync.Mutex
+	interfaces []pcapgo.NgInterface
+	linkTypes  []layers.LinkType
+	files      *fileRing
+
+	log *logp.Logger
+}
+
+// New returns a Capture configured with cfg.
+func New(cfg *config.Capture) (*Capture, error) {
+	if cfg.When == nil {
+		return nil, errors.New("capture requires a 'when' condition")
+	}
+	log := logp.NewLogger("capture")
+	condition, err := conditions.NewCondition(cfg.When, log)
+	if err != nil {
+		return nil, fmt.Errorf("invalid capture condition: %w", err)
+	}
+
+	window := cfg.Window
+	if window <= 0 {
+		window = defaultWindow
+	}
+	bufferSize := int(cfg.BufferSize)
+	if bufferSize <= 0 {
+		bufferSize = defaultBufferSize
+	}
+	maxFileSize := int64(cfg.MaxFileSize)
+	if maxFileSize <= 0 {
+		maxFileSize = defaultMaxFileSize
+	}
+	maxFiles := cfg.MaxFiles
+	if maxFiles <= 0 {
+		maxFiles = defaultMaxFiles
+	}
+	path := cfg.Path
+	if path == "" {
+		path = "capture"
+	}
+
+	files, err := newFileRing(paths.Resolve(paths.Data, path), maxFileSize, maxFiles, log)
+	if err != nil {
+		return nil, err
+	}
+	log.Infof("Recording the packets of the last %v of the transactions matching %v in %s", window, condition, files.dir)
+
+	return &Capture{
+		condition: condition,
+		buffer:    newBuffer(window, bufferSize),
+		files:     files,
+		log:       log,
+	}, nil
+}
+
+// Interface registers an interface the packets are read from and returns
+// its index, to be given with its packets to Add.
+func (c *Capture) Interface(name string, linkType layers.LinkType) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for i, iface := range c.interfaces {
+		if iface.Name == name && iface.LinkType == linkType {
+			return i
+		}
+	}
+	c.interfaces = append(c.interfaces, pcapgo.NgInterface{
+		Name:                name,
+		OS:                  runtime.GOOS,
+		LinkType:            linkType,
+		TimestampResolution: 9,
+	})
+	c.linkTypes = append(c.linkTypes, linkType)
+	return len(c.interfaces) - 1
+}
+
+// Add adds a packet read from an interface. The capture takes ownership of
+// data.
+func (c *Capture) Add(iface int, ci gopacket.CaptureInfo, data []byte) {
+	ci.InterfaceIndex = iface
+	c.buffer.add(ci, data)
+}
+
+// Trigger records the packets of the flow of a transaction if it matches the
+// condition of the capture, and adds the name of the file they are written to
+// to the event. The buffer is not locked while its packets are searched, and
+// the files are written in the background, so that the packets keep being
+// added meanwhile.
+func (c *Capture) Trigger(event *beat.Event, fields *pb.Fields) {
+	if fields == nil || fields.Source == nil || fields.Destination == nil {
+		return
+	}
+	if !c.condition.Check(event) {
+		return
+	}
+	f, ok := eventFlow(fields)
+	if !ok {
+		return
+	}
+	triggers.Inc()
+
+	candidates := c.buffer.snapshot()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	packets := findFlow(candidates, f, c.linkTypes)
+	if len(packets) == 0 {
+		return
+	}
+	file, err := c.write(packets, event)
+	if err != nil {
+		writeErrors.Inc()
+		c.log.Errorf("Failed to record the packets of %s %s:%d -> %s:%d: %v",
+			f.transport, fields.Source.IP, fields.Source.Port, fields.Destination.IP, fields.Destination.Port, err)
+		return
+	}
+	c.log.Debugf("Recorded %d packets of %s %s:%d -> %s:%d in %s", len(packets),
+		f.transport, fields.Source.IP, fields.Source.Port, fields.Destination.IP, fields.Destination.Port, file)
+	_, _ = event.PutValue("capture.file", file)
+	_, _ = event.PutValue("capture.packets", len(packets))
+}
+
+// write queues the write of the packets in a section of the current file. Each section
+// contains the interfaces its packets were read from.
+func (c *Capture) write(packets []*packet, event *beat.Event) (string, error) {
+	var buf bytes.Buffer
+	var w *pcapgo.NgWriter
+	sectionIndex := map[int]int{}
+	for _, p := range packets {
+		idx, ok := sectionIndex[p.ci.InterfaceIndex]
+		if !ok {
+			var err error
+			iface := c.interfaces[p.ci.InterfaceIndex]
+			if w == nil {
+				w, err = pcapgo.NewNgWriterInterface(&buf, iface, pcapgo.NgWriterOptions{
+					SectionInfo: pcapgo.NgSectionInfo{
+						Hardware:    runtime.GOARCH,
+						OS:          runtime.GOOS,
+						Application: "packetbeat",
+						Comment:     sectionComment(event),
+					},
+				})
+			} else {
+				idx, err = w.AddInterface(iface)
+			}
+			if err != nil {
+				return "", err
+			}
+			sectionIndex[p.ci.InterfaceIndex] = idx
+		}
+		ci := p.ci
+		ci.InterfaceIndex = idx
+		if err := w.WritePacket(ci, p.data); err != nil {
+			return "", err
+		}
+	}
+	if err := w.Flush(); err != nil {
+		return "", err
+	}
+	return c.files.write(buf.Bytes(), time.Now())
+}
+
+// Close writes the recordings queued and closes the current file.
+func (c *Capture) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.files.close()
+}
+
+// eventFlow returns the flow of the transaction of an event.
+func eventFlow(fields *pb.Fields) (flow, bool) {
+	var f flow
+	var err error
+	f.transport = fields.Network.Transport
+	if f.src.ip, err = netip.ParseAddr(fields.Source.IP); err != nil {
+		return f, false
+	}
+	if f.dst.ip, err = netip.ParseAddr(fields.Destination.IP); err != nil {
+		return f, false
+	}
+	f.src.ip, f.dst.ip = f.src.ip.Unmap(), f.dst.ip.Unmap()
+	f.src.port, f.dst.port = uint16(fields.Source.Port), uint16(fields.Destination.Port)
+	return f, true
+}
+
+// sectionComment describes the transaction that triggered the recording.
+func sectionComment(event *beat.Event) string {
+	typ, _ := event.GetValue("type")
+	return fmt.Sprintf("%v transaction at %s", typ, event.Timestamp.UTC().Format(time.RFC3339Nano))
+}
@@ -0,0 +1,205 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+//go:build !integration
+
+package capture
+
+import (
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/layers"
+	"github.com/google/gopacket/pcapgo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/elastic/beats/v7/libbeat/beat"
+	"github.com/elastic/beats/v7/libbeat/common/cfgtype"
+	"github.com/elastic/beats/v7/libbeat/conditions"
+	"github.com/elastic/beats/v7/libbeat/ecs"
+	"github.com/elastic/beats/v7/packetbeat/config"
+	"github.com/elastic/beats/v7/packetbeat/pb"
+	"github.com/elastic/elastic-agent-libs/mapstr"
+)
+
+func newTestCapture(t *testing.T, cfg config.Capture) *Capture {
+	t.Helper()
+	cfg.Path = t.TempDir()
+	cfg.When = &conditions.Config{Equals: &conditions.Fields{}}
+	require.NoError(t, cfg.When.Equals.Unpack(map[string]interface{}{"status": "Error"}))
+	c, err := New(&cfg)
+	require.NoError(t, err)
+	t.Cleanup(func() { assert.NoError(t, c.Close()) })
+	return c
+}
+
+// tcpPacket returns an Ethernet frame of a TCP segment.
+func tcpPacket(t *testing.T, src, dst string, srcPort, dstPort uint16, payload string) []byte {
+	t.Helper()
+	ip := &layers.IPv4{Version: 4, TTL: 64, Protocol: layers.IPProtocolTCP, SrcIP: net.ParseIP(src), DstIP: net.ParseIP(dst)}
+	tcp := &layers.TCP{SrcPort: layers.TCPPort(srcPort), DstPort: layers.TCPPort(dstPort), PSH: true, ACK: true, Window: 1024}
+	require.NoError(t, tcp.SetNetworkLayerForChecksum(ip))
+	buf := gopacket.NewSerializeBuffer()
+	err := gopacket.SerializeLayers(buf, gopacket.SerializeOptions{FixLengths: true, ComputeChecksums: true},
+		&layers.Ethernet{SrcMAC: net.HardwareAddr{2, 0, 0, 0, 0, 1}, DstMAC: net.HardwareAddr{2, 0, 0, 0, 0, 2}, EthernetType: layers.EthernetTypeIPv4},
+		ip, tcp, gopacket.Payload(payload))
+	require.NoError(t, err)
+	return buf.Bytes()
+}
+
+func addPacket(c *Capture, iface int, ts time.Time, data []byte) {
+	c.Add(iface, gopacket.CaptureInfo{Timestamp: ts, CaptureLength: len(data), Length: len(data)}, data)
+}
+
+func transaction(status string) (*beat.Event, *pb.Fields) {
+	event := &beat.Event{
+		Timestamp: time.Now(),
+		Fields:    mapstr.M{"type": "http", "status": status},
+	}
+	fields := &pb.Fields{
+		Source:      &ecs.Source{IP: "10.0.0.1", Port: 40000},
+		Destination: &ecs.Destination{IP: "10.0.0.2", Port: 80},
+	}
+	fields.Network.Transport = "tcp"
+	return event, fields
+}
+
+// readPackets returns the packets of all the sections of a pcapng file.
+func readPackets(t *testing.T, name string) [][]byte {
+	t.Helper()
+	f, err := os.Open(name)
+	require.NoError(t, err)
+	defer f.Close()
+	r, err := pcapgo.NewNgReader(f, pcapgo.NgReaderOptions{})
+	require.NoError(t, err)
+	var packets [][]byte
+	for {
+		data, ci, err := r.ReadPacketData()
+		if err == io.EOF { //nolint:errorlint // io.EOF is not wrapped.
+			return packets
+		}
+		require.NoError(t, err)
+		assert.Equal(t, layers.LinkTypeEthernet, r.LinkType())
+		assert.False(t, ci.Timestamp.IsZero())
+		packets = append(packets, data)
+	}
+}
+
+func TestTrigger(t *testing.T) {
+	c := newTestCapture(t, config.Capture{})
+	iface := c.Interface("eth0", layers.LinkTypeEthernet)
+
+	ts := time.Now()
+	request := tcpPacket(t, "10.0.0.1", "10.0.0.2", 40000, 80, "GET / HTTP/1.1\r\n\r\n")
+	other := tcpPacket(t, "10.0.0.1", "10.0.0.3", 40001, 80, "GET / HTTP/1.1\r\n\r\n")
+	response := tcpPacket(t, "10.0.0.2", "10.0.0.1", 80, 40000, "HTTP/1.1 500 Internal Server Error\r\n\r\n")
+	addPacket(c, iface, ts, request)
+	addPacket(c, iface, ts.Add(time.Millisecond), other)
+	addPacket(c, iface, ts.Add(2*time.Millisecond), response)
+
+	event, fields := transaction("OK")
+	c.Trigger(event, fields)
+	assert.NotContains(t, event.Fields, "capture")
+
+	event, fields = transaction("Error")
+	c.Trigger(event, fields)
+	file, err := event.GetValue("capture.file")
+	require.NoError(t, err)
+	packets, _ := event.GetValue("capture.packets")
+	assert.Equal(t, 2, packets)
+	c.files.flush()
+	assert.Equal(t, [][]byte{request, response}, readPackets(t, file.(string)))
+
+	// Each recording is written in its own section.
+	event, fields = transaction("Error")
+	c.Trigger(event, fields)
+	file2, _ := event.GetValue("capture.file")
+	assert.Equal(t, file, file2)
+	c.files.flush()
+	assert.Len(t, readPackets(t, file.(string)), 4)
+}
+
+func TestBufferEviction(t *testing.T) {
+	data := make([]byte, 100)
+	ts := time.Now()
+
+	b := newBuffer(time.Second, 1000)
+	b.add(gopacket.CaptureInfo{Timestamp: ts}, data)
+	b.add(gopacket.CaptureInfo{Timestamp: ts.Add(500 * time.Millisecond)}, data)
+	b.add(gopacket.CaptureInfo{Timestamp: ts.Add(1200 * time.Millisecond)}, data)
+	assert.Len(t, b.packets, 2)
+	assert.Equal(t, 200, b.size)
+
+	b = newBuffer(time.Minute, 250)
+	for i := 0; i < 5; i++ {
+		b.add(gopacket.CaptureInfo{Timestamp: ts}, data)
+	}
+	assert.Len(t, b.packets, 2)
+	assert.Equal(t, 200, b.size)
+
+	// The last packet is kept, even if larger than the buffer.
+	b.add(gopacket.CaptureInfo{Timestamp: ts}, make([]byte, 300))
+	assert.Len(t, b.packets, 1)
+}
+
+func TestFileRotation(t *testing.T) {
+	c := newTestCapture(t, config.Capture{MaxFileSize: cfgtype.ByteSize(100), MaxFiles: 2})
+	iface := c.Interface("eth0", layers.LinkTypeEthernet)
+	addPacket(c, iface, time.Now(), tcpPacket(t, "10.0.0.1", "10.0.0.2", 40000, 80, "GET / HTTP/1.1\r\n\r\n"))
+
+	var files []string
+	for i := 0; i < 3; i++ {
+		event, fields := transaction("Error")
+		c.Trigger(event, fields)
+		file, err := event.GetValue("capture.file")
+		require.NoError(t, err)
+		files = append(files, file.(string))
+	}
+
+	c.files.flush()
+
+	// Sections larger than the maximum size are written in their own file.
+	assert.Len(t, readPackets(t, files[2]), 1)
+	assert.NoFileExists(t, files[0])
+	found, err := filepath.Glob(filepath.Join(c.files.dir, "*.pcapng"))
+	require.NoError(t, err)
+	assert.Equal(t, files[1:], found)
+}
+
+func TestCloseWritesQueued(t *testing.T) {
+	c := newTestCapture(t, config.Capture{})
+	iface := c.Interface("eth0", layers.LinkTypeEthernet)
+	addPacket(c, iface, time.Now(), tcpPacket(t, "10.0.0.1", "10.0.0.2", 40000, 80, "GET / HTTP/1.1\r\n\r\n"))
+
+	event, fields := transaction("Error")
+	c.Trigger(event, fields)
+	file, err := event.GetValue("capture.file")
+	require.NoError(t, err)
+	require.NoError(t, c.Close())
+	assert.Len(t, readPackets(t, file.(string)), 1)
+}
+
+func TestNewRequiresCondition(t *testing.T) {
+	_, err := New(&config.Capture{Path: t.TempDir()})
+	assert.Error(t, err)
+}
@@ -0,0 +1,216 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package capture
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/elastic/elastic-agent-libs/logp"
+)
+
+const (
+	filePrefix    = "packetbeat-"
+	fileExtension = ".pcapng"
+
+	// writeQueueSize is the number of recordings waiting to be written.
+	writeQueueSize = 64
+)
+
+var errQueueFull = errors.New("capture write queue is full")
+
+// fileRing writes to a set of files, starting a new file when the current
+// one reaches its maximum size and removing the oldest files when there are
+// too many. The file names contain their creation time, so that files aren't
+// renamed once their names have been reported.
+//
+// The files written are decided when write is called, so that their names can
+// be reported right away, and are written in the background, in order.
+type fileRing struct {
+	dir      string
+	maxSize  int64
+	maxFiles int
+
+	// files are the files of the ring, the oldest first, and current the one
+	// written to, of size bytes once the queued writes are done.
+	files   []string
+	current string
+	size    int64
+	seq     int
+	closed  bool
+
+	writes chan fileWrite
+	done   chan struct{}
+	log    *logp.Logger
+}
+
+// fileWrite is a write of data to a file, after the removal of the files
+// removed from the ring. When flushed is not nil, it is closed once the
+// previous writes are done.
+type fileWrite struct {
+	name    string
+	data    []byte
+	remove  []string
+	flushed chan struct{}
+}
+
+// newFileRing creates a ring in dir, including the files left by previous
+// runs.
+func newFileRing(dir string, maxSize int64, maxFiles int, log *logp.Logger) (*fileRing, error) {
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, fmt.Errorf("failed to create capture directory: %w", err)
+	}
+	files, err := filepath.Glob(filepath.Join(dir, filePrefix+"*"+fileExtension))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(files)
+	r := &fileRing{
+		dir:      dir,
+		maxSize:  maxSize,
+		maxFiles: maxFiles,
+		files:    files,
+		writes:   make(chan fileWrite, writeQueueSize),
+		done:     make(chan struct{}),
+		log:      log,
+	}
+	go r.run()
+	return r, nil
+}
+
+// write queues the write of data to the current file, starting a new file if
+// it would exceed its maximum size, and returns the name of the file. data is
+// never split across files.
+func (r *fileRing) write(data []byte, now time.Time) (string, error) {
+	if r.closed {
+		return "", errors.New("capture is closed")
+	}
+	w := fileWrite{data: data}
+	rotate := r.current == "" || (r.size > 0 && r.size+int64(len(data)) > r.maxSize)
+	if rotate {
+		w.name = r.nextName(now)
+		w.remove = r.removed(1)
+	} else {
+		w.name = r.current
+	}
+
+	select {
+	case r.writes <- w:
+	default:
+		return "", errQueueFull
+	}
+
+	if rotate {
+		r.seq++
+		r.current = w.name
+		r.size = 0
+		r.files = append(r.files[len(w.remove):], w.name)
+	}
+	r.size += int64(len(data))
+	return w.name, nil
+}
+
+// nextName returns the name of the next file of the ring.
+func (r *fileRing) nextName(now time.Time) string {
+	return filepath.Join(r.dir, fmt.Sprintf("%s%s-%06d%s", filePrefix, now.UTC().Format("20060102150405"), r.seq+1, fileExtension))
+}
+
+// removed returns the oldest files to remove when n files are added.
+func (r *fileRing) removed(n int) []string {
+	excess := len(r.files) + n - r.maxFiles
+	if excess <= 0 {
+		return nil
+	}
+	if excess > len(r.files) {
+		excess = len(r.files)
+	}
+	return r.files[:excess:excess]
+}
+
+// flush waits until the queued writes are done.
+func (r *fileRing) flush() {
+	if r.closed {
+		return
+	}
+	flushed := make(chan struct{})
+	r.writes <- fileWrite{flushed: flushed}
+	<-flushed
+}
+
+// close writes the queued writes and closes the current file.
+func (r *fileRing) close() error {
+	if r.closed {
+		return nil
+	}
+	r.closed = true
+	close(r.writes)
+	<-r.done
+	return nil
+}
+
+// run does the writes until the ring is closed.
+func (r *fileRing) run() {
+	defer close(r.done)
+
+	var f *os.File
+	defer func() {
+		if f != nil {
+			if err := f.Close(); err != nil {
+				r.log.Errorf("Failed to close capture file: %v", err)
+			}
+		}
+	}()
+
+	for w := range r.writes {
+		if w.flushed != nil {
+			close(w.flushed)
+			continue
+		}
+
+		if f == nil || f.Name() != w.name {
+			if f != nil {
+				if err := f.Close(); err != nil {
+					r.log.Errorf("Failed to close capture file: %v", err)
+				}
+				f = nil
+			}
+			for _, name := range w.remove {
+				if err := os.Remove(name); err != nil && !os.IsNotExist(err) {
+					writeErrors.Inc()
+					r.log.Errorf("Failed to remove capture file: %v", err)
+				}
+			}
+			var err error
+			f, err = os.OpenFile(w.name, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o600)
+			if err != nil {
+				f = nil
+				writeErrors.Inc()
+				r.log.Errorf("Failed to create capture file: %v", err)
+				continue
+			}
+		}
+		if _, err := f.Write(w.data); err != nil {
+			writeErrors.Inc()
+			r.log.Errorf("Failed to write capture file %s: %v", w.name, err)
+		}
+	}
+}
@@ -24,6 +24,8 @@ import (
 	"strings"
 	"time"
 
+	"github.com/elastic/beats/v7/libbeat/common/cfgtype"
+	"github.com/elastic/beats/v7/libbeat/conditions"
 	"github.com/elastic/beats/v7/libbeat/processors"
 	"github.com/elastic/beats/v7/packetbeat/procs"
 	conf "github.com/elastic/elastic-agent-libs/config"
@@ -36,6 +38,7 @@ type Config struct {
 	Interface          *InterfaceConfig   `config:"interfaces"`
 	Interfaces         []InterfaceConfig  `config:"interfaces"`
 	Flows              *Flows             `config:"flows"`
+	Capture            *Capture           `config:"capture"`
 	Protocols          map[string]*conf.C `config:"protocols"`
 	ProtocolsList      []*conf.C          `config:"protocols"`
 	Procs              procs.ProcsConfig  `config:"procs"`
@@ -146,6 +149,45 @@ type Flows struct {
 	Index string `config:"index"`
 	// DeltaFlowReports when enabled will report flow network stats(bytes, packets) as delta values
 	EnableDeltaFlowReports bool `config:"enable_delta_flow_reports"`
+	// Export configures the export of the flows to IPFIX or NetFlow v9 collectors.
+	Export *FlowsExport `config:"export"`
+}
+
+// FlowsExport configures the export of the flow reports to IPFIX or NetFlow
+// v9 collectors over UDP.
+type FlowsExport struct {
+	Enabled *bool `config:"enabled"`
+	// Protocol is either ipfix or netflow9.
+	Protocol string   `config:"protocol"`
+	Hosts    []string `config:"hosts" validate:"required"`
+	// ObservationDomainID is the observation domain (IPFIX) or source ID
+	// (NetFlow v9) of the messages.
+	ObservationDomainID uint32 `config:"observation_domain_id"`
+	// EnterpriseID is the private enterprise number of the elements for the
+	// process and community ID. They are only exported to IPFIX collectors,
+	// when set.
+	EnterpriseID uint32 `config:"enterprise_id"`
+	// MaxMessageSize is the maximum size of the UDP datagrams.
+	MaxMessageSize int `config:"max_message_size"`
+}
+
+// Capture configures the recording of the packets of the flows of the
+// transactions matching a condition.
+type Capture struct {
+	Enabled *bool `config:"enabled"`
+	// Path is the directory where the pcapng files are written.
+	Path string `config:"path"`
+	// Window is how long packets are kept in memory, waiting for a
+	// transaction to trigger their recording.
+	Window time.Duration `config:"window"`
+	// BufferSize limits the size of the packets kept in memory.
+	BufferSize cfgtype.ByteSize `config:"buffer_size"`
+	// MaxFileSize is the size of a file after which a new file is started.
+	MaxFileSize cfgtype.ByteSize `config:"max_file_size"`
+	// MaxFiles is the number of files kept, the oldest files are removed.
+	MaxFiles int `config:"max_files"`
+	// When is the condition the transactions must match.
+	When *conditions.Config `config:"when"`
 }
 
 type ProtocolCommon struct {
@@ -159,6 +201,14 @@ func (f *Flows) IsEnabled() bool {
 	return f != nil && (f.Enabled == nil || *f.Enabled)
 }
 
+func (e *FlowsExport) IsEnabled() bool {
+	return e != nil && (e.Enabled == nil || *e.Enabled)
+}
+
+func (c *Capture) IsEnabled() bool {
+	return c != nil && (c.Enabled == nil || *c.Enabled)
+}
+
 func (i InterfaceConfig) Validate() error {
 	if i.Type != "af_packet" && i.FanoutGroup != nil {
 		return errFanoutGroupAFPacketOnly
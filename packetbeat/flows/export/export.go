@@ -0,0 +1,230 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+// Package export exports the flow reports of packetbeat to IPFIX (RFC 7011)
+// or NetFlow v9 (RFC 3954) collectors over UDP.
+package export
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"sort"
+	"time"
+
+	"github.com/elastic/beats/v7/libbeat/beat"
+	"github.com/elastic/beats/v7/packetbeat/config"
+	"github.com/elastic/elastic-agent-libs/logp"
+	"github.com/elastic/elastic-agent-libs/monitoring"
+)
+
+const (
+	defaultPortIPFIX      = "4739"
+	defaultPortNetflow9   = "2055"
+	defaultMaxMessageSize = 1400
+	minMaxMessageSize     = 512
+	protocolIPFIX         = "ipfix"
+	protocolNetflow9      = "netflow9"
+)
+
+var (
+	messagesSent = monitoring.NewInt(nil, "flows.export.messages")
+	recordsSent  = monitoring.NewInt(nil, "flows.export.records")
+	sendErrors   = monitoring.NewInt(nil, "flows.export.errors")
+)
+
+// Exporter sends the flow reports to the collectors. The templates are sent
+// at the beginning of each report, so that collectors can decode the records
+// after a restart or a lost message.
+//
+// The counters of the records are the number of bytes and packets seen since
+// the previous report of the flow, whether or not the flow events report
+// delta values.
+type Exporter struct {
+	version uint16
+	domain  uint32
+	pen     uint32
+	maxSize int
+	delta   bool
+
+	conns      []net.Conn
+	ipv4, ipv6 *template
+
+	// seq is the number of messages (NetFlow v9) or data records (IPFIX)
+	// sent.
+	seq   uint32
+	start time.Time
+	// totals are the last counters reported for the flows, by flow ID,
+	// when the flow events report cumulative values.
+	totals map[string][4]uint64
+
+	msg message
+	buf []byte
+
+	log *logp.Logger
+}
+
+// New returns an Exporter configured with cfg. delta is whether the flow
+// events report the counters as delta values.
+func New(cfg *config.FlowsExport, delta bool) (*Exporter, error) {
+	e := &Exporter{
+		domain:  cfg.ObservationDomainID,
+		pen:     cfg.EnterpriseID,
+		maxSize: cfg.MaxMessageSize,
+		delta:   delta,
+		start:   time.Now(),
+		totals:  make(map[string][4]uint64),
+		log:     logp.NewLogger("flows.export"),
+	}
+	var port string
+	switch cfg.Protocol {
+	case protocolIPFIX, "":
+		e.version, port = versionIPFIX, defaultPortIPFIX
+	case protocolNetflow9:
+		// Enterprise elements and variable length strings can't be encoded
+		// in NetFlow v9.
+		e.version, port, e.pen = versionNetflow9, defaultPortNetflow9, 0
+	default:
+		return nil, fmt.Errorf("unknown flow export protocol %q", cfg.Protocol)
+	}
+	if e.maxSize == 0 {
+		e.maxSize = defaultMaxMessageSize
+	}
+	if e.maxSize < minMaxMessageSize || e.maxSize > 65507 {
+		return nil, fmt.Errorf("max_message_size must be between %d and 65507", minMaxMessageSize)
+	}
+	e.ipv4, e.ipv6 = newTemplates(e.pen != 0)
+	e.msg = message{version: e.version, domain: e.domain}
+
+	for _, host := range cfg.Hosts {
+		if _, _, err := net.SplitHostPort(host); err != nil {
+			host = net.JoinHostPort(host, port)
+		}
+		conn, err := net.Dial("udp", host)
+		if err != nil {
+			_ = e.Close()
+			return nil, fmt.Errorf("failed to set up flow collector %s: %w", host, err)
+		}
+		e.conns = append(e.conns, conn)
+	}
+	return e, nil
+}
+
+// Reporter returns a reporter exporting the flow events before passing them
+// to pub.
+func (e *Exporter) Reporter(pub func([]beat.Event)) func([]beat.Event) {
+	return func(events []beat.Event) {
+		e.Export(events)
+		pub(events)
+	}
+}
+
+// Export sends the records of the flow events to the collectors. It must
+// not be called concurrently.
+func (e *Exporter) Export(events []beat.Event) {
+	records := make([]*record, 0, len(events))
+	for i := range events {
+		r, ok := newRecord(&events[i])
+		if !ok {
+			continue
+		}
+		e.deltas(r)
+		records = append(records, r)
+	}
+	if len(records) == 0 {
+		return
+	}
+	// Group the records by template to minimize the number of sets.
+	sort.SliceStable(records, func(i, j int) bool {
+		return !records[i].isIPv6() && records[j].isIPv6()
+	})
+
+	now := time.Now()
+	e.msg.reset()
+	e.msg.appendTemplates(e.pen, e.ipv4, e.ipv6)
+	for _, r := range records {
+		t := e.ipv4
+		if r.isIPv6() {
+			t = e.ipv6
+		}
+		e.buf = t.appendRecord(e.buf[:0], r)
+		if !e.msg.fits(t.id, len(e.buf), e.maxSize) && e.msg.data != 0 {
+			e.send(now)
+			e.msg.reset()
+		}
+		e.msg.appendRecord(t.id, e.buf)
+	}
+	e.send(now)
+}
+
+// deltas replaces the cumulative counters of r by the difference with the
+// previous report of the flow.
+func (e *Exporter) deltas(r *record) {
+	if e.delta || r.id == "" {
+		return
+	}
+	counters := [4]uint64{r.srcBytes, r.dstBytes, r.srcPackets, r.dstPackets}
+	prev := e.totals[r.id]
+	if r.final {
+		delete(e.totals, r.id)
+	} else {
+		e.totals[r.id] = counters
+	}
+	for i := range counters {
+		if counters[i] >= prev[i] {
+			counters[i] -= prev[i]
+		}
+	}
+	r.srcBytes, r.dstBytes, r.srcPackets, r.dstPackets = counters[0], counters[1], counters[2], counters[3]
+}
+
+// send sends the current message to all the collectors.
+func (e *Exporter) send(now time.Time) {
+	if e.msg.empty() {
+		return
+	}
+	var seq uint32
+	if e.version == versionNetflow9 {
+		seq = e.seq
+		e.seq++
+	} else {
+		seq = e.seq
+		e.seq += uint32(e.msg.data)
+	}
+	data := e.msg.finish(now, seq, now.Sub(e.start))
+	for _, conn := range e.conns {
+		if _, err := conn.Write(data); err != nil {
+			sendErrors.Inc()
+			e.log.Debugf("failed to send flows to %s: %v", conn.RemoteAddr(), err)
+			continue
+		}
+		messagesSent.Inc()
+		recordsSent.Add(int64(e.msg.data))
+	}
+}
+
+// Close closes the connections to the collectors.
+func (e *Exporter) Close() error {
+	var errs []error
+	for _, conn := range e.conns {
+		if err := conn.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	e.conns = nil
+	return errors.Join(errs...)
+}
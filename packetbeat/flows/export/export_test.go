@@ -0,0 +1,198 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+//go:build !integration
+
+package export
+
+import (
+	"encoding/binary"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/elastic/beats/v7/libbeat/beat"
+	"github.com/elastic/beats/v7/libbeat/common"
+	"github.com/elastic/beats/v7/packetbeat/config"
+	"github.com/elastic/elastic-agent-libs/mapstr"
+)
+
+func listen(t *testing.T) *net.UDPConn {
+	t.Helper()
+	conn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+func newTestExporter(t *testing.T, cfg config.FlowsExport, delta bool, collector *net.UDPConn) *Exporter {
+	t.Helper()
+	cfg.Hosts = []string{collector.LocalAddr().String()}
+	e, err := New(&cfg, delta)
+	require.NoError(t, err)
+	t.Cleanup(func() { assert.NoError(t, e.Close()) })
+	return e
+}
+
+// receive returns the next message sent to the collector.
+func receive(t *testing.T, conn *net.UDPConn) []byte {
+	t.Helper()
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+	buf := make([]byte, 65536)
+	n, err := conn.Read(buf)
+	require.NoError(t, err)
+	return buf[:n]
+}
+
+func flowEvent(id, srcIP, dstIP string, bytes uint64, final bool) beat.Event {
+	start := time.Unix(1542292881, 0)
+	return beat.Event{
+		Timestamp: start.Add(10 * time.Second),
+		Fields: mapstr.M{
+			"event": mapstr.M{
+				"start": common.Time(start),
+				"end":   common.Time(start.Add(3 * time.Second)),
+			},
+			"flow": mapstr.M{
+				"id":    common.NetString(id),
+				"final": final,
+			},
+			"network": mapstr.M{
+				"transport":    "tcp",
+				"community_id": "1:t9T66/2c66NQyftAEsr4aMZv4Hc=",
+			},
+			"source": mapstr.M{
+				"ip":      srcIP,
+				"port":    uint16(38901),
+				"bytes":   bytes,
+				"packets": uint64(1),
+			},
+			"destination": mapstr.M{
+				"ip":      dstIP,
+				"port":    uint16(80),
+				"bytes":   2 * bytes,
+				"packets": uint64(2),
+			},
+		},
+	}
+}
+
+type set struct {
+	id   uint16
+	body []byte
+}
+
+// sets returns the sets of a message.
+func sets(t *testing.T, msg []byte, headerSize int) []set {
+	t.Helper()
+	var sets []set
+	for b := msg[headerSize:]; len(b) != 0; {
+		require.GreaterOrEqual(t, len(b), setHeaderSize)
+		length := int(binary.BigEndian.Uint16(b[2:]))
+		require.LessOrEqual(t, length, len(b))
+		sets = append(sets, set{id: binary.BigEndian.Uint16(b), body: b[setHeaderSize:length]})
+		b = b[length:]
+	}
+	return sets
+}
+
+func TestExportIPFIX(t *testing.T) {
+	collector := listen(t)
+	e := newTestExporter(t, config.FlowsExport{ObservationDomainID: 7}, false, collector)
+
+	e.Export([]beat.Event{
+		flowEvent("a", "2001:db8::1", "2001:db8::2", 10, false),
+		flowEvent("b", "203.0.113.3", "198.51.100.2", 10, false),
+		{Fields: mapstr.M{"source": mapstr.M{"mac": "01-02-03-04-05-06"}}},
+	})
+	msg := receive(t, collector)
+	assert.Equal(t, uint16(versionIPFIX), binary.BigEndian.Uint16(msg))
+	assert.Equal(t, len(msg), int(binary.BigEndian.Uint16(msg[2:])))
+	assert.Equal(t, uint32(0), binary.BigEndian.Uint32(msg[8:]), "sequence")
+	assert.Equal(t, uint32(7), binary.BigEndian.Uint32(msg[12:]), "observation domain")
+
+	s := sets(t, msg, headerSizeIPFIX)
+	require.Len(t, s, 3)
+	assert.Equal(t, uint16(templateSetIPFIX), s[0].id)
+	// The IPv4 records are sent first, the flow without IP is not exported.
+	assert.Equal(t, uint16(templateIPv4), s[1].id)
+	assert.Equal(t, uint16(templateIPv6), s[2].id)
+	ipv4Len := len(s[1].body)
+
+	// The sequence number counts the data records, and cumulative counters
+	// are exported as deltas.
+	e.Export([]beat.Event{flowEvent("b", "203.0.113.3", "198.51.100.2", 25, true)})
+	msg = receive(t, collector)
+	assert.Equal(t, uint32(2), binary.BigEndian.Uint32(msg[8:]), "sequence")
+	s = sets(t, msg, headerSizeIPFIX)
+	require.Len(t, s, 2)
+	r := s[1].body
+	require.Len(t, r, ipv4Len)
+	offset := 8 + 8 + 4 + 4 + 2 + 2 + 1 + 6 + 6 + 2
+	assert.Equal(t, uint64(15+30), binary.BigEndian.Uint64(r[offset:]), "octetDeltaCount")
+	assert.Equal(t, uint64(15), binary.BigEndian.Uint64(r[offset+16:]), "initiatorOctets")
+	assert.Equal(t, uint64(30), binary.BigEndian.Uint64(r[offset+24:]), "responderOctets")
+	assert.Equal(t, byte(endReasonIdleTimeout), r[len(r)-1], "flowEndReason")
+	assert.NotContains(t, e.totals, "b")
+}
+
+func TestExportNetflow9(t *testing.T) {
+	collector := listen(t)
+	e := newTestExporter(t, config.FlowsExport{Protocol: "netflow9", EnterpriseID: 32473, MaxMessageSize: 512}, true, collector)
+
+	var events []beat.Event
+	for i := 0; i < 10; i++ {
+		events = append(events, flowEvent("a", "203.0.113.3", "198.51.100.2", 10, false))
+	}
+	e.Export(events)
+
+	// The records are split to fit the maximum message size, and only the
+	// first message has the templates.
+	var records int
+	for seq := uint32(0); records < len(events); seq++ {
+		msg := receive(t, collector)
+		assert.LessOrEqual(t, len(msg), 512)
+		assert.Equal(t, uint16(versionNetflow9), binary.BigEndian.Uint16(msg))
+		assert.Equal(t, seq, binary.BigEndian.Uint32(msg[12:]), "sequence")
+		count := int(binary.BigEndian.Uint16(msg[2:]))
+
+		s := sets(t, msg, headerSizeNetflow9)
+		if seq == 0 {
+			require.Equal(t, uint16(templateSetNetflow9), s[0].id)
+			// Enterprise elements are not supported by NetFlow v9.
+			for b := s[0].body[4:]; len(b) >= 4; b = b[4:] {
+				assert.Zero(t, binary.BigEndian.Uint16(b)&enterpriseBit)
+			}
+			count -= 2
+			s = s[1:]
+		}
+		require.Len(t, s, 1)
+		assert.Zero(t, len(s[0].body)%4, "padding")
+		records += count
+	}
+	assert.Equal(t, len(events), records)
+}
+
+func TestNewErrors(t *testing.T) {
+	_, err := New(&config.FlowsExport{Protocol: "sflow", Hosts: []string{"127.0.0.1"}}, false)
+	assert.Error(t, err)
+	_, err = New(&config.FlowsExport{MaxMessageSize: 100, Hosts: []string{"127.0.0.1"}}, false)
+	assert.Error(t, err)
+}
@@ -0,0 +1,336 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package export
+
+import (
+	"encoding/binary"
+	"time"
+)
+
+const (
+	versionNetflow9 = 9
+	versionIPFIX    = 10
+
+	headerSizeNetflow9 = 20
+	headerSizeIPFIX    = 16
+	setHeaderSize      = 4
+
+	// Set IDs of the template sets.
+	templateSetNetflow9 = 0
+	templateSetIPFIX    = 2
+
+	// Template IDs of the flow records.
+	templateIPv4 = 256
+	templateIPv6 = 257
+
+	enterpriseBit  = 0x8000
+	variableLength = 0xffff
+
+	// flowEndReason values.
+	endReasonIdleTimeout   = 1
+	endReasonActiveTimeout = 2
+)
+
+// element is an information element of the templates.
+type element struct {
+	id         uint16
+	enterprise bool
+	length     uint16
+	put        func(b []byte, r *record) []byte
+}
+
+// Information elements defined by IANA, which are also understood by
+// NetFlow v9 collectors.
+var (
+	flowStartMilliseconds = element{id: 152, length: 8, put: func(b []byte, r *record) []byte {
+		return binary.BigEndian.AppendUint64(b, uint64(r.start.UnixMilli()))
+	}}
+	flowEndMilliseconds = element{id: 153, length: 8, put: func(b []byte, r *record) []byte {
+		return binary.BigEndian.AppendUint64(b, uint64(r.end.UnixMilli()))
+	}}
+	sourceIPv4Address = element{id: 8, length: 4, put: func(b []byte, r *record) []byte {
+		return append(b, r.srcIP...)
+	}}
+	destinationIPv4Address = element{id: 12, length: 4, put: func(b []byte, r *record) []byte {
+		return append(b, r.dstIP...)
+	}}
+	sourceIPv6Address = element{id: 27, length: 16, put: func(b []byte, r *record) []byte {
+		return append(b, r.srcIP...)
+	}}
+	destinationIPv6Address = element{id: 28, length: 16, put: func(b []byte, r *record) []byte {
+		return append(b, r.dstIP...)
+	}}
+	sourceTransportPort = element{id: 7, length: 2, put: func(b []byte, r *record) []byte {
+		return binary.BigEndian.AppendUint16(b, r.srcPort)
+	}}
+	destinationTransportPort = element{id: 11, length: 2, put: func(b []byte, r *record) []byte {
+		return binary.BigEndian.AppendUint16(b, r.dstPort)
+	}}
+	protocolIdentifier = element{id: 4, length: 1, put: func(b []byte, r *record) []byte {
+		return append(b, r.protocol)
+	}}
+	sourceMacAddress = element{id: 56, length: 6, put: func(b []byte, r *record) []byte {
+		return appendMAC(b, r.srcMAC)
+	}}
+	destinationMacAddress = element{id: 80, length: 6, put: func(b []byte, r *record) []byte {
+		return appendMAC(b, r.dstMAC)
+	}}
+	vlanID = element{id: 58, length: 2, put: func(b []byte, r *record) []byte {
+		return binary.BigEndian.AppendUint16(b, r.vlan)
+	}}
+	octetDeltaCount = element{id: 1, length: 8, put: func(b []byte, r *record) []byte {
+		return binary.BigEndian.AppendUint64(b, r.srcBytes+r.dstBytes)
+	}}
+	packetDeltaCount = element{id: 2, length: 8, put: func(b []byte, r *record) []byte {
+		return binary.BigEndian.AppendUint64(b, r.srcPackets+r.dstPackets)
+	}}
+	initiatorOctets = element{id: 231, length: 8, put: func(b []byte, r *record) []byte {
+		return binary.BigEndian.AppendUint64(b, r.srcBytes)
+	}}
+	responderOctets = element{id: 232, length: 8, put: func(b []byte, r *record) []byte {
+		return binary.BigEndian.AppendUint64(b, r.dstBytes)
+	}}
+	initiatorPackets = element{id: 298, length: 8, put: func(b []byte, r *record) []byte {
+		return binary.BigEndian.AppendUint64(b, r.srcPackets)
+	}}
+	responderPackets = element{id: 299, length: 8, put: func(b []byte, r *record) []byte {
+		return binary.BigEndian.AppendUint64(b, r.dstPackets)
+	}}
+	flowEndReason = element{id: 136, length: 1, put: func(b []byte, r *record) []byte {
+		if r.final {
+			return append(b, endReasonIdleTimeout)
+		}
+		return append(b, endReasonActiveTimeout)
+	}}
+)
+
+// Enterprise specific information elements, exported with the configured
+// private enterprise number.
+var enterpriseElements = []element{
+	{id: 1, enterprise: true, length: variableLength, put: func(b []byte, r *record) []byte {
+		return appendString(b, r.communityID)
+	}},
+	{id: 2, enterprise: true, length: 4, put: func(b []byte, r *record) []byte {
+		return binary.BigEndian.AppendUint32(b, r.srcProcess.pid)
+	}},
+	{id: 3, enterprise: true, length: variableLength, put: func(b []byte, r *record) []byte {
+		return appendString(b, r.srcProcess.name)
+	}},
+	{id: 4, enterprise: true, length: variableLength, put: func(b []byte, r *record) []byte {
+		return appendString(b, r.srcProcess.exe)
+	}},
+	{id: 5, enterprise: true, length: 4, put: func(b []byte, r *record) []byte {
+		return binary.BigEndian.AppendUint32(b, r.dstProcess.pid)
+	}},
+	{id: 6, enterprise: true, length: variableLength, put: func(b []byte, r *record) []byte {
+		return appendString(b, r.dstProcess.name)
+	}},
+	{id: 7, enterprise: true, length: variableLength, put: func(b []byte, r *record) []byte {
+		return appendString(b, r.dstProcess.exe)
+	}},
+}
+
+// template is the template of the flow records of an IP version.
+type template struct {
+	id       uint16
+	elements []element
+}
+
+// newTemplates returns the templates of the IPv4 and IPv6 flow records. The
+// enterprise elements are only included if enterprise is set.
+func newTemplates(enterprise bool) (ipv4, ipv6 *template) {
+	newTemplate := func(id uint16, src, dst element) *template {
+		elements := []element{
+			flowStartMilliseconds, flowEndMilliseconds,
+			src, dst,
+			sourceTransportPort, destinationTransportPort, protocolIdentifier,
+			sourceMacAddress, destinationMacAddress, vlanID,
+			octetDeltaCount, packetDeltaCount,
+			initiatorOctets, responderOctets, initiatorPackets, responderPackets,
+			flowEndReason,
+		}
+		if enterprise {
+			elements = append(elements, enterpriseElements...)
+		}
+		return &template{id: id, elements: elements}
+	}
+	return newTemplate(templateIPv4, sourceIPv4Address, destinationIPv4Address),
+		newTemplate(templateIPv6, sourceIPv6Address, destinationIPv6Address)
+}
+
+// appendTemplate appends the template record of t.
+func (t *template) appendTemplate(b []byte, pen uint32) []byte {
+	b = binary.BigEndian.AppendUint16(b, t.id)
+	b = binary.BigEndian.AppendUint16(b, uint16(len(t.elements)))
+	for _, e := range t.elements {
+		if e.enterprise {
+			b = binary.BigEndian.AppendUint16(b, e.id|enterpriseBit)
+			b = binary.BigEndian.AppendUint16(b, e.length)
+			b = binary.BigEndian.AppendUint32(b, pen)
+			continue
+		}
+		b = binary.BigEndian.AppendUint16(b, e.id)
+		b = binary.BigEndian.AppendUint16(b, e.length)
+	}
+	return b
+}
+
+// appendRecord appends the data record of r.
+func (t *template) appendRecord(b []byte, r *record) []byte {
+	for _, e := range t.elements {
+		b = e.put(b, r)
+	}
+	return b
+}
+
+func appendMAC(b []byte, mac []byte) []byte {
+	if len(mac) != 6 {
+		return append(b, 0, 0, 0, 0, 0, 0)
+	}
+	return append(b, mac...)
+}
+
+// appendString appends a variable length string element.
+func appendString(b []byte, s string) []byte {
+	const maxLength = variableLength - 3
+	if len(s) > maxLength {
+		s = s[:maxLength]
+	}
+	if len(s) < 255 {
+		b = append(b, byte(len(s)))
+	} else {
+		b = append(b, 255)
+		b = binary.BigEndian.AppendUint16(b, uint16(len(s)))
+	}
+	return append(b, s...)
+}
+
+// message builds the messages sent to the collectors.
+type message struct {
+	version uint16
+	domain  uint32
+
+	buf []byte
+	// set is the offset of the header of the current set, or -1.
+	set   int
+	setID uint16
+	// records is the number of template and data records of the message,
+	// and data the number of data records.
+	records int
+	data    int
+}
+
+func (m *message) headerSize() int {
+	if m.version == versionNetflow9 {
+		return headerSizeNetflow9
+	}
+	return headerSizeIPFIX
+}
+
+// reset starts a new message.
+func (m *message) reset() {
+	m.buf = append(m.buf[:0], make([]byte, m.headerSize())...)
+	m.set = -1
+	m.records = 0
+	m.data = 0
+}
+
+func (m *message) empty() bool {
+	return m.records == 0
+}
+
+// startSet starts a set of records, closing the current one.
+func (m *message) startSet(id uint16) {
+	m.endSet()
+	m.set = len(m.buf)
+	m.setID = id
+	m.buf = append(m.buf, 0, 0, 0, 0)
+}
+
+// endSet writes the header of the current set. NetFlow v9 sets are padded to
+// 32 bits. IPFIX sets aren't, as their padding could be mistaken for variable
+// length records.
+func (m *message) endSet() {
+	if m.set < 0 {
+		return
+	}
+	if m.version == versionNetflow9 {
+		for (len(m.buf)-m.set)%4 != 0 {
+			m.buf = append(m.buf, 0)
+		}
+	}
+	binary.BigEndian.PutUint16(m.buf[m.set:], m.setID)
+	binary.BigEndian.PutUint16(m.buf[m.set+2:], uint16(len(m.buf)-m.set))
+	m.set = -1
+}
+
+// appendTemplates appends a template set with the templates.
+func (m *message) appendTemplates(pen uint32, templates ...*template) {
+	id := uint16(templateSetIPFIX)
+	if m.version == versionNetflow9 {
+		id = templateSetNetflow9
+	}
+	m.startSet(id)
+	for _, t := range templates {
+		m.buf = t.appendTemplate(m.buf, pen)
+		m.records++
+	}
+	m.endSet()
+}
+
+// appendRecord appends an encoded data record of template id.
+func (m *message) appendRecord(id uint16, data []byte) {
+	if m.set < 0 || m.setID != id {
+		m.startSet(id)
+	}
+	m.buf = append(m.buf, data...)
+	m.records++
+	m.data++
+}
+
+// fits returns whether a data record of size bytes fits in the message
+// without exceeding maxSize bytes.
+func (m *message) fits(id uint16, size, maxSize int) bool {
+	if m.set < 0 || m.setID != id {
+		size += setHeaderSize
+	}
+	// Account for the NetFlow v9 padding.
+	return len(m.buf)+size+3 <= maxSize
+}
+
+// finish writes the message header and returns the encoded message. seq is
+// the sequence number of the message, and uptime the time elapsed since the
+// exporter started.
+func (m *message) finish(now time.Time, seq uint32, uptime time.Duration) []byte {
+	m.endSet()
+	h := m.buf
+	binary.BigEndian.PutUint16(h[0:], m.version)
+	if m.version == versionNetflow9 {
+		binary.BigEndian.PutUint16(h[2:], uint16(m.records))
+		binary.BigEndian.PutUint32(h[4:], uint32(uptime.Milliseconds()))
+		binary.BigEndian.PutUint32(h[8:], uint32(now.Unix()))
+		binary.BigEndian.PutUint32(h[12:], seq)
+		binary.BigEndian.PutUint32(h[16:], m.domain)
+	} else {
+		binary.BigEndian.PutUint16(h[2:], uint16(len(m.buf)))
+		binary.BigEndian.PutUint32(h[4:], uint32(now.Unix()))
+		binary.BigEndian.PutUint32(h[8:], seq)
+		binary.BigEndian.PutUint32(h[12:], m.domain)
+	}
+	return m.buf
+}
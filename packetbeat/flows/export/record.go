@@ -0,0 +1,157 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package export
+
+import (
+	"net"
+	"time"
+
+	"github.com/elastic/beats/v7/libbeat/beat"
+	"github.com/elastic/beats/v7/libbeat/common"
+	"github.com/elastic/elastic-agent-libs/mapstr"
+)
+
+// record is a flow report, as exported to the collectors.
+type record struct {
+	id         string
+	start, end time.Time
+	final      bool
+
+	srcIP, dstIP     net.IP
+	srcPort, dstPort uint16
+	protocol         uint8
+	srcMAC, dstMAC   net.HardwareAddr
+	vlan             uint16
+
+	// Counters of the packets sent by the initiator and the responder of
+	// the flow since the previous report.
+	srcBytes, dstBytes     uint64
+	srcPackets, dstPackets uint64
+
+	communityID            string
+	srcProcess, dstProcess process
+}
+
+type process struct {
+	pid  uint32
+	name string
+	exe  string
+}
+
+// isIPv6 returns whether the flow is exported with the IPv6 template.
+func (r *record) isIPv6() bool {
+	return r.srcIP.To4() == nil
+}
+
+// protocolNumbers are the IANA protocol numbers of the network.transport
+// values of the flow events.
+var protocolNumbers = map[string]uint8{
+	"icmp":      1,
+	"tcp":       6,
+	"udp":       17,
+	"ipv6-icmp": 58,
+}
+
+// newRecord returns the record of a flow event. Flows without IP layer can't
+// be exported and are ignored. For tunneled flows, the addresses of the outer
+// layer are exported.
+func newRecord(event *beat.Event) (*record, bool) {
+	f := event.Fields
+	srcIP := net.ParseIP(first(f, "source.ip"))
+	dstIP := net.ParseIP(first(f, "destination.ip"))
+	if srcIP == nil || dstIP == nil || (srcIP.To4() == nil) != (dstIP.To4() == nil) {
+		return nil, false
+	}
+	if v4 := srcIP.To4(); v4 != nil {
+		srcIP, dstIP = v4, dstIP.To4()
+	}
+	r := &record{
+		srcIP: srcIP,
+		dstIP: dstIP,
+	}
+	if id, err := f.GetValue("flow.id"); err == nil {
+		if id, ok := id.(common.NetString); ok {
+			r.id = string(id)
+		}
+	}
+	if v, err := f.GetValue("event.start"); err == nil {
+		if ts, ok := v.(common.Time); ok {
+			r.start = time.Time(ts)
+		}
+	}
+	if v, err := f.GetValue("event.end"); err == nil {
+		if ts, ok := v.(common.Time); ok {
+			r.end = time.Time(ts)
+		}
+	}
+	if v, err := f.GetValue("flow.final"); err == nil {
+		r.final, _ = v.(bool)
+	}
+	switch vlan := get(f, "flow.vlan").(type) {
+	case uint64:
+		r.vlan = uint16(vlan)
+	case []uint64:
+		r.vlan = uint16(vlan[0])
+	}
+	r.srcMAC, _ = net.ParseMAC(first(f, "source.mac"))
+	r.dstMAC, _ = net.ParseMAC(first(f, "destination.mac"))
+	r.srcPort, _ = get(f, "source.port").(uint16)
+	r.dstPort, _ = get(f, "destination.port").(uint16)
+	r.protocol = protocolNumbers[first(f, "network.transport")]
+	r.srcBytes, _ = get(f, "source.bytes").(uint64)
+	r.dstBytes, _ = get(f, "destination.bytes").(uint64)
+	r.srcPackets, _ = get(f, "source.packets").(uint64)
+	r.dstPackets, _ = get(f, "destination.packets").(uint64)
+	r.communityID = first(f, "network.community_id")
+	r.srcProcess = newProcess(f, "source.process")
+	r.dstProcess = newProcess(f, "destination.process")
+	return r, true
+}
+
+func newProcess(f mapstr.M, key string) process {
+	var p process
+	if pid, ok := get(f, key+".pid").(int); ok && pid > 0 {
+		p.pid = uint32(pid)
+	}
+	p.name = first(f, key+".name")
+	p.exe = first(f, key+".executable")
+	return p
+}
+
+// get returns the value of key, or nil if it's not set.
+func get(f mapstr.M, key string) interface{} {
+	v, err := f.GetValue(key)
+	if err != nil {
+		return nil
+	}
+	return v
+}
+
+// first returns the value of a string field, or its first value if it
+// holds several values.
+func first(f mapstr.M, key string) string {
+	switch v := get(f, key).(type) {
+	case string:
+		return v
+	case []string:
+		if len(v) != 0 {
+			return v[0]
+		}
+	}
+	return ""
+}
@@ -0,0 +1,36 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+// Code generated by beats/dev-tools/cmd/asset/asset.go - DO NOT EDIT.
+
+package http2
+
+import (
+	"github.com/elastic/beats/v7/libbeat/asset"
+)
+
+func init() {
+	if err := asset.SetFields("packetbeat", "http2", asset.ModuleFieldsPri, AssetHttp2); err != nil {
+		panic(err)
+	}
+}
+
+// AssetHttp2 returns asset data.
+// This is the base64 encoded zlib format compressed contents of protos/http2.
+func AssetHttp2() string {
+	return "eJy0lE9r20AQxe/6FI+cYxdy9KEQHIdCWzvYytks2pG0eKWVZ0dJ/e2L/qwjxSJtcMtexOzOm9/MPDTDgU4L5CLVXQSIEUsL3HyL46cvdzcRoMknbCoxrlzgawQA3SVUqZFtn5bwFSUmNQnohUpBashqP4/Qfy3apBlKVdBbpebIqaIFMnZ11UeGGcMsL0yq2Bt9vgnZ1pXZIDiBG06cE4ymUkxqiOFSSE6hma5ACDIda/LStsjkK1d6mkcXVMTseJ84TYNCHdaBTq+O9d+TtVpotALEdhfvd/F2df8TKauCYLp4j/qqPJg8ye1IK3UM+qWKyhKW9+vl6gccY7t6fN6tHnq9vpXQRsZV8vmNEL+Y5B80ntbWnnCslW32olv5MILOXl2lW4g6UImUXdHMYSQUFlYpyScWVZDkTl/PesHWCb9H+xOOFyW1nzbO5/zcQnRyrXcuSJrdzvoHwsrYs/NHWh+YvEv+D7MbYQ9tu/neWHa9ifePm+f1wwRTQd6rjK6H6hl6vRFeoqydHudldbyb7UfzFFOQq+V69l5oAlp5+OZPbMo36PA6J6WJR2Jn5mNNXubR7wEAZJ64eQ=="
+}
@@ -0,0 +1,175 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package http2
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// clientPreface is sent by the client at the start of a HTTP/2 connection.
+const clientPreface = "PRI * HTTP/2.0\r\n\r\nSM\r\n\r\n"
+
+const frameHeaderLen = 9
+
+type frameType uint8
+
+// Frame types, https://www.rfc-editor.org/rfc/rfc9113#section-6
+const (
+	frameData         frameType = 0x0
+	frameHeaders      frameType = 0x1
+	framePriority     frameType = 0x2
+	frameRSTStream    frameType = 0x3
+	frameSettings     frameType = 0x4
+	framePushPromise  frameType = 0x5
+	framePing         frameType = 0x6
+	frameGoAway       frameType = 0x7
+	frameWindowUpdate frameType = 0x8
+	frameContinuation frameType = 0x9
+)
+
+// Frame flags
+const (
+	flagEndStream  = 0x1
+	flagAck        = 0x1
+	flagEndHeaders = 0x4
+	flagPadded     = 0x8
+	flagPriority   = 0x20
+)
+
+// settingHeaderTableSize is the identifier of SETTINGS_HEADER_TABLE_SIZE.
+const settingHeaderTableSize = 0x1
+
+// Error codes used in RST_STREAM and GOAWAY frames.
+var errorCodes = []string{
+	"NO_ERROR",
+	"PROTOCOL_ERROR",
+	"INTERNAL_ERROR",
+	"FLOW_CONTROL_ERROR",
+	"SETTINGS_TIMEOUT",
+	"STREAM_CLOSED",
+	"FRAME_SIZE_ERROR",
+	"REFUSED_STREAM",
+	"CANCEL",
+	"COMPRESSION_ERROR",
+	"CONNECT_ERROR",
+	"ENHANCE_YOUR_CALM",
+	"INADEQUATE_SECURITY",
+	"HTTP_1_1_REQUIRED",
+}
+
+func errorCodeName(code uint32) string {
+	if int(code) < len(errorCodes) {
+		return errorCodes[code]
+	}
+	return fmt.Sprintf("UNKNOWN_ERROR_0x%x", code)
+}
+
+type frameHeader struct {
+	length   int
+	typ      frameType
+	flags    uint8
+	streamID uint32
+}
+
+func parseFrameHeader(b []byte) frameHeader {
+	return frameHeader{
+		length:   int(b[0])<<16 | int(b[1])<<8 | int(b[2]),
+		typ:      frameType(b[3]),
+		flags:    b[4],
+		streamID: binary.BigEndian.Uint32(b[5:9]) & 0x7fffffff,
+	}
+}
+
+func (h frameHeader) has(flag uint8) bool {
+	return h.flags&flag != 0
+}
+
+// unpad returns the payload of a DATA, HEADERS or PUSH_PROMISE frame without
+// its padding.
+func unpad(h frameHeader, payload []byte) ([]byte, error) {
+	if !h.has(flagPadded) {
+		return payload, nil
+	}
+	if len(payload) == 0 {
+		return nil, fmt.Errorf("missing pad length in %v frame", h.typ)
+	}
+	padding := int(payload[0])
+	if padding >= len(payload) {
+		return nil, fmt.Errorf("invalid pad length %d in %v frame", padding, h.typ)
+	}
+	return payload[1 : len(payload)-padding], nil
+}
+
+// headerBlockFragment returns the header block fragment of a HEADERS frame.
+func headerBlockFragment(h frameHeader, payload []byte) ([]byte, error) {
+	payload, err := unpad(h, payload)
+	if err != nil {
+		return nil, err
+	}
+	if h.has(flagPriority) {
+		// Stream dependency and weight.
+		if len(payload) < 5 {
+			return nil, fmt.Errorf("invalid priority in HEADERS frame of stream %d", h.streamID)
+		}
+		payload = payload[5:]
+	}
+	return payload, nil
+}
+
+// parseHeaderTableSize returns the value of SETTINGS_HEADER_TABLE_SIZE if it
+// is set in the payload of a SETTINGS frame.
+func parseHeaderTableSize(payload []byte) (uint32, bool) {
+	var (
+		size  uint32
+		found bool
+	)
+	for len(payload) >= 6 {
+		if binary.BigEndian.Uint16(payload) == settingHeaderTableSize {
+			size, found = binary.BigEndian.Uint32(payload[2:6]), true
+		}
+		payload = payload[6:]
+	}
+	return size, found
+}
+
+func (t frameType) String() string {
+	switch t {
+	case frameData:
+		return "DATA"
+	case frameHeaders:
+		return "HEADERS"
+	case framePriority:
+		return "PRIORITY"
+	case frameRSTStream:
+		return "RST_STREAM"
+	case frameSettings:
+		return "SETTINGS"
+	case framePushPromise:
+		return "PUSH_PROMISE"
+	case framePing:
+		return "PING"
+	case frameGoAway:
+		return "GOAWAY"
+	case frameWindowUpdate:
+		return "WINDOW_UPDATE"
+	case frameContinuation:
+		return "CONTINUATION"
+	}
+	return fmt.Sprintf("UNKNOWN_FRAME_TYPE_%d", uint8(t))
+}
@@ -0,0 +1,114 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package http2
+
+import (
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/elastic/elastic-agent-libs/mapstr"
+)
+
+// Status codes of gRPC, https://grpc.github.io/grpc/core/md_doc_statuscodes.html
+var grpcStatusCodes = []string{
+	"OK",
+	"CANCELLED",
+	"UNKNOWN",
+	"INVALID_ARGUMENT",
+	"DEADLINE_EXCEEDED",
+	"NOT_FOUND",
+	"ALREADY_EXISTS",
+	"PERMISSION_DENIED",
+	"RESOURCE_EXHAUSTED",
+	"FAILED_PRECONDITION",
+	"ABORTED",
+	"OUT_OF_RANGE",
+	"UNIMPLEMENTED",
+	"INTERNAL",
+	"UNAVAILABLE",
+	"DATA_LOSS",
+	"UNAUTHENTICATED",
+}
+
+// isGRPC returns true if the content type is the one of gRPC, as
+// application/grpc or application/grpc+proto.
+func isGRPC(contentType string) bool {
+	return contentType == "application/grpc" ||
+		strings.HasPrefix(contentType, "application/grpc+") ||
+		strings.HasPrefix(contentType, "application/grpc;")
+}
+
+// parseGRPCPath returns the service and method of a gRPC call from its path,
+// that has the form /<package>.<service>/<method>.
+func parseGRPCPath(path string) (service, method string, ok bool) {
+	path, ok = strings.CutPrefix(path, "/")
+	if !ok {
+		return "", "", false
+	}
+	service, method, ok = strings.Cut(path, "/")
+	if !ok || service == "" || method == "" || strings.Contains(method, "/") {
+		return "", "", false
+	}
+	return service, method, true
+}
+
+// grpcFields returns the gRPC fields of a call, the status is read from the
+// trailers of the response, or from its headers in a Trailers-Only response.
+func grpcFields(requ, resp *message) (fields mapstr.M, code int, hasCode bool) {
+	fields = mapstr.M{}
+	if requ != nil {
+		if service, method, ok := parseGRPCPath(requ.path); ok {
+			fields["service"] = service
+			fields["method"] = method
+		}
+		if timeout := requ.header("grpc-timeout"); timeout != "" {
+			fields["timeout"] = timeout
+		}
+	}
+	if resp != nil {
+		status := resp.trailer("grpc-status")
+		if status == "" {
+			status = resp.header("grpc-status")
+		}
+		if c, err := strconv.Atoi(status); err == nil {
+			code, hasCode = c, true
+			fields["status_code"] = code
+			fields["status"] = grpcStatusName(code)
+		}
+
+		message := resp.trailer("grpc-message")
+		if message == "" {
+			message = resp.header("grpc-message")
+		}
+		if message != "" {
+			if decoded, err := url.PathUnescape(message); err == nil {
+				message = decoded
+			}
+			fields["message"] = message
+		}
+	}
+	return fields, code, hasCode
+}
+
+func grpcStatusName(code int) string {
+	if code >= 0 && code < len(grpcStatusCodes) {
+		return grpcStatusCodes[code]
+	}
+	return "UNKNOWN"
+}
@@ -0,0 +1,64 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+//go:build !integration
+
+package http2
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseGRPCPath(t *testing.T) {
+	tests := []struct {
+		path    string
+		service string
+		method  string
+		ok      bool
+	}{
+		{path: "/helloworld.Greeter/SayHello", service: "helloworld.Greeter", method: "SayHello", ok: true},
+		{path: "/Service/Method", service: "Service", method: "Method", ok: true},
+		{path: "helloworld.Greeter/SayHello"},
+		{path: "/helloworld.Greeter"},
+		{path: "/helloworld.Greeter/"},
+		{path: "/a/b/c"},
+	}
+	for _, test := range tests {
+		service, method, ok := parseGRPCPath(test.path)
+		assert.Equal(t, test.ok, ok, test.path)
+		assert.Equal(t, test.service, service, test.path)
+		assert.Equal(t, test.method, method, test.path)
+	}
+}
+
+func TestIsGRPC(t *testing.T) {
+	assert.True(t, isGRPC("application/grpc"))
+	assert.True(t, isGRPC("application/grpc+proto"))
+	assert.True(t, isGRPC("application/grpc+json"))
+	assert.False(t, isGRPC("application/grpc-web"))
+	assert.False(t, isGRPC("application/json"))
+}
+
+func TestGRPCStatusName(t *testing.T) {
+	assert.Equal(t, "OK", grpcStatusName(0))
+	assert.Equal(t, "UNAVAILABLE", grpcStatusName(14))
+	assert.Equal(t, "UNAUTHENTICATED", grpcStatusName(16))
+	assert.Equal(t, "UNKNOWN", grpcStatusName(17))
+	assert.Equal(t, "UNKNOWN", grpcStatusName(-1))
+}
@@ -0,0 +1,784 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package http2
+
+import (
+	"encoding/binary"
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/net/http2/hpack"
+
+	"github.com/elastic/beats/v7/libbeat/beat"
+	"github.com/elastic/beats/v7/libbeat/common"
+	"github.com/elastic/beats/v7/libbeat/ecs"
+	conf "github.com/elastic/elastic-agent-libs/config"
+	"github.com/elastic/elastic-agent-libs/logp"
+	"github.com/elastic/elastic-agent-libs/mapstr"
+	"github.com/elastic/elastic-agent-libs/monitoring"
+
+	"github.com/elastic/beats/v7/packetbeat/pb"
+	"github.com/elastic/beats/v7/packetbeat/procs"
+	"github.com/elastic/beats/v7/packetbeat/protos"
+	"github.com/elastic/beats/v7/packetbeat/protos/applayer"
+	"github.com/elastic/beats/v7/packetbeat/protos/http"
+	"github.com/elastic/beats/v7/packetbeat/protos/tcp"
+)
+
+// initialHeaderTableSize is the default size of the HPACK dynamic table,
+// until it is changed with SETTINGS_HEADER_TABLE_SIZE.
+const initialHeaderTableSize = 4096
+
+type stream struct {
+	applayer.Stream
+	// prefaceChecked is set once the start of the stream has been checked
+	// for the client connection preface.
+	prefaceChecked bool
+}
+
+// headerBlock is a header block received in a HEADERS or PUSH_PROMISE frame
+// and the CONTINUATION frames that follow it.
+type headerBlock struct {
+	typ       frameType
+	streamID  uint32
+	endStream bool
+	fragment  []byte
+	size      int
+	ts        time.Time
+}
+
+type connectionData struct {
+	streams [2]*stream
+	// decoders keep the HPACK dynamic table of the header blocks sent in
+	// each direction.
+	decoders     [2]*hpack.Decoder
+	headerBlocks [2]*headerBlock
+	transactions map[uint32]*transaction
+}
+
+// message is a request or a response of a stream.
+type message struct {
+	ts           time.Time
+	endTs        time.Time
+	direction    uint8
+	tcpTuple     common.TCPTuple
+	cmdlineTuple *common.ProcessTuple
+
+	method     string
+	scheme     string
+	authority  string
+	path       string
+	statusCode int
+	headers    []hpack.HeaderField
+	trailers   []hpack.HeaderField
+
+	size     int
+	bodySize int
+	ended    bool
+}
+
+// transaction is a request and its response, exchanged in a stream.
+type transaction struct {
+	streamID  uint32
+	request   *message
+	response  *message
+	resetCode string
+	resetTs   time.Time
+}
+
+// HTTP/2 protocol plugin
+type http2Plugin struct {
+	// config
+	ports              []int
+	sendRequest        bool
+	sendResponse       bool
+	sendHeaders        bool
+	headersWhitelist   map[string]bool
+	maxOpenStreams     int
+	transactionTimeout time.Duration
+
+	watcher *procs.ProcessesWatcher
+	results protos.Reporter
+}
+
+var (
+	debugf  = logp.MakeDebug("http2")
+	isDebug = false
+)
+
+var (
+	unmatchedResponses = monitoring.NewInt(nil, "http2.unmatched_responses")
+	droppedStreams     = monitoring.NewInt(nil, "http2.dropped_streams")
+)
+
+func init() {
+	protos.Register("http2", New)
+}
+
+func New(
+	testMode bool,
+	results protos.Reporter,
+	watcher *procs.ProcessesWatcher,
+	cfg *conf.C,
+) (protos.Plugin, error) {
+	p := &http2Plugin{}
+	config := defaultConfig
+	if !testMode {
+		if err := cfg.Unpack(&config); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := p.init(results, watcher, &config); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+func (h2 *http2Plugin) init(results protos.Reporter, watcher *procs.ProcessesWatcher, config *http2Config) error {
+	h2.setFromConfig(config)
+
+	h2.results = results
+	h2.watcher = watcher
+	isDebug = logp.IsDebug("http2")
+
+	return nil
+}
+
+func (h2 *http2Plugin) setFromConfig(config *http2Config) {
+	h2.ports = config.Ports
+	h2.sendRequest = config.SendRequest
+	h2.sendResponse = config.SendResponse
+	h2.maxOpenStreams = config.MaxOpenStreams
+	h2.transactionTimeout = config.TransactionTimeout
+
+	if config.SendAllHeaders {
+		h2.sendHeaders = true
+		h2.headersWhitelist = nil
+	} else if len(config.SendHeaders) > 0 {
+		h2.sendHeaders = true
+		h2.headersWhitelist = map[string]bool{}
+		for _, hdr := range config.SendHeaders {
+			h2.headersWhitelist[strings.ToLower(hdr)] = true
+		}
+	}
+}
+
+func (h2 *http2Plugin) GetPorts() []int {
+	return h2.ports
+}
+
+func (h2 *http2Plugin) ConnectionTimeout() time.Duration {
+	return h2.transactionTimeout
+}
+
+func (h2 *http2Plugin) Parse(
+	pkt *protos.Packet,
+	tcptuple *common.TCPTuple,
+	dir uint8,
+	private protos.ProtocolData,
+) protos.ProtocolData {
+	conn := ensureConnection(private)
+	conn = h2.doParse(conn, pkt, tcptuple, dir)
+	if conn == nil {
+		return nil
+	}
+	return conn
+}
+
+func newConnectionData() *connectionData {
+	conn := &connectionData{
+		transactions: map[uint32]*transaction{},
+	}
+	for dir := range conn.decoders {
+		conn.decoders[dir] = hpack.NewDecoder(initialHeaderTableSize, nil)
+		conn.decoders[dir].SetMaxStringLength(tcp.TCPMaxDataInStream)
+	}
+	return conn
+}
+
+func ensureConnection(private protos.ProtocolData) *connectionData {
+	if private == nil {
+		return newConnectionData()
+	}
+
+	priv, ok := private.(*connectionData)
+	if !ok {
+		logp.Warn("http2 connection data type error, create new one")
+		return newConnectionData()
+	}
+	if priv == nil {
+		logp.Warn("Unexpected: http2 connection data not set, create new one")
+		return newConnectionData()
+	}
+
+	return priv
+}
+
+func (h2 *http2Plugin) doParse(
+	conn *connectionData,
+	pkt *protos.Packet,
+	tcptuple *common.TCPTuple,
+	dir uint8,
+) *connectionData {
+	st := conn.streams[dir]
+	if st == nil {
+		st = &stream{}
+		st.Stream.Init(tcp.TCPMaxDataInStream)
+		conn.streams[dir] = st
+		if isDebug {
+			debugf("new stream: %p (dir=%v, len=%v)", st, dir, len(pkt.Payload))
+		}
+	}
+
+	if err := st.Append(pkt.Payload); err != nil {
+		if isDebug {
+			debugf("%v, dropping TCP stream: ", err)
+		}
+		return nil
+	}
+
+	if !st.prefaceChecked {
+		buf := st.Buf.Bytes()
+		n := min(len(buf), len(clientPreface))
+		if string(buf[:n]) == clientPreface[:n] {
+			if n < len(clientPreface) {
+				// wait for the rest of the preface
+				return conn
+			}
+			_ = st.Buf.Advance(len(clientPreface))
+		}
+		st.prefaceChecked = true
+	}
+
+	for st.Buf.Len() >= frameHeaderLen {
+		hdr := parseFrameHeader(st.Buf.Bytes())
+		if !st.Buf.Avail(frameHeaderLen + hdr.length) {
+			// wait for more data
+			break
+		}
+
+		frame, err := st.Buf.Collect(frameHeaderLen + hdr.length)
+		if err == nil {
+			err = h2.handleFrame(conn, hdr, frame[frameHeaderLen:], pkt.Ts, tcptuple, dir)
+		}
+		if err != nil {
+			// The state of the HPACK decoders is lost, drop the connection.
+			if isDebug {
+				debugf("%v, dropping HTTP/2 connection", err)
+			}
+			return nil
+		}
+		st.Reset()
+	}
+
+	return conn
+}
+
+func (h2 *http2Plugin) handleFrame(
+	conn *connectionData,
+	hdr frameHeader,
+	payload []byte,
+	ts time.Time,
+	tcptuple *common.TCPTuple,
+	dir uint8,
+) error {
+	if block := conn.headerBlocks[dir]; block != nil && (hdr.typ != frameContinuation || hdr.streamID != block.streamID) {
+		return fmt.Errorf("expected CONTINUATION frame of stream %d, got %v frame of stream %d", block.streamID, hdr.typ, hdr.streamID)
+	}
+
+	size := frameHeaderLen + len(payload)
+	switch hdr.typ {
+	case frameData:
+		data, err := unpad(hdr, payload)
+		if err != nil {
+			return err
+		}
+		if m := conn.message(hdr.streamID, dir); m != nil {
+			m.size += size
+			m.bodySize += len(data)
+			if hdr.has(flagEndStream) {
+				h2.endMessage(conn, hdr.streamID, m, ts)
+			}
+		}
+	case frameHeaders:
+		fragment, err := headerBlockFragment(hdr, payload)
+		if err != nil {
+			return err
+		}
+		conn.headerBlocks[dir] = &headerBlock{
+			typ:       frameHeaders,
+			streamID:  hdr.streamID,
+			endStream: hdr.has(flagEndStream),
+			ts:        ts,
+		}
+		return h2.continueHeaderBlock(conn, hdr, fragment, size, tcptuple, dir)
+	case framePushPromise:
+		fragment, err := unpad(hdr, payload)
+		if err != nil {
+			return err
+		}
+		if len(fragment) < 4 {
+			return fmt.Errorf("missing promised stream ID in PUSH_PROMISE frame of stream %d", hdr.streamID)
+		}
+		conn.headerBlocks[dir] = &headerBlock{
+			typ:      framePushPromise,
+			streamID: hdr.streamID,
+			ts:       ts,
+		}
+		return h2.continueHeaderBlock(conn, hdr, fragment[4:], size, tcptuple, dir)
+	case frameContinuation:
+		if conn.headerBlocks[dir] == nil {
+			return fmt.Errorf("unexpected CONTINUATION frame of stream %d", hdr.streamID)
+		}
+		return h2.continueHeaderBlock(conn, hdr, payload, size, tcptuple, dir)
+	case frameRSTStream:
+		if len(payload) != 4 {
+			return fmt.Errorf("invalid RST_STREAM frame of stream %d", hdr.streamID)
+		}
+		h2.resetStream(conn, hdr.streamID, errorCodeName(binary.BigEndian.Uint32(payload)), ts)
+	case frameSettings:
+		if hdr.has(flagAck) {
+			break
+		}
+		if tableSize, ok := parseHeaderTableSize(payload); ok {
+			// The setting limits the table of the encoder of the peer, used
+			// by the header blocks sent in the other direction.
+			conn.decoders[1-dir].SetAllowedMaxDynamicTableSize(tableSize)
+		}
+	}
+	return nil
+}
+
+func (h2 *http2Plugin) continueHeaderBlock(
+	conn *connectionData,
+	hdr frameHeader,
+	fragment []byte,
+	size int,
+	tcptuple *common.TCPTuple,
+	dir uint8,
+) error {
+	block := conn.headerBlocks[dir]
+	block.fragment = append(block.fragment, fragment...)
+	block.size += size
+	if len(block.fragment) > tcp.TCPMaxDataInStream {
+		conn.headerBlocks[dir] = nil
+		return fmt.Errorf("header block of stream %d exceeds %d bytes", block.streamID, tcp.TCPMaxDataInStream)
+	}
+	if !hdr.has(flagEndHeaders) {
+		// wait for CONTINUATION frames
+		return nil
+	}
+	conn.headerBlocks[dir] = nil
+
+	// Header blocks must always be decoded to keep the dynamic table in sync
+	// with the one of the encoder.
+	fields, err := conn.decoders[dir].DecodeFull(block.fragment)
+	if err != nil {
+		return fmt.Errorf("failed to decode header block of stream %d: %w", block.streamID, err)
+	}
+	if block.typ == framePushPromise {
+		return nil
+	}
+
+	h2.handleHeaders(conn, block, fields, tcptuple, dir)
+	return nil
+}
+
+func (h2 *http2Plugin) handleHeaders(
+	conn *connectionData,
+	block *headerBlock,
+	fields []hpack.HeaderField,
+	tcptuple *common.TCPTuple,
+	dir uint8,
+) {
+	m := &message{
+		ts:        block.ts,
+		direction: dir,
+		tcpTuple:  *tcptuple,
+		size:      block.size,
+	}
+	isRequest, isResponse := false, false
+	for _, f := range fields {
+		if !f.IsPseudo() {
+			m.headers = append(m.headers, f)
+			continue
+		}
+		switch f.Name {
+		case ":method":
+			m.method = f.Value
+			isRequest = true
+		case ":scheme":
+			m.scheme = f.Value
+		case ":authority":
+			m.authority = f.Value
+		case ":path":
+			m.path = f.Value
+		case ":status":
+			m.statusCode, _ = strconv.Atoi(f.Value)
+			isResponse = true
+		}
+	}
+
+	trans := conn.transactions[block.streamID]
+	switch {
+	case isRequest:
+		if trans == nil && len(conn.transactions) >= h2.maxOpenStreams {
+			droppedStreams.Add(1)
+			if isDebug {
+				debugf("Too many open streams, ignoring stream %d", block.streamID)
+			}
+			return
+		}
+		m.cmdlineTuple = h2.watcher.FindProcessesTupleTCP(tcptuple.IPPort())
+		trans = &transaction{streamID: block.streamID, request: m}
+		conn.transactions[block.streamID] = trans
+	case isResponse:
+		if trans == nil {
+			unmatchedResponses.Add(1)
+			if isDebug {
+				debugf("Response from unknown stream %d. Ignoring", block.streamID)
+			}
+			return
+		}
+		if m.statusCode < 200 {
+			// informational response
+			return
+		}
+		trans.response = m
+	default:
+		// trailers
+		target := conn.message(block.streamID, dir)
+		if target == nil {
+			return
+		}
+		target.trailers = append(target.trailers, m.headers...)
+		target.size += m.size
+		m = target
+	}
+
+	if block.endStream {
+		h2.endMessage(conn, block.streamID, m, block.ts)
+	}
+}
+
+// message returns the request or response of the stream sent in the
+// direction.
+func (conn *connectionData) message(streamID uint32, dir uint8) *message {
+	trans := conn.transactions[streamID]
+	if trans == nil {
+		return nil
+	}
+	if trans.request.direction == dir {
+		return trans.request
+	}
+	return trans.response
+}
+
+// endMessage is called when the sender of the message closes the stream, the
+// transaction is published once the response is complete.
+func (h2 *http2Plugin) endMessage(conn *connectionData, streamID uint32, m *message, ts time.Time) {
+	m.ended = true
+	m.endTs = ts
+
+	trans := conn.transactions[streamID]
+	if trans.response == nil || !trans.response.ended {
+		return
+	}
+	delete(conn.transactions, streamID)
+	h2.publishTransaction(trans)
+}
+
+func (h2 *http2Plugin) resetStream(conn *connectionData, streamID uint32, code string, ts time.Time) {
+	trans := conn.transactions[streamID]
+	if trans == nil {
+		return
+	}
+	trans.resetCode = code
+	trans.resetTs = ts
+	delete(conn.transactions, streamID)
+	h2.publishTransaction(trans)
+}
+
+func (h2 *http2Plugin) publishTransaction(trans *transaction) {
+	if h2.results == nil {
+		return
+	}
+	h2.results(h2.newTransaction(trans))
+}
+
+func (h2 *http2Plugin) newTransaction(trans *transaction) beat.Event {
+	requ, resp := trans.request, trans.response
+
+	status := common.OK_STATUS
+	var notes []string
+	switch {
+	case trans.resetCode != "":
+		status = common.ERROR_STATUS
+		notes = append(notes, "Stream reset with "+trans.resetCode)
+	case resp == nil:
+		status = common.ERROR_STATUS
+		notes = append(notes, "Unmatched request")
+	case !resp.ended:
+		notes = append(notes, "Incomplete response")
+	}
+	if resp != nil && resp.statusCode >= 400 {
+		status = common.ERROR_STATUS
+	}
+
+	source, destination := common.MakeEndpointPair(requ.tcpTuple.BaseTuple, requ.cmdlineTuple)
+	src, dst := &source, &destination
+	if requ.direction == tcp.TCPDirectionReverse {
+		src, dst = dst, src
+	}
+
+	evt, pbf := pb.NewBeatEvent(requ.ts)
+	pbf.SetSource(src)
+	pbf.SetDestination(dst)
+	pbf.AddIP(src.IP)
+	pbf.AddIP(dst.IP)
+	pbf.Source.Bytes = int64(requ.size)
+	pbf.Event.Dataset = "http2"
+	pbf.Event.Start = requ.ts
+	pbf.Network.Transport = "tcp"
+	pbf.Network.Protocol = pbf.Event.Dataset
+
+	fields := evt.Fields
+	fields["type"] = pbf.Event.Dataset
+
+	path, query, _ := strings.Cut(requ.path, "?")
+	host, port := splitAuthority(requ.authority)
+	if host != "" {
+		if net.ParseIP(host) == nil {
+			pbf.Destination.Domain = host
+			pbf.AddHost(host)
+		} else {
+			pbf.AddIP(host)
+		}
+	}
+	if port == 0 {
+		port = int(pbf.Destination.Port)
+	}
+
+	httpFields := http.ProtocolFields{
+		Version:          "2",
+		RequestMethod:    common.NetString(requ.method),
+		RequestBytes:     int64(requ.size),
+		RequestBodyBytes: int64(requ.bodySize),
+		RequestHeaders:   h2.collectHeaders(requ),
+	}
+	http2Fields := mapstr.M{
+		"stream_id": trans.streamID,
+	}
+
+	if resp != nil {
+		pbf.Destination.Bytes = int64(resp.size)
+		pbf.Event.End = resp.endTs
+		if !resp.ended {
+			pbf.Event.End = resp.ts
+		}
+
+		httpFields.ResponseStatusCode = int64(resp.statusCode)
+		httpFields.ResponseBytes = int64(resp.size)
+		httpFields.ResponseBodyBytes = int64(resp.bodySize)
+		httpFields.ResponseHeaders = h2.collectHeaders(resp)
+	}
+	if trans.resetCode != "" {
+		pbf.Event.End = trans.resetTs
+		http2Fields["error_code"] = trans.resetCode
+	}
+
+	if isGRPC(requ.header("content-type")) {
+		grpc, code, hasCode := grpcFields(requ, resp)
+		if hasCode && code != 0 {
+			status = common.ERROR_STATUS
+		}
+		fields["grpc"] = grpc
+		if method, ok := grpc["method"].(string); ok {
+			pbf.Event.Action = "grpc." + strings.ToLower(method)
+		}
+	}
+
+	fields["status"] = status
+	fields["method"] = requ.method
+	fields["query"] = fmt.Sprintf("%s %s", requ.method, path)
+	fields["http2"] = http2Fields
+	if h2.sendRequest {
+		fields["request"] = requ.raw()
+	}
+	if h2.sendResponse && resp != nil {
+		fields["response"] = resp.raw()
+	}
+
+	if status == common.ERROR_STATUS {
+		pbf.Event.Outcome = "failure"
+	}
+	pbf.Error.Message = notes
+
+	pb.MarshalStruct(evt.Fields, "http", httpFields)
+	pb.MarshalStruct(evt.Fields, "url", newURL(requ.scheme, host, int64(port), path, query))
+	if userAgent := requ.header("user-agent"); userAgent != "" {
+		pb.MarshalStruct(evt.Fields, "user_agent", ecs.UserAgent{Original: userAgent})
+	}
+
+	return evt
+}
+
+func (h2 *http2Plugin) collectHeaders(m *message) mapstr.M {
+	hdrs := mapstr.M{}
+	if contentType := m.header("content-type"); contentType != "" {
+		hdrs["content-type"] = contentType
+	}
+	if !h2.sendHeaders {
+		return hdrs
+	}
+
+	for _, fields := range [][]hpack.HeaderField{m.headers, m.trailers} {
+		for _, f := range fields {
+			if h2.headersWhitelist != nil && !h2.headersWhitelist[f.Name] {
+				continue
+			}
+			if value, ok := hdrs[f.Name].(string); ok && f.Name != "content-type" {
+				// repeated headers are joined, as in HTTP/1.x
+				hdrs[f.Name] = value + ", " + f.Value
+				continue
+			}
+			hdrs[f.Name] = f.Value
+		}
+	}
+	return hdrs
+}
+
+// header returns the value of a header of the message, or an empty string.
+func (m *message) header(name string) string {
+	return findHeader(m.headers, name)
+}
+
+// trailer returns the value of a trailer of the message, or an empty string.
+func (m *message) trailer(name string) string {
+	return findHeader(m.trailers, name)
+}
+
+func findHeader(fields []hpack.HeaderField, name string) string {
+	for _, f := range fields {
+		if f.Name == name {
+			return f.Value
+		}
+	}
+	return ""
+}
+
+// raw returns a text representation of the headers and trailers of the
+// message.
+func (m *message) raw() string {
+	var b strings.Builder
+	if m.method != "" {
+		fmt.Fprintf(&b, ":method: %s\r\n:scheme: %s\r\n:authority: %s\r\n:path: %s\r\n", m.method, m.scheme, m.authority, m.path)
+	} else {
+		fmt.Fprintf(&b, ":status: %d\r\n", m.statusCode)
+	}
+	for _, f := range m.headers {
+		fmt.Fprintf(&b, "%s: %s\r\n", f.Name, f.Value)
+	}
+	b.WriteString("\r\n")
+	for _, f := range m.trailers {
+		fmt.Fprintf(&b, "%s: %s\r\n", f.Name, f.Value)
+	}
+	return b.String()
+}
+
+// splitAuthority returns the host and port of the :authority pseudo-header.
+func splitAuthority(authority string) (host string, port int) {
+	if h, p, err := net.SplitHostPort(authority); err == nil {
+		port, _ = strconv.Atoi(p)
+		return h, port
+	}
+	return strings.Trim(authority, "[]"), 0
+}
+
+// newURL returns a new ecs.Url object with data from the HTTP/2 request.
+func newURL(scheme, host string, port int64, path, query string) *ecs.Url {
+	if scheme == "" {
+		scheme = "http"
+	}
+	u := &ecs.Url{
+		Scheme: scheme,
+		Domain: host,
+		Path:   path,
+		Query:  query,
+	}
+	defaultPort := int64(80)
+	if scheme == "https" {
+		defaultPort = 443
+	}
+	if port != defaultPort {
+		u.Port = port
+	}
+	if host != "" && port > 0 {
+		authority := host
+		if u.Port != 0 {
+			authority = net.JoinHostPort(host, strconv.FormatInt(port, 10))
+		} else if strings.IndexByte(host, ':') != -1 {
+			authority = "[" + host + "]"
+		}
+		u.Full = scheme + "://" + authority + path
+		if query != "" {
+			u.Full += "?" + query
+		}
+	}
+	return u
+}
+
+func (h2 *http2Plugin) GapInStream(tcptuple *common.TCPTuple, dir uint8,
+	nbytes int, private protos.ProtocolData) (priv protos.ProtocolData, drop bool,
+) {
+	// The header blocks of the missing data can't be decoded, and without
+	// them the dynamic tables are out of sync, drop the connection.
+	return nil, true
+}
+
+func (h2 *http2Plugin) ReceivedFin(tcptuple *common.TCPTuple, dir uint8,
+	private protos.ProtocolData,
+) protos.ProtocolData {
+	return private
+}
+
+// Expired publishes the transactions still open when the connection expires.
+func (h2 *http2Plugin) Expired(tuple *common.TCPTuple, private protos.ProtocolData) {
+	conn, ok := private.(*connectionData)
+	if !ok || conn == nil {
+		return
+	}
+	if isDebug {
+		debugf("expired connection %s", tuple)
+	}
+
+	streamIDs := make([]uint32, 0, len(conn.transactions))
+	for streamID := range conn.transactions {
+		streamIDs = append(streamIDs, streamID)
+	}
+	sort.Slice(streamIDs, func(i, j int) bool { return streamIDs[i] < streamIDs[j] })
+	for _, streamID := range streamIDs {
+		h2.publishTransaction(conn.transactions[streamID])
+		delete(conn.transactions, streamID)
+	}
+}
@@ -0,0 +1,409 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+//go:build !integration
+
+package http2
+
+import (
+	"bytes"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/hpack"
+
+	"github.com/elastic/beats/v7/libbeat/beat"
+	"github.com/elastic/beats/v7/libbeat/common"
+	"github.com/elastic/beats/v7/packetbeat/procs"
+	"github.com/elastic/beats/v7/packetbeat/protos"
+	"github.com/elastic/beats/v7/packetbeat/protos/tcp"
+	"github.com/elastic/beats/v7/packetbeat/publish"
+	conf "github.com/elastic/elastic-agent-libs/config"
+	"github.com/elastic/elastic-agent-libs/mapstr"
+)
+
+type eventStore struct {
+	events []beat.Event
+}
+
+func (e *eventStore) publish(event beat.Event) {
+	publish.MarshalPacketbeatFields(&event, nil, nil)
+	e.events = append(e.events, event)
+}
+
+func http2ModForTests(t *testing.T, store *eventStore, settings map[string]interface{}) *http2Plugin {
+	t.Helper()
+	p, err := New(false, store.publish, &procs.ProcessesWatcher{}, conf.MustNewConfigFrom(settings))
+	require.NoError(t, err)
+	return p.(*http2Plugin)
+}
+
+func testTCPTuple() *common.TCPTuple {
+	t := &common.TCPTuple{
+		IPLength: 4,
+		BaseTuple: common.BaseTuple{
+			SrcIP: net.IPv4(192, 168, 0, 1), DstIP: net.IPv4(192, 168, 0, 2),
+			SrcPort: 6512, DstPort: 50051,
+		},
+	}
+	t.ComputeHashables()
+	return t
+}
+
+// endpoint writes the frames sent by a client or a server, sharing an HPACK
+// encoder as a real endpoint does.
+type endpoint struct {
+	buf     bytes.Buffer
+	framer  *http2.Framer
+	hbuf    bytes.Buffer
+	encoder *hpack.Encoder
+}
+
+func newEndpoint(client bool) *endpoint {
+	e := &endpoint{}
+	if client {
+		e.buf.WriteString(clientPreface)
+	}
+	e.framer = http2.NewFramer(&e.buf, nil)
+	e.encoder = hpack.NewEncoder(&e.hbuf)
+	return e
+}
+
+func (e *endpoint) headerBlock(t *testing.T, fields ...string) []byte {
+	t.Helper()
+	e.hbuf.Reset()
+	for i := 0; i < len(fields); i += 2 {
+		require.NoError(t, e.encoder.WriteField(hpack.HeaderField{Name: fields[i], Value: fields[i+1]}))
+	}
+	return append([]byte(nil), e.hbuf.Bytes()...)
+}
+
+func (e *endpoint) headers(t *testing.T, streamID uint32, endStream bool, fields ...string) {
+	t.Helper()
+	require.NoError(t, e.framer.WriteHeaders(http2.HeadersFrameParam{
+		StreamID:      streamID,
+		BlockFragment: e.headerBlock(t, fields...),
+		EndStream:     endStream,
+		EndHeaders:    true,
+	}))
+}
+
+func (e *endpoint) data(t *testing.T, streamID uint32, endStream bool, data string) {
+	t.Helper()
+	require.NoError(t, e.framer.WriteData(streamID, endStream, []byte(data)))
+}
+
+// flush returns the bytes written since the last call.
+func (e *endpoint) flush() []byte {
+	b := append([]byte(nil), e.buf.Bytes()...)
+	e.buf.Reset()
+	return b
+}
+
+type testConnection struct {
+	t       *testing.T
+	plugin  *http2Plugin
+	tuple   *common.TCPTuple
+	private protos.ProtocolData
+}
+
+func (c *testConnection) send(dir uint8, payload []byte) {
+	c.private = c.plugin.Parse(&protos.Packet{Ts: time.Now(), Payload: payload}, c.tuple, dir, c.private)
+}
+
+func (c *testConnection) client(payload []byte) {
+	c.send(tcp.TCPDirectionOriginal, payload)
+}
+
+func (c *testConnection) server(payload []byte) {
+	c.send(tcp.TCPDirectionReverse, payload)
+}
+
+func newTestConnection(t *testing.T, store *eventStore, settings map[string]interface{}) *testConnection {
+	return &testConnection{
+		t:      t,
+		plugin: http2ModForTests(t, store, settings),
+		tuple:  testTCPTuple(),
+	}
+}
+
+func grpcRequest(t *testing.T, client *endpoint, streamID uint32, method string) {
+	client.headers(t, streamID, false,
+		":method", "POST",
+		":scheme", "http",
+		":path", method,
+		":authority", "greeter.example.com:50051",
+		"content-type", "application/grpc",
+		"user-agent", "grpc-go/1.73.0",
+		"te", "trailers",
+		"grpc-timeout", "1S",
+	)
+	client.data(t, streamID, true, "\x00\x00\x00\x00\x07\x0a\x05world")
+}
+
+func TestGRPCUnaryCall(t *testing.T) {
+	store := &eventStore{}
+	conn := newTestConnection(t, store, nil)
+	client, server := newEndpoint(true), newEndpoint(false)
+
+	require.NoError(t, client.framer.WriteSettings())
+	require.NoError(t, server.framer.WriteSettings())
+	conn.client(client.flush())
+	conn.server(server.flush())
+
+	// The second call is encoded with the dynamic table filled by the first.
+	for _, streamID := range []uint32{1, 3} {
+		grpcRequest(t, client, streamID, "/helloworld.Greeter/SayHello")
+		conn.client(client.flush())
+
+		server.headers(t, streamID, false, ":status", "200", "content-type", "application/grpc")
+		server.data(t, streamID, false, "\x00\x00\x00\x00\x0d\x0a\x0bHello world")
+		server.headers(t, streamID, true, "grpc-status", "0", "grpc-message", "")
+		conn.server(server.flush())
+	}
+
+	require.Len(t, store.events, 2)
+	for i, event := range store.events {
+		fields := event.Fields
+		assert.Equal(t, "http2", fields["type"])
+		assert.Equal(t, common.OK_STATUS, fields["status"])
+		assert.Equal(t, "POST /helloworld.Greeter/SayHello", fields["query"])
+		assert.Equal(t, mapstr.M{"stream_id": uint32(2*i + 1)}, fields["http2"])
+		assert.Equal(t, mapstr.M{
+			"service":     "helloworld.Greeter",
+			"method":      "SayHello",
+			"status_code": 0,
+			"status":      "OK",
+			"timeout":     "1S",
+		}, fields["grpc"])
+		assertFieldValue(t, fields, "event.action", "grpc.sayhello")
+		assertFieldValue(t, fields, "http.version", "2")
+		assertFieldValue(t, fields, "http.request.method", common.NetString("POST"))
+		assertFieldValue(t, fields, "http.request.body.bytes", int64(12))
+		assertFieldValue(t, fields, "http.response.status_code", int64(200))
+		assertFieldValue(t, fields, "http.response.body.bytes", int64(18))
+		assertFieldValue(t, fields, "url.full", "http://greeter.example.com:50051/helloworld.Greeter/SayHello")
+		assertFieldValue(t, fields, "user_agent.original", "grpc-go/1.73.0")
+		assertFieldValue(t, fields, "destination.domain", "greeter.example.com")
+	}
+}
+
+func TestGRPCTrailersOnlyError(t *testing.T) {
+	store := &eventStore{}
+	conn := newTestConnection(t, store, nil)
+	client, server := newEndpoint(true), newEndpoint(false)
+
+	grpcRequest(t, client, 1, "/grpc.health.v1.Health/Check")
+	conn.client(client.flush())
+	server.headers(t, 1, true,
+		":status", "200",
+		"content-type", "application/grpc",
+		"grpc-status", "5",
+		"grpc-message", "unknown service %22foo%22",
+	)
+	conn.server(server.flush())
+
+	require.Len(t, store.events, 1)
+	fields := store.events[0].Fields
+	assert.Equal(t, common.ERROR_STATUS, fields["status"])
+	assert.Equal(t, mapstr.M{
+		"service":     "grpc.health.v1.Health",
+		"method":      "Check",
+		"status_code": 5,
+		"status":      "NOT_FOUND",
+		"message":     `unknown service "foo"`,
+		"timeout":     "1S",
+	}, fields["grpc"])
+	assertFieldValue(t, fields, "event.outcome", "failure")
+}
+
+func TestInterleavedStreams(t *testing.T) {
+	store := &eventStore{}
+	conn := newTestConnection(t, store, map[string]interface{}{"send_headers": []string{"x-request-id"}})
+	client, server := newEndpoint(true), newEndpoint(false)
+
+	client.headers(t, 1, true, ":method", "GET", ":scheme", "http", ":path", "/slow?x=1", ":authority", "10.0.0.1", "x-request-id", "a")
+	client.headers(t, 3, true, ":method", "GET", ":scheme", "http", ":path", "/fast", ":authority", "10.0.0.1", "x-request-id", "b")
+	conn.client(client.flush())
+
+	server.headers(t, 3, false, ":status", "200", "content-type", "text/plain")
+	server.headers(t, 1, false, ":status", "404", "content-type", "text/plain")
+	server.data(t, 3, true, "fast")
+	server.data(t, 1, true, "not found")
+	conn.server(server.flush())
+
+	require.Len(t, store.events, 2)
+	fast, slow := store.events[0].Fields, store.events[1].Fields
+	assert.Equal(t, "GET /fast", fast["query"])
+	assert.Equal(t, common.OK_STATUS, fast["status"])
+	assertFieldValue(t, fast, "http.request.headers", mapstr.M{"x-request-id": "b"})
+	assertFieldValue(t, fast, "http.response.headers", mapstr.M{"content-type": "text/plain"})
+
+	assert.Equal(t, "GET /slow", slow["query"])
+	assert.Equal(t, common.ERROR_STATUS, slow["status"])
+	assertFieldValue(t, slow, "url.query", "x=1")
+	assertFieldValue(t, slow, "url.full", "http://10.0.0.1:50051/slow?x=1")
+	assertFieldValue(t, slow, "http.response.status_code", int64(404))
+	assertFieldValue(t, slow, "http.request.headers", mapstr.M{"x-request-id": "a"})
+	_, hasGRPC := slow["grpc"]
+	assert.False(t, hasGRPC)
+}
+
+func TestStreamReset(t *testing.T) {
+	store := &eventStore{}
+	conn := newTestConnection(t, store, nil)
+	client := newEndpoint(true)
+
+	grpcRequest(t, client, 1, "/helloworld.Greeter/SayHello")
+	require.NoError(t, client.framer.WriteRSTStream(1, http2.ErrCodeCancel))
+	conn.client(client.flush())
+
+	require.Len(t, store.events, 1)
+	fields := store.events[0].Fields
+	assert.Equal(t, common.ERROR_STATUS, fields["status"])
+	assert.Equal(t, mapstr.M{"stream_id": uint32(1), "error_code": "CANCEL"}, fields["http2"])
+	assertFieldValue(t, fields, "error.message", "Stream reset with CANCEL")
+}
+
+func TestContinuationPaddingAndSplitPackets(t *testing.T) {
+	store := &eventStore{}
+	conn := newTestConnection(t, store, map[string]interface{}{"send_all_headers": true})
+	client, server := newEndpoint(true), newEndpoint(false)
+
+	block := client.headerBlock(t,
+		":method", "POST",
+		":scheme", "http",
+		":path", "/upload",
+		":authority", "example.com",
+		"content-type", "application/json",
+		"x-long", string(bytes.Repeat([]byte("a"), 100)),
+	)
+	require.NoError(t, client.framer.WriteHeaders(http2.HeadersFrameParam{
+		StreamID:      1,
+		BlockFragment: block[:10],
+		PadLength:     8,
+		Priority:      http2.PriorityParam{StreamDep: 0, Weight: 15},
+	}))
+	require.NoError(t, client.framer.WriteContinuation(1, false, block[10:50]))
+	require.NoError(t, client.framer.WriteContinuation(1, true, block[50:]))
+	require.NoError(t, client.framer.WriteDataPadded(1, true, []byte(`{"a":1}`), make([]byte, 16)))
+
+	// Send the frames byte by byte.
+	for _, b := range client.flush() {
+		conn.client([]byte{b})
+	}
+
+	server.headers(t, 1, true, ":status", "204")
+	conn.server(server.flush())
+
+	require.Len(t, store.events, 1)
+	fields := store.events[0].Fields
+	assert.Equal(t, "POST /upload", fields["query"])
+	assertFieldValue(t, fields, "http.request.body.bytes", int64(7))
+	assertFieldValue(t, fields, "http.request.headers.x-long", string(bytes.Repeat([]byte("a"), 100)))
+	assertFieldValue(t, fields, "http.response.status_code", int64(204))
+	assertFieldValue(t, fields, "url.full", "http://example.com:50051/upload")
+}
+
+func TestHeaderTableSizeSetting(t *testing.T) {
+	store := &eventStore{}
+	conn := newTestConnection(t, store, nil)
+	client, server := newEndpoint(true), newEndpoint(false)
+
+	// The server allows the client to use a larger dynamic table.
+	require.NoError(t, server.framer.WriteSettings(http2.Setting{ID: http2.SettingHeaderTableSize, Val: 65536}))
+	conn.server(server.flush())
+	client.encoder.SetMaxDynamicTableSizeLimit(65536)
+	client.encoder.SetMaxDynamicTableSize(65536)
+
+	for streamID := uint32(1); streamID <= 3; streamID += 2 {
+		client.headers(t, streamID, true, ":method", "GET", ":scheme", "http", ":path", "/", ":authority", "example.com", "x-big", string(bytes.Repeat([]byte("b"), 5000)))
+		conn.client(client.flush())
+		server.headers(t, streamID, true, ":status", "200")
+		conn.server(server.flush())
+	}
+
+	require.NotNil(t, conn.private)
+	require.Len(t, store.events, 2)
+}
+
+func TestInvalidHeaderBlockDropsConnection(t *testing.T) {
+	store := &eventStore{}
+	conn := newTestConnection(t, store, nil)
+	client := newEndpoint(true)
+
+	// Index 100 is not in the static table and the dynamic table is empty.
+	require.NoError(t, client.framer.WriteHeaders(http2.HeadersFrameParam{
+		StreamID:      1,
+		BlockFragment: []byte{0x80 | 100},
+		EndStream:     true,
+		EndHeaders:    true,
+	}))
+	conn.client(client.flush())
+
+	assert.Nil(t, conn.private)
+	assert.Empty(t, store.events)
+}
+
+func TestOversizedHeaderBlockDropsConnection(t *testing.T) {
+	store := &eventStore{}
+	conn := newTestConnection(t, store, nil)
+	client := newEndpoint(true)
+
+	// A header block that never ends.
+	require.NoError(t, client.framer.WriteHeaders(http2.HeadersFrameParam{
+		StreamID:      1,
+		BlockFragment: client.headerBlock(t, ":method", "GET"),
+	}))
+	conn.client(client.flush())
+	fragment := make([]byte, 16384)
+	for i := 0; i <= tcp.TCPMaxDataInStream/len(fragment); i++ {
+		require.NoError(t, client.framer.WriteContinuation(1, false, fragment))
+		conn.client(client.flush())
+	}
+
+	assert.Nil(t, conn.private)
+	assert.Empty(t, store.events)
+}
+
+func TestExpiredConnection(t *testing.T) {
+	store := &eventStore{}
+	conn := newTestConnection(t, store, nil)
+	client := newEndpoint(true)
+
+	grpcRequest(t, client, 1, "/helloworld.Greeter/SayHello")
+	conn.client(client.flush())
+	require.Empty(t, store.events)
+
+	conn.plugin.Expired(conn.tuple, conn.private)
+	require.Len(t, store.events, 1)
+	fields := store.events[0].Fields
+	assert.Equal(t, common.ERROR_STATUS, fields["status"])
+	assertFieldValue(t, fields, "error.message", "Unmatched request")
+}
+
+func assertFieldValue(t *testing.T, fields mapstr.M, key string, expected interface{}) {
+	t.Helper()
+	value, err := fields.GetValue(key)
+	if assert.NoError(t, err, key) {
+		assert.Equal(t, expected, value, key)
+	}
+}
@@ -0,0 +1,390 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package kafka
+
+import "strconv"
+
+// API keys of the requests with a parsed body.
+const (
+	apiProduce      int16 = 0
+	apiFetch        int16 = 1
+	apiMetadata     int16 = 3
+	apiOffsetCommit int16 = 8
+	apiJoinGroup    int16 = 11
+	apiVersions     int16 = 18
+)
+
+var apiNames = map[int16]string{
+	0:  "Produce",
+	1:  "Fetch",
+	2:  "ListOffsets",
+	3:  "Metadata",
+	4:  "LeaderAndIsr",
+	5:  "StopReplica",
+	6:  "UpdateMetadata",
+	7:  "ControlledShutdown",
+	8:  "OffsetCommit",
+	9:  "OffsetFetch",
+	10: "FindCoordinator",
+	11: "JoinGroup",
+	12: "Heartbeat",
+	13: "LeaveGroup",
+	14: "SyncGroup",
+	15: "DescribeGroups",
+	16: "ListGroups",
+	17: "SaslHandshake",
+	18: "ApiVersions",
+	19: "CreateTopics",
+	20: "DeleteTopics",
+	21: "DeleteRecords",
+	22: "InitProducerId",
+	23: "OffsetForLeaderEpoch",
+	24: "AddPartitionsToTxn",
+	25: "AddOffsetsToTxn",
+	26: "EndTxn",
+	27: "WriteTxnMarkers",
+	28: "TxnOffsetCommit",
+	29: "DescribeAcls",
+	30: "CreateAcls",
+	31: "DeleteAcls",
+	32: "DescribeConfigs",
+	33: "AlterConfigs",
+	34: "AlterReplicaLogDirs",
+	35: "DescribeLogDirs",
+	36: "SaslAuthenticate",
+	37: "CreatePartitions",
+	38: "CreateDelegationToken",
+	39: "RenewDelegationToken",
+	40: "ExpireDelegationToken",
+	41: "DescribeDelegationToken",
+	42: "DeleteGroups",
+	43: "ElectLeaders",
+	44: "IncrementalAlterConfigs",
+	45: "AlterPartitionReassignments",
+	46: "ListPartitionReassignments",
+	47: "OffsetDelete",
+	48: "DescribeClientQuotas",
+	49: "AlterClientQuotas",
+	50: "DescribeUserScramCredentials",
+	51: "AlterUserScramCredentials",
+}
+
+func apiName(key int16) string {
+	if name, ok := apiNames[key]; ok {
+		return name
+	}
+	return strconv.Itoa(int(key))
+}
+
+// apiSpec describes how to parse the body of the messages of an API.
+type apiSpec struct {
+	// flexibleVersion is the first version using flexible messages.
+	flexibleVersion int16
+	// maxVersion is the last version with a known layout.
+	maxVersion    int16
+	parseRequest  func(d *decoder, m *message, version int16)
+	parseResponse func(d *decoder, m *message, version int16)
+}
+
+var apiSpecs = map[int16]apiSpec{
+	apiProduce:      {9, 13, parseProduceRequest, parseProduceResponse},
+	apiFetch:        {12, 17, parseFetchRequest, parseFetchResponse},
+	apiMetadata:     {9, 13, parseMetadataRequest, parseMetadataResponse},
+	apiOffsetCommit: {8, 9, parseOffsetCommitRequest, parseOffsetCommitResponse},
+	apiJoinGroup:    {6, 9, parseJoinGroupRequest, parseJoinGroupResponse},
+	apiVersions:     {3, 4, parseAPIVersionsRequest, parseAPIVersionsResponse},
+}
+
+// isFlexible returns whether the given version of an API uses flexible
+// messages, and whether its layout is known.
+func isFlexible(key, version int16) (flexible, known bool) {
+	spec, ok := apiSpecs[key]
+	if !ok || version < 0 || version > spec.maxVersion {
+		return false, false
+	}
+	return version >= spec.flexibleVersion, true
+}
+
+// topicName reads the name of a topic, or its ID in the versions that
+// identify topics by ID.
+func topicName(d *decoder, byID bool) string {
+	if byID {
+		return d.uuid()
+	}
+	return d.string()
+}
+
+func parseProduceRequest(d *decoder, m *message, version int16) {
+	if version >= 3 {
+		d.string() // transactional_id
+	}
+	m.acks = d.int16()
+	m.hasAcks = true
+	d.int32() // timeout_ms
+	for i, n := 0, d.arrayLength(); i < n && d.err == nil; i++ {
+		topic := topicName(d, version >= 13)
+		for j, n := 0, d.arrayLength(); j < n && d.err == nil; j++ {
+			m.addPartition(topic, d.int32())
+			d.skipBytes() // records
+			d.taggedFields()
+		}
+		d.taggedFields()
+	}
+}
+
+func parseProduceResponse(d *decoder, m *message, version int16) {
+	for i, n := 0, d.arrayLength(); i < n && d.err == nil; i++ {
+		topicName(d, version >= 13)
+		for j, n := 0, d.arrayLength(); j < n && d.err == nil; j++ {
+			d.int32() // index
+			m.addError(d.int16())
+			d.int64() // base_offset
+			if version >= 2 {
+				d.int64() // log_append_time_ms
+			}
+			if version >= 5 {
+				d.int64() // log_start_offset
+			}
+			if version >= 8 {
+				for k, n := 0, d.arrayLength(); k < n && d.err == nil; k++ {
+					d.int32()  // batch_index
+					d.string() // batch_index_error_message
+					d.taggedFields()
+				}
+				d.string() // error_message
+			}
+			d.taggedFields()
+		}
+		d.taggedFields()
+	}
+	if version >= 1 {
+		m.setThrottleTime(d.int32())
+	}
+}
+
+func parseFetchRequest(d *decoder, m *message, version int16) {
+	if version <= 14 {
+		d.int32() // replica_id
+	}
+	d.int32() // max_wait_ms
+	d.int32() // min_bytes
+	if version >= 3 {
+		d.int32() // max_bytes
+	}
+	if version >= 4 {
+		d.int8() // isolation_level
+	}
+	if version >= 7 {
+		d.int32() // session_id
+		d.int32() // session_epoch
+	}
+	for i, n := 0, d.arrayLength(); i < n && d.err == nil; i++ {
+		topic := topicName(d, version >= 13)
+		for j, n := 0, d.arrayLength(); j < n && d.err == nil; j++ {
+			m.addPartition(topic, d.int32())
+			if version >= 9 {
+				d.int32() // current_leader_epoch
+			}
+			d.int64() // fetch_offset
+			if version >= 12 {
+				d.int32() // last_fetched_epoch
+			}
+			if version >= 5 {
+				d.int64() // log_start_offset
+			}
+			d.int32() // partition_max_bytes
+			d.taggedFields()
+		}
+		d.taggedFields()
+	}
+}
+
+func parseFetchResponse(d *decoder, m *message, version int16) {
+	if version >= 1 {
+		m.setThrottleTime(d.int32())
+	}
+	if version >= 7 {
+		m.addError(d.int16())
+		d.int32() // session_id
+	}
+	for i, n := 0, d.arrayLength(); i < n && d.err == nil; i++ {
+		topicName(d, version >= 13)
+		for j, n := 0, d.arrayLength(); j < n && d.err == nil; j++ {
+			d.int32() // partition_index
+			m.addError(d.int16())
+			d.int64() // high_watermark
+			if version >= 4 {
+				d.int64() // last_stable_offset
+			}
+			if version >= 5 {
+				d.int64() // log_start_offset
+			}
+			if version >= 4 {
+				for k, n := 0, d.arrayLength(); k < n && d.err == nil; k++ {
+					d.int64() // producer_id
+					d.int64() // first_offset
+					d.taggedFields()
+				}
+			}
+			if version >= 11 {
+				d.int32() // preferred_read_replica
+			}
+			d.skipBytes() // records
+			d.taggedFields()
+		}
+		d.taggedFields()
+	}
+}
+
+func parseMetadataRequest(d *decoder, m *message, version int16) {
+	for i, n := 0, d.arrayLength(); i < n && d.err == nil; i++ {
+		if version >= 10 {
+			d.uuid() // topic_id
+		}
+		if name := d.string(); name != "" {
+			m.addTopic(name)
+		}
+		d.taggedFields()
+	}
+}
+
+func parseMetadataResponse(d *decoder, m *message, version int16) {
+	if version >= 3 {
+		m.setThrottleTime(d.int32())
+	}
+	for i, n := 0, d.arrayLength(); i < n && d.err == nil; i++ {
+		d.int32()  // node_id
+		d.string() // host
+		d.int32()  // port
+		if version >= 1 {
+			d.string() // rack
+		}
+		d.taggedFields()
+	}
+	if version >= 2 {
+		d.string() // cluster_id
+	}
+	if version >= 1 {
+		d.int32() // controller_id
+	}
+	for i, n := 0, d.arrayLength(); i < n && d.err == nil; i++ {
+		m.addError(d.int16())
+		d.string() // name
+		if version >= 10 {
+			d.uuid() // topic_id
+		}
+		if version >= 1 {
+			d.int8() // is_internal
+		}
+		for j, n := 0, d.arrayLength(); j < n && d.err == nil; j++ {
+			m.addError(d.int16())
+			d.int32() // partition_index
+			d.int32() // leader_id
+			if version >= 7 {
+				d.int32() // leader_epoch
+			}
+			d.skipInt32Array() // replica_nodes
+			d.skipInt32Array() // isr_nodes
+			if version >= 5 {
+				d.skipInt32Array() // offline_replicas
+			}
+			d.taggedFields()
+		}
+		if version >= 8 {
+			d.int32() // topic_authorized_operations
+		}
+		d.taggedFields()
+	}
+	if version >= 8 && version <= 10 {
+		d.int32() // cluster_authorized_operations
+	}
+	if version >= 13 {
+		m.addError(d.int16())
+	}
+}
+
+func parseOffsetCommitRequest(d *decoder, m *message, version int16) {
+	m.groupID = d.string()
+	if version >= 1 {
+		d.int32()  // generation_id_or_member_epoch
+		d.string() // member_id
+	}
+	if version >= 7 {
+		d.string() // group_instance_id
+	}
+	if version >= 2 && version <= 4 {
+		d.int64() // retention_time_ms
+	}
+	for i, n := 0, d.arrayLength(); i < n && d.err == nil; i++ {
+		topic := d.string()
+		for j, n := 0, d.arrayLength(); j < n && d.err == nil; j++ {
+			m.addPartition(topic, d.int32())
+			d.int64() // committed_offset
+			if version >= 6 {
+				d.int32() // committed_leader_epoch
+			}
+			if version == 1 {
+				d.int64() // commit_timestamp
+			}
+			d.string() // committed_metadata
+			d.taggedFields()
+		}
+		d.taggedFields()
+	}
+}
+
+func parseOffsetCommitResponse(d *decoder, m *message, version int16) {
+	if version >= 3 {
+		m.setThrottleTime(d.int32())
+	}
+	for i, n := 0, d.arrayLength(); i < n && d.err == nil; i++ {
+		d.string() // name
+		for j, n := 0, d.arrayLength(); j < n && d.err == nil; j++ {
+			d.int32() // partition_index
+			m.addError(d.int16())
+			d.taggedFields()
+		}
+		d.taggedFields()
+	}
+}
+
+func parseJoinGroupRequest(d *decoder, m *message, version int16) {
+	m.groupID = d.string()
+}
+
+func parseJoinGroupResponse(d *decoder, m *message, version int16) {
+	if version >= 2 {
+		m.setThrottleTime(d.int32())
+	}
+	m.addError(d.int16())
+}
+
+func parseAPIVersionsRequest(d *decoder, m *message, version int16) {}
+
+func parseAPIVersionsResponse(d *decoder, m *message, version int16) {
+	m.addError(d.int16())
+	for i, n := 0, d.arrayLength(); i < n && d.err == nil; i++ {
+		d.int16() // api_key
+		d.int16() // min_version
+		d.int16() // max_version
+		d.taggedFields()
+	}
+	if version >= 1 {
+		m.setThrottleTime(d.int32())
+	}
+}
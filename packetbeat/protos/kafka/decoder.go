@@ -0,0 +1,207 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package kafka
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+)
+
+var (
+	errTruncated     = errors.New("message truncated")
+	errInvalidLength = errors.New("invalid length")
+	errVarintTooLong = errors.New("varint too long")
+)
+
+// decoder reads the primitive types of the Kafka protocol from a message.
+// The first error is kept and subsequent reads return zero values, so a
+// parser only needs to check for errors once it is done.
+type decoder struct {
+	buf []byte
+	pos int
+	err error
+
+	// flexible is set for the message versions that use compact strings,
+	// arrays and bytes, and tagged fields.
+	flexible bool
+}
+
+func newDecoder(buf []byte) *decoder {
+	return &decoder{buf: buf}
+}
+
+func (d *decoder) fail(err error) {
+	if d.err == nil {
+		d.err = err
+	}
+}
+
+func (d *decoder) remaining() int {
+	return len(d.buf) - d.pos
+}
+
+func (d *decoder) read(n int) []byte {
+	if d.err != nil {
+		return nil
+	}
+	if n < 0 || d.remaining() < n {
+		d.fail(errTruncated)
+		return nil
+	}
+	b := d.buf[d.pos : d.pos+n]
+	d.pos += n
+	return b
+}
+
+func (d *decoder) skip(n int) {
+	d.read(n)
+}
+
+func (d *decoder) int8() int8 {
+	if b := d.read(1); b != nil {
+		return int8(b[0])
+	}
+	return 0
+}
+
+func (d *decoder) int16() int16 {
+	if b := d.read(2); b != nil {
+		return int16(binary.BigEndian.Uint16(b))
+	}
+	return 0
+}
+
+func (d *decoder) int32() int32 {
+	if b := d.read(4); b != nil {
+		return int32(binary.BigEndian.Uint32(b))
+	}
+	return 0
+}
+
+func (d *decoder) int64() int64 {
+	if b := d.read(8); b != nil {
+		return int64(binary.BigEndian.Uint64(b))
+	}
+	return 0
+}
+
+func (d *decoder) uvarint() uint64 {
+	if d.err != nil {
+		return 0
+	}
+	value, n := binary.Uvarint(d.buf[d.pos:])
+	switch {
+	case n == 0:
+		d.fail(errTruncated)
+		return 0
+	case n < 0:
+		d.fail(errVarintTooLong)
+		return 0
+	}
+	d.pos += n
+	return value
+}
+
+// compactLength reads the length of a compact string, array or bytes, which
+// is encoded as length+1 so that zero is used for null values.
+func (d *decoder) compactLength() int {
+	length := d.uvarint()
+	if length > uint64(d.remaining())+1 {
+		d.fail(errInvalidLength)
+		return -1
+	}
+	return int(length) - 1
+}
+
+// uuid reads a topic ID, formatted as Kafka tools display it.
+func (d *decoder) uuid() string {
+	if b := d.read(16); b != nil {
+		return base64.RawURLEncoding.EncodeToString(b)
+	}
+	return ""
+}
+
+// string reads a string or a nullable string, null strings are returned as
+// empty strings.
+func (d *decoder) string() string {
+	var length int
+	if d.flexible {
+		length = d.compactLength()
+	} else {
+		length = int(d.int16())
+	}
+	if length <= 0 {
+		return ""
+	}
+	return string(d.read(length))
+}
+
+// skipBytes skips a bytes or nullable bytes value.
+func (d *decoder) skipBytes() {
+	var length int
+	if d.flexible {
+		length = d.compactLength()
+	} else {
+		length = int(d.int32())
+	}
+	if length > 0 {
+		d.skip(length)
+	}
+}
+
+// arrayLength reads the number of elements of an array, or -1 for null
+// arrays.
+func (d *decoder) arrayLength() int {
+	var length int
+	if d.flexible {
+		length = d.compactLength()
+	} else {
+		length = int(d.int32())
+	}
+	// All elements take at least one byte.
+	if length > d.remaining() {
+		d.fail(errInvalidLength)
+		return -1
+	}
+	return length
+}
+
+// skipInt32Array skips an array of int32 values.
+func (d *decoder) skipInt32Array() {
+	if n := d.arrayLength(); n > 0 {
+		d.skip(4 * n)
+	}
+}
+
+// taggedFields skips the tagged fields at the end of a structure in flexible
+// versions.
+func (d *decoder) taggedFields() {
+	if !d.flexible {
+		return
+	}
+	for n := d.uvarint(); n > 0 && d.err == nil; n-- {
+		d.uvarint() // tag
+		size := d.uvarint()
+		if size > uint64(d.remaining()) {
+			d.fail(errTruncated)
+			return
+		}
+		d.skip(int(size))
+	}
+}
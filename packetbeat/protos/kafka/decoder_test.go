@@ -0,0 +1,71 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+//go:build !integration
+
+package kafka
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecoderStrings(t *testing.T) {
+	d := newDecoder([]byte{0, 3, 'f', 'o', 'o', 0xff, 0xff})
+	assert.Equal(t, "foo", d.string())
+	assert.Equal(t, "", d.string())
+	assert.NoError(t, d.err)
+
+	d = newDecoder([]byte{4, 'b', 'a', 'r', 0, 1})
+	d.flexible = true
+	assert.Equal(t, "bar", d.string())
+	assert.Equal(t, "", d.string())
+	assert.Equal(t, "", d.string())
+	assert.NoError(t, d.err)
+}
+
+func TestDecoderTaggedFields(t *testing.T) {
+	// Two tagged fields followed by an int16.
+	d := newDecoder([]byte{2, 0, 1, 0xaa, 5, 2, 0xbb, 0xcc, 0, 7})
+	d.flexible = true
+	d.taggedFields()
+	assert.Equal(t, int16(7), d.int16())
+	assert.NoError(t, d.err)
+
+	d = newDecoder([]byte{1, 0, 5, 0xaa})
+	d.flexible = true
+	d.taggedFields()
+	assert.ErrorIs(t, d.err, errTruncated)
+}
+
+func TestDecoderErrors(t *testing.T) {
+	d := newDecoder([]byte{0, 1})
+	assert.Equal(t, int32(0), d.int32())
+	assert.ErrorIs(t, d.err, errTruncated)
+	// The first error is kept and later reads return zero values.
+	assert.Equal(t, int16(0), d.int16())
+	assert.ErrorIs(t, d.err, errTruncated)
+
+	d = newDecoder([]byte{0, 0, 0x10, 0})
+	assert.Equal(t, -1, d.arrayLength())
+	assert.ErrorIs(t, d.err, errInvalidLength)
+
+	d = newDecoder([]byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x01})
+	d.uvarint()
+	assert.ErrorIs(t, d.err, errVarintTooLong)
+}
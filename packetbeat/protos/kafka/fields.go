@@ -0,0 +1,36 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+// Code generated by beats/dev-tools/cmd/asset/asset.go - DO NOT EDIT.
+
+package kafka
+
+import (
+	"github.com/elastic/beats/v7/libbeat/asset"
+)
+
+func init() {
+	if err := asset.SetFields("packetbeat", "kafka", asset.ModuleFieldsPri, AssetKafka); err != nil {
+		panic(err)
+	}
+}
+
+// AssetKafka returns asset data.
+// This is the base64 encoded zlib format compressed contents of protos/kafka.
+func AssetKafka() string {
+	return "eJyslE1r20wQx+/6FEMuzyU2z9mHQkigqAHbBJcexUY7igZJO8rMKI776cvqJVFcQVscdEis1f7/v3ldQYWnDVSuqFwCYGQ1buDqPv6+SgA8ai7UGnHYwJcEAKA/W2mLORWUA75gMCgIa6/rBMb/Nv2nKwiuwXf5+NipxQ08CXft+GZ+Y37LtZRVeHp7P92tOTzNXi4gTs+hRLjZpzFG4AKsRBB87lBtnSzaReOZwuBX4enI4v/eMqpMftH+3Hr6FgBfXdPGjO+FfZfjMtULihKHCxMxqszBOkUPj6c/JCZnEaxdjDYjfyHFTAzSu7PUXA9ExtA4y0sgUxDUloPiElhNGGyJ6Z9LNkhFIo1/KcyxoETnURYIjFvK9XL7QacX9WfmazgMh+QxGBU0lCy9AyfzXgUQbFnsraIkkN4tMLdOjCLIJ3C/ay2yX0PB0jiLUE77lPaRwtmcAbjgweaCQMHjKyi2TtwYlAPvtFycHxaPoqv/fw+33zSf0yMctGtQhuUVW3dXFIp2y01D1ofwjSl87U/HDOhCAVxe6YVTFLrmESUiuLwKfKzRPzUY+nF57kimfI1rZdZL5ce8PwpXKOAZNfxn47B5MJ6uKBzJSviJwudeC6GhCMsn9FUsnU7LoReFnD3G8KyTMG+0YTv0nTa1wwe579v77e7HNjvs9ulttnvI9jcPh/SQ7rYL/FYKm9WYGTWYNZeWKapE0obqmhRzDl7n8wHHfioGz7dNPNTkg5jvMNbEwXPH5uCFuHZGHNbJrwEAtQg7Xw=="
+}
@@ -0,0 +1,537 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package kafka
+
+import (
+	"encoding/binary"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/elastic/beats/v7/libbeat/beat"
+	"github.com/elastic/beats/v7/libbeat/common"
+	conf "github.com/elastic/elastic-agent-libs/config"
+	"github.com/elastic/elastic-agent-libs/logp"
+	"github.com/elastic/elastic-agent-libs/mapstr"
+	"github.com/elastic/elastic-agent-libs/monitoring"
+
+	"github.com/elastic/beats/v7/packetbeat/pb"
+	"github.com/elastic/beats/v7/packetbeat/procs"
+	"github.com/elastic/beats/v7/packetbeat/protos"
+	"github.com/elastic/beats/v7/packetbeat/protos/applayer"
+	"github.com/elastic/beats/v7/packetbeat/protos/tcp"
+)
+
+const (
+	// maxMessageSize is the size of the largest message accepted, as the
+	// default socket.request.max.bytes of the brokers. Larger sizes are
+	// most likely not Kafka messages, like TLS records.
+	maxMessageSize = 100 * 1024 * 1024
+
+	// maxHeaderSize is the size of the data buffered to parse the header of
+	// a message too large to be buffered.
+	maxHeaderSize = 64 * 1024
+)
+
+type stream struct {
+	applayer.Stream
+	// skip is the number of bytes left of a message too large to be
+	// buffered.
+	skip int
+}
+
+type connectionData struct {
+	streams [2]*stream
+	// requests waiting for a response, in the order they were sent.
+	requests []*message
+}
+
+// message is a Kafka request or response.
+type message struct {
+	ts           time.Time
+	direction    uint8
+	tcpTuple     common.TCPTuple
+	cmdlineTuple *common.ProcessTuple
+	size         int
+
+	apiKey        int16
+	apiVersion    int16
+	correlationID int32
+	clientID      string
+
+	topics     []string
+	partitions []string
+	groupID    string
+	acks       int16
+	hasAcks    bool
+
+	errors          []string
+	throttleTimeMs  int32
+	hasThrottleTime bool
+
+	notes []string
+}
+
+// Kafka protocol plugin
+type kafkaPlugin struct {
+	// config
+	ports              []int
+	maxPendingRequests int
+	transactionTimeout time.Duration
+
+	watcher *procs.ProcessesWatcher
+	results protos.Reporter
+}
+
+var (
+	debugf  = logp.MakeDebug("kafka")
+	isDebug = false
+)
+
+var (
+	unmatchedResponses = monitoring.NewInt(nil, "kafka.unmatched_responses")
+	unmatchedRequests  = monitoring.NewInt(nil, "kafka.unmatched_requests")
+)
+
+func init() {
+	protos.Register("kafka", New)
+}
+
+func New(
+	testMode bool,
+	results protos.Reporter,
+	watcher *procs.ProcessesWatcher,
+	cfg *conf.C,
+) (protos.Plugin, error) {
+	p := &kafkaPlugin{}
+	config := defaultConfig
+	if !testMode {
+		if err := cfg.Unpack(&config); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := p.init(results, watcher, &config); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+func (kp *kafkaPlugin) init(results protos.Reporter, watcher *procs.ProcessesWatcher, config *kafkaConfig) error {
+	kp.setFromConfig(config)
+
+	kp.results = results
+	kp.watcher = watcher
+	isDebug = logp.IsDebug("kafka")
+
+	return nil
+}
+
+func (kp *kafkaPlugin) setFromConfig(config *kafkaConfig) {
+	kp.ports = config.Ports
+	kp.maxPendingRequests = config.MaxPendingRequests
+	kp.transactionTimeout = config.TransactionTimeout
+}
+
+func (kp *kafkaPlugin) GetPorts() []int {
+	return kp.ports
+}
+
+func (kp *kafkaPlugin) ConnectionTimeout() time.Duration {
+	return kp.transactionTimeout
+}
+
+func (kp *kafkaPlugin) Parse(
+	pkt *protos.Packet,
+	tcptuple *common.TCPTuple,
+	dir uint8,
+	private protos.ProtocolData,
+) protos.ProtocolData {
+	conn := ensureConnection(private)
+	conn = kp.doParse(conn, pkt, tcptuple, dir)
+	if conn == nil {
+		return nil
+	}
+	return conn
+}
+
+func ensureConnection(private protos.ProtocolData) *connectionData {
+	if private == nil {
+		return &connectionData{}
+	}
+
+	priv, ok := private.(*connectionData)
+	if !ok {
+		logp.Warn("kafka connection data type error, create new one")
+		return &connectionData{}
+	}
+	if priv == nil {
+		logp.Warn("Unexpected: kafka connection data not set, create new one")
+		return &connectionData{}
+	}
+
+	return priv
+}
+
+func (kp *kafkaPlugin) doParse(
+	conn *connectionData,
+	pkt *protos.Packet,
+	tcptuple *common.TCPTuple,
+	dir uint8,
+) *connectionData {
+	st := conn.streams[dir]
+	if st == nil {
+		st = &stream{}
+		st.Stream.Init(tcp.TCPMaxDataInStream)
+		conn.streams[dir] = st
+		if isDebug {
+			debugf("new stream: %p (dir=%v, len=%v)", st, dir, len(pkt.Payload))
+		}
+	}
+
+	payload := pkt.Payload
+	if st.skip > 0 {
+		n := min(st.skip, len(payload))
+		st.skip -= n
+		payload = payload[n:]
+	}
+	if err := st.Append(payload); err != nil {
+		if isDebug {
+			debugf("%v, dropping TCP stream: ", err)
+		}
+		return nil
+	}
+
+	isRequest := kp.isRequest(tcptuple, dir)
+	for st.Buf.Len() >= 4 {
+		buf := st.Buf.Bytes()
+		size := int(int32(binary.BigEndian.Uint32(buf)))
+		if size < 4 || size > maxMessageSize {
+			if isDebug {
+				debugf("invalid message size %d, dropping Kafka connection", size)
+			}
+			return nil
+		}
+
+		var err error
+		switch {
+		case st.Buf.Avail(4 + size):
+			var data []byte
+			data, err = st.Buf.Collect(4 + size)
+			if err == nil {
+				m := kp.newMessage(pkt.Ts, tcptuple, dir, 4+size)
+				err = kp.handleMessage(conn, m, data[4:], isRequest, true)
+			}
+		case 4+size > st.MaxDataInStream:
+			// Parse the header of the message and skip its body.
+			if len(buf) < maxHeaderSize {
+				// wait for more data
+				return conn
+			}
+			m := kp.newMessage(pkt.Ts, tcptuple, dir, 4+size)
+			m.notes = append(m.notes, "Message too large to be parsed")
+			err = kp.handleMessage(conn, m, buf[4:], isRequest, false)
+			st.skip = 4 + size - len(buf)
+			_ = st.Buf.Advance(len(buf))
+		default:
+			// wait for more data
+			return conn
+		}
+		if err != nil {
+			if isDebug {
+				debugf("%v, dropping Kafka connection", err)
+			}
+			return nil
+		}
+		st.Reset()
+	}
+
+	return conn
+}
+
+func (kp *kafkaPlugin) newMessage(ts time.Time, tcptuple *common.TCPTuple, dir uint8, size int) *message {
+	return &message{
+		ts:           ts,
+		direction:    dir,
+		tcpTuple:     *tcptuple,
+		cmdlineTuple: kp.watcher.FindProcessesTupleTCP(tcptuple.IPPort()),
+		size:         size,
+	}
+}
+
+// isRequest returns whether the messages sent in the given direction are
+// requests, that is whether they are sent to a Kafka port.
+func (kp *kafkaPlugin) isRequest(tcptuple *common.TCPTuple, dir uint8) bool {
+	dstPort := tcptuple.DstPort
+	if dir == tcp.TCPDirectionReverse {
+		dstPort = tcptuple.SrcPort
+	}
+	return slices.Contains(kp.ports, int(dstPort))
+}
+
+// handleMessage parses a message without its size. If complete is false,
+// data only contains the beginning of the message and only its header is
+// parsed.
+func (kp *kafkaPlugin) handleMessage(conn *connectionData, m *message, data []byte, isRequest, complete bool) error {
+	d := newDecoder(data)
+	if isRequest {
+		m.apiKey = d.int16()
+		m.apiVersion = d.int16()
+		m.correlationID = d.int32()
+		// The client ID is a nullable string in all header versions.
+		m.clientID = d.string()
+		if d.err != nil {
+			return fmt.Errorf("failed to parse request header: %w", d.err)
+		}
+		if m.apiKey < 0 || m.apiVersion < 0 {
+			return fmt.Errorf("invalid request API key %d, version %d", m.apiKey, m.apiVersion)
+		}
+		if complete {
+			kp.parseBody(d, m, true)
+		}
+		kp.handleRequest(conn, m)
+		return nil
+	}
+
+	m.correlationID = d.int32()
+	requ := kp.takeRequest(conn, m.correlationID)
+	if requ == nil {
+		unmatchedResponses.Add(1)
+		if isDebug {
+			debugf("response with correlation ID %d has no matching request", m.correlationID)
+		}
+		return nil
+	}
+	m.apiKey, m.apiVersion = requ.apiKey, requ.apiVersion
+	if complete {
+		kp.parseBody(d, m, false)
+	}
+	kp.publishTransaction(requ, m)
+	return nil
+}
+
+// parseBody parses the rest of the header of a message and its body, if its
+// layout is known.
+func (kp *kafkaPlugin) parseBody(d *decoder, m *message, isRequest bool) {
+	flexible, known := isFlexible(m.apiKey, m.apiVersion)
+	if !known {
+		return
+	}
+	// Responses to ApiVersions never use the flexible header, so that clients
+	// can parse them before knowing the versions supported by the broker.
+	d.flexible = flexible && (isRequest || m.apiKey != apiVersions)
+	d.taggedFields()
+	d.flexible = flexible
+
+	spec := apiSpecs[m.apiKey]
+	kind := "response"
+	if isRequest {
+		spec.parseRequest(d, m, m.apiVersion)
+		kind = "request"
+	} else {
+		spec.parseResponse(d, m, m.apiVersion)
+	}
+	if d.err != nil {
+		m.notes = append(m.notes, fmt.Sprintf("Failed to parse %s v%d %s: %v", apiName(m.apiKey), m.apiVersion, kind, d.err))
+	}
+}
+
+func (kp *kafkaPlugin) handleRequest(conn *connectionData, m *message) {
+	if m.apiKey == apiProduce && m.hasAcks && m.acks == 0 {
+		// The broker doesn't respond to produce requests without acks.
+		kp.publishTransaction(m, nil)
+		return
+	}
+	if len(conn.requests) >= kp.maxPendingRequests {
+		unmatchedRequests.Add(1)
+		kp.publishTransaction(conn.requests[0], nil)
+		conn.requests = conn.requests[1:]
+	}
+	conn.requests = append(conn.requests, m)
+}
+
+// takeRequest removes the request with the given correlation ID from the
+// pending requests. The brokers respond in the order of the requests, so the
+// requests sent before it are published without response.
+func (kp *kafkaPlugin) takeRequest(conn *connectionData, correlationID int32) *message {
+	for i, requ := range conn.requests {
+		if requ.correlationID != correlationID {
+			continue
+		}
+		for _, unmatched := range conn.requests[:i] {
+			unmatchedRequests.Add(1)
+			kp.publishTransaction(unmatched, nil)
+		}
+		conn.requests = conn.requests[i+1:]
+		return requ
+	}
+	return nil
+}
+
+func (kp *kafkaPlugin) publishTransaction(requ, resp *message) {
+	if kp.results == nil {
+		return
+	}
+	kp.results(kp.newTransaction(requ, resp))
+}
+
+func (kp *kafkaPlugin) newTransaction(requ, resp *message) beat.Event {
+	source, destination := common.MakeEndpointPair(requ.tcpTuple.BaseTuple, requ.cmdlineTuple)
+	src, dst := &source, &destination
+	if requ.direction == tcp.TCPDirectionReverse {
+		src, dst = dst, src
+	}
+
+	evt, pbf := pb.NewBeatEvent(requ.ts)
+	pbf.SetSource(src)
+	pbf.SetDestination(dst)
+	pbf.AddIP(src.IP)
+	pbf.AddIP(dst.IP)
+	pbf.Source.Bytes = int64(requ.size)
+	pbf.Event.Dataset = "kafka"
+	pbf.Event.Start = requ.ts
+	pbf.Network.Transport = "tcp"
+	pbf.Network.Protocol = pbf.Event.Dataset
+
+	name := apiName(requ.apiKey)
+	kafka := mapstr.M{
+		"api_key":        requ.apiKey,
+		"api_name":       name,
+		"api_version":    requ.apiVersion,
+		"correlation_id": requ.correlationID,
+	}
+	if requ.clientID != "" {
+		kafka["client_id"] = requ.clientID
+	}
+	if len(requ.topics) > 0 {
+		kafka["topics"] = requ.topics
+	}
+	if len(requ.partitions) > 0 {
+		kafka["partitions"] = requ.partitions
+	}
+	if requ.groupID != "" {
+		kafka["group_id"] = requ.groupID
+	}
+	if requ.hasAcks {
+		kafka["acks"] = requ.acks
+	}
+
+	status := common.OK_STATUS
+	notes := requ.notes
+	switch {
+	case resp != nil:
+		pbf.Destination.Bytes = int64(resp.size)
+		pbf.Event.End = resp.ts
+		if len(resp.errors) > 0 {
+			status = common.ERROR_STATUS
+			kafka["errors"] = resp.errors
+		}
+		if resp.hasThrottleTime {
+			kafka["throttle_time_ms"] = resp.throttleTimeMs
+		}
+		notes = append(notes, resp.notes...)
+	case requ.apiKey == apiProduce && requ.hasAcks && requ.acks == 0:
+		// No response expected.
+	default:
+		status = common.ERROR_STATUS
+		notes = append(notes, "Unmatched request")
+	}
+
+	fields := evt.Fields
+	fields["type"] = pbf.Event.Dataset
+	fields["status"] = status
+	fields["method"] = name
+	fields["kafka"] = kafka
+	if len(requ.topics) > 0 {
+		fields["resource"] = strings.Join(requ.topics, ",")
+	}
+
+	if status == common.ERROR_STATUS {
+		pbf.Event.Outcome = "failure"
+	}
+	pbf.Error.Message = notes
+
+	return evt
+}
+
+func (m *message) addTopic(topic string) {
+	if !slices.Contains(m.topics, topic) {
+		m.topics = append(m.topics, topic)
+	}
+}
+
+// addPartition adds a partition of a topic, named as in Kafka tools.
+func (m *message) addPartition(topic string, partition int32) {
+	m.addTopic(topic)
+	m.partitions = append(m.partitions, fmt.Sprintf("%s-%d", topic, partition))
+}
+
+// addError adds the name of an error code, if it is not the code for no
+// error.
+func (m *message) addError(code int16) {
+	if code == 0 {
+		return
+	}
+	if name := errorName(code); !slices.Contains(m.errors, name) {
+		m.errors = append(m.errors, name)
+	}
+}
+
+func (m *message) setThrottleTime(ms int32) {
+	m.throttleTimeMs = ms
+	m.hasThrottleTime = true
+}
+
+func (kp *kafkaPlugin) GapInStream(tcptuple *common.TCPTuple, dir uint8,
+	nbytes int, private protos.ProtocolData) (priv protos.ProtocolData, drop bool,
+) {
+	conn, ok := private.(*connectionData)
+	if !ok || conn == nil {
+		return private, true
+	}
+	// Gaps in the body of a message that is skipped don't break the framing.
+	if st := conn.streams[dir]; st != nil && st.skip >= nbytes {
+		st.skip -= nbytes
+		return private, false
+	}
+	return private, true
+}
+
+func (kp *kafkaPlugin) ReceivedFin(tcptuple *common.TCPTuple, dir uint8,
+	private protos.ProtocolData,
+) protos.ProtocolData {
+	return private
+}
+
+// Expired publishes the requests still waiting for a response when the
+// connection expires.
+func (kp *kafkaPlugin) Expired(tuple *common.TCPTuple, private protos.ProtocolData) {
+	conn, ok := private.(*connectionData)
+	if !ok || conn == nil {
+		return
+	}
+	if isDebug {
+		debugf("expired connection %s", tuple)
+	}
+	for _, requ := range conn.requests {
+		unmatchedRequests.Add(1)
+		kp.publishTransaction(requ, nil)
+	}
+	conn.requests = nil
+}
@@ -0,0 +1,551 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+//go:build !integration
+
+package kafka
+
+import (
+	"bytes"
+	"encoding/binary"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/elastic/beats/v7/libbeat/beat"
+	"github.com/elastic/beats/v7/libbeat/common"
+	"github.com/elastic/beats/v7/packetbeat/procs"
+	"github.com/elastic/beats/v7/packetbeat/protos"
+	"github.com/elastic/beats/v7/packetbeat/protos/tcp"
+	"github.com/elastic/beats/v7/packetbeat/publish"
+	conf "github.com/elastic/elastic-agent-libs/config"
+	"github.com/elastic/elastic-agent-libs/mapstr"
+)
+
+type eventStore struct {
+	events []beat.Event
+}
+
+func (e *eventStore) publish(event beat.Event) {
+	publish.MarshalPacketbeatFields(&event, nil, nil)
+	e.events = append(e.events, event)
+}
+
+func kafkaModForTests(t *testing.T, store *eventStore, settings map[string]interface{}) *kafkaPlugin {
+	t.Helper()
+	if settings == nil {
+		settings = map[string]interface{}{}
+	}
+	if _, ok := settings["ports"]; !ok {
+		settings["ports"] = []int{9092}
+	}
+	p, err := New(false, store.publish, &procs.ProcessesWatcher{}, conf.MustNewConfigFrom(settings))
+	require.NoError(t, err)
+	return p.(*kafkaPlugin)
+}
+
+func testTCPTuple() *common.TCPTuple {
+	t := &common.TCPTuple{
+		IPLength: 4,
+		BaseTuple: common.BaseTuple{
+			SrcIP: net.IPv4(192, 168, 0, 1), DstIP: net.IPv4(192, 168, 0, 2),
+			SrcPort: 6512, DstPort: 9092,
+		},
+	}
+	t.ComputeHashables()
+	return t
+}
+
+// encoder writes the primitive types of the Kafka protocol.
+type encoder struct {
+	buf      bytes.Buffer
+	flexible bool
+}
+
+func (e *encoder) int8(v int8) *encoder {
+	e.buf.WriteByte(byte(v))
+	return e
+}
+
+func (e *encoder) int16(v int16) *encoder {
+	e.buf.Write(binary.BigEndian.AppendUint16(nil, uint16(v)))
+	return e
+}
+
+func (e *encoder) int32(v int32) *encoder {
+	e.buf.Write(binary.BigEndian.AppendUint32(nil, uint32(v)))
+	return e
+}
+
+func (e *encoder) int64(v int64) *encoder {
+	e.buf.Write(binary.BigEndian.AppendUint64(nil, uint64(v)))
+	return e
+}
+
+func (e *encoder) uvarint(v int) *encoder {
+	e.buf.Write(binary.AppendUvarint(nil, uint64(v)))
+	return e
+}
+
+// length writes the length of a string, array or bytes, -1 being null.
+func (e *encoder) length(n int, size int) *encoder {
+	switch {
+	case e.flexible:
+		return e.uvarint(n + 1)
+	case size == 2:
+		return e.int16(int16(n))
+	default:
+		return e.int32(int32(n))
+	}
+}
+
+func (e *encoder) string(s string) *encoder {
+	e.length(len(s), 2)
+	e.buf.WriteString(s)
+	return e
+}
+
+func (e *encoder) null() *encoder {
+	return e.length(-1, 2)
+}
+
+func (e *encoder) bytes(b []byte) *encoder {
+	e.length(len(b), 4)
+	e.buf.Write(b)
+	return e
+}
+
+func (e *encoder) array(n int) *encoder {
+	return e.length(n, 4)
+}
+
+func (e *encoder) tagged() *encoder {
+	if e.flexible {
+		e.uvarint(0)
+	}
+	return e
+}
+
+// request returns a request with the given header and body.
+func request(apiKey, version int16, correlationID int32, flexible bool, body func(e *encoder)) []byte {
+	e := &encoder{}
+	e.int16(apiKey).int16(version).int32(correlationID).string("test-client")
+	e.flexible = flexible
+	e.tagged()
+	body(e)
+	return frame(e)
+}
+
+// response returns a response with the given header and body.
+func response(correlationID int32, flexibleHeader, flexible bool, body func(e *encoder)) []byte {
+	e := &encoder{}
+	e.int32(correlationID)
+	e.flexible = flexibleHeader
+	e.tagged()
+	e.flexible = flexible
+	body(e)
+	return frame(e)
+}
+
+func frame(e *encoder) []byte {
+	return append(binary.BigEndian.AppendUint32(nil, uint32(e.buf.Len())), e.buf.Bytes()...)
+}
+
+type testConnection struct {
+	plugin  *kafkaPlugin
+	tuple   *common.TCPTuple
+	private protos.ProtocolData
+	ts      time.Time
+}
+
+func newTestConnection(t *testing.T, store *eventStore, settings map[string]interface{}) *testConnection {
+	return &testConnection{
+		plugin: kafkaModForTests(t, store, settings),
+		tuple:  testTCPTuple(),
+		ts:     time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC),
+	}
+}
+
+func (c *testConnection) send(dir uint8, payload []byte) {
+	c.ts = c.ts.Add(time.Millisecond)
+	c.private = c.plugin.Parse(&protos.Packet{Ts: c.ts, Payload: payload}, c.tuple, dir, c.private)
+}
+
+func (c *testConnection) client(payload []byte) {
+	c.send(tcp.TCPDirectionOriginal, payload)
+}
+
+func (c *testConnection) server(payload []byte) {
+	c.send(tcp.TCPDirectionReverse, payload)
+}
+
+func kafkaFields(t *testing.T, event beat.Event) mapstr.M {
+	t.Helper()
+	fields, err := event.Fields.GetValue("kafka")
+	require.NoError(t, err)
+	return fields.(mapstr.M)
+}
+
+func TestProduce(t *testing.T) {
+	store := &eventStore{}
+	conn := newTestConnection(t, store, nil)
+
+	requ := request(apiProduce, 7, 42, false, func(e *encoder) {
+		e.null().int16(-1).int32(30000)
+		e.array(1).string("orders")
+		e.array(2)
+		e.int32(0).bytes([]byte("records"))
+		e.int32(1).bytes(nil)
+	})
+	resp := response(42, false, false, func(e *encoder) {
+		e.array(1).string("orders")
+		e.array(2)
+		e.int32(0).int16(0).int64(100).int64(-1).int64(0)
+		e.int32(1).int16(6).int64(-1).int64(-1).int64(0)
+		e.int32(0)
+	})
+	conn.client(requ)
+	conn.server(resp)
+
+	require.Len(t, store.events, 1)
+	event := store.events[0]
+	assert.Equal(t, mapstr.M{
+		"api_key":          apiProduce,
+		"api_name":         "Produce",
+		"api_version":      int16(7),
+		"correlation_id":   int32(42),
+		"client_id":        "test-client",
+		"topics":           []string{"orders"},
+		"partitions":       []string{"orders-0", "orders-1"},
+		"acks":             int16(-1),
+		"errors":           []string{"NOT_LEADER_OR_FOLLOWER"},
+		"throttle_time_ms": int32(0),
+	}, kafkaFields(t, event))
+	assert.Equal(t, "Error", event.Fields["status"])
+	assert.Equal(t, "Produce", event.Fields["method"])
+	assert.Equal(t, "orders", event.Fields["resource"])
+
+	outcome, _ := event.Fields.GetValue("event.outcome")
+	assert.Equal(t, "failure", outcome)
+	duration, _ := event.Fields.GetValue("event.duration")
+	assert.Equal(t, time.Millisecond, duration)
+	sourceBytes, _ := event.Fields.GetValue("source.bytes")
+	assert.EqualValues(t, len(requ), sourceBytes)
+	destinationBytes, _ := event.Fields.GetValue("destination.bytes")
+	assert.EqualValues(t, len(resp), destinationBytes)
+	sourcePort, _ := event.Fields.GetValue("source.port")
+	assert.EqualValues(t, 6512, sourcePort)
+}
+
+func TestProduceWithoutAcks(t *testing.T) {
+	store := &eventStore{}
+	conn := newTestConnection(t, store, nil)
+
+	conn.client(request(apiProduce, 3, 1, false, func(e *encoder) {
+		e.null().int16(0).int32(30000)
+		e.array(1).string("logs")
+		e.array(1).int32(2).bytes([]byte("records"))
+	}))
+
+	require.Len(t, store.events, 1)
+	event := store.events[0]
+	kafka := kafkaFields(t, event)
+	assert.Equal(t, int16(0), kafka["acks"])
+	assert.Equal(t, []string{"logs-2"}, kafka["partitions"])
+	assert.Equal(t, "OK", event.Fields["status"])
+	errors, _ := event.Fields.GetValue("error.message")
+	assert.Nil(t, errors)
+}
+
+func TestFetchFlexible(t *testing.T) {
+	store := &eventStore{}
+	conn := newTestConnection(t, store, nil)
+
+	conn.client(request(apiFetch, 12, 7, true, func(e *encoder) {
+		e.int32(-1).int32(500).int32(1).int32(52428800).int8(0).int32(0).int32(-1)
+		e.array(1).string("events")
+		e.array(1)
+		e.int32(3).int32(-1).int64(1234).int32(-1).int64(-1).int32(1048576).tagged()
+		e.tagged()
+		e.array(0).string("").tagged()
+	}))
+	conn.server(response(7, true, true, func(e *encoder) {
+		e.int32(0).int16(0).int32(0)
+		e.array(1).string("events")
+		e.array(1)
+		e.int32(3).int16(1).int64(2000).int64(2000).int64(0).array(-1).int32(-1).bytes(nil)
+		// A tagged field with a diverging epoch.
+		e.uvarint(1).uvarint(0).uvarint(3).int8(0).int16(0)
+		e.tagged()
+		e.tagged()
+	}))
+
+	require.Len(t, store.events, 1)
+	event := store.events[0]
+	kafka := kafkaFields(t, event)
+	assert.Equal(t, "Fetch", kafka["api_name"])
+	assert.Equal(t, []string{"events"}, kafka["topics"])
+	assert.Equal(t, []string{"events-3"}, kafka["partitions"])
+	assert.Equal(t, []string{"OFFSET_OUT_OF_RANGE"}, kafka["errors"])
+	errors, _ := event.Fields.GetValue("error.message")
+	assert.Nil(t, errors)
+}
+
+func TestMetadata(t *testing.T) {
+	store := &eventStore{}
+	conn := newTestConnection(t, store, nil)
+
+	conn.client(request(apiMetadata, 9, 1, true, func(e *encoder) {
+		e.array(2).string("orders").tagged().string("missing").tagged()
+		e.int8(1).int8(0).int8(0).tagged()
+	}))
+	conn.server(response(1, true, true, func(e *encoder) {
+		e.int32(0)
+		e.array(1).int32(1).string("broker-1").int32(9092).null().tagged()
+		e.string("cluster").int32(1)
+		e.array(2)
+		e.int16(0).string("orders").int8(0)
+		e.array(1).int16(0).int32(0).int32(1).int32(0).array(1).int32(1).array(1).int32(1).array(0).tagged()
+		e.int32(0).tagged()
+		e.int16(3).string("missing").int8(0).array(0).int32(0).tagged()
+		e.int32(0).tagged()
+	}))
+
+	require.Len(t, store.events, 1)
+	kafka := kafkaFields(t, store.events[0])
+	assert.Equal(t, []string{"orders", "missing"}, kafka["topics"])
+	assert.Equal(t, []string{"UNKNOWN_TOPIC_OR_PARTITION"}, kafka["errors"])
+	assert.Nil(t, kafka["partitions"])
+}
+
+func TestOffsetCommitAndJoinGroup(t *testing.T) {
+	store := &eventStore{}
+	conn := newTestConnection(t, store, nil)
+
+	conn.client(request(apiJoinGroup, 5, 1, false, func(e *encoder) {
+		e.string("billing").int32(10000).int32(300000).string("").null().string("consumer")
+		e.array(1).string("range").bytes(nil)
+	}))
+	conn.server(response(1, false, false, func(e *encoder) {
+		e.int32(0).int16(79).int32(-1).string("").string("").string("member-1").array(0)
+	}))
+	conn.client(request(apiOffsetCommit, 8, 2, true, func(e *encoder) {
+		e.string("billing").int32(1).string("member-1").null()
+		e.array(1).string("invoices")
+		e.array(1).int32(0).int64(42).int32(-1).null().tagged()
+		e.tagged()
+		e.tagged()
+	}))
+	conn.server(response(2, true, true, func(e *encoder) {
+		e.int32(0)
+		e.array(1).string("invoices")
+		e.array(1).int32(0).int16(0).tagged()
+		e.tagged()
+		e.tagged()
+	}))
+
+	require.Len(t, store.events, 2)
+	join := kafkaFields(t, store.events[0])
+	assert.Equal(t, "JoinGroup", join["api_name"])
+	assert.Equal(t, "billing", join["group_id"])
+	assert.Equal(t, []string{"MEMBER_ID_REQUIRED"}, join["errors"])
+
+	commit := kafkaFields(t, store.events[1])
+	assert.Equal(t, "OffsetCommit", commit["api_name"])
+	assert.Equal(t, "billing", commit["group_id"])
+	assert.Equal(t, []string{"invoices-0"}, commit["partitions"])
+	assert.Nil(t, commit["errors"])
+	assert.Equal(t, "OK", store.events[1].Fields["status"])
+}
+
+func TestAPIVersions(t *testing.T) {
+	store := &eventStore{}
+	conn := newTestConnection(t, store, nil)
+
+	conn.client(request(apiVersions, 3, 0, true, func(e *encoder) {
+		e.string("librdkafka").string("2.3.0").tagged()
+	}))
+	// The response header never has tagged fields.
+	conn.server(response(0, false, true, func(e *encoder) {
+		e.int16(0)
+		e.array(2)
+		e.int16(0).int16(0).int16(9).tagged()
+		e.int16(1).int16(0).int16(13).tagged()
+		e.int32(0).tagged()
+	}))
+
+	require.Len(t, store.events, 1)
+	event := store.events[0]
+	assert.Equal(t, "ApiVersions", kafkaFields(t, event)["api_name"])
+	assert.Equal(t, "OK", event.Fields["status"])
+	errors, _ := event.Fields.GetValue("error.message")
+	assert.Nil(t, errors)
+}
+
+func TestUnknownAPI(t *testing.T) {
+	store := &eventStore{}
+	conn := newTestConnection(t, store, nil)
+
+	conn.client(request(12, 4, 5, true, func(e *encoder) {
+		e.string("billing").int32(1).string("member-1").null().tagged()
+	}))
+	conn.server(response(5, true, true, func(e *encoder) {
+		e.int32(0).int16(0).tagged()
+	}))
+
+	require.Len(t, store.events, 1)
+	kafka := kafkaFields(t, store.events[0])
+	assert.Equal(t, "Heartbeat", kafka["api_name"])
+	assert.Equal(t, int16(4), kafka["api_version"])
+}
+
+func TestPipelinedRequests(t *testing.T) {
+	store := &eventStore{}
+	conn := newTestConnection(t, store, nil)
+
+	metadata := func(correlationID int32) []byte {
+		return request(apiMetadata, 1, correlationID, false, func(e *encoder) {
+			e.array(-1)
+		})
+	}
+	conn.client(append(metadata(1), metadata(2)...))
+	conn.client(metadata(3))
+	// The response to the first request is missing.
+	for _, correlationID := range []int32{2, 3} {
+		conn.server(response(correlationID, false, false, func(e *encoder) {
+			e.array(0).int32(1).array(0)
+		}))
+	}
+
+	require.Len(t, store.events, 3)
+	var ids []interface{}
+	for _, event := range store.events {
+		ids = append(ids, kafkaFields(t, event)["correlation_id"])
+	}
+	assert.Equal(t, []interface{}{int32(1), int32(2), int32(3)}, ids)
+	assert.Equal(t, "Error", store.events[0].Fields["status"])
+	errors, _ := store.events[0].Fields.GetValue("error.message")
+	assert.Equal(t, "Unmatched request", errors)
+	assert.Equal(t, "OK", store.events[1].Fields["status"])
+}
+
+func TestSplitMessages(t *testing.T) {
+	store := &eventStore{}
+	conn := newTestConnection(t, store, nil)
+
+	requ := request(apiMetadata, 1, 1, false, func(e *encoder) {
+		e.array(1).string("orders")
+	})
+	resp := response(1, false, false, func(e *encoder) {
+		e.array(0).int32(1).array(0)
+	})
+	for _, b := range requ {
+		conn.client([]byte{b})
+	}
+	conn.server(resp[:3])
+	conn.server(resp[3:])
+
+	require.Len(t, store.events, 1)
+	assert.Equal(t, []string{"orders"}, kafkaFields(t, store.events[0])["topics"])
+}
+
+func TestLargeMessage(t *testing.T) {
+	store := &eventStore{}
+	conn := newTestConnection(t, store, nil)
+
+	conn.client(request(apiFetch, 4, 1, false, func(e *encoder) {
+		e.int32(-1).int32(500).int32(1).int32(52428800).int8(0)
+		e.array(1).string("events")
+		e.array(1).int32(0).int64(0).int32(52428800)
+	}))
+
+	// A response larger than the stream buffer, with a gap in its records.
+	records := bytes.Repeat([]byte{0}, tcp.TCPMaxDataInStream)
+	resp := response(1, false, false, func(e *encoder) {
+		e.int32(0)
+		e.array(1).string("events")
+		e.array(1).int32(0).int16(0).int64(1).int64(1).array(0).bytes(records)
+	})
+	const packetSize = 64 * 1024
+	for pos := 0; pos < len(resp); pos += packetSize {
+		if pos == 10*packetSize {
+			_, drop := conn.plugin.GapInStream(conn.tuple, tcp.TCPDirectionReverse, packetSize, conn.private)
+			require.False(t, drop)
+			continue
+		}
+		conn.server(resp[pos:min(pos+packetSize, len(resp))])
+	}
+
+	// The following messages are parsed.
+	conn.client(request(apiMetadata, 1, 2, false, func(e *encoder) {
+		e.array(1).string("events")
+	}))
+	conn.server(response(2, false, false, func(e *encoder) {
+		e.array(0).int32(1).array(0)
+	}))
+
+	require.Len(t, store.events, 2)
+	event := store.events[0]
+	assert.Equal(t, "Fetch", kafkaFields(t, event)["api_name"])
+	destinationBytes, _ := event.Fields.GetValue("destination.bytes")
+	assert.EqualValues(t, len(resp), destinationBytes)
+	errors, _ := event.Fields.GetValue("error.message")
+	assert.Equal(t, "Message too large to be parsed", errors)
+	assert.Equal(t, "Metadata", kafkaFields(t, store.events[1])["api_name"])
+}
+
+func TestInvalidMessageDropsConnection(t *testing.T) {
+	store := &eventStore{}
+	conn := newTestConnection(t, store, nil)
+
+	conn.client([]byte("GET / HTTP/1.1\r\n\r\n"))
+	assert.Nil(t, conn.private)
+	assert.Empty(t, store.events)
+}
+
+func TestExpiredRequests(t *testing.T) {
+	store := &eventStore{}
+	conn := newTestConnection(t, store, nil)
+
+	conn.client(request(apiJoinGroup, 0, 1, false, func(e *encoder) {
+		e.string("billing").int32(10000).string("").string("consumer").array(0)
+	}))
+	require.Empty(t, store.events)
+
+	conn.plugin.Expired(conn.tuple, conn.private)
+	require.Len(t, store.events, 1)
+	assert.Equal(t, "Error", store.events[0].Fields["status"])
+}
+
+func TestMaxPendingRequests(t *testing.T) {
+	store := &eventStore{}
+	conn := newTestConnection(t, store, map[string]interface{}{
+		"max_pending_requests": 2,
+	})
+
+	for correlationID := int32(1); correlationID <= 3; correlationID++ {
+		conn.client(request(apiMetadata, 1, correlationID, false, func(e *encoder) {
+			e.array(-1)
+		}))
+	}
+
+	require.Len(t, store.events, 1)
+	assert.Equal(t, int32(1), kafkaFields(t, store.events[0])["correlation_id"])
+}
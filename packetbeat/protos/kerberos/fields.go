@@ -0,0 +1,36 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+// Code generated by beats/dev-tools/cmd/asset/asset.go - DO NOT EDIT.
+
+package kerberos
+
+import (
+	"github.com/elastic/beats/v7/libbeat/asset"
+)
+
+func init() {
+	if err := asset.SetFields("packetbeat", "kerberos", asset.ModuleFieldsPri, AssetKerberos); err != nil {
+		panic(err)
+	}
+}
+
+// AssetKerberos returns asset data.
+// This is the base64 encoded zlib format compressed contents of protos/kerberos.
+func AssetKerberos() string {
+	return "eJy0ldFv2jAQxt/5K059JkybtkrjYRqDqKsYK02ptLfEOBewEmzvbMry3082cZuUbNpEK16IHX/3+75cLhGUWI+hRFojKTMAsMJWOIaLebN0MQDI0XAS2golx/BpAAAQtiOjkYtCcMAHlBYKgVVuRgNo/o393RFItsNOHbdsa41j2JDa62alfah9kPDnHo1N3YnHzSBQYn1QlLfWe4DDb7VFfwxUAXaLQRmMo7fKr81n0yFkk7soiW8zUATZ6up4MRr0oBmtpMFXYdNVHS5aUMtsGJCWjq8jks2TL1GcJDdJHy2vBEp7PqYmIbnQrPIpBMaj+hAOwm5BWAOErNqNvLHjHqiiq3V1Fx6BAWFAyaqGUqqDhILU7imGUesY/mI77bqUVYLj5/jHZLH8Fo+mN4tTwwbpQXB8LceNPDCwgpdonYfGD+ZQKBp2hJ4F0+eppLXd2DctU3836CM+356XCa4aBz39U+Y8VT4jc35NZ8CEmvPZFBplMC5I2UXpiapQdGCUs3WFp6TooF4AEiWn2u/6aA2YvdaKLOawrjtdLyQoypGe97gmLJBQcuxvYjTvPlxG3Jpou2M8Mlv2Nvp4eepIs5xZlr6QLy8TwteEEdvbLUorOHNHwNU66Yce/OUkir9Po9X1Ir5bTRbLU+7jm5HiywzIZ88jID6+fmb/9GTms+kIVn6nnRgEEcyPoyoj/t6HnwFnEtYIDIzYSCfefOmYsUJuet4IP51e2ZxmZMN/X69loFDk7ulIHWdtDywSKUq5yk9ZKyU3/wHqhMAJBa7Hz06Y138o7nri/KDaM/gfWHr6dj6bpnGSpMskntyvvqZJfHt/ncSzwe8BAIimkxQ="
+}
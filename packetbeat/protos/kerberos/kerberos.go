@@ -0,0 +1,258 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package kerberos
+
+import (
+	"slices"
+	"time"
+
+	"github.com/elastic/beats/v7/libbeat/beat"
+	"github.com/elastic/beats/v7/libbeat/common"
+	conf "github.com/elastic/elastic-agent-libs/config"
+	"github.com/elastic/elastic-agent-libs/logp"
+	"github.com/elastic/elastic-agent-libs/mapstr"
+	"github.com/elastic/elastic-agent-libs/monitoring"
+
+	"github.com/elastic/beats/v7/packetbeat/pb"
+	"github.com/elastic/beats/v7/packetbeat/procs"
+	"github.com/elastic/beats/v7/packetbeat/protos"
+)
+
+type connectionData struct {
+	streams [2]*stream
+	// pending are the requests waiting for their reply. The KDC replies in
+	// the order of the requests.
+	pending []*transaction
+}
+
+// transaction is a request and its reply.
+type transaction struct {
+	transport string
+	requ      *message
+	resp      *message
+}
+
+// Kerberos protocol plugin
+type kerberosPlugin struct {
+	// config
+	ports              []int
+	maxPendingRequests int
+	transactionTimeout time.Duration
+
+	// udpConnections holds the pending requests of the UDP clients, by IP
+	// and port tuple of their requests.
+	udpConnections *common.Cache
+
+	watcher *procs.ProcessesWatcher
+	results protos.Reporter
+}
+
+var (
+	debugf  = logp.MakeDebug("kerberos")
+	isDebug = false
+)
+
+var (
+	unmatchedResponses = monitoring.NewInt(nil, "kerberos.unmatched_responses")
+	unmatchedRequests  = monitoring.NewInt(nil, "kerberos.unmatched_requests")
+)
+
+func init() {
+	protos.Register("kerberos", New)
+}
+
+func New(
+	testMode bool,
+	results protos.Reporter,
+	watcher *procs.ProcessesWatcher,
+	cfg *conf.C,
+) (protos.Plugin, error) {
+	p := &kerberosPlugin{}
+	config := defaultConfig
+	if !testMode {
+		if err := cfg.Unpack(&config); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := p.init(results, watcher, &config); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+func (kp *kerberosPlugin) init(results protos.Reporter, watcher *procs.ProcessesWatcher, config *kerberosConfig) error {
+	kp.setFromConfig(config)
+
+	kp.udpConnections = common.NewCacheWithRemovalListener(
+		kp.transactionTimeout,
+		protos.DefaultTransactionHashSize,
+		func(k common.Key, v common.Value) {
+			conn, ok := v.(*connectionData)
+			if !ok {
+				logp.Err("Expired value is not a *kerberos.connectionData.")
+				return
+			}
+			kp.expireRequests(conn)
+		})
+	kp.udpConnections.StartJanitor(kp.transactionTimeout)
+
+	kp.results = results
+	kp.watcher = watcher
+	isDebug = logp.IsDebug("kerberos")
+
+	return nil
+}
+
+func (kp *kerberosPlugin) setFromConfig(config *kerberosConfig) {
+	kp.ports = config.Ports
+	kp.maxPendingRequests = config.MaxPendingRequests
+	kp.transactionTimeout = config.TransactionTimeout
+}
+
+func (kp *kerberosPlugin) GetPorts() []int {
+	return kp.ports
+}
+
+func (kp *kerberosPlugin) ConnectionTimeout() time.Duration {
+	return kp.transactionTimeout
+}
+
+// isServerPort returns whether a port is one of the configured Kerberos ports.
+func (kp *kerberosPlugin) isServerPort(port uint16) bool {
+	return slices.Contains(kp.ports, int(port))
+}
+
+func (kp *kerberosPlugin) handleMessage(conn *connectionData, m *message, transport string) {
+	if m.isRequest() {
+		if len(conn.pending) >= kp.maxPendingRequests {
+			unmatchedRequests.Add(1)
+			kp.publishTransaction(conn.pending[0])
+			conn.pending = conn.pending[1:]
+		}
+		conn.pending = append(conn.pending, &transaction{transport: transport, requ: m})
+		return
+	}
+
+	if len(conn.pending) == 0 {
+		unmatchedResponses.Add(1)
+		if isDebug {
+			debugf("%s has no matching request", msgTypeNames[m.msgType])
+		}
+		return
+	}
+	t := conn.pending[0]
+	conn.pending = conn.pending[1:]
+	t.resp = m
+	kp.publishTransaction(t)
+}
+
+// expireRequests publishes the requests still waiting for a reply.
+func (kp *kerberosPlugin) expireRequests(conn *connectionData) {
+	for _, t := range conn.pending {
+		unmatchedRequests.Add(1)
+		kp.publishTransaction(t)
+	}
+	conn.pending = nil
+}
+
+func (kp *kerberosPlugin) publishTransaction(t *transaction) {
+	if kp.results == nil {
+		return
+	}
+	kp.results(newEvent(t))
+}
+
+func newEvent(t *transaction) beat.Event {
+	requ := t.requ
+	src, dst := common.MakeEndpointPair(requ.tuple.BaseTuple, requ.cmdlineTuple)
+
+	evt, pbf := pb.NewBeatEvent(requ.ts)
+	pbf.SetSource(&src)
+	pbf.SetDestination(&dst)
+	pbf.AddIP(src.IP)
+	pbf.AddIP(dst.IP)
+	pbf.Source.Bytes = int64(requ.size)
+	pbf.Event.Dataset = "kerberos"
+	pbf.Event.Start = requ.ts
+	pbf.Network.Transport = t.transport
+	pbf.Network.Protocol = pbf.Event.Dataset
+
+	requestType := msgTypeNames[requ.msgType]
+	kerberos := mapstr.M{
+		"request_type": requestType,
+		"realm":        requ.realm,
+	}
+	putString := func(key, value string) {
+		if value != "" {
+			kerberos[key] = value
+		}
+	}
+	putList := func(key string, value []string) {
+		if len(value) > 0 {
+			kerberos[key] = value
+		}
+	}
+	putString("client", requ.client)
+	putString("service", requ.service)
+	putList("kdc_options", requ.kdcOptions)
+	putList("etypes", requ.etypes)
+	putList("padata_types", requ.paData)
+
+	status := common.OK_STATUS
+	var notes []string
+	if resp := t.resp; resp != nil {
+		pbf.Destination.Bytes = int64(resp.size)
+		pbf.Event.End = resp.ts
+		kerberos["response_type"] = msgTypeNames[resp.msgType]
+		switch resp.msgType {
+		case msgTypeKRBError:
+			status = common.ERROR_STATUS
+			kerberos["error_code"] = resp.errorCode
+			kerberos["error_name"] = errorName(resp.errorCode)
+			notes = append(notes, errorName(resp.errorCode))
+			if resp.errorText != "" {
+				notes = append(notes, resp.errorText)
+			}
+		default:
+			// The client of TGS requests is only known from the reply.
+			putString("client", resp.client)
+			putString("ticket_etype", resp.ticketEType)
+			putString("reply_etype", resp.replyEType)
+		}
+	} else {
+		status = common.ERROR_STATUS
+		notes = append(notes, "Unmatched request")
+	}
+
+	fields := evt.Fields
+	fields["type"] = pbf.Event.Dataset
+	fields["status"] = status
+	fields["method"] = requestType
+	fields["kerberos"] = kerberos
+	if requ.service != "" {
+		fields["resource"] = requ.service
+	}
+
+	if status == common.ERROR_STATUS {
+		pbf.Event.Outcome = "failure"
+	}
+	pbf.Error.Message = notes
+
+	return evt
+}
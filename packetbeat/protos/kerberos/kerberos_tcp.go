@@ -0,0 +1,165 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package kerberos
+
+import (
+	"encoding/binary"
+
+	"github.com/elastic/beats/v7/libbeat/common"
+	"github.com/elastic/elastic-agent-libs/logp"
+
+	"github.com/elastic/beats/v7/packetbeat/protos"
+	"github.com/elastic/beats/v7/packetbeat/protos/applayer"
+	"github.com/elastic/beats/v7/packetbeat/protos/tcp"
+)
+
+// recordMarkerSize is the size of the length prefixed to the messages sent
+// over TCP (RFC 4120, section 7.2.2).
+const recordMarkerSize = 4
+
+type stream struct {
+	applayer.Stream
+}
+
+func (kp *kerberosPlugin) Parse(
+	pkt *protos.Packet,
+	tcptuple *common.TCPTuple,
+	dir uint8,
+	private protos.ProtocolData,
+) protos.ProtocolData {
+	conn := ensureConnection(private)
+	conn = kp.doParse(conn, pkt, tcptuple, dir)
+	if conn == nil {
+		return nil
+	}
+	return conn
+}
+
+func ensureConnection(private protos.ProtocolData) *connectionData {
+	if private == nil {
+		return &connectionData{}
+	}
+
+	priv, ok := private.(*connectionData)
+	if !ok {
+		logp.Warn("kerberos connection data type error, create new one")
+		return &connectionData{}
+	}
+	if priv == nil {
+		logp.Warn("Unexpected: kerberos connection data not set, create new one")
+		return &connectionData{}
+	}
+
+	return priv
+}
+
+func (kp *kerberosPlugin) doParse(
+	conn *connectionData,
+	pkt *protos.Packet,
+	tcptuple *common.TCPTuple,
+	dir uint8,
+) *connectionData {
+	st := conn.streams[dir]
+	if st == nil {
+		st = &stream{}
+		st.Stream.Init(tcp.TCPMaxDataInStream)
+		conn.streams[dir] = st
+		if isDebug {
+			debugf("new stream: %p (dir=%v, len=%v)", st, dir, len(pkt.Payload))
+		}
+	}
+
+	if err := st.Append(pkt.Payload); err != nil {
+		if isDebug {
+			debugf("%v, dropping TCP stream: ", err)
+		}
+		return nil
+	}
+
+	tuple, cmdlineTuple := tcptuple.IPPort(), kp.watcher.FindProcessesTupleTCP(tcptuple.IPPort())
+	if dir == tcp.TCPDirectionReverse {
+		reversed := common.NewIPPortTuple(tuple.IPLength, tuple.DstIP, tuple.DstPort, tuple.SrcIP, tuple.SrcPort)
+		tuple = &reversed
+		if cmdlineTuple != nil {
+			reversedCmdline := cmdlineTuple.Reverse()
+			cmdlineTuple = &reversedCmdline
+		}
+	}
+
+	for st.Buf.Len() >= recordMarkerSize {
+		length := binary.BigEndian.Uint32(st.Buf.Bytes())
+		// The high bit is reserved for extensions of the framing, not used
+		// by the messages decoded here.
+		if length&0x80000000 != 0 || int(length) > st.MaxDataInStream-recordMarkerSize {
+			if isDebug {
+				debugf("invalid record length %d, dropping Kerberos connection", length)
+			}
+			return nil
+		}
+
+		size := recordMarkerSize + int(length)
+		if !st.Buf.Avail(size) {
+			// wait for more data
+			return conn
+		}
+		data, err := st.Buf.Collect(size)
+		var m *message
+		if err == nil {
+			m, err = parseMessage(data[recordMarkerSize:])
+		}
+		if err != nil {
+			if isDebug {
+				debugf("%v, dropping Kerberos connection", err)
+			}
+			return nil
+		}
+		m.ts = pkt.Ts
+		m.tuple = *tuple
+		m.cmdlineTuple = cmdlineTuple
+		m.size = size
+		kp.handleMessage(conn, m, "tcp")
+		st.Reset()
+	}
+
+	return conn
+}
+
+func (kp *kerberosPlugin) GapInStream(tcptuple *common.TCPTuple, dir uint8,
+	nbytes int, private protos.ProtocolData) (priv protos.ProtocolData, drop bool,
+) {
+	return private, true
+}
+
+func (kp *kerberosPlugin) ReceivedFin(tcptuple *common.TCPTuple, dir uint8,
+	private protos.ProtocolData,
+) protos.ProtocolData {
+	return private
+}
+
+// Expired publishes the requests still waiting for a reply when the
+// connection expires.
+func (kp *kerberosPlugin) Expired(tuple *common.TCPTuple, private protos.ProtocolData) {
+	conn, ok := private.(*connectionData)
+	if !ok || conn == nil {
+		return
+	}
+	if isDebug {
+		debugf("expired connection %s", tuple)
+	}
+	kp.expireRequests(conn)
+}
@@ -0,0 +1,366 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+//go:build !integration
+
+package kerberos
+
+import (
+	"encoding/asn1"
+	"encoding/binary"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/elastic/beats/v7/libbeat/beat"
+	"github.com/elastic/beats/v7/libbeat/common"
+	"github.com/elastic/beats/v7/packetbeat/procs"
+	"github.com/elastic/beats/v7/packetbeat/protos"
+	"github.com/elastic/beats/v7/packetbeat/protos/tcp"
+	"github.com/elastic/beats/v7/packetbeat/publish"
+	conf "github.com/elastic/elastic-agent-libs/config"
+	"github.com/elastic/elastic-agent-libs/mapstr"
+)
+
+type eventStore struct {
+	events []beat.Event
+}
+
+func (e *eventStore) publish(event beat.Event) {
+	publish.MarshalPacketbeatFields(&event, nil, nil)
+	e.events = append(e.events, event)
+}
+
+func kerberosModForTests(t *testing.T, store *eventStore) *kerberosPlugin {
+	t.Helper()
+	p, err := New(false, store.publish, &procs.ProcessesWatcher{}, conf.MustNewConfigFrom(map[string]interface{}{
+		"ports": []int{88},
+	}))
+	require.NoError(t, err)
+	kp := p.(*kerberosPlugin)
+	t.Cleanup(kp.udpConnections.StopJanitor)
+	return kp
+}
+
+func testTCPTuple() *common.TCPTuple {
+	t := &common.TCPTuple{
+		IPLength: 4,
+		BaseTuple: common.BaseTuple{
+			SrcIP: net.IPv4(192, 168, 0, 1), DstIP: net.IPv4(192, 168, 0, 2),
+			SrcPort: 6512, DstPort: 88,
+		},
+	}
+	t.ComputeHashables()
+	return t
+}
+
+// Helpers to encode DER elements. The standard library can't marshal
+// GeneralStrings, used by Kerberos for all its strings.
+
+func tlv(id byte, parts ...[]byte) []byte {
+	var content []byte
+	for _, part := range parts {
+		content = append(content, part...)
+	}
+	b := []byte{id}
+	switch n := len(content); {
+	case n < 0x80:
+		b = append(b, byte(n))
+	case n < 0x100:
+		b = append(b, 0x81, byte(n))
+	default:
+		b = append(b, 0x82)
+		b = binary.BigEndian.AppendUint16(b, uint16(n))
+	}
+	return append(b, content...)
+}
+
+func seq(parts ...[]byte) []byte {
+	return tlv(0x30, parts...)
+}
+
+// field is a field of a sequence, explicitly tagged.
+func field(tag int, v []byte) []byte {
+	return tlv(0xa0|byte(tag), v)
+}
+
+func app(tag int, parts ...[]byte) []byte {
+	return tlv(0x60|byte(tag), parts...)
+}
+
+func marshal(t *testing.T, v interface{}, params string) []byte {
+	t.Helper()
+	b, err := asn1.MarshalWithParams(v, params)
+	require.NoError(t, err)
+	return b
+}
+
+func integer(t *testing.T, v int) []byte {
+	return marshal(t, v, "")
+}
+
+func gstr(s string) []byte {
+	return tlv(asn1.TagGeneralString, []byte(s))
+}
+
+func name(t *testing.T, typ int, parts ...string) []byte {
+	var strs [][]byte
+	for _, part := range parts {
+		strs = append(strs, gstr(part))
+	}
+	return seq(field(0, integer(t, typ)), field(1, seq(strs...)))
+}
+
+func encrypted(t *testing.T, etype int) []byte {
+	return seq(field(0, integer(t, etype)), field(2, marshal(t, []byte("cipher"), "")))
+}
+
+func kdcReqMessage(t *testing.T, msgType int, cname, sname []byte, paTypes []int, etypes ...int) []byte {
+	var pa [][]byte
+	for _, typ := range paTypes {
+		pa = append(pa, seq(field(1, integer(t, typ)), field(2, marshal(t, []byte{}, ""))))
+	}
+	var etypeList [][]byte
+	for _, etype := range etypes {
+		etypeList = append(etypeList, integer(t, etype))
+	}
+	// forwardable, renewable, canonicalize and renewable-ok
+	options := asn1.BitString{Bytes: []byte{0x40, 0x81, 0x00, 0x10}, BitLength: 32}
+	body := [][]byte{field(0, marshal(t, options, ""))}
+	if cname != nil {
+		body = append(body, field(1, cname))
+	}
+	body = append(body,
+		field(2, gstr("EXAMPLE.COM")),
+		field(3, sname),
+		field(5, marshal(t, time.Date(2037, 9, 13, 2, 48, 5, 0, time.UTC), "generalized")),
+		field(7, integer(t, 12345)),
+		field(8, seq(etypeList...)),
+	)
+	parts := [][]byte{field(1, integer(t, 5)), field(2, integer(t, msgType))}
+	if len(pa) > 0 {
+		parts = append(parts, field(3, seq(pa...)))
+	}
+	parts = append(parts, field(4, seq(body...)))
+	return app(msgType, seq(parts...))
+}
+
+func kdcRepMessage(t *testing.T, msgType int, cname, sname []byte, ticketEType, replyEType int) []byte {
+	ticket := app(1, seq(
+		field(0, integer(t, 5)),
+		field(1, gstr("EXAMPLE.COM")),
+		field(2, sname),
+		field(3, encrypted(t, ticketEType)),
+	))
+	return app(msgType, seq(
+		field(0, integer(t, 5)),
+		field(1, integer(t, msgType)),
+		field(3, gstr("EXAMPLE.COM")),
+		field(4, cname),
+		field(5, ticket),
+		field(6, encrypted(t, replyEType)),
+	))
+}
+
+func krbErrorMessage(t *testing.T, code int, sname []byte, text string) []byte {
+	parts := [][]byte{
+		field(0, integer(t, 5)),
+		field(1, integer(t, msgTypeKRBError)),
+		field(4, marshal(t, time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC), "generalized")),
+		field(5, integer(t, 0)),
+		field(6, integer(t, code)),
+		field(9, gstr("EXAMPLE.COM")),
+		field(10, sname),
+	}
+	if text != "" {
+		parts = append(parts, field(11, gstr(text)))
+	}
+	return app(msgTypeKRBError, seq(parts...))
+}
+
+// record prefixes a message with its length, as sent over TCP.
+func record(msg []byte) []byte {
+	return append(binary.BigEndian.AppendUint32(nil, uint32(len(msg))), msg...)
+}
+
+type testPacket struct {
+	dir  uint8
+	data []byte
+}
+
+func client(data []byte) testPacket {
+	return testPacket{dir: tcp.TCPDirectionOriginal, data: data}
+}
+
+func server(data []byte) testPacket {
+	return testPacket{dir: tcp.TCPDirectionReverse, data: data}
+}
+
+func parsePackets(kerberos *kerberosPlugin, packets ...testPacket) protos.ProtocolData {
+	tuple := testTCPTuple()
+	var private protos.ProtocolData
+	ts := time.Now()
+	for _, p := range packets {
+		ts = ts.Add(time.Millisecond)
+		private = kerberos.Parse(&protos.Packet{Ts: ts, Payload: p.data}, tuple, p.dir, private)
+	}
+	return private
+}
+
+func kerberosFields(t *testing.T, event beat.Event) mapstr.M {
+	t.Helper()
+	v, err := event.GetValue("kerberos")
+	require.NoError(t, err)
+	return v.(mapstr.M)
+}
+
+func TestASExchange(t *testing.T) {
+	store := &eventStore{}
+	kerberos := kerberosModForTests(t, store)
+
+	alice := name(t, 1, "alice")
+	krbtgt := name(t, 2, "krbtgt", "EXAMPLE.COM")
+	requ := record(kdcReqMessage(t, msgTypeASReq, alice, krbtgt, []int{2, 128}, 18, 17, 23))
+	resp := record(kdcRepMessage(t, msgTypeASRep, alice, krbtgt, 18, 18))
+	parsePackets(kerberos,
+		client(record(kdcReqMessage(t, msgTypeASReq, alice, krbtgt, []int{128}, 18, 17, 23))),
+		server(record(krbErrorMessage(t, 25, krbtgt, ""))),
+		client(requ),
+		server(resp),
+	)
+
+	require.Len(t, store.events, 2)
+	preauth := store.events[0]
+	assert.Equal(t, mapstr.M{
+		"request_type":  "AS-REQ",
+		"response_type": "KRB-ERROR",
+		"client":        "alice@EXAMPLE.COM",
+		"service":       "krbtgt/EXAMPLE.COM@EXAMPLE.COM",
+		"realm":         "EXAMPLE.COM",
+		"kdc_options":   []string{"forwardable", "renewable", "canonicalize", "renewable-ok"},
+		"etypes":        []string{"aes256-cts-hmac-sha1-96", "aes128-cts-hmac-sha1-96", "rc4-hmac"},
+		"padata_types":  []string{"PA-PAC-REQUEST"},
+		"error_code":    25,
+		"error_name":    "KDC_ERR_PREAUTH_REQUIRED",
+	}, kerberosFields(t, preauth))
+	assert.Equal(t, "Error", preauth.Fields["status"])
+	msg, _ := preauth.GetValue("error.message")
+	assert.Equal(t, "KDC_ERR_PREAUTH_REQUIRED", msg)
+
+	event := store.events[1]
+	fields := kerberosFields(t, event)
+	assert.Equal(t, []string{"PA-ENC-TIMESTAMP", "PA-PAC-REQUEST"}, fields["padata_types"])
+	assert.Equal(t, "aes256-cts-hmac-sha1-96", fields["ticket_etype"])
+	assert.Equal(t, "aes256-cts-hmac-sha1-96", fields["reply_etype"])
+	assert.Equal(t, "OK", event.Fields["status"])
+	assert.Equal(t, "AS-REQ", event.Fields["method"])
+	assert.Equal(t, "krbtgt/EXAMPLE.COM@EXAMPLE.COM", event.Fields["resource"])
+	transport, _ := event.GetValue("network.transport")
+	assert.Equal(t, "tcp", transport)
+	bytes, _ := event.GetValue("source.bytes")
+	assert.EqualValues(t, len(requ), bytes)
+	bytes, _ = event.GetValue("destination.bytes")
+	assert.EqualValues(t, len(resp), bytes)
+	port, _ := event.GetValue("destination.port")
+	assert.EqualValues(t, 88, port)
+}
+
+func TestTGSExchangeUDP(t *testing.T) {
+	store := &eventStore{}
+	kerberos := kerberosModForTests(t, store)
+
+	requTuple := common.NewIPPortTuple(4, net.IPv4(192, 168, 0, 1), 52000, net.IPv4(192, 168, 0, 2), 88)
+	respTuple := common.NewIPPortTuple(4, net.IPv4(192, 168, 0, 2), 88, net.IPv4(192, 168, 0, 1), 52000)
+	cifs := name(t, 2, "cifs", "fs.example.com")
+	ts := time.Now()
+	kerberos.ParseUDP(&protos.Packet{Ts: ts, Tuple: requTuple, Payload: kdcReqMessage(t, msgTypeTGSReq, nil, cifs, []int{1}, 18, 23)})
+	assert.Empty(t, store.events)
+	resp := kdcRepMessage(t, msgTypeTGSRep, name(t, 1, "alice"), cifs, 23, 18)
+	kerberos.ParseUDP(&protos.Packet{Ts: ts.Add(time.Millisecond), Tuple: respTuple, Payload: resp})
+
+	require.Len(t, store.events, 1)
+	event := store.events[0]
+	fields := kerberosFields(t, event)
+	assert.Equal(t, "TGS-REQ", fields["request_type"])
+	assert.Equal(t, "TGS-REP", fields["response_type"])
+	assert.Equal(t, "alice@EXAMPLE.COM", fields["client"])
+	assert.Equal(t, "cifs/fs.example.com@EXAMPLE.COM", fields["service"])
+	assert.Equal(t, "rc4-hmac", fields["ticket_etype"])
+	transport, _ := event.GetValue("network.transport")
+	assert.Equal(t, "udp", transport)
+	port, _ := event.GetValue("source.port")
+	assert.EqualValues(t, 52000, port)
+}
+
+func TestErrorText(t *testing.T) {
+	store := &eventStore{}
+	kerberos := kerberosModForTests(t, store)
+
+	host := name(t, 3, "host", "unknown.example.com")
+	parsePackets(kerberos,
+		client(record(kdcReqMessage(t, msgTypeTGSReq, nil, host, []int{1}, 18))),
+		server(record(krbErrorMessage(t, 7, host, "Server not found in Kerberos database"))),
+	)
+
+	require.Len(t, store.events, 1)
+	event := store.events[0]
+	assert.Equal(t, "KDC_ERR_S_PRINCIPAL_UNKNOWN", kerberosFields(t, event)["error_name"])
+	msg, _ := event.GetValue("error.message")
+	assert.Equal(t, []string{"KDC_ERR_S_PRINCIPAL_UNKNOWN", "Server not found in Kerberos database"}, msg)
+	outcome, _ := event.GetValue("event.outcome")
+	assert.Equal(t, "failure", outcome)
+}
+
+func TestFragmentedMessages(t *testing.T) {
+	store := &eventStore{}
+	kerberos := kerberosModForTests(t, store)
+
+	krbtgt := name(t, 2, "krbtgt", "EXAMPLE.COM")
+	data := record(kdcReqMessage(t, msgTypeASReq, name(t, 1, "alice"), krbtgt, nil, 18))
+	data = append(data, record(kdcReqMessage(t, msgTypeASReq, name(t, 1, "bob"), krbtgt, nil, 18))...)
+	var packets []testPacket
+	for i := 0; i < len(data); i += 7 {
+		packets = append(packets, client(data[i:min(i+7, len(data))]))
+	}
+	packets = append(packets, server(record(kdcRepMessage(t, msgTypeASRep, name(t, 1, "alice"), krbtgt, 18, 18))))
+	private := parsePackets(kerberos, packets...)
+
+	require.Len(t, store.events, 1)
+	assert.Equal(t, "alice@EXAMPLE.COM", kerberosFields(t, store.events[0])["client"])
+
+	kerberos.Expired(testTCPTuple(), private)
+	require.Len(t, store.events, 2)
+	assert.Equal(t, "bob@EXAMPLE.COM", kerberosFields(t, store.events[1])["client"])
+	msg, _ := store.events[1].GetValue("error.message")
+	assert.Equal(t, "Unmatched request", msg)
+}
+
+func TestInvalidData(t *testing.T) {
+	store := &eventStore{}
+	kerberos := kerberosModForTests(t, store)
+
+	private := parsePackets(kerberos, client([]byte("GET / HTTP/1.1\r\n\r\n")))
+	assert.Nil(t, private)
+	assert.Empty(t, store.events)
+
+	private = parsePackets(kerberos, client(record([]byte{0x30, 0x03, 0x02, 0x01, 0x05})))
+	assert.Nil(t, private)
+	assert.Empty(t, store.events)
+}
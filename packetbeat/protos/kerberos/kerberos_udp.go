@@ -0,0 +1,49 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package kerberos
+
+import (
+	"github.com/elastic/beats/v7/packetbeat/protos"
+)
+
+// ParseUDP parses the messages sent over UDP, one per datagram.
+func (kp *kerberosPlugin) ParseUDP(pkt *protos.Packet) {
+	key := pkt.Tuple.Hashable()
+	if !kp.isServerPort(pkt.Tuple.DstPort) {
+		key = pkt.Tuple.RevHashable()
+	}
+
+	m, err := parseMessage(pkt.Payload)
+	if err != nil {
+		if isDebug {
+			debugf("%v, dropping Kerberos datagram from %s", err, &pkt.Tuple)
+		}
+		return
+	}
+	m.ts = pkt.Ts
+	m.tuple = pkt.Tuple
+	m.cmdlineTuple = kp.watcher.FindProcessesTupleUDP(&pkt.Tuple)
+	m.size = len(pkt.Payload)
+
+	conn, _ := kp.udpConnections.Get(key).(*connectionData)
+	if conn == nil {
+		conn = &connectionData{}
+		kp.udpConnections.Put(key, conn)
+	}
+	kp.handleMessage(conn, m, "udp")
+}
@@ -0,0 +1,210 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package kerberos
+
+import (
+	"encoding/asn1"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/elastic/beats/v7/libbeat/common"
+)
+
+// ASN.1 structures of the messages exchanged with the KDC, as defined in
+// RFC 4120. Only the fields reported are decoded, the trailing fields of
+// sequences are ignored.
+
+type principalName struct {
+	NameType   int      `asn1:"explicit,tag:0"`
+	NameString []string `asn1:"generalstring,explicit,tag:1"`
+}
+
+type paData struct {
+	Type int `asn1:"explicit,tag:1"`
+}
+
+type encryptedData struct {
+	EType int `asn1:"explicit,tag:0"`
+}
+
+type kdcReq struct {
+	PVNO    int        `asn1:"explicit,tag:1"`
+	MsgType int        `asn1:"explicit,tag:2"`
+	PAData  []paData   `asn1:"explicit,optional,tag:3"`
+	ReqBody kdcReqBody `asn1:"explicit,tag:4"`
+}
+
+type kdcReqBody struct {
+	KDCOptions asn1.BitString `asn1:"explicit,tag:0"`
+	CName      principalName  `asn1:"explicit,optional,tag:1"`
+	Realm      string         `asn1:"generalstring,explicit,tag:2"`
+	SName      principalName  `asn1:"explicit,optional,tag:3"`
+	From       time.Time      `asn1:"generalized,explicit,optional,tag:4"`
+	Till       time.Time      `asn1:"generalized,explicit,tag:5"`
+	RTime      time.Time      `asn1:"generalized,explicit,optional,tag:6"`
+	Nonce      int64          `asn1:"explicit,tag:7"`
+	EType      []int          `asn1:"explicit,tag:8"`
+}
+
+type kdcRep struct {
+	PVNO    int           `asn1:"explicit,tag:0"`
+	MsgType int           `asn1:"explicit,tag:1"`
+	PAData  []paData      `asn1:"explicit,optional,tag:2"`
+	CRealm  string        `asn1:"generalstring,explicit,tag:3"`
+	CName   principalName `asn1:"explicit,tag:4"`
+	Ticket  asn1.RawValue `asn1:"explicit,tag:5"`
+	EncPart encryptedData `asn1:"explicit,tag:6"`
+}
+
+type ticket struct {
+	TktVNO  int           `asn1:"explicit,tag:0"`
+	Realm   string        `asn1:"generalstring,explicit,tag:1"`
+	SName   principalName `asn1:"explicit,tag:2"`
+	EncPart encryptedData `asn1:"explicit,tag:3"`
+}
+
+type krbError struct {
+	PVNO      int           `asn1:"explicit,tag:0"`
+	MsgType   int           `asn1:"explicit,tag:1"`
+	CTime     time.Time     `asn1:"generalized,explicit,optional,tag:2"`
+	Cusec     int           `asn1:"explicit,optional,tag:3"`
+	STime     time.Time     `asn1:"generalized,explicit,tag:4"`
+	Susec     int           `asn1:"explicit,tag:5"`
+	ErrorCode int           `asn1:"explicit,tag:6"`
+	CRealm    string        `asn1:"generalstring,explicit,optional,tag:7"`
+	CName     principalName `asn1:"explicit,optional,tag:8"`
+	Realm     string        `asn1:"generalstring,explicit,tag:9"`
+	SName     principalName `asn1:"explicit,tag:10"`
+	EText     string        `asn1:"generalstring,explicit,optional,tag:11"`
+}
+
+// message is a decoded message exchanged with the KDC.
+type message struct {
+	ts time.Time
+	// tuple and cmdlineTuple are oriented from the sender of the message.
+	tuple        common.IPPortTuple
+	cmdlineTuple *common.ProcessTuple
+	size         int
+
+	msgType int
+
+	// Requests
+	client     string
+	service    string
+	realm      string
+	kdcOptions []string
+	etypes     []string
+	paData     []string
+
+	// Replies
+	ticketEType string
+	replyEType  string
+
+	// Errors
+	errorCode int
+	errorText string
+}
+
+// isRequest returns whether the message is sent by a client to the KDC.
+func (m *message) isRequest() bool {
+	return m.msgType == msgTypeASReq || m.msgType == msgTypeTGSReq
+}
+
+// parseMessage decodes a message exchanged with the KDC.
+func parseMessage(data []byte) (*message, error) {
+	if len(data) == 0 {
+		return nil, fmt.Errorf("empty message")
+	}
+	// Messages are application tagged with their message type.
+	m := &message{msgType: int(data[0] & 0x1f)}
+	params := fmt.Sprintf("application,explicit,tag:%d", m.msgType)
+	var err error
+	switch m.msgType {
+	case msgTypeASReq, msgTypeTGSReq:
+		var req kdcReq
+		if _, err = asn1.UnmarshalWithParams(data, &req, params); err == nil {
+			m.setRequest(&req)
+		}
+	case msgTypeASRep, msgTypeTGSRep:
+		var rep kdcRep
+		if _, err = asn1.UnmarshalWithParams(data, &rep, params); err == nil {
+			err = m.setReply(&rep)
+		}
+	case msgTypeKRBError:
+		var krbErr krbError
+		if _, err = asn1.UnmarshalWithParams(data, &krbErr, params); err == nil {
+			m.errorCode = krbErr.ErrorCode
+			m.errorText = krbErr.EText
+			m.realm = krbErr.Realm
+			if len(krbErr.CName.NameString) > 0 {
+				m.client = principal(krbErr.CName, krbErr.CRealm)
+			}
+		}
+	default:
+		return nil, fmt.Errorf("unexpected message type %d", m.msgType)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", msgTypeNames[m.msgType], err)
+	}
+	return m, nil
+}
+
+func (m *message) setRequest(req *kdcReq) {
+	body := &req.ReqBody
+	m.realm = body.Realm
+	if len(body.CName.NameString) > 0 {
+		m.client = principal(body.CName, body.Realm)
+	}
+	if len(body.SName.NameString) > 0 {
+		m.service = principal(body.SName, body.Realm)
+	}
+	for bit := 0; bit < body.KDCOptions.BitLength; bit++ {
+		if name, ok := kdcOptionNames[bit]; ok && body.KDCOptions.At(bit) == 1 {
+			m.kdcOptions = append(m.kdcOptions, name)
+		}
+	}
+	for _, etype := range body.EType {
+		m.etypes = append(m.etypes, etypeName(etype))
+	}
+	for _, pa := range req.PAData {
+		m.paData = append(m.paData, paDataName(pa.Type))
+	}
+}
+
+func (m *message) setReply(rep *kdcRep) error {
+	m.client = principal(rep.CName, rep.CRealm)
+	m.replyEType = etypeName(rep.EncPart.EType)
+	// The raw value of an explicitly tagged field includes its tag.
+	var t ticket
+	if _, err := asn1.UnmarshalWithParams(rep.Ticket.Bytes, &t, "application,explicit,tag:1"); err != nil {
+		return fmt.Errorf("invalid ticket: %w", err)
+	}
+	m.service = principal(t.SName, t.Realm)
+	m.ticketEType = etypeName(t.EncPart.EType)
+	return nil
+}
+
+// principal formats a principal name as in Kerberos tools.
+func principal(name principalName, realm string) string {
+	s := strings.Join(name.NameString, "/")
+	if realm != "" {
+		s += "@" + realm
+	}
+	return s
+}
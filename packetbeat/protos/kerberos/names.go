@@ -0,0 +1,191 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package kerberos
+
+import "strconv"
+
+// Message types of the messages exchanged with the KDC.
+const (
+	msgTypeASReq    = 10
+	msgTypeASRep    = 11
+	msgTypeTGSReq   = 12
+	msgTypeTGSRep   = 13
+	msgTypeKRBError = 30
+)
+
+var msgTypeNames = map[int]string{
+	msgTypeASReq:    "AS-REQ",
+	msgTypeASRep:    "AS-REP",
+	msgTypeTGSReq:   "TGS-REQ",
+	msgTypeTGSRep:   "TGS-REP",
+	msgTypeKRBError: "KRB-ERROR",
+}
+
+// etypeNames are the names of the encryption types, as used by MIT Kerberos.
+var etypeNames = map[int]string{
+	1:  "des-cbc-crc",
+	2:  "des-cbc-md4",
+	3:  "des-cbc-md5",
+	5:  "des3-cbc-md5",
+	7:  "des3-cbc-sha1",
+	16: "des3-cbc-sha1-kd",
+	17: "aes128-cts-hmac-sha1-96",
+	18: "aes256-cts-hmac-sha1-96",
+	19: "aes128-cts-hmac-sha256-128",
+	20: "aes256-cts-hmac-sha384-192",
+	23: "rc4-hmac",
+	24: "rc4-hmac-exp",
+	25: "camellia128-cts-cmac",
+	26: "camellia256-cts-cmac",
+}
+
+func etypeName(etype int) string {
+	if name, ok := etypeNames[etype]; ok {
+		return name
+	}
+	return strconv.Itoa(etype)
+}
+
+var errorNames = map[int]string{
+	0:  "KDC_ERR_NONE",
+	1:  "KDC_ERR_NAME_EXP",
+	2:  "KDC_ERR_SERVICE_EXP",
+	3:  "KDC_ERR_BAD_PVNO",
+	4:  "KDC_ERR_C_OLD_MAST_KVNO",
+	5:  "KDC_ERR_S_OLD_MAST_KVNO",
+	6:  "KDC_ERR_C_PRINCIPAL_UNKNOWN",
+	7:  "KDC_ERR_S_PRINCIPAL_UNKNOWN",
+	8:  "KDC_ERR_PRINCIPAL_NOT_UNIQUE",
+	9:  "KDC_ERR_NULL_KEY",
+	10: "KDC_ERR_CANNOT_POSTDATE",
+	11: "KDC_ERR_NEVER_VALID",
+	12: "KDC_ERR_POLICY",
+	13: "KDC_ERR_BADOPTION",
+	14: "KDC_ERR_ETYPE_NOSUPP",
+	15: "KDC_ERR_SUMTYPE_NOSUPP",
+	16: "KDC_ERR_PADATA_TYPE_NOSUPP",
+	17: "KDC_ERR_TRTYPE_NOSUPP",
+	18: "KDC_ERR_CLIENT_REVOKED",
+	19: "KDC_ERR_SERVICE_REVOKED",
+	20: "KDC_ERR_TGT_REVOKED",
+	21: "KDC_ERR_CLIENT_NOTYET",
+	22: "KDC_ERR_SERVICE_NOTYET",
+	23: "KDC_ERR_KEY_EXPIRED",
+	24: "KDC_ERR_PREAUTH_FAILED",
+	25: "KDC_ERR_PREAUTH_REQUIRED",
+	26: "KDC_ERR_SERVER_NOMATCH",
+	27: "KDC_ERR_MUST_USE_USER2USER",
+	28: "KDC_ERR_PATH_NOT_ACCEPTED",
+	29: "KDC_ERR_SVC_UNAVAILABLE",
+	31: "KRB_AP_ERR_BAD_INTEGRITY",
+	32: "KRB_AP_ERR_TKT_EXPIRED",
+	33: "KRB_AP_ERR_TKT_NYV",
+	34: "KRB_AP_ERR_REPEAT",
+	35: "KRB_AP_ERR_NOT_US",
+	36: "KRB_AP_ERR_BADMATCH",
+	37: "KRB_AP_ERR_SKEW",
+	38: "KRB_AP_ERR_BADADDR",
+	39: "KRB_AP_ERR_BADVERSION",
+	40: "KRB_AP_ERR_MSG_TYPE",
+	41: "KRB_AP_ERR_MODIFIED",
+	42: "KRB_AP_ERR_BADORDER",
+	44: "KRB_AP_ERR_BADKEYVER",
+	45: "KRB_AP_ERR_NOKEY",
+	46: "KRB_AP_ERR_MUT_FAIL",
+	47: "KRB_AP_ERR_BADDIRECTION",
+	48: "KRB_AP_ERR_METHOD",
+	49: "KRB_AP_ERR_BADSEQ",
+	50: "KRB_AP_ERR_INAPP_CKSUM",
+	51: "KRB_AP_PATH_NOT_ACCEPTED",
+	52: "KRB_ERR_RESPONSE_TOO_BIG",
+	60: "KRB_ERR_GENERIC",
+	61: "KRB_ERR_FIELD_TOOLONG",
+	62: "KDC_ERR_CLIENT_NOT_TRUSTED",
+	63: "KDC_ERR_KDC_NOT_TRUSTED",
+	64: "KDC_ERR_INVALID_SIG",
+	65: "KDC_ERR_KEY_TOO_WEAK",
+	66: "KDC_ERR_CERTIFICATE_MISMATCH",
+	67: "KRB_AP_ERR_NO_TGT",
+	68: "KDC_ERR_WRONG_REALM",
+	69: "KRB_AP_ERR_USER_TO_USER_REQUIRED",
+	70: "KDC_ERR_CANT_VERIFY_CERTIFICATE",
+	71: "KDC_ERR_INVALID_CERTIFICATE",
+	72: "KDC_ERR_REVOKED_CERTIFICATE",
+	73: "KDC_ERR_REVOCATION_STATUS_UNKNOWN",
+	74: "KDC_ERR_REVOCATION_STATUS_UNAVAILABLE",
+	75: "KDC_ERR_CLIENT_NAME_MISMATCH",
+	76: "KDC_ERR_KDC_NAME_MISMATCH",
+}
+
+func errorName(code int) string {
+	if name, ok := errorNames[code]; ok {
+		return name
+	}
+	return strconv.Itoa(code)
+}
+
+var paDataNames = map[int]string{
+	1:   "PA-TGS-REQ",
+	2:   "PA-ENC-TIMESTAMP",
+	3:   "PA-PW-SALT",
+	11:  "PA-ETYPE-INFO",
+	14:  "PA-PK-AS-REQ-OLD",
+	15:  "PA-PK-AS-REP-OLD",
+	16:  "PA-PK-AS-REQ",
+	17:  "PA-PK-AS-REP",
+	19:  "PA-ETYPE-INFO2",
+	20:  "PA-SVR-REFERRAL-INFO",
+	128: "PA-PAC-REQUEST",
+	129: "PA-FOR-USER",
+	130: "PA-FOR-X509-USER",
+	133: "PA-FX-COOKIE",
+	136: "PA-FX-FAST",
+	137: "PA-FX-ERROR",
+	138: "PA-ENCRYPTED-CHALLENGE",
+	149: "PA-REQ-ENC-PA-REP",
+	150: "PA-AS-FRESHNESS",
+	165: "PA-SUPPORTED-ENCTYPES",
+	167: "PA-PAC-OPTIONS",
+}
+
+func paDataName(typ int) string {
+	if name, ok := paDataNames[typ]; ok {
+		return name
+	}
+	return strconv.Itoa(typ)
+}
+
+// kdcOptionNames are the names of the bits of the KDC options.
+var kdcOptionNames = map[int]string{
+	1:  "forwardable",
+	2:  "forwarded",
+	3:  "proxiable",
+	4:  "proxy",
+	5:  "allow-postdate",
+	6:  "postdated",
+	8:  "renewable",
+	11: "opt-hardware-auth",
+	14: "cname-in-addl-tkt",
+	15: "canonicalize",
+	16: "request-anonymous",
+	26: "disable-transited-check",
+	27: "renewable-ok",
+	28: "enc-tkt-in-skey",
+	30: "renew",
+	31: "validate",
+}
@@ -0,0 +1,218 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package ldap
+
+import (
+	"errors"
+)
+
+// Classes of BER tags.
+const (
+	classUniversal   = 0
+	classApplication = 1
+	classContext     = 2
+)
+
+// Universal tags used by LDAP.
+const (
+	tagBoolean     = 1
+	tagInteger     = 2
+	tagOctetString = 4
+	tagEnumerated  = 10
+	tagSequence    = 16
+)
+
+var (
+	errTruncated         = errors.New("element truncated")
+	errIndefiniteLength  = errors.New("indefinite length not allowed")
+	errInvalidLength     = errors.New("invalid length")
+	errUnexpectedElement = errors.New("unexpected element")
+)
+
+// element is a BER encoded element.
+type element struct {
+	class       uint8
+	constructed bool
+	tag         int
+	data        []byte
+}
+
+func (e element) is(class uint8, tag int) bool {
+	return e.class == class && e.tag == tag
+}
+
+// readHeader reads the identifier and length octets of the element at the
+// start of b. It returns errTruncated if more data is needed.
+func readHeader(b []byte) (class uint8, constructed bool, tag, headerLen, length int, err error) {
+	if len(b) < 2 {
+		return 0, false, 0, 0, 0, errTruncated
+	}
+	class, constructed, tag = b[0]>>6, b[0]&0x20 != 0, int(b[0]&0x1f)
+	pos := 1
+	if tag == 0x1f {
+		// High tag number form.
+		tag = 0
+		for {
+			if pos >= len(b) {
+				return 0, false, 0, 0, 0, errTruncated
+			}
+			if pos > 3 {
+				return 0, false, 0, 0, 0, errUnexpectedElement
+			}
+			c := b[pos]
+			pos++
+			tag = tag<<7 | int(c&0x7f)
+			if c&0x80 == 0 {
+				break
+			}
+		}
+	}
+	if pos >= len(b) {
+		return 0, false, 0, 0, 0, errTruncated
+	}
+	c := b[pos]
+	pos++
+	switch {
+	case c < 0x80:
+		length = int(c)
+	case c == 0x80:
+		return 0, false, 0, 0, 0, errIndefiniteLength
+	default:
+		// Long form lengths need not be minimal in BER, as used by
+		// Active Directory.
+		n := int(c & 0x7f)
+		if n > 4 {
+			return 0, false, 0, 0, 0, errInvalidLength
+		}
+		if len(b) < pos+n {
+			return 0, false, 0, 0, 0, errTruncated
+		}
+		for _, c := range b[pos : pos+n] {
+			length = length<<8 | int(c)
+		}
+		pos += n
+		if length < 0 {
+			return 0, false, 0, 0, 0, errInvalidLength
+		}
+	}
+	return class, constructed, tag, pos, length, nil
+}
+
+// decoder reads the elements of a BER encoded sequence. The first error is
+// kept and subsequent reads return empty elements.
+type decoder struct {
+	buf []byte
+	err error
+}
+
+func (d *decoder) fail(err error) {
+	if d.err == nil {
+		d.err = err
+	}
+}
+
+// more returns whether elements are left.
+func (d *decoder) more() bool {
+	return d.err == nil && len(d.buf) > 0
+}
+
+// next reads the next element.
+func (d *decoder) next() element {
+	if d.err != nil {
+		return element{}
+	}
+	class, constructed, tag, headerLen, length, err := readHeader(d.buf)
+	if err == nil && len(d.buf) < headerLen+length {
+		err = errTruncated
+	}
+	if err != nil {
+		d.fail(err)
+		return element{}
+	}
+	e := element{class: class, constructed: constructed, tag: tag, data: d.buf[headerLen : headerLen+length]}
+	d.buf = d.buf[headerLen+length:]
+	return e
+}
+
+// peek returns whether the next element has the given class and tag.
+func (d *decoder) peek(class uint8, tag int) bool {
+	if !d.more() {
+		return false
+	}
+	c, _, t, _, _, err := readHeader(d.buf)
+	return err == nil && c == class && t == tag
+}
+
+// expect reads the next element, which must have the given class and tag.
+func (d *decoder) expect(class uint8, tag int) element {
+	e := d.next()
+	if d.err == nil && !e.is(class, tag) {
+		d.fail(errUnexpectedElement)
+		return element{}
+	}
+	return e
+}
+
+func (d *decoder) sequence() *decoder {
+	return d.children(d.expect(classUniversal, tagSequence))
+}
+
+// children returns a decoder for the elements of a constructed element.
+func (d *decoder) children(e element) *decoder {
+	return &decoder{buf: e.data, err: d.err}
+}
+
+// done propagates the error of a child decoder.
+func (d *decoder) done(child *decoder) {
+	if child.err != nil {
+		d.fail(child.err)
+	}
+}
+
+func (d *decoder) integer() int64 {
+	return d.intValue(d.expect(classUniversal, tagInteger))
+}
+
+func (d *decoder) enumerated() int64 {
+	return d.intValue(d.expect(classUniversal, tagEnumerated))
+}
+
+func (d *decoder) octetString() string {
+	return string(d.expect(classUniversal, tagOctetString).data)
+}
+
+func (d *decoder) boolean() bool {
+	e := d.expect(classUniversal, tagBoolean)
+	return len(e.data) > 0 && e.data[0] != 0
+}
+
+// intValue decodes the content of an INTEGER or ENUMERATED element.
+func (d *decoder) intValue(e element) int64 {
+	if d.err != nil {
+		return 0
+	}
+	if len(e.data) == 0 || len(e.data) > 8 {
+		d.fail(errInvalidLength)
+		return 0
+	}
+	v := int64(int8(e.data[0]))
+	for _, c := range e.data[1:] {
+		v = v<<8 | int64(c)
+	}
+	return v
+}
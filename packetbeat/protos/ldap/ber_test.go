@@ -0,0 +1,62 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+//go:build !integration
+
+package ldap
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadHeader(t *testing.T) {
+	for _, tc := range []struct {
+		data      []byte
+		tag       int
+		headerLen int
+		length    int
+		err       error
+	}{
+		{data: []byte{0x30, 0x05}, tag: tagSequence, headerLen: 2, length: 5},
+		{data: []byte{0x30, 0x81, 0x80}, tag: tagSequence, headerLen: 3, length: 128},
+		// Non-minimal lengths are valid BER.
+		{data: []byte{0x30, 0x84, 0x00, 0x00, 0x00, 0x05}, tag: tagSequence, headerLen: 6, length: 5},
+		{data: []byte{0x30, 0x84, 0x00}, err: errTruncated},
+		{data: []byte{0x30, 0x80}, err: errIndefiniteLength},
+		{data: []byte{0x30, 0x85, 0, 0, 0, 0, 1}, err: errInvalidLength},
+		{data: []byte{0x30}, err: errTruncated},
+	} {
+		_, _, tag, headerLen, length, err := readHeader(tc.data)
+		assert.Equal(t, tc.err, err, "% x", tc.data)
+		assert.Equal(t, tc.tag, tag, "% x", tc.data)
+		assert.Equal(t, tc.headerLen, headerLen, "% x", tc.data)
+		assert.Equal(t, tc.length, length, "% x", tc.data)
+	}
+}
+
+func TestIntValue(t *testing.T) {
+	d := &decoder{}
+	assert.Equal(t, int64(-1), d.intValue(element{data: []byte{0xff}}))
+	assert.Equal(t, int64(128), d.intValue(element{data: []byte{0x00, 0x80}}))
+	assert.Equal(t, int64(-129), d.intValue(element{data: []byte{0xff, 0x7f}}))
+	require.NoError(t, d.err)
+	d.intValue(element{})
+	assert.Equal(t, errInvalidLength, d.err)
+}
@@ -0,0 +1,36 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+// Code generated by beats/dev-tools/cmd/asset/asset.go - DO NOT EDIT.
+
+package ldap
+
+import (
+	"github.com/elastic/beats/v7/libbeat/asset"
+)
+
+func init() {
+	if err := asset.SetFields("packetbeat", "ldap", asset.ModuleFieldsPri, AssetLdap); err != nil {
+		panic(err)
+	}
+}
+
+// AssetLdap returns asset data.
+// This is the base64 encoded zlib format compressed contents of protos/ldap.
+func AssetLdap() string {
+	return "eJy0l01v4zYTx+/5FIMcHiSAI6yfzaaAgRQIku52gTQb1L0rY3JssUuRKmdkx/30BfViyyvF3YVc5BCJIv/zmxdy6Cv4StsZWI3FGYAYsTSD88eHu+fzMwBNrIIpxHg3g5/PAADipysuSJmlUUBrcgJLQ1ZzcgbN06yaeQUOc9ppxyHZFjSDVfBlO9Jd0F2UEzOuKDV696ldbr1bdQYHGNu/PzJqheDzA/glSEYQ6K+SWCZQMmkQDzmKysAIQyAuvGPi5KxH5AsKGCPRMVEDfaXtxgf9/Uw7qW+Qks5MesW8iMlgwqCyPo8+AYg2LMatSsMZ6crPLg5gUVhDDOJn1Xg1oUY+kFoYpydxFBbIBH7xJylpfav5J+BDNYOchC1IhgKGD1Ryr83SkJ4Aah3/abIk8SFQtKyjhPJ5gYH0YKiUu0WdGzfR6rYZjI/K5/3wrSnwUDJ/rLqK4MUrb1u51ukYkX1We8axlCyN9sanMEqRE6PqglKZN4qGMCbwwiaW1Av4cCDzwsj2JYFnZI7GGTAQOFpTgECFDxLj3XMirkpzUhk6w/l4T+Z380fY6Q1HciDpn+bzq/nz0y+fvgwgKn+KGFcyLVBdz0eRNpm3NC8XEoj6UJoCLVO0Bpl4HNyvfgONUJWzSpoCOUUaFttB3h4Pm78ptSY3MnIz5Phq8jIHV+YLCjFecbPHAySQlMH9AJOY/DRMUQgqITAOmJR3mt9KZQ9jaaxQGJejSFHrDJudRLBqWIJxq7jjAjE5+bbZQFz/+8d7uP4w/TBYdxf/u6jP3nuLzLclU7i84Lvf7pTypZMnzOkWrVF0edn3FUWCWZQytiajv3ut1ss699j4XjeLziy/BHT1uX8gFgtou+sduwUxEtj2giP5q1tKfTSewK14NK0a2lp72xqfADLIQW9Hp3udcu8BU4EBd3FR3sYlHIvh8eHzx8H8ngcqLCqadUHP+2472qThFPcDRxsIZFHMevCy0A3Ew9ORREQiLgsKxp9gO0WsmHkndYF8P0fzJY1v4zm+1JdKdECvQk7T8U41Td4nN8k0uU6myfT65ib5/7t373/qQ+ICnfbuP7z7tiaa6nPt+x6/BxWISyup8ppGUtVKEJX2WPW9+227p0lY5wJ7HGMge8at0Rp9H0jH+xZa7sNWvyRIp6fYe41WrOceXs+wNrhynsWotEn6eIC95q6Q/h2kPt/TpvGPrJTjF4m2mbxNsbsMnQ5EeSfGlVVHgb3+G2D/DAAI32el"
+}
@@ -0,0 +1,150 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package ldap
+
+import (
+	"errors"
+	"strings"
+	"unicode/utf8"
+)
+
+// Choices of the Filter type of search requests.
+const (
+	filterAnd             = 0
+	filterOr              = 1
+	filterNot             = 2
+	filterEqualityMatch   = 3
+	filterSubstrings      = 4
+	filterGreaterOrEqual  = 5
+	filterLessOrEqual     = 6
+	filterPresent         = 7
+	filterApproxMatch     = 8
+	filterExtensibleMatch = 9
+)
+
+// maxFilterDepth is the maximum nesting of filters that is decoded.
+const maxFilterDepth = 32
+
+var errFilterTooDeep = errors.New("filter nested too deeply")
+
+// formatFilter returns the string representation of a search filter, as
+// defined in RFC 4515.
+func formatFilter(e element) (string, error) {
+	var sb strings.Builder
+	if err := writeFilter(&sb, e, 0); err != nil {
+		return "", err
+	}
+	return sb.String(), nil
+}
+
+func writeFilter(sb *strings.Builder, e element, depth int) error {
+	if depth > maxFilterDepth {
+		return errFilterTooDeep
+	}
+	if e.class != classContext {
+		return errUnexpectedElement
+	}
+	d := &decoder{buf: e.data}
+	sb.WriteByte('(')
+	switch e.tag {
+	case filterAnd, filterOr, filterNot:
+		sb.WriteByte("&|!"[e.tag])
+		for d.more() {
+			if err := writeFilter(sb, d.next(), depth+1); err != nil {
+				return err
+			}
+		}
+	case filterEqualityMatch, filterGreaterOrEqual, filterLessOrEqual, filterApproxMatch:
+		sb.WriteString(d.octetString())
+		sb.WriteString(map[int]string{
+			filterEqualityMatch:  "=",
+			filterGreaterOrEqual: ">=",
+			filterLessOrEqual:    "<=",
+			filterApproxMatch:    "~=",
+		}[e.tag])
+		writeValue(sb, d.octetString())
+	case filterSubstrings:
+		sb.WriteString(d.octetString())
+		sb.WriteByte('=')
+		subs := d.sequence()
+		for i := 0; subs.more(); i++ {
+			sub := subs.next()
+			if i == 0 && sub.tag != 0 {
+				// No initial substring.
+				sb.WriteByte('*')
+			}
+			writeValue(sb, string(sub.data))
+			if sub.tag != 2 {
+				// Not the final substring.
+				sb.WriteByte('*')
+			}
+		}
+		d.done(subs)
+	case filterPresent:
+		sb.WriteString(string(e.data))
+		sb.WriteString("=*")
+	case filterExtensibleMatch:
+		var rule, value string
+		for d.more() {
+			sub := d.next()
+			switch sub.tag {
+			case 1:
+				rule = string(sub.data)
+			case 2:
+				sb.WriteString(string(sub.data))
+			case 3:
+				value = string(sub.data)
+			case 4:
+				if len(sub.data) > 0 && sub.data[0] != 0 {
+					sb.WriteString(":dn")
+				}
+			}
+		}
+		if rule != "" {
+			sb.WriteByte(':')
+			sb.WriteString(rule)
+		}
+		sb.WriteString(":=")
+		writeValue(sb, value)
+	default:
+		return errUnexpectedElement
+	}
+	sb.WriteByte(')')
+	return d.err
+}
+
+// writeValue writes an assertion value, escaping the characters that can't
+// appear in filters and the bytes that aren't printable UTF-8, like those of
+// binary attributes.
+func writeValue(sb *strings.Builder, v string) {
+	const hex = "0123456789abcdef"
+	for i := 0; i < len(v); {
+		r, size := utf8.DecodeRuneInString(v[i:])
+		switch {
+		case r == '*' || r == '(' || r == ')' || r == '\\' || r < 0x20 || r == 0x7f || r == utf8.RuneError:
+			for _, c := range []byte(v[i : i+size]) {
+				sb.WriteByte('\\')
+				sb.WriteByte(hex[c>>4])
+				sb.WriteByte(hex[c&0x0f])
+			}
+		default:
+			sb.WriteString(v[i : i+size])
+		}
+		i += size
+	}
+}
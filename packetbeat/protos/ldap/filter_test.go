@@ -0,0 +1,83 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+//go:build !integration
+
+package ldap
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFormatFilter(t *testing.T) {
+	eq := func(attr, value string) []byte {
+		return ctx(filterEqualityMatch, true, str(attr), str(value))
+	}
+	for _, tc := range []struct {
+		filter   []byte
+		expected string
+	}{
+		{ctx(filterPresent, false, []byte("objectClass")), "(objectClass=*)"},
+		{eq("cn", "Babs Jensen"), "(cn=Babs Jensen)"},
+		{ctx(filterNot, true, eq("cn", "Tim Howes")), "(!(cn=Tim Howes))"},
+		{
+			ctx(filterAnd, true,
+				eq("objectClass", "Person"),
+				ctx(filterOr, true, eq("sn", "Jensen"), ctx(filterSubstrings, true, str("cn"), seq(ctx(0, false, []byte("Babs J"))))),
+			),
+			"(&(objectClass=Person)(|(sn=Jensen)(cn=Babs J*)))",
+		},
+		{
+			ctx(filterSubstrings, true, str("o"), seq(ctx(0, false, []byte("univ")), ctx(1, false, []byte("of")), ctx(2, false, []byte("mich")))),
+			"(o=univ*of*mich)",
+		},
+		{ctx(filterSubstrings, true, str("cn"), seq(ctx(1, false, []byte("x")))), "(cn=*x*)"},
+		{ctx(filterSubstrings, true, str("cn"), seq(ctx(2, false, []byte("son")))), "(cn=*son)"},
+		{ctx(filterGreaterOrEqual, true, str("uSNChanged"), str("4096")), "(uSNChanged>=4096)"},
+		{ctx(filterLessOrEqual, true, str("pwdLastSet"), str("0")), "(pwdLastSet<=0)"},
+		{ctx(filterApproxMatch, true, str("sn"), str("Jensen")), "(sn~=Jensen)"},
+		{
+			ctx(filterExtensibleMatch, true, ctx(1, false, []byte("1.2.840.113556.1.4.803")), ctx(2, false, []byte("userAccountControl")), ctx(3, false, []byte("2"))),
+			"(userAccountControl:1.2.840.113556.1.4.803:=2)",
+		},
+		{
+			ctx(filterExtensibleMatch, true, ctx(2, false, []byte("o")), ctx(3, false, []byte("Ace Industry")), ctx(4, false, []byte{0xff})),
+			"(o:dn:=Ace Industry)",
+		},
+		// Escaped characters, binary values and UTF-8.
+		{eq("cn", "Parens R Us (for all your parenthetical needs)"), `(cn=Parens R Us \28for all your parenthetical needs\29)`},
+		{eq("filename", `C:\MyFile`), `(filename=C:\5cMyFile)`},
+		{eq("objectGUID", "\x04\x02\x48\x00\xff"), `(objectGUID=\04\02H\00\ff)`},
+		{eq("sn", "Lučić"), "(sn=Lučić)"},
+	} {
+		d := &decoder{buf: tc.filter}
+		s, err := formatFilter(d.next())
+		require.NoError(t, err)
+		assert.Equal(t, tc.expected, s)
+	}
+
+	deep := ctx(filterPresent, false, []byte("cn"))
+	for i := 0; i <= maxFilterDepth; i++ {
+		deep = ctx(filterNot, true, deep)
+	}
+	d := &decoder{buf: deep}
+	_, err := formatFilter(d.next())
+	assert.Equal(t, errFilterTooDeep, err)
+}
@@ -0,0 +1,323 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package ldap
+
+import (
+	"slices"
+	"time"
+
+	"github.com/elastic/beats/v7/libbeat/beat"
+	"github.com/elastic/beats/v7/libbeat/common"
+	conf "github.com/elastic/elastic-agent-libs/config"
+	"github.com/elastic/elastic-agent-libs/logp"
+	"github.com/elastic/elastic-agent-libs/mapstr"
+	"github.com/elastic/elastic-agent-libs/monitoring"
+
+	"github.com/elastic/beats/v7/packetbeat/pb"
+	"github.com/elastic/beats/v7/packetbeat/procs"
+	"github.com/elastic/beats/v7/packetbeat/protos"
+)
+
+type connectionData struct {
+	streams [2]*stream
+	// pending are the requests waiting for their response, in the order
+	// they were sent.
+	pending []*transaction
+	// encrypted is set once StartTLS succeeded, the rest of the connection
+	// can't be decoded.
+	encrypted bool
+}
+
+// transaction is a request and its responses.
+type transaction struct {
+	transport string
+	requ      *message
+	resp      *message
+
+	// entries and references count the results of a search.
+	entries    int
+	references int
+
+	// respBytes is the size of all the responses.
+	respBytes int
+}
+
+// LDAP protocol plugin
+type ldapPlugin struct {
+	// config
+	ports              []int
+	maxPendingRequests int
+	transactionTimeout time.Duration
+
+	// udpConnections holds the pending requests of the UDP (CLDAP) clients,
+	// by IP and port tuple of their requests.
+	udpConnections *common.Cache
+
+	watcher *procs.ProcessesWatcher
+	results protos.Reporter
+}
+
+var (
+	debugf  = logp.MakeDebug("ldap")
+	isDebug = false
+)
+
+var (
+	unmatchedResponses = monitoring.NewInt(nil, "ldap.unmatched_responses")
+	unmatchedRequests  = monitoring.NewInt(nil, "ldap.unmatched_requests")
+)
+
+func init() {
+	protos.Register("ldap", New)
+}
+
+func New(
+	testMode bool,
+	results protos.Reporter,
+	watcher *procs.ProcessesWatcher,
+	cfg *conf.C,
+) (protos.Plugin, error) {
+	p := &ldapPlugin{}
+	config := defaultConfig
+	if !testMode {
+		if err := cfg.Unpack(&config); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := p.init(results, watcher, &config); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+func (lp *ldapPlugin) init(results protos.Reporter, watcher *procs.ProcessesWatcher, config *ldapConfig) error {
+	lp.setFromConfig(config)
+
+	lp.udpConnections = common.NewCacheWithRemovalListener(
+		lp.transactionTimeout,
+		protos.DefaultTransactionHashSize,
+		func(k common.Key, v common.Value) {
+			conn, ok := v.(*connectionData)
+			if !ok {
+				logp.Err("Expired value is not a *ldap.connectionData.")
+				return
+			}
+			lp.expireRequests(conn)
+		})
+	lp.udpConnections.StartJanitor(lp.transactionTimeout)
+
+	lp.results = results
+	lp.watcher = watcher
+	isDebug = logp.IsDebug("ldap")
+
+	return nil
+}
+
+func (lp *ldapPlugin) setFromConfig(config *ldapConfig) {
+	lp.ports = config.Ports
+	lp.maxPendingRequests = config.MaxPendingRequests
+	lp.transactionTimeout = config.TransactionTimeout
+}
+
+func (lp *ldapPlugin) GetPorts() []int {
+	return lp.ports
+}
+
+func (lp *ldapPlugin) ConnectionTimeout() time.Duration {
+	return lp.transactionTimeout
+}
+
+// isServerPort returns whether a port is one of the configured LDAP ports.
+func (lp *ldapPlugin) isServerPort(port uint16) bool {
+	return slices.Contains(lp.ports, int(port))
+}
+
+func (lp *ldapPlugin) handleMessage(conn *connectionData, m *message, transport string) {
+	if isRequest(m.op) {
+		t := &transaction{transport: transport, requ: m}
+		if !hasResponse(m.op) {
+			lp.publishTransaction(t)
+			return
+		}
+		lp.startTransaction(conn, t)
+		return
+	}
+
+	i := slices.IndexFunc(conn.pending, func(t *transaction) bool {
+		return t.requ.id == m.id
+	})
+	if i < 0 {
+		unmatchedResponses.Add(1)
+		if isDebug {
+			debugf("response with message ID %d has no matching request", m.id)
+		}
+		return
+	}
+	t := conn.pending[i]
+	t.respBytes += m.size
+	switch m.op {
+	case opSearchResultEntry:
+		t.entries++
+		return
+	case opSearchResultReference:
+		t.references++
+		return
+	case opIntermediateResponse:
+		return
+	}
+	conn.pending = slices.Delete(conn.pending, i, i+1)
+	t.resp = m
+	if m.op == opExtendedResponse && t.requ.requestName == oidStartTLS && m.resultCode == resultSuccess {
+		conn.encrypted = true
+	}
+	lp.publishTransaction(t)
+}
+
+// startTransaction adds a request to the pending requests. A pending request
+// with the same message ID is published without response.
+func (lp *ldapPlugin) startTransaction(conn *connectionData, t *transaction) {
+	i := slices.IndexFunc(conn.pending, func(prev *transaction) bool {
+		return prev.requ.id == t.requ.id
+	})
+	if i >= 0 {
+		unmatchedRequests.Add(1)
+		lp.publishTransaction(conn.pending[i])
+		conn.pending = slices.Delete(conn.pending, i, i+1)
+	}
+	if len(conn.pending) >= lp.maxPendingRequests {
+		unmatchedRequests.Add(1)
+		lp.publishTransaction(conn.pending[0])
+		conn.pending = conn.pending[1:]
+	}
+	conn.pending = append(conn.pending, t)
+}
+
+// expireRequests publishes the requests still waiting for a response.
+func (lp *ldapPlugin) expireRequests(conn *connectionData) {
+	for _, t := range conn.pending {
+		unmatchedRequests.Add(1)
+		lp.publishTransaction(t)
+	}
+	conn.pending = nil
+}
+
+func (lp *ldapPlugin) publishTransaction(t *transaction) {
+	if lp.results == nil {
+		return
+	}
+	lp.results(newEvent(t))
+}
+
+func newEvent(t *transaction) beat.Event {
+	requ := t.requ
+	src, dst := common.MakeEndpointPair(requ.tuple.BaseTuple, requ.cmdlineTuple)
+
+	evt, pbf := pb.NewBeatEvent(requ.ts)
+	pbf.SetSource(&src)
+	pbf.SetDestination(&dst)
+	pbf.AddIP(src.IP)
+	pbf.AddIP(dst.IP)
+	pbf.Source.Bytes = int64(requ.size)
+	pbf.Event.Dataset = "ldap"
+	pbf.Event.Start = requ.ts
+	pbf.Network.Transport = t.transport
+	pbf.Network.Protocol = pbf.Event.Dataset
+
+	operation := operations[requ.op]
+	ldap := mapstr.M{
+		"message_id": requ.id,
+		"operation":  operation,
+	}
+	putString := func(key, value string) {
+		if value != "" {
+			ldap[key] = value
+		}
+	}
+	putString("dn", requ.dn)
+	switch requ.op {
+	case opBindRequest:
+		ldap["version"] = requ.version
+		putString("auth_type", requ.authType)
+		putString("sasl_mechanism", requ.saslMechanism)
+	case opSearchRequest:
+		ldap["scope"] = requ.scope
+		ldap["deref_aliases"] = requ.derefAliases
+		ldap["size_limit"] = requ.sizeLimit
+		ldap["time_limit"] = requ.timeLimit
+		putString("filter", requ.filter)
+	case opModifyDNRequest:
+		putString("new_rdn", requ.newRDN)
+		putString("new_superior", requ.newSuperior)
+	case opExtendedRequest:
+		putString("request_name", requ.requestName)
+	case opAbandonRequest:
+		ldap["abandon_id"] = requ.abandonID
+	}
+	if len(requ.attributes) > 0 {
+		ldap["attributes"] = requ.attributes
+	}
+	if len(requ.modifications) > 0 {
+		ldap["modifications"] = requ.modifications
+	}
+
+	status := common.OK_STATUS
+	var notes []string
+	switch {
+	case t.resp != nil:
+		resp := t.resp
+		pbf.Destination.Bytes = int64(t.respBytes)
+		pbf.Event.End = resp.ts
+		ldap["result_code"] = resp.resultCode
+		ldap["result_name"] = resultName(resp.resultCode)
+		putString("matched_dn", resp.matchedDN)
+		putString("diagnostic_message", resp.diagnosticMessage)
+		if requ.op == opSearchRequest {
+			ldap["search_entries"] = t.entries
+			ldap["search_references"] = t.references
+		}
+		if isFailure(resp.resultCode) {
+			status = common.ERROR_STATUS
+			notes = append(notes, resultName(resp.resultCode))
+			if resp.diagnosticMessage != "" {
+				notes = append(notes, resp.diagnosticMessage)
+			}
+		}
+	case hasResponse(requ.op):
+		status = common.ERROR_STATUS
+		notes = append(notes, "Unmatched request")
+	}
+
+	fields := evt.Fields
+	fields["type"] = pbf.Event.Dataset
+	fields["status"] = status
+	fields["method"] = operation
+	fields["ldap"] = ldap
+	if requ.dn != "" {
+		fields["resource"] = requ.dn
+	} else if requ.op == opExtendedRequest {
+		fields["resource"] = requ.requestName
+	}
+
+	if status == common.ERROR_STATUS {
+		pbf.Event.Outcome = "failure"
+	}
+	pbf.Error.Message = notes
+
+	return evt
+}
@@ -0,0 +1,205 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package ldap
+
+import (
+	"errors"
+
+	"github.com/elastic/beats/v7/libbeat/common"
+	"github.com/elastic/elastic-agent-libs/logp"
+
+	"github.com/elastic/beats/v7/packetbeat/protos"
+	"github.com/elastic/beats/v7/packetbeat/protos/applayer"
+	"github.com/elastic/beats/v7/packetbeat/protos/tcp"
+)
+
+// maxHeaderSize is the size of the data buffered to parse the header of a
+// message too large to be buffered.
+const maxHeaderSize = 64 * 1024
+
+type stream struct {
+	applayer.Stream
+	// skip is the number of bytes left of a message too large to be
+	// buffered.
+	skip int
+}
+
+func (lp *ldapPlugin) Parse(
+	pkt *protos.Packet,
+	tcptuple *common.TCPTuple,
+	dir uint8,
+	private protos.ProtocolData,
+) protos.ProtocolData {
+	conn := ensureConnection(private)
+	conn = lp.doParse(conn, pkt, tcptuple, dir)
+	if conn == nil {
+		return nil
+	}
+	return conn
+}
+
+func ensureConnection(private protos.ProtocolData) *connectionData {
+	if private == nil {
+		return &connectionData{}
+	}
+
+	priv, ok := private.(*connectionData)
+	if !ok {
+		logp.Warn("ldap connection data type error, create new one")
+		return &connectionData{}
+	}
+	if priv == nil {
+		logp.Warn("Unexpected: ldap connection data not set, create new one")
+		return &connectionData{}
+	}
+
+	return priv
+}
+
+func (lp *ldapPlugin) doParse(
+	conn *connectionData,
+	pkt *protos.Packet,
+	tcptuple *common.TCPTuple,
+	dir uint8,
+) *connectionData {
+	if conn.encrypted {
+		return conn
+	}
+
+	st := conn.streams[dir]
+	if st == nil {
+		st = &stream{}
+		st.Stream.Init(tcp.TCPMaxDataInStream)
+		conn.streams[dir] = st
+		if isDebug {
+			debugf("new stream: %p (dir=%v, len=%v)", st, dir, len(pkt.Payload))
+		}
+	}
+
+	payload := pkt.Payload
+	if st.skip > 0 {
+		n := min(st.skip, len(payload))
+		st.skip -= n
+		payload = payload[n:]
+	}
+	if err := st.Append(payload); err != nil {
+		if isDebug {
+			debugf("%v, dropping TCP stream: ", err)
+		}
+		return nil
+	}
+
+	tuple, cmdlineTuple := tcptuple.IPPort(), lp.watcher.FindProcessesTupleTCP(tcptuple.IPPort())
+	if dir == tcp.TCPDirectionReverse {
+		reversed := common.NewIPPortTuple(tuple.IPLength, tuple.DstIP, tuple.DstPort, tuple.SrcIP, tuple.SrcPort)
+		tuple = &reversed
+		if cmdlineTuple != nil {
+			reversedCmdline := cmdlineTuple.Reverse()
+			cmdlineTuple = &reversedCmdline
+		}
+	}
+
+	for st.Buf.Len() > 0 && !conn.encrypted {
+		buf := st.Buf.Bytes()
+		class, _, tag, headerLen, length, err := readHeader(buf)
+		if errors.Is(err, errTruncated) {
+			// wait for more data
+			return conn
+		}
+		if err == nil && (class != classUniversal || tag != tagSequence) {
+			err = errUnexpectedElement
+		}
+		if err != nil {
+			if isDebug {
+				debugf("%v, dropping LDAP connection", err)
+			}
+			return nil
+		}
+
+		size := headerLen + length
+		var m *message
+		switch {
+		case st.Buf.Avail(size):
+			var data []byte
+			data, err = st.Buf.Collect(size)
+			if err == nil {
+				m, err = parseMessage(data[headerLen:], true)
+			}
+		case size > st.MaxDataInStream:
+			// Parse the header of the message and skip its body.
+			if len(buf) < maxHeaderSize {
+				// wait for more data
+				return conn
+			}
+			m, err = parseMessage(buf[headerLen:], false)
+			st.skip = size - len(buf)
+			_ = st.Buf.Advance(len(buf))
+		default:
+			// wait for more data
+			return conn
+		}
+		if err != nil {
+			if isDebug {
+				debugf("%v, dropping LDAP connection", err)
+			}
+			return nil
+		}
+		m.ts = pkt.Ts
+		m.tuple = *tuple
+		m.cmdlineTuple = cmdlineTuple
+		m.size = size
+		lp.handleMessage(conn, m, "tcp")
+		st.Reset()
+	}
+
+	return conn
+}
+
+func (lp *ldapPlugin) GapInStream(tcptuple *common.TCPTuple, dir uint8,
+	nbytes int, private protos.ProtocolData) (priv protos.ProtocolData, drop bool,
+) {
+	conn, ok := private.(*connectionData)
+	if !ok || conn == nil {
+		return private, true
+	}
+	// Gaps in the body of a message that is skipped don't break the framing.
+	if st := conn.streams[dir]; st != nil && st.skip >= nbytes {
+		st.skip -= nbytes
+		return private, false
+	}
+	return private, true
+}
+
+func (lp *ldapPlugin) ReceivedFin(tcptuple *common.TCPTuple, dir uint8,
+	private protos.ProtocolData,
+) protos.ProtocolData {
+	return private
+}
+
+// Expired publishes the requests still waiting for a response when the
+// connection expires.
+func (lp *ldapPlugin) Expired(tuple *common.TCPTuple, private protos.ProtocolData) {
+	conn, ok := private.(*connectionData)
+	if !ok || conn == nil {
+		return
+	}
+	if isDebug {
+		debugf("expired connection %s", tuple)
+	}
+	lp.expireRequests(conn)
+}
@@ -0,0 +1,392 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+//go:build !integration
+
+package ldap
+
+import (
+	"encoding/binary"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/elastic/beats/v7/libbeat/beat"
+	"github.com/elastic/beats/v7/libbeat/common"
+	"github.com/elastic/beats/v7/packetbeat/procs"
+	"github.com/elastic/beats/v7/packetbeat/protos"
+	"github.com/elastic/beats/v7/packetbeat/protos/tcp"
+	"github.com/elastic/beats/v7/packetbeat/publish"
+	conf "github.com/elastic/elastic-agent-libs/config"
+	"github.com/elastic/elastic-agent-libs/mapstr"
+)
+
+type eventStore struct {
+	events []beat.Event
+}
+
+func (e *eventStore) publish(event beat.Event) {
+	publish.MarshalPacketbeatFields(&event, nil, nil)
+	e.events = append(e.events, event)
+}
+
+func ldapModForTests(t *testing.T, store *eventStore) *ldapPlugin {
+	t.Helper()
+	p, err := New(false, store.publish, &procs.ProcessesWatcher{}, conf.MustNewConfigFrom(map[string]interface{}{
+		"ports": []int{389},
+	}))
+	require.NoError(t, err)
+	lp := p.(*ldapPlugin)
+	t.Cleanup(lp.udpConnections.StopJanitor)
+	return lp
+}
+
+func testTCPTuple() *common.TCPTuple {
+	t := &common.TCPTuple{
+		IPLength: 4,
+		BaseTuple: common.BaseTuple{
+			SrcIP: net.IPv4(192, 168, 0, 1), DstIP: net.IPv4(192, 168, 0, 2),
+			SrcPort: 6512, DstPort: 389,
+		},
+	}
+	t.ComputeHashables()
+	return t
+}
+
+// Helpers to encode BER elements.
+
+func tlv(class byte, constructed bool, tag int, parts ...[]byte) []byte {
+	var content []byte
+	for _, part := range parts {
+		content = append(content, part...)
+	}
+	id := class<<6 | byte(tag)
+	if constructed {
+		id |= 0x20
+	}
+	b := []byte{id}
+	if len(content) < 0x80 {
+		b = append(b, byte(len(content)))
+	} else {
+		// Four bytes lengths, as sent by Active Directory.
+		b = append(b, 0x84)
+		b = binary.BigEndian.AppendUint32(b, uint32(len(content)))
+	}
+	return append(b, content...)
+}
+
+func seq(parts ...[]byte) []byte {
+	return tlv(classUniversal, true, tagSequence, parts...)
+}
+
+func str(s string) []byte {
+	return tlv(classUniversal, false, tagOctetString, []byte(s))
+}
+
+// integer encodes small integers, up to 127.
+func integer(v int) []byte {
+	return tlv(classUniversal, false, tagInteger, []byte{byte(v)})
+}
+
+func enum(v int) []byte {
+	return tlv(classUniversal, false, tagEnumerated, []byte{byte(v)})
+}
+
+func boolean(v bool) []byte {
+	if v {
+		return tlv(classUniversal, false, tagBoolean, []byte{0xff})
+	}
+	return tlv(classUniversal, false, tagBoolean, []byte{0})
+}
+
+func ctx(tag int, constructed bool, parts ...[]byte) []byte {
+	return tlv(classContext, constructed, tag, parts...)
+}
+
+func ldapMessage(id int, op int, constructed bool, parts ...[]byte) []byte {
+	return seq(integer(id), tlv(classApplication, constructed, op, parts...))
+}
+
+func result(id int, op int, code int, diagnostic string, extra ...[]byte) []byte {
+	return ldapMessage(id, op, true, append([][]byte{enum(code), str(""), str(diagnostic)}, extra...)...)
+}
+
+func searchRequest(id int, base string, filter []byte, attrs ...string) []byte {
+	var attrList [][]byte
+	for _, attr := range attrs {
+		attrList = append(attrList, str(attr))
+	}
+	return ldapMessage(id, opSearchRequest, true,
+		str(base), enum(2), enum(0), integer(0), integer(0), boolean(false),
+		filter, seq(attrList...))
+}
+
+type testPacket struct {
+	dir  uint8
+	data []byte
+}
+
+func client(data []byte) testPacket {
+	return testPacket{dir: tcp.TCPDirectionOriginal, data: data}
+}
+
+func server(data []byte) testPacket {
+	return testPacket{dir: tcp.TCPDirectionReverse, data: data}
+}
+
+func parsePackets(ldap *ldapPlugin, packets ...testPacket) protos.ProtocolData {
+	tuple := testTCPTuple()
+	var private protos.ProtocolData
+	ts := time.Now()
+	for _, p := range packets {
+		ts = ts.Add(time.Millisecond)
+		private = ldap.Parse(&protos.Packet{Ts: ts, Payload: p.data}, tuple, p.dir, private)
+	}
+	return private
+}
+
+func ldapFields(t *testing.T, event beat.Event) mapstr.M {
+	t.Helper()
+	v, err := event.GetValue("ldap")
+	require.NoError(t, err)
+	return v.(mapstr.M)
+}
+
+func TestBind(t *testing.T) {
+	store := &eventStore{}
+	ldap := ldapModForTests(t, store)
+
+	requ := ldapMessage(1, opBindRequest, true, integer(3), str("cn=admin,dc=example,dc=com"), ctx(0, false, []byte("secret")))
+	resp := result(1, opBindResponse, 0, "")
+	parsePackets(ldap, client(requ), server(resp))
+
+	require.Len(t, store.events, 1)
+	event := store.events[0]
+	assert.Equal(t, mapstr.M{
+		"message_id":  int64(1),
+		"operation":   "bind",
+		"dn":          "cn=admin,dc=example,dc=com",
+		"version":     int64(3),
+		"auth_type":   "simple",
+		"result_code": int64(0),
+		"result_name": "success",
+	}, ldapFields(t, event))
+	assert.Equal(t, "OK", event.Fields["status"])
+	assert.Equal(t, "bind", event.Fields["method"])
+	assert.Equal(t, "cn=admin,dc=example,dc=com", event.Fields["resource"])
+	transport, _ := event.GetValue("network.transport")
+	assert.Equal(t, "tcp", transport)
+	bytes, _ := event.GetValue("source.bytes")
+	assert.EqualValues(t, len(requ), bytes)
+	bytes, _ = event.GetValue("destination.bytes")
+	assert.EqualValues(t, len(resp), bytes)
+	port, _ := event.GetValue("destination.port")
+	assert.EqualValues(t, 389, port)
+}
+
+func TestBindFailure(t *testing.T) {
+	store := &eventStore{}
+	ldap := ldapModForTests(t, store)
+
+	parsePackets(ldap,
+		client(ldapMessage(1, opBindRequest, true, integer(3), str(""), ctx(3, true, str("GSS-SPNEGO"), str("token")))),
+		server(result(1, opBindResponse, 49, "80090308: LdapErr: DSID-0C09050F")),
+	)
+
+	require.Len(t, store.events, 1)
+	event := store.events[0]
+	fields := ldapFields(t, event)
+	assert.Equal(t, "sasl", fields["auth_type"])
+	assert.Equal(t, "GSS-SPNEGO", fields["sasl_mechanism"])
+	assert.Equal(t, "invalidCredentials", fields["result_name"])
+	assert.Equal(t, "Error", event.Fields["status"])
+	msg, _ := event.GetValue("error.message")
+	assert.Equal(t, []string{"invalidCredentials", "80090308: LdapErr: DSID-0C09050F"}, msg)
+}
+
+func TestSearch(t *testing.T) {
+	store := &eventStore{}
+	ldap := ldapModForTests(t, store)
+
+	filter := ctx(filterAnd, true,
+		ctx(filterEqualityMatch, true, str("objectClass"), str("user")),
+		ctx(filterSubstrings, true, str("cn"), seq(ctx(0, false, []byte("al")))),
+	)
+	entry := ldapMessage(2, opSearchResultEntry, true, str("cn=alice,dc=example,dc=com"), seq())
+	ref := ldapMessage(2, opSearchResultReference, true, str("ldap://other/dc=example,dc=com"))
+	done := result(2, opSearchResultDone, 0, "")
+	parsePackets(ldap,
+		client(searchRequest(2, "dc=example,dc=com", filter, "cn", "mail")),
+		server(append(append(entry, entry...), ref...)),
+		server(done),
+	)
+
+	require.Len(t, store.events, 1)
+	event := store.events[0]
+	assert.Equal(t, mapstr.M{
+		"message_id":        int64(2),
+		"operation":         "search",
+		"dn":                "dc=example,dc=com",
+		"scope":             "wholeSubtree",
+		"deref_aliases":     "neverDerefAliases",
+		"size_limit":        int64(0),
+		"time_limit":        int64(0),
+		"filter":            "(&(objectClass=user)(cn=al*))",
+		"attributes":        []string{"cn", "mail"},
+		"result_code":       int64(0),
+		"result_name":       "success",
+		"search_entries":    2,
+		"search_references": 1,
+	}, ldapFields(t, event))
+	bytes, _ := event.GetValue("destination.bytes")
+	assert.EqualValues(t, 2*len(entry)+len(ref)+len(done), bytes)
+}
+
+func TestUpdates(t *testing.T) {
+	store := &eventStore{}
+	ldap := ldapModForTests(t, store)
+
+	parsePackets(ldap,
+		client(ldapMessage(3, opModifyRequest, true, str("cn=alice,dc=example,dc=com"), seq(
+			seq(enum(2), seq(str("description"), tlv(classUniversal, true, 17, str("admin")))),
+			seq(enum(0), seq(str("member"), tlv(classUniversal, true, 17, str("cn=bob")))),
+		))),
+		client(ldapMessage(4, opAddRequest, true, str("cn=bob,dc=example,dc=com"), seq(
+			seq(str("objectClass"), tlv(classUniversal, true, 17, str("person"))),
+			seq(str("sn"), tlv(classUniversal, true, 17, str("Bob"))),
+		))),
+		client(ldapMessage(5, opDelRequest, false, []byte("cn=carol,dc=example,dc=com"))),
+		client(ldapMessage(6, opModifyDNRequest, true, str("cn=bob,dc=example,dc=com"), str("cn=robert"), boolean(true), ctx(0, false, []byte("ou=people,dc=example,dc=com")))),
+		server(result(4, opAddResponse, 68, "")),
+		server(result(3, opModifyResponse, 50, "")),
+		server(result(5, opDelResponse, 0, "")),
+		server(result(6, opModifyDNResponse, 0, "")),
+	)
+
+	require.Len(t, store.events, 4)
+	add := ldapFields(t, store.events[0])
+	assert.Equal(t, "add", add["operation"])
+	assert.Equal(t, []string{"objectClass", "sn"}, add["attributes"])
+	assert.Equal(t, "entryAlreadyExists", add["result_name"])
+
+	modify := ldapFields(t, store.events[1])
+	assert.Equal(t, []string{"replace: description", "add: member"}, modify["modifications"])
+	assert.Equal(t, "insufficientAccessRights", modify["result_name"])
+	assert.Equal(t, "Error", store.events[1].Fields["status"])
+
+	assert.Equal(t, "cn=carol,dc=example,dc=com", ldapFields(t, store.events[2])["dn"])
+
+	modDN := ldapFields(t, store.events[3])
+	assert.Equal(t, "cn=robert", modDN["new_rdn"])
+	assert.Equal(t, "ou=people,dc=example,dc=com", modDN["new_superior"])
+}
+
+func TestRequestsWithoutResponse(t *testing.T) {
+	store := &eventStore{}
+	ldap := ldapModForTests(t, store)
+
+	parsePackets(ldap,
+		client(ldapMessage(7, opAbandonRequest, false, []byte{5})),
+		client(ldapMessage(8, opUnbindRequest, false)),
+	)
+
+	require.Len(t, store.events, 2)
+	assert.Equal(t, int64(5), ldapFields(t, store.events[0])["abandon_id"])
+	assert.Equal(t, "unbind", store.events[1].Fields["method"])
+	for _, event := range store.events {
+		assert.Equal(t, "OK", event.Fields["status"])
+	}
+}
+
+func TestStartTLS(t *testing.T) {
+	store := &eventStore{}
+	ldap := ldapModForTests(t, store)
+
+	private := parsePackets(ldap,
+		client(ldapMessage(1, opExtendedRequest, true, ctx(0, false, []byte(oidStartTLS)))),
+		server(result(1, opExtendedResponse, 0, "")),
+		client([]byte{0x16, 0x03, 0x01, 0x02, 0x00, 0x01}),
+	)
+
+	require.Len(t, store.events, 1)
+	assert.Equal(t, oidStartTLS, store.events[0].Fields["resource"])
+	assert.Equal(t, oidStartTLS, ldapFields(t, store.events[0])["request_name"])
+	require.NotNil(t, private)
+	assert.True(t, private.(*connectionData).encrypted)
+}
+
+func TestFragmentedMessages(t *testing.T) {
+	store := &eventStore{}
+	ldap := ldapModForTests(t, store)
+
+	filter := ctx(filterPresent, false, []byte("objectClass"))
+	data := append(searchRequest(1, string(make([]byte, 300)), filter), searchRequest(2, "", filter)...)
+	var packets []testPacket
+	for i := 0; i < len(data); i += 7 {
+		packets = append(packets, client(data[i:min(i+7, len(data))]))
+	}
+	packets = append(packets, server(result(2, opSearchResultDone, 0, "")))
+	private := parsePackets(ldap, packets...)
+
+	require.Len(t, store.events, 1)
+	assert.Equal(t, "(objectClass=*)", ldapFields(t, store.events[0])["filter"])
+
+	ldap.Expired(testTCPTuple(), private)
+	require.Len(t, store.events, 2)
+	assert.Equal(t, int64(1), ldapFields(t, store.events[1])["message_id"])
+	msg, _ := store.events[1].GetValue("error.message")
+	assert.Equal(t, "Unmatched request", msg)
+}
+
+func TestCLDAP(t *testing.T) {
+	store := &eventStore{}
+	ldap := ldapModForTests(t, store)
+
+	requTuple := common.NewIPPortTuple(4, net.IPv4(192, 168, 0, 1), 52000, net.IPv4(192, 168, 0, 2), 389)
+	respTuple := common.NewIPPortTuple(4, net.IPv4(192, 168, 0, 2), 389, net.IPv4(192, 168, 0, 1), 52000)
+	filter := ctx(filterAnd, true,
+		ctx(filterEqualityMatch, true, str("DnsDomain"), str("example.com")),
+		ctx(filterEqualityMatch, true, str("NtVer"), str("\x16\x00\x00\x00")),
+	)
+	ts := time.Now()
+	ldap.ParseUDP(&protos.Packet{Ts: ts, Tuple: requTuple, Payload: searchRequest(12, "", filter, "Netlogon")})
+	assert.Empty(t, store.events)
+	resp := append(ldapMessage(12, opSearchResultEntry, true, str(""), seq()), result(12, opSearchResultDone, 0, "")...)
+	ldap.ParseUDP(&protos.Packet{Ts: ts.Add(time.Millisecond), Tuple: respTuple, Payload: resp})
+
+	require.Len(t, store.events, 1)
+	event := store.events[0]
+	fields := ldapFields(t, event)
+	assert.Equal(t, `(&(DnsDomain=example.com)(NtVer=\16\00\00\00))`, fields["filter"])
+	assert.Equal(t, 1, fields["search_entries"])
+	transport, _ := event.GetValue("network.transport")
+	assert.Equal(t, "udp", transport)
+	port, _ := event.GetValue("source.port")
+	assert.EqualValues(t, 52000, port)
+}
+
+func TestInvalidData(t *testing.T) {
+	store := &eventStore{}
+	ldap := ldapModForTests(t, store)
+
+	private := parsePackets(ldap, client([]byte("GET / HTTP/1.1\r\n\r\n")))
+	assert.Nil(t, private)
+	assert.Empty(t, store.events)
+}
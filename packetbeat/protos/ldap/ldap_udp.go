@@ -0,0 +1,64 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package ldap
+
+import (
+	"github.com/elastic/beats/v7/packetbeat/protos"
+)
+
+// ParseUDP parses the messages of Connection-less LDAP (CLDAP), used by
+// Active Directory clients to locate domain controllers. A datagram can
+// contain several messages, like search results.
+func (lp *ldapPlugin) ParseUDP(pkt *protos.Packet) {
+	key := pkt.Tuple.Hashable()
+	if !lp.isServerPort(pkt.Tuple.DstPort) {
+		key = pkt.Tuple.RevHashable()
+	}
+	conn, _ := lp.udpConnections.Get(key).(*connectionData)
+	if conn == nil {
+		conn = &connectionData{}
+		lp.udpConnections.Put(key, conn)
+	}
+
+	cmdlineTuple := lp.watcher.FindProcessesTupleUDP(&pkt.Tuple)
+	for data := pkt.Payload; len(data) > 0; {
+		class, _, tag, headerLen, length, err := readHeader(data)
+		if err == nil && (class != classUniversal || tag != tagSequence) {
+			err = errUnexpectedElement
+		}
+		if err == nil && len(data) < headerLen+length {
+			err = errTruncated
+		}
+		var m *message
+		if err == nil {
+			m, err = parseMessage(data[headerLen:headerLen+length], true)
+		}
+		if err != nil {
+			if isDebug {
+				debugf("%v, dropping LDAP datagram from %s", err, &pkt.Tuple)
+			}
+			return
+		}
+		m.ts = pkt.Ts
+		m.tuple = pkt.Tuple
+		m.cmdlineTuple = cmdlineTuple
+		m.size = headerLen + length
+		lp.handleMessage(conn, m, "udp")
+		data = data[headerLen+length:]
+	}
+}
@@ -0,0 +1,332 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package ldap
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/elastic/beats/v7/libbeat/common"
+)
+
+// Protocol operations, the application tags of the protocolOp choice.
+const (
+	opBindRequest           = 0
+	opBindResponse          = 1
+	opUnbindRequest         = 2
+	opSearchRequest         = 3
+	opSearchResultEntry     = 4
+	opSearchResultDone      = 5
+	opModifyRequest         = 6
+	opModifyResponse        = 7
+	opAddRequest            = 8
+	opAddResponse           = 9
+	opDelRequest            = 10
+	opDelResponse           = 11
+	opModifyDNRequest       = 12
+	opModifyDNResponse      = 13
+	opCompareRequest        = 14
+	opCompareResponse       = 15
+	opAbandonRequest        = 16
+	opSearchResultReference = 19
+	opExtendedRequest       = 23
+	opExtendedResponse      = 24
+	opIntermediateResponse  = 25
+)
+
+// operations are the names of the operations started by the requests.
+var operations = map[int]string{
+	opBindRequest:     "bind",
+	opUnbindRequest:   "unbind",
+	opSearchRequest:   "search",
+	opModifyRequest:   "modify",
+	opAddRequest:      "add",
+	opDelRequest:      "delete",
+	opModifyDNRequest: "modifyDN",
+	opCompareRequest:  "compare",
+	opAbandonRequest:  "abandon",
+	opExtendedRequest: "extended",
+}
+
+func isRequest(op int) bool {
+	_, ok := operations[op]
+	return ok
+}
+
+// hasResponse returns whether the server responds to a request.
+func hasResponse(op int) bool {
+	return op != opUnbindRequest && op != opAbandonRequest
+}
+
+// oidStartTLS is the name of the StartTLS extended operation.
+const oidStartTLS = "1.3.6.1.4.1.1466.20037"
+
+// Result codes that don't report a failure.
+const (
+	resultSuccess            = 0
+	resultCompareFalse       = 5
+	resultCompareTrue        = 6
+	resultSaslBindInProgress = 14
+)
+
+var resultNames = map[int64]string{
+	0:   "success",
+	1:   "operationsError",
+	2:   "protocolError",
+	3:   "timeLimitExceeded",
+	4:   "sizeLimitExceeded",
+	5:   "compareFalse",
+	6:   "compareTrue",
+	7:   "authMethodNotSupported",
+	8:   "strongerAuthRequired",
+	10:  "referral",
+	11:  "adminLimitExceeded",
+	12:  "unavailableCriticalExtension",
+	13:  "confidentialityRequired",
+	14:  "saslBindInProgress",
+	16:  "noSuchAttribute",
+	17:  "undefinedAttributeType",
+	18:  "inappropriateMatching",
+	19:  "constraintViolation",
+	20:  "attributeOrValueExists",
+	21:  "invalidAttributeSyntax",
+	32:  "noSuchObject",
+	33:  "aliasProblem",
+	34:  "invalidDNSyntax",
+	36:  "aliasDereferencingProblem",
+	48:  "inappropriateAuthentication",
+	49:  "invalidCredentials",
+	50:  "insufficientAccessRights",
+	51:  "busy",
+	52:  "unavailable",
+	53:  "unwillingToPerform",
+	54:  "loopDetect",
+	64:  "namingViolation",
+	65:  "objectClassViolation",
+	66:  "notAllowedOnNonLeaf",
+	67:  "notAllowedOnRDN",
+	68:  "entryAlreadyExists",
+	69:  "objectClassModsProhibited",
+	71:  "affectsMultipleDSAs",
+	80:  "other",
+	118: "canceled",
+	119: "noSuchOperation",
+	120: "tooLate",
+	121: "cannotCancel",
+	122: "assertionFailed",
+	123: "authorizationDenied",
+}
+
+func resultName(code int64) string {
+	if name, ok := resultNames[code]; ok {
+		return name
+	}
+	return strconv.FormatInt(code, 10)
+}
+
+func isFailure(code int64) bool {
+	switch code {
+	case resultSuccess, resultCompareFalse, resultCompareTrue, resultSaslBindInProgress:
+		return false
+	}
+	return true
+}
+
+var scopeNames = []string{"baseObject", "singleLevel", "wholeSubtree"}
+
+var derefNames = []string{"neverDerefAliases", "derefInSearching", "derefFindingBaseObj", "derefAlways"}
+
+var modifyOperations = []string{"add", "delete", "replace"}
+
+func enumName(names []string, v int64) string {
+	if v >= 0 && v < int64(len(names)) {
+		return names[v]
+	}
+	return strconv.FormatInt(v, 10)
+}
+
+// message is a decoded LDAPMessage.
+type message struct {
+	ts time.Time
+	// tuple and cmdlineTuple are oriented from the sender of the message.
+	tuple        common.IPPortTuple
+	cmdlineTuple *common.ProcessTuple
+	size         int
+
+	id int64
+	op int
+
+	// Requests
+	dn            string
+	version       int64
+	authType      string
+	saslMechanism string
+	scope         string
+	derefAliases  string
+	sizeLimit     int64
+	timeLimit     int64
+	filter        string
+	attributes    []string
+	modifications []string
+	newRDN        string
+	newSuperior   string
+	requestName   string
+	abandonID     int64
+
+	// Responses
+	hasResult         bool
+	resultCode        int64
+	matchedDN         string
+	diagnosticMessage string
+}
+
+// parseMessage decodes an LDAPMessage without its outer SEQUENCE header. If
+// complete is false, data only contains the beginning of the message and
+// only its message ID and operation are decoded.
+func parseMessage(data []byte, complete bool) (*message, error) {
+	d := &decoder{buf: data}
+	m := &message{id: d.integer()}
+	op := d.next()
+	if d.err != nil {
+		return nil, fmt.Errorf("failed to parse message header: %w", d.err)
+	}
+	if op.class != classApplication {
+		return nil, fmt.Errorf("failed to parse message header: %w", errUnexpectedElement)
+	}
+	m.op = op.tag
+	if !complete {
+		return m, nil
+	}
+
+	body := d.children(op)
+	switch m.op {
+	case opBindRequest:
+		m.version = body.integer()
+		m.dn = body.octetString()
+		auth := body.next()
+		switch {
+		case auth.is(classContext, 0):
+			// The password is never reported.
+			m.authType = "simple"
+		case auth.is(classContext, 3):
+			m.authType = "sasl"
+			m.saslMechanism = body.children(auth).octetString()
+		default:
+			m.authType = strconv.Itoa(auth.tag)
+		}
+
+	case opSearchRequest:
+		m.dn = body.octetString()
+		m.scope = enumName(scopeNames, body.enumerated())
+		m.derefAliases = enumName(derefNames, body.enumerated())
+		m.sizeLimit = body.integer()
+		m.timeLimit = body.integer()
+		body.boolean() // typesOnly
+		filter := body.next()
+		if body.err == nil {
+			var err error
+			if m.filter, err = formatFilter(filter); err != nil {
+				body.fail(err)
+			}
+		}
+		attrs := body.sequence()
+		for attrs.more() {
+			m.attributes = append(m.attributes, attrs.octetString())
+		}
+		body.done(attrs)
+
+	case opModifyRequest:
+		m.dn = body.octetString()
+		changes := body.sequence()
+		for changes.more() {
+			change := changes.sequence()
+			operation := enumName(modifyOperations, change.enumerated())
+			attr := change.sequence()
+			m.modifications = append(m.modifications, operation+": "+attr.octetString())
+			change.done(attr)
+			changes.done(change)
+		}
+		body.done(changes)
+
+	case opAddRequest:
+		m.dn = body.octetString()
+		attrs := body.sequence()
+		for attrs.more() {
+			attr := attrs.sequence()
+			m.attributes = append(m.attributes, attr.octetString())
+			attrs.done(attr)
+		}
+		body.done(attrs)
+
+	case opDelRequest:
+		m.dn = string(op.data)
+
+	case opModifyDNRequest:
+		m.dn = body.octetString()
+		m.newRDN = body.octetString()
+		body.boolean() // deleteoldrdn
+		if body.peek(classContext, 0) {
+			m.newSuperior = string(body.next().data)
+		}
+
+	case opCompareRequest:
+		m.dn = body.octetString()
+		ava := body.sequence()
+		m.attributes = []string{ava.octetString()}
+		body.done(ava)
+
+	case opAbandonRequest:
+		m.abandonID = body.intValue(op)
+
+	case opExtendedRequest:
+		if body.peek(classContext, 0) {
+			m.requestName = string(body.next().data)
+		}
+
+	case opBindResponse, opSearchResultDone, opModifyResponse, opAddResponse,
+		opDelResponse, opModifyDNResponse, opCompareResponse, opExtendedResponse:
+		m.hasResult = true
+		m.resultCode = body.enumerated()
+		m.matchedDN = body.octetString()
+		m.diagnosticMessage = body.octetString()
+		if m.op == opExtendedResponse {
+			for body.more() {
+				if e := body.next(); e.is(classContext, 10) {
+					m.requestName = string(e.data)
+				}
+			}
+		}
+
+	case opUnbindRequest, opSearchResultEntry, opSearchResultReference, opIntermediateResponse:
+
+	default:
+		return nil, fmt.Errorf("unknown protocol operation %d", m.op)
+	}
+	if body.err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", opName(m.op), body.err)
+	}
+	return m, nil
+}
+
+func opName(op int) string {
+	if name, ok := operations[op]; ok {
+		return name + " request"
+	}
+	return fmt.Sprintf("operation %d", op)
+}
@@ -0,0 +1,36 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+// Code generated by beats/dev-tools/cmd/asset/asset.go - DO NOT EDIT.
+
+package mqtt
+
+import (
+	"github.com/elastic/beats/v7/libbeat/asset"
+)
+
+func init() {
+	if err := asset.SetFields("packetbeat", "mqtt", asset.ModuleFieldsPri, AssetMqtt); err != nil {
+		panic(err)
+	}
+}
+
+// AssetMqtt returns asset data.
+// This is the base64 encoded zlib format compressed contents of protos/mqtt.
+func AssetMqtt() string {
+	return "eJysVk1v4zYQvftXDHJqgUTYRbtF4UOBxt2iQdtst7bRo0yLTzZhmtSSYzvqry9IUY7kyG22Wvii8OPNmzfzJryjHeop7T8xT4hYscaUbn7/uFjcTIgkfOFUxcqaKf0wISIKW3e+QqFKVRCOMEylgpY+m1D6msaTd2TEHmfssMR1hSltnD1UaaV7oXupEsUOnIcL5732/g71yTrZWR/g2f4WW8RrZEviLaiwhp3VKQDxVjB5Fo4h436p7SnrIOBJ7Ksgyh/L+98e5r9MXlJ1lm1hdX6E88qa8XwTUEs5SH6OQgcPSeu6TcagCDC3JDx5mFbp5qdMPDb78Pj4frZIOWf0TfY2e0vKk/D+sIek0xZDJ3tYJ+HJWCYPmEGBbt5lb25eylNoBcO5kuN1aaBISRhWpYLrFPVSh4vce1ip9tYFAdTGPAvq4Y5w2UCR45WhLLQ1m9enkEJ3Uoj1ZEt7wcU2FkEUO2NPGnKDPQz7lGUPK5XyJdGDhwtf49UOSLHFh/RMiQwoVWgIk3v4QS+srQ37r2cxC8dp3sBRqcWGvkprwbWhzNEf77I3X7ftcL3mA3x3QJULrY4YWdoARBGIlGG4o9CBnUdhjfSfJ2KSL68cLkz9P2T8awvewnUanBwa4wtDeFKeldm0MeMkcahsHIlN1XtwgfyPs1+vk2dbqWJ8/0WYtqRp+rZBaRE3hVbCIxqkbQISrk/XwVt9bDzGZ1jeog5z30gq7ZDhP1k/sh8+2jlpHKGv5PAypgMLZcYVOyjX4DRmeW1seajGB5aHSqtCMEgiGMHVn0ci1iYvlWY4/6U6KMG1JObL+/nsz4f792QdLR+f/7zKykF4a/LCSoztiQaKIhQ58MGZs8muzf34JLkl63po3Edrz/70ML8YLiluCJXi2rKHFJ3TPAlaawsjab68f3a5D7Z6ngrCd2P7Ac0qUWsrZO7V3xipWYAIg3NdM86aiKrptPBM2sN7scFFl/VwrtY28ewcbigynvj1FP+bTdLxltgdTHBImEc9nNVePOVd2VZNxhl9MLqm9J+AVEkrDyPboytS3aYkghFrDfkvNYEprFRmM95gLVKb7OpMKr7rb8mDw9xdrYXHd9+uzg/NHlKrmGoemEehlaTl4ue777PJPwMAZzeSlw=="
+}
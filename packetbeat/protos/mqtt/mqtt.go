@@ -0,0 +1,597 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package mqtt
+
+import (
+	"encoding/base64"
+	"errors"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/elastic/beats/v7/libbeat/beat"
+	"github.com/elastic/beats/v7/libbeat/common"
+	conf "github.com/elastic/elastic-agent-libs/config"
+	"github.com/elastic/elastic-agent-libs/logp"
+	"github.com/elastic/elastic-agent-libs/mapstr"
+	"github.com/elastic/elastic-agent-libs/monitoring"
+
+	"github.com/elastic/beats/v7/packetbeat/pb"
+	"github.com/elastic/beats/v7/packetbeat/procs"
+	"github.com/elastic/beats/v7/packetbeat/protos"
+	"github.com/elastic/beats/v7/packetbeat/protos/applayer"
+	"github.com/elastic/beats/v7/packetbeat/protos/tcp"
+)
+
+// maxHeaderSize is the size of the data buffered to parse the headers of a
+// PUBLISH packet too large to be buffered.
+const maxHeaderSize = 64 * 1024
+
+type stream struct {
+	applayer.Stream
+	// skip is the number of bytes left of a packet too large to be
+	// buffered.
+	skip int
+}
+
+type connectionData struct {
+	streams [2]*stream
+
+	// protocolLevel and clientID are taken from the CONNECT packet.
+	protocolLevel uint8
+	clientID      string
+
+	// topicAliases maps the topic aliases of MQTT 5.0 to topic names, for
+	// each direction.
+	topicAliases [2]map[uint16]string
+
+	// pending are the flows waiting for an acknowledgement, in the order
+	// they were started.
+	pending []*transaction
+}
+
+// message is an MQTT control packet seen on the wire.
+type message struct {
+	*packet
+	ts           time.Time
+	direction    uint8
+	tcpTuple     common.TCPTuple
+	cmdlineTuple *common.ProcessTuple
+}
+
+// transaction is a flow of packets started by a CONNECT, PUBLISH, SUBSCRIBE
+// or UNSUBSCRIBE packet, until its acknowledgement.
+type transaction struct {
+	requ          *message
+	resp          *message
+	protocolLevel uint8
+	clientID      string
+
+	// expect is the type of the next packet of the flow, sent in the
+	// direction expectDir.
+	expect    packetType
+	expectDir uint8
+
+	// srcBytes and dstBytes are the sizes of the packets sent by the
+	// initiator of the flow and by its peer.
+	srcBytes int
+	dstBytes int
+
+	reasonCodes []int
+	failed      bool
+	notes       []string
+}
+
+// MQTT protocol plugin
+type mqttPlugin struct {
+	// config
+	ports              []int
+	sendPayload        bool
+	maxPayloadSize     int
+	maxPendingRequests int
+	transactionTimeout time.Duration
+
+	watcher *procs.ProcessesWatcher
+	results protos.Reporter
+}
+
+var (
+	debugf  = logp.MakeDebug("mqtt")
+	isDebug = false
+)
+
+var (
+	unmatchedResponses = monitoring.NewInt(nil, "mqtt.unmatched_responses")
+	unmatchedRequests  = monitoring.NewInt(nil, "mqtt.unmatched_requests")
+)
+
+func init() {
+	protos.Register("mqtt", New)
+}
+
+func New(
+	testMode bool,
+	results protos.Reporter,
+	watcher *procs.ProcessesWatcher,
+	cfg *conf.C,
+) (protos.Plugin, error) {
+	p := &mqttPlugin{}
+	config := defaultConfig
+	if !testMode {
+		if err := cfg.Unpack(&config); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := p.init(results, watcher, &config); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+func (mp *mqttPlugin) init(results protos.Reporter, watcher *procs.ProcessesWatcher, config *mqttConfig) error {
+	mp.setFromConfig(config)
+
+	mp.results = results
+	mp.watcher = watcher
+	isDebug = logp.IsDebug("mqtt")
+
+	return nil
+}
+
+func (mp *mqttPlugin) setFromConfig(config *mqttConfig) {
+	mp.ports = config.Ports
+	mp.sendPayload = config.SendPayload
+	mp.maxPayloadSize = config.MaxPayloadSize
+	mp.maxPendingRequests = config.MaxPendingRequests
+	mp.transactionTimeout = config.TransactionTimeout
+}
+
+func (mp *mqttPlugin) GetPorts() []int {
+	return mp.ports
+}
+
+func (mp *mqttPlugin) ConnectionTimeout() time.Duration {
+	return mp.transactionTimeout
+}
+
+func (mp *mqttPlugin) Parse(
+	pkt *protos.Packet,
+	tcptuple *common.TCPTuple,
+	dir uint8,
+	private protos.ProtocolData,
+) protos.ProtocolData {
+	conn := ensureConnection(private)
+	conn = mp.doParse(conn, pkt, tcptuple, dir)
+	if conn == nil {
+		return nil
+	}
+	return conn
+}
+
+func ensureConnection(private protos.ProtocolData) *connectionData {
+	if private == nil {
+		return &connectionData{}
+	}
+
+	priv, ok := private.(*connectionData)
+	if !ok {
+		logp.Warn("mqtt connection data type error, create new one")
+		return &connectionData{}
+	}
+	if priv == nil {
+		logp.Warn("Unexpected: mqtt connection data not set, create new one")
+		return &connectionData{}
+	}
+
+	return priv
+}
+
+func (mp *mqttPlugin) doParse(
+	conn *connectionData,
+	pkt *protos.Packet,
+	tcptuple *common.TCPTuple,
+	dir uint8,
+) *connectionData {
+	st := conn.streams[dir]
+	if st == nil {
+		st = &stream{}
+		st.Stream.Init(tcp.TCPMaxDataInStream)
+		conn.streams[dir] = st
+		if isDebug {
+			debugf("new stream: %p (dir=%v, len=%v)", st, dir, len(pkt.Payload))
+		}
+	}
+
+	payload := pkt.Payload
+	if st.skip > 0 {
+		n := min(st.skip, len(payload))
+		st.skip -= n
+		payload = payload[n:]
+	}
+	if err := st.Append(payload); err != nil {
+		if isDebug {
+			debugf("%v, dropping TCP stream: ", err)
+		}
+		return nil
+	}
+
+	maxPayload := 0
+	if mp.sendPayload {
+		maxPayload = mp.maxPayloadSize
+	}
+	for st.Buf.Len() > 0 {
+		buf := st.Buf.Bytes()
+		typ, flags, headerLen, remainingLen, err := parseFixedHeader(buf)
+		if errors.Is(err, errTruncated) {
+			// wait for more data
+			return conn
+		}
+		if err == nil && !validFlags(typ, flags) {
+			err = errInvalidFlags
+		}
+		if err != nil {
+			if isDebug {
+				debugf("%v, dropping MQTT connection", err)
+			}
+			return nil
+		}
+
+		size := headerLen + remainingLen
+		var p *packet
+		switch {
+		case st.Buf.Avail(size):
+			var data []byte
+			data, err = st.Buf.Collect(size)
+			if err == nil {
+				p, err = parsePacket(typ, flags, data[headerLen:], remainingLen, conn.level(), maxPayload)
+			}
+		case size > st.MaxDataInStream && typ == packetPublish:
+			// Parse the headers of the packet and skip its payload.
+			if len(buf) < maxHeaderSize {
+				// wait for more data
+				return conn
+			}
+			p, err = parsePacket(typ, flags, buf[headerLen:], remainingLen, conn.level(), maxPayload)
+			st.skip = size - len(buf)
+			_ = st.Buf.Advance(len(buf))
+		case size > st.MaxDataInStream:
+			err = errTooLarge
+		default:
+			// wait for more data
+			return conn
+		}
+		if err != nil {
+			if isDebug {
+				debugf("%v, dropping MQTT connection", err)
+			}
+			return nil
+		}
+		p.size = size
+		mp.handlePacket(conn, &message{
+			packet:       p,
+			ts:           pkt.Ts,
+			direction:    dir,
+			tcpTuple:     *tcptuple,
+			cmdlineTuple: mp.watcher.FindProcessesTupleTCP(tcptuple.IPPort()),
+		})
+		st.Reset()
+	}
+
+	return conn
+}
+
+// level returns the protocol level of the connection. MQTT 3.1.1 is assumed
+// when the CONNECT packet was not seen.
+func (conn *connectionData) level() uint8 {
+	if conn.protocolLevel == 0 {
+		return protocolLevel311
+	}
+	return conn.protocolLevel
+}
+
+func (mp *mqttPlugin) handlePacket(conn *connectionData, m *message) {
+	switch m.typ {
+	case packetConnect:
+		conn.protocolLevel = m.protocolLevel
+		conn.clientID = m.clientID
+		conn.topicAliases = [2]map[uint16]string{}
+		mp.startTransaction(conn, m, packetConnack)
+
+	case packetPublish:
+		conn.resolveTopicAlias(m)
+		switch m.qos {
+		case 0:
+			mp.publishTransaction(conn.newTransaction(m, 0))
+		case 1:
+			mp.startTransaction(conn, m, packetPuback)
+		default:
+			mp.startTransaction(conn, m, packetPubrec)
+		}
+
+	case packetSubscribe:
+		mp.startTransaction(conn, m, packetSuback)
+
+	case packetUnsubscribe:
+		mp.startTransaction(conn, m, packetUnsuback)
+
+	case packetDisconnect:
+		t := conn.newTransaction(m, 0)
+		t.addReasons(m)
+		mp.publishTransaction(t)
+
+	case packetConnack, packetPuback, packetPubrec, packetPubrel, packetPubcomp,
+		packetSuback, packetUnsuback:
+		t := conn.takeTransaction(m)
+		if t == nil {
+			unmatchedResponses.Add(1)
+			if isDebug {
+				debugf("%v packet with ID %d has no matching request", m.typ, m.packetID)
+			}
+			return
+		}
+		if m.direction == t.requ.direction {
+			t.srcBytes += m.size
+		} else {
+			t.dstBytes += m.size
+		}
+		t.addReasons(m)
+		switch {
+		case m.typ == packetConnack && m.assignedClientID != "":
+			conn.clientID = m.assignedClientID
+			t.clientID = m.assignedClientID
+		case m.typ == packetPubrec && (len(m.reasonCodes) == 0 || m.reasonCodes[0] < 0x80):
+			// The publisher releases the message and the flow continues.
+			t.expect, t.expectDir = packetPubrel, t.requ.direction
+			conn.pending = append(conn.pending, t)
+			return
+		case m.typ == packetPubrel:
+			t.expect, t.expectDir = packetPubcomp, 1-t.requ.direction
+			conn.pending = append(conn.pending, t)
+			return
+		}
+		t.resp = m
+		mp.publishTransaction(t)
+
+	case packetPingreq, packetPingresp, packetAuth:
+		// Not reported.
+	}
+}
+
+func (conn *connectionData) newTransaction(m *message, expect packetType) *transaction {
+	return &transaction{
+		requ:          m,
+		protocolLevel: conn.level(),
+		clientID:      conn.clientID,
+		expect:        expect,
+		expectDir:     1 - m.direction,
+		srcBytes:      m.size,
+	}
+}
+
+// startTransaction adds a flow waiting for the expected acknowledgement. A
+// pending flow with the same packet identifier is replaced, unless the new
+// packet is its retransmission.
+func (mp *mqttPlugin) startTransaction(conn *connectionData, m *message, expect packetType) {
+	t := conn.newTransaction(m, expect)
+	for i, prev := range conn.pending {
+		if prev.expect != t.expect || prev.expectDir != t.expectDir || prev.requ.packetID != m.packetID {
+			continue
+		}
+		if m.dup {
+			prev.srcBytes += m.size
+			return
+		}
+		conn.pending = slices.Delete(conn.pending, i, i+1)
+		unmatchedRequests.Add(1)
+		mp.publishTransaction(prev)
+		break
+	}
+	if len(conn.pending) >= mp.maxPendingRequests {
+		unmatchedRequests.Add(1)
+		mp.publishTransaction(conn.pending[0])
+		conn.pending = conn.pending[1:]
+	}
+	conn.pending = append(conn.pending, t)
+}
+
+// takeTransaction removes the pending flow the packet belongs to.
+func (conn *connectionData) takeTransaction(m *message) *transaction {
+	for i, t := range conn.pending {
+		if t.expect == m.typ && t.expectDir == m.direction && t.requ.packetID == m.packetID {
+			conn.pending = slices.Delete(conn.pending, i, i+1)
+			return t
+		}
+	}
+	return nil
+}
+
+// resolveTopicAlias replaces the topic alias of a PUBLISH packet by its
+// topic name, or records the alias when the packet sets it.
+func (conn *connectionData) resolveTopicAlias(m *message) {
+	if m.topicAlias == 0 {
+		return
+	}
+	aliases := conn.topicAliases[m.direction]
+	if m.topic != "" {
+		if aliases == nil {
+			aliases = map[uint16]string{}
+			conn.topicAliases[m.direction] = aliases
+		}
+		aliases[m.topicAlias] = m.topic
+		return
+	}
+	m.topic = aliases[m.topicAlias]
+}
+
+// addReasons adds the reason codes of a packet and the names of those
+// reporting a failure.
+func (t *transaction) addReasons(m *message) {
+	for _, code := range m.reasonCodes {
+		t.reasonCodes = append(t.reasonCodes, int(code))
+		if name := reasonFailure(m.typ, code, t.protocolLevel); name != "" {
+			t.failed = true
+			t.notes = append(t.notes, name)
+		}
+	}
+	if m.reasonString != "" {
+		t.notes = append(t.notes, m.reasonString)
+	}
+}
+
+func (mp *mqttPlugin) publishTransaction(t *transaction) {
+	if mp.results == nil {
+		return
+	}
+	mp.results(mp.newEvent(t))
+}
+
+func (mp *mqttPlugin) newEvent(t *transaction) beat.Event {
+	requ := t.requ
+	source, destination := common.MakeEndpointPair(requ.tcpTuple.BaseTuple, requ.cmdlineTuple)
+	src, dst := &source, &destination
+	if requ.direction == tcp.TCPDirectionReverse {
+		src, dst = dst, src
+	}
+
+	evt, pbf := pb.NewBeatEvent(requ.ts)
+	pbf.SetSource(src)
+	pbf.SetDestination(dst)
+	pbf.AddIP(src.IP)
+	pbf.AddIP(dst.IP)
+	pbf.Source.Bytes = int64(t.srcBytes)
+	pbf.Event.Dataset = "mqtt"
+	pbf.Event.Start = requ.ts
+	pbf.Network.Transport = "tcp"
+	pbf.Network.Protocol = pbf.Event.Dataset
+
+	mqtt := mapstr.M{
+		"packet_type":      requ.typ.String(),
+		"protocol_version": protocolVersion(t.protocolLevel),
+	}
+	if t.clientID != "" {
+		mqtt["client_id"] = t.clientID
+	}
+	if requ.packetID != 0 {
+		mqtt["packet_id"] = requ.packetID
+	}
+
+	fields := evt.Fields
+	switch requ.typ {
+	case packetConnect:
+		mqtt["clean_session"] = requ.cleanStart
+		mqtt["keep_alive"] = requ.keepAlive
+		if requ.username != "" {
+			mqtt["username"] = requ.username
+		}
+		if t.resp != nil {
+			mqtt["session_present"] = t.resp.sessionPresent
+		}
+	case packetPublish:
+		mqtt["topic"] = requ.topic
+		mqtt["qos"] = requ.qos
+		mqtt["retain"] = requ.retain
+		mqtt["dup"] = requ.dup
+		mqtt["payload_size"] = requ.payloadSize
+		if mp.sendPayload {
+			if utf8.Valid(requ.payload) {
+				mqtt["payload"] = string(requ.payload)
+			} else {
+				mqtt["payload"] = base64.StdEncoding.EncodeToString(requ.payload)
+				mqtt["payload_encoding"] = "base64"
+			}
+		}
+		if requ.topic != "" {
+			fields["resource"] = requ.topic
+		}
+	case packetSubscribe, packetUnsubscribe:
+		mqtt["topic_filters"] = requ.topicFilters
+		fields["resource"] = strings.Join(requ.topicFilters, ",")
+	}
+	if len(t.reasonCodes) > 0 {
+		mqtt["reason_codes"] = t.reasonCodes
+	}
+
+	status := common.OK_STATUS
+	notes := t.notes
+	if t.failed {
+		status = common.ERROR_STATUS
+	}
+	if t.expect != 0 && t.resp == nil {
+		status = common.ERROR_STATUS
+		notes = append(notes, "Unmatched request")
+	}
+	if t.resp != nil {
+		pbf.Event.End = t.resp.ts
+	}
+	if t.dstBytes > 0 {
+		pbf.Destination.Bytes = int64(t.dstBytes)
+	}
+
+	fields["type"] = pbf.Event.Dataset
+	fields["status"] = status
+	fields["method"] = requ.typ.String()
+	fields["mqtt"] = mqtt
+
+	if status == common.ERROR_STATUS {
+		pbf.Event.Outcome = "failure"
+	}
+	pbf.Error.Message = notes
+
+	return evt
+}
+
+func (mp *mqttPlugin) GapInStream(tcptuple *common.TCPTuple, dir uint8,
+	nbytes int, private protos.ProtocolData) (priv protos.ProtocolData, drop bool,
+) {
+	conn, ok := private.(*connectionData)
+	if !ok || conn == nil {
+		return private, true
+	}
+	// Gaps in the payload of a packet that is skipped don't break the
+	// framing.
+	if st := conn.streams[dir]; st != nil && st.skip >= nbytes {
+		st.skip -= nbytes
+		return private, false
+	}
+	return private, true
+}
+
+func (mp *mqttPlugin) ReceivedFin(tcptuple *common.TCPTuple, dir uint8,
+	private protos.ProtocolData,
+) protos.ProtocolData {
+	return private
+}
+
+// Expired publishes the flows still waiting for an acknowledgement when the
+// connection expires.
+func (mp *mqttPlugin) Expired(tuple *common.TCPTuple, private protos.ProtocolData) {
+	conn, ok := private.(*connectionData)
+	if !ok || conn == nil {
+		return
+	}
+	if isDebug {
+		debugf("expired connection %s", tuple)
+	}
+	for _, t := range conn.pending {
+		unmatchedRequests.Add(1)
+		mp.publishTransaction(t)
+	}
+	conn.pending = nil
+}
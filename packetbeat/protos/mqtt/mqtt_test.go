@@ -0,0 +1,417 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+//go:build !integration
+
+package mqtt
+
+import (
+	"encoding/binary"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/elastic/beats/v7/libbeat/beat"
+	"github.com/elastic/beats/v7/libbeat/common"
+	"github.com/elastic/beats/v7/packetbeat/procs"
+	"github.com/elastic/beats/v7/packetbeat/protos"
+	"github.com/elastic/beats/v7/packetbeat/protos/tcp"
+	"github.com/elastic/beats/v7/packetbeat/publish"
+	conf "github.com/elastic/elastic-agent-libs/config"
+	"github.com/elastic/elastic-agent-libs/mapstr"
+)
+
+type eventStore struct {
+	events []beat.Event
+}
+
+func (e *eventStore) publish(event beat.Event) {
+	publish.MarshalPacketbeatFields(&event, nil, nil)
+	e.events = append(e.events, event)
+}
+
+func mqttModForTests(t *testing.T, store *eventStore, settings map[string]interface{}) *mqttPlugin {
+	t.Helper()
+	if settings == nil {
+		settings = map[string]interface{}{}
+	}
+	if _, ok := settings["ports"]; !ok {
+		settings["ports"] = []int{1883}
+	}
+	p, err := New(false, store.publish, &procs.ProcessesWatcher{}, conf.MustNewConfigFrom(settings))
+	require.NoError(t, err)
+	return p.(*mqttPlugin)
+}
+
+func testTCPTuple() *common.TCPTuple {
+	t := &common.TCPTuple{
+		IPLength: 4,
+		BaseTuple: common.BaseTuple{
+			SrcIP: net.IPv4(192, 168, 0, 1), DstIP: net.IPv4(192, 168, 0, 2),
+			SrcPort: 6512, DstPort: 1883,
+		},
+	}
+	t.ComputeHashables()
+	return t
+}
+
+// Helpers to encode MQTT packets.
+
+func mqttPacket(typ packetType, flags uint8, parts ...[]byte) []byte {
+	var body []byte
+	for _, part := range parts {
+		body = append(body, part...)
+	}
+	b := append([]byte{byte(typ)<<4 | flags}, encodeVarint(len(body))...)
+	return append(b, body...)
+}
+
+func encodeVarint(v int) []byte {
+	var b []byte
+	for {
+		c := byte(v & 0x7f)
+		v >>= 7
+		if v > 0 {
+			c |= 0x80
+		}
+		b = append(b, c)
+		if v == 0 {
+			return b
+		}
+	}
+}
+
+func u16(v uint16) []byte {
+	return binary.BigEndian.AppendUint16(nil, v)
+}
+
+func str(s string) []byte {
+	return append(u16(uint16(len(s))), s...)
+}
+
+// props encodes MQTT 5.0 properties.
+func props(parts ...[]byte) []byte {
+	var b []byte
+	for _, part := range parts {
+		b = append(b, part...)
+	}
+	return append(encodeVarint(len(b)), b...)
+}
+
+func connect(level uint8, flags uint8, clientID string, extra ...[]byte) []byte {
+	parts := [][]byte{str("MQTT"), {level, flags}, u16(60)}
+	if level == protocolLevel5 {
+		parts = append(parts, props())
+	}
+	parts = append(parts, str(clientID))
+	return mqttPacket(packetConnect, 0, append(parts, extra...)...)
+}
+
+func publishPacket(flags uint8, topic string, packetID uint16, rest ...[]byte) []byte {
+	parts := [][]byte{str(topic)}
+	if (flags>>1)&0x03 > 0 {
+		parts = append(parts, u16(packetID))
+	}
+	return mqttPacket(packetPublish, flags, append(parts, rest...)...)
+}
+
+type testPacket struct {
+	dir  uint8
+	data []byte
+}
+
+func client(data []byte) testPacket {
+	return testPacket{dir: tcp.TCPDirectionOriginal, data: data}
+}
+
+func server(data []byte) testPacket {
+	return testPacket{dir: tcp.TCPDirectionReverse, data: data}
+}
+
+func parsePackets(mqtt *mqttPlugin, packets ...testPacket) protos.ProtocolData {
+	tuple := testTCPTuple()
+	var private protos.ProtocolData
+	ts := time.Now()
+	for _, p := range packets {
+		ts = ts.Add(time.Millisecond)
+		private = mqtt.Parse(&protos.Packet{Ts: ts, Payload: p.data}, tuple, p.dir, private)
+	}
+	return private
+}
+
+func mqttFields(t *testing.T, event beat.Event) mapstr.M {
+	t.Helper()
+	v, err := event.GetValue("mqtt")
+	require.NoError(t, err)
+	return v.(mapstr.M)
+}
+
+func TestConnect(t *testing.T) {
+	store := &eventStore{}
+	mqtt := mqttModForTests(t, store, nil)
+
+	requ := connect(protocolLevel311, 0xc2, "sensor-1", str("user"), str("secret"))
+	resp := mqttPacket(packetConnack, 0, []byte{1, 0})
+	parsePackets(mqtt, client(requ), server(resp))
+
+	require.Len(t, store.events, 1)
+	event := store.events[0]
+	assert.Equal(t, mapstr.M{
+		"packet_type":      "CONNECT",
+		"protocol_version": "3.1.1",
+		"client_id":        "sensor-1",
+		"username":         "user",
+		"clean_session":    true,
+		"keep_alive":       uint16(60),
+		"session_present":  true,
+		"reason_codes":     []int{0},
+	}, mqttFields(t, event))
+	assert.Equal(t, "OK", event.Fields["status"])
+	assert.Equal(t, "CONNECT", event.Fields["method"])
+	assert.Equal(t, "mqtt", event.Fields["type"])
+
+	bytes, _ := event.GetValue("source.bytes")
+	assert.EqualValues(t, len(requ), bytes)
+	bytes, _ = event.GetValue("destination.bytes")
+	assert.EqualValues(t, len(resp), bytes)
+	port, _ := event.GetValue("destination.port")
+	assert.EqualValues(t, 1883, port)
+}
+
+func TestConnectRefused(t *testing.T) {
+	store := &eventStore{}
+	mqtt := mqttModForTests(t, store, nil)
+
+	parsePackets(mqtt,
+		client(connect(protocolLevel311, 0x02, "sensor-1")),
+		server(mqttPacket(packetConnack, 0, []byte{0, 5})),
+	)
+
+	require.Len(t, store.events, 1)
+	event := store.events[0]
+	assert.Equal(t, "Error", event.Fields["status"])
+	msg, _ := event.GetValue("error.message")
+	assert.Equal(t, "Connection Refused, not authorized", msg)
+	outcome, _ := event.GetValue("event.outcome")
+	assert.Equal(t, "failure", outcome)
+}
+
+func TestPublishQoS0(t *testing.T) {
+	store := &eventStore{}
+	mqtt := mqttModForTests(t, store, map[string]interface{}{
+		"send_payload":     true,
+		"max_payload_size": 5,
+	})
+
+	parsePackets(mqtt,
+		client(connect(protocolLevel311, 0x02, "sensor-1")),
+		server(mqttPacket(packetConnack, 0, []byte{0, 0})),
+		client(publishPacket(0x01, "sensors/temp", 0, []byte("21.5 C"))),
+		client(publishPacket(0x00, "sensors/raw", 0, []byte{0xff, 0xfe})),
+	)
+
+	require.Len(t, store.events, 3)
+	assert.Equal(t, mapstr.M{
+		"packet_type":      "PUBLISH",
+		"protocol_version": "3.1.1",
+		"client_id":        "sensor-1",
+		"topic":            "sensors/temp",
+		"qos":              uint8(0),
+		"retain":           true,
+		"dup":              false,
+		"payload_size":     6,
+		"payload":          "21.5 ",
+	}, mqttFields(t, store.events[1]))
+	assert.Equal(t, "sensors/temp", store.events[1].Fields["resource"])
+	assert.Equal(t, "OK", store.events[1].Fields["status"])
+
+	fields := mqttFields(t, store.events[2])
+	assert.Equal(t, "//4=", fields["payload"])
+	assert.Equal(t, "base64", fields["payload_encoding"])
+}
+
+func TestPublishQoS1(t *testing.T) {
+	store := &eventStore{}
+	mqtt := mqttModForTests(t, store, nil)
+
+	parsePackets(mqtt,
+		client(publishPacket(0x02, "a/b", 10, []byte("x"))),
+		client(publishPacket(0x02, "a/c", 11, []byte("y"))),
+		server(mqttPacket(packetPuback, 0, u16(11))),
+		server(mqttPacket(packetPuback, 0, u16(10))),
+	)
+
+	require.Len(t, store.events, 2)
+	for i, topic := range []string{"a/c", "a/b"} {
+		fields := mqttFields(t, store.events[i])
+		assert.Equal(t, topic, fields["topic"])
+		assert.Equal(t, uint8(1), fields["qos"])
+		assert.NotContains(t, fields, "payload")
+		assert.Equal(t, "OK", store.events[i].Fields["status"])
+	}
+	assert.Equal(t, uint16(11), mqttFields(t, store.events[0])["packet_id"])
+}
+
+func TestPublishQoS2(t *testing.T) {
+	store := &eventStore{}
+	mqtt := mqttModForTests(t, store, nil)
+
+	// Message delivered by the server to a subscriber.
+	pub := publishPacket(0x04, "a/b", 7, []byte("hello"))
+	pubrec := mqttPacket(packetPubrec, 0, u16(7))
+	pubrel := mqttPacket(packetPubrel, 0x02, u16(7))
+	pubcomp := mqttPacket(packetPubcomp, 0, u16(7))
+	parsePackets(mqtt, server(pub), client(pubrec), server(pubrel))
+	assert.Empty(t, store.events)
+	parsePackets(mqtt, server(pub), client(pubrec), server(pubrel), client(pubcomp))
+
+	require.Len(t, store.events, 1)
+	event := store.events[0]
+	assert.Equal(t, "OK", event.Fields["status"])
+	assert.Equal(t, uint8(2), mqttFields(t, event)["qos"])
+	bytes, _ := event.GetValue("source.bytes")
+	assert.EqualValues(t, len(pub)+len(pubrel), bytes)
+	bytes, _ = event.GetValue("destination.bytes")
+	assert.EqualValues(t, len(pubrec)+len(pubcomp), bytes)
+	port, _ := event.GetValue("source.port")
+	assert.EqualValues(t, 1883, port)
+}
+
+func TestSubscribe(t *testing.T) {
+	store := &eventStore{}
+	mqtt := mqttModForTests(t, store, nil)
+
+	parsePackets(mqtt,
+		client(mqttPacket(packetSubscribe, 0x02, u16(3), str("a/#"), []byte{1}, str("$SYS/#"), []byte{0})),
+		server(mqttPacket(packetSuback, 0, u16(3), []byte{1, 0x80})),
+		client(mqttPacket(packetUnsubscribe, 0x02, u16(4), str("a/#"))),
+		server(mqttPacket(packetUnsuback, 0, u16(4))),
+	)
+
+	require.Len(t, store.events, 2)
+	event := store.events[0]
+	fields := mqttFields(t, event)
+	assert.Equal(t, []string{"a/#", "$SYS/#"}, fields["topic_filters"])
+	assert.Equal(t, []int{1, 0x80}, fields["reason_codes"])
+	assert.Equal(t, "a/#,$SYS/#", event.Fields["resource"])
+	assert.Equal(t, "Error", event.Fields["status"])
+	msg, _ := event.GetValue("error.message")
+	assert.Equal(t, "Failure", msg)
+
+	event = store.events[1]
+	assert.Equal(t, "UNSUBSCRIBE", event.Fields["method"])
+	assert.Equal(t, "OK", event.Fields["status"])
+}
+
+func TestMQTT5(t *testing.T) {
+	store := &eventStore{}
+	mqtt := mqttModForTests(t, store, nil)
+
+	parsePackets(mqtt,
+		client(connect(protocolLevel5, 0x02, "")),
+		server(mqttPacket(packetConnack, 0, []byte{0, 0},
+			props([]byte{0x21}, u16(10), []byte{propAssignedClientID}, str("auto-1")))),
+		// The topic alias is set by the first message and used by the
+		// second.
+		client(publishPacket(0x02, "sensors/temp", 1,
+			props([]byte{0x01, 1}, []byte{propTopicAlias}, u16(4)), []byte("21"))),
+		server(mqttPacket(packetPuback, 0, u16(1), []byte{0x10}, props())),
+		client(publishPacket(0x02, "", 2, props([]byte{propTopicAlias}, u16(4)), []byte("22"))),
+		server(mqttPacket(packetPuback, 0, u16(2), []byte{0x97},
+			props([]byte{propReasonString}, str("slow down")))),
+		client(mqttPacket(packetSubscribe, 0x02, u16(3), props([]byte{0x0b, 0x01}), str("cmd/#"), []byte{2})),
+		server(mqttPacket(packetSuback, 0, u16(3), props(), []byte{0x87})),
+		client(mqttPacket(packetDisconnect, 0, []byte{0x04}, props([]byte{0x11}, []byte{0, 0, 0, 0}))),
+	)
+
+	require.Len(t, store.events, 5)
+	fields := mqttFields(t, store.events[0])
+	assert.Equal(t, "5.0", fields["protocol_version"])
+	assert.Equal(t, "auto-1", fields["client_id"])
+
+	fields = mqttFields(t, store.events[1])
+	assert.Equal(t, "sensors/temp", fields["topic"])
+	assert.Equal(t, "auto-1", fields["client_id"])
+	assert.Equal(t, []int{0x10}, fields["reason_codes"])
+	assert.Equal(t, "OK", store.events[1].Fields["status"])
+
+	event := store.events[2]
+	assert.Equal(t, "sensors/temp", mqttFields(t, event)["topic"])
+	assert.Equal(t, "Error", event.Fields["status"])
+	msg, _ := event.GetValue("error.message")
+	assert.Equal(t, []string{"Quota exceeded", "slow down"}, msg)
+
+	event = store.events[3]
+	assert.Equal(t, "Error", event.Fields["status"])
+	msg, _ = event.GetValue("error.message")
+	assert.Equal(t, "Not authorized", msg)
+
+	event = store.events[4]
+	assert.Equal(t, "DISCONNECT", event.Fields["method"])
+	assert.Equal(t, []int{0x04}, mqttFields(t, event)["reason_codes"])
+	assert.Equal(t, "OK", event.Fields["status"])
+}
+
+func TestFragmentedPackets(t *testing.T) {
+	store := &eventStore{}
+	mqtt := mqttModForTests(t, store, nil)
+
+	data := append(publishPacket(0x00, "a/b", 0, make([]byte, 200)), publishPacket(0x00, "a/c", 0)...)
+	var packets []testPacket
+	for i := 0; i < len(data); i += 7 {
+		packets = append(packets, client(data[i:min(i+7, len(data))]))
+	}
+	parsePackets(mqtt, packets...)
+
+	require.Len(t, store.events, 2)
+	assert.Equal(t, 200, mqttFields(t, store.events[0])["payload_size"])
+	assert.Equal(t, "a/c", mqttFields(t, store.events[1])["topic"])
+}
+
+func TestUnmatchedRequests(t *testing.T) {
+	store := &eventStore{}
+	mqtt := mqttModForTests(t, store, map[string]interface{}{
+		"max_pending_requests": 1,
+	})
+
+	private := parsePackets(mqtt,
+		client(publishPacket(0x02, "a/b", 1, []byte("x"))),
+		client(publishPacket(0x02, "a/c", 2, []byte("y"))),
+		server(mqttPacket(packetPuback, 0, u16(9))),
+	)
+	require.Len(t, store.events, 1)
+	assert.Equal(t, "a/b", mqttFields(t, store.events[0])["topic"])
+	assert.Equal(t, "Error", store.events[0].Fields["status"])
+	msg, _ := store.events[0].GetValue("error.message")
+	assert.Equal(t, "Unmatched request", msg)
+
+	mqtt.Expired(testTCPTuple(), private)
+	require.Len(t, store.events, 2)
+	assert.Equal(t, "a/c", mqttFields(t, store.events[1])["topic"])
+}
+
+func TestInvalidData(t *testing.T) {
+	store := &eventStore{}
+	mqtt := mqttModForTests(t, store, nil)
+
+	private := parsePackets(mqtt, client([]byte("GET / HTTP/1.1\r\n\r\n")))
+	assert.Nil(t, private)
+	assert.Empty(t, store.events)
+}
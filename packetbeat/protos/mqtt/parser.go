@@ -0,0 +1,448 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package mqtt
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+type packetType uint8
+
+// Control packet types.
+const (
+	packetConnect     packetType = 1
+	packetConnack     packetType = 2
+	packetPublish     packetType = 3
+	packetPuback      packetType = 4
+	packetPubrec      packetType = 5
+	packetPubrel      packetType = 6
+	packetPubcomp     packetType = 7
+	packetSubscribe   packetType = 8
+	packetSuback      packetType = 9
+	packetUnsubscribe packetType = 10
+	packetUnsuback    packetType = 11
+	packetPingreq     packetType = 12
+	packetPingresp    packetType = 13
+	packetDisconnect  packetType = 14
+	packetAuth        packetType = 15
+)
+
+var packetTypeNames = [...]string{
+	packetConnect:     "CONNECT",
+	packetConnack:     "CONNACK",
+	packetPublish:     "PUBLISH",
+	packetPuback:      "PUBACK",
+	packetPubrec:      "PUBREC",
+	packetPubrel:      "PUBREL",
+	packetPubcomp:     "PUBCOMP",
+	packetSubscribe:   "SUBSCRIBE",
+	packetSuback:      "SUBACK",
+	packetUnsubscribe: "UNSUBSCRIBE",
+	packetUnsuback:    "UNSUBACK",
+	packetPingreq:     "PINGREQ",
+	packetPingresp:    "PINGRESP",
+	packetDisconnect:  "DISCONNECT",
+	packetAuth:        "AUTH",
+}
+
+func (t packetType) String() string {
+	if int(t) < len(packetTypeNames) && packetTypeNames[t] != "" {
+		return packetTypeNames[t]
+	}
+	return fmt.Sprintf("(unknown:%d)", uint8(t))
+}
+
+// Protocol levels sent in CONNECT packets.
+const (
+	protocolLevel31  uint8 = 3
+	protocolLevel311 uint8 = 4
+	protocolLevel5   uint8 = 5
+)
+
+func protocolVersion(level uint8) string {
+	switch level {
+	case protocolLevel31:
+		return "3.1"
+	case protocolLevel311:
+		return "3.1.1"
+	case protocolLevel5:
+		return "5.0"
+	}
+	return fmt.Sprintf("(unknown:%d)", level)
+}
+
+// Properties of MQTT 5.0 packets used by the analyzer.
+const (
+	propAssignedClientID = 0x12
+	propReasonString     = 0x1f
+	propTopicAlias       = 0x23
+)
+
+// propertyTypes are the types of the MQTT 5.0 properties, needed to skip
+// them.
+var propertyTypes = map[byte]propertyType{
+	0x01: propByte,     // Payload Format Indicator
+	0x02: propFourByte, // Message Expiry Interval
+	0x03: propString,   // Content Type
+	0x08: propString,   // Response Topic
+	0x09: propBinary,   // Correlation Data
+	0x0b: propVarint,   // Subscription Identifier
+	0x11: propFourByte, // Session Expiry Interval
+	0x12: propString,   // Assigned Client Identifier
+	0x13: propTwoByte,  // Server Keep Alive
+	0x15: propString,   // Authentication Method
+	0x16: propBinary,   // Authentication Data
+	0x17: propByte,     // Request Problem Information
+	0x18: propFourByte, // Will Delay Interval
+	0x19: propByte,     // Request Response Information
+	0x1a: propString,   // Response Information
+	0x1c: propString,   // Server Reference
+	0x1f: propString,   // Reason String
+	0x21: propTwoByte,  // Receive Maximum
+	0x22: propTwoByte,  // Topic Alias Maximum
+	0x23: propTwoByte,  // Topic Alias
+	0x24: propByte,     // Maximum QoS
+	0x25: propByte,     // Retain Available
+	0x26: propStringPair,
+	0x27: propFourByte, // Maximum Packet Size
+	0x28: propByte,     // Wildcard Subscription Available
+	0x29: propByte,     // Subscription Identifier Available
+	0x2a: propByte,     // Shared Subscription Available
+}
+
+type propertyType uint8
+
+const (
+	propByte propertyType = iota
+	propTwoByte
+	propFourByte
+	propVarint
+	propString
+	propBinary
+	propStringPair
+)
+
+var (
+	errTruncated       = errors.New("packet truncated")
+	errInvalidVarint   = errors.New("invalid variable byte integer")
+	errUnknownProperty = errors.New("unknown property")
+	errInvalidFlags    = errors.New("invalid fixed header flags")
+	errTooLarge        = errors.New("packet too large")
+)
+
+// packet is an MQTT control packet.
+type packet struct {
+	typ   packetType
+	flags uint8
+	// size is the size of the packet, including its fixed header.
+	size int
+
+	// CONNECT
+	protocolLevel uint8
+	cleanStart    bool
+	keepAlive     uint16
+	clientID      string
+	username      string
+
+	// CONNACK
+	sessionPresent   bool
+	assignedClientID string
+
+	// PUBLISH
+	dup         bool
+	qos         uint8
+	retain      bool
+	topic       string
+	topicAlias  uint16
+	payload     []byte
+	payloadSize int
+
+	packetID     uint16
+	topicFilters []string
+	reasonCodes  []uint8
+	reasonString string
+}
+
+// decoder reads the data types of MQTT from a packet. The first error is
+// kept and subsequent reads return zero values.
+type decoder struct {
+	buf []byte
+	pos int
+	err error
+}
+
+func (d *decoder) fail(err error) {
+	if d.err == nil {
+		d.err = err
+	}
+}
+
+func (d *decoder) remaining() int {
+	return len(d.buf) - d.pos
+}
+
+func (d *decoder) read(n int) []byte {
+	if d.err != nil {
+		return nil
+	}
+	if n < 0 || d.remaining() < n {
+		d.fail(errTruncated)
+		return nil
+	}
+	b := d.buf[d.pos : d.pos+n]
+	d.pos += n
+	return b
+}
+
+func (d *decoder) byte() uint8 {
+	if b := d.read(1); b != nil {
+		return b[0]
+	}
+	return 0
+}
+
+func (d *decoder) uint16() uint16 {
+	if b := d.read(2); b != nil {
+		return binary.BigEndian.Uint16(b)
+	}
+	return 0
+}
+
+func (d *decoder) binary() []byte {
+	return d.read(int(d.uint16()))
+}
+
+func (d *decoder) string() string {
+	return string(d.binary())
+}
+
+func (d *decoder) varint() int {
+	if d.err != nil {
+		return 0
+	}
+	value, n, err := readVarint(d.buf[d.pos:])
+	if err != nil {
+		d.fail(err)
+		return 0
+	}
+	d.pos += n
+	return value
+}
+
+// properties reads the properties of an MQTT 5.0 packet, calling fn with the
+// properties used by the analyzer.
+func (d *decoder) properties(fn func(id byte, d *decoder)) {
+	length := d.varint()
+	props := &decoder{buf: d.read(length)}
+	if d.err != nil {
+		return
+	}
+	for props.remaining() > 0 && props.err == nil {
+		id := props.byte()
+		switch id {
+		case propAssignedClientID, propReasonString, propTopicAlias:
+			fn(id, props)
+			continue
+		}
+		typ, ok := propertyTypes[id]
+		if !ok {
+			props.fail(errUnknownProperty)
+			break
+		}
+		switch typ {
+		case propByte:
+			props.read(1)
+		case propTwoByte:
+			props.read(2)
+		case propFourByte:
+			props.read(4)
+		case propVarint:
+			props.varint()
+		case propString, propBinary:
+			props.binary()
+		case propStringPair:
+			props.binary()
+			props.binary()
+		}
+	}
+	d.fail(props.err)
+}
+
+// readVarint reads a variable byte integer, returning ErrTruncated if more
+// data is needed.
+func readVarint(b []byte) (value, n int, err error) {
+	for shift := 0; n < 4; shift += 7 {
+		if n >= len(b) {
+			return 0, 0, errTruncated
+		}
+		c := b[n]
+		n++
+		value |= int(c&0x7f) << shift
+		if c&0x80 == 0 {
+			return value, n, nil
+		}
+	}
+	return 0, 0, errInvalidVarint
+}
+
+// parseFixedHeader parses the fixed header at the start of b, returning the
+// packet type, its flags, the length of the fixed header and the length of
+// the rest of the packet.
+func parseFixedHeader(b []byte) (typ packetType, flags uint8, headerLen, remainingLen int, err error) {
+	if len(b) < 2 {
+		return 0, 0, 0, 0, errTruncated
+	}
+	typ, flags = packetType(b[0]>>4), b[0]&0x0f
+	remainingLen, n, err := readVarint(b[1:])
+	if err != nil {
+		return 0, 0, 0, 0, err
+	}
+	return typ, flags, 1 + n, remainingLen, nil
+}
+
+// validFlags returns whether the flags of the fixed header are valid for the
+// packet type.
+func validFlags(typ packetType, flags uint8) bool {
+	switch typ {
+	case packetPublish:
+		return (flags>>1)&0x03 != 3
+	case packetPubrel, packetSubscribe, packetUnsubscribe:
+		return flags == 0x02
+	case packetConnect, packetConnack, packetPuback, packetPubrec, packetPubcomp,
+		packetSuback, packetUnsuback, packetPingreq, packetPingresp,
+		packetDisconnect, packetAuth:
+		return flags == 0
+	}
+	return false
+}
+
+// parsePacket parses the variable header and payload of a packet. If body is
+// shorter than remainingLen, only the beginning of a PUBLISH packet is
+// available and its payload is truncated.
+func parsePacket(typ packetType, flags uint8, body []byte, remainingLen int, level uint8, maxPayload int) (*packet, error) {
+	p := &packet{typ: typ, flags: flags}
+	d := &decoder{buf: body}
+	v5 := level == protocolLevel5
+	onProperty := func(id byte, props *decoder) {
+		switch id {
+		case propAssignedClientID:
+			p.assignedClientID = props.string()
+		case propReasonString:
+			p.reasonString = props.string()
+		case propTopicAlias:
+			p.topicAlias = props.uint16()
+		}
+	}
+
+	switch typ {
+	case packetConnect:
+		d.string() // protocol name
+		p.protocolLevel = d.byte()
+		v5 = p.protocolLevel == protocolLevel5
+		connectFlags := d.byte()
+		p.cleanStart = connectFlags&0x02 != 0
+		p.keepAlive = d.uint16()
+		if v5 {
+			d.properties(onProperty)
+		}
+		p.clientID = d.string()
+		if connectFlags&0x04 != 0 {
+			if v5 {
+				d.properties(onProperty) // will properties
+			}
+			d.string() // will topic
+			d.binary() // will payload
+		}
+		if connectFlags&0x80 != 0 {
+			p.username = d.string()
+		}
+
+	case packetConnack:
+		p.sessionPresent = d.byte()&0x01 != 0
+		p.reasonCodes = []uint8{d.byte()}
+		if v5 {
+			d.properties(onProperty)
+		}
+
+	case packetPublish:
+		p.dup = flags&0x08 != 0
+		p.qos = (flags >> 1) & 0x03
+		p.retain = flags&0x01 != 0
+		p.topic = d.string()
+		if p.qos > 0 {
+			p.packetID = d.uint16()
+		}
+		if v5 {
+			d.properties(onProperty)
+		}
+		if d.err == nil {
+			p.payloadSize = remainingLen - d.pos
+			p.payload = append([]byte(nil), body[d.pos:min(len(body), d.pos+maxPayload)]...)
+		}
+
+	case packetPuback, packetPubrec, packetPubrel, packetPubcomp:
+		p.packetID = d.uint16()
+		if v5 && d.remaining() > 0 {
+			p.reasonCodes = []uint8{d.byte()}
+			if d.remaining() > 0 {
+				d.properties(onProperty)
+			}
+		}
+
+	case packetSubscribe, packetUnsubscribe:
+		p.packetID = d.uint16()
+		if v5 {
+			d.properties(onProperty)
+		}
+		for d.remaining() > 0 && d.err == nil {
+			p.topicFilters = append(p.topicFilters, d.string())
+			if typ == packetSubscribe {
+				d.byte() // subscription options
+			}
+		}
+
+	case packetSuback, packetUnsuback:
+		p.packetID = d.uint16()
+		if v5 {
+			d.properties(onProperty)
+		}
+		if d.err == nil && d.remaining() > 0 {
+			p.reasonCodes = append([]uint8(nil), d.read(d.remaining())...)
+		}
+
+	case packetDisconnect, packetAuth:
+		if v5 && d.remaining() > 0 {
+			p.reasonCodes = []uint8{d.byte()}
+			if d.remaining() > 0 {
+				d.properties(onProperty)
+			}
+		}
+
+	case packetPingreq, packetPingresp:
+
+	default:
+		return nil, fmt.Errorf("invalid packet type %d", uint8(typ))
+	}
+
+	if d.err != nil {
+		return nil, fmt.Errorf("failed to parse %v packet: %w", typ, d.err)
+	}
+	return p, nil
+}
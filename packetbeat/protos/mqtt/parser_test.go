@@ -0,0 +1,104 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+//go:build !integration
+
+package mqtt
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadVarint(t *testing.T) {
+	for _, tc := range []struct {
+		data  []byte
+		value int
+		n     int
+		err   error
+	}{
+		{data: []byte{0x00}, value: 0, n: 1},
+		{data: []byte{0x7f}, value: 127, n: 1},
+		{data: []byte{0x80, 0x01}, value: 128, n: 2},
+		{data: []byte{0xff, 0x7f, 0x00}, value: 16383, n: 2},
+		{data: []byte{0xff, 0xff, 0xff, 0x7f}, value: 268435455, n: 4},
+		{data: []byte{0x80, 0x80}, err: errTruncated},
+		{data: []byte{0x80, 0x80, 0x80, 0x80, 0x01}, err: errInvalidVarint},
+	} {
+		value, n, err := readVarint(tc.data)
+		assert.Equal(t, tc.err, err, "% x", tc.data)
+		assert.Equal(t, tc.value, value, "% x", tc.data)
+		assert.Equal(t, tc.n, n, "% x", tc.data)
+	}
+}
+
+func TestParseFixedHeader(t *testing.T) {
+	typ, flags, headerLen, remainingLen, err := parseFixedHeader([]byte{0x3b, 0xc8, 0x01})
+	require.NoError(t, err)
+	assert.Equal(t, packetPublish, typ)
+	assert.Equal(t, uint8(0x0b), flags)
+	assert.Equal(t, 3, headerLen)
+	assert.Equal(t, 200, remainingLen)
+
+	_, _, _, _, err = parseFixedHeader([]byte{0x30})
+	assert.Equal(t, errTruncated, err)
+
+	assert.True(t, validFlags(packetPubrel, 0x02))
+	assert.False(t, validFlags(packetPubrel, 0x00))
+	assert.False(t, validFlags(packetPublish, 0x06))
+	assert.False(t, validFlags(0, 0))
+}
+
+func TestParseProperties(t *testing.T) {
+	// Properties of all types, followed by the topic filter.
+	body := append(u16(1), props(
+		[]byte{0x01, 1},
+		[]byte{0x02, 0, 0, 0, 1},
+		[]byte{0x0b, 0x80, 0x01},
+		[]byte{0x03}, str("text/plain"),
+		[]byte{0x09}, str("\x00\x01"),
+		[]byte{0x21}, u16(5),
+		[]byte{0x26}, str("key"), str("value"),
+		[]byte{propReasonString}, str("reason"),
+	)...)
+	body = append(append(body, str("a/b")...), 0)
+	p, err := parsePacket(packetSubscribe, 0x02, body, len(body), protocolLevel5, 0)
+	require.NoError(t, err)
+	assert.Equal(t, uint16(1), p.packetID)
+	assert.Equal(t, "reason", p.reasonString)
+	assert.Equal(t, []string{"a/b"}, p.topicFilters)
+
+	// Parsed as MQTT 3.1.1, the properties are taken for topic filters.
+	_, err = parsePacket(packetSubscribe, 0x02, body, len(body), protocolLevel311, 0)
+	assert.Error(t, err)
+
+	body = append(u16(1), props([]byte{0x7f, 0})...)
+	_, err = parsePacket(packetSuback, 0, body, len(body), protocolLevel5, 0)
+	assert.ErrorIs(t, err, errUnknownProperty)
+}
+
+func TestParsePublishTruncated(t *testing.T) {
+	// Only the beginning of a large message is available.
+	body := append(str("a/b"), "0123456789"...)
+	p, err := parsePacket(packetPublish, 0, body, 100000, protocolLevel311, 4)
+	require.NoError(t, err)
+	assert.Equal(t, "a/b", p.topic)
+	assert.Equal(t, 100000-5, p.payloadSize)
+	assert.Equal(t, []byte("0123"), p.payload)
+}
@@ -0,0 +1,94 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package mqtt
+
+import "strconv"
+
+// reasonNames are the names of the failure reason codes of MQTT 5.0.
+var reasonNames = map[uint8]string{
+	0x80: "Unspecified error",
+	0x81: "Malformed Packet",
+	0x82: "Protocol Error",
+	0x83: "Implementation specific error",
+	0x84: "Unsupported Protocol Version",
+	0x85: "Client Identifier not valid",
+	0x86: "Bad User Name or Password",
+	0x87: "Not authorized",
+	0x88: "Server unavailable",
+	0x89: "Server busy",
+	0x8a: "Banned",
+	0x8b: "Server shutting down",
+	0x8c: "Bad authentication method",
+	0x8d: "Keep Alive timeout",
+	0x8e: "Session taken over",
+	0x8f: "Topic Filter invalid",
+	0x90: "Topic Name invalid",
+	0x91: "Packet Identifier in use",
+	0x92: "Packet Identifier not found",
+	0x93: "Receive Maximum exceeded",
+	0x94: "Topic Alias invalid",
+	0x95: "Packet too large",
+	0x96: "Message rate too high",
+	0x97: "Quota exceeded",
+	0x98: "Administrative action",
+	0x99: "Payload format invalid",
+	0x9a: "Retain not supported",
+	0x9b: "QoS not supported",
+	0x9c: "Use another server",
+	0x9d: "Server moved",
+	0x9e: "Shared Subscriptions not supported",
+	0x9f: "Connection rate exceeded",
+	0xa0: "Maximum connect time",
+	0xa1: "Subscription Identifiers not supported",
+	0xa2: "Wildcard Subscriptions not supported",
+}
+
+// connackReturnNames are the names of the CONNACK return codes of MQTT 3.1
+// and 3.1.1.
+var connackReturnNames = map[uint8]string{
+	1: "Connection Refused, unacceptable protocol version",
+	2: "Connection Refused, identifier rejected",
+	3: "Connection Refused, Server unavailable",
+	4: "Connection Refused, bad user name or password",
+	5: "Connection Refused, not authorized",
+}
+
+// reasonFailure returns the name of a reason code if it reports a failure, or
+// an empty string.
+func reasonFailure(typ packetType, code uint8, level uint8) string {
+	if level != protocolLevel5 {
+		switch {
+		case typ == packetConnack && code != 0:
+			if name, ok := connackReturnNames[code]; ok {
+				return name
+			}
+		case typ == packetSuback && code == 0x80:
+			return "Failure"
+		default:
+			return ""
+		}
+		return "(unknown:" + strconv.Itoa(int(code)) + ")"
+	}
+	if code < 0x80 {
+		return ""
+	}
+	if name, ok := reasonNames[code]; ok {
+		return name
+	}
+	return "(unknown:" + strconv.Itoa(int(code)) + ")"
+}
@@ -0,0 +1,194 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package pgsql
+
+import (
+	"errors"
+
+	"github.com/elastic/beats/v7/libbeat/common"
+)
+
+// maxPreparedStatements is the maximum number of named prepared statements,
+// and of named portals, tracked per connection.
+const maxPreparedStatements = 1000
+
+var errInvalidExtendedQuery = errors.New("invalid extended query message")
+
+// pgsqlConnection is the state of a connection shared by its two streams. It
+// is kept when a stream is dropped.
+type pgsqlConnection struct {
+	// frontendDir is the direction of the messages sent by the frontend,
+	// once frontendKnown is set.
+	frontendDir   uint8
+	frontendKnown bool
+
+	// encrypted is set once the server accepted an SSLRequest or a
+	// GSSENCRequest. The rest of the connection can't be decoded.
+	encrypted bool
+
+	// statements are the queries of the prepared statements, and portals
+	// the statements bound to the portals, by name. The unnamed statement
+	// and portal have an empty name.
+	statements map[string]string
+	portals    map[string]pgsqlPortal
+}
+
+type pgsqlPortal struct {
+	statement string
+	query     string
+	numParams int
+	// known is whether the statement was prepared during the capture.
+	known bool
+}
+
+// pgsqlExecution is an Execute message of an extended query request.
+type pgsqlExecution struct {
+	statement string
+	query     string
+	numParams int
+	notes     []string
+}
+
+func (s *pgsqlStream) connection() *pgsqlConnection {
+	if s.conn == nil {
+		s.conn = &pgsqlConnection{}
+	}
+	return s.conn
+}
+
+// isFrontend returns whether the stream is known to carry the messages sent
+// by the frontend.
+func (s *pgsqlStream) isFrontend() bool {
+	c := s.connection()
+	return c.frontendKnown && c.frontendDir == s.dir
+}
+
+// setFrontend records whether the stream carries the messages of the
+// frontend, after a message type only sent by the frontend or the backend.
+func (s *pgsqlStream) setFrontend(frontend bool) {
+	c := s.connection()
+	if c.frontendKnown {
+		return
+	}
+	c.frontendKnown = true
+	c.frontendDir = s.dir
+	if !frontend {
+		c.frontendDir = 1 - s.dir
+	}
+}
+
+// parseParse parses the body of a Parse message, and records the query of
+// the prepared statement.
+func (c *pgsqlConnection) parseParse(buf []byte) error {
+	name, err := common.ReadString(buf)
+	if err != nil {
+		return errInvalidExtendedQuery
+	}
+	query, err := common.ReadString(buf[len(name)+1:])
+	if err != nil {
+		return errInvalidExtendedQuery
+	}
+	if c.statements == nil {
+		c.statements = make(map[string]string)
+	}
+	if _, exists := c.statements[name]; exists || len(c.statements) < maxPreparedStatements {
+		c.statements[name] = query
+	}
+	return nil
+}
+
+// parseBind parses the body of a Bind message, and records the statement
+// bound to the portal.
+func (c *pgsqlConnection) parseBind(buf []byte) error {
+	portal, err := common.ReadString(buf)
+	if err != nil {
+		return errInvalidExtendedQuery
+	}
+	off := len(portal) + 1
+	statement, err := common.ReadString(buf[off:])
+	if err != nil {
+		return errInvalidExtendedQuery
+	}
+	off += len(statement) + 1
+
+	// skip the parameter format codes (int16 each)
+	if len(buf) < off+2 {
+		return errInvalidExtendedQuery
+	}
+	off += 2 + 2*readCount(buf[off:])
+
+	// read parameter count (int16)
+	if len(buf) < off+2 {
+		return errInvalidExtendedQuery
+	}
+	numParams := readCount(buf[off:])
+
+	query, known := c.statements[statement]
+	if c.portals == nil {
+		c.portals = make(map[string]pgsqlPortal)
+	}
+	if _, exists := c.portals[portal]; exists || len(c.portals) < maxPreparedStatements {
+		c.portals[portal] = pgsqlPortal{
+			statement: statement,
+			query:     query,
+			numParams: numParams,
+			known:     known,
+		}
+	}
+	return nil
+}
+
+// parseExecute parses the body of an Execute message, and returns the
+// execution of the statement bound to the portal.
+func (c *pgsqlConnection) parseExecute(buf []byte) (pgsqlExecution, error) {
+	name, err := common.ReadString(buf)
+	if err != nil {
+		return pgsqlExecution{}, errInvalidExtendedQuery
+	}
+	portal := c.portals[name]
+	exec := pgsqlExecution{
+		statement: portal.statement,
+		query:     portal.query,
+		numParams: portal.numParams,
+	}
+	if !portal.known {
+		// The statement was prepared, or the portal bound, before the
+		// capture started.
+		exec.notes = []string{"Prepared statement not seen"}
+	}
+	return exec, nil
+}
+
+// parseClose parses the body of a Close message, and forgets the closed
+// prepared statement or portal.
+func (c *pgsqlConnection) parseClose(buf []byte) error {
+	if len(buf) < 1 {
+		return errInvalidExtendedQuery
+	}
+	name, err := common.ReadString(buf[1:])
+	if err != nil {
+		return errInvalidExtendedQuery
+	}
+	switch buf[0] {
+	case 'S':
+		delete(c.statements, name)
+	case 'P':
+		delete(c.portals, name)
+	}
+	return nil
+}
@@ -32,5 +32,5 @@ func init() {
 // AssetPgsql returns asset data.
 // This is the base64 encoded zlib format compressed contents of protos/pgsql.
 func AssetPgsql() string {
-	return "eJzEkcFu6jAURPf5ihHrBx+QBRJCPAkJtRTYI0gmwapjB1+bKn9fhaTF0GbBqllF1z4zR9djvLNJUZdy1gnglddMMVpb8aXj9m01SoCckjlVe2VNimkCALcLY6mZqUJl4IXGo1DUuUwS9H/p9f4Y5lDxVtR+vqmZonQ21P0kJmKKzlm3z2zO76MHq92JkVMHoAUmEdEVamvKZKCiosihfK6lZyZDmcILnfLNU6FfUKxfWxF11NxfDjowWlPXudhsXjcPs/+z3Wz1MFvPXpbzn7ImVPtu/0Oi07ugZQF/IraL1WK+wznQNVAFJGQZRYqg/8GflHRvCiUQenh7hUyojnR3cbboXx+OPjjD/JeFto7OfvyZYdsd+30GAAD//yg57bo="
+	return "eJzEk8GO2jAYhO95itGeCw+Qw0qrFZWQULtluaOQTILVxM76/003b185AdZLQS2nyjlEtmfm0+TPDD855OgbeWszQI22zPHw4kQbz9cfq4cMqCilN70aZ3M8ZgDwcWEmPUtTmxI80Cpqw7aSeYbjWz7en8EWHT+C4tKhZ47Gu9Afd1JFqqL3zm9LV/F8dEG12TNhmgSIgnmimAJbZ5vsRkRHkaK5L+Womd/yFB7ojQ53mZ5EKX7vRMyu5fZQtIFJTfGZYbFef19f7H192jytLvZenr4tn/+EtaHbTv3fAn1MDoBlDd0Tr4vV4nmDt0A/wNSQUJYUqUP7Bbo3Mk0BjECoUDeKbOh29J/sXH2cF3hq8JbVlUIjo3e//hthzE75rgL2hS86uT52/8S9OefD1RjtqPSCnQu2OhH2nn3hWUG0UHbx1+M7y6CsPrntBhQWfFfaitVUw5Vqzy53MBYdI+FfaG4QnJziWmqs3zodh6R2fvwGwcbJTCzn2e8BADd8W58="
 }
@@ -65,22 +65,24 @@ func (pgsql *pgsqlPlugin) parseMessageStart(s *pgsqlStream) (bool, bool) {
 			return pgsql.parseCommand(s)
 		}
 
-		// In case of Commands: StartupMessage, SSLRequest, CancelRequest that don't have
-		// their type in the first byte
+		// In case of Commands: StartupMessage, SSLRequest, GSSENCRequest, CancelRequest
+		// that don't have their type in the first byte
+		s.setFrontend(true)
 
 		// check buffer available
-		if len(s.data[s.parseOffset:]) <= length {
+		if len(s.data[s.parseOffset:]) < length {
 			pgsql.detailf("Wait for more data 1")
 			return true, false
 		}
 
 		// ignore non SSLRequest commands
-		if command != sslRequest {
+		if command != sslRequest && command != gssEncRequest {
 			s.parseOffset += length
 			continue
 		}
 
-		// if SSLRequest is received, expect for one byte reply (S or N)
+		// if SSLRequest is received, expect for one byte reply (S or N), or
+		// (G or N) for a GSSENCRequest
 		m.start = s.parseOffset
 		s.parseOffset += length
 		m.end = s.parseOffset
@@ -98,7 +100,7 @@ func (pgsql *pgsqlPlugin) parseCommand(s *pgsqlStream) (bool, bool) {
 
 	if s.expectSSLResponse {
 		// SSLRequest was received in the other stream
-		if typ == 'N' || typ == 'S' {
+		if typ == 'N' || typ == 'S' || typ == 'G' {
 			m := s.message
 
 			// one byte reply to SSLRequest
@@ -108,6 +110,7 @@ func (pgsql *pgsqlPlugin) parseCommand(s *pgsqlStream) (bool, bool) {
 			m.end = s.parseOffset
 			m.isSSLResponse = true
 			m.size = uint64(m.end - m.start)
+			s.connection().encrypted = typ != 'N'
 
 			return true, true
 		}
@@ -127,8 +130,29 @@ func (pgsql *pgsqlPlugin) parseCommand(s *pgsqlStream) (bool, bool) {
 
 	pgsql.detailf("Pgsql type %c, length=%d", typ, length)
 
+	if s.isFrontend() {
+		switch typ {
+		case 'Q':
+			return pgsql.parseSimpleQuery(s, length)
+		case 'P', 'B', 'D', 'E', 'C', 'S', 'H':
+			return pgsql.parseExtReq(s)
+		default:
+			if !pgsqlValidType(typ) {
+				pgsql.detailf("invalid frame type: '%c'", typ)
+				return false, false
+			}
+			return pgsql.parseSkipMessage(s, length)
+		}
+	}
+
+	switch typ {
+	case 'T', 'Z', 'R', '1', '2':
+		s.setFrontend(false)
+	}
+
 	switch typ {
 	case 'Q':
+		s.setFrontend(true)
 		return pgsql.parseSimpleQuery(s, length)
 	case 'T':
 		return pgsql.parseRowDescription(s, length)
@@ -140,10 +164,17 @@ func (pgsql *pgsqlPlugin) parseCommand(s *pgsqlStream) (bool, bool) {
 		return pgsql.parseReadyForQuery(s, length)
 	case 'E':
 		return pgsql.parseErrorResponse(s, length)
-	case 'P':
-		return pgsql.parseExtReq(s, length)
-	case '1':
+	case 'P', 'B':
+		s.setFrontend(true)
+		return pgsql.parseExtReq(s)
+	case '1', '2':
 		return pgsql.parseExtResp(s, length)
+	case 'D':
+		if s.connection().frontendKnown {
+			// DataRow of an Execute whose portal wasn't described
+			return pgsql.parseDataStart(s)
+		}
+		return pgsql.parseSkipMessage(s, length)
 	default:
 		if !pgsqlValidType(typ) {
 			pgsql.detailf("invalid frame type: '%c'", typ)
@@ -286,32 +317,14 @@ func (pgsql *pgsqlPlugin) parseErrorResponse(s *pgsqlStream, length int) (bool,
 	return true, true
 }
 
-func (pgsql *pgsqlPlugin) parseExtReq(s *pgsqlStream, length int) (bool, bool) {
-	// Ready for query -> Parse for an extended query request
-	pgsql.detailf("Parse")
+func (pgsql *pgsqlPlugin) parseExtReq(s *pgsqlStream) (bool, bool) {
+	// Parse, Bind or any other message starting an extended query request
+	pgsql.detailf("Extended query request")
 
 	m := s.message
 	m.start = s.parseOffset
 	m.isRequest = true
 
-	s.parseOffset++ // type
-	s.parseOffset += length
-	m.end = s.parseOffset
-	m.size = uint64(m.end - m.start)
-	m.toExport = true
-
-	query, err := common.ReadString(s.data[m.start+6:])
-	if err != nil {
-		pgsql.detailf("Invalid extended query request")
-		return false, false
-	}
-	m.query = query
-	pgsql.detailf("Parse in an extended query request: %s", m.query)
-
-	// Ignore SET statement
-	if strings.HasPrefix(m.query, "SET ") {
-		m.toExport = false
-	}
 	s.parseState = pgsqlExtendedQueryState
 	return pgsql.parseMessageExtendedQuery(s)
 }
@@ -333,6 +346,18 @@ func (pgsql *pgsqlPlugin) parseExtResp(s *pgsqlStream, length int) (bool, bool)
 	return pgsql.parseMessageData(s)
 }
 
+func (pgsql *pgsqlPlugin) parseDataStart(s *pgsqlStream) (bool, bool) {
+	// DataRow without RowDescription, the format of the columns is unknown
+	m := s.message
+	m.start = s.parseOffset
+	m.isRequest = false
+	m.isOK = true
+	m.toExport = true
+
+	s.parseState = pgsqlGetDataState
+	return pgsql.parseMessageData(s)
+}
+
 func (pgsql *pgsqlPlugin) parseSkipMessage(s *pgsqlStream, length int) (bool, bool) {
 	// TODO: add info from NoticeResponse in case there are warning messages for a query
 	// ignore command
@@ -500,13 +525,47 @@ func (pgsql *pgsqlPlugin) parseMessageData(s *pgsqlStream) (bool, bool) {
 			pgsql.detailf("Rows: %s", m.rows)
 
 			return true, true
-		case '2':
-			// Parse completion -> Bind completion for an extended query response
+		case '1', '2', '3', 't', 'n':
+			// ParseComplete, BindComplete, CloseComplete, ParameterDescription
+			// and NoData of an extended query response
 
 			// skip type
 			s.parseOffset++
 			s.parseOffset += length
+		case 'I', 's':
+			// EmptyQueryResponse or PortalSuspended -> end of the execution
+			s.parseOffset++
+			s.parseOffset += length
+			m.end = s.parseOffset
+			m.size = uint64(m.end - m.start)
 			s.parseState = pgsqlStartState
+
+			return true, true
+		case 'E':
+			// ErrorResponse -> the rest of the request is skipped
+			m.isOK = false
+			m.isError = true
+			m.toExport = true
+
+			s.parseOffset++ // type
+			pgsql.parseError(s, s.data[s.parseOffset+4:s.parseOffset+length])
+			s.parseOffset += length
+			m.end = s.parseOffset
+			m.size = uint64(m.end - m.start)
+			s.parseState = pgsqlStartState
+
+			return true, true
+		case 'Z':
+			// ReadyForQuery -> end of a request without Execute, like the
+			// Describe of a prepared statement
+			s.parseOffset++
+			s.parseOffset += length
+			m.end = s.parseOffset
+			m.size = uint64(m.end - m.start)
+			m.toExport = false
+			s.parseState = pgsqlStartState
+
+			return true, true
 		case 'T':
 			return pgsql.parseRowDescription(s, length)
 		default:
@@ -532,12 +591,12 @@ func (pgsql *pgsqlPlugin) parseDataRow(s *pgsqlStream, buf []byte) error {
 	rowLength := 0
 
 	for i := 0; i < fieldCount; i++ {
-		if len(buf) <= off {
+		if len(buf) < off+4 {
 			return errFieldBufferShort
 		}
 
-		// read column length (int32)
-		columnLength := readLength(buf[off:])
+		// read column length (int32, -1 for NULL)
+		columnLength := int(int32(common.BytesNtohl(buf[off:])))
 		off += 4
 
 		if columnLength > 0 && columnLength > len(buf[off:]) {
@@ -548,12 +607,12 @@ func (pgsql *pgsqlPlugin) parseDataRow(s *pgsqlStream, buf []byte) error {
 
 		// read column value (byten)
 		var columnValue []byte
-		if m.fieldsFormat[i] == 0 {
-			// field value in text format
-			if columnLength > 0 {
+		if columnLength > 0 {
+			if i < len(m.fieldsFormat) && m.fieldsFormat[i] == 0 {
+				// field value in text format
 				columnValue = buf[off : off+columnLength]
-				off += columnLength
 			}
+			off += columnLength
 		}
 
 		if rowLength < pgsql.maxRowLength {
@@ -572,6 +631,13 @@ func (pgsql *pgsqlPlugin) parseDataRow(s *pgsqlStream, buf []byte) error {
 	}
 
 	m.numberOfRows++
+	if len(m.fieldsFormat) < fieldCount {
+		// The RowDescription of an extended query response is only sent
+		// if the portal is described, so the format of the columns isn't
+		// known.
+		m.numberOfFields = fieldCount
+		return nil
+	}
 	if len(m.rows) < pgsql.maxStoreRows {
 		m.rows = append(m.rows, rows)
 	}
@@ -582,14 +648,16 @@ func (pgsql *pgsqlPlugin) parseDataRow(s *pgsqlStream, buf []byte) error {
 func (pgsql *pgsqlPlugin) parseMessageExtendedQuery(s *pgsqlStream) (bool, bool) {
 	pgsql.detailf("parseMessageExtendedQuery")
 
-	// An extended query request contains:
-	// Parse
+	// An extended query request contains, for each statement:
+	// Parse (optional if the statement is already prepared)
 	// Bind
-	// Describe
+	// Describe (optional)
 	// Execute
-	// Sync
+	// and ends with a Sync, or a Flush. Prepared statements and portals can
+	// be closed with Close.
 
 	m := s.message
+	conn := s.connection()
 
 	for len(s.data[s.parseOffset:]) >= 5 {
 		// read type
@@ -607,37 +675,34 @@ func (pgsql *pgsqlPlugin) parseMessageExtendedQuery(s *pgsqlStream) (bool, bool)
 			pgsql.detailf("Wait for more data")
 			return true, false
 		}
+		body := s.data[s.parseOffset+5 : s.parseOffset+length+1]
 
+		var err error
 		switch typ {
+		case 'P':
+			err = conn.parseParse(body)
 		case 'B':
-			// Parse -> Bind
-
-			// skip type
-			s.parseOffset++
-			s.parseOffset += length
-			// TODO: pgsql.parseBind(s)
+			err = conn.parseBind(body)
 		case 'D':
-			// Bind -> Describe
-
-			// skip type
-			s.parseOffset++
-			s.parseOffset += length
-			// TODO: pgsql.parseDescribe(s)
+			// Describe
 		case 'E':
-			// Bind(or Describe) -> Execute
-
-			// skip type
-			s.parseOffset++
-			s.parseOffset += length
-			// TODO: pgsql.parseExecute(s)
-		case 'S':
-			// Execute -> Sync
+			var exec pgsqlExecution
+			exec, err = conn.parseExecute(body)
+			if err == nil {
+				pgsql.detailf("Execute in an extended query request: %s", exec.query)
+				m.executions = append(m.executions, exec)
+			}
+		case 'C':
+			err = conn.parseClose(body)
+		case 'S', 'H':
+			// Sync or Flush -> end of the request
 
 			// skip type
 			s.parseOffset++
 			s.parseOffset += length
 			m.end = s.parseOffset
 			m.size = uint64(m.end - m.start)
+			m.toExport = len(m.executions) > 0
 			s.parseState = pgsqlStartState
 
 			return true, true
@@ -647,6 +712,15 @@ func (pgsql *pgsqlPlugin) parseMessageExtendedQuery(s *pgsqlStream) (bool, bool)
 			s.parseState = pgsqlStartState
 			return false, false
 		}
+		if err != nil {
+			pgsql.detailf("Invalid extended query request: %v", err)
+			s.parseState = pgsqlStartState
+			return false, false
+		}
+
+		// skip type
+		s.parseOffset++
+		s.parseOffset += length
 	}
 
 	return true, false
@@ -672,6 +746,10 @@ func (pgsql *pgsqlPlugin) isSpecialCommand(data []byte) (bool, int, int) {
 		// SSL Request
 		pgsql.debugf("SSL Request, length=%d", length)
 		return true, length, sslRequest
+	} else if length == 8 && code == 80877104 {
+		// GSSENC Request
+		pgsql.debugf("GSSENC Request, length=%d", length)
+		return true, length, gssEncRequest
 	} else if code == 196608 {
 		// Startup Message
 		pgsql.debugf("Startup Message, length=%d", length)
@@ -702,12 +780,17 @@ func pgsqlString(b []byte, sz int) (string, error) {
 	return string(b[:sz-1]), nil
 }
 
+// isTLSRecord returns whether data starts with a TLS handshake record.
+func isTLSRecord(data []byte) bool {
+	return len(data) >= 3 && data[0] == 0x16 && data[1] == 0x03 && data[2] <= 0x04
+}
+
 func pgsqlValidType(t byte) bool {
 	switch t {
 	case '1', '2', '3',
 		'A', 'B', 'C', 'D', 'E', 'F', 'G', 'H', 'I', 'K',
 		'N', 'P', 'Q', 'R', 'S', 'T', 'V', 'W', 'X', 'Z',
-		'c', 'd', 'f', 'n', 'p', 's', 't':
+		'c', 'd', 'f', 'n', 'p', 's', 't', 'v':
 		return true
 	default:
 		return false
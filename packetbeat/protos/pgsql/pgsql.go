@@ -50,6 +50,10 @@ type pgsqlPlugin struct {
 	transactions       *common.Cache
 	transactionTimeout time.Duration
 
+	// batches is the number of extended query requests received, used to
+	// identify the transactions of a request.
+	batches uint64
+
 	results protos.Reporter
 	watcher *procs.ProcessesWatcher
 
@@ -62,12 +66,13 @@ type pgsqlMessage struct {
 	start         int
 	end           int
 	isSSLResponse bool
-	isSSLRequest  bool
+	isSSLRequest  bool // SSLRequest or GSSENCRequest
 	toExport      bool
 
 	ts             time.Time
 	isRequest      bool
 	query          string
+	executions     []pgsqlExecution
 	size           uint64
 	fields         []string
 	fieldsFormat   []byte
@@ -99,6 +104,14 @@ type pgsqlTransaction struct {
 	notes    []string
 	isError  bool
 
+	// batch identifies the extended query request of the transaction. The
+	// statements executed after an error in a request are skipped by the
+	// server, up to the next Sync.
+	batch uint64
+	// ignored transactions are matched with their response, but not
+	// published.
+	ignored bool
+
 	pgsql mapstr.M
 
 	requestRaw  string
@@ -107,6 +120,8 @@ type pgsqlTransaction struct {
 
 type pgsqlStream struct {
 	data []byte
+	dir  uint8
+	conn *pgsqlConnection
 
 	parseOffset       int
 	parseState        int
@@ -124,6 +139,7 @@ const (
 
 const (
 	sslRequest = iota
+	gssEncRequest
 	startupMessage
 	cancelRequest
 )
@@ -231,6 +247,7 @@ func getQueryMethod(q string) string {
 
 type pgsqlPrivateData struct {
 	data [2]*pgsqlStream
+	conn *pgsqlConnection
 }
 
 func (pgsql *pgsqlPlugin) ConnectionTimeout() time.Duration {
@@ -249,9 +266,18 @@ func (pgsql *pgsqlPlugin) Parse(pkt *protos.Packet, tcptuple *common.TCPTuple,
 		}
 	}
 
+	if priv.conn == nil {
+		priv.conn = &pgsqlConnection{}
+	}
+	if priv.conn.encrypted {
+		return priv
+	}
+
 	if priv.data[dir] == nil {
 		priv.data[dir] = &pgsqlStream{
 			data:    pkt.Payload,
+			dir:     dir,
+			conn:    priv.conn,
 			message: &pgsqlMessage{ts: pkt.Ts},
 		}
 		pgsql.detailf("New stream created")
@@ -271,6 +297,14 @@ func (pgsql *pgsqlPlugin) Parse(pkt *protos.Packet, tcptuple *common.TCPTuple,
 	if priv.data[1-dir] != nil && priv.data[1-dir].seenSSLRequest {
 		stream.expectSSLResponse = true
 	}
+	if isTLSRecord(stream.data) {
+		// TLS without SSLRequest, as negotiated by the clients with
+		// sslnegotiation=direct.
+		pgsql.debugf("TLS handshake, ignoring the encrypted connection")
+		priv.conn.encrypted = true
+		priv.data = [2]*pgsqlStream{}
+		return priv
+	}
 
 	for len(stream.data) > 0 {
 
@@ -298,6 +332,11 @@ func (pgsql *pgsqlPlugin) Parse(pkt *protos.Packet, tcptuple *common.TCPTuple,
 				// SSL request answered
 				stream.expectSSLResponse = false
 				priv.data[1-dir].seenSSLRequest = false
+				if priv.conn.encrypted {
+					pgsql.debugf("Encryption accepted, ignoring the encrypted connection")
+					priv.data = [2]*pgsqlStream{}
+					return priv
+				}
 			} else {
 				if stream.message.toExport {
 					pgsql.handlePgsql(pgsql, stream.message, tcptuple, dir, msg)
@@ -323,7 +362,7 @@ func messageHasEnoughData(msg *pgsqlMessage) bool {
 		return false
 	}
 	if msg.isRequest {
-		return len(msg.query) > 0
+		return len(msg.query) > 0 || len(msg.executions) > 0
 	}
 	return len(msg.rows) > 0
 }
@@ -388,40 +427,62 @@ var handlePgsql = func(pgsql *pgsqlPlugin, m *pgsqlMessage, tcptuple *common.TCP
 func (pgsql *pgsqlPlugin) receivedPgsqlRequest(msg *pgsqlMessage) {
 	tuple := msg.tcpTuple
 
+	transList := pgsql.getTransaction(tuple.Hashable())
+	if transList == nil {
+		transList = []*pgsqlTransaction{}
+	}
+
+	if len(msg.executions) > 0 {
+		// one transaction per Execute of an extended query request
+		pgsql.batches++
+		for _, exec := range msg.executions {
+			trans := pgsql.newTransaction(msg, exec.query)
+			trans.batch = pgsql.batches
+			trans.pgsql["num_params"] = exec.numParams
+			if exec.statement != "" {
+				trans.pgsql["statement"] = exec.statement
+			}
+			trans.notes = append(trans.notes, exec.notes...)
+			// Ignore SET statement
+			trans.ignored = strings.HasPrefix(exec.query, "SET ")
+			transList = append(transList, trans)
+		}
+		pgsql.transactions.Put(tuple.Hashable(), transList)
+		return
+	}
+
 	// parse the query, as it might contain a list of pgsql command
 	// separated by ';'
 	queries := pgsqlQueryParser(msg.query)
 
 	pgsql.debugf("Queries (%d) :%s", len(queries), queries)
 
-	transList := pgsql.getTransaction(tuple.Hashable())
-	if transList == nil {
-		transList = []*pgsqlTransaction{}
-	}
-
 	for _, query := range queries {
+		transList = append(transList, pgsql.newTransaction(msg, query))
+	}
+	pgsql.transactions.Put(tuple.Hashable(), transList)
+}
 
-		trans := &pgsqlTransaction{tuple: tuple}
+func (pgsql *pgsqlPlugin) newTransaction(msg *pgsqlMessage, query string) *pgsqlTransaction {
+	trans := &pgsqlTransaction{tuple: msg.tcpTuple}
 
-		trans.ts = msg.ts
-		trans.src, trans.dst = common.MakeEndpointPair(msg.tcpTuple.BaseTuple, msg.cmdlineTuple)
+	trans.ts = msg.ts
+	trans.src, trans.dst = common.MakeEndpointPair(msg.tcpTuple.BaseTuple, msg.cmdlineTuple)
 
-		if msg.direction == tcp.TCPDirectionReverse {
-			trans.src, trans.dst = trans.dst, trans.src
-		}
+	if msg.direction == tcp.TCPDirectionReverse {
+		trans.src, trans.dst = trans.dst, trans.src
+	}
 
-		trans.pgsql = mapstr.M{}
-		trans.query = query
-		trans.method = getQueryMethod(query)
-		trans.bytesIn = msg.size
+	trans.pgsql = mapstr.M{}
+	trans.query = query
+	trans.method = getQueryMethod(query)
+	trans.bytesIn = msg.size
 
-		trans.notes = msg.notes
+	trans.notes = append([]string(nil), msg.notes...)
 
-		trans.requestRaw = query
+	trans.requestRaw = query
 
-		transList = append(transList, trans)
-	}
-	pgsql.transactions.Put(tuple.Hashable(), transList)
+	return trans
 }
 
 func (pgsql *pgsqlPlugin) receivedPgsqlResponse(msg *pgsqlMessage) {
@@ -461,7 +522,13 @@ func (pgsql *pgsqlPlugin) receivedPgsqlResponse(msg *pgsqlMessage) {
 
 	trans.notes = append(trans.notes, msg.notes...)
 
-	pgsql.publishTransaction(trans)
+	if !trans.ignored {
+		pgsql.publishTransaction(trans)
+	}
+
+	if msg.isError && trans.batch != 0 {
+		pgsql.skipBatch(tuple, trans.batch)
+	}
 
 	pgsql.debugf("Postgres transaction completed: %s\n%s", trans.pgsql, trans.responseRaw)
 }
@@ -504,6 +571,17 @@ func (pgsql *pgsqlPlugin) publishTransaction(t *pgsqlTransaction) {
 	pgsql.results(evt)
 }
 
+// skipBatch drops the transactions of an extended query request following
+// an error, as the server skips their statements.
+func (pgsql *pgsqlPlugin) skipBatch(tuple common.TCPTuple, batch uint64) {
+	transList := pgsql.getTransaction(tuple.Hashable())
+	for len(transList) > 0 && transList[0].batch == batch {
+		trans := pgsql.removeTransaction(transList, tuple, 0)
+		pgsql.debugf("Statement skipped after an error: %s", trans.query)
+		transList = pgsql.getTransaction(tuple.Hashable())
+	}
+}
+
 func (pgsql *pgsqlPlugin) removeTransaction(transList []*pgsqlTransaction,
 	tuple common.TCPTuple, index int,
 ) *pgsqlTransaction {
@@ -20,6 +20,7 @@
 package pgsql
 
 import (
+	"encoding/binary"
 	"encoding/hex"
 	"net"
 	"testing"
@@ -385,3 +386,238 @@ func Test_gap_in_response(t *testing.T) {
 		assert.Equal(t, m, "Packet loss while capturing the response")
 	}
 }
+
+// Helpers to encode the messages of the protocol.
+
+func pgsqlMsg(typ byte, parts ...[]byte) []byte {
+	var body []byte
+	for _, p := range parts {
+		body = append(body, p...)
+	}
+	return append(binary.BigEndian.AppendUint32([]byte{typ}, uint32(len(body)+4)), body...)
+}
+
+func cstring(s string) []byte {
+	return append([]byte(s), 0)
+}
+
+func int16s(v ...int) []byte {
+	var b []byte
+	for _, x := range v {
+		b = binary.BigEndian.AppendUint16(b, uint16(x))
+	}
+	return b
+}
+
+func int32s(v ...int) []byte {
+	var b []byte
+	for _, x := range v {
+		b = binary.BigEndian.AppendUint32(b, uint32(x))
+	}
+	return b
+}
+
+func parseMsg(statement, query string, numParams int) []byte {
+	return pgsqlMsg('P', cstring(statement), cstring(query), int16s(numParams), make([]byte, 4*numParams))
+}
+
+func bindMsg(portal, statement string, params ...string) []byte {
+	b := append(cstring(portal), cstring(statement)...)
+	b = append(b, int16s(1, 0, len(params))...)
+	for _, p := range params {
+		b = append(b, int32s(len(p))...)
+		b = append(b, p...)
+	}
+	return pgsqlMsg('B', b, int16s(1, 1))
+}
+
+func executeMsg(portal string) []byte {
+	return pgsqlMsg('E', cstring(portal), int32s(0))
+}
+
+func concat(msgs ...[]byte) []byte {
+	var b []byte
+	for _, m := range msgs {
+		b = append(b, m...)
+	}
+	return b
+}
+
+var (
+	syncMsg          = pgsqlMsg('S')
+	parseComplete    = pgsqlMsg('1')
+	bindComplete     = pgsqlMsg('2')
+	readyForQuery    = pgsqlMsg('Z', []byte{'I'})
+	rowDescription   = pgsqlMsg('T', int16s(1), cstring("id"), int32s(0), int16s(1), int32s(23), int16s(4), int32s(-1), int16s(0))
+	binaryDataRow    = pgsqlMsg('D', int16s(2), int32s(4), int32s(42), int32s(-1))
+	textDataRow      = pgsqlMsg('D', int16s(1), int32s(2), []byte("42"))
+	selectComplete   = pgsqlMsg('C', cstring("SELECT 1"))
+	uniqueViolation  = pgsqlMsg('E', []byte("SERROR\x00C23505\x00Mduplicate key\x00\x00"))
+	authenticationOK = pgsqlMsg('R', int32s(0))
+)
+
+type testConn struct {
+	pgsql   *pgsqlPlugin
+	tuple   *common.TCPTuple
+	private protos.ProtocolData
+}
+
+func (c *testConn) send(dir uint8, data []byte) {
+	c.private = c.pgsql.Parse(&protos.Packet{Ts: time.Now(), Payload: data}, c.tuple, dir, c.private)
+}
+
+func TestPgsqlParser_extendedQuery(t *testing.T) {
+	store := &eventStore{}
+	c := &testConn{pgsql: pgsqlModForTests(store), tuple: testTCPTuple()}
+
+	// prepare a named statement, and execute it twice in a pipeline
+	query := "SELECT id FROM users WHERE name = $1"
+	c.send(0, concat(
+		parseMsg("stmt1", query, 1),
+		pgsqlMsg('D', []byte{'S'}, cstring("stmt1")),
+		bindMsg("", "stmt1", "alice"), pgsqlMsg('D', []byte{'P'}, cstring("")), executeMsg(""),
+		bindMsg("", "stmt1", "bob"), executeMsg(""),
+		syncMsg))
+	assert.Empty(t, store.events)
+
+	c.send(1, concat(
+		parseComplete, pgsqlMsg('t', int16s(1), int32s(25)), rowDescription,
+		bindComplete, rowDescription, textDataRow, selectComplete,
+		bindComplete, textDataRow, selectComplete,
+		readyForQuery))
+
+	if assert.Len(t, store.events, 2) {
+		for _, event := range store.events {
+			fields := event.Fields
+			assert.Equal(t, query, fields["query"])
+			assert.Equal(t, "SELECT", fields["method"])
+			assert.Equal(t, "OK", fields["status"])
+			pgsql := fields["pgsql"].(mapstr.M)
+			assert.Equal(t, 1, pgsql["num_params"])
+			assert.Equal(t, "stmt1", pgsql["statement"])
+			assert.Equal(t, 1, pgsql["num_rows"])
+		}
+	}
+	store.events = nil
+
+	// execute the prepared statement again, without describing the portal
+	c.send(0, concat(bindMsg("", "stmt1", "carol"), executeMsg(""), syncMsg))
+	c.send(1, concat(bindComplete, binaryDataRow, binaryDataRow, selectComplete, readyForQuery))
+
+	fields := expectTransaction(t, store)
+	assert.Equal(t, query, fields["query"])
+	pgsql := fields["pgsql"].(mapstr.M)
+	assert.Equal(t, 2, pgsql["num_rows"])
+	assert.Equal(t, 2, pgsql["num_fields"])
+
+	// close the statement
+	c.send(0, concat(pgsqlMsg('C', []byte{'S'}, cstring("stmt1")), syncMsg))
+	c.send(1, concat(pgsqlMsg('3'), readyForQuery))
+	assert.Empty(t, store.events)
+	assert.Empty(t, c.private.(pgsqlPrivateData).conn.statements)
+	assert.Nil(t, c.pgsql.getTransaction(c.tuple.Hashable()))
+}
+
+func TestPgsqlParser_extendedQueryError(t *testing.T) {
+	store := &eventStore{}
+	c := &testConn{pgsql: pgsqlModForTests(store), tuple: testTCPTuple()}
+
+	// the second statement isn't executed after the error of the first one
+	c.send(0, concat(
+		parseMsg("", "INSERT INTO users (name) VALUES ($1)", 1), bindMsg("", "", "alice"), executeMsg(""),
+		parseMsg("", "SELECT count(*) FROM users", 0), bindMsg("", ""), executeMsg(""),
+		syncMsg))
+	c.send(1, concat(parseComplete, bindComplete, uniqueViolation, readyForQuery))
+
+	fields := expectTransaction(t, store)
+	assert.Equal(t, "INSERT", fields["method"])
+	assert.Equal(t, "Error", fields["status"])
+	assert.Equal(t, "23505", fields["pgsql"].(mapstr.M)["error_code"])
+	assert.Empty(t, store.events)
+	assert.Nil(t, c.pgsql.getTransaction(c.tuple.Hashable()))
+
+	// the following request is matched with its response
+	c.send(0, concat(parseMsg("", "SELECT 1", 0), bindMsg("", ""), executeMsg(""), syncMsg))
+	c.send(1, concat(parseComplete, bindComplete, textDataRow, selectComplete, readyForQuery))
+	fields = expectTransaction(t, store)
+	assert.Equal(t, "SELECT 1", fields["query"])
+	assert.Equal(t, "OK", fields["status"])
+}
+
+func TestPgsqlParser_preparedStatementNotSeen(t *testing.T) {
+	store := &eventStore{}
+	c := &testConn{pgsql: pgsqlModForTests(store), tuple: testTCPTuple()}
+
+	c.send(0, concat(bindMsg("", "stmt7", "1", "2"), executeMsg(""), syncMsg))
+	c.send(1, concat(bindComplete, pgsqlMsg('C', cstring("UPDATE 1")), readyForQuery))
+
+	fields := expectTransaction(t, store)
+	assert.Equal(t, "", fields["query"])
+	pgsql := fields["pgsql"].(mapstr.M)
+	assert.Equal(t, 2, pgsql["num_params"])
+	assert.Equal(t, "stmt7", pgsql["statement"])
+	if m, err := fields.GetValue("error.message"); assert.NoError(t, err) {
+		assert.Equal(t, "Prepared statement not seen", m)
+	}
+}
+
+func TestPgsqlParser_scramAuthentication(t *testing.T) {
+	store := &eventStore{}
+	c := &testConn{pgsql: pgsqlModForTests(store), tuple: testTCPTuple()}
+
+	startup := concat(int32s(0), int32s(196608), cstring("user"), cstring("alice"), []byte{0})
+	binary.BigEndian.PutUint32(startup, uint32(len(startup)))
+	c.send(0, startup)
+	c.send(1, pgsqlMsg('R', int32s(10), cstring("SCRAM-SHA-256"), []byte{0}))
+	clientFirst := "n,,n=,r=rOprNGfwEbeRWgbNEkqO"
+	c.send(0, pgsqlMsg('p', cstring("SCRAM-SHA-256"), int32s(len(clientFirst)), []byte(clientFirst)))
+	c.send(1, pgsqlMsg('R', int32s(11), []byte("r=rOprNGfwEbeRWgbNEkqO%hvYDpWUa2RaTCAfuxFIlj)hNlF,s=W22ZaJ0SNY7soEsUEjb6gQ==,i=4096")))
+	c.send(0, pgsqlMsg('p', []byte("c=biws,r=rOprNGfwEbeRWgbNEkqO%hvYDpWUa2RaTCAfuxFIlj)hNlF,p=dHzbZapWIk4jUhN+Ute9ytag9zjfMHgsqmmiz7AndVQ=")))
+	c.send(1, concat(
+		pgsqlMsg('R', int32s(12), []byte("v=6rriTRBi23WpRR/wtup+mMhUZUn/dB5nLTJRsjl95G4=")),
+		authenticationOK,
+		pgsqlMsg('S', cstring("server_version"), cstring("17.2")),
+		pgsqlMsg('K', int32s(1234, 5678)),
+		readyForQuery))
+	assert.Empty(t, store.events)
+
+	c.send(0, pgsqlMsg('Q', cstring("SELECT 1")))
+	c.send(1, concat(rowDescription, textDataRow, selectComplete, readyForQuery))
+	fields := expectTransaction(t, store)
+	assert.Equal(t, "SELECT 1", fields["query"])
+	assert.Equal(t, "OK", fields["status"])
+}
+
+func TestPgsqlParser_sslRequest(t *testing.T) {
+	tlsClientHello := []byte{0x16, 0x03, 0x01, 0x00, 0x05, 0x01, 0x00, 0x00, 0x01, 0x00}
+	sslRequest := int32s(8, 80877103)
+
+	for name, test := range map[string]struct {
+		response byte
+		events   int
+	}{
+		"accepted": {response: 'S', events: 0},
+		"refused":  {response: 'N', events: 1},
+	} {
+		t.Run(name, func(t *testing.T) {
+			store := &eventStore{}
+			c := &testConn{pgsql: pgsqlModForTests(store), tuple: testTCPTuple()}
+
+			c.send(0, sslRequest)
+			c.send(1, []byte{test.response})
+			if test.response == 'S' {
+				c.send(0, tlsClientHello)
+				assert.True(t, c.private.(pgsqlPrivateData).conn.encrypted)
+			}
+			c.send(0, pgsqlMsg('Q', cstring("SELECT 1")))
+			c.send(1, concat(rowDescription, textDataRow, selectComplete, readyForQuery))
+			assert.Len(t, store.events, test.events)
+		})
+	}
+
+	// TLS without SSLRequest
+	store := &eventStore{}
+	c := &testConn{pgsql: pgsqlModForTests(store), tuple: testTCPTuple()}
+	c.send(0, tlsClientHello)
+	assert.True(t, c.private.(pgsqlPrivateData).conn.encrypted)
+}
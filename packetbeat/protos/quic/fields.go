@@ -0,0 +1,36 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+// Code generated by beats/dev-tools/cmd/asset/asset.go - DO NOT EDIT.
+
+package quic
+
+import (
+	"github.com/elastic/beats/v7/libbeat/asset"
+)
+
+func init() {
+	if err := asset.SetFields("packetbeat", "quic", asset.ModuleFieldsPri, AssetQuic); err != nil {
+		panic(err)
+	}
+}
+
+// AssetQuic returns asset data.
+// This is the base64 encoded zlib format compressed contents of protos/quic.
+func AssetQuic() string {
+	return "eJy8lUFvGjEQhe/8iqecE0SaRqUceqEckCJoCVXV08rYs6yFsbdjQ+DfV2t2wRTSpgJVXFazno8373ngDgva9vBzpWULCDoY6uHm67dh/6YFKPKSdRm0sz18agFA9erOlyR1riVoTTYg12SUb7dQP/XiyTtYsaQ9uyqFbUk9zNmtyrqSNqRNa2Kvnd3Xm94FbV8cq6R+RmPzmRYU9TY0uByhIAytDloYlEIuKPimLI0mG9oJgjZiWUZH7m9aJxr9qiwdB1JZzfeXy21IBzhm2yjaE6+Jb5GzW0IHvx/K0twFLcKxXaina5/qVuSDtrEhk85akvFRq8vlJ2gc0Bh+bjzONfvwWwD1uyPYLotbaIuCNkKR1EthzmbTffj4XnbzzgM93j9+6HTPBOVWLOnasx7PJwvnyTZhvSb/VFpM9X9Ka+7RX6UxBd4m6N2VmDlnSNi3afleUCiIk++Fr34wBCYVvMk/OKyF0UoEikeFUky+2ssjWrqjJ2qlcZ4yYnZ8uX/VtWlubERCOhUrAv3xaDToT4fjUdZ/Gj8PkHN1Os6lVqzt/AhXIQphlS/EgtqYPj1DGOLgIZjAVC+58OhPfnyZjrPBZDKeIHfGuBdSmKURYGdP1R8Vnd2HlJN1Nu+6fzQrqzgJZpeycXb+drv+3aKTfT9Y9JpaJuGv8Yew46AsWPir6P01AMlUFbs="
+}
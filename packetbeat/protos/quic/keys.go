@@ -0,0 +1,154 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package quic
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hkdf"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+)
+
+// QUIC versions whose Initial packets can be decrypted.
+const (
+	version1       = 0x00000001
+	version2       = 0x6b3343cf
+	versionDraft29 = 0xff00001d
+)
+
+// Long header packet types, independent of the version.
+const (
+	packetInitial = iota
+	packet0RTT
+	packetHandshake
+	packetRetry
+)
+
+// versionParams are the parameters of a QUIC version needed to decrypt its
+// Initial packets.
+type versionParams struct {
+	name string
+	// salt is the salt of the initial secret.
+	salt []byte
+	// labelPrefix is the prefix of the labels of the packet protection keys.
+	labelPrefix string
+	// types are the long header packet types, indexed by their value in the
+	// packet header.
+	types [4]int
+}
+
+var versions = map[uint32]*versionParams{
+	// RFC 9001, section 5.2.
+	version1: {
+		name:        "1",
+		salt:        []byte{0x38, 0x76, 0x2c, 0xf7, 0xf5, 0x59, 0x34, 0xb3, 0x4d, 0x17, 0x9a, 0xe6, 0xa4, 0xc8, 0x0c, 0xad, 0xcc, 0xbb, 0x7f, 0x0a},
+		labelPrefix: "quic",
+		types:       [4]int{packetInitial, packet0RTT, packetHandshake, packetRetry},
+	},
+	// RFC 9369, section 3.
+	version2: {
+		name:        "2",
+		salt:        []byte{0x0d, 0xed, 0xe3, 0xde, 0xf7, 0x00, 0xa6, 0xdb, 0x81, 0x93, 0x81, 0xbe, 0x6e, 0x26, 0x9d, 0xcb, 0xf9, 0xbd, 0x2e, 0xd9},
+		labelPrefix: "quicv2",
+		types:       [4]int{packetRetry, packetInitial, packet0RTT, packetHandshake},
+	},
+	// draft-ietf-quic-tls-29, still used by some clients.
+	versionDraft29: {
+		name:        "draft-29",
+		salt:        []byte{0xaf, 0xbf, 0xec, 0x28, 0x99, 0x93, 0xd2, 0x4c, 0x9e, 0x97, 0x86, 0xf1, 0x9c, 0x61, 0x11, 0xe0, 0x43, 0x90, 0xa8, 0x99},
+		labelPrefix: "quic",
+		types:       [4]int{packetInitial, packet0RTT, packetHandshake, packetRetry},
+	},
+}
+
+func versionName(version uint32) string {
+	if params, ok := versions[version]; ok {
+		return params.name
+	}
+	return fmt.Sprintf("0x%08x", version)
+}
+
+// keys are the packet protection keys of the Initial packets sent by the
+// client or the server.
+type keys struct {
+	aead cipher.AEAD
+	iv   []byte
+	hp   cipher.Block
+}
+
+// newInitialKeys returns the keys of the Initial packets derived from the
+// destination connection ID of the first Initial packet of the client.
+func newInitialKeys(params *versionParams, dcid []byte, server bool) (*keys, error) {
+	key, iv, hpKey, err := initialSecrets(params, dcid, server)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	hp, err := aes.NewCipher(hpKey)
+	if err != nil {
+		return nil, err
+	}
+	return &keys{aead: aead, iv: iv, hp: hp}, nil
+}
+
+// initialSecrets derives the AEAD key and IV, and the header protection key
+// of the Initial packets, as described in RFC 9001, section 5.2.
+func initialSecrets(params *versionParams, dcid []byte, server bool) (key, iv, hp []byte, err error) {
+	initial, err := hkdf.Extract(sha256.New, dcid, params.salt)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	label := "client in"
+	if server {
+		label = "server in"
+	}
+	secret, err := expandLabel(initial, label, sha256.Size)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if key, err = expandLabel(secret, params.labelPrefix+" key", 16); err != nil {
+		return nil, nil, nil, err
+	}
+	if iv, err = expandLabel(secret, params.labelPrefix+" iv", 12); err != nil {
+		return nil, nil, nil, err
+	}
+	if hp, err = expandLabel(secret, params.labelPrefix+" hp", 16); err != nil {
+		return nil, nil, nil, err
+	}
+	return key, iv, hp, nil
+}
+
+// expandLabel is the HKDF-Expand-Label function of TLS 1.3, with an empty
+// context.
+func expandLabel(secret []byte, label string, length int) ([]byte, error) {
+	label = "tls13 " + label
+	info := binary.BigEndian.AppendUint16(nil, uint16(length))
+	info = append(info, byte(len(label)))
+	info = append(info, label...)
+	info = append(info, 0)
+	return hkdf.Expand(sha256.New, secret, string(info), length)
+}
@@ -0,0 +1,66 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+//go:build !integration
+
+package quic
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// The test vectors of RFC 9001, appendix A, and RFC 9369, appendix A.
+func TestInitialSecrets(t *testing.T) {
+	dcid, _ := hex.DecodeString("8394c8f03e515708")
+	for _, test := range []struct {
+		version     uint32
+		server      bool
+		key, iv, hp string
+	}{
+		{version1, false, "1f369613dd76d5467730efcbe3b1a22d", "fa044b2f42a3fd3b46fb255c", "9f50449e04a0e810283a1e9933adedd2"},
+		{version1, true, "cf3a5331653c364c88f0f379b6067e37", "0ac1493ca1905853b0bba03e", "c206b8d9b9f0f37644430b490eeaa314"},
+		{version2, false, "8b1a0bc121284290a29e0971b5cd045d", "91f73e2351d8fa91660e909f", "45b95e15235d6f45a6b19cbcb0294ba9"},
+		{version2, true, "82db637861d55e1d011f19ea71d5d2a7", "dd13c276499c0249d3310652", "edf6d05c83121201b436e16877593c3a"},
+	} {
+		key, iv, hp, err := initialSecrets(versions[test.version], dcid, test.server)
+		require.NoError(t, err)
+		assert.Equal(t, test.key, hex.EncodeToString(key))
+		assert.Equal(t, test.iv, hex.EncodeToString(iv))
+		assert.Equal(t, test.hp, hex.EncodeToString(hp))
+	}
+}
+
+func TestHeaderProtectionMask(t *testing.T) {
+	dcid, _ := hex.DecodeString("8394c8f03e515708")
+	k, err := newInitialKeys(versions[version1], dcid, false)
+	require.NoError(t, err)
+	sample, _ := hex.DecodeString("d1b1c98dd7689fb8ec11d242b123dc9b")
+	mask := make([]byte, 16)
+	k.hp.Encrypt(mask, sample)
+	assert.Equal(t, "437b9aec36", hex.EncodeToString(mask[:5]))
+}
+
+func TestVersionName(t *testing.T) {
+	assert.Equal(t, "1", versionName(version1))
+	assert.Equal(t, "2", versionName(version2))
+	assert.Equal(t, "draft-29", versionName(versionDraft29))
+	assert.Equal(t, "0x1a2a3a4a", versionName(0x1a2a3a4a))
+}
@@ -0,0 +1,343 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package quic
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+const (
+	headerFormLong = 0x80
+	// sampleSize is the size of the sample of the encrypted payload used to
+	// compute the header protection mask.
+	sampleSize = 16
+	// maxCryptoData is the maximum size of the TLS handshake data buffered
+	// per direction, enough for a client hello with post-quantum key shares.
+	maxCryptoData = 64 * 1024
+)
+
+// Frame types of Initial packets.
+const (
+	framePadding             = 0x00
+	framePing                = 0x01
+	frameAck                 = 0x02
+	frameAckECN              = 0x03
+	frameCrypto              = 0x06
+	frameConnectionCloseQUIC = 0x1c
+	frameConnectionCloseApp  = 0x1d
+)
+
+// transportErrorNames are the names of the error codes of the
+// CONNECTION_CLOSE frames, from RFC 9000, section 20.1.
+var transportErrorNames = []string{
+	"NO_ERROR",
+	"INTERNAL_ERROR",
+	"CONNECTION_REFUSED",
+	"FLOW_CONTROL_ERROR",
+	"STREAM_LIMIT_ERROR",
+	"STREAM_STATE_ERROR",
+	"FINAL_SIZE_ERROR",
+	"FRAME_ENCODING_ERROR",
+	"TRANSPORT_PARAMETER_ERROR",
+	"CONNECTION_ID_LIMIT_ERROR",
+	"PROTOCOL_VIOLATION",
+	"INVALID_TOKEN",
+	"APPLICATION_ERROR",
+	"CRYPTO_BUFFER_EXCEEDED",
+	"KEY_UPDATE_ERROR",
+	"AEAD_LIMIT_REACHED",
+	"NO_VIABLE_PATH",
+}
+
+func transportErrorName(code uint64) string {
+	switch {
+	case code < uint64(len(transportErrorNames)):
+		return transportErrorNames[code]
+	case code >= 0x100 && code <= 0x1ff:
+		// The TLS alerts.
+		return fmt.Sprintf("CRYPTO_ERROR_0x%02x", code-0x100)
+	}
+	return fmt.Sprintf("0x%x", code)
+}
+
+var (
+	errTruncated       = errors.New("truncated packet")
+	errShortHeader     = errors.New("short header packet")
+	errUnexpectedFrame = errors.New("unexpected frame in Initial packet")
+)
+
+// packet is a long header packet.
+type packet struct {
+	version uint32
+	// typ is the packet type of the header, whose meaning depends on the
+	// version.
+	typ        uint8
+	dcid, scid []byte
+
+	// data is the protected packet, whose payload starts with the packet
+	// number at pnOffset. It is only set for the packets with a length.
+	data     []byte
+	pnOffset int
+
+	// supportedVersions are the versions of a version negotiation packet.
+	supportedVersions []uint32
+}
+
+// parseLongHeader parses the long header packet at the start of data, and
+// returns the packets coalesced after it.
+func parseLongHeader(data []byte) (p *packet, rest []byte, err error) {
+	if len(data) == 0 || data[0]&headerFormLong == 0 {
+		return nil, nil, errShortHeader
+	}
+	r := reader{data: data, pos: 1}
+	p = &packet{
+		typ:     data[0] >> 4 & 0x3,
+		version: r.uint32(),
+	}
+	p.dcid = r.bytes(int(r.uint8()))
+	p.scid = r.bytes(int(r.uint8()))
+	if r.err != nil {
+		return nil, nil, r.err
+	}
+
+	if p.version == 0 {
+		for r.remaining() >= 4 {
+			p.supportedVersions = append(p.supportedVersions, r.uint32())
+		}
+		return p, nil, nil
+	}
+	params := versions[p.version]
+	if params == nil || params.types[p.typ] == packetRetry {
+		// The length of the packets of unknown versions is unknown, and
+		// Retry packets have no length.
+		return p, nil, nil
+	}
+	if params.types[p.typ] == packetInitial {
+		r.bytes(int(r.varint())) // token
+	}
+	length := r.varint()
+	if r.err != nil {
+		return nil, nil, r.err
+	}
+	if length > uint64(r.remaining()) {
+		return nil, nil, errTruncated
+	}
+	end := r.pos + int(length)
+	p.data, p.pnOffset = data[:end], r.pos
+	return p, data[end:], nil
+}
+
+// decrypt removes the header protection of an Initial packet and returns its
+// decrypted payload, as described in RFC 9001, section 5.
+func (p *packet) decrypt(k *keys) ([]byte, error) {
+	if len(p.data) < p.pnOffset+4+sampleSize {
+		return nil, errTruncated
+	}
+	var mask [16]byte
+	k.hp.Encrypt(mask[:], p.data[p.pnOffset+4:p.pnOffset+4+sampleSize])
+
+	// The header is unprotected in a copy, as it is used as the additional
+	// data of the AEAD.
+	pnLen := int((p.data[0]^mask[0])&0x03) + 1
+	header := make([]byte, p.pnOffset+pnLen)
+	copy(header, p.data)
+	header[0] ^= mask[0] & 0x0f
+	var pn uint64
+	for i := 0; i < pnLen; i++ {
+		header[p.pnOffset+i] ^= mask[1+i]
+		pn = pn<<8 | uint64(header[p.pnOffset+i])
+	}
+
+	// The packet number of the first packets is the truncated packet number.
+	nonce := make([]byte, len(k.iv))
+	copy(nonce, k.iv)
+	for i := 0; i < 8; i++ {
+		nonce[len(nonce)-1-i] ^= byte(pn >> (8 * i))
+	}
+	return k.aead.Open(nil, nonce, p.data[len(header):], header)
+}
+
+// frames are the frames of an Initial packet that are reported.
+type frames struct {
+	crypto []cryptoFrame
+	// closeCode and closeReason are the error code and reason phrase of a
+	// CONNECTION_CLOSE frame.
+	closed      bool
+	closeCode   uint64
+	closeReason string
+}
+
+type cryptoFrame struct {
+	offset uint64
+	data   []byte
+}
+
+// parseFrames parses the frames of the decrypted payload of an Initial
+// packet.
+func parseFrames(payload []byte) (*frames, error) {
+	f := &frames{}
+	r := reader{data: payload}
+	for r.remaining() > 0 && r.err == nil {
+		switch typ := r.varint(); typ {
+		case framePadding, framePing:
+		case frameAck, frameAckECN:
+			r.varint() // largest acknowledged
+			r.varint() // delay
+			n := r.varint()
+			r.varint() // first range
+			for i := uint64(0); i < n && r.err == nil; i++ {
+				r.varint() // gap
+				r.varint() // range length
+			}
+			if typ == frameAckECN {
+				r.varint()
+				r.varint()
+				r.varint()
+			}
+		case frameCrypto:
+			offset := r.varint()
+			data := r.bytes(int(r.varint()))
+			if r.err == nil {
+				f.crypto = append(f.crypto, cryptoFrame{offset: offset, data: data})
+			}
+		case frameConnectionCloseQUIC, frameConnectionCloseApp:
+			f.closed = true
+			f.closeCode = r.varint()
+			if typ == frameConnectionCloseQUIC {
+				r.varint() // frame type
+			}
+			f.closeReason = string(r.bytes(int(r.varint())))
+		default:
+			return f, errUnexpectedFrame
+		}
+	}
+	return f, r.err
+}
+
+// cryptoStream reassembles the TLS handshake data of the CRYPTO frames of a
+// direction.
+type cryptoStream struct {
+	data []byte
+	// pending are the frames received out of order, by offset.
+	pending map[uint64][]byte
+}
+
+// add adds the data of a CRYPTO frame to the stream.
+func (s *cryptoStream) add(offset uint64, data []byte) {
+	if offset+uint64(len(data)) > maxCryptoData {
+		return
+	}
+	if offset > uint64(len(s.data)) {
+		if s.pending == nil {
+			s.pending = make(map[uint64][]byte)
+		}
+		s.pending[offset] = append([]byte(nil), data...)
+		return
+	}
+	s.append(offset, data)
+	for len(s.pending) > 0 {
+		progress := false
+		for off, b := range s.pending {
+			if off <= uint64(len(s.data)) {
+				s.append(off, b)
+				delete(s.pending, off)
+				progress = true
+			}
+		}
+		if !progress {
+			break
+		}
+	}
+}
+
+// append appends the part of the data at offset that is beyond the end of
+// the stream.
+func (s *cryptoStream) append(offset uint64, data []byte) {
+	if end := offset + uint64(len(data)); end > uint64(len(s.data)) {
+		s.data = append(s.data, data[uint64(len(s.data))-offset:]...)
+	}
+}
+
+// message returns the first handshake message of the stream, if it's
+// complete.
+func (s *cryptoStream) message() ([]byte, bool) {
+	if len(s.data) < 4 {
+		return nil, false
+	}
+	n := 4 + (int(s.data[1])<<16 | int(s.data[2])<<8 | int(s.data[3]))
+	if len(s.data) < n {
+		return nil, false
+	}
+	return s.data[:n], true
+}
+
+// reader reads the fields of a packet. Reads past the end of the data set
+// err and return zero values.
+type reader struct {
+	data []byte
+	pos  int
+	err  error
+}
+
+func (r *reader) remaining() int {
+	return len(r.data) - r.pos
+}
+
+func (r *reader) bytes(n int) []byte {
+	if r.err != nil || n < 0 || n > r.remaining() {
+		r.err = errTruncated
+		return nil
+	}
+	b := r.data[r.pos : r.pos+n]
+	r.pos += n
+	return b
+}
+
+func (r *reader) uint8() uint8 {
+	if b := r.bytes(1); b != nil {
+		return b[0]
+	}
+	return 0
+}
+
+func (r *reader) uint32() uint32 {
+	if b := r.bytes(4); b != nil {
+		return binary.BigEndian.Uint32(b)
+	}
+	return 0
+}
+
+// varint reads a variable-length integer, as described in RFC 9000,
+// section 16.
+func (r *reader) varint() uint64 {
+	if r.err != nil || r.remaining() < 1 {
+		r.err = errTruncated
+		return 0
+	}
+	b := r.bytes(1 << (r.data[r.pos] >> 6))
+	if b == nil {
+		return 0
+	}
+	v := uint64(b[0] & 0x3f)
+	for _, c := range b[1:] {
+		v = v<<8 | uint64(c)
+	}
+	return v
+}
@@ -0,0 +1,405 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package quic
+
+import (
+	"encoding/hex"
+	"slices"
+	"time"
+
+	"github.com/elastic/beats/v7/libbeat/beat"
+	"github.com/elastic/beats/v7/libbeat/common"
+	conf "github.com/elastic/elastic-agent-libs/config"
+	"github.com/elastic/elastic-agent-libs/logp"
+	"github.com/elastic/elastic-agent-libs/mapstr"
+	"github.com/elastic/elastic-agent-libs/monitoring"
+
+	"github.com/elastic/beats/v7/packetbeat/pb"
+	"github.com/elastic/beats/v7/packetbeat/procs"
+	"github.com/elastic/beats/v7/packetbeat/protos"
+	"github.com/elastic/beats/v7/packetbeat/protos/tls"
+)
+
+// connection is the handshake of a QUIC connection, up to the server hello.
+type connection struct {
+	// tuple and cmdlineTuple are oriented from the client.
+	tuple        common.IPPortTuple
+	cmdlineTuple *common.ProcessTuple
+	start, end   time.Time
+	// requBytes and respBytes are the sizes of the datagrams sent by the
+	// client and the server.
+	requBytes, respBytes int
+
+	version uint32
+	// origDCID is the destination connection ID of the first Initial packet
+	// of the client, and dcid the one the initial keys are derived from,
+	// which is changed by a Retry.
+	origDCID, dcid []byte
+	scid           []byte
+	serverCID      []byte
+	retry          bool
+	// supportedVersions are the versions of a version negotiation packet.
+	supportedVersions []uint32
+
+	// keys are the keys of the Initial packets of the client and the server.
+	keys   [2]*keys
+	crypto [2]cryptoStream
+	// done is whether the hello of a direction was parsed or is invalid.
+	done   [2]bool
+	client *tls.Hello
+	server *tls.Hello
+	close  *frames
+
+	notes     []string
+	published bool
+}
+
+// QUIC protocol plugin
+type quicPlugin struct {
+	// config
+	ports                 []int
+	transactionTimeout    time.Duration
+	includeDetailedFields bool
+
+	// connections holds the handshakes by IP and port tuple of the client.
+	connections *common.Cache
+
+	watcher *procs.ProcessesWatcher
+	results protos.Reporter
+}
+
+var (
+	debugf  = logp.MakeDebug("quic")
+	isDebug = false
+)
+
+var undecryptedPackets = monitoring.NewInt(nil, "quic.undecrypted_packets")
+
+func init() {
+	protos.Register("quic", New)
+}
+
+func New(
+	testMode bool,
+	results protos.Reporter,
+	watcher *procs.ProcessesWatcher,
+	cfg *conf.C,
+) (protos.Plugin, error) {
+	p := &quicPlugin{}
+	config := defaultConfig
+	if !testMode {
+		if err := cfg.Unpack(&config); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := p.init(results, watcher, &config); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+func (qp *quicPlugin) init(results protos.Reporter, watcher *procs.ProcessesWatcher, config *quicConfig) error {
+	qp.setFromConfig(config)
+
+	qp.connections = common.NewCacheWithRemovalListener(
+		qp.transactionTimeout,
+		protos.DefaultTransactionHashSize,
+		func(k common.Key, v common.Value) {
+			conn, ok := v.(*connection)
+			if !ok {
+				logp.Err("Expired value is not a *quic.connection.")
+				return
+			}
+			if !conn.published {
+				qp.publish(conn)
+			}
+		})
+	qp.connections.StartJanitor(qp.transactionTimeout)
+
+	qp.results = results
+	qp.watcher = watcher
+	isDebug = logp.IsDebug("quic")
+
+	return nil
+}
+
+func (qp *quicPlugin) setFromConfig(config *quicConfig) {
+	qp.ports = config.Ports
+	qp.transactionTimeout = config.TransactionTimeout
+	qp.includeDetailedFields = config.IncludeDetailedFields
+}
+
+func (qp *quicPlugin) GetPorts() []int {
+	return qp.ports
+}
+
+// isServerPort returns whether a port is one of the configured QUIC ports.
+func (qp *quicPlugin) isServerPort(port uint16) bool {
+	return slices.Contains(qp.ports, int(port))
+}
+
+// ParseUDP parses the long header packets of a datagram. The handshake of a
+// connection is reported once the server hello is decrypted, or when the
+// connection expires.
+func (qp *quicPlugin) ParseUDP(pkt *protos.Packet) {
+	fromClient := qp.isServerPort(pkt.Tuple.DstPort)
+	key := pkt.Tuple.Hashable()
+	if !fromClient {
+		key = pkt.Tuple.RevHashable()
+	}
+
+	p, rest, err := parseLongHeader(pkt.Payload)
+	if err != nil {
+		// Short header packets are sent once the handshake is done.
+		if isDebug && err != errShortHeader {
+			debugf("%v, dropping QUIC datagram from %s", err, &pkt.Tuple)
+		}
+		return
+	}
+
+	conn, _ := qp.connections.Get(key).(*connection)
+	if conn == nil {
+		// Connections are tracked from the first Initial packet of the
+		// client.
+		params := versions[p.version]
+		if !fromClient || p.version == 0 || (params != nil && params.types[p.typ] != packetInitial) {
+			return
+		}
+		conn = &connection{
+			tuple:        pkt.Tuple,
+			cmdlineTuple: qp.watcher.FindProcessesTupleUDP(&pkt.Tuple),
+			start:        pkt.Ts,
+			version:      p.version,
+			origDCID:     p.dcid,
+			dcid:         p.dcid,
+			scid:         p.scid,
+		}
+		if params == nil {
+			conn.addNote("Unsupported version")
+		}
+		qp.connections.Put(key, conn)
+	}
+	if conn.published {
+		return
+	}
+
+	conn.end = pkt.Ts
+	if fromClient {
+		conn.requBytes += len(pkt.Payload)
+	} else {
+		conn.respBytes += len(pkt.Payload)
+	}
+	for p != nil {
+		qp.handlePacket(conn, p, fromClient)
+		if len(rest) == 0 {
+			break
+		}
+		if p, rest, err = parseLongHeader(rest); err != nil && isDebug && err != errShortHeader {
+			debugf("%v, ignoring coalesced QUIC packets from %s", err, &pkt.Tuple)
+		}
+	}
+
+	if conn.done[0] && (conn.done[1] || conn.close != nil) {
+		qp.publish(conn)
+	}
+}
+
+func (qp *quicPlugin) handlePacket(conn *connection, p *packet, fromClient bool) {
+	if p.version == 0 {
+		if !fromClient {
+			conn.supportedVersions = p.supportedVersions
+		}
+		return
+	}
+	if p.version != conn.version {
+		if !fromClient {
+			// The server chose another compatible version. The keys of
+			// its packets are derived for that version.
+			conn.keys[1] = nil
+		} else {
+			// The client resumed the handshake with another version after a
+			// version negotiation.
+			conn.dcid = p.dcid
+			conn.keys = [2]*keys{}
+			conn.crypto = [2]cryptoStream{}
+			conn.done = [2]bool{}
+		}
+		conn.version = p.version
+	}
+	params := versions[p.version]
+	if params == nil {
+		return
+	}
+
+	switch params.types[p.typ] {
+	case packetRetry:
+		if fromClient {
+			return
+		}
+		// The client resends its Initial packets with a new destination
+		// connection ID, from which the new keys are derived.
+		conn.retry = true
+		conn.serverCID = p.scid
+		conn.dcid = p.scid
+		conn.keys = [2]*keys{}
+		return
+	case packetInitial:
+	default:
+		return
+	}
+
+	dir := 0
+	if !fromClient {
+		dir = 1
+		conn.serverCID = p.scid
+	}
+	if conn.keys[dir] == nil {
+		k, err := newInitialKeys(params, conn.dcid, !fromClient)
+		if err != nil {
+			logp.Warn("failed deriving QUIC initial keys: %v", err)
+			return
+		}
+		conn.keys[dir] = k
+	}
+	payload, err := p.decrypt(conn.keys[dir])
+	if err != nil {
+		undecryptedPackets.Add(1)
+		conn.addNote("Initial packet decryption failed")
+		if isDebug {
+			debugf("failed decrypting QUIC Initial packet from %s: %v", &conn.tuple, err)
+		}
+		return
+	}
+	f, err := parseFrames(payload)
+	if err != nil && isDebug {
+		debugf("%v in QUIC Initial packet", err)
+	}
+	if f.closed && conn.close == nil {
+		conn.close = f
+	}
+
+	if conn.done[dir] {
+		return
+	}
+	stream := &conn.crypto[dir]
+	for _, c := range f.crypto {
+		stream.add(c.offset, c.data)
+	}
+	msg, ok := stream.message()
+	if !ok {
+		return
+	}
+	conn.done[dir] = true
+	conn.crypto[dir] = cryptoStream{}
+	hello, err := tls.ParseHello(msg)
+	if err != nil || hello.IsClient() != fromClient {
+		conn.addNote("Invalid TLS handshake")
+		if isDebug {
+			debugf("failed parsing TLS hello from %s: %v", &conn.tuple, err)
+		}
+		return
+	}
+	if fromClient {
+		conn.client = hello
+	} else {
+		conn.server = hello
+	}
+}
+
+func (c *connection) addNote(note string) {
+	if !slices.Contains(c.notes, note) {
+		c.notes = append(c.notes, note)
+	}
+}
+
+func (qp *quicPlugin) publish(conn *connection) {
+	conn.published = true
+	if qp.results == nil {
+		return
+	}
+	qp.results(qp.newEvent(conn))
+}
+
+func (qp *quicPlugin) newEvent(conn *connection) beat.Event {
+	src, dst := common.MakeEndpointPair(conn.tuple.BaseTuple, conn.cmdlineTuple)
+
+	evt, pbf := pb.NewBeatEvent(conn.start)
+	pbf.SetSource(&src)
+	pbf.SetDestination(&dst)
+	pbf.AddIP(src.IP)
+	pbf.AddIP(dst.IP)
+	pbf.Source.Bytes = int64(conn.requBytes)
+	pbf.Destination.Bytes = int64(conn.respBytes)
+	pbf.Event.Dataset = "quic"
+	pbf.Event.Start = conn.start
+	pbf.Event.End = conn.end
+	pbf.Network.Transport = "udp"
+	pbf.Network.Protocol = pbf.Event.Dataset
+
+	quic := mapstr.M{
+		"version":                   versionName(conn.version),
+		"destination_connection_id": hex.EncodeToString(conn.origDCID),
+		"source_connection_id":      hex.EncodeToString(conn.scid),
+		"retry":                     conn.retry,
+	}
+	if conn.serverCID != nil {
+		quic["server_connection_id"] = hex.EncodeToString(conn.serverCID)
+	}
+	if len(conn.supportedVersions) > 0 {
+		names := make([]string, 0, len(conn.supportedVersions))
+		for _, v := range conn.supportedVersions {
+			names = append(names, versionName(v))
+		}
+		quic["supported_versions"] = names
+	}
+
+	fields := evt.Fields
+	fields["type"] = pbf.Event.Dataset
+	fields["quic"] = quic
+	if conn.client != nil || conn.server != nil {
+		tls.PutHelloFields(fields, conn.client, conn.server, tls.JA4TransportQUIC, qp.includeDetailedFields)
+	}
+	if conn.client != nil {
+		if name := conn.client.ServerName(); name != "" {
+			pbf.Destination.Domain = name
+		}
+	}
+
+	status := common.OK_STATUS
+	notes := conn.notes
+	if c := conn.close; c != nil {
+		quic["close_error"] = transportErrorName(c.closeCode)
+		quic["close_error_code"] = c.closeCode
+		if c.closeReason != "" {
+			quic["close_reason"] = c.closeReason
+		}
+		status = common.ERROR_STATUS
+		notes = append(notes, transportErrorName(c.closeCode))
+	}
+	if conn.client == nil || conn.server == nil {
+		status = common.ERROR_STATUS
+	}
+	fields["status"] = status
+	if status == common.ERROR_STATUS {
+		pbf.Event.Outcome = "failure"
+	}
+	pbf.Error.Message = notes
+
+	return evt
+}
@@ -0,0 +1,402 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+//go:build !integration
+
+package quic
+
+import (
+	"encoding/binary"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/elastic/beats/v7/libbeat/beat"
+	"github.com/elastic/beats/v7/libbeat/common"
+	"github.com/elastic/beats/v7/packetbeat/procs"
+	"github.com/elastic/beats/v7/packetbeat/protos"
+	"github.com/elastic/beats/v7/packetbeat/publish"
+	conf "github.com/elastic/elastic-agent-libs/config"
+)
+
+type eventStore struct {
+	events []beat.Event
+}
+
+func (e *eventStore) publish(event beat.Event) {
+	publish.MarshalPacketbeatFields(&event, nil, nil)
+	e.events = append(e.events, event)
+}
+
+func quicModForTests(t *testing.T, store *eventStore, timeout time.Duration) *quicPlugin {
+	t.Helper()
+	p, err := New(false, store.publish, &procs.ProcessesWatcher{}, conf.MustNewConfigFrom(map[string]interface{}{
+		"ports":               []int{443},
+		"transaction_timeout": timeout,
+	}))
+	require.NoError(t, err)
+	qp := p.(*quicPlugin)
+	t.Cleanup(qp.connections.StopJanitor)
+	return qp
+}
+
+var (
+	clientCID = []byte{0x83, 0x94, 0xc8, 0xf0, 0x3e, 0x51, 0x57, 0x08}
+	serverCID = []byte{0xf0, 0x67, 0xa5, 0x50, 0x2a, 0x42, 0x62, 0xb5}
+	retryCID  = []byte{0x11, 0x22, 0x33, 0x44}
+	clientSrc = []byte{0x01, 0x02}
+)
+
+// Helpers to encode the TLS handshake.
+
+func vec8(data ...[]byte) []byte {
+	b := []byte{0}
+	for _, d := range data {
+		b = append(b, d...)
+	}
+	b[0] = byte(len(b) - 1)
+	return b
+}
+
+func vec16(data ...[]byte) []byte {
+	b := []byte{0, 0}
+	for _, d := range data {
+		b = append(b, d...)
+	}
+	binary.BigEndian.PutUint16(b, uint16(len(b)-2))
+	return b
+}
+
+func u16(v ...uint16) []byte {
+	var b []byte
+	for _, x := range v {
+		b = binary.BigEndian.AppendUint16(b, x)
+	}
+	return b
+}
+
+func extension(typ uint16, data ...[]byte) []byte {
+	return append(u16(typ), vec16(data...)...)
+}
+
+func handshake(typ byte, body ...[]byte) []byte {
+	var msg []byte
+	for _, b := range body {
+		msg = append(msg, b...)
+	}
+	return append([]byte{typ, byte(len(msg) >> 16), byte(len(msg) >> 8), byte(len(msg))}, msg...)
+}
+
+func clientHello(serverName string) []byte {
+	return handshake(1,
+		u16(0x0303), make([]byte, 32), vec8(),
+		vec16(u16(0x1301, 0x1302, 0x1303)), vec8([]byte{0}),
+		vec16(
+			extension(0, vec16([]byte{0}, vec16([]byte(serverName)))),
+			extension(10, vec16(u16(0x001d, 0x0017))),
+			extension(13, vec16(u16(0x0403, 0x0804, 0x0401))),
+			extension(16, vec16(vec8([]byte("h3")))),
+			extension(43, vec8(u16(0x0304))),
+			extension(51, vec16(u16(0x001d), vec16(make([]byte, 32)))),
+			extension(57, []byte{0x04, 0x04, 0x80, 0x10, 0x00, 0x00}),
+		),
+	)
+}
+
+func serverHello() []byte {
+	return handshake(2,
+		u16(0x0303), make([]byte, 32), vec8(),
+		u16(0x1301), []byte{0},
+		vec16(
+			extension(43, u16(0x0304)),
+			extension(51, u16(0x001d), vec16(make([]byte, 32))),
+		),
+	)
+}
+
+// Helpers to encode the QUIC packets.
+
+func varint(v uint64) []byte {
+	switch {
+	case v < 1<<6:
+		return []byte{byte(v)}
+	case v < 1<<14:
+		return binary.BigEndian.AppendUint16(nil, uint16(v)|0x4000)
+	}
+	return binary.BigEndian.AppendUint32(nil, uint32(v)|0x80000000)
+}
+
+func cryptoFrameData(offset int, data []byte) []byte {
+	b := append([]byte{frameCrypto}, varint(uint64(offset))...)
+	b = append(b, varint(uint64(len(data)))...)
+	return append(b, data...)
+}
+
+func closeFrame(code uint64, reason string) []byte {
+	b := append([]byte{frameConnectionCloseQUIC}, varint(code)...)
+	b = append(b, 0x06)
+	b = append(b, varint(uint64(len(reason)))...)
+	return append(b, reason...)
+}
+
+// initial encodes a protected Initial packet carrying the frames, with the
+// keys derived from keyCID.
+func initial(t *testing.T, version uint32, keyCID, dcid, scid []byte, server bool, pn uint32, frames ...[]byte) []byte {
+	t.Helper()
+	params := versions[version]
+	var typ byte
+	for i, ptype := range params.types {
+		if ptype == packetInitial {
+			typ = byte(i)
+		}
+	}
+	var payload []byte
+	for _, f := range frames {
+		payload = append(payload, f...)
+	}
+	// Leave enough data after the packet number for the sample.
+	for len(payload) < sampleSize {
+		payload = append(payload, framePadding)
+	}
+
+	const pnLen = 2
+	header := []byte{0xc0 | typ<<4 | (pnLen - 1)}
+	header = binary.BigEndian.AppendUint32(header, version)
+	header = append(header, vec8(dcid)...)
+	header = append(header, vec8(scid)...)
+	header = append(header, 0) // token
+	header = binary.BigEndian.AppendUint16(header, uint16(pnLen+len(payload)+16)|0x4000)
+	pnOffset := len(header)
+	header = binary.BigEndian.AppendUint16(header, uint16(pn))
+
+	k, err := newInitialKeys(params, keyCID, server)
+	require.NoError(t, err)
+	nonce := append([]byte(nil), k.iv...)
+	for i := 0; i < 4; i++ {
+		nonce[len(nonce)-1-i] ^= byte(pn >> (8 * i))
+	}
+	data := k.aead.Seal(header, nonce, payload, header)
+
+	mask := make([]byte, 16)
+	k.hp.Encrypt(mask, data[pnOffset+4:pnOffset+4+sampleSize])
+	data[0] ^= mask[0] & 0x0f
+	for i := 0; i < pnLen; i++ {
+		data[pnOffset+i] ^= mask[1+i]
+	}
+	return data
+}
+
+func retry(version uint32, dcid, scid []byte) []byte {
+	params := versions[version]
+	var typ byte
+	for i, ptype := range params.types {
+		if ptype == packetRetry {
+			typ = byte(i)
+		}
+	}
+	b := binary.BigEndian.AppendUint32([]byte{0xc0 | typ<<4}, version)
+	b = append(b, vec8(dcid)...)
+	b = append(b, vec8(scid)...)
+	b = append(b, "token"...)
+	return append(b, make([]byte, 16)...) // integrity tag
+}
+
+func versionNegotiation(dcid, scid []byte, supported ...uint32) []byte {
+	b := []byte{0x80, 0, 0, 0, 0}
+	b = append(b, vec8(dcid)...)
+	b = append(b, vec8(scid)...)
+	for _, v := range supported {
+		b = binary.BigEndian.AppendUint32(b, v)
+	}
+	return b
+}
+
+type testConn struct {
+	t    *testing.T
+	quic *quicPlugin
+	ts   time.Time
+}
+
+func (c *testConn) send(fromClient bool, datagram ...[]byte) {
+	tuple := common.NewIPPortTuple(4, net.IPv4(192, 168, 0, 1), 52000, net.IPv4(192, 168, 0, 2), 443)
+	if !fromClient {
+		tuple = common.NewIPPortTuple(4, net.IPv4(192, 168, 0, 2), 443, net.IPv4(192, 168, 0, 1), 52000)
+	}
+	var payload []byte
+	for _, p := range datagram {
+		payload = append(payload, p...)
+	}
+	c.ts = c.ts.Add(time.Millisecond)
+	c.quic.ParseUDP(&protos.Packet{Ts: c.ts, Tuple: tuple, Payload: payload})
+}
+
+func getValue(t *testing.T, event beat.Event, key string) interface{} {
+	t.Helper()
+	v, err := event.GetValue(key)
+	require.NoError(t, err, key)
+	return v
+}
+
+func TestHandshake(t *testing.T) {
+	store := &eventStore{}
+	c := &testConn{t: t, quic: quicModForTests(t, store, time.Minute), ts: time.Now()}
+
+	// The client hello is split in two packets, sent in reverse order.
+	hello := clientHello("www.example.com")
+	c.send(true, initial(t, version1, clientCID, clientCID, clientSrc, false, 1, cryptoFrameData(100, hello[100:])))
+	c.send(true, initial(t, version1, clientCID, clientCID, clientSrc, false, 0, cryptoFrameData(0, hello[:100])))
+	assert.Empty(t, store.events)
+
+	// The server Initial packet is coalesced with a Handshake packet.
+	handshakePacket := []byte{0xe0, 0, 0, 0, 1, 2, 1, 2, 4, 0xf0, 0x67, 0xa5, 0x50, 0x01, 0x00}
+	c.send(false,
+		initial(t, version1, clientCID, clientSrc, serverCID, true, 0,
+			[]byte{frameAck, 0, 0, 0, 0}, cryptoFrameData(0, serverHello())),
+		handshakePacket)
+
+	require.Len(t, store.events, 1)
+	event := store.events[0]
+	assert.Equal(t, "quic", getValue(t, event, "type"))
+	assert.Equal(t, "quic", getValue(t, event, "network.protocol"))
+	assert.Equal(t, "udp", getValue(t, event, "network.transport"))
+	assert.Equal(t, "OK", getValue(t, event, "status"))
+	assert.Equal(t, "www.example.com", getValue(t, event, "destination.domain"))
+	assert.Equal(t, "1", getValue(t, event, "quic.version"))
+	assert.Equal(t, "8394c8f03e515708", getValue(t, event, "quic.destination_connection_id"))
+	assert.Equal(t, "0102", getValue(t, event, "quic.source_connection_id"))
+	assert.Equal(t, "f067a5502a4262b5", getValue(t, event, "quic.server_connection_id"))
+	assert.Equal(t, false, getValue(t, event, "quic.retry"))
+	assert.Equal(t, "www.example.com", getValue(t, event, "tls.client.server_name"))
+	assert.Equal(t, "1.3", getValue(t, event, "tls.version"))
+	assert.Equal(t, "TLS_AES_128_GCM_SHA256", getValue(t, event, "tls.cipher"))
+	assert.Regexp(t, "^q13d0307h3_", getValue(t, event, "tls.client.ja4"))
+	assert.Regexp(t, "^q130200_1301_", getValue(t, event, "tls.server.ja4s"))
+	assert.NotEmpty(t, getValue(t, event, "tls.client.ja3"))
+	assert.NotEmpty(t, getValue(t, event, "tls.server.ja3s"))
+	alpn := getValue(t, event, "tls.detailed.client_hello.extensions.application_layer_protocol_negotiation")
+	assert.Equal(t, []string{"h3"}, alpn)
+	assert.EqualValues(t, 52000, getValue(t, event, "source.port"))
+
+	// The packets after the server hello are ignored.
+	c.send(true, initial(t, version1, clientCID, serverCID, clientSrc, false, 2, []byte{frameAck, 0, 0, 0, 0}))
+	assert.Len(t, store.events, 1)
+}
+
+func TestRetryVersion2(t *testing.T) {
+	store := &eventStore{}
+	c := &testConn{t: t, quic: quicModForTests(t, store, time.Minute), ts: time.Now()}
+
+	hello := clientHello("www.example.com")
+	c.send(true, initial(t, version2, clientCID, clientCID, clientSrc, false, 0, cryptoFrameData(0, hello)))
+	c.send(false, retry(version2, clientSrc, retryCID))
+	// The keys of the packets after the Retry are derived from the
+	// connection ID chosen by the server.
+	c.send(true, initial(t, version2, retryCID, retryCID, clientSrc, false, 1, cryptoFrameData(0, hello)))
+	c.send(false, initial(t, version2, retryCID, clientSrc, serverCID, true, 0, cryptoFrameData(0, serverHello())))
+
+	require.Len(t, store.events, 1)
+	event := store.events[0]
+	assert.Equal(t, "OK", getValue(t, event, "status"))
+	assert.Equal(t, "2", getValue(t, event, "quic.version"))
+	assert.Equal(t, true, getValue(t, event, "quic.retry"))
+	assert.Equal(t, "8394c8f03e515708", getValue(t, event, "quic.destination_connection_id"))
+	assert.Equal(t, "f067a5502a4262b5", getValue(t, event, "quic.server_connection_id"))
+	assert.Equal(t, "1.3", getValue(t, event, "tls.version"))
+}
+
+func TestConnectionClose(t *testing.T) {
+	store := &eventStore{}
+	c := &testConn{t: t, quic: quicModForTests(t, store, time.Minute), ts: time.Now()}
+
+	c.send(true, initial(t, version1, clientCID, clientCID, clientSrc, false, 0, cryptoFrameData(0, clientHello("www.example.com"))))
+	// A handshake failure alert.
+	c.send(false, initial(t, version1, clientCID, clientSrc, serverCID, true, 0, closeFrame(0x128, "no application protocol")))
+
+	require.Len(t, store.events, 1)
+	event := store.events[0]
+	assert.Equal(t, "Error", getValue(t, event, "status"))
+	assert.Equal(t, "failure", getValue(t, event, "event.outcome"))
+	assert.Equal(t, "CRYPTO_ERROR_0x28", getValue(t, event, "quic.close_error"))
+	assert.EqualValues(t, 0x128, getValue(t, event, "quic.close_error_code"))
+	assert.Equal(t, "no application protocol", getValue(t, event, "quic.close_reason"))
+	assert.Equal(t, "www.example.com", getValue(t, event, "tls.client.server_name"))
+	_, err := event.GetValue("tls.version")
+	assert.Error(t, err)
+}
+
+func TestExpiredHandshake(t *testing.T) {
+	store := &eventStore{}
+	c := &testConn{t: t, quic: quicModForTests(t, store, 10*time.Millisecond), ts: time.Now()}
+
+	// A client of an unknown version, and the version negotiation of the
+	// server.
+	c.send(true, []byte{0xc0, 0x1a, 0x2a, 0x3a, 0x4a, 8, 0x83, 0x94, 0xc8, 0xf0, 0x3e, 0x51, 0x57, 0x08, 0, 0, 0x01, 0x00})
+	c.send(false, versionNegotiation(clientSrc, clientCID, version1, version2))
+	// Short header packets are ignored.
+	c.send(true, []byte{0x40, 1, 2, 3})
+
+	assert.Eventually(t, func() bool {
+		c.quic.connections.CleanUp()
+		return len(store.events) == 1
+	}, time.Second, 10*time.Millisecond)
+	event := store.events[0]
+	assert.Equal(t, "Error", getValue(t, event, "status"))
+	assert.Equal(t, "0x1a2a3a4a", getValue(t, event, "quic.version"))
+	assert.Equal(t, []string{"1", "2"}, getValue(t, event, "quic.supported_versions"))
+	assert.Equal(t, "Unsupported version", getValue(t, event, "error.message"))
+}
+
+func TestDecryptionFailure(t *testing.T) {
+	store := &eventStore{}
+	c := &testConn{t: t, quic: quicModForTests(t, store, 10*time.Millisecond), ts: time.Now()}
+
+	packet := initial(t, version1, clientCID, clientCID, clientSrc, false, 0, cryptoFrameData(0, clientHello("www.example.com")))
+	packet[len(packet)-1] ^= 0xff
+	c.send(true, packet)
+	// A server packet without a client Initial packet is ignored.
+	c.send(false, initial(t, version1, serverCID, clientSrc, serverCID, true, 0, cryptoFrameData(0, serverHello())))
+
+	assert.Eventually(t, func() bool {
+		c.quic.connections.CleanUp()
+		return len(store.events) == 1
+	}, time.Second, 10*time.Millisecond)
+	event := store.events[0]
+	assert.Equal(t, "Error", getValue(t, event, "status"))
+	assert.Equal(t, "Initial packet decryption failed", getValue(t, event, "error.message"))
+	_, err := event.GetValue("tls")
+	assert.Error(t, err)
+}
+
+func TestCryptoStream(t *testing.T) {
+	var s cryptoStream
+	msg := handshake(1, make([]byte, 10))
+	s.add(8, msg[8:])
+	s.add(4, msg[4:6])
+	_, ok := s.message()
+	assert.False(t, ok)
+	// Retransmitted data overlapping the stream.
+	s.add(0, msg[:5])
+	_, ok = s.message()
+	assert.False(t, ok)
+	s.add(5, msg[5:9])
+	data, ok := s.message()
+	assert.True(t, ok)
+	assert.Equal(t, msg, data)
+}
@@ -0,0 +1,73 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package smb
+
+import (
+	"bytes"
+	"encoding/binary"
+)
+
+var (
+	ntlmSignature = []byte("NTLMSSP\x00")
+	// krb5APReq is the Kerberos OID followed by the token ID of an AP-REQ, as
+	// found in the GSS-API token of a Kerberos authentication.
+	krb5APReq = []byte{0x2a, 0x86, 0x48, 0x86, 0xf7, 0x12, 0x01, 0x02, 0x02, 0x01, 0x00}
+)
+
+const (
+	ntlmAuthenticate = 3
+	ntlmUnicode      = 0x00000001
+)
+
+// parseSecurityBlob returns the authentication mechanism of the security
+// buffer of a session setup request, and the user of NTLM authentications.
+// The SPNEGO tokens are searched for the NTLM messages and Kerberos tickets
+// instead of being decoded.
+func parseSecurityBlob(blob []byte) authInfo {
+	var auth authInfo
+	if i := bytes.Index(blob, ntlmSignature); i >= 0 {
+		auth.mechanism = "ntlm"
+		msg := blob[i:]
+		if len(msg) >= 64 && binary.LittleEndian.Uint32(msg[8:]) == ntlmAuthenticate {
+			unicode := binary.LittleEndian.Uint32(msg[60:])&ntlmUnicode != 0
+			auth.domain = ntlmString(msg, 28, unicode)
+			auth.user = ntlmString(msg, 36, unicode)
+			auth.host = ntlmString(msg, 44, unicode)
+		}
+		return auth
+	}
+	if bytes.Contains(blob, krb5APReq) {
+		auth.mechanism = "kerberos"
+	}
+	return auth
+}
+
+// ntlmString returns the string whose length and offset are in the field at
+// off of an NTLM message.
+func ntlmString(msg []byte, off int, unicode bool) string {
+	n := int(binary.LittleEndian.Uint16(msg[off:]))
+	start := int(binary.LittleEndian.Uint32(msg[off+4:]))
+	if start+n > len(msg) {
+		return ""
+	}
+	s := msg[start : start+n]
+	if unicode {
+		return decodeUTF16(s)
+	}
+	return string(s)
+}
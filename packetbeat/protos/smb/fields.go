@@ -0,0 +1,36 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+// Code generated by beats/dev-tools/cmd/asset/asset.go - DO NOT EDIT.
+
+package smb
+
+import (
+	"github.com/elastic/beats/v7/libbeat/asset"
+)
+
+func init() {
+	if err := asset.SetFields("packetbeat", "smb", asset.ModuleFieldsPri, AssetSmb); err != nil {
+		panic(err)
+	}
+}
+
+// AssetSmb returns asset data.
+// This is the base64 encoded zlib format compressed contents of protos/smb.
+func AssetSmb() string {
+	return "eJy8mEFv4zYThu/5FYO97MUxkt3vQwsfCmSz7iLAJljUbnsJINPiyBpYIlVyHMf99QVFyrIjOs5W2toXRzHfefhyhhz6Eta4m4AtlxcATFzgBN7N7j+9uwCQaFNDFZNWE/jlAgBgdv/pAwgl3YePYCtMKaMU8AkVQ0ZYSDu+gPBpUg+5BCVKbEK4N+8qnMDK6E0Vnhx+/2gMWktaJST3/2qGr3G31ebweQS3ec9zbLTg7jPoDDhHKNFascIRkIIcn4XElEpRjA+G4rMoq9qTq+cr9/rfVfP6/7uLDq8kUWDK/WGDEChcaSbBKEGrGjrVSmHqxkU5P46vx9cnwexgZBZ0lqFBCcud5yrI5QB5yj03GPxrg5bHXaZUl6VQsj9SEGqWdR8xYk9qUDB2WUIqxBKt0Gr1dpYgdJBlAWcEG4sSWEMpOM2B2IJBW2llMWIOGxyApqVwek3uHGKBqKqC0ALrCIXNhcH+C1QJzhuQWvIkQWTJ3j8+WjRPaB7roe9PUCbOn/6oTuUYVWcgjuybwEKSXS9GsKiowgVoA4vKkOLFKQsTVKnZVYyHJC7UBJZaFyjU2wj/zJFzNK/R1baSQQshZr1TdLAyKgZwy/nfuOUUT6zrCAwWgukJXf5zfhgZ/ETiSy91ah8NVtrwmJ85tvYseDPAtvYwD1LNdNrajIDN5jfz32fJze3tdDZLPk8f7qafT7ElqZbYs47VpkRD6WuU3ei0UkPmW7OsuvnT+wNkQ6gTDKRWScjJAWn8ltAme4g0gszosnMEvWqSAxTFShvivOyfSUES9pJg0TUF7VEZ2LfEueuj/JkdzbOb6ezyy/3NbRe8Le4kpSpH0x+8lQQv2RP8+sPPl19u77vsqajEkgpiwgEq91CtKQvv8Pcmww/YpEM7YEGYvcEox4etQl3LsdDQtKx+tN8GUbpuVYAltSowNN5VvVZ1qwzHHRUAG6GsqBtGYCpRb3hUr6DesEMk0zRiNmJJKHrbf/9aojmaaDt96/rGkGG+i4yC+Br+z0h8CkVIxIbzpMQ0F4rsAPuF00PFlArHDHtlxyj2y2qRN1WzB49gobgoXedxJLdYo1mi0TbWiGy1WbsTibTqD3148IfO/0A/FJ5Q8DD/ev9ihhG0MMkkK8TK9oerZeL2+SSawGLlfHQtnNoURdfIkByJFCxiZvp7RCLJVtrSMJ6GIg1rjBK2Oaq2tarn4+Puz2J8Jsv2Jb3S8cuPrlAllJ2cjQcYbCIs1qiOy8ldYl9OIorqvyK7qM6KxNLfZzuqTJtS8ASWO0b7dnYn3SS2i1Wb5o/uLniHTpLBlLXZDXeCnIFw7Zdo40aYdJZZ5HN2nbHFiwC9zEeDQrr7z9bQq8YUqFacn4P4l2vW7un1wAbD37aXeATJGNuA6kMyQxNrUX8U4zkm0ikX8cvDd1dkqhUbXYBTcwxCefnGqrf/BHZ9nV5d/xT56avWSxx6f9yj0+UMehT119nt/Gvy7e7bNJn/dvMwu53e/THtMivcDkWM2z218PVh0D0IReuLJ9MtdQdGYoGMSYVKkloNs4F0A0MpzNq2VZxpAxILZNJqfPHPAB/pSJY="
+}
@@ -0,0 +1,434 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package smb
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"time"
+	"unicode/utf16"
+
+	"github.com/elastic/beats/v7/libbeat/common"
+)
+
+const (
+	headerSize          = 64
+	transformHeaderSize = 52
+
+	// Flags of the SMB2 header.
+	flagServerToRedir     = 0x00000001
+	flagAsyncCommand      = 0x00000002
+	flagRelatedOperations = 0x00000004
+	flagSigned            = 0x00000008
+
+	// Security mode bits of the negotiate messages.
+	securitySigningRequired = 0x0002
+
+	// Negotiate context types.
+	contextEncryptionCapabilities = 0x0002
+	contextSigningCapabilities    = 0x0008
+
+	// shareFlagEncryptData is set on shares requiring encryption.
+	shareFlagEncryptData = 0x00008000
+	// fileAttributeDirectory is set on directories.
+	fileAttributeDirectory = 0x00000010
+
+	// errorResponseSize is the structure size of the error responses.
+	errorResponseSize = 9
+)
+
+var (
+	protocolSMB1      = []byte{0xff, 'S', 'M', 'B'}
+	protocolSMB2      = []byte{0xfe, 'S', 'M', 'B'}
+	protocolTransform = []byte{0xfd, 'S', 'M', 'B'}
+	protocolCompress  = []byte{0xfc, 'S', 'M', 'B'}
+
+	errTruncated       = errors.New("truncated message")
+	errUnknownProtocol = errors.New("unknown protocol identifier")
+)
+
+// fileID identifies an open file.
+type fileID [16]byte
+
+// relatedFileID is the file ID of the compounded requests applying to the
+// file opened by the previous request.
+var relatedFileID = fileID{
+	0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff,
+	0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff,
+}
+
+// header is the header of an SMB2 message.
+type header struct {
+	command   uint16
+	status    uint32
+	flags     uint32
+	next      uint32
+	messageID uint64
+	treeID    uint32
+	sessionID uint64
+}
+
+func (h *header) isResponse() bool {
+	return h.flags&flagServerToRedir != 0
+}
+
+// message is an SMB2 message. Compounded messages are split in several
+// messages.
+type message struct {
+	ts time.Time
+	// tuple and cmdlineTuple are oriented from the sender of the message.
+	tuple        common.IPPortTuple
+	cmdlineTuple *common.ProcessTuple
+	size         int
+
+	header
+
+	// encrypted is set on the messages of encrypted sessions, whose header is
+	// a transform header.
+	encrypted bool
+
+	// Negotiate
+	dialects         []uint16
+	securityMode     uint16
+	dialect          uint16
+	capabilities     uint32
+	cipher           uint16
+	hasCipher        bool
+	signingAlgorithm uint16
+	hasSigning       bool
+
+	// Session setup
+	auth         authInfo
+	sessionFlags uint16
+
+	// Tree connect
+	shareType  uint8
+	shareFlags uint32
+
+	// Requests applying to a file, and the name of the created file or of the
+	// tree connect path.
+	fileID fileID
+	hasID  bool
+	path   string
+
+	// Create
+	disposition    uint32
+	createAction   uint32
+	fileSize       uint64
+	fileAttributes uint32
+
+	// Read and write
+	offset      uint64
+	length      uint32
+	transferred uint32
+
+	// Ioctl
+	ctlCode uint32
+
+	// Set info
+	infoClass     uint8
+	newName       string
+	deletePending bool
+}
+
+// authInfo is the authentication of a session setup request.
+type authInfo struct {
+	mechanism string
+	user      string
+	domain    string
+	host      string
+}
+
+// parseMessages parses an SMB message, splitting compounded messages. A
+// truncated message is parsed up to the end of data.
+func parseMessages(data []byte, size int) ([]*message, error) {
+	switch {
+	case len(data) < 4:
+		return nil, errTruncated
+	case bytes.HasPrefix(data, protocolTransform):
+		return parseTransform(data, size)
+	case bytes.HasPrefix(data, protocolSMB1), bytes.HasPrefix(data, protocolCompress):
+		// SMB1 negotiations preceding SMB2 and compressed messages can't
+		// be decoded.
+		return nil, nil
+	case !bytes.HasPrefix(data, protocolSMB2):
+		return nil, errUnknownProtocol
+	}
+
+	var msgs []*message
+	var prev *message
+	for {
+		m := &message{}
+		if err := m.parseHeader(data); err != nil {
+			if prev == nil {
+				return nil, err
+			}
+			// Truncated compounded message.
+			return msgs, nil
+		}
+		end := len(data)
+		if m.next != 0 && int(m.next) >= headerSize && int(m.next) <= len(data) {
+			end = int(m.next)
+		}
+		m.size = end
+		if m.next == 0 {
+			m.size = size
+		}
+		m.parseBody(data[:end])
+		if prev != nil && m.flags&flagRelatedOperations != 0 {
+			m.relate(prev)
+		}
+		msgs = append(msgs, m)
+		if m.next == 0 || end != int(m.next) {
+			return msgs, nil
+		}
+		size -= end
+		data = data[end:]
+		prev = m
+	}
+}
+
+// parseTransform parses the transform header of an encrypted message.
+func parseTransform(data []byte, size int) ([]*message, error) {
+	if len(data) < transformHeaderSize {
+		return nil, errTruncated
+	}
+	m := &message{
+		encrypted: true,
+		size:      size,
+	}
+	m.sessionID = binary.LittleEndian.Uint64(data[44:])
+	return []*message{m}, nil
+}
+
+func (m *message) parseHeader(data []byte) error {
+	if len(data) < headerSize {
+		return errTruncated
+	}
+	if !bytes.HasPrefix(data, protocolSMB2) {
+		return errUnknownProtocol
+	}
+	m.status = binary.LittleEndian.Uint32(data[8:])
+	m.command = binary.LittleEndian.Uint16(data[12:])
+	m.flags = binary.LittleEndian.Uint32(data[16:])
+	m.next = binary.LittleEndian.Uint32(data[20:])
+	m.messageID = binary.LittleEndian.Uint64(data[24:])
+	if m.flags&flagAsyncCommand == 0 {
+		m.treeID = binary.LittleEndian.Uint32(data[36:])
+	}
+	m.sessionID = binary.LittleEndian.Uint64(data[40:])
+	return nil
+}
+
+// relate sets the context of a compounded request from the previous request
+// of the chain.
+func (m *message) relate(prev *message) {
+	if m.isResponse() {
+		return
+	}
+	if m.sessionID == 0 || m.sessionID == ^uint64(0) {
+		m.sessionID = prev.sessionID
+	}
+	if m.treeID == 0 || m.treeID == ^uint32(0) {
+		m.treeID = prev.treeID
+	}
+	if m.hasID && m.fileID == relatedFileID {
+		m.hasID = false
+		m.path = prev.path
+		if prev.hasID {
+			m.fileID, m.hasID = prev.fileID, true
+		}
+	}
+}
+
+// parseBody parses the body of the commands that are reported. Fields that
+// are out of the bounds of the message are ignored.
+func (m *message) parseBody(data []byte) {
+	b := body{data: data, base: headerSize}
+	if m.isResponse() && b.uint16(0) == errorResponseSize && m.status != statusSuccess &&
+		m.command != cmdSessionSetup {
+		return
+	}
+	if off, ok := fileIDOffsets[m.command]; ok && !m.isResponse() {
+		m.fileID, m.hasID = b.fileID(off)
+	}
+	switch {
+	case m.command == cmdNegotiate && !m.isResponse():
+		m.securityMode = b.uint16(4)
+		count := int(b.uint16(2))
+		for i := 0; i < count; i++ {
+			if !b.has(36+2*i, 2) {
+				break
+			}
+			m.dialects = append(m.dialects, b.uint16(36+2*i))
+		}
+	case m.command == cmdNegotiate:
+		m.securityMode = b.uint16(2)
+		m.dialect = b.uint16(4)
+		m.capabilities = b.uint32(24)
+		m.parseNegotiateContexts(data, int(b.uint16(6)), int(b.uint32(60)))
+	case m.command == cmdSessionSetup && !m.isResponse():
+		m.auth = parseSecurityBlob(b.buffer(12, 14))
+	case m.command == cmdSessionSetup:
+		m.sessionFlags = b.uint16(2)
+	case m.command == cmdTreeConnect && !m.isResponse():
+		m.path = decodeUTF16(b.buffer(4, 6))
+	case m.command == cmdTreeConnect:
+		m.shareType = b.uint8(2)
+		m.shareFlags = b.uint32(4)
+	case m.command == cmdCreate && !m.isResponse():
+		m.disposition = b.uint32(36)
+		m.path = decodeUTF16(b.buffer(44, 46))
+	case m.command == cmdCreate:
+		m.createAction = b.uint32(4)
+		m.fileSize = b.uint64(48)
+		m.fileAttributes = b.uint32(56)
+		m.fileID, m.hasID = b.fileID(64)
+	case m.command == cmdRead && !m.isResponse():
+		m.length = b.uint32(4)
+		m.offset = b.uint64(8)
+	case m.command == cmdRead:
+		m.transferred = b.uint32(4)
+	case m.command == cmdWrite && !m.isResponse():
+		m.length = b.uint32(4)
+		m.offset = b.uint64(8)
+	case m.command == cmdWrite:
+		m.transferred = b.uint32(4)
+	case m.command == cmdIoctl && !m.isResponse():
+		m.ctlCode = b.uint32(4)
+	case m.command == cmdSetInfo && !m.isResponse():
+		m.parseSetInfo(data, b)
+	}
+}
+
+// parseNegotiateContexts parses the negotiate contexts of a negotiate
+// response, with the cipher and signing algorithm selected by the server.
+func (m *message) parseNegotiateContexts(data []byte, count, offset int) {
+	for i := 0; i < count && offset+8 <= len(data); i++ {
+		typ := binary.LittleEndian.Uint16(data[offset:])
+		length := int(binary.LittleEndian.Uint16(data[offset+2:]))
+		ctx := body{data: data[offset+8 : min(offset+8+length, len(data))]}
+		switch typ {
+		case contextEncryptionCapabilities:
+			if ctx.uint16(0) > 0 && ctx.has(2, 2) {
+				m.cipher, m.hasCipher = ctx.uint16(2), true
+			}
+		case contextSigningCapabilities:
+			if ctx.uint16(0) > 0 && ctx.has(2, 2) {
+				m.signingAlgorithm, m.hasSigning = ctx.uint16(2), true
+			}
+		}
+		// Contexts are 8-byte aligned.
+		offset += 8 + (length+7)&^7
+	}
+}
+
+// parseSetInfo parses the file information of the requests renaming and
+// deleting files.
+func (m *message) parseSetInfo(data []byte, b body) {
+	const infoTypeFile = 1
+	if b.uint8(2) != infoTypeFile {
+		return
+	}
+	m.infoClass = b.uint8(3)
+	off, length := int(b.uint16(8)), int(b.uint32(4))
+	if off < headerSize || off+length > len(data) {
+		return
+	}
+	info := body{data: data[off : off+length]}
+	switch m.infoClass {
+	case fileRenameInformation:
+		// FILE_RENAME_INFORMATION_TYPE_2
+		n := int(info.uint32(16))
+		if info.has(20, n) {
+			m.newName = decodeUTF16(info.data[20 : 20+n])
+		}
+	case fileDispositionInformation:
+		m.deletePending = info.uint8(0) != 0
+	case fileDispositionInformationEx:
+		const fileDispositionDelete = 0x1
+		m.deletePending = info.uint32(0)&fileDispositionDelete != 0
+	}
+}
+
+// body reads the little endian fields of a structure starting at base.
+// Fields out of bounds are read as zero.
+type body struct {
+	data []byte
+	base int
+}
+
+func (b body) has(off, n int) bool {
+	return off >= 0 && n >= 0 && b.base+off+n <= len(b.data)
+}
+
+func (b body) uint8(off int) uint8 {
+	if !b.has(off, 1) {
+		return 0
+	}
+	return b.data[b.base+off]
+}
+
+func (b body) uint16(off int) uint16 {
+	if !b.has(off, 2) {
+		return 0
+	}
+	return binary.LittleEndian.Uint16(b.data[b.base+off:])
+}
+
+func (b body) uint32(off int) uint32 {
+	if !b.has(off, 4) {
+		return 0
+	}
+	return binary.LittleEndian.Uint32(b.data[b.base+off:])
+}
+
+func (b body) uint64(off int) uint64 {
+	if !b.has(off, 8) {
+		return 0
+	}
+	return binary.LittleEndian.Uint64(b.data[b.base+off:])
+}
+
+func (b body) fileID(off int) (fileID, bool) {
+	var id fileID
+	if !b.has(off, len(id)) {
+		return id, false
+	}
+	copy(id[:], b.data[b.base+off:])
+	return id, true
+}
+
+// buffer returns the buffer whose offset from the start of the message and
+// length are the 16-bit fields at offOff and lenOff.
+func (b body) buffer(offOff, lenOff int) []byte {
+	off, n := int(b.uint16(offOff)), int(b.uint16(lenOff))
+	if off < headerSize || off+n > len(b.data) {
+		return nil
+	}
+	return b.data[off : off+n]
+}
+
+func decodeUTF16(b []byte) string {
+	u := make([]uint16, len(b)/2)
+	for i := range u {
+		u[i] = binary.LittleEndian.Uint16(b[2*i:])
+	}
+	return string(utf16.Decode(u))
+}
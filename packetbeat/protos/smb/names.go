@@ -0,0 +1,288 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package smb
+
+import "fmt"
+
+// SMB2 commands.
+const (
+	cmdNegotiate      = 0x00
+	cmdSessionSetup   = 0x01
+	cmdLogoff         = 0x02
+	cmdTreeConnect    = 0x03
+	cmdTreeDisconnect = 0x04
+	cmdCreate         = 0x05
+	cmdClose          = 0x06
+	cmdFlush          = 0x07
+	cmdRead           = 0x08
+	cmdWrite          = 0x09
+	cmdLock           = 0x0a
+	cmdIoctl          = 0x0b
+	cmdCancel         = 0x0c
+	cmdEcho           = 0x0d
+	cmdQueryDirectory = 0x0e
+	cmdChangeNotify   = 0x0f
+	cmdQueryInfo      = 0x10
+	cmdSetInfo        = 0x11
+	cmdOplockBreak    = 0x12
+)
+
+var commandNames = map[uint16]string{
+	cmdNegotiate:      "negotiate",
+	cmdSessionSetup:   "session_setup",
+	cmdLogoff:         "logoff",
+	cmdTreeConnect:    "tree_connect",
+	cmdTreeDisconnect: "tree_disconnect",
+	cmdCreate:         "create",
+	cmdClose:          "close",
+	cmdFlush:          "flush",
+	cmdRead:           "read",
+	cmdWrite:          "write",
+	cmdLock:           "lock",
+	cmdIoctl:          "ioctl",
+	cmdCancel:         "cancel",
+	cmdEcho:           "echo",
+	cmdQueryDirectory: "query_directory",
+	cmdChangeNotify:   "change_notify",
+	cmdQueryInfo:      "query_info",
+	cmdSetInfo:        "set_info",
+	cmdOplockBreak:    "oplock_break",
+}
+
+func commandName(cmd uint16) string {
+	if name, ok := commandNames[cmd]; ok {
+		return name
+	}
+	return fmt.Sprintf("0x%02x", cmd)
+}
+
+// fileIDOffsets are the offsets of the file ID in the body of the requests
+// applying to an open file.
+var fileIDOffsets = map[uint16]int{
+	cmdClose:          8,
+	cmdFlush:          8,
+	cmdRead:           16,
+	cmdWrite:          16,
+	cmdLock:           8,
+	cmdIoctl:          8,
+	cmdQueryDirectory: 8,
+	cmdChangeNotify:   8,
+	cmdQueryInfo:      24,
+	cmdSetInfo:        16,
+}
+
+// NT status codes.
+const (
+	statusSuccess                = 0x00000000
+	statusPending                = 0x00000103
+	statusMoreProcessingRequired = 0xc0000016
+)
+
+var statusNames = map[uint32]string{
+	statusSuccess:                "STATUS_SUCCESS",
+	statusPending:                "STATUS_PENDING",
+	0x0000010c:                   "STATUS_NOTIFY_ENUM_DIR",
+	0x80000005:                   "STATUS_BUFFER_OVERFLOW",
+	0x80000006:                   "STATUS_NO_MORE_FILES",
+	0xc0000001:                   "STATUS_UNSUCCESSFUL",
+	0xc0000002:                   "STATUS_NOT_IMPLEMENTED",
+	0xc0000003:                   "STATUS_INVALID_INFO_CLASS",
+	0xc0000008:                   "STATUS_INVALID_HANDLE",
+	0xc000000d:                   "STATUS_INVALID_PARAMETER",
+	0xc000000f:                   "STATUS_NO_SUCH_FILE",
+	0xc0000010:                   "STATUS_INVALID_DEVICE_REQUEST",
+	0xc0000011:                   "STATUS_END_OF_FILE",
+	statusMoreProcessingRequired: "STATUS_MORE_PROCESSING_REQUIRED",
+	0xc0000022:                   "STATUS_ACCESS_DENIED",
+	0xc0000023:                   "STATUS_BUFFER_TOO_SMALL",
+	0xc0000033:                   "STATUS_OBJECT_NAME_INVALID",
+	0xc0000034:                   "STATUS_OBJECT_NAME_NOT_FOUND",
+	0xc0000035:                   "STATUS_OBJECT_NAME_COLLISION",
+	0xc000003a:                   "STATUS_OBJECT_PATH_NOT_FOUND",
+	0xc0000043:                   "STATUS_SHARING_VIOLATION",
+	0xc0000054:                   "STATUS_FILE_LOCK_CONFLICT",
+	0xc0000055:                   "STATUS_LOCK_NOT_GRANTED",
+	0xc0000056:                   "STATUS_DELETE_PENDING",
+	0xc0000061:                   "STATUS_PRIVILEGE_NOT_HELD",
+	0xc000006a:                   "STATUS_WRONG_PASSWORD",
+	0xc000006d:                   "STATUS_LOGON_FAILURE",
+	0xc000006e:                   "STATUS_ACCOUNT_RESTRICTION",
+	0xc000006f:                   "STATUS_INVALID_LOGON_HOURS",
+	0xc0000070:                   "STATUS_INVALID_WORKSTATION",
+	0xc0000071:                   "STATUS_PASSWORD_EXPIRED",
+	0xc0000072:                   "STATUS_ACCOUNT_DISABLED",
+	0xc000007f:                   "STATUS_DISK_FULL",
+	0xc000009a:                   "STATUS_INSUFFICIENT_RESOURCES",
+	0xc00000b5:                   "STATUS_IO_TIMEOUT",
+	0xc00000ba:                   "STATUS_FILE_IS_A_DIRECTORY",
+	0xc00000bb:                   "STATUS_NOT_SUPPORTED",
+	0xc00000c9:                   "STATUS_NETWORK_NAME_DELETED",
+	0xc00000cc:                   "STATUS_BAD_NETWORK_NAME",
+	0xc0000101:                   "STATUS_DIRECTORY_NOT_EMPTY",
+	0xc0000103:                   "STATUS_NOT_A_DIRECTORY",
+	0xc0000120:                   "STATUS_CANCELLED",
+	0xc0000128:                   "STATUS_FILE_CLOSED",
+	0xc000014b:                   "STATUS_PIPE_BROKEN",
+	0xc000015b:                   "STATUS_LOGON_TYPE_NOT_GRANTED",
+	0xc000018d:                   "STATUS_TRUSTED_RELATIONSHIP_FAILURE",
+	0xc0000193:                   "STATUS_ACCOUNT_EXPIRED",
+	0xc0000203:                   "STATUS_USER_SESSION_DELETED",
+	0xc0000224:                   "STATUS_PASSWORD_MUST_CHANGE",
+	0xc0000225:                   "STATUS_NOT_FOUND",
+	0xc0000234:                   "STATUS_ACCOUNT_LOCKED_OUT",
+	0xc0000257:                   "STATUS_PATH_NOT_COVERED",
+	0xc000035c:                   "STATUS_NETWORK_SESSION_EXPIRED",
+	0xc0000466:                   "STATUS_SERVER_UNAVAILABLE",
+}
+
+func statusName(status uint32) string {
+	if name, ok := statusNames[status]; ok {
+		return name
+	}
+	return fmt.Sprintf("0x%08x", status)
+}
+
+// isFailure returns whether the status reports an error. The intermediate
+// responses of the multi-leg authentications aren't failures.
+func isFailure(status uint32) bool {
+	return status>>30 == 3 && status != statusMoreProcessingRequired
+}
+
+var dialectNames = map[uint16]string{
+	0x0202: "2.0.2",
+	0x0210: "2.1",
+	0x0222: "2.2.2",
+	0x0224: "2.2.4",
+	0x02ff: "2.???",
+	0x0300: "3.0",
+	0x0302: "3.0.2",
+	0x0310: "3.1",
+	0x0311: "3.1.1",
+}
+
+func dialectName(dialect uint16) string {
+	if name, ok := dialectNames[dialect]; ok {
+		return name
+	}
+	return fmt.Sprintf("0x%04x", dialect)
+}
+
+var cipherNames = map[uint16]string{
+	1: "AES-128-CCM",
+	2: "AES-128-GCM",
+	3: "AES-256-CCM",
+	4: "AES-256-GCM",
+}
+
+var signingAlgorithmNames = map[uint16]string{
+	0: "HMAC-SHA256",
+	1: "AES-CMAC",
+	2: "AES-GMAC",
+}
+
+func nameOr(names map[uint16]string, v uint16) string {
+	if name, ok := names[v]; ok {
+		return name
+	}
+	return fmt.Sprintf("0x%04x", v)
+}
+
+// flagNames returns the names of the flags set in v.
+func flagNames(names []string, v uint32) []string {
+	var set []string
+	for i, name := range names {
+		if name != "" && v&(1<<i) != 0 {
+			set = append(set, name)
+		}
+	}
+	return set
+}
+
+// capabilityNames are the names of the bits of the negotiated capabilities.
+var capabilityNames = []string{
+	"dfs",
+	"leasing",
+	"large_mtu",
+	"multi_channel",
+	"persistent_handles",
+	"directory_leasing",
+	"encryption",
+	"notifications",
+}
+
+// sessionFlagNames are the names of the bits of the session flags.
+var sessionFlagNames = []string{
+	"guest",
+	"null",
+	"encrypt_data",
+}
+
+var shareTypeNames = map[uint8]string{
+	1: "disk",
+	2: "pipe",
+	3: "print",
+}
+
+var createDispositionNames = []string{
+	"supersede",
+	"open",
+	"create",
+	"open_if",
+	"overwrite",
+	"overwrite_if",
+}
+
+var createActionNames = []string{
+	"superseded",
+	"opened",
+	"created",
+	"overwritten",
+}
+
+func indexName(names []string, v uint32) string {
+	if int(v) < len(names) {
+		return names[v]
+	}
+	return fmt.Sprintf("0x%x", v)
+}
+
+var ioctlNames = map[uint32]string{
+	0x00060194: "FSCTL_DFS_GET_REFERRALS",
+	0x000601b0: "FSCTL_DFS_GET_REFERRALS_EX",
+	0x000900a4: "FSCTL_SET_REPARSE_POINT",
+	0x000900a8: "FSCTL_GET_REPARSE_POINT",
+	0x00098208: "FSCTL_FILE_LEVEL_TRIM",
+	0x00110018: "FSCTL_PIPE_WAIT",
+	0x0011400c: "FSCTL_PIPE_PEEK",
+	0x0011c017: "FSCTL_PIPE_TRANSCEIVE",
+	0x00140078: "FSCTL_SRV_REQUEST_RESUME_KEY",
+	0x001401d4: "FSCTL_LMR_REQUEST_RESILIENCY",
+	0x001401fc: "FSCTL_QUERY_NETWORK_INTERFACE_INFO",
+	0x00140204: "FSCTL_VALIDATE_NEGOTIATE_INFO",
+	0x00144064: "FSCTL_SRV_ENUMERATE_SNAPSHOTS",
+	0x001440f2: "FSCTL_SRV_COPYCHUNK",
+	0x001441bb: "FSCTL_SRV_READ_HASH",
+	0x001480f2: "FSCTL_SRV_COPYCHUNK_WRITE",
+}
+
+// File information classes of the SET_INFO requests that are reported.
+const (
+	fileRenameInformation        = 10
+	fileDispositionInformation   = 13
+	fileDispositionInformationEx = 64
+)
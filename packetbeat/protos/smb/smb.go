@@ -0,0 +1,481 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package smb
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/elastic/beats/v7/libbeat/beat"
+	"github.com/elastic/beats/v7/libbeat/common"
+	conf "github.com/elastic/elastic-agent-libs/config"
+	"github.com/elastic/elastic-agent-libs/logp"
+	"github.com/elastic/elastic-agent-libs/mapstr"
+	"github.com/elastic/elastic-agent-libs/monitoring"
+
+	"github.com/elastic/beats/v7/packetbeat/pb"
+	"github.com/elastic/beats/v7/packetbeat/procs"
+	"github.com/elastic/beats/v7/packetbeat/protos"
+)
+
+// maxOpenFiles is the maximum number of open files and tree connects whose
+// names are kept per connection.
+const maxOpenFiles = 4096
+
+type connectionData struct {
+	streams [2]*stream
+	// pending are the requests waiting for their response, in the order
+	// they were sent.
+	pending []*transaction
+
+	dialect uint16
+	// trees are the paths of the tree connects, by tree ID.
+	trees map[uint32]string
+	// files are the names of the open files, by file ID.
+	files map[fileID]string
+	// users are the authentications of the sessions, by session ID.
+	users map[uint64]authInfo
+
+	// encrypted counts the messages of an encrypted session.
+	encrypted *transaction
+}
+
+func newConnectionData() *connectionData {
+	return &connectionData{
+		trees: make(map[uint32]string),
+		files: make(map[fileID]string),
+		users: make(map[uint64]authInfo),
+	}
+}
+
+// transaction is a request and its response, or the messages of an encrypted
+// session.
+type transaction struct {
+	requ *message
+	resp *message
+
+	dialect uint16
+	// share and file are the path of the tree connect and the name of the
+	// file the request applies to.
+	share string
+	file  string
+	user  authInfo
+
+	// Encrypted messages sent by the client and the server.
+	requests, responses  int
+	requBytes, respBytes int
+	end                  time.Time
+}
+
+// SMB protocol plugin
+type smbPlugin struct {
+	// config
+	ports              []int
+	maxPendingRequests int
+	transactionTimeout time.Duration
+
+	watcher *procs.ProcessesWatcher
+	results protos.Reporter
+}
+
+var (
+	debugf  = logp.MakeDebug("smb")
+	isDebug = false
+)
+
+var (
+	unmatchedResponses = monitoring.NewInt(nil, "smb.unmatched_responses")
+	unmatchedRequests  = monitoring.NewInt(nil, "smb.unmatched_requests")
+)
+
+func init() {
+	protos.Register("smb", New)
+}
+
+func New(
+	testMode bool,
+	results protos.Reporter,
+	watcher *procs.ProcessesWatcher,
+	cfg *conf.C,
+) (protos.Plugin, error) {
+	p := &smbPlugin{}
+	config := defaultConfig
+	if !testMode {
+		if err := cfg.Unpack(&config); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := p.init(results, watcher, &config); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+func (sp *smbPlugin) init(results protos.Reporter, watcher *procs.ProcessesWatcher, config *smbConfig) error {
+	sp.setFromConfig(config)
+	sp.results = results
+	sp.watcher = watcher
+	isDebug = logp.IsDebug("smb")
+	return nil
+}
+
+func (sp *smbPlugin) setFromConfig(config *smbConfig) {
+	sp.ports = config.Ports
+	sp.maxPendingRequests = config.MaxPendingRequests
+	sp.transactionTimeout = config.TransactionTimeout
+}
+
+func (sp *smbPlugin) GetPorts() []int {
+	return sp.ports
+}
+
+func (sp *smbPlugin) ConnectionTimeout() time.Duration {
+	return sp.transactionTimeout
+}
+
+// isServerPort returns whether a port is one of the configured SMB ports.
+func (sp *smbPlugin) isServerPort(port uint16) bool {
+	return slices.Contains(sp.ports, int(port))
+}
+
+func (sp *smbPlugin) handleMessage(conn *connectionData, m *message) {
+	if m.isResponse() {
+		sp.handleResponse(conn, m)
+		return
+	}
+	switch m.command {
+	case cmdCancel, cmdEcho:
+		return
+	}
+	t := &transaction{
+		requ:    m,
+		dialect: conn.dialect,
+		share:   conn.trees[m.treeID],
+		user:    conn.users[m.sessionID],
+	}
+	switch {
+	case m.command == cmdTreeConnect:
+		t.share = m.path
+	case m.command == cmdCreate || m.path != "":
+		t.file = m.path
+	case m.hasID:
+		t.file = conn.files[m.fileID]
+	}
+	sp.startTransaction(conn, t)
+}
+
+func (sp *smbPlugin) handleResponse(conn *connectionData, m *message) {
+	switch {
+	case m.messageID == ^uint64(0):
+		// Oplock break notification.
+		return
+	case m.flags&flagAsyncCommand != 0 && m.status == statusPending:
+		// Interim response, the final response follows.
+		return
+	case m.command == cmdNegotiate && m.status == statusSuccess:
+		conn.dialect = m.dialect
+	}
+
+	i := slices.IndexFunc(conn.pending, func(t *transaction) bool {
+		return t.requ.messageID == m.messageID && t.requ.command == m.command
+	})
+	if i < 0 {
+		unmatchedResponses.Add(1)
+		if isDebug {
+			debugf("response with message ID %d has no matching request", m.messageID)
+		}
+		return
+	}
+	t := conn.pending[i]
+	conn.pending = slices.Delete(conn.pending, i, i+1)
+	t.resp = m
+	sp.updateConnection(conn, t)
+	sp.publishTransaction(t)
+}
+
+// updateConnection keeps track of the dialect, sessions, tree connects and
+// open files of a connection.
+func (sp *smbPlugin) updateConnection(conn *connectionData, t *transaction) {
+	requ, resp := t.requ, t.resp
+	if resp.status != statusSuccess {
+		return
+	}
+	switch requ.command {
+	case cmdNegotiate:
+		t.dialect = resp.dialect
+	case cmdSessionSetup:
+		if requ.auth.user != "" {
+			t.user = requ.auth
+			conn.users[resp.sessionID] = requ.auth
+		}
+	case cmdLogoff:
+		delete(conn.users, requ.sessionID)
+	case cmdTreeConnect:
+		if len(conn.trees) >= maxOpenFiles {
+			clear(conn.trees)
+		}
+		conn.trees[resp.treeID] = requ.path
+	case cmdTreeDisconnect:
+		delete(conn.trees, requ.treeID)
+	case cmdCreate:
+		if len(conn.files) >= maxOpenFiles {
+			clear(conn.files)
+		}
+		conn.files[resp.fileID] = t.file
+	case cmdClose:
+		if requ.hasID {
+			delete(conn.files, requ.fileID)
+		}
+	}
+}
+
+// startTransaction adds a request to the pending requests. A pending request
+// with the same message ID is published without response.
+func (sp *smbPlugin) startTransaction(conn *connectionData, t *transaction) {
+	i := slices.IndexFunc(conn.pending, func(prev *transaction) bool {
+		return prev.requ.messageID == t.requ.messageID
+	})
+	if i >= 0 {
+		unmatchedRequests.Add(1)
+		sp.publishTransaction(conn.pending[i])
+		conn.pending = slices.Delete(conn.pending, i, i+1)
+	}
+	if len(conn.pending) >= sp.maxPendingRequests {
+		unmatchedRequests.Add(1)
+		sp.publishTransaction(conn.pending[0])
+		conn.pending = conn.pending[1:]
+	}
+	conn.pending = append(conn.pending, t)
+}
+
+// handleEncrypted counts the messages of an encrypted session. They are
+// reported in a single event per session and transaction timeout.
+// m.tuple is oriented from the client.
+func (sp *smbPlugin) handleEncrypted(conn *connectionData, m *message, fromClient bool) {
+	t := conn.encrypted
+	if t != nil && (t.requ.sessionID != m.sessionID || m.ts.Sub(t.requ.ts) >= sp.transactionTimeout) {
+		sp.publishTransaction(t)
+		t = nil
+	}
+	if t == nil {
+		t = &transaction{
+			requ:    m,
+			dialect: conn.dialect,
+			user:    conn.users[m.sessionID],
+		}
+		conn.encrypted = t
+	}
+	if fromClient {
+		t.requests++
+		t.requBytes += m.size
+	} else {
+		t.responses++
+		t.respBytes += m.size
+	}
+	t.end = m.ts
+}
+
+// expireRequests publishes the requests still waiting for a response, and
+// the messages of the encrypted session.
+func (sp *smbPlugin) expireRequests(conn *connectionData) {
+	for _, t := range conn.pending {
+		unmatchedRequests.Add(1)
+		sp.publishTransaction(t)
+	}
+	conn.pending = nil
+	if conn.encrypted != nil {
+		sp.publishTransaction(conn.encrypted)
+		conn.encrypted = nil
+	}
+}
+
+func (sp *smbPlugin) publishTransaction(t *transaction) {
+	if sp.results == nil {
+		return
+	}
+	sp.results(newEvent(t))
+}
+
+func newEvent(t *transaction) beat.Event {
+	requ := t.requ
+	src, dst := common.MakeEndpointPair(requ.tuple.BaseTuple, requ.cmdlineTuple)
+
+	evt, pbf := pb.NewBeatEvent(requ.ts)
+	pbf.SetSource(&src)
+	pbf.SetDestination(&dst)
+	pbf.AddIP(src.IP)
+	pbf.AddIP(dst.IP)
+	pbf.Event.Dataset = "smb"
+	pbf.Event.Start = requ.ts
+	pbf.Network.Transport = "tcp"
+	pbf.Network.Protocol = pbf.Event.Dataset
+
+	smb := mapstr.M{
+		"session_id": fmt.Sprintf("0x%016x", requ.sessionID),
+	}
+	if t.dialect != 0 {
+		smb["dialect"] = dialectName(t.dialect)
+	}
+	fields := evt.Fields
+	fields["type"] = pbf.Event.Dataset
+	fields["smb"] = smb
+	if t.user.user != "" {
+		_, _ = fields.Put("user.name", t.user.user)
+		if t.user.domain != "" {
+			_, _ = fields.Put("user.domain", t.user.domain)
+		}
+	}
+
+	if requ.encrypted {
+		pbf.Source.Bytes = int64(t.requBytes)
+		pbf.Destination.Bytes = int64(t.respBytes)
+		pbf.Event.End = t.end
+		smb["encrypted"] = true
+		smb["requests"] = t.requests
+		smb["responses"] = t.responses
+		fields["status"] = common.OK_STATUS
+		return evt
+	}
+
+	pbf.Source.Bytes = int64(requ.size)
+	command := commandName(requ.command)
+	smb["command"] = command
+	smb["message_id"] = requ.messageID
+	smb["encrypted"] = false
+	if requ.treeID != 0 {
+		smb["tree_id"] = requ.treeID
+	}
+	if t.share != "" {
+		smb["share"] = t.share
+	}
+	if t.file != "" {
+		smb["file"] = t.file
+	}
+	signed := requ.flags&flagSigned != 0
+	if t.resp != nil {
+		signed = signed || t.resp.flags&flagSigned != 0
+	}
+	smb["signed"] = signed
+	addRequestFields(smb, requ)
+
+	status := common.OK_STATUS
+	var notes []string
+	if resp := t.resp; resp != nil {
+		pbf.Destination.Bytes = int64(resp.size)
+		pbf.Event.End = resp.ts
+		smb["status"] = statusName(resp.status)
+		smb["status_code"] = resp.status
+		if isFailure(resp.status) {
+			status = common.ERROR_STATUS
+			notes = append(notes, statusName(resp.status))
+		} else {
+			addResponseFields(smb, requ, resp)
+		}
+	} else {
+		status = common.ERROR_STATUS
+		notes = append(notes, "Unmatched request")
+	}
+
+	fields["status"] = status
+	fields["method"] = strings.ToUpper(command)
+	switch {
+	case t.share != "" && t.file != "":
+		fields["resource"] = t.share + `\` + t.file
+	case t.share != "":
+		fields["resource"] = t.share
+	case t.file != "":
+		fields["resource"] = t.file
+	}
+
+	if status == common.ERROR_STATUS {
+		pbf.Event.Outcome = "failure"
+	}
+	pbf.Error.Message = notes
+
+	return evt
+}
+
+func addRequestFields(smb mapstr.M, requ *message) {
+	switch requ.command {
+	case cmdNegotiate:
+		dialects := make([]string, 0, len(requ.dialects))
+		for _, d := range requ.dialects {
+			dialects = append(dialects, dialectName(d))
+		}
+		smb["dialects"] = dialects
+	case cmdSessionSetup:
+		if requ.auth.mechanism != "" {
+			smb["auth_mechanism"] = requ.auth.mechanism
+		}
+		if requ.auth.host != "" {
+			smb["workstation"] = requ.auth.host
+		}
+	case cmdCreate:
+		smb["create_disposition"] = indexName(createDispositionNames, requ.disposition)
+	case cmdRead, cmdWrite:
+		smb["offset"] = requ.offset
+		smb["length"] = requ.length
+	case cmdIoctl:
+		smb["ioctl_code"] = fmt.Sprintf("0x%08x", requ.ctlCode)
+		if name, ok := ioctlNames[requ.ctlCode]; ok {
+			smb["ioctl_name"] = name
+		}
+	case cmdSetInfo:
+		switch requ.infoClass {
+		case fileRenameInformation:
+			smb["new_name"] = requ.newName
+		case fileDispositionInformation, fileDispositionInformationEx:
+			smb["delete_pending"] = requ.deletePending
+		}
+	}
+}
+
+func addResponseFields(smb mapstr.M, requ, resp *message) {
+	switch requ.command {
+	case cmdNegotiate:
+		smb["signing_required"] = resp.securityMode&securitySigningRequired != 0
+		if capabilities := flagNames(capabilityNames, resp.capabilities); len(capabilities) > 0 {
+			smb["capabilities"] = capabilities
+		}
+		if resp.hasCipher {
+			smb["encryption_cipher"] = nameOr(cipherNames, resp.cipher)
+		}
+		if resp.hasSigning {
+			smb["signing_algorithm"] = nameOr(signingAlgorithmNames, resp.signingAlgorithm)
+		}
+	case cmdSessionSetup:
+		if flags := flagNames(sessionFlagNames, uint32(resp.sessionFlags)); len(flags) > 0 {
+			smb["session_flags"] = flags
+		}
+	case cmdTreeConnect:
+		smb["tree_id"] = resp.treeID
+		if name, ok := shareTypeNames[resp.shareType]; ok {
+			smb["share_type"] = name
+		}
+		smb["share_encrypted"] = resp.shareFlags&shareFlagEncryptData != 0
+	case cmdCreate:
+		smb["create_action"] = indexName(createActionNames, resp.createAction)
+		smb["file_size"] = resp.fileSize
+		smb["directory"] = resp.fileAttributes&fileAttributeDirectory != 0
+	case cmdRead, cmdWrite:
+		smb["transferred"] = resp.transferred
+	}
+}
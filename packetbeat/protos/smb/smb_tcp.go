@@ -0,0 +1,213 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package smb
+
+import (
+	"github.com/elastic/beats/v7/libbeat/common"
+	"github.com/elastic/elastic-agent-libs/logp"
+
+	"github.com/elastic/beats/v7/packetbeat/protos"
+	"github.com/elastic/beats/v7/packetbeat/protos/applayer"
+	"github.com/elastic/beats/v7/packetbeat/protos/tcp"
+)
+
+const (
+	// netbiosHeaderSize is the size of the NetBIOS session service header
+	// framing the SMB messages.
+	netbiosHeaderSize = 4
+	// netbiosSessionMessage is the type of the NetBIOS packets carrying SMB
+	// messages. Other types are only used on port 139 to set up the session.
+	netbiosSessionMessage = 0x00
+	// maxHeaderSize is the size of the data parsed of a message too large
+	// to be buffered, such as the data of reads and writes.
+	maxHeaderSize = 64 * 1024
+)
+
+type stream struct {
+	applayer.Stream
+	// skip is the number of bytes left of a message too large to be
+	// buffered.
+	skip int
+}
+
+func (sp *smbPlugin) Parse(
+	pkt *protos.Packet,
+	tcptuple *common.TCPTuple,
+	dir uint8,
+	private protos.ProtocolData,
+) protos.ProtocolData {
+	conn := ensureConnection(private)
+	conn = sp.doParse(conn, pkt, tcptuple, dir)
+	if conn == nil {
+		return nil
+	}
+	return conn
+}
+
+func ensureConnection(private protos.ProtocolData) *connectionData {
+	if private == nil {
+		return newConnectionData()
+	}
+
+	priv, ok := private.(*connectionData)
+	if !ok {
+		logp.Warn("smb connection data type error, create new one")
+		return newConnectionData()
+	}
+	if priv == nil {
+		logp.Warn("Unexpected: smb connection data not set, create new one")
+		return newConnectionData()
+	}
+
+	return priv
+}
+
+func (sp *smbPlugin) doParse(
+	conn *connectionData,
+	pkt *protos.Packet,
+	tcptuple *common.TCPTuple,
+	dir uint8,
+) *connectionData {
+	st := conn.streams[dir]
+	if st == nil {
+		st = &stream{}
+		st.Stream.Init(tcp.TCPMaxDataInStream)
+		conn.streams[dir] = st
+		if isDebug {
+			debugf("new stream: %p (dir=%v, len=%v)", st, dir, len(pkt.Payload))
+		}
+	}
+
+	payload := pkt.Payload
+	if st.skip > 0 {
+		n := min(st.skip, len(payload))
+		st.skip -= n
+		payload = payload[n:]
+	}
+	if err := st.Append(payload); err != nil {
+		if isDebug {
+			debugf("%v, dropping TCP stream: ", err)
+		}
+		return nil
+	}
+
+	tuple, cmdlineTuple := tcptuple.IPPort(), sp.watcher.FindProcessesTupleTCP(tcptuple.IPPort())
+	if dir == tcp.TCPDirectionReverse {
+		reversed := common.NewIPPortTuple(tuple.IPLength, tuple.DstIP, tuple.DstPort, tuple.SrcIP, tuple.SrcPort)
+		tuple = &reversed
+		if cmdlineTuple != nil {
+			reversedCmdline := cmdlineTuple.Reverse()
+			cmdlineTuple = &reversedCmdline
+		}
+	}
+	fromClient := sp.isServerPort(tuple.DstPort)
+
+	for st.Buf.Len() >= netbiosHeaderSize {
+		buf := st.Buf.Bytes()
+		typ := buf[0]
+		size := netbiosHeaderSize + (int(buf[1])<<16 | int(buf[2])<<8 | int(buf[3]))
+
+		var data []byte
+		switch {
+		case st.Buf.Avail(size):
+			var err error
+			data, err = st.Buf.Collect(size)
+			if err != nil {
+				return nil
+			}
+		case size > maxHeaderSize:
+			// Parse the beginning of the message and skip the rest.
+			if len(buf) < maxHeaderSize {
+				// wait for more data
+				return conn
+			}
+			data = buf
+			st.skip = size - len(buf)
+			_ = st.Buf.Advance(len(buf))
+		default:
+			// wait for more data
+			return conn
+		}
+		if typ != netbiosSessionMessage {
+			st.Reset()
+			continue
+		}
+
+		msgs, err := parseMessages(data[netbiosHeaderSize:], size-netbiosHeaderSize)
+		if err != nil {
+			if isDebug {
+				debugf("%v, dropping SMB connection", err)
+			}
+			return nil
+		}
+		for _, m := range msgs {
+			m.ts = pkt.Ts
+			m.tuple = *tuple
+			m.cmdlineTuple = cmdlineTuple
+			if m.encrypted {
+				if !fromClient {
+					m.tuple = common.NewIPPortTuple(tuple.IPLength, tuple.DstIP, tuple.DstPort, tuple.SrcIP, tuple.SrcPort)
+					if cmdlineTuple != nil {
+						reversedCmdline := cmdlineTuple.Reverse()
+						m.cmdlineTuple = &reversedCmdline
+					}
+				}
+				sp.handleEncrypted(conn, m, fromClient)
+				continue
+			}
+			sp.handleMessage(conn, m)
+		}
+		st.Reset()
+	}
+
+	return conn
+}
+
+func (sp *smbPlugin) GapInStream(tcptuple *common.TCPTuple, dir uint8,
+	nbytes int, private protos.ProtocolData) (priv protos.ProtocolData, drop bool,
+) {
+	conn, ok := private.(*connectionData)
+	if !ok || conn == nil {
+		return private, true
+	}
+	// Gaps in the data of a message that is skipped don't break the framing.
+	if st := conn.streams[dir]; st != nil && st.skip >= nbytes {
+		st.skip -= nbytes
+		return private, false
+	}
+	return private, true
+}
+
+func (sp *smbPlugin) ReceivedFin(tcptuple *common.TCPTuple, dir uint8,
+	private protos.ProtocolData,
+) protos.ProtocolData {
+	return private
+}
+
+// Expired publishes the requests still waiting for a response when the
+// connection expires.
+func (sp *smbPlugin) Expired(tuple *common.TCPTuple, private protos.ProtocolData) {
+	conn, ok := private.(*connectionData)
+	if !ok || conn == nil {
+		return
+	}
+	if isDebug {
+		debugf("expired connection %s", tuple)
+	}
+	sp.expireRequests(conn)
+}
@@ -0,0 +1,694 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+//go:build !integration
+
+package smb
+
+import (
+	"encoding/binary"
+	"net"
+	"testing"
+	"time"
+	"unicode/utf16"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/elastic/beats/v7/libbeat/beat"
+	"github.com/elastic/beats/v7/libbeat/common"
+	"github.com/elastic/beats/v7/packetbeat/procs"
+	"github.com/elastic/beats/v7/packetbeat/protos"
+	"github.com/elastic/beats/v7/packetbeat/protos/tcp"
+	"github.com/elastic/beats/v7/packetbeat/publish"
+	conf "github.com/elastic/elastic-agent-libs/config"
+	"github.com/elastic/elastic-agent-libs/mapstr"
+)
+
+type eventStore struct {
+	events []beat.Event
+}
+
+func (e *eventStore) publish(event beat.Event) {
+	publish.MarshalPacketbeatFields(&event, nil, nil)
+	e.events = append(e.events, event)
+}
+
+func smbModForTests(t *testing.T, store *eventStore) *smbPlugin {
+	t.Helper()
+	p, err := New(false, store.publish, &procs.ProcessesWatcher{}, conf.MustNewConfigFrom(map[string]interface{}{
+		"ports": []int{445},
+	}))
+	require.NoError(t, err)
+	return p.(*smbPlugin)
+}
+
+func testTCPTuple() *common.TCPTuple {
+	t := &common.TCPTuple{
+		IPLength: 4,
+		BaseTuple: common.BaseTuple{
+			SrcIP: net.IPv4(192, 168, 0, 1), DstIP: net.IPv4(192, 168, 0, 2),
+			SrcPort: 6512, DstPort: 445,
+		},
+	}
+	t.ComputeHashables()
+	return t
+}
+
+type testPacket struct {
+	dir  uint8
+	data []byte
+}
+
+func client(data []byte) testPacket {
+	return testPacket{dir: tcp.TCPDirectionOriginal, data: data}
+}
+
+func server(data []byte) testPacket {
+	return testPacket{dir: tcp.TCPDirectionReverse, data: data}
+}
+
+func parsePackets(smb *smbPlugin, packets ...testPacket) protos.ProtocolData {
+	tuple := testTCPTuple()
+	var private protos.ProtocolData
+	ts := time.Now()
+	for _, p := range packets {
+		ts = ts.Add(time.Millisecond)
+		private = smb.Parse(&protos.Packet{Ts: ts, Payload: p.data}, tuple, p.dir, private)
+	}
+	return private
+}
+
+func smbFields(t *testing.T, event beat.Event) mapstr.M {
+	t.Helper()
+	v, err := event.GetValue("smb")
+	require.NoError(t, err)
+	return v.(mapstr.M)
+}
+
+// Helpers to encode SMB2 messages.
+
+// smbMessage is an SMB2 message to encode.
+type smbMessage struct {
+	command   uint16
+	status    uint32
+	flags     uint32
+	messageID uint64
+	treeID    uint32
+	sessionID uint64
+	body      []byte
+}
+
+func (m smbMessage) encode() []byte {
+	b := []byte{0xfe, 'S', 'M', 'B'}
+	b = binary.LittleEndian.AppendUint16(b, 64)
+	b = binary.LittleEndian.AppendUint16(b, 1)
+	b = binary.LittleEndian.AppendUint32(b, m.status)
+	b = binary.LittleEndian.AppendUint16(b, m.command)
+	b = binary.LittleEndian.AppendUint16(b, 1)
+	b = binary.LittleEndian.AppendUint32(b, m.flags)
+	b = binary.LittleEndian.AppendUint32(b, 0)
+	b = binary.LittleEndian.AppendUint64(b, m.messageID)
+	b = binary.LittleEndian.AppendUint32(b, 0xfeff)
+	b = binary.LittleEndian.AppendUint32(b, m.treeID)
+	b = binary.LittleEndian.AppendUint64(b, m.sessionID)
+	b = append(b, make([]byte, 16)...)
+	return append(b, m.body...)
+}
+
+// frame returns the NetBIOS framing of compounded messages.
+func frame(msgs ...smbMessage) []byte {
+	var payload []byte
+	for i, m := range msgs {
+		b := m.encode()
+		if i < len(msgs)-1 {
+			for len(b)%8 != 0 {
+				b = append(b, 0)
+			}
+			binary.LittleEndian.PutUint32(b[20:], uint32(len(b)))
+		}
+		payload = append(payload, b...)
+	}
+	b := []byte{0, byte(len(payload) >> 16), byte(len(payload) >> 8), byte(len(payload))}
+	return append(b, payload...)
+}
+
+// transform returns an encrypted message of a session.
+func transform(sessionID uint64, size int) []byte {
+	b := []byte{0xfd, 'S', 'M', 'B'}
+	b = append(b, make([]byte, 32)...)
+	b = binary.LittleEndian.AppendUint32(b, uint32(size))
+	b = binary.LittleEndian.AppendUint16(b, 0)
+	b = binary.LittleEndian.AppendUint16(b, 1)
+	b = binary.LittleEndian.AppendUint64(b, sessionID)
+	b = append(b, make([]byte, size)...)
+	return append([]byte{0, byte(len(b) >> 16), byte(len(b) >> 8), byte(len(b))}, b...)
+}
+
+func utf16le(s string) []byte {
+	var b []byte
+	for _, c := range utf16.Encode([]rune(s)) {
+		b = binary.LittleEndian.AppendUint16(b, c)
+	}
+	return b
+}
+
+// fixed returns a structure starting with its 16-bit size or type,
+// followed by the fields. Ints are zeroed fields of that size.
+func fixed(size uint16, fields ...interface{}) []byte {
+	b := binary.LittleEndian.AppendUint16(nil, size)
+	for _, f := range fields {
+		switch f := f.(type) {
+		case uint8:
+			b = append(b, f)
+		case uint16:
+			b = binary.LittleEndian.AppendUint16(b, f)
+		case uint32:
+			b = binary.LittleEndian.AppendUint32(b, f)
+		case uint64:
+			b = binary.LittleEndian.AppendUint64(b, f)
+		case []byte:
+			b = append(b, f...)
+		case int:
+			b = append(b, make([]byte, f)...)
+		}
+	}
+	return b
+}
+
+func negotiateRequest(dialects ...uint16) []byte {
+	b := fixed(36, uint16(len(dialects)), uint16(1), 2, uint32(0x7f), 16, 8)
+	for _, d := range dialects {
+		b = binary.LittleEndian.AppendUint16(b, d)
+	}
+	return b
+}
+
+func negotiateResponse(dialect, securityMode uint16, cipher, signing uint16) []byte {
+	contexts := 0
+	if dialect == 0x0311 {
+		contexts = 2
+	}
+	// The contexts follow the fixed part, at offset 64+64.
+	b := fixed(65, securityMode, dialect, uint16(contexts), 16, uint32(0x2f), uint32(8<<20), uint32(8<<20), uint32(8<<20), 16,
+		uint16(0), uint16(0), uint32(128))
+	if contexts > 0 {
+		b = append(b, negotiateContext(contextEncryptionCapabilities, cipher)...)
+		b = append(b, 0, 0, 0, 0)
+		b = append(b, negotiateContext(contextSigningCapabilities, signing)...)
+	}
+	return b
+}
+
+// negotiateContext returns a negotiate context selecting an algorithm.
+func negotiateContext(typ, algorithm uint16) []byte {
+	return fixed(typ, uint16(4), uint32(0), uint16(1), algorithm)
+}
+
+func sessionSetupRequest(blob []byte) []byte {
+	return fixed(25, uint8(0), uint8(1), uint32(1), uint32(0), uint16(88), uint16(len(blob)), uint64(0), blob)
+}
+
+func sessionSetupResponse(flags uint16) []byte {
+	return fixed(9, flags, uint16(0), uint16(0))
+}
+
+func ntlmNegotiateMessage() []byte {
+	b := append([]byte("NTLMSSP\x00"), 1, 0, 0, 0)
+	return append(b, make([]byte, 24)...)
+}
+
+func ntlmAuthenticateMessage(domain, user, host string) []byte {
+	payload := [][]byte{utf16le(domain), utf16le(user), utf16le(host)}
+	b := append([]byte("NTLMSSP\x00"), 3, 0, 0, 0)
+	offset := 64
+	field := func(n int) {
+		b = binary.LittleEndian.AppendUint16(b, uint16(n))
+		b = binary.LittleEndian.AppendUint16(b, uint16(n))
+		b = binary.LittleEndian.AppendUint32(b, uint32(offset))
+		offset += n
+	}
+	field(0)
+	field(0)
+	for _, p := range payload {
+		field(len(p))
+	}
+	field(0)
+	b = binary.LittleEndian.AppendUint32(b, 0xe2088215)
+	for _, p := range payload {
+		b = append(b, p...)
+	}
+	// SPNEGO NegTokenResp wrapper, not decoded.
+	return append([]byte{0xa1, 0x82, 0x01, 0x00, 0x30, 0x81, 0xfd}, b...)
+}
+
+func treeConnectRequest(path string) []byte {
+	p := utf16le(path)
+	return fixed(9, uint16(0), uint16(72), uint16(len(p)), p)
+}
+
+func treeConnectResponse(shareType uint8, shareFlags uint32) []byte {
+	return fixed(16, shareType, uint8(0), shareFlags, uint32(0), uint32(0x1f01ff))
+}
+
+func createRequest(name string, disposition uint32) []byte {
+	n := utf16le(name)
+	return fixed(57, uint8(0), uint8(0), uint32(2), uint64(0), uint64(0), uint32(0x12019f), uint32(0x80),
+		uint32(7), disposition, uint32(0x40), uint16(120), uint16(len(n)), uint32(0), uint32(0), n)
+}
+
+func createResponse(action uint32, size uint64, attributes uint32, id fileID) []byte {
+	return fixed(89, uint8(0), uint8(0), action, 32, uint64(size+4095)&^4095, size, attributes, uint32(0), id[:], uint32(0), uint32(0))
+}
+
+func closeRequest(id fileID) []byte {
+	return fixed(24, uint16(0), uint32(0), id[:])
+}
+
+func closeResponse() []byte {
+	return fixed(60, uint16(0), uint32(0), 52)
+}
+
+func readRequest(id fileID, length uint32, offset uint64) []byte {
+	return fixed(49, uint8(0), uint8(0), length, offset, id[:], uint32(1), uint32(0), uint32(0), uint16(0), uint16(0), uint8(0))
+}
+
+func readResponse(data []byte) []byte {
+	return fixed(17, uint8(80), uint8(0), uint32(len(data)), uint32(0), uint32(0), data)
+}
+
+func writeRequest(id fileID, data []byte, offset uint64) []byte {
+	return fixed(49, uint16(112), uint32(len(data)), offset, id[:], uint32(0), uint32(0), uint16(0), uint16(0), uint32(0), data)
+}
+
+func writeResponse(count uint32) []byte {
+	return fixed(17, uint16(0), count, uint32(0), uint16(0), uint16(0))
+}
+
+func ioctlRequest(code uint32, id fileID, input []byte) []byte {
+	return fixed(57, uint16(0), code, id[:], uint32(120), uint32(len(input)), uint32(0), uint32(0), uint32(0), uint32(1024),
+		uint32(1), uint32(0), input)
+}
+
+func ioctlResponse(code uint32, id fileID) []byte {
+	return fixed(49, uint16(0), code, id[:], uint32(0), uint32(0), uint32(0), uint32(0), uint32(0), uint32(0))
+}
+
+func queryInfoRequest(id fileID) []byte {
+	return fixed(41, uint8(1), uint8(5), uint32(1024), uint16(0), uint16(0), uint32(0), uint32(0), uint32(0), id[:])
+}
+
+func queryInfoResponse(info []byte) []byte {
+	return fixed(9, uint16(72), uint32(len(info)), info)
+}
+
+func setInfoRequest(id fileID, class uint8, info []byte) []byte {
+	return fixed(33, uint8(1), class, uint32(len(info)), uint16(96), uint16(0), uint32(0), id[:], info)
+}
+
+func setInfoResponse() []byte {
+	return fixed(2)
+}
+
+func changeNotifyRequest(id fileID) []byte {
+	return fixed(32, uint16(0), uint32(1024), id[:], uint32(0x17), uint32(0))
+}
+
+func changeNotifyResponse() []byte {
+	return fixed(9, uint16(72), uint32(0), 1)
+}
+
+func errorResponse() []byte {
+	return fixed(9, uint8(0), uint8(0), uint32(0), 1)
+}
+
+func renameInfo(name string) []byte {
+	n := utf16le(name)
+	b := append([]byte{0}, make([]byte, 7)...)
+	b = binary.LittleEndian.AppendUint64(b, 0)
+	b = binary.LittleEndian.AppendUint32(b, uint32(len(n)))
+	return append(b, n...)
+}
+
+const (
+	testSessionID = 0x0000040000000005
+	testTreeID    = 1
+)
+
+var testFileID = fileID{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16}
+
+func request(command uint16, messageID uint64, body []byte) smbMessage {
+	return smbMessage{
+		command:   command,
+		messageID: messageID,
+		treeID:    testTreeID,
+		sessionID: testSessionID,
+		body:      body,
+	}
+}
+
+func response(command uint16, messageID uint64, status uint32, body []byte) smbMessage {
+	m := request(command, messageID, body)
+	m.status = status
+	m.flags = flagServerToRedir
+	return m
+}
+
+func TestNegotiateAndSessionSetup(t *testing.T) {
+	store := &eventStore{}
+	smb := smbModForTests(t, store)
+
+	negotiate := smbMessage{command: cmdNegotiate, body: negotiateRequest(0x0202, 0x0210, 0x0300, 0x0302, 0x0311)}
+	negotiated := negotiate
+	negotiated.flags = flagServerToRedir
+	negotiated.body = negotiateResponse(0x0311, 0x3, 2, 2)
+	auth := ntlmAuthenticateMessage("EXAMPLE", "alice", "WS01")
+	parsePackets(smb,
+		client(frame(negotiate)),
+		server(frame(negotiated)),
+		client(frame(smbMessage{command: cmdSessionSetup, messageID: 1, body: sessionSetupRequest(ntlmNegotiateMessage())})),
+		server(frame(smbMessage{
+			command: cmdSessionSetup, messageID: 1, sessionID: testSessionID, flags: flagServerToRedir,
+			status: statusMoreProcessingRequired, body: sessionSetupResponse(0),
+		})),
+		client(frame(smbMessage{command: cmdSessionSetup, messageID: 2, sessionID: testSessionID, body: sessionSetupRequest(auth)})),
+		server(frame(smbMessage{
+			command: cmdSessionSetup, messageID: 2, sessionID: testSessionID, flags: flagServerToRedir | flagSigned,
+			body: sessionSetupResponse(0),
+		})),
+	)
+
+	require.Len(t, store.events, 3)
+	event := store.events[0]
+	assert.Equal(t, mapstr.M{
+		"session_id":        "0x0000000000000000",
+		"dialect":           "3.1.1",
+		"command":           "negotiate",
+		"message_id":        uint64(0),
+		"encrypted":         false,
+		"signed":            false,
+		"dialects":          []string{"2.0.2", "2.1", "3.0", "3.0.2", "3.1.1"},
+		"status":            "STATUS_SUCCESS",
+		"status_code":       uint32(0),
+		"signing_required":  true,
+		"capabilities":      []string{"dfs", "leasing", "large_mtu", "multi_channel", "directory_leasing"},
+		"encryption_cipher": "AES-128-GCM",
+		"signing_algorithm": "AES-GMAC",
+	}, smbFields(t, event))
+	assert.Equal(t, "OK", event.Fields["status"])
+	assert.Equal(t, "NEGOTIATE", event.Fields["method"])
+	transport, _ := event.GetValue("network.transport")
+	assert.Equal(t, "tcp", transport)
+	port, _ := event.GetValue("destination.port")
+	assert.EqualValues(t, 445, port)
+
+	event = store.events[1]
+	fields := smbFields(t, event)
+	assert.Equal(t, "ntlm", fields["auth_mechanism"])
+	assert.Equal(t, "STATUS_MORE_PROCESSING_REQUIRED", fields["status"])
+	assert.Equal(t, "OK", event.Fields["status"])
+	user, _ := event.GetValue("user.name")
+	assert.Nil(t, user)
+
+	event = store.events[2]
+	fields = smbFields(t, event)
+	assert.Equal(t, "0x0000040000000005", fields["session_id"])
+	assert.Equal(t, "3.1.1", fields["dialect"])
+	assert.Equal(t, "WS01", fields["workstation"])
+	assert.Equal(t, true, fields["signed"])
+	assert.NotContains(t, fields, "session_flags")
+	user, _ = event.GetValue("user.name")
+	assert.Equal(t, "alice", user)
+	domain, _ := event.GetValue("user.domain")
+	assert.Equal(t, "EXAMPLE", domain)
+	bytes, _ := event.GetValue("source.bytes")
+	assert.EqualValues(t, len(frame(smbMessage{body: sessionSetupRequest(auth)}))-netbiosHeaderSize, bytes)
+}
+
+func TestFileOperations(t *testing.T) {
+	store := &eventStore{}
+	smb := smbModForTests(t, store)
+
+	data := []byte("hello world")
+	private := parsePackets(smb,
+		client(frame(request(cmdTreeConnect, 3, treeConnectRequest(`\\server\share`)))),
+		server(frame(response(cmdTreeConnect, 3, statusSuccess, treeConnectResponse(1, 0)))),
+		client(frame(request(cmdCreate, 4, createRequest(`docs\report.txt`, 3)))),
+		server(frame(response(cmdCreate, 4, statusSuccess, createResponse(2, 0, 0x20, testFileID)))),
+		client(frame(request(cmdWrite, 5, writeRequest(testFileID, data, 0)))),
+		server(frame(response(cmdWrite, 5, statusSuccess, writeResponse(uint32(len(data)))))),
+		client(frame(request(cmdRead, 6, readRequest(testFileID, 1024, 0)))),
+		server(frame(response(cmdRead, 6, statusSuccess, readResponse(data)))),
+		client(frame(request(cmdClose, 7, closeRequest(testFileID)))),
+		server(frame(response(cmdClose, 7, statusSuccess, closeResponse()))),
+	)
+
+	require.Len(t, store.events, 5)
+	event := store.events[0]
+	assert.Equal(t, mapstr.M{
+		"session_id":      "0x0000040000000005",
+		"command":         "tree_connect",
+		"message_id":      uint64(3),
+		"encrypted":       false,
+		"signed":          false,
+		"tree_id":         uint32(testTreeID),
+		"share":           `\\server\share`,
+		"share_type":      "disk",
+		"share_encrypted": false,
+		"status":          "STATUS_SUCCESS",
+		"status_code":     uint32(0),
+	}, smbFields(t, event))
+	assert.Equal(t, "TREE_CONNECT", event.Fields["method"])
+	assert.Equal(t, `\\server\share`, event.Fields["resource"])
+
+	event = store.events[1]
+	fields := smbFields(t, event)
+	assert.Equal(t, `docs\report.txt`, fields["file"])
+	assert.Equal(t, "open_if", fields["create_disposition"])
+	assert.Equal(t, "created", fields["create_action"])
+	assert.Equal(t, uint64(0), fields["file_size"])
+	assert.Equal(t, false, fields["directory"])
+	assert.Equal(t, `\\server\share\docs\report.txt`, event.Fields["resource"])
+
+	for i, command := range []string{"write", "read"} {
+		event = store.events[2+i]
+		fields = smbFields(t, event)
+		assert.Equal(t, command, fields["command"])
+		assert.Equal(t, `docs\report.txt`, fields["file"])
+		assert.Equal(t, uint64(0), fields["offset"])
+		assert.Equal(t, uint32(len(data)), fields["transferred"])
+	}
+	assert.Equal(t, uint32(1024), smbFields(t, store.events[3])["length"])
+
+	event = store.events[4]
+	assert.Equal(t, `docs\report.txt`, smbFields(t, event)["file"])
+	assert.Equal(t, "CLOSE", event.Fields["method"])
+
+	conn := private.(*connectionData)
+	assert.Equal(t, map[uint32]string{testTreeID: `\\server\share`}, conn.trees)
+	assert.Empty(t, conn.files)
+}
+
+func TestCompoundedRequests(t *testing.T) {
+	store := &eventStore{}
+	smb := smbModForTests(t, store)
+
+	related := func(m smbMessage) smbMessage {
+		m.flags |= flagRelatedOperations
+		m.sessionID, m.treeID = ^uint64(0), ^uint32(0)
+		return m
+	}
+	info := make([]byte, 24)
+	parsePackets(smb,
+		client(frame(
+			request(cmdCreate, 10, createRequest("notes.txt", 1)),
+			related(request(cmdQueryInfo, 11, queryInfoRequest(relatedFileID))),
+			related(request(cmdClose, 12, closeRequest(relatedFileID))),
+		)),
+		server(frame(
+			response(cmdCreate, 10, statusSuccess, createResponse(1, 42, 0x20, testFileID)),
+			response(cmdQueryInfo, 11, statusSuccess, queryInfoResponse(info)),
+			response(cmdClose, 12, statusSuccess, closeResponse()),
+		)),
+	)
+
+	require.Len(t, store.events, 3)
+	for i, command := range []string{"create", "query_info", "close"} {
+		fields := smbFields(t, store.events[i])
+		assert.Equal(t, command, fields["command"])
+		assert.Equal(t, "notes.txt", fields["file"])
+		assert.Equal(t, "0x0000040000000005", fields["session_id"])
+		assert.Equal(t, uint32(testTreeID), fields["tree_id"])
+		assert.Equal(t, "STATUS_SUCCESS", fields["status"])
+	}
+	assert.Equal(t, uint64(42), smbFields(t, store.events[0])["file_size"])
+}
+
+func TestErrorsAndAsyncResponses(t *testing.T) {
+	store := &eventStore{}
+	smb := smbModForTests(t, store)
+
+	pending := response(cmdChangeNotify, 21, statusPending, errorResponse())
+	pending.flags |= flagAsyncCommand
+	final := response(cmdChangeNotify, 21, statusSuccess, changeNotifyResponse())
+	final.flags |= flagAsyncCommand
+	parsePackets(smb,
+		client(frame(request(cmdTreeConnect, 20, treeConnectRequest(`\\server\missing`)))),
+		server(frame(response(cmdTreeConnect, 20, 0xc00000cc, errorResponse()))),
+		client(frame(request(cmdChangeNotify, 21, changeNotifyRequest(testFileID)))),
+		server(frame(pending)),
+		client(frame(request(cmdSetInfo, 22, setInfoRequest(testFileID, fileRenameInformation, renameInfo(`docs\new.txt`))))),
+		server(frame(response(cmdSetInfo, 22, 0xc0000022, errorResponse()))),
+		client(frame(request(cmdSetInfo, 23, setInfoRequest(testFileID, fileDispositionInformation, []byte{1})))),
+		server(frame(response(cmdSetInfo, 23, statusSuccess, setInfoResponse()))),
+		client(frame(request(cmdIoctl, 24, ioctlRequest(0x0011c017, testFileID, []byte{5, 0, 0, 3})))),
+		server(frame(response(cmdIoctl, 24, statusSuccess, ioctlResponse(0x0011c017, testFileID)))),
+		server(frame(final)),
+	)
+
+	require.Len(t, store.events, 5)
+	event := store.events[0]
+	fields := smbFields(t, event)
+	assert.Equal(t, "STATUS_BAD_NETWORK_NAME", fields["status"])
+	assert.Equal(t, uint32(0xc00000cc), fields["status_code"])
+	assert.NotContains(t, fields, "share_type")
+	assert.Equal(t, "Error", event.Fields["status"])
+	msg, _ := event.GetValue("error.message")
+	assert.Equal(t, "STATUS_BAD_NETWORK_NAME", msg)
+	outcome, _ := event.GetValue("event.outcome")
+	assert.Equal(t, "failure", outcome)
+
+	fields = smbFields(t, store.events[1])
+	assert.Equal(t, `docs\new.txt`, fields["new_name"])
+	assert.Equal(t, "STATUS_ACCESS_DENIED", fields["status"])
+
+	fields = smbFields(t, store.events[2])
+	assert.Equal(t, true, fields["delete_pending"])
+
+	fields = smbFields(t, store.events[3])
+	assert.Equal(t, "0x0011c017", fields["ioctl_code"])
+	assert.Equal(t, "FSCTL_PIPE_TRANSCEIVE", fields["ioctl_name"])
+
+	event = store.events[4]
+	fields = smbFields(t, event)
+	assert.Equal(t, "change_notify", fields["command"])
+	assert.Equal(t, "STATUS_SUCCESS", fields["status"])
+	assert.Equal(t, "OK", event.Fields["status"])
+}
+
+func TestEncryptedSession(t *testing.T) {
+	store := &eventStore{}
+	smb := smbModForTests(t, store)
+
+	private := parsePackets(smb,
+		client(transform(testSessionID, 100)),
+		server(transform(testSessionID, 200)),
+		client(transform(testSessionID, 300)),
+		server(transform(testSessionID, 400)),
+	)
+	assert.Empty(t, store.events)
+
+	smb.Expired(testTCPTuple(), private)
+	require.Len(t, store.events, 1)
+	event := store.events[0]
+	assert.Equal(t, mapstr.M{
+		"session_id": "0x0000040000000005",
+		"encrypted":  true,
+		"requests":   2,
+		"responses":  2,
+	}, smbFields(t, event))
+	assert.Equal(t, "OK", event.Fields["status"])
+	bytes, _ := event.GetValue("source.bytes")
+	assert.EqualValues(t, 2*transformHeaderSize+400, bytes)
+	bytes, _ = event.GetValue("destination.bytes")
+	assert.EqualValues(t, 2*transformHeaderSize+600, bytes)
+	port, _ := event.GetValue("destination.port")
+	assert.EqualValues(t, 445, port)
+}
+
+func TestLargeMessages(t *testing.T) {
+	store := &eventStore{}
+	smb := smbModForTests(t, store)
+
+	data := make([]byte, 200000)
+	msg := frame(request(cmdWrite, 30, writeRequest(testFileID, data, 4096)))
+	var packets []testPacket
+	for i := 0; i < len(msg); i += 1448 {
+		packets = append(packets, client(msg[i:min(i+1448, len(msg))]))
+	}
+	packets = append(packets,
+		server(frame(response(cmdWrite, 30, statusSuccess, writeResponse(uint32(len(data)))))),
+		client(frame(request(cmdFlush, 31, closeRequest(testFileID)))),
+		server(frame(response(cmdFlush, 31, statusSuccess, closeResponse()))),
+	)
+	parsePackets(smb, packets...)
+
+	require.Len(t, store.events, 2)
+	event := store.events[0]
+	fields := smbFields(t, event)
+	assert.Equal(t, uint64(4096), fields["offset"])
+	assert.Equal(t, uint32(len(data)), fields["length"])
+	assert.Equal(t, uint32(len(data)), fields["transferred"])
+	bytes, _ := event.GetValue("source.bytes")
+	assert.EqualValues(t, len(msg)-netbiosHeaderSize, bytes)
+	assert.Equal(t, "flush", smbFields(t, store.events[1])["command"])
+}
+
+func TestRequestsWithoutResponse(t *testing.T) {
+	store := &eventStore{}
+	smb := smbModForTests(t, store)
+
+	private := parsePackets(smb,
+		client(frame(request(cmdRead, 40, readRequest(testFileID, 10, 0)))),
+		client(frame(request(cmdEcho, 41, fixed(4, uint16(0))))),
+	)
+	assert.Empty(t, store.events)
+
+	smb.Expired(testTCPTuple(), private)
+	require.Len(t, store.events, 1)
+	event := store.events[0]
+	assert.Equal(t, "read", smbFields(t, event)["command"])
+	assert.Equal(t, "Error", event.Fields["status"])
+	msg, _ := event.GetValue("error.message")
+	assert.Equal(t, "Unmatched request", msg)
+}
+
+func TestParseSecurityBlob(t *testing.T) {
+	// Prefix of a SPNEGO NegTokenInit with a Kerberos AP-REQ.
+	kerberos := append([]byte{0x60, 0x82, 0x05, 0x00, 0x06, 0x06, 0x2b, 0x06, 0x01, 0x05, 0x05, 0x02,
+		0xa0, 0x82, 0x04, 0xf4, 0x30, 0x82, 0x04, 0xf0, 0xa2, 0x82, 0x04, 0xec, 0x04, 0x82, 0x04, 0xe8,
+		0x60, 0x82, 0x04, 0xe4, 0x06, 0x09}, krb5APReq...)
+	assert.Equal(t, authInfo{mechanism: "kerberos"}, parseSecurityBlob(kerberos))
+	assert.Equal(t, authInfo{mechanism: "ntlm"}, parseSecurityBlob(ntlmNegotiateMessage()))
+	assert.Equal(t, authInfo{mechanism: "ntlm", user: "bob", domain: "CORP", host: "HOST"},
+		parseSecurityBlob(ntlmAuthenticateMessage("CORP", "bob", "HOST")))
+	assert.Equal(t, authInfo{}, parseSecurityBlob(nil))
+}
+
+func TestInvalidData(t *testing.T) {
+	store := &eventStore{}
+	smb := smbModForTests(t, store)
+
+	// SMB1 negotiations are ignored.
+	smb1 := append([]byte{0, 0, 0, 8}, protocolSMB1...)
+	private := parsePackets(smb, client(append(smb1, 0x72, 0, 0, 0)))
+	assert.NotNil(t, private)
+
+	private = parsePackets(smb, client([]byte("\x00\x00\x00\x10GET / HTTP/1.1\r\n")))
+	assert.Nil(t, private)
+	assert.Empty(t, store.events)
+}
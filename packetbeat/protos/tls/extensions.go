@@ -49,6 +49,14 @@ const (
 	ExtensionSupportedGroups ExtensionID = 10
 	// ExtensionEllipticCurvePointsFormats identifies the points formats extension
 	ExtensionEllipticCurvePointsFormats = 11
+	// ExtensionServerName identifies the server name indication extension
+	ExtensionServerName ExtensionID = 0
+	// ExtensionSignatureAlgorithms identifies the signature algorithms extension
+	ExtensionSignatureAlgorithms ExtensionID = 13
+	// ExtensionALPN identifies the application layer protocol negotiation extension
+	ExtensionALPN ExtensionID = 16
+	// ExtensionSupportedVersions identifies the supported versions extension
+	ExtensionSupportedVersions ExtensionID = 43
 )
 
 var extensionMap = map[uint16]extension{
@@ -65,7 +73,7 @@ var extensionMap = map[uint16]extension{
 	10:     {"supported_groups", parseSupportedGroups, true},
 	11:     {"ec_points_formats", parseEcPoints, true},
 	12:     {"srp", parseSrp, false},
-	13:     {"signature_algorithms", parseSignatureSchemes, false},
+	13:     {"signature_algorithms", parseSignatureSchemes, true},
 	16:     {"application_layer_protocol_negotiation", parseALPN, false},
 	35:     {"session_ticket", parseTicket, false},
 	43:     {"supported_versions", parseSupportedVersions, true},
@@ -32,5 +32,5 @@ func init() {
 // AssetTls returns asset data.
 // This is the base64 encoded zlib format compressed contents of protos/tls.
 func AssetTls() string {
-	return "eJzsWc1u20YQvuspBu7BCRDLDYIWqA8FAsUHA0ET1El7JFbkiNxmucvsDiXz7YvZJSn+Sg7sKIETniSRnPnm75sZ7QV8wuoKSLkoQRJSYbIAIEkKr+D8Tf0TfHh7e74ASNDFVhYkjb6CPxcAAN1HLlyBsdzIGHCLmmAjUSVuuYD605V/4wK0yNHr9N8BqCrwClJryqL+pfs8X79AigRWJmA2QJl0sMtQww6hLFIrEgQycL26hZfL39uXGkWxkqip/XlK35TOroi73379o3djTghfCW5EqSiqBcJGKIeDZ6aUdRVu0Tpp9Oh+o/cTVjtjk4n7vRj9E8Sw19gE2BibC1pOvIZ3Ii846K8Ws6CkcyXaZWHNVup4aNIXg3tfywFjwWLKOHeSMqkhNqUmWy3nobhy/R/G9E2wPCgZHdot2p/J+DMZHwdLg6HD3n2dw6Q4lhCHMm86EQ7ZNtExuteHDBuhoZiQ+wgU1pCJjYLSYTJMkDY5zvnRl8tX54tJsBZdmXvNUY6UmSG0B8C+CVAdOo88Ew7WiDqoxOSFv1vqBK2qpE4h6A/WwDuNYDYjmWcyOeOS8A5oJN+84Rw4Ixl/QtrfDt8B7wg1P7ec9kBofFGMlrgrC8LI4ucSHeG0M9bGKBTD+B5xxr8ZUoa29gizm3dIq8nfCFCYGEVJGWrycECSQzX2RenYa6J5q2PAjKUmdkVk0RVGj9jtgenJMVXE2Sk0vFvdvm8sO+z0DJUy92bpEzHw2EK4RxGuK9hlMs66gdxJl6EDGpoYrtjkealDiJPScjB9n6zT+gCVWqETk38dO//2siERJHwpsmEjY8lAihotQ+ebqGNbebGs/FATCLZFcgrdI6D/qOXnEkGX+ZqLzYBMuIw2VY+KmCF8mIwN5ZBInc7ESGuMqWkuneAe7HRFYSxhEsUmL2xtcqA39/WyU0nnK7CjtOZU103KGp2bGikAbhEhIyrc1eXlbrdbSqHF0tj0UjgnU52jJnfJGi5Y9IVMBt+Wdxnlat43LRvPO2Kq9EduYIM9e3Qkgh8tkn3ONpEay5qjEhgNoRF/iaROuFCn2QXuEcORAW/raGXGEatwY591wYiiUDWCSIkKbdQUY6QxNSQfFdx0kvHVwO7gufB4WnLoJRuP/VIpz22G+WSibGCCH0LvPoE5qFPKGkJv+CFofwFy06bUCx4xhAbMC6rAkZ1jDA/QgEi23I8dNvUWSMcLdsec0PJH3XCmauWxHVHHlWm+0QqUCXpQNFtDfE2f0IxrpfjhGFal3SKsuDuZ1Ioiq+DZ9Wr1HGJ/4yAu2BswZJTDZstUCyotRkKlxkrK8hOa3mqHvfYQyVxUsEaOG0gNiUwlCTVveyPnWLpiHBVGanJR2FW/XZifXa+eg8dSr81uCTeBudHT0ryx6OX13h2lfyw0FMIeT3sSVLpmizjojbleN/LFrZfZTNWQ13+g7NeJ6XZ+uNN1QTOe2YfuF7wRaG7S/GLLrz0jlvBa7UTl4IwXk7PAtujm6wv6eyvPbWgjmUQ8+UTKU/lRE1xm7FxMJg1QvRbRDFlkS7+2tTjmqqQP2hseHRyCHgi3Hn15DtwvhN0hqRl+pxe0IeSo1D7lk+h0Vd0B60twh5bDsCFowDAdvxfcS9co5nbMen57Qjum94Z0YS8Lcezui1Mib/wLLCmTacbU0SgY9bb6bwltCPAuRpxdiqgDc/8HhtRdrvRef6IrrMJ4ern7elkx3ulaHIPwbazJW6aalNduSV8WsR9idT/pejrfuO+znp52I/yrFolJVzH0V7/va7f7yKXPq5jWptQxBvoUgy3PbzQ8FjfRmZXXj1ozvAx2wu9wteuErrPdsWfCMpD88PN9TZ1Per6f+d9/DHn6eGMWeHOwIXrzZn/Sb5XDTjhv25OfOrvHSXEm5PRxoLBWVIsDsFf8artvtN2vSdijRz4Th1uPj+beB1BCoaWIlQ055AHHT691AM4Nn4TU4RwlTHteYVhBGSxu0Vb1jxZjlFsmv/8DAAD//6l0gvo="
+	return "eJzsWU1z2zgSvfNXdHkPmamKGcvy92GrUp4csjW1k1rP7O6NBREtEhMQ4ACgZP77rQZBipJISt7Edk0mUQ6WSHW/1x8P3dQpfMb6Dpy0CUfHhEQeATjhJN7Bm5/CR/Drzw9vIgCONjWidEKrO/h7BADQv+XUlpiKpUgBV6gcLAVKbuMIwl93/hunoFiB3qd/D+DqEu8gM7oqwyf9++n1N8jQgREc9BJcLiysc1SwRqjKzDCO4DR8uH+AWXzVfal1lEqBynUfD/kb8tk38Tu72Pq8tfEZ67U2fOfaQJj6r/fwj/cXsBQqQ1MaoVxDCimEASzkKKWGAq1lGcY7JvCRFSUlyM3mfHY5u8rPkxvO2PJqdnl+fT1L8PLq/BqX7JwtZtEYocevzOi/Q5QCnRSNo8JgbpwMm1/P2e3yZp5eLZLrs/Pz9PJqznF+k1zc8jm7veCz9IIP03m8PLvdsTucZHpxXLJKuiQkHJZMWty5Z6gY+g5XaKzQau/6dBj3QvnvxgxFiyjAUpuCud0QbYVpHo2CEtZWaOLS6JVQKX4puE/BDmgDBjOhFayFy4WCVFfKmToeh2Krxe+YulfB8kViYdGs0ES7MJ8oFjY6nusxvfUwJhcN3OPl4uz87CyZzc9myfn8AtnVze3s8uaVNSJw+K4R3zXixTSixdAbeqaL4lBBDBXDdCFMcTvQS7/m2Brti0FptNOpllBZ5KM99IZuncXzN9EgWIO2KrznpECXa/71YH9s+9165DmzsEBUjUvkb/3VSnE0shYqg8Z/wwZ+UQh6uWfzRPATOjaDGjaWP/5EZ9aJE+lndJvLzXvAR4eK7ouHI9DMLElPjxKDf1RoHQ4HY6G1RKaeFoz/5OhyNH0FpIB0nvrjk9PAKpejch4OCGdR7seishQ1Njh0DTLVqS0Tg7bUyuLXyzOVJ+VUeolnCn65f/jUMpsOuj/IjlbpoaY73HiH2B3B8JgmXNSwzkWa9xO5FjZHC04PWkx1UVTKJwx4ZSiZftcJZR1Ho0QNU1wXz8PzX942cOaYb0VY1PtknYYMFRqCThdRpab24SPnE8gDt0Tw50H/mxJ/VAiqKhbUbBoEpzZa1ltSRApB71NtmnbgQmWD9lKtFKauPVx6yZ0iWZWlNg55kuqiNIFyI2/2eXhTdUphfQf2nAZNtf2iDOjs0EgB8IAIuXOlvXv3br1ex4IpFmuTvWPWikwVqJx9Rx5OyfSp4Dvv4sfcFXI8Np0ajwdiqPX3wkCEvXr0LFJ9rgTf1GybqdbCYSnZSqOX6YTeJEJxatRhdTkmh3sEfg7ZyrV15MJGk2BYWcqAIJGsRpO0zZgozLQTXxXccJHRq4Xdw3Pq8XTisFVs9OhGSOm1TZOexNM0W31ozu4XoIMqc3kr6K0+NN7fglh2JfWWRgymAIvS1WCdGVMM+k8HOF/RQGGx7Tc/ljSG7aEgdPoRDhz7AoEIeaXBqfUKLmfui7LZEfE9/YI0PkhJyU/hvjIrhHs6nXRmWJnX8MOH+/sfIfUXJnHBhsCuokzTFplirjKYMJlpI1xevCD1zjtsvDeZLFgNC6QuBKGAi0w4JkftdXYOlSumSamFcjZpdtXXS/MPH+5/BI8lrM02ho+NctMYlmM0aI3Iore39d298k+ZgpKZw2XvmKtsu0WM+Jw+6/Zi8eBttlM1FOG51madGD7Op0+6PmjCM3rTccnbA02HNH2x09ctEjG8l2tWWzihxeSkUVu04/3VhxvmNjSJ4AlNPon0Uj4BjZDcgc21cU8hILeOiHbIcqaiBXGDwx4F2hNPJoegL4QbRl+97C+E/SGpHX7bLETRFOSkUr7keRL9/4XxxK7ugfUtuEaDIHHpoAVDcvyJ0Vm6QLZLoYXe9MW3tGP6aAhLCs5DHvv7Ymum/++jP7Up37nIcrSuc7B3toXHEko7wMcUcXQpcj2YoYqQ05HS00of9TgajeOfeYWVmA4vd89Dh6pif6frcOykb2l00SnVoL1uS3paxv4Sq/tGeqKnHtlPX0/HD+6pQ/t1NsJ/BpPI+45he/WLJgG/9G73G6kkrWJK6Yp+KPDyybpSCs+H10JKGovb7Iza285aO7zs7ITxgRi0opsEAbXPH4de6nrbHQlgswzwv/x8H6Tzm57vR577H/vzxijw9ocNtjVv2m06rXNYM+u5ffNTZy8YSZozsRvPhgAzhtXRBOx7+mq3b3SnX1uwGyfTv7M8L5owQBxGwyQal5AzGx2fzwN5fK8a4JBq5ZhQ/lljmPa8Q9+KPnS4QlOHDw2mKFbI4+h/AwCtM1RY"
 }
@@ -0,0 +1,132 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package tls
+
+import (
+	"errors"
+
+	"github.com/elastic/beats/v7/libbeat/common/streambuf"
+	"github.com/elastic/beats/v7/libbeat/ecs"
+	"github.com/elastic/beats/v7/packetbeat/pb"
+	"github.com/elastic/elastic-agent-libs/mapstr"
+)
+
+var errNotHello = errors.New("not a client or server hello")
+
+// Hello is a client or server hello message of a TLS handshake carried by
+// another protocol, such as the CRYPTO frames of QUIC.
+type Hello struct {
+	hello    *helloMessage
+	isClient bool
+}
+
+// ParseHello parses a client or server hello handshake message, starting
+// with its handshake header. The data following the message is ignored.
+func ParseHello(data []byte) (h *Hello, err error) {
+	buf := streambuf.NewFixed(data)
+	header, err := readHandshakeHeader(buf)
+	if err != nil {
+		return nil, err
+	}
+	if handshakeHeaderSize+header.length > len(data) {
+		return nil, streambuf.ErrNoMoreBytes
+	}
+
+	// Recover from any bufferView.subview out of bounds errors.
+	defer func() {
+		r := recover()
+		switch r := r.(type) {
+		case nil:
+		case bufferViewError:
+			h, err = nil, r
+		default:
+			panic(r)
+		}
+	}()
+
+	view := *newBufferView(buf, handshakeHeaderSize, header.length)
+	h = &Hello{}
+	switch header.handshakeType {
+	case clientHello:
+		h.hello, h.isClient = parseClientHello(view), true
+	case serverHello:
+		h.hello = parseServerHello(view)
+	}
+	if h.hello == nil {
+		return nil, errNotHello
+	}
+	return h, nil
+}
+
+// IsClient returns whether the message is a client hello.
+func (h *Hello) IsClient() bool {
+	return h.isClient
+}
+
+// ServerName returns the server name indication of a client hello.
+func (h *Hello) ServerName() string {
+	if list, ok := h.hello.extensions.Parsed["server_name_indication"].([]string); ok && len(list) > 0 {
+		return list[0]
+	}
+	return ""
+}
+
+// PutHelloFields sets the tls fields of the client and server hellos of a
+// handshake in fields, as the TLS protocol does. Either hello may be nil.
+// transport is the transport of the JA4 fingerprints, and detailed whether
+// the tls.detailed fields are set.
+func PutHelloFields(fields mapstr.M, client, server *Hello, transport byte, detailed bool) {
+	var tls ecs.Tls
+	details := mapstr.M{}
+	ja4 := mapstr.M{}
+	if client != nil {
+		details["client_hello"] = client.hello.toMap()
+		tls.ClientServerName = client.ServerName()
+		tls.ClientJa3, _ = getJa3Fingerprint(client.hello)
+		ja4["client.ja4"], _ = getJa4Fingerprint(client.hello, transport)
+		tls.ClientSupportedCiphers = client.hello.supportedCiphers()
+	}
+	if server != nil {
+		details["server_hello"] = server.hello.toMap()
+		tls.ServerJa3s, _ = getJa3Fingerprint(server.hello)
+		ja4["server.ja4s"], _ = getJa4sFingerprint(server.hello, transport)
+		tls.Cipher = server.hello.selected.cipherSuite.String()
+
+		// The legacy version of a client hello isn't the negotiated version,
+		// which is only known from the server hello.
+		clientHello := &helloMessage{}
+		if client != nil {
+			clientHello = client.hello
+		}
+		version := negotiatedVersion(clientHello, server.hello)
+		details["version"] = version.String()
+		pVer := version.GetProtocolVersion()
+		tls.VersionProtocol, tls.Version = pVer.Protocol, pVer.Version
+	}
+
+	pb.MarshalStruct(fields, "tls", tls)
+	if len(tls.ClientSupportedCiphers) > 0 {
+		fields.Put("tls.client.supported_ciphers", tls.ClientSupportedCiphers)
+	}
+	for key, fingerprint := range ja4 {
+		fields.Put("tls."+key, fingerprint)
+	}
+	if detailed {
+		fields.Put("tls.detailed", details)
+	}
+}
@@ -0,0 +1,95 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+//go:build !integration
+
+package tls
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/elastic/elastic-agent-libs/mapstr"
+)
+
+func TestParseHello(t *testing.T) {
+	// The hellos without their record header.
+	data, err := hex.DecodeString(rawClientHello)
+	require.NoError(t, err)
+	client, err := ParseHello(data[recordHeaderSize:])
+	require.NoError(t, err)
+	assert.True(t, client.IsClient())
+	assert.Equal(t, "example.org", client.ServerName())
+
+	data, err = hex.DecodeString(rawServerHello)
+	require.NoError(t, err)
+	server, err := ParseHello(data[recordHeaderSize:])
+	require.NoError(t, err)
+	assert.False(t, server.IsClient())
+	assert.Equal(t, "", server.ServerName())
+
+	fields := mapstr.M{}
+	PutHelloFields(fields, client, server, JA4TransportQUIC, false)
+	for key, expected := range map[string]string{
+		"tls.client.server_name": "example.org",
+		"tls.client.ja3":         "94c485bca29d5392be53f2b8cf7f4304",
+		"tls.client.ja4":         "q12d1311h2_8b80da21ef18_eb7c9aabf852",
+		"tls.cipher":             "TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256",
+		"tls.version":            "1.2",
+		"tls.version_protocol":   "tls",
+	} {
+		value, err := fields.GetValue(key)
+		assert.NoError(t, err, key)
+		assert.Equal(t, expected, value, key)
+	}
+	ciphers, _ := fields.GetValue("tls.client.supported_ciphers")
+	assert.Len(t, ciphers, 13)
+	ja4s, _ := fields.GetValue("tls.server.ja4s")
+	assert.Regexp(t, "^q12", ja4s)
+	assert.NotContains(t, fields["tls"], "detailed")
+
+	fields = mapstr.M{}
+	PutHelloFields(fields, client, nil, JA4TransportQUIC, true)
+	alpn, err := fields.GetValue("tls.detailed.client_hello.extensions.application_layer_protocol_negotiation")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"h2", "http/1.1"}, alpn)
+	assert.NotContains(t, fields["tls"], "version")
+}
+
+func TestParseHelloInvalid(t *testing.T) {
+	data, err := hex.DecodeString(rawClientHello)
+	require.NoError(t, err)
+	hello := data[recordHeaderSize:]
+
+	_, err = ParseHello(hello[:len(hello)-1])
+	assert.Error(t, err)
+	_, err = ParseHello(hello[:2])
+	assert.Error(t, err)
+
+	// A hello whose cipher suites overflow the message.
+	truncated := append([]byte{}, hello[:60]...)
+	truncated[3] = 56
+	_, err = ParseHello(truncated)
+	assert.Error(t, err)
+
+	// A certificate message.
+	_, err = ParseHello([]byte{11, 0, 0, 3, 0, 0, 0})
+	assert.Error(t, err)
+}
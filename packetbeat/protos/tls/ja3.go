@@ -78,8 +78,8 @@ func extractJa3Array(raw []byte, size int) []uint16 {
 	if size == 2 {
 		limit = limit*256 + int(raw[1])
 	}
-	if actual < limit {
-		limit = actual
+	if actual < size+limit {
+		limit = actual - size
 	}
 	var array []uint16
 	for pos := size; pos <= limit; pos += size {
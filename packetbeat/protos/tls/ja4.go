@@ -0,0 +1,218 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package tls
+
+import (
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/hex"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Transports of the TLS connection, as the first character of JA4.
+const (
+	JA4TransportTCP  = 't'
+	JA4TransportQUIC = 'q'
+)
+
+// ja4EmptyHash replaces the hash of an empty list in JA4 fingerprints.
+const ja4EmptyHash = "000000000000"
+
+// getJa4Fingerprint returns the JA4 fingerprint of a client hello, and its
+// raw form with the lists that are hashed in the fingerprint.
+// See https://github.com/FoxIO-LLC/ja4/blob/main/technical_details/JA4.md.
+func getJa4Fingerprint(hello *helloMessage, transport byte) (ja4 string, raw string) {
+	var ciphers []string
+	for _, suite := range hello.supported.cipherSuites {
+		if !isGreaseValue(uint16(suite)) {
+			ciphers = append(ciphers, fmt.Sprintf("%04x", uint16(suite)))
+		}
+	}
+	slices.Sort(ciphers)
+
+	// The server name and ALPN extensions are counted but not hashed.
+	_, hasSNI := hello.extensions.Parsed["server_name_indication"]
+	sni := byte('i')
+	if hasSNI {
+		sni = 'd'
+	}
+	var extensions []string
+	for _, ext := range hello.extensions.InOrder {
+		if ext != ExtensionServerName && ext != ExtensionALPN {
+			extensions = append(extensions, fmt.Sprintf("%04x", uint16(ext)))
+		}
+	}
+	slices.Sort(extensions)
+	var signatureAlgorithms []string
+	for _, value := range extractJa3Array(hello.extensions.Raw[ExtensionSignatureAlgorithms], 2) {
+		signatureAlgorithms = append(signatureAlgorithms, fmt.Sprintf("%04x", value))
+	}
+
+	prefix := fmt.Sprintf("%c%s%c%02d%02d%s",
+		transport,
+		ja4Version(hello, true),
+		sni,
+		min(len(ciphers), 99),
+		min(len(hello.extensions.InOrder), 99),
+		ja4ALPN(hello),
+	)
+
+	extensionsStr := strings.Join(extensions, ",")
+	if len(signatureAlgorithms) > 0 {
+		extensionsStr += "_" + strings.Join(signatureAlgorithms, ",")
+	}
+
+	ciphersStr := strings.Join(ciphers, ",")
+	ja4 = prefix + "_" + ja4Hash(ciphersStr) + "_" + ja4Hash(extensionsStr)
+	raw = prefix + "_" + ciphersStr + "_" + extensionsStr
+	return ja4, raw
+}
+
+// getJa4sFingerprint returns the JA4S fingerprint of a server hello, and its
+// raw form with the list of extensions that is hashed in the fingerprint.
+// See https://github.com/FoxIO-LLC/ja4/blob/main/technical_details/JA4S.md.
+func getJa4sFingerprint(hello *helloMessage, transport byte) (ja4s string, raw string) {
+	extensions := make([]string, len(hello.extensions.InOrder))
+	for idx, ext := range hello.extensions.InOrder {
+		extensions[idx] = fmt.Sprintf("%04x", uint16(ext))
+	}
+
+	prefix := fmt.Sprintf("%c%s%02d%s_%04x",
+		transport,
+		ja4Version(hello, false),
+		min(len(extensions), 99),
+		ja4ALPN(hello),
+		uint16(hello.selected.cipherSuite),
+	)
+
+	extensionsStr := strings.Join(extensions, ",")
+	return prefix + "_" + ja4Hash(extensionsStr), prefix + "_" + extensionsStr
+}
+
+// getJa4xFingerprint returns the JA4X fingerprint of a certificate, and its
+// raw form with the OIDs of the issuer, subject and extensions that are
+// hashed in the fingerprint.
+// See https://github.com/FoxIO-LLC/ja4/blob/main/technical_details/JA4X.md.
+func getJa4xFingerprint(cert *x509.Certificate) (ja4x string, raw string) {
+	var issuer, subject, extensions []string
+	for _, name := range cert.Issuer.Names {
+		issuer = append(issuer, ja4OID(name.Type))
+	}
+	for _, name := range cert.Subject.Names {
+		subject = append(subject, ja4OID(name.Type))
+	}
+	for _, ext := range cert.Extensions {
+		extensions = append(extensions, ja4OID(ext.Id))
+	}
+
+	parts := []string{strings.Join(issuer, ","), strings.Join(subject, ","), strings.Join(extensions, ",")}
+	hashes := make([]string, len(parts))
+	for idx, part := range parts {
+		hashes[idx] = ja4Hash(part)
+	}
+	return strings.Join(hashes, "_"), strings.Join(parts, "_")
+}
+
+// ja4Version returns the highest version in the supported_versions extension
+// of the hello, or its version if the extension is not present.
+func ja4Version(hello *helloMessage, isClient bool) string {
+	version := uint16(hello.version.major)<<8 | uint16(hello.version.minor)
+	if raw, ok := hello.extensions.Raw[ExtensionSupportedVersions]; ok {
+		// The client sends a list with a 1-byte length of 2-byte versions,
+		// the server sends the selected version.
+		var versions []uint16
+		if !isClient {
+			raw = append([]byte{byte(len(raw))}, raw...)
+		}
+		if len(raw) > 0 {
+			limit := min(1+int(raw[0]), len(raw))
+			for pos := 1; pos+2 <= limit; pos += 2 {
+				if value := uint16(raw[pos])<<8 | uint16(raw[pos+1]); !isGreaseValue(value) {
+					versions = append(versions, value)
+				}
+			}
+		}
+		if len(versions) > 0 {
+			version = slices.Max(versions)
+		}
+	}
+
+	switch version {
+	case 0x0304:
+		return "13"
+	case 0x0303:
+		return "12"
+	case 0x0302:
+		return "11"
+	case 0x0301:
+		return "10"
+	case 0x0300:
+		return "s3"
+	case 0x0002:
+		return "s2"
+	case 0xfeff:
+		return "d1"
+	case 0xfefd:
+		return "d2"
+	case 0xfefc:
+		return "d3"
+	}
+	return "00"
+}
+
+// ja4ALPN returns the first and last characters of the first ALPN value, or
+// of its hex representation if they are not alphanumeric.
+func ja4ALPN(hello *helloMessage) string {
+	protocols, _ := hello.extensions.Parsed["application_layer_protocol_negotiation"].([]string)
+	if len(protocols) == 0 || len(protocols[0]) == 0 {
+		return "00"
+	}
+	alpn := protocols[0]
+	first, last := alpn[0], alpn[len(alpn)-1]
+	if isAlphanumeric(first) && isAlphanumeric(last) {
+		return string([]byte{first, last})
+	}
+	encoded := hex.EncodeToString([]byte(alpn))
+	return string([]byte{encoded[0], encoded[len(encoded)-1]})
+}
+
+// ja4OID returns the hex representation of the DER encoding of an OID.
+func ja4OID(oid asn1.ObjectIdentifier) string {
+	der, err := asn1.Marshal(oid)
+	if err != nil || len(der) < 2 {
+		return ""
+	}
+	// Skip the tag and length, OIDs are always shorter than 128 bytes.
+	return hex.EncodeToString(der[2:])
+}
+
+// ja4Hash returns the first 12 characters of the SHA256 hash of s.
+func ja4Hash(s string) string {
+	if s == "" {
+		return ja4EmptyHash
+	}
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])[:12]
+}
+
+func isAlphanumeric(c byte) bool {
+	return ('0' <= c && c <= '9') || ('A' <= c && c <= 'Z') || ('a' <= c && c <= 'z')
+}
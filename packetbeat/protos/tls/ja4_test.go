@@ -0,0 +1,124 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+//go:build !integration
+
+package tls
+
+import (
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/hex"
+	"testing"
+
+	"github.com/elastic/beats/v7/packetbeat/protos"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Client and server hellos for the examples in the JA4 specification, with
+// GREASE values that must be ignored.
+const (
+	ja4ClientHello = "16030100f3010000ef0303000102030405060708090a0b0c0d0e0f1011121314" +
+		"15161718191a1b1c1d1e1f0000202a2a130113021303c02bc02fc02cc030cca9" +
+		"cca8c013c014009c009d002f0035010000a60a0a000000000010000e00000b65" +
+		"78616d706c652e6f726700170000ff01000100000a000a00083a3a001d001700" +
+		"18000b00020100002300000010000e000c02683208687474702f312e31000500" +
+		"050100000000000d001200100403080404010503080505010806060100120000" +
+		"003300020000002d00020101002b0007063a3a03040303001b00030200024469" +
+		"000500030268321a1a000100001500080000000000000000"
+	ja4ServerHello = "160303003a020000360303202122232425262728292a2b2c2d2e2f3031323334" +
+		"35363738393a3b3c3d3e3f00130100000e00330004001d0000002b00020304"
+)
+
+func TestJa4(t *testing.T) {
+	results, tls := testInit()
+	tcpTuple := testTCPTuple()
+	var private protos.ProtocolData
+	for dir, packet := range []string{ja4ClientHello, ja4ServerHello} {
+		data, err := hex.DecodeString(packet)
+		assert.NoError(t, err)
+		private = tls.Parse(&protos.Packet{Payload: data}, tcpTuple, uint8(dir), private)
+	}
+	tls.ReceivedFin(tcpTuple, 0, private)
+	assert.Len(t, results.events, 1)
+	event := results.events[0]
+
+	ja4, err := event.Fields.GetValue("tls.client.ja4")
+	assert.NoError(t, err)
+	assert.Equal(t, "t13d1516h2_8daaf6152771_e5627efa2ab1", ja4)
+
+	ja4s, err := event.Fields.GetValue("tls.server.ja4s")
+	assert.NoError(t, err)
+	assert.Equal(t, "t130200_1301_234ea6891581", ja4s)
+}
+
+func TestJa4Raw(t *testing.T) {
+	buf := sBuf(t, ja4ClientHello)
+	hello := parseClientHello(*newBufferView(buf, 9, buf.Len()-9))
+	_, raw := getJa4Fingerprint(hello, JA4TransportTCP)
+	assert.Equal(t, "t13d1516h2_"+
+		"002f,0035,009c,009d,1301,1302,1303,c013,c014,c02b,c02c,c02f,c030,cca8,cca9_"+
+		"0005,000a,000b,000d,0012,0015,0017,001b,0023,002b,002d,0033,4469,ff01_"+
+		"0403,0804,0401,0503,0805,0501,0806,0601", raw)
+}
+
+func TestJa4ALPN(t *testing.T) {
+	for alpn, expected := range map[string]string{
+		"":         "00",
+		"h2":       "h2",
+		"http/1.1": "h1",
+		"h":        "hh",
+		"\xab":     "ab",
+		"\xabc":    "a3",
+		"3\xab":    "3b",
+	} {
+		hello := &helloMessage{}
+		if alpn != "" {
+			hello.extensions.Parsed = map[string]interface{}{
+				"application_layer_protocol_negotiation": []string{alpn},
+			}
+		}
+		assert.Equal(t, expected, ja4ALPN(hello), "alpn %q", alpn)
+	}
+}
+
+func TestJa4x(t *testing.T) {
+	oid := func(ids ...int) pkix.AttributeTypeAndValue {
+		return pkix.AttributeTypeAndValue{Type: asn1.ObjectIdentifier(ids)}
+	}
+	cert := &x509.Certificate{
+		Issuer: pkix.Name{Names: []pkix.AttributeTypeAndValue{
+			oid(2, 5, 4, 6), oid(2, 5, 4, 10), oid(2, 5, 4, 3),
+		}},
+		Subject: pkix.Name{Names: []pkix.AttributeTypeAndValue{
+			oid(2, 5, 4, 3),
+		}},
+		Extensions: []pkix.Extension{
+			{Id: asn1.ObjectIdentifier{2, 5, 29, 15}},
+			{Id: asn1.ObjectIdentifier{1, 3, 6, 1, 5, 5, 7, 1, 1}},
+		},
+	}
+	ja4x, raw := getJa4xFingerprint(cert)
+	assert.Equal(t, "550406,55040a,550403_550403_551d0f,2b06010505070101", raw)
+	assert.Equal(t, "a373a9f83c6b_7022c563de38_49d3a94d1c4d", ja4x)
+
+	ja4x, raw = getJa4xFingerprint(&x509.Certificate{})
+	assert.Equal(t, "__", raw)
+	assert.Equal(t, "000000000000_000000000000_000000000000", ja4x)
+}
@@ -276,6 +276,7 @@ func (plugin *tlsPlugin) createEvent(conn *tlsConnectionData) beat.Event {
 		Established: conn.handshakeCompleted > 1,
 	}
 	detailed := mapstr.M{}
+	ja4 := mapstr.M{}
 
 	emptyHello := &helloMessage{}
 	var clientHello, serverHello *helloMessage
@@ -283,6 +284,7 @@ func (plugin *tlsPlugin) createEvent(conn *tlsConnectionData) beat.Event {
 		clientHello = client.parser.hello
 		detailed["client_hello"] = clientHello.toMap()
 		tls.ClientJa3, _ = getJa3Fingerprint(clientHello)
+		ja4["client.ja4"], _ = getJa4Fingerprint(clientHello, JA4TransportTCP)
 		tls.ClientSupportedCiphers = clientHello.supportedCiphers()
 	} else {
 		clientHello = emptyHello
@@ -291,6 +293,7 @@ func (plugin *tlsPlugin) createEvent(conn *tlsConnectionData) beat.Event {
 		serverHello = server.parser.hello
 		detailed["server_hello"] = serverHello.toMap()
 		tls.ServerJa3s, _ = getJa3Fingerprint(serverHello)
+		ja4["server.ja4s"], _ = getJa4sFingerprint(serverHello, JA4TransportTCP)
 		tls.Cipher = serverHello.selected.cipherSuite.String()
 	} else {
 		serverHello = emptyHello
@@ -327,6 +330,7 @@ func (plugin *tlsPlugin) createEvent(conn *tlsConnectionData) beat.Event {
 		tls.ClientIssuer = cert.Issuer.String()
 		tls.ClientNotAfter = cert.NotAfter
 		tls.ClientNotBefore = cert.NotBefore
+		ja4["client.ja4x"], _ = getJa4xFingerprint(cert)
 	}
 	if list := server.parser.certificates; len(list) > 0 {
 		cert := list[0]
@@ -339,6 +343,7 @@ func (plugin *tlsPlugin) createEvent(conn *tlsConnectionData) beat.Event {
 		tls.ServerIssuer = cert.Issuer.String()
 		tls.ServerNotAfter = cert.NotAfter
 		tls.ServerNotBefore = cert.NotBefore
+		ja4["server.ja4x"], _ = getJa4xFingerprint(cert)
 	}
 	detailed["client_certificate_requested"] = server.parser.certRequested
 
@@ -391,21 +396,7 @@ func (plugin *tlsPlugin) createEvent(conn *tlsConnectionData) beat.Event {
 	}
 
 	// TLS version in use
-	var version tlsVersion
-	if !serverHello.version.IsZero() {
-		var ok bool
-		var raw []byte
-		const supportedVersionsExt = 43
-		if raw, ok = serverHello.extensions.Raw[supportedVersionsExt]; ok {
-			version.major = raw[0]
-			version.minor = raw[1]
-		}
-		if !ok {
-			version = serverHello.version
-		}
-	} else if !clientHello.version.IsZero() {
-		version = clientHello.version
-	}
+	version := negotiatedVersion(clientHello, serverHello)
 	detailed["version"] = version.String()
 	pVer := version.GetProtocolVersion()
 	tls.VersionProtocol, tls.Version = pVer.Protocol, pVer.Version
@@ -459,6 +450,9 @@ func (plugin *tlsPlugin) createEvent(conn *tlsConnectionData) beat.Event {
 	if len(tls.ClientSupportedCiphers) > 0 {
 		fields.Put("tls.client.supported_ciphers", tls.ClientSupportedCiphers)
 	}
+	for key, fingerprint := range ja4 {
+		fields.Put("tls."+key, fingerprint)
+	}
 	// Enforce booleans (not serialized when false)
 	if !tls.Established {
 		fields.Put("tls.established", tls.Established)
@@ -469,6 +463,27 @@ func (plugin *tlsPlugin) createEvent(conn *tlsConnectionData) beat.Event {
 	return evt
 }
 
+// negotiatedVersion returns the TLS version in use, from the
+// supported_versions extension of the server hello for TLS 1.3.
+func negotiatedVersion(clientHello, serverHello *helloMessage) tlsVersion {
+	var version tlsVersion
+	if !serverHello.version.IsZero() {
+		var ok bool
+		var raw []byte
+		const supportedVersionsExt = 43
+		if raw, ok = serverHello.extensions.Raw[supportedVersionsExt]; ok {
+			version.major = raw[0]
+			version.minor = raw[1]
+		}
+		if !ok {
+			version = serverHello.version
+		}
+	} else if !clientHello.version.IsZero() {
+		version = clientHello.version
+	}
+	return version
+}
+
 func getPEMCertChain(certs []*x509.Certificate) (chain []string) {
 	n := len(certs)
 	if n == 0 {
@@ -24,6 +24,7 @@ import (
 	"github.com/elastic/beats/v7/libbeat/beat"
 	"github.com/elastic/beats/v7/libbeat/common"
 	"github.com/elastic/beats/v7/libbeat/processors"
+	"github.com/elastic/beats/v7/packetbeat/capture"
 	"github.com/elastic/beats/v7/packetbeat/pb"
 	conf "github.com/elastic/elastic-agent-libs/config"
 	"github.com/elastic/elastic-agent-libs/logp"
@@ -42,6 +43,7 @@ type transProcessor struct {
 	localIPs         []net.IP // TODO: Periodically update this list.
 	internalNetworks []string
 	name             string
+	capture          *capture.Capture
 }
 
 var debugf = logp.MakeDebug("publish")
@@ -52,6 +54,7 @@ func NewTransactionPublisher(
 	ignoreOutgoing bool,
 	canDrop bool,
 	internalNetworks []string,
+	capture *capture.Capture,
 ) (*TransactionPublisher, error) {
 	addrs, err := common.LocalIPAddrs()
 	if err != nil {
@@ -73,6 +76,7 @@ func NewTransactionPublisher(
 			internalNetworks: internalNetworks,
 			name:             name,
 			ignoreOutgoing:   ignoreOutgoing,
+			capture:          capture,
 		},
 	}
 	return p, nil
@@ -164,6 +168,9 @@ func (p *transProcessor) Run(event *beat.Event) (*beat.Event, error) {
 				fields.Source.IP, fields.Destination.IP)
 			return nil, nil
 		}
+		if p.capture != nil {
+			p.capture.Trigger(event, fields)
+		}
 	}
 
 	return event, nil
@@ -36,6 +36,7 @@ import (
 
 	"github.com/elastic/elastic-agent-libs/logp"
 
+	"github.com/elastic/beats/v7/packetbeat/capture"
 	"github.com/elastic/beats/v7/packetbeat/config"
 	"github.com/elastic/beats/v7/packetbeat/decoder"
 )
@@ -70,6 +71,10 @@ type sniffer struct {
 
 	decoders Decoders
 
+	// capture keeps the packets read for the capture of the flows of
+	// transactions, if enabled.
+	capture *capture.Capture
+
 	log *logp.Logger
 }
 
@@ -89,8 +94,9 @@ const (
 // New create a new Sniffer instance. Settings are validated in a best effort
 // only, but no device is opened yet. Accessing and configuring the actual device
 // is done by the Run method. The id parameter is used to specify the metric
-// collection ID for AF_PACKET sniffers on Linux.
-func New(id string, testMode bool, _ string, decoders map[string]Decoders, interfaces []config.InterfaceConfig) (*Sniffer, error) {
+// collection ID for AF_PACKET sniffers on Linux. If capture is not nil, the
+// packets read are passed to it.
+func New(id string, testMode bool, _ string, decoders map[string]Decoders, interfaces []config.InterfaceConfig, capture *capture.Capture) (*Sniffer, error) {
 	s := &Sniffer{
 		sniffers: make([]sniffer, len(interfaces)),
 		log:      logp.NewLogger("sniffer"),
@@ -108,6 +114,7 @@ func New(id string, testMode bool, _ string, decoders map[string]Decoders, inter
 			id:            id,
 			idx:           i,
 			decoders:      dec,
+			capture:       capture,
 			log:           s.log,
 		}
 		child.state.Store(snifferInactive)
@@ -302,7 +309,7 @@ func (s *sniffer) sniffStatic(ctx context.Context, device string) error {
 	if cleanup != nil {
 		defer cleanup()
 	}
-	return s.sniffHandle(ctx, handle, dec, nil)
+	return s.sniffHandle(ctx, device, handle, dec, nil)
 }
 
 // sniffDynamic performs sniffing work on a stream of dynamic interfaces from
@@ -347,12 +354,12 @@ func (s *sniffer) sniffOneDynamic(ctx context.Context, device string, last layer
 		}
 	}
 
-	err = s.sniffHandle(ctx, handle, dec, refresh)
+	err = s.sniffHandle(ctx, device, handle, dec, refresh)
 	return linkType, dec, err
 }
 
 // sniff performs the sniffing work and writing dump files if requested.
-func (s *sniffer) sniffHandle(ctx context.Context, handle snifferHandle, dec *decoder.Decoder, refresh chan<- struct{}) error {
+func (s *sniffer) sniffHandle(ctx context.Context, device string, handle snifferHandle, dec *decoder.Decoder, refresh chan<- struct{}) error {
 	var w *pcapgo.Writer
 	if s.config.Dumpfile != "" {
 		const timeSuffixFormat = "20060102150405"
@@ -371,6 +378,15 @@ func (s *sniffer) sniffHandle(ctx context.Context, handle snifferHandle, dec *de
 		}
 	}
 
+	captureIdx := -1
+	if s.capture != nil {
+		name := device
+		if s.config.File != "" {
+			name = s.config.File
+		}
+		captureIdx = s.capture.Interface(name, handle.LinkType())
+	}
+
 	// Mark inactive sniffer as active. In case of the sniffer/packetbeat closing
 	// before/while Run is executed, the state will be snifferClosing.
 	// => return if state is already snifferClosing.
@@ -457,6 +473,9 @@ func (s *sniffer) sniffHandle(ctx context.Context, handle snifferHandle, dec *de
 		if s.config.OneAtATime {
 			s.log.Debugw("Packet received.", "network.packets", packets)
 		}
+		if captureIdx >= 0 {
+			s.capture.Add(captureIdx, ci, data)
+		}
 		dec.OnPacket(data, &ci)
 	}
 
@@ -17,3 +17,32 @@ class Test(BaseTest):
         assert o["query"] == "SELECT * from test where id = $1"
         assert o["source.bytes"] == 90
         assert o["destination.bytes"] == 101
+
+    def test_prepared_statements(self):
+        """
+        Should report each execution of a prepared statement, after an SCRAM
+        authentication, and skip the statements following an error.
+        """
+        self.render_config_template(
+            pgsql_ports=[5432]
+        )
+        self.run_packetbeat(pcap="pgsql_prepared_statements.pcap")
+
+        objs = self.read_output()
+        assert len(objs) == 3
+        assert all([o["type"] == "pgsql" for o in objs])
+
+        for o in objs[:2]:
+            assert o["status"] == "OK"
+            assert o["method"] == "SELECT"
+            assert o["query"] == "SELECT id, email FROM users WHERE name = $1"
+            assert o["pgsql.statement"] == "stmtcache_1"
+            assert o["pgsql.num_params"] == 1
+        assert objs[0]["pgsql.num_rows"] == 1
+        assert objs[1]["pgsql.num_rows"] == 2
+
+        o = objs[2]
+        assert o["status"] == "Error"
+        assert o["method"] == "INSERT"
+        assert o["pgsql.error_code"] == "23505"
+        assert "pgsql.statement" not in o
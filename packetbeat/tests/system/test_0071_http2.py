@@ -0,0 +1,84 @@
+from packetbeat import BaseTest
+
+"""
+Tests for the HTTP/2 protocol, over cleartext connections (h2c), and gRPC.
+"""
+
+
+class Test(BaseTest):
+
+    def test_grpc_health_check(self):
+        """
+        Should decode the gRPC calls and their status from the trailers,
+        or from the headers of a Trailers-Only response.
+        """
+        self.render_config_template(
+            http2_ports=[50051],
+        )
+        self.run_packetbeat(pcap="grpc_health_check.pcap",
+                            debug_selectors=["http2"])
+        objs = self.read_output()
+
+        assert len(objs) == 3
+        assert all([o["type"] == "http2" for o in objs])
+        assert all([o["network.protocol"] == "http2" for o in objs])
+        assert all([o["http.version"] == "2" for o in objs])
+        assert all([o["query"] == "POST /grpc.health.v1.Health/Check" for o in objs])
+        assert all([o["grpc.service"] == "grpc.health.v1.Health" for o in objs])
+        assert all([o["grpc.method"] == "Check" for o in objs])
+        assert all([o["url.full"] == "http://health.example.com:50051/grpc.health.v1.Health/Check" for o in objs])
+        assert [o["http2.stream_id"] for o in objs] == [1, 3, 5]
+
+        # The headers of the second and third calls use the dynamic table.
+        assert all([o["user_agent.original"] == "grpc-health-probe grpc-go/1.73.0" for o in objs])
+
+        for o in objs[:2]:
+            assert o["status"] == "OK"
+            assert o["grpc.status_code"] == 0
+            assert o["grpc.status"] == "OK"
+            assert o["http.response.status_code"] == 200
+
+        o = objs[2]
+        assert o["status"] == "Error"
+        assert o["event.outcome"] == "failure"
+        assert o["grpc.status_code"] == 5
+        assert o["grpc.status"] == "NOT_FOUND"
+        assert o["grpc.message"] == "unknown service"
+
+    def test_h2c(self):
+        """
+        Should decode the requests and responses multiplexed in a
+        cleartext HTTP/2 connection.
+        """
+        self.render_config_template(
+            http2_ports=[8080],
+            http2_send_all_headers=True,
+        )
+        self.run_packetbeat(pcap="http2_h2c.pcap",
+                            debug_selectors=["http2"])
+        objs = self.read_output()
+
+        assert len(objs) == 3
+        assert all(["grpc.method" not in o for o in objs])
+
+        o = objs[0]
+        assert o["status"] == "OK"
+        assert o["query"] == "GET /hello"
+        assert o["url.full"] == "http://h2c.example.com:8080/hello"
+        assert o["http.response.status_code"] == 200
+        assert o["http.response.body.bytes"] == 14
+        assert o["http.request.headers"]["user-agent"] == "h2c-client/1.0"
+        assert o["http.response.headers"]["content-type"] == "text/plain; charset=utf-8"
+
+        o = objs[1]
+        assert o["status"] == "OK"
+        assert o["query"] == "POST /api/items"
+        assert o["http.request.body.bytes"] == 25
+        assert o["http.request.headers"]["content-type"] == "application/json"
+        assert o["http.response.status_code"] == 201
+
+        o = objs[2]
+        assert o["status"] == "Error"
+        assert o["query"] == "GET /missing"
+        assert o["url.query"] == "page=2"
+        assert o["http.response.status_code"] == 404
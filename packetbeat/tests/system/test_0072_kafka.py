@@ -0,0 +1,55 @@
+from packetbeat import BaseTest
+
+"""
+Tests for the Kafka protocol.
+"""
+
+
+class Test(BaseTest):
+
+    def test_kafka_client(self):
+        """
+        Should decode the requests of a Kafka client and match them with
+        the responses of the broker by correlation ID.
+        """
+        self.render_config_template(
+            kafka_ports=[9092],
+        )
+        self.run_packetbeat(pcap="kafka_client.pcap",
+                            debug_selectors=["kafka"])
+        objs = self.read_output()
+
+        assert len(objs) == 7
+        assert all([o["type"] == "kafka" for o in objs])
+        assert all([o["network.protocol"] == "kafka" for o in objs])
+        assert all([o["kafka.client_id"] == "billing-service" for o in objs])
+        assert all(["error.message" not in o for o in objs])
+        assert [o["kafka.correlation_id"] for o in objs] == list(range(7))
+        assert [o["method"] for o in objs] == [
+            "ApiVersions", "ApiVersions", "Metadata", "Produce",
+            "Fetch", "JoinGroup", "OffsetCommit"]
+
+        # Flexible versions of ApiVersions and Metadata.
+        assert objs[1]["kafka.api_version"] == 3
+        assert objs[2]["kafka.api_version"] == 9
+        assert objs[2]["kafka.topics"] == ["orders"]
+
+        produce = objs[3]
+        assert produce["status"] == "Error"
+        assert produce["event.outcome"] == "failure"
+        assert produce["kafka.acks"] == -1
+        assert produce["kafka.partitions"] == ["orders-0", "orders-1"]
+        assert produce["kafka.errors"] == ["NOT_LEADER_OR_FOLLOWER"]
+        assert produce["source.bytes"] == 273
+        assert produce["destination.bytes"] == 72
+
+        fetch = objs[4]
+        assert fetch["status"] == "OK"
+        assert fetch["kafka.api_version"] == 11
+        assert fetch["kafka.partitions"] == ["orders-0"]
+
+        assert objs[5]["kafka.group_id"] == "billing"
+
+        commit = objs[6]
+        assert commit["kafka.group_id"] == "billing"
+        assert commit["kafka.errors"] == ["UNKNOWN_TOPIC_OR_PARTITION"]
@@ -0,0 +1,66 @@
+from packetbeat import BaseTest
+
+"""
+Tests for the MQTT protocol.
+"""
+
+
+class Test(BaseTest):
+
+    def test_mqtt_client(self):
+        """
+        Should decode the packets of an MQTT 3.1.1 client session and
+        correlate them with their acknowledgements.
+        """
+        self.render_config_template(
+            mqtt_ports=[1883],
+            mqtt_send_payload=True,
+        )
+        self.run_packetbeat(pcap="mqtt_client.pcap",
+                            debug_selectors=["mqtt"])
+        objs = self.read_output()
+
+        assert len(objs) == 9
+        assert all([o["type"] == "mqtt" for o in objs])
+        assert all([o["network.protocol"] == "mqtt" for o in objs])
+        assert all([o["mqtt.client_id"] == "sensor-42" for o in objs])
+        assert all([o["mqtt.protocol_version"] == "3.1.1" for o in objs])
+        assert [o["method"] for o in objs] == [
+            "CONNECT", "SUBSCRIBE", "SUBSCRIBE", "PUBLISH", "PUBLISH",
+            "PUBLISH", "PUBLISH", "UNSUBSCRIBE", "DISCONNECT"]
+
+        connect = objs[0]
+        assert connect["status"] == "OK"
+        assert connect["mqtt.username"] == "iot"
+        assert connect["mqtt.keep_alive"] == 30
+        assert connect["mqtt.clean_session"]
+        assert connect["mqtt.reason_codes"] == [0]
+
+        assert objs[1]["mqtt.topic_filters"] == ["sensors/+/temp"]
+        assert objs[1]["mqtt.reason_codes"] == [1]
+
+        refused = objs[2]
+        assert refused["status"] == "Error"
+        assert refused["event.outcome"] == "failure"
+        assert refused["resource"] == "$SYS/#"
+        assert refused["mqtt.reason_codes"] == [128]
+        assert refused["error.message"] == "Failure"
+
+        assert [o["mqtt.qos"] for o in objs[3:7]] == [0, 1, 2, 1]
+        assert [o["mqtt.payload"] for o in objs[3:7]] == [
+            "21.5", "21.7", "open", "18.0"]
+        assert all([o["mqtt.payload_size"] == 4 for o in objs[3:7]])
+        assert all([o["status"] == "OK" for o in objs[3:7]])
+
+        # The QoS 2 flow includes PUBREC, PUBREL and PUBCOMP.
+        exactly_once = objs[5]
+        assert exactly_once["resource"] == "alarms/door"
+        assert exactly_once["mqtt.retain"]
+        assert exactly_once["source.bytes"] == 25
+        assert exactly_once["destination.bytes"] == 8
+
+        # Message delivered by the broker to the client.
+        delivered = objs[6]
+        assert delivered["source.port"] == 1883
+        assert delivered["mqtt.topic"] == "sensors/garage/temp"
+        assert delivered["mqtt.packet_id"] == 1
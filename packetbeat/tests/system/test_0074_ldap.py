@@ -0,0 +1,57 @@
+from packetbeat import BaseTest
+
+"""
+Tests for the LDAP protocol.
+"""
+
+
+class Test(BaseTest):
+
+    def test_ldap_session(self):
+        """
+        Should decode the operations of an LDAP session and of a CLDAP
+        ping and correlate them with their responses.
+        """
+        self.render_config_template(
+            ldap_ports=[389],
+        )
+        self.run_packetbeat(pcap="ldap_session.pcap",
+                            debug_selectors=["ldap"])
+        objs = self.read_output()
+
+        assert len(objs) == 7
+        assert all([o["type"] == "ldap" for o in objs])
+        assert all([o["network.protocol"] == "ldap" for o in objs])
+        assert [o["method"] for o in objs] == [
+            "bind", "search", "modify", "add", "delete", "unbind", "search"]
+
+        bind = objs[0]
+        assert bind["status"] == "OK"
+        assert bind["resource"] == "cn=admin,dc=example,dc=com"
+        assert bind["ldap.auth_type"] == "simple"
+        assert bind["ldap.version"] == 3
+
+        search = objs[1]
+        assert search["ldap.scope"] == "wholeSubtree"
+        assert search["ldap.filter"] == "(&(objectClass=person)(cn=al*))"
+        assert search["ldap.attributes"] == ["cn", "mail"]
+        assert search["ldap.search_entries"] == 2
+        assert search["destination.bytes"] == 136
+
+        modify = objs[2]
+        assert modify["status"] == "Error"
+        assert modify["event.outcome"] == "failure"
+        assert modify["ldap.result_code"] == 50
+        assert modify["ldap.result_name"] == "insufficientAccessRights"
+        assert modify["ldap.modifications"] == ["replace: description"]
+        assert modify["error.message"] == [
+            "insufficientAccessRights", "no write access to parent"]
+
+        assert objs[3]["ldap.attributes"] == ["objectClass", "sn"]
+        assert all([o["status"] == "OK" for o in objs[3:]])
+
+        cldap = objs[6]
+        assert cldap["network.transport"] == "udp"
+        assert cldap["ldap.filter"] == \
+            "(&(DnsDomain=example.com)(NtVer=\\16\\00\\00\\00))"
+        assert cldap["ldap.search_entries"] == 1
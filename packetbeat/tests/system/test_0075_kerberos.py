@@ -0,0 +1,61 @@
+from packetbeat import BaseTest
+
+"""
+Tests for the Kerberos protocol.
+"""
+
+
+class Test(BaseTest):
+
+    def test_kerberos_session(self):
+        """
+        Should decode the AS exchanges of a client over TCP and its TGS
+        exchanges over UDP.
+        """
+        self.render_config_template(
+            kerberos_ports=[88],
+        )
+        self.run_packetbeat(pcap="kerberos_session.pcap",
+                            debug_selectors=["kerberos"])
+        objs = self.read_output()
+
+        assert len(objs) == 4
+        assert all([o["type"] == "kerberos" for o in objs])
+        assert all([o["network.protocol"] == "kerberos" for o in objs])
+        assert all([o["kerberos.client"] == "alice@EXAMPLE.COM"
+                    for o in objs])
+        assert all([o["kerberos.realm"] == "EXAMPLE.COM" for o in objs])
+        assert [o["method"] for o in objs] == [
+            "AS-REQ", "AS-REQ", "TGS-REQ", "TGS-REQ"]
+        assert [o["network.transport"] for o in objs] == [
+            "tcp", "tcp", "udp", "udp"]
+
+        preauth = objs[0]
+        assert preauth["status"] == "Error"
+        assert preauth["kerberos.response_type"] == "KRB-ERROR"
+        assert preauth["kerberos.error_code"] == 25
+        assert preauth["kerberos.error_name"] == "KDC_ERR_PREAUTH_REQUIRED"
+        assert preauth["kerberos.etypes"] == [
+            "aes256-cts-hmac-sha1-96", "aes128-cts-hmac-sha1-96", "rc4-hmac"]
+
+        tgt = objs[1]
+        assert tgt["status"] == "OK"
+        assert tgt["resource"] == "krbtgt/EXAMPLE.COM@EXAMPLE.COM"
+        assert tgt["kerberos.padata_types"] == [
+            "PA-ENC-TIMESTAMP", "PA-PAC-REQUEST"]
+        assert tgt["kerberos.ticket_etype"] == "aes256-cts-hmac-sha1-96"
+
+        service = objs[2]
+        assert service["status"] == "OK"
+        assert service["resource"] == "cifs/fs.example.com@EXAMPLE.COM"
+        assert service["kerberos.response_type"] == "TGS-REP"
+        assert service["kerberos.ticket_etype"] == "rc4-hmac"
+
+        unknown = objs[3]
+        assert unknown["status"] == "Error"
+        assert unknown["event.outcome"] == "failure"
+        assert unknown["kerberos.error_name"] == \
+            "KDC_ERR_S_PRINCIPAL_UNKNOWN"
+        assert unknown["error.message"] == [
+            "KDC_ERR_S_PRINCIPAL_UNKNOWN",
+            "Server not found in Kerberos database"]
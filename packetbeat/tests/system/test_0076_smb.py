@@ -0,0 +1,70 @@
+from packetbeat import BaseTest
+
+"""
+Tests for the SMB protocol.
+"""
+
+
+class Test(BaseTest):
+
+    def test_smb_session(self):
+        """
+        Should decode the negotiation, NTLM authentication, tree connects and
+        file operations of an SMB 3.1.1 session.
+        """
+        self.render_config_template(
+            smb_ports=[445],
+        )
+        self.run_packetbeat(pcap="smb_session.pcap",
+                            debug_selectors=["smb"])
+        objs = self.read_output()
+
+        assert len(objs) == 10
+        assert all([o["type"] == "smb" for o in objs])
+        assert all([o["network.protocol"] == "smb" for o in objs])
+        assert all([o["smb.dialect"] == "3.1.1" for o in objs])
+        assert [o["method"] for o in objs] == [
+            "NEGOTIATE", "SESSION_SETUP", "SESSION_SETUP", "TREE_CONNECT",
+            "CREATE", "WRITE", "READ", "CLOSE", "TREE_CONNECT",
+            "TREE_CONNECT"]
+
+        negotiate = objs[0]
+        assert negotiate["smb.dialects"] == [
+            "2.0.2", "2.1", "3.0", "3.0.2", "3.1.1"]
+        assert negotiate["smb.signing_required"]
+        assert negotiate["smb.encryption_cipher"] == "AES-128-GCM"
+        assert negotiate["smb.signing_algorithm"] == "AES-GMAC"
+
+        challenge = objs[1]
+        assert challenge["status"] == "OK"
+        assert challenge["smb.status"] == "STATUS_MORE_PROCESSING_REQUIRED"
+
+        auth = objs[2]
+        assert auth["smb.auth_mechanism"] == "ntlm"
+        assert auth["smb.workstation"] == "WS01"
+        assert auth["smb.session_id"] == "0x0000040000000011"
+        assert all([o["user.name"] == "alice" for o in objs[2:]])
+        assert all([o["user.domain"] == "EXAMPLE" for o in objs[2:]])
+
+        tree = objs[3]
+        assert tree["smb.share"] == "\\\\fs.example.com\\projects"
+        assert tree["smb.share_type"] == "disk"
+        assert tree["smb.tree_id"] == 5
+
+        for o in objs[4:8]:
+            assert o["status"] == "OK"
+            assert o["smb.file"] == "reports\\q1.xlsx"
+            assert o["resource"] == \
+                "\\\\fs.example.com\\projects\\reports\\q1.xlsx"
+        assert objs[4]["smb.create_action"] == "created"
+        assert objs[5]["smb.transferred"] == 100000
+        assert objs[5]["source.bytes"] == 100112
+        assert objs[6]["smb.transferred"] == 65536
+
+        missing = objs[8]
+        assert missing["status"] == "Error"
+        assert missing["event.outcome"] == "failure"
+        assert missing["smb.status"] == "STATUS_BAD_NETWORK_NAME"
+        assert missing["error.message"] == "STATUS_BAD_NETWORK_NAME"
+
+        assert objs[9]["smb.share_encrypted"]
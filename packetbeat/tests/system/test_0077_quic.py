@@ -0,0 +1,47 @@
+from packetbeat import BaseTest
+
+"""
+Tests for the QUIC protocol.
+"""
+
+
+class Test(BaseTest):
+
+    def test_quic_handshakes(self):
+        """
+        Should decrypt the Initial packets of QUIC connections and report
+        their TLS handshake.
+        """
+        self.render_config_template(
+            quic_ports=[443],
+        )
+        self.run_packetbeat(pcap="quic_handshake.pcap",
+                            debug_selectors=["quic"])
+        objs = self.read_output()
+
+        assert len(objs) == 2
+        assert all([o["type"] == "quic" for o in objs])
+        assert all([o["network.transport"] == "udp" for o in objs])
+        assert all([o["quic.version"] == "1" for o in objs])
+        assert all([o["tls.client.ja4"].startswith("q13d") for o in objs])
+
+        ok = objs[0]
+        assert ok["status"] == "OK"
+        assert ok["destination.domain"] == "www.example.com"
+        assert ok["tls.client.server_name"] == "www.example.com"
+        assert ok["quic.destination_connection_id"] == "5a1f9c3e07d24481"
+        assert ok["quic.source_connection_id"] == "c1137e02"
+        assert ok["quic.server_connection_id"] == "9e44210b6a38f51c"
+        assert not ok["quic.retry"]
+        assert ok["tls.version"] == "1.3"
+        assert ok["tls.cipher"] == "TLS_AES_128_GCM_SHA256"
+        assert ok["tls.server.ja4s"] == "q130200_1301_a56c5b993250"
+        assert ok["tls.detailed.client_hello.extensions." +
+                  "application_layer_protocol_negotiation"] == ["h3"]
+
+        refused = objs[1]
+        assert refused["status"] == "Error"
+        assert refused["tls.client.server_name"] == "api.example.com"
+        assert refused["quic.close_error"] == "CRYPTO_ERROR_0x78"
+        assert refused["quic.close_reason"] == "no application protocol"
+        assert "tls.version" not in refused
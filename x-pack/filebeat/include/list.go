@@ -41,6 +41,7 @@ import (
 	_ "github.com/elastic/beats/v7/x-pack/filebeat/module/iptables"
 	_ "github.com/elastic/beats/v7/x-pack/filebeat/module/juniper"
 	_ "github.com/elastic/beats/v7/x-pack/filebeat/module/microsoft"
+	_ "github.com/elastic/beats/v7/x-pack/filebeat/module/meraki"
 	_ "github.com/elastic/beats/v7/x-pack/filebeat/module/misp"
 	_ "github.com/elastic/beats/v7/x-pack/filebeat/module/mssql"
 	_ "github.com/elastic/beats/v7/x-pack/filebeat/module/mysqlenterprise"
@@ -0,0 +1,120 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package archive
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+type archiveFormat uint8
+
+const (
+	formatUnknown archiveFormat = iota
+	formatTar
+	formatTarGzip
+	formatZip
+)
+
+// detectFormat returns the archive format based on the file name.
+func detectFormat(name string) archiveFormat {
+	name = strings.ToLower(name)
+	switch {
+	case strings.HasSuffix(name, ".tar.gz"), strings.HasSuffix(name, ".tgz"):
+		return formatTarGzip
+	case strings.HasSuffix(name, ".tar"):
+		return formatTar
+	case strings.HasSuffix(name, ".zip"):
+		return formatZip
+	default:
+		return formatUnknown
+	}
+}
+
+// memberFunc is called for every regular file of an archive. The reader is
+// only valid until the function returns.
+type memberFunc func(name string, r io.Reader) error
+
+// walkArchive calls fn for every regular file of the archive at path, in the
+// order they are stored in the archive. It stops at the first error returned
+// by fn.
+func walkArchive(path string, format archiveFormat, fn memberFunc) error {
+	switch format {
+	case formatTar, formatTarGzip:
+		return walkTar(path, format == formatTarGzip, fn)
+	case formatZip:
+		return walkZip(path, fn)
+	default:
+		return fmt.Errorf("unsupported archive format for %s", path)
+	}
+}
+
+func walkTar(path string, compressed bool, fn memberFunc) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	var r io.Reader = f
+	if compressed {
+		gz, err := gzip.NewReader(f)
+		if err != nil {
+			return fmt.Errorf("failed to open gzip stream: %w", err)
+		}
+		defer gz.Close()
+		r = gz
+	}
+
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read tar header: %w", err)
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+		if err := fn(hdr.Name, tr); err != nil {
+			return err
+		}
+	}
+}
+
+func walkZip(path string, fn memberFunc) error {
+	zr, err := zip.OpenReader(path)
+	if err != nil {
+		return err
+	}
+	defer zr.Close()
+
+	for _, f := range zr.File {
+		if !f.Mode().IsRegular() {
+			continue
+		}
+		if err := walkZipMember(f, fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func walkZipMember(f *zip.File, fn memberFunc) error {
+	r, err := f.Open()
+	if err != nil {
+		return fmt.Errorf("failed to open zip member %s: %w", f.Name, err)
+	}
+	defer r.Close()
+	return fn(f.Name, r)
+}
@@ -0,0 +1,110 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package archive
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type testMember struct {
+	name    string
+	content string
+}
+
+// writeTestArchive creates an archive in dir holding the members, in the
+// format given by the name extension.
+func writeTestArchive(t *testing.T, dir, name string, members ...testMember) string {
+	t.Helper()
+
+	path := filepath.Join(dir, name)
+	f, err := os.Create(path)
+	require.NoError(t, err)
+	defer f.Close()
+
+	switch detectFormat(name) {
+	case formatZip:
+		zw := zip.NewWriter(f)
+		_, err = zw.Create("dir/")
+		require.NoError(t, err)
+		for _, m := range members {
+			w, err := zw.Create(m.name)
+			require.NoError(t, err)
+			_, err = io.WriteString(w, m.content)
+			require.NoError(t, err)
+		}
+		require.NoError(t, zw.Close())
+	case formatTar, formatTarGzip:
+		var w io.Writer = f
+		if detectFormat(name) == formatTarGzip {
+			gz := gzip.NewWriter(f)
+			defer func() { require.NoError(t, gz.Close()) }()
+			w = gz
+		}
+		tw := tar.NewWriter(w)
+		require.NoError(t, tw.WriteHeader(&tar.Header{Name: "dir/", Typeflag: tar.TypeDir, Mode: 0o755, ModTime: time.Now()}))
+		for _, m := range members {
+			require.NoError(t, tw.WriteHeader(&tar.Header{Name: m.name, Typeflag: tar.TypeReg, Mode: 0o644, Size: int64(len(m.content)), ModTime: time.Now()}))
+			_, err = io.WriteString(tw, m.content)
+			require.NoError(t, err)
+		}
+		require.NoError(t, tw.Close())
+	default:
+		t.Fatalf("unsupported archive name %s", name)
+	}
+	return path
+}
+
+func TestDetectFormat(t *testing.T) {
+	require.Equal(t, formatTarGzip, detectFormat("bundle.tar.gz"))
+	require.Equal(t, formatTarGzip, detectFormat("BUNDLE.TGZ"))
+	require.Equal(t, formatTar, detectFormat("bundle.tar"))
+	require.Equal(t, formatZip, detectFormat("bundle.zip"))
+	require.Equal(t, formatUnknown, detectFormat("bundle.gz"))
+	require.Equal(t, formatUnknown, detectFormat("app.log"))
+}
+
+func TestWalkArchive(t *testing.T) {
+	members := []testMember{
+		{"dir/a.log", "line a1\nline a2\n"},
+		{"b.log", "line b1\n"},
+	}
+
+	for _, name := range []string{"bundle.tar", "bundle.tar.gz", "bundle.tgz", "bundle.zip"} {
+		t.Run(name, func(t *testing.T) {
+			path := writeTestArchive(t, t.TempDir(), name, members...)
+
+			var got []testMember
+			err := walkArchive(path, detectFormat(name), func(name string, r io.Reader) error {
+				b, err := io.ReadAll(r)
+				if err != nil {
+					return err
+				}
+				got = append(got, testMember{name, string(b)})
+				return nil
+			})
+			require.NoError(t, err)
+			require.Equal(t, members, got)
+		})
+	}
+}
+
+func TestWalkArchiveInvalid(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"bundle.tar.gz", "bundle.zip"} {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(path, []byte("not an archive"), 0o644))
+		err := walkArchive(path, detectFormat(name), func(string, io.Reader) error { return nil })
+		require.Error(t, err, name)
+	}
+}
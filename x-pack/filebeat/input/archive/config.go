@@ -0,0 +1,97 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package archive
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/dustin/go-humanize"
+
+	"github.com/elastic/beats/v7/libbeat/common/cfgtype"
+	"github.com/elastic/beats/v7/libbeat/common/match"
+	"github.com/elastic/beats/v7/libbeat/reader/parser"
+	"github.com/elastic/beats/v7/libbeat/reader/readfile"
+	"github.com/elastic/beats/v7/libbeat/reader/readfile/encoding"
+)
+
+type config struct {
+	Paths          []string        `config:"paths"          validate:"required"`      // Glob patterns matching the archives to read.
+	ScanFrequency  time.Duration   `config:"scan_frequency" validate:"nonzero,min=0"` // Interval between two scans of the paths.
+	IncludeMembers []match.Matcher `config:"include_members"`                         // Only read members whose name matches one of the patterns.
+	ExcludeMembers []match.Matcher `config:"exclude_members"`                         // Do not read members whose name matches one of the patterns.
+	DeleteAfterACK bool            `config:"delete_after_ack"`                        // Delete archives once all their events are ACKed.
+	MoveTo         string          `config:"move_to"`                                 // Move archives to this directory once all their events are ACKed.
+	ReaderConfig   readerConfig    `config:",inline"`
+}
+
+// readerConfig defines the options for reading the members of an archive.
+type readerConfig struct {
+	BufferSize     cfgtype.ByteSize        `config:"buffer_size"`
+	Encoding       string                  `config:"encoding"`
+	LineTerminator readfile.LineTerminator `config:"line_terminator"`
+	MaxBytes       cfgtype.ByteSize        `config:"max_bytes"`
+	Parsers        parser.Config           `config:",inline"`
+}
+
+func defaultConfig() config {
+	return config{
+		ScanFrequency: 10 * time.Second,
+	}
+}
+
+func (c *config) Validate() error {
+	for _, p := range c.Paths {
+		if _, err := filepath.Match(p, ""); err != nil {
+			return fmt.Errorf("invalid path pattern %q: %w", p, err)
+		}
+	}
+	if c.DeleteAfterACK && c.MoveTo != "" {
+		return errors.New("delete_after_ack and move_to cannot be used together")
+	}
+	return nil
+}
+
+func (rc *readerConfig) InitDefaults() {
+	rc.BufferSize = 16 * humanize.KiByte
+	rc.MaxBytes = 10 * humanize.MiByte
+	rc.LineTerminator = readfile.AutoLineTerminator
+}
+
+func (rc *readerConfig) Validate() error {
+	if rc.BufferSize <= 0 {
+		return fmt.Errorf("buffer_size <%v> must be greater than 0", rc.BufferSize)
+	}
+
+	if rc.MaxBytes <= 0 {
+		return fmt.Errorf("max_bytes <%v> must be greater than 0", rc.MaxBytes)
+	}
+
+	_, found := encoding.FindEncoding(rc.Encoding)
+	if !found {
+		return fmt.Errorf("encoding type <%v> not found", rc.Encoding)
+	}
+
+	return nil
+}
+
+// memberSelected reports whether the member with the given name should be read.
+func (c *config) memberSelected(name string) bool {
+	if len(c.IncludeMembers) > 0 && !matchAny(c.IncludeMembers, name) {
+		return false
+	}
+	return !matchAny(c.ExcludeMembers, name)
+}
+
+func matchAny(matchers []match.Matcher, name string) bool {
+	for _, m := range matchers {
+		if m.MatchString(name) {
+			return true
+		}
+	}
+	return false
+}
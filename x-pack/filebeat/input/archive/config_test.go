@@ -0,0 +1,99 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package archive
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/elastic/beats/v7/libbeat/reader/readfile"
+	conf "github.com/elastic/elastic-agent-libs/config"
+)
+
+func TestConfig(t *testing.T) {
+	testCases := []struct {
+		name        string
+		userConfig  map[string]interface{}
+		expectedErr string
+	}{
+		{
+			"valid",
+			map[string]interface{}{
+				"paths":            []string{"/var/spool/bundles/*.tar.gz"},
+				"delete_after_ack": true,
+			},
+			"",
+		},
+		{
+			"missing paths",
+			map[string]interface{}{},
+			"missing required field accessing 'paths'",
+		},
+		{
+			"invalid path pattern",
+			map[string]interface{}{
+				"paths": []string{"/var/spool/[bundles"},
+			},
+			`invalid path pattern "/var/spool/[bundles"`,
+		},
+		{
+			"delete and move",
+			map[string]interface{}{
+				"paths":            []string{"/var/spool/bundles/*.zip"},
+				"delete_after_ack": true,
+				"move_to":          "/var/spool/done",
+			},
+			"delete_after_ack and move_to cannot be used together",
+		},
+		{
+			"unknown encoding",
+			map[string]interface{}{
+				"paths":    []string{"/var/spool/bundles/*.zip"},
+				"encoding": "no-such-encoding",
+			},
+			"encoding type <no-such-encoding> not found",
+		},
+		{
+			"invalid scan_frequency",
+			map[string]interface{}{
+				"paths":          []string{"/var/spool/bundles/*.zip"},
+				"scan_frequency": 0,
+			},
+			"zero value accessing 'scan_frequency'",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			c := defaultConfig()
+			err := conf.MustNewConfigFrom(tc.userConfig).Unpack(&c)
+			if tc.expectedErr != "" {
+				require.ErrorContains(t, err, tc.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, 10*time.Second, c.ScanFrequency)
+			require.EqualValues(t, 16*1024, c.ReaderConfig.BufferSize)
+			require.EqualValues(t, 10*1024*1024, c.ReaderConfig.MaxBytes)
+			require.Equal(t, readfile.AutoLineTerminator, c.ReaderConfig.LineTerminator)
+		})
+	}
+}
+
+func TestMemberSelected(t *testing.T) {
+	c := defaultConfig()
+	err := conf.MustNewConfigFrom(map[string]interface{}{
+		"paths":           []string{"/var/spool/bundles/*.zip"},
+		"include_members": []string{`\.log$`},
+		"exclude_members": []string{`^debug/`},
+	}).Unpack(&c)
+	require.NoError(t, err)
+
+	require.True(t, c.memberSelected("logs/app.log"))
+	require.False(t, c.memberSelected("logs/app.json"))
+	require.False(t, c.memberSelected("debug/app.log"))
+}
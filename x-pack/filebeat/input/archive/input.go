@@ -0,0 +1,101 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package archive
+
+import (
+	"fmt"
+
+	v2 "github.com/elastic/beats/v7/filebeat/input/v2"
+	"github.com/elastic/beats/v7/libbeat/beat"
+	"github.com/elastic/beats/v7/libbeat/common/acker"
+	"github.com/elastic/beats/v7/libbeat/feature"
+	"github.com/elastic/beats/v7/libbeat/management/status"
+	"github.com/elastic/beats/v7/libbeat/statestore"
+	conf "github.com/elastic/elastic-agent-libs/config"
+	"github.com/elastic/go-concert/timed"
+	"github.com/elastic/go-concert/unison"
+)
+
+const inputName = "archive"
+
+func Plugin(store statestore.States) v2.Plugin {
+	return v2.Plugin{
+		Name:      inputName,
+		Stability: feature.Beta,
+		Info:      "Collect logs from tar and zip archives",
+		Manager:   &archiveInputManager{store: store},
+	}
+}
+
+type archiveInputManager struct {
+	store statestore.States
+}
+
+func (im *archiveInputManager) Init(grp unison.Group) error {
+	return nil
+}
+
+func (im *archiveInputManager) Create(cfg *conf.C) (v2.Input, error) {
+	config := defaultConfig()
+	if err := cfg.Unpack(&config); err != nil {
+		return nil, err
+	}
+	return &archiveInput{config: config, store: im.store}, nil
+}
+
+type archiveInput struct {
+	config config
+	store  statestore.States
+}
+
+var _ v2.Input = (*archiveInput)(nil)
+
+func (in *archiveInput) Name() string { return inputName }
+
+func (in *archiveInput) Test(ctx v2.TestContext) error {
+	return nil
+}
+
+func (in *archiveInput) Run(inputCtx v2.Context, pipeline beat.Pipeline) error {
+	inputCtx.UpdateStatus(status.Starting, "")
+	log := inputCtx.Logger
+	log.Info("Starting " + inputName + " input")
+	defer log.Info(inputName + " input stopped")
+
+	states, err := newStates(log, in.store, inputCtx.ID)
+	if err != nil {
+		err = fmt.Errorf("can not start persistent store: %w", err)
+		inputCtx.UpdateStatus(status.Failed, err.Error())
+		return err
+	}
+	defer states.Close()
+
+	metrics := newInputMetrics(inputCtx.ID, nil)
+	defer metrics.Close()
+
+	r := newArchiveReader(in.config, log, states, metrics)
+
+	// Create client for publishing events and receive notification of their ACKs.
+	client, err := pipeline.ConnectWith(beat.ClientConfig{
+		EventListener: acker.ConnectionOnly(acker.EventPrivateReporter(func(_ int, privates []interface{}) {
+			r.onACK(privates)
+		})),
+	})
+	if err != nil {
+		err = fmt.Errorf("failed to create pipeline client: %w", err)
+		inputCtx.UpdateStatus(status.Failed, err.Error())
+		return err
+	}
+	defer client.Close()
+
+	inputCtx.UpdateStatus(status.Running, "")
+	ctx := v2.GoContextFromCanceler(inputCtx.Cancelation)
+	for ctx.Err() == nil {
+		r.scan(ctx, client.Publish)
+		_ = timed.Wait(ctx, in.config.ScanFrequency)
+	}
+	inputCtx.UpdateStatus(status.Stopping, "")
+	return nil
+}
@@ -0,0 +1,41 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package archive
+
+import (
+	"github.com/elastic/beats/v7/libbeat/monitoring/inputmon"
+	"github.com/elastic/elastic-agent-libs/monitoring"
+)
+
+type inputMetrics struct {
+	unregister func()
+
+	archivesOpenedTotal    *monitoring.Uint // Number of archives opened for reading.
+	archivesCompletedTotal *monitoring.Uint // Number of archives whose events are all ACKed.
+	archiveErrorsTotal     *monitoring.Uint // Number of archives that failed to be read or finalized.
+	membersReadTotal       *monitoring.Uint // Number of archive members read to the end.
+	messagesReadTotal      *monitoring.Uint // Number of messages read from archive members.
+	bytesProcessedTotal    *monitoring.Uint // Number of bytes of archive members processed.
+	eventsPublishedTotal   *monitoring.Uint // Number of events published.
+}
+
+func (m *inputMetrics) Close() {
+	m.unregister()
+}
+
+func newInputMetrics(id string, optionalParent *monitoring.Registry) *inputMetrics {
+	reg, unreg := inputmon.NewInputRegistry(inputName, id, optionalParent)
+
+	return &inputMetrics{
+		unregister:             unreg,
+		archivesOpenedTotal:    monitoring.NewUint(reg, "archives_opened_total"),
+		archivesCompletedTotal: monitoring.NewUint(reg, "archives_completed_total"),
+		archiveErrorsTotal:     monitoring.NewUint(reg, "archive_errors_total"),
+		membersReadTotal:       monitoring.NewUint(reg, "members_read_total"),
+		messagesReadTotal:      monitoring.NewUint(reg, "messages_read_total"),
+		bytesProcessedTotal:    monitoring.NewUint(reg, "bytes_processed_total"),
+		eventsPublishedTotal:   monitoring.NewUint(reg, "events_published_total"),
+	}
+}
@@ -0,0 +1,330 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package archive
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/elastic/beats/v7/libbeat/beat"
+	"github.com/elastic/beats/v7/libbeat/reader"
+	"github.com/elastic/beats/v7/libbeat/reader/readfile"
+	"github.com/elastic/beats/v7/libbeat/reader/readfile/encoding"
+	"github.com/elastic/elastic-agent-libs/logp"
+	"github.com/elastic/elastic-agent-libs/mapstr"
+)
+
+// ackProgress is attached to every published event. Once the event is ACKed
+// the member state is persisted.
+type ackProgress struct {
+	member state
+	// archive is only set on the last event published for an archive. It
+	// holds the final state of the archive.
+	archive *state
+}
+
+// archiveReader reads the archives matching the configured paths and keeps
+// track of their progress.
+type archiveReader struct {
+	config  config
+	log     *logp.Logger
+	states  *states
+	metrics *inputMetrics
+
+	// inflight holds the IDs of the archives read to the end whose events
+	// are not all ACKed yet. mu must be held to access inflight.
+	mu       sync.Mutex
+	inflight map[string]struct{}
+}
+
+func newArchiveReader(config config, log *logp.Logger, states *states, metrics *inputMetrics) *archiveReader {
+	return &archiveReader{
+		config:   config,
+		log:      log,
+		states:   states,
+		metrics:  metrics,
+		inflight: map[string]struct{}{},
+	}
+}
+
+// scan reads all archives matching the configured paths that have not been
+// read before and removes the states of the archives that no longer exist.
+func (r *archiveReader) scan(ctx context.Context, publish func(beat.Event)) {
+	known := map[string]struct{}{}
+	for _, pattern := range r.config.Paths {
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			r.log.Errorw("Failed to match archive paths.", "pattern", pattern, "error", err)
+			continue
+		}
+		for _, path := range matches {
+			if ctx.Err() != nil {
+				return
+			}
+
+			format := detectFormat(path)
+			if format == formatUnknown {
+				r.log.Debugw("Skipping file with unsupported archive format.", "path", path)
+				continue
+			}
+			info, err := os.Stat(path)
+			if err != nil {
+				r.log.Warnw("Failed to stat archive.", "path", path, "error", err)
+				continue
+			}
+			if !info.Mode().IsRegular() {
+				continue
+			}
+
+			arc := state{Archive: path, Size: info.Size(), ModTime: info.ModTime()}
+			id := arc.archiveID()
+			if _, ok := known[id]; ok {
+				continue
+			}
+			known[id] = struct{}{}
+
+			if r.isInflight(id) {
+				continue
+			}
+			if prev, ok := r.states.Get(arc.ID()); ok && (prev.Done || prev.Failed) {
+				continue
+			}
+			r.readArchive(ctx, arc, format, publish)
+		}
+	}
+
+	if ctx.Err() != nil {
+		return
+	}
+	if err := r.states.CleanUp(known); err != nil {
+		r.log.Errorw("Failed to clean up archive states.", "error", err)
+	}
+}
+
+// readArchive publishes the events of all selected members of the archive.
+// Events are published one step behind reading, so that the last event of
+// a member and of the archive can be flagged before it is published.
+func (r *archiveReader) readArchive(ctx context.Context, arc state, format archiveFormat, publish func(beat.Event)) {
+	r.log.Infow("Reading archive.", "path", arc.Archive)
+	r.metrics.archivesOpenedTotal.Inc()
+
+	var (
+		last         *beat.Event
+		lastProgress *ackProgress
+	)
+	emit := func(event beat.Event, progress *ackProgress) {
+		if last != nil {
+			publish(*last)
+			r.metrics.eventsPublishedTotal.Inc()
+		}
+		last, lastProgress = &event, progress
+	}
+
+	err := walkArchive(arc.Archive, format, func(name string, rd io.Reader) error {
+		if !r.config.memberSelected(name) {
+			return nil
+		}
+
+		member := arc
+		member.Member = name
+		if prev, ok := r.states.Get(member.ID()); ok {
+			if prev.Done {
+				return nil
+			}
+			member.Offset = prev.Offset
+		}
+
+		n, err := r.readMember(ctx, member, rd, emit)
+		if err != nil {
+			return fmt.Errorf("failed to read member %s: %w", name, err)
+		}
+		if n > 0 {
+			lastProgress.member.Done = true
+		}
+		r.metrics.membersReadTotal.Inc()
+		return nil
+	})
+
+	if ctx.Err() != nil {
+		// The archive is read again from the ACKed offsets on restart.
+		if last != nil {
+			publish(*last)
+			r.metrics.eventsPublishedTotal.Inc()
+		}
+		return
+	}
+
+	final := arc
+	if err != nil {
+		r.log.Errorw("Failed to read archive.", "path", arc.Archive, "error", err)
+		r.metrics.archiveErrorsTotal.Inc()
+		final.Failed = true
+	} else {
+		final.Done = true
+	}
+
+	if last == nil {
+		r.complete(final)
+		return
+	}
+
+	r.mu.Lock()
+	r.inflight[arc.archiveID()] = struct{}{}
+	r.mu.Unlock()
+	lastProgress.archive = &final
+	publish(*last)
+	r.metrics.eventsPublishedTotal.Inc()
+}
+
+// readMember reads the member through the configured parsers and emits an
+// event per message. Messages ending before member.Offset have been ACKed
+// already and are skipped. It returns the number of emitted events.
+func (r *archiveReader) readMember(ctx context.Context, member state, rd io.Reader, emit func(beat.Event, *ackProgress)) (int, error) {
+	rc := r.config.ReaderConfig
+	encodingFactory, ok := encoding.FindEncoding(rc.Encoding)
+	if !ok || encodingFactory == nil {
+		return 0, fmt.Errorf("failed to find '%v' encoding", rc.Encoding)
+	}
+
+	enc, err := encodingFactory(rd)
+	if err != nil {
+		return 0, fmt.Errorf("failed to initialize encoding: %w", err)
+	}
+
+	var msgReader reader.Reader
+	msgReader, err = readfile.NewEncodeReader(io.NopCloser(rd), readfile.Config{
+		Codec:        enc,
+		BufferSize:   int(rc.BufferSize),
+		Terminator:   rc.LineTerminator,
+		CollectOnEOF: true,
+		MaxBytes:     int(rc.MaxBytes) * 4,
+	}, r.log)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create encode reader: %w", err)
+	}
+
+	msgReader = readfile.NewStripNewline(msgReader, rc.LineTerminator)
+	msgReader = rc.Parsers.Create(msgReader, r.log)
+	msgReader = readfile.NewLimitReader(msgReader, int(rc.MaxBytes))
+
+	var n int
+	var offset int64
+	for {
+		message, err := msgReader.Next()
+		// message.Offset counts the bytes of the messages dropped by the
+		// parsers before this one.
+		start := offset + int64(message.Offset)
+		offset = start + int64(message.Bytes)
+		if message.Bytes > 0 {
+			r.metrics.messagesReadTotal.Inc()
+			r.metrics.bytesProcessedTotal.Add(uint64(message.Bytes))
+		}
+
+		if len(message.Content) > 0 && start >= member.Offset {
+			progress := &ackProgress{member: member}
+			progress.member.Offset = offset
+			emit(r.createEvent(progress, message, start), progress)
+			n++
+		}
+
+		if errors.Is(err, io.EOF) {
+			return n, nil
+		}
+		if err != nil {
+			return n, fmt.Errorf("error reading message: %w", err)
+		}
+		if ctx.Err() != nil {
+			return n, ctx.Err()
+		}
+	}
+}
+
+func (r *archiveReader) createEvent(progress *ackProgress, message reader.Message, offset int64) beat.Event {
+	member := progress.member
+	event := beat.Event{
+		Timestamp: time.Now().UTC(),
+		Fields: mapstr.M{
+			"message": string(message.Content),
+			"log": mapstr.M{
+				"offset": offset,
+				"file": mapstr.M{
+					"path": filepath.Join(member.Archive, member.Member),
+				},
+			},
+			"archive": mapstr.M{
+				"path":   member.Archive,
+				"member": member.Member,
+			},
+		},
+		Meta:    message.Meta,
+		Private: progress,
+	}
+	event.Fields.DeepUpdate(message.Fields)
+	return event
+}
+
+func (r *archiveReader) isInflight(id string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	_, ok := r.inflight[id]
+	return ok
+}
+
+// onACK persists the progress of the ACKed events.
+func (r *archiveReader) onACK(privates []interface{}) {
+	for _, private := range privates {
+		p, ok := private.(*ackProgress)
+		if !ok {
+			continue
+		}
+		if err := r.states.Update(p.member); err != nil {
+			r.log.Errorw("Failed to save archive member state.", "path", p.member.Archive, "member", p.member.Member, "error", err)
+		}
+		if p.archive != nil {
+			r.mu.Lock()
+			delete(r.inflight, p.archive.archiveID())
+			r.mu.Unlock()
+			r.complete(*p.archive)
+		}
+	}
+}
+
+// complete stores the final state of an archive whose events are all ACKed
+// and deletes or moves it if configured to.
+func (r *archiveReader) complete(arc state) {
+	if err := r.states.Update(arc); err != nil {
+		r.log.Errorw("Failed to save archive state.", "path", arc.Archive, "error", err)
+	}
+	if !arc.Done {
+		return
+	}
+	r.metrics.archivesCompletedTotal.Inc()
+
+	var err error
+	switch {
+	case r.config.DeleteAfterACK:
+		err = os.Remove(arc.Archive)
+	case r.config.MoveTo != "":
+		err = os.Rename(arc.Archive, filepath.Join(r.config.MoveTo, filepath.Base(arc.Archive)))
+	default:
+		return
+	}
+	if err != nil {
+		r.log.Errorw("Failed to finalize archive.", "path", arc.Archive, "error", err)
+		r.metrics.archiveErrorsTotal.Inc()
+		return
+	}
+
+	// The archive is gone, its states are not needed anymore.
+	if err := r.states.RemoveArchive(arc.archiveID()); err != nil {
+		r.log.Errorw("Failed to remove archive states.", "path", arc.Archive, "error", err)
+	}
+}
@@ -0,0 +1,267 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package archive
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/elastic/beats/v7/libbeat/beat"
+	"github.com/elastic/beats/v7/libbeat/statestore"
+	"github.com/elastic/beats/v7/libbeat/statestore/storetest"
+	conf "github.com/elastic/elastic-agent-libs/config"
+	"github.com/elastic/elastic-agent-libs/logp/logptest"
+	"github.com/elastic/elastic-agent-libs/monitoring"
+)
+
+var _ statestore.States = (*testInputStore)(nil)
+
+type testInputStore struct {
+	registry *statestore.Registry
+}
+
+func openTestStatestore() *testInputStore {
+	return &testInputStore{
+		registry: statestore.NewRegistry(storetest.NewMemoryStoreBackend()),
+	}
+}
+
+func (s *testInputStore) Close() {
+	_ = s.registry.Close()
+}
+
+func (s *testInputStore) StoreFor(string) (*statestore.Store, error) {
+	return s.registry.Get("filebeat")
+}
+
+func (s *testInputStore) CleanupInterval() time.Duration {
+	return 24 * time.Hour
+}
+
+func newTestReader(t *testing.T, store statestore.States, userConfig map[string]interface{}) (*archiveReader, *inputMetrics) {
+	t.Helper()
+
+	c := defaultConfig()
+	require.NoError(t, conf.MustNewConfigFrom(userConfig).Unpack(&c))
+
+	log := logptest.NewTestingLogger(t, "")
+	states, err := newStates(log, store, "test-input")
+	require.NoError(t, err)
+	t.Cleanup(states.Close)
+
+	metrics := newInputMetrics("", monitoring.NewRegistry())
+	return newArchiveReader(c, log, states, metrics), metrics
+}
+
+type eventCollector struct {
+	events []beat.Event
+}
+
+func (c *eventCollector) publish(event beat.Event) {
+	c.events = append(c.events, event)
+}
+
+func (c *eventCollector) messages() []string {
+	var messages []string
+	for _, e := range c.events {
+		messages = append(messages, e.Fields["message"].(string)) //nolint:errcheck // It's a test.
+	}
+	return messages
+}
+
+// ack ACKs the first n collected events and removes them from the collector.
+func (c *eventCollector) ack(r *archiveReader, n int) {
+	privates := make([]interface{}, n)
+	for i, e := range c.events[:n] {
+		privates[i] = e.Private
+	}
+	c.events = c.events[n:]
+	r.onACK(privates)
+}
+
+func TestArchiveReader(t *testing.T) {
+	dir := t.TempDir()
+	path := writeTestArchive(t, dir, "bundle.tar.gz",
+		testMember{"logs/app.log", "app 1\napp 2\n"},
+		testMember{"logs/empty.log", ""},
+		testMember{"logs/db.log", "db 1\n"},
+	)
+
+	store := openTestStatestore()
+	t.Cleanup(store.Close)
+	r, metrics := newTestReader(t, store, map[string]interface{}{
+		"paths": []string{filepath.Join(dir, "*")},
+	})
+
+	var c eventCollector
+	r.scan(context.Background(), c.publish)
+	require.Equal(t, []string{"app 1", "app 2", "db 1"}, c.messages())
+
+	event := c.events[1]
+	offset, _ := event.Fields.GetValue("log.offset")
+	require.Equal(t, int64(6), offset)
+	filePath, _ := event.Fields.GetValue("log.file.path")
+	require.Equal(t, filepath.Join(path, "logs/app.log"), filePath)
+	archivePath, _ := event.Fields.GetValue("archive.path")
+	require.Equal(t, path, archivePath)
+	member, _ := event.Fields.GetValue("archive.member")
+	require.Equal(t, "logs/app.log", member)
+
+	// The archive is not read again while its events are not ACKed.
+	var again eventCollector
+	r.scan(context.Background(), again.publish)
+	require.Empty(t, again.events)
+
+	c.ack(r, len(c.events))
+	require.EqualValues(t, 1, metrics.archivesCompletedTotal.Get())
+	require.EqualValues(t, 3, metrics.eventsPublishedTotal.Get())
+	require.EqualValues(t, 3, metrics.membersReadTotal.Get())
+
+	// Completed archives are not read again, even after a restart.
+	r, _ = newTestReader(t, store, map[string]interface{}{
+		"paths": []string{filepath.Join(dir, "*")},
+	})
+	r.scan(context.Background(), again.publish)
+	require.Empty(t, again.events)
+	require.FileExists(t, path)
+}
+
+func TestArchiveReaderResume(t *testing.T) {
+	dir := t.TempDir()
+	writeTestArchive(t, dir, "bundle.zip",
+		testMember{"a.log", "a 1\na 2\na 3\n"},
+		testMember{"b.log", "b 1\n"},
+	)
+
+	store := openTestStatestore()
+	t.Cleanup(store.Close)
+	userConfig := map[string]interface{}{
+		"paths": []string{filepath.Join(dir, "*.zip")},
+	}
+	r, _ := newTestReader(t, store, userConfig)
+
+	var c eventCollector
+	r.scan(context.Background(), c.publish)
+	require.Equal(t, []string{"a 1", "a 2", "a 3", "b 1"}, c.messages())
+
+	// Only the first two events are ACKed before the restart.
+	c.ack(r, 2)
+
+	r, _ = newTestReader(t, store, userConfig)
+	var resumed eventCollector
+	r.scan(context.Background(), resumed.publish)
+	require.Equal(t, []string{"a 3", "b 1"}, resumed.messages())
+}
+
+func TestArchiveReaderParsers(t *testing.T) {
+	dir := t.TempDir()
+	writeTestArchive(t, dir, "bundle.tar",
+		testMember{"app.log", "debug 1\napp 1\ndebug 2\ndebug 3\napp 2\napp 3\n"},
+	)
+
+	store := openTestStatestore()
+	t.Cleanup(store.Close)
+	userConfig := map[string]interface{}{
+		"paths": []string{filepath.Join(dir, "*")},
+		"parsers": []map[string]interface{}{
+			{"include_message": map[string]interface{}{"patterns": []string{"^app"}}},
+		},
+	}
+	r, _ := newTestReader(t, store, userConfig)
+
+	var c eventCollector
+	r.scan(context.Background(), c.publish)
+	require.Equal(t, []string{"app 1", "app 2", "app 3"}, c.messages())
+
+	// The offsets include the bytes of the dropped messages.
+	var offsets []interface{}
+	for _, e := range c.events {
+		offset, _ := e.Fields.GetValue("log.offset")
+		offsets = append(offsets, offset)
+	}
+	require.Equal(t, []interface{}{int64(8), int64(30), int64(36)}, offsets)
+
+	c.ack(r, 2)
+
+	r, _ = newTestReader(t, store, userConfig)
+	var resumed eventCollector
+	r.scan(context.Background(), resumed.publish)
+	require.Equal(t, []string{"app 3"}, resumed.messages())
+}
+
+func TestArchiveReaderAfterACK(t *testing.T) {
+	testCases := map[string]struct {
+		config func(moveTo string) map[string]interface{}
+		moved  bool
+	}{
+		"delete_after_ack": {
+			config: func(string) map[string]interface{} {
+				return map[string]interface{}{"delete_after_ack": true}
+			},
+		},
+		"move_to": {
+			config: func(moveTo string) map[string]interface{} {
+				return map[string]interface{}{"move_to": moveTo}
+			},
+			moved: true,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			dir, moveTo := t.TempDir(), t.TempDir()
+			path := writeTestArchive(t, dir, "bundle.tar", testMember{"app.log", "app 1\n"})
+
+			store := openTestStatestore()
+			t.Cleanup(store.Close)
+			userConfig := tc.config(moveTo)
+			userConfig["paths"] = []string{filepath.Join(dir, "*")}
+			r, _ := newTestReader(t, store, userConfig)
+
+			var c eventCollector
+			r.scan(context.Background(), c.publish)
+			require.Len(t, c.events, 1)
+			require.FileExists(t, path)
+
+			c.ack(r, 1)
+			require.NoFileExists(t, path)
+			if tc.moved {
+				require.FileExists(t, filepath.Join(moveTo, "bundle.tar"))
+			}
+			require.Empty(t, r.states.states)
+		})
+	}
+}
+
+func TestArchiveReaderFailed(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "broken.zip")
+	require.NoError(t, os.WriteFile(path, []byte("not a zip file"), 0o644))
+
+	store := openTestStatestore()
+	t.Cleanup(store.Close)
+	r, metrics := newTestReader(t, store, map[string]interface{}{
+		"paths":            []string{filepath.Join(dir, "*")},
+		"delete_after_ack": true,
+	})
+
+	var c eventCollector
+	r.scan(context.Background(), c.publish)
+	r.scan(context.Background(), c.publish)
+	require.Empty(t, c.events)
+	require.EqualValues(t, 1, metrics.archiveErrorsTotal.Get())
+	require.FileExists(t, path)
+
+	// A modified archive is read again.
+	writeTestArchive(t, dir, "broken.zip", testMember{"app.log", "app 1\n"})
+	require.NoError(t, os.Chtimes(path, time.Now(), time.Now().Add(time.Minute)))
+	r.scan(context.Background(), c.publish)
+	require.Equal(t, []string{"app 1"}, c.messages())
+}
@@ -0,0 +1,151 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package archive
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/elastic/beats/v7/libbeat/statestore"
+	"github.com/elastic/elastic-agent-libs/logp"
+)
+
+const archiveStatePrefix = "filebeat::archive::state::"
+
+// state is the reading progress of an archive member. The state with an
+// empty Member tracks the archive as a whole.
+type state struct {
+	Archive string    `json:"archive" struct:"archive"`
+	Member  string    `json:"member" struct:"member"`
+	Size    int64     `json:"size" struct:"size"`
+	ModTime time.Time `json:"mod_time" struct:"mod_time"`
+
+	// Offset is the number of bytes of the member whose events are ACKed.
+	Offset int64 `json:"offset" struct:"offset"`
+
+	// Done is true when all events of the member or archive are ACKed.
+	Done bool `json:"done" struct:"done"`
+
+	// Failed is true when the archive could not be read to the end. It is
+	// not read again unless it is modified.
+	Failed bool `json:"failed" struct:"failed"`
+}
+
+// archiveID identifies an archive by its path, size and modification time,
+// so that an archive replaced under the same name is read again.
+func archiveID(path string, size int64, modTime time.Time) string {
+	return path + "::" + strconv.FormatInt(size, 10) + "::" + strconv.FormatInt(modTime.UnixNano(), 10)
+}
+
+func (s *state) archiveID() string {
+	return archiveID(s.Archive, s.Size, s.ModTime)
+}
+
+func (s *state) ID() string {
+	return s.archiveID() + "::" + s.Member
+}
+
+// states holds the states of one input instance and persists them in the
+// registry.
+type states struct {
+	// states are indexed by state ID.
+	// mu must be held to access states and store.
+	mu     sync.Mutex
+	states map[string]state
+	store  *statestore.Store
+
+	// keyPrefix is the prefix of the registry keys owned by the input.
+	keyPrefix string
+}
+
+func newStates(log *logp.Logger, stateStore statestore.States, inputID string) (*states, error) {
+	store, err := stateStore.StoreFor("")
+	if err != nil {
+		return nil, fmt.Errorf("can't access persistent store: %w", err)
+	}
+
+	keyPrefix := archiveStatePrefix + inputID + "::"
+	table := map[string]state{}
+	err = store.Each(func(key string, dec statestore.ValueDecoder) (bool, error) {
+		if !strings.HasPrefix(key, keyPrefix) {
+			return true, nil
+		}
+
+		// try to decode. Ignore faulty/incompatible values.
+		var st state
+		if err := dec.Decode(&st); err != nil {
+			log.Warnf("invalid archive state loading key %v", key)
+			return true, nil
+		}
+		table[st.ID()] = st
+		return true, nil
+	})
+	if err != nil {
+		store.Close()
+		return nil, fmt.Errorf("loading archive input state: %w", err)
+	}
+
+	return &states{
+		states:    table,
+		store:     store,
+		keyPrefix: keyPrefix,
+	}, nil
+}
+
+// Get returns the state with the given ID.
+func (s *states) Get(id string) (state, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	st, ok := s.states[id]
+	return st, ok
+}
+
+// Update stores st in memory and in the registry.
+func (s *states) Update(st state) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	id := st.ID()
+	s.states[id] = st
+	return s.store.Set(s.keyPrefix+id, st)
+}
+
+// RemoveArchive removes the states of the archive and of all its members.
+func (s *states) RemoveArchive(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.removeLocked(func(st *state) bool { return st.archiveID() == id })
+}
+
+// CleanUp removes the states of all archives whose ID is not in known.
+func (s *states) CleanUp(known map[string]struct{}) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.removeLocked(func(st *state) bool {
+		_, ok := known[st.archiveID()]
+		return !ok
+	})
+}
+
+func (s *states) removeLocked(remove func(*state) bool) error {
+	for id, st := range s.states {
+		if !remove(&st) {
+			continue
+		}
+		delete(s.states, id)
+		if err := s.store.Remove(s.keyPrefix + id); err != nil {
+			return fmt.Errorf("error while removing the state for ID %s: %w", id, err)
+		}
+	}
+	return nil
+}
+
+func (s *states) Close() {
+	s.mu.Lock()
+	s.store.Close()
+	s.mu.Unlock()
+}
@@ -45,6 +45,7 @@ type config struct {
 	Program               string                  `config:"program"`
 	SecretHeader          string                  `config:"secret.header"`
 	SecretValue           string                  `config:"secret.value"`
+	SecretField           string                  `config:"secret.field"`
 	HMACHeader            string                  `config:"hmac.header"`
 	HMACKey               string                  `config:"hmac.key"`
 	HMACType              string                  `config:"hmac.type"`
@@ -98,10 +99,18 @@ func (c *config) Validate() error {
 		}
 	}
 
-	if (c.SecretHeader != "" && c.SecretValue == "") || (c.SecretHeader == "" && c.SecretValue != "") {
+	if (c.SecretHeader != "" && c.SecretValue == "") || (c.SecretHeader == "" && c.SecretField == "" && c.SecretValue != "") {
 		return errors.New("both secret.header and secret.value must be set")
 	}
 
+	if c.SecretField != "" && c.SecretValue == "" {
+		return errors.New("both secret.field and secret.value must be set")
+	}
+
+	if c.SecretHeader != "" && c.SecretField != "" {
+		return errors.New("secret.header and secret.field cannot both be set")
+	}
+
 	if (c.HMACHeader != "" && c.HMACKey == "") || (c.HMACHeader == "" && c.HMACKey != "") {
 		return errors.New("both hmac.header and hmac.key must be set")
 	}
@@ -46,6 +46,28 @@ func Test_validateConfig(t *testing.T) {
 			},
 			wantError: "response_body must be valid JSON",
 		},
+		{
+			name: "secret field without value",
+			config: config{
+				URL:          "/",
+				ResponseBody: `{"message": "success"}`,
+				Method:       http.MethodPost,
+				SecretField:  "sharedSecret",
+			},
+			wantError: "both secret.field and secret.value must be set",
+		},
+		{
+			name: "secret header and field",
+			config: config{
+				URL:          "/",
+				ResponseBody: `{"message": "success"}`,
+				Method:       http.MethodPost,
+				SecretHeader: "X-Secret",
+				SecretField:  "sharedSecret",
+				SecretValue:  "secret",
+			},
+			wantError: "secret.header and secret.field cannot both be set",
+		},
 	}
 
 	for _, tc := range testCases {
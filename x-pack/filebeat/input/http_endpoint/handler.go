@@ -177,10 +177,18 @@ func (h *handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		r.Body = io.NopCloser(&buf)
 	}
 
-	objs, code, err := httpReadJSON(body, h.program)
+	var validate func(interface{}) error
+	if h.validator.hasBodySecret() {
+		validate = h.validator.validateBody
+	}
+	objs, code, err := httpReadJSON(body, validate, h.program)
 	if err != nil {
 		h.sendAPIErrorResponse(txID, w, r, h.log, code, err)
-		h.status.UpdateStatus(status.Degraded, "unable to read message JSON: "+err.Error())
+		if errors.Is(err, errIncorrectBodySecret) {
+			h.status.UpdateStatus(status.Degraded, "request did not validate: "+err.Error())
+		} else {
+			h.status.UpdateStatus(status.Degraded, "unable to read message JSON: "+err.Error())
+		}
 		h.metrics.apiErrors.Add(1)
 		return
 	}
@@ -396,18 +404,23 @@ func (h *handler) publishEvent(obj, headers mapstr.M, acker *batchACKTracker) er
 	return nil
 }
 
-func httpReadJSON(body io.Reader, prg *program) (objs []mapstr.M, status int, err error) {
+// httpReadJSON decodes the JSON objects of the request body. When validate is
+// not nil, it is called with each decoded JSON value before prg is run.
+func httpReadJSON(body io.Reader, validate func(interface{}) error, prg *program) (objs []mapstr.M, status int, err error) {
 	if body == http.NoBody {
 		return nil, http.StatusNotAcceptable, errBodyEmpty
 	}
-	obj, err := decodeJSON(body, prg)
+	obj, err := decodeJSON(body, validate, prg)
 	if err != nil {
+		if errors.Is(err, errIncorrectBodySecret) {
+			return nil, http.StatusUnauthorized, err
+		}
 		return nil, http.StatusBadRequest, err
 	}
 	return obj, http.StatusOK, err
 }
 
-func decodeJSON(body io.Reader, prg *program) (objs []mapstr.M, err error) {
+func decodeJSON(body io.Reader, validate func(interface{}) error, prg *program) (objs []mapstr.M, err error) {
 	decoder := json.NewDecoder(body)
 	for decoder.More() {
 		var raw json.RawMessage
@@ -423,18 +436,24 @@ func decodeJSON(body io.Reader, prg *program) (objs []mapstr.M, err error) {
 			return nil, fmt.Errorf("malformed JSON object at stream position %d: %w", decoder.InputOffset(), err)
 		}
 
+		if validate != nil {
+			if err = validate(obj); err != nil {
+				return nil, err
+			}
+		}
 		if prg != nil {
 			obj, err = prg.eval(obj)
 			if err != nil {
 				return nil, err
 			}
-			if _, ok := obj.([]interface{}); ok {
-				// Re-marshal to ensure the raw bytes agree with the constructed object.
-				// This is only necessary when the program constructs an array return.
-				raw, err = json.Marshal(obj)
-				if err != nil {
-					return nil, fmt.Errorf("failed to remarshal object: %w", err)
-				}
+		}
+		if _, ok := obj.([]interface{}); ok && (validate != nil || prg != nil) {
+			// Re-marshal to ensure the raw bytes agree with the constructed object.
+			// This is only necessary when the program constructs an array return,
+			// or when the secret was removed from the objects of an array.
+			raw, err = json.Marshal(obj)
+			if err != nil {
+				return nil, fmt.Errorf("failed to remarshal object: %w", err)
 			}
 		}
 
@@ -170,7 +170,7 @@ func Test_httpReadJSON(t *testing.T) {
 			if err != nil {
 				t.Fatalf("failed to compile program: %v", err)
 			}
-			gotObjs, gotStatus, err := httpReadJSON(strings.NewReader(tt.body), prg)
+			gotObjs, gotStatus, err := httpReadJSON(strings.NewReader(tt.body), nil, prg)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("httpReadJSON() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -381,6 +381,106 @@ func Test_apiResponse(t *testing.T) {
 			wantStatus:   http.StatusOK,
 			wantResponse: `{"message": "success"}`,
 		},
+		{
+			name: "body_secret",
+			conf: func() config {
+				c := defaultConfig()
+				c.SecretField = "sharedSecret"
+				c.SecretValue = "mysecret"
+				return c
+			}(),
+			request: func() *http.Request {
+				req := httptest.NewRequest(http.MethodPost, "/", bytes.NewBufferString(`{"id":0,"sharedSecret":"mysecret"}`))
+				req.Header.Set("Content-Type", "application/json")
+				return req
+			}(),
+			events: []mapstr.M{
+				{
+					"json": mapstr.M{
+						"id": int64(0),
+					},
+				},
+			},
+			wantStatus:   http.StatusOK,
+			wantResponse: `{"message": "success"}`,
+		},
+		{
+			name: "body_secret_incorrect",
+			conf: func() config {
+				c := defaultConfig()
+				c.SecretField = "sharedSecret"
+				c.SecretValue = "mysecret"
+				return c
+			}(),
+			request: func() *http.Request {
+				req := httptest.NewRequest(http.MethodPost, "/", bytes.NewBufferString(`[{"id":0,"sharedSecret":"mysecret"},{"id":1,"sharedSecret":"wrong"}]`))
+				req.Header.Set("Content-Type", "application/json")
+				return req
+			}(),
+			wantStatus:   http.StatusUnauthorized,
+			wantResponse: `{"message":"incorrect body secret"}`,
+		},
+		{
+			name: "body_secret_missing",
+			conf: func() config {
+				c := defaultConfig()
+				c.SecretField = "sharedSecret"
+				c.SecretValue = "mysecret"
+				return c
+			}(),
+			request: func() *http.Request {
+				req := httptest.NewRequest(http.MethodPost, "/", bytes.NewBufferString(`{"id":0}`))
+				req.Header.Set("Content-Type", "application/json")
+				return req
+			}(),
+			wantStatus:   http.StatusUnauthorized,
+			wantResponse: `{"message":"incorrect body secret"}`,
+		},
+		{
+			name: "body_secret_with_program",
+			conf: func() config {
+				c := defaultConfig()
+				c.SecretField = "sharedSecret"
+				c.SecretValue = "mysecret"
+				// The secret is removed before the program runs.
+				c.Program = `{"id": obj.id, "had_secret": has(obj.sharedSecret)}`
+				return c
+			}(),
+			request: func() *http.Request {
+				req := httptest.NewRequest(http.MethodPost, "/", bytes.NewBufferString(`{"id":0,"sharedSecret":"mysecret"}`))
+				req.Header.Set("Content-Type", "application/json")
+				return req
+			}(),
+			events: []mapstr.M{
+				{
+					"json": mapstr.M{
+						"id":         float64(0),
+						"had_secret": false,
+					},
+				},
+			},
+			wantStatus:   http.StatusOK,
+			wantResponse: `{"message": "success"}`,
+		},
+		{
+			name: "body_secret_added_by_program",
+			conf: func() config {
+				c := defaultConfig()
+				c.SecretField = "sharedSecret"
+				c.SecretValue = "mysecret"
+				// The secret is checked against the body as sent, not the
+				// output of the program.
+				c.Program = `{"id": obj.id, "sharedSecret": "mysecret"}`
+				return c
+			}(),
+			request: func() *http.Request {
+				req := httptest.NewRequest(http.MethodPost, "/", bytes.NewBufferString(`{"id":0}`))
+				req.Header.Set("Content-Type", "application/json")
+				return req
+			}(),
+			wantStatus:   http.StatusUnauthorized,
+			wantResponse: `{"message":"incorrect body secret"}`,
+		},
 		{
 			name: "hmac_header_not_present",
 			conf: func() config {
@@ -598,7 +698,10 @@ func Test_apiResponse(t *testing.T) {
 			pub := new(publisher)
 			metrics := newInputMetrics("")
 			defer metrics.Close()
-			apiHandler := newHandler(ctx, newTracerConfig(tc.name, tc.conf, *withTraces), nil, pub.Publish, nil, logp.NewLogger("http_endpoint.test"), metrics)
+			log := logp.NewLogger("http_endpoint.test")
+			prg, err := newProgram(tc.conf.Program, log)
+			require.NoError(t, err)
+			apiHandler := newHandler(ctx, newTracerConfig(tc.name, tc.conf, *withTraces), prg, pub.Publish, nil, log, metrics)
 
 			// Execute handler.
 			respRec := httptest.NewRecorder()
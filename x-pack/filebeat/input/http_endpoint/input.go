@@ -362,6 +362,7 @@ func newHandler(ctx context.Context, c config, prg *program, pub func(beat.Event
 			contentType:    c.ContentType,
 			secretHeader:   c.SecretHeader,
 			secretValue:    c.SecretValue,
+			secretField:    c.SecretField,
 			hmacHeader:     c.HMACHeader,
 			hmacKey:        c.HMACKey,
 			hmacType:       c.HMACType,
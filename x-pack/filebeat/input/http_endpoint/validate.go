@@ -17,11 +17,14 @@ import (
 	"io"
 	"net/http"
 	"strings"
+
+	"github.com/elastic/elastic-agent-libs/mapstr"
 )
 
 var (
 	errIncorrectUserOrPass    = errors.New("incorrect username or password")
 	errIncorrectHeaderSecret  = errors.New("incorrect header or header secret")
+	errIncorrectBodySecret    = errors.New("incorrect body secret")
 	errMissingHMACHeader      = errors.New("missing HMAC header")
 	errIncorrectHMACSignature = errors.New("invalid HMAC signature")
 )
@@ -33,6 +36,7 @@ type apiValidator struct {
 	contentType        string
 	secretHeader       string
 	secretValue        string
+	secretField        string
 	hmacHeader         string
 	hmacKey            string
 	hmacType           string
@@ -115,6 +119,49 @@ func (v *apiValidator) validateRequest(r *http.Request) (status int, err error)
 	return http.StatusAccepted, nil
 }
 
+// hasBodySecret returns whether the request body must hold a secret.
+func (v *apiValidator) hasBodySecret() bool {
+	return v.secretField != "" && v.secretValue != ""
+}
+
+// validateBody checks that the secret.field of the JSON object, or of each
+// object of the JSON array, decoded from the request body holds the
+// secret.value. It runs on the body as sent, before the program, and removes
+// the secret so that it is neither seen by the program nor published with the
+// events.
+func (v *apiValidator) validateBody(obj interface{}) error {
+	switch obj := obj.(type) {
+	case map[string]interface{}:
+		return v.validateBodyObject(obj)
+	case []interface{}:
+		for _, elem := range obj {
+			m, ok := elem.(map[string]interface{})
+			if !ok {
+				return errIncorrectBodySecret
+			}
+			if err := v.validateBodyObject(m); err != nil {
+				return err
+			}
+		}
+		return nil
+	default:
+		return errIncorrectBodySecret
+	}
+}
+
+func (v *apiValidator) validateBodyObject(obj mapstr.M) error {
+	secret, err := obj.GetValue(v.secretField)
+	if err != nil {
+		return errIncorrectBodySecret
+	}
+	s, ok := secret.(string)
+	if !ok || !hmac.Equal([]byte(s), []byte(v.secretValue)) {
+		return errIncorrectBodySecret
+	}
+	_ = obj.Delete(v.secretField)
+	return nil
+}
+
 func (v *apiValidator) isMethodOK(m string) bool {
 	if m == http.MethodOptions {
 		return v.optionsHeaders != nil
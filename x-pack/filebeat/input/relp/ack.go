@@ -0,0 +1,42 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package relp
+
+import (
+	"time"
+
+	"github.com/elastic/beats/v7/libbeat/beat"
+	"github.com/elastic/beats/v7/libbeat/common/acker"
+)
+
+// txnACK is stored in the private field of each published event. It ties the
+// event back to the RELP transaction that must be answered once the event
+// has been acknowledged by an output.
+type txnACK struct {
+	session *session
+	txnr    int
+	start   time.Time // Time of receipt of the transaction.
+}
+
+// ACK sends the positive response for the transaction.
+func (a *txnACK) ACK() {
+	a.session.ack(a.txnr, a.start)
+}
+
+// newEventACKHandler returns a beat ACKer that can receive callbacks when
+// an event has been ACKed by an output. If the event contains a private metadata
+// pointing to a txnACK then its ACK() method is invoked to send the RELP
+// response for the transaction.
+func newEventACKHandler() beat.EventListener {
+	return acker.ConnectionOnly(
+		acker.EventPrivateReporter(func(_ int, privates []interface{}) {
+			for _, private := range privates {
+				if ack, ok := private.(*txnACK); ok {
+					ack.ACK()
+				}
+			}
+		}),
+	)
+}
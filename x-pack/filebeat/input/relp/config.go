@@ -0,0 +1,30 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package relp
+
+import (
+	"time"
+
+	"github.com/dustin/go-humanize"
+
+	"github.com/elastic/beats/v7/libbeat/common/cfgtype"
+	"github.com/elastic/elastic-agent-libs/transport/tlscommon"
+)
+
+type config struct {
+	ListenAddress  string                  `config:"listen_address" validate:"nonzero"`            // Bind address for the server (e.g. address:port). Default to localhost:2514.
+	TLS            *tlscommon.ServerConfig `config:"ssl"`                                          // TLS options.
+	Timeout        time.Duration           `config:"timeout"          validate:"min=0"`            // Idle read timeout for RELP sessions. Zero disables the timeout.
+	MaxConnections int                     `config:"max_connections"  validate:"min=0"`            // Maximum number of concurrent connections. Default is 0 which means no limit.
+	WindowSize     int                     `config:"window_size"      validate:"min=1"`            // Maximum number of unacknowledged transactions per session.
+	MaxMessageSize cfgtype.ByteSize        `config:"max_message_size" validate:"nonzero,positive"` // Maximum size of the DATA part of a RELP frame.
+}
+
+func (c *config) InitDefaults() {
+	c.ListenAddress = "localhost:2514"
+	c.Timeout = 5 * time.Minute
+	c.WindowSize = 128
+	c.MaxMessageSize = 128 * humanize.KiByte
+}
@@ -0,0 +1,90 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package relp
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	conf "github.com/elastic/elastic-agent-libs/config"
+)
+
+func TestConfig(t *testing.T) {
+	testCases := []struct {
+		name        string
+		userConfig  map[string]interface{}
+		expected    *config
+		expectedErr string
+	}{
+		{
+			"defaults",
+			map[string]interface{}{},
+			&config{
+				ListenAddress:  "localhost:2514",
+				Timeout:        5 * time.Minute,
+				WindowSize:     128,
+				MaxMessageSize: 128 * 1024,
+			},
+			"",
+		},
+		{
+			"max_message_size",
+			map[string]interface{}{
+				"max_message_size": "1MiB",
+			},
+			&config{
+				ListenAddress:  "localhost:2514",
+				Timeout:        5 * time.Minute,
+				WindowSize:     128,
+				MaxMessageSize: 1024 * 1024,
+			},
+			"",
+		},
+		{
+			"validate window_size",
+			map[string]interface{}{
+				"window_size": 0,
+			},
+			nil,
+			`requires value >= 1 accessing 'window_size'`,
+		},
+		{
+			"validate timeout",
+			map[string]interface{}{
+				"timeout": "-1s",
+			},
+			nil,
+			`requires duration >= 0`,
+		},
+		{
+			"validate max_connections",
+			map[string]interface{}{
+				"max_connections": -1,
+			},
+			nil,
+			`requires value >= 0 accessing 'max_connections'`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			c := conf.MustNewConfigFrom(tc.userConfig)
+
+			var relpConf config
+			err := c.Unpack(&relpConf)
+
+			if tc.expectedErr != "" {
+				require.Error(t, err, "expected error: %s", tc.expectedErr)
+				require.Contains(t, err.Error(), tc.expectedErr)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, *tc.expected, relpConf)
+		})
+	}
+}
@@ -0,0 +1,49 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package relp
+
+import (
+	"github.com/rcrowley/go-metrics"
+
+	"github.com/elastic/beats/v7/libbeat/monitoring/inputmon"
+	"github.com/elastic/elastic-agent-libs/monitoring"
+	"github.com/elastic/elastic-agent-libs/monitoring/adapter"
+)
+
+type inputMetrics struct {
+	unregister func()
+
+	bindAddress           *monitoring.String // Bind address of input.
+	sessionsActive        *monitoring.Int    // Number of open RELP sessions.
+	sessionsTotal         *monitoring.Uint   // Number of RELP sessions opened.
+	messagesReceivedTotal *monitoring.Uint   // Number of syslog messages received (not necessarily processed fully).
+	messagesACKedTotal    *monitoring.Uint   // Number of syslog messages ACKed to the sender.
+	errorsTotal           *monitoring.Uint   // Number of sessions terminated because of a protocol or I/O error.
+	messageProcessingTime metrics.Sample     // Histogram of the elapsed message processing times in nanoseconds (time of receipt to time of ACK).
+}
+
+// Close removes the metrics from the registry.
+func (m *inputMetrics) Close() {
+	m.unregister()
+}
+
+func newInputMetrics(id string, optionalParent *monitoring.Registry) *inputMetrics {
+	reg, unreg := inputmon.NewInputRegistry(inputName, id, optionalParent)
+
+	out := &inputMetrics{
+		unregister:            unreg,
+		bindAddress:           monitoring.NewString(reg, "bind_address"),
+		sessionsActive:        monitoring.NewInt(reg, "sessions_active"),
+		sessionsTotal:         monitoring.NewUint(reg, "sessions_total"),
+		messagesReceivedTotal: monitoring.NewUint(reg, "messages_received_total"),
+		messagesACKedTotal:    monitoring.NewUint(reg, "messages_acked_total"),
+		errorsTotal:           monitoring.NewUint(reg, "errors_total"),
+		messageProcessingTime: metrics.NewUniformSample(1024),
+	}
+	adapter.NewGoMetrics(reg, "message_processing_time", adapter.Accept).
+		Register("histogram", metrics.NewHistogram(out.messageProcessingTime)) //nolint:errcheck // A unique namespace is used so name collisions are impossible.
+
+	return out
+}
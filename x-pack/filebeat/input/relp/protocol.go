@@ -0,0 +1,215 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package relp
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// RELP commands. See https://www.rsyslog.com/doc/relp.html.
+const (
+	cmdOpen        = "open"
+	cmdClose       = "close"
+	cmdSyslog      = "syslog"
+	cmdRsp         = "rsp"
+	cmdServerClose = "serverclose"
+)
+
+const (
+	relpVersion  = "0"
+	relpSoftware = "beats-relp"
+
+	maxTxnr       = 999_999_999 // TXNR is at most 9 digits.
+	maxDigits     = 9           // Maximum number of digits of TXNR and DATALEN.
+	maxCommandLen = 32          // Maximum length of a command name.
+	trailer       = '\n'
+)
+
+var errFrameSyntax = errors.New("relp frame syntax error")
+
+// frame is a single RELP frame:
+//
+//	TXNR SP COMMAND SP DATALEN [SP DATA] TRAILER
+type frame struct {
+	txnr    int
+	command string
+	data    []byte
+}
+
+// readFrame reads a single RELP frame from r. DATA larger than maxDataLen
+// is rejected with an error.
+func readFrame(r *bufio.Reader, maxDataLen int) (frame, error) {
+	var f frame
+
+	txnr, err := readNumber(r, ' ')
+	if err != nil {
+		return f, err
+	}
+	if txnr > maxTxnr {
+		return f, fmt.Errorf("%w: invalid txnr %d", errFrameSyntax, txnr)
+	}
+	f.txnr = txnr
+
+	command, err := readToken(r, ' ', maxCommandLen)
+	if err != nil {
+		return f, err
+	}
+	f.command = command
+
+	// DATALEN is followed by SP when DATA is present and by the trailer
+	// otherwise.
+	dataLen, err := readNumber(r, ' ', trailer)
+	if err != nil {
+		return f, err
+	}
+	if dataLen == 0 {
+		if err := consumeTrailer(r, dataLen); err != nil {
+			return f, err
+		}
+		return f, nil
+	}
+	if dataLen > maxDataLen {
+		return f, fmt.Errorf("relp frame data length %d exceeds max_message_size %d", dataLen, maxDataLen)
+	}
+
+	f.data = make([]byte, dataLen)
+	if _, err := io.ReadFull(r, f.data); err != nil {
+		return f, unexpectedEOF(err)
+	}
+	if err := consumeTrailer(r, dataLen); err != nil {
+		return f, err
+	}
+	return f, nil
+}
+
+// readNumber reads an unsigned decimal number of at most maxDigits digits
+// terminated by one of the given delimiters. The delimiter is consumed unless
+// it is the trailer, which is left for consumeTrailer.
+func readNumber(r *bufio.Reader, delims ...byte) (int, error) {
+	var n, digits int
+	for {
+		b, err := r.ReadByte()
+		if err != nil {
+			if digits > 0 && errors.Is(err, io.EOF) && isDelim(trailer, delims) {
+				// Let consumeTrailer decide whether a missing trailer is valid.
+				return n, nil
+			}
+			if digits > 0 {
+				err = unexpectedEOF(err)
+			}
+			return 0, err
+		}
+		switch {
+		case b >= '0' && b <= '9':
+			digits++
+			if digits > maxDigits {
+				return 0, fmt.Errorf("%w: number too long", errFrameSyntax)
+			}
+			n = n*10 + int(b-'0')
+		case digits > 0 && isDelim(b, delims):
+			if b == trailer {
+				return n, r.UnreadByte()
+			}
+			return n, nil
+		default:
+			return 0, fmt.Errorf("%w: unexpected character %q in number", errFrameSyntax, b)
+		}
+	}
+}
+
+// readToken reads a non-empty token of at most maxLen bytes terminated by
+// delim. The delimiter is consumed.
+func readToken(r *bufio.Reader, delim byte, maxLen int) (string, error) {
+	buf := make([]byte, 0, maxLen)
+	for {
+		b, err := r.ReadByte()
+		if err != nil {
+			return "", unexpectedEOF(err)
+		}
+		if b == delim {
+			if len(buf) == 0 {
+				return "", fmt.Errorf("%w: empty command", errFrameSyntax)
+			}
+			return string(buf), nil
+		}
+		if len(buf) == maxLen {
+			return "", fmt.Errorf("%w: command too long", errFrameSyntax)
+		}
+		buf = append(buf, b)
+	}
+}
+
+// consumeTrailer reads the frame trailer. librelp tolerates a missing
+// trailer when DATALEN is zero, so that case is accepted too.
+func consumeTrailer(r *bufio.Reader, dataLen int) error {
+	b, err := r.ReadByte()
+	if err != nil {
+		if dataLen == 0 && errors.Is(err, io.EOF) {
+			return nil
+		}
+		return unexpectedEOF(err)
+	}
+	if b != trailer {
+		return fmt.Errorf("%w: missing frame trailer", errFrameSyntax)
+	}
+	return nil
+}
+
+func isDelim(b byte, delims []byte) bool {
+	for _, d := range delims {
+		if b == d {
+			return true
+		}
+	}
+	return false
+}
+
+func unexpectedEOF(err error) error {
+	if errors.Is(err, io.EOF) {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
+
+// appendFrame appends the wire encoding of a RELP frame to buf.
+func appendFrame(buf []byte, txnr int, command string, data []byte) []byte {
+	buf = strconv.AppendInt(buf, int64(txnr), 10)
+	buf = append(buf, ' ')
+	buf = append(buf, command...)
+	buf = append(buf, ' ')
+	buf = strconv.AppendInt(buf, int64(len(data)), 10)
+	if len(data) > 0 {
+		buf = append(buf, ' ')
+		buf = append(buf, data...)
+	}
+	return append(buf, trailer)
+}
+
+// openResponse returns the DATA of the rsp frame sent for an open command.
+func openResponse() []byte {
+	return []byte("200 OK\n" +
+		"relp_version=" + relpVersion + "\n" +
+		"relp_software=" + relpSoftware + "\n" +
+		"commands=" + cmdSyslog)
+}
+
+// parseOffers parses the offers sent by a client in an open command. Each
+// offer is on its own line as name[=value].
+func parseOffers(data []byte) map[string]string {
+	offers := map[string]string{}
+	for _, line := range bytes.Split(data, []byte{'\n'}) {
+		if len(line) == 0 {
+			continue
+		}
+		name, value, _ := bytes.Cut(line, []byte{'='})
+		offers[string(name)] = string(value)
+	}
+	return offers
+}
@@ -0,0 +1,125 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package relp
+
+import (
+	"bufio"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadFrame(t *testing.T) {
+	testCases := []struct {
+		name        string
+		input       string
+		expected    []frame
+		expectedErr string
+	}{
+		{
+			name:  "open",
+			input: "1 open 86 relp_version=0\nrelp_software=librelp,1.2.13,http://librelp.adiscon.com\ncommands=syslog\n",
+			expected: []frame{{
+				txnr:    1,
+				command: "open",
+				data:    []byte("relp_version=0\nrelp_software=librelp,1.2.13,http://librelp.adiscon.com\ncommands=syslog"),
+			}},
+		},
+		{
+			name:  "syslog sequence",
+			input: "2 syslog 11 <13>hello 1\n3 syslog 11 <13>hello 2\n",
+			expected: []frame{
+				{txnr: 2, command: "syslog", data: []byte("<13>hello 1")},
+				{txnr: 3, command: "syslog", data: []byte("<13>hello 2")},
+			},
+		},
+		{
+			name:  "data containing trailer",
+			input: "2 syslog 9 line\nline\n",
+			expected: []frame{
+				{txnr: 2, command: "syslog", data: []byte("line\nline")},
+			},
+		},
+		{
+			name:     "close without data",
+			input:    "4 close 0\n",
+			expected: []frame{{txnr: 4, command: "close"}},
+		},
+		{
+			name:     "close without trailer",
+			input:    "4 close 0",
+			expected: []frame{{txnr: 4, command: "close"}},
+		},
+		{
+			name:        "missing trailer",
+			input:       "2 syslog 5 hello!",
+			expectedErr: "missing frame trailer",
+		},
+		{
+			name:        "truncated data",
+			input:       "2 syslog 10 hello",
+			expectedErr: io.ErrUnexpectedEOF.Error(),
+		},
+		{
+			name:        "invalid txnr",
+			input:       "x syslog 5 hello\n",
+			expectedErr: "unexpected character",
+		},
+		{
+			name:        "txnr too long",
+			input:       "1234567890 syslog 5 hello\n",
+			expectedErr: "number too long",
+		},
+		{
+			name:        "empty command",
+			input:       "1  5 hello\n",
+			expectedErr: "empty command",
+		},
+		{
+			name:        "message too large",
+			input:       "2 syslog 2048 hello\n",
+			expectedErr: "exceeds max_message_size",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := bufio.NewReader(strings.NewReader(tc.input))
+
+			var frames []frame
+			for {
+				f, err := readFrame(r, 1024)
+				if err == io.EOF {
+					break
+				}
+				if tc.expectedErr != "" {
+					require.ErrorContains(t, err, tc.expectedErr)
+					return
+				}
+				require.NoError(t, err)
+				frames = append(frames, f)
+			}
+			assert.Equal(t, tc.expected, frames)
+		})
+	}
+}
+
+func TestAppendFrame(t *testing.T) {
+	assert.Equal(t, "2 rsp 6 200 OK\n", string(appendFrame(nil, 2, cmdRsp, []byte("200 OK"))))
+	assert.Equal(t, "3 rsp 0\n", string(appendFrame(nil, 3, cmdRsp, nil)))
+	assert.Equal(t, "0 serverclose 0\n", string(appendFrame(nil, 0, cmdServerClose, nil)))
+}
+
+func TestParseOffers(t *testing.T) {
+	offers := parseOffers([]byte("relp_version=0\nrelp_software=librelp,1.2.13,http://librelp.adiscon.com\ncommands=syslog\n"))
+	assert.Equal(t, map[string]string{
+		"relp_version":  "0",
+		"relp_software": "librelp,1.2.13,http://librelp.adiscon.com",
+		"commands":      "syslog",
+	}, offers)
+}
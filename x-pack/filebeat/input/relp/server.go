@@ -0,0 +1,178 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package relp
+
+import (
+	"crypto/tls"
+	"errors"
+	"net"
+	"sync"
+
+	"golang.org/x/net/netutil"
+
+	"github.com/elastic/beats/v7/libbeat/beat"
+	"github.com/elastic/beats/v7/libbeat/management/status"
+	"github.com/elastic/elastic-agent-libs/logp"
+	"github.com/elastic/elastic-agent-libs/monitoring"
+	"github.com/elastic/elastic-agent-libs/transport/tlscommon"
+)
+
+type server struct {
+	config      config
+	status      status.StatusReporter
+	log         *logp.Logger
+	publish     func(beat.Event)
+	metrics     *inputMetrics
+	listener    net.Listener
+	bindAddress string
+
+	mutex    sync.Mutex // mutex synchronizes access to sessions and closed.
+	sessions map[*session]struct{}
+	closed   bool
+	wg       sync.WaitGroup // wg tracks running sessions.
+
+	closeOnce sync.Once
+	closeErr  error
+}
+
+func newServer(c config, log *logp.Logger, pub func(beat.Event), stat status.StatusReporter, metrics *inputMetrics) (*server, error) {
+	if stat == nil {
+		stat = noopReporter{}
+	}
+	l, err := newListener(c)
+	if err != nil {
+		stat.UpdateStatus(status.Failed, "failed to start relp server: "+err.Error())
+		return nil, err
+	}
+
+	if metrics == nil {
+		metrics = newInputMetrics("", monitoring.NewRegistry())
+	}
+
+	bindAddress := l.Addr().String()
+	bindURI := "tcp://" + bindAddress
+	if c.TLS.IsEnabled() {
+		bindURI = "tls://" + bindAddress
+	}
+	log.Infof(inputName+" is listening at %v.", bindURI)
+	metrics.bindAddress.Set(bindURI)
+
+	return &server{
+		config:      c,
+		status:      stat,
+		log:         log,
+		publish:     pub,
+		metrics:     metrics,
+		listener:    l,
+		bindAddress: bindAddress,
+		sessions:    map[*session]struct{}{},
+	}, nil
+}
+
+type noopReporter struct{}
+
+func (noopReporter) UpdateStatus(status.Status, string) {}
+
+// Close stops accepting connections and terminates all sessions. Clients are
+// notified with a serverclose command so that they retransmit any transaction
+// that has not been answered yet.
+func (s *server) Close() error {
+	s.closeOnce.Do(func() {
+		s.status.UpdateStatus(status.Stopping, "")
+		s.closeErr = s.listener.Close()
+
+		s.mutex.Lock()
+		s.closed = true
+		for sess := range s.sessions {
+			sess.shutdown()
+		}
+		s.mutex.Unlock()
+
+		s.wg.Wait()
+		s.status.UpdateStatus(status.Stopped, "")
+	})
+	return s.closeErr
+}
+
+// Run accepts connections until the server is closed.
+func (s *server) Run() error {
+	s.status.UpdateStatus(status.Running, "")
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return nil
+			}
+			var netErr net.Error
+			if errors.As(err, &netErr) && netErr.Timeout() {
+				s.log.Warnw("Temporary error accepting connection.", "error", err)
+				continue
+			}
+			s.status.UpdateStatus(status.Failed, "failed to accept connection: "+err.Error())
+			return err
+		}
+
+		sess := newSession(conn, s.config, s.log, s.publish, s.metrics)
+		if !s.track(sess) {
+			conn.Close()
+			return nil
+		}
+		go func() {
+			defer s.untrack(sess)
+			sess.run()
+		}()
+	}
+}
+
+// track registers the session with the server. It returns false if the server
+// has been closed.
+func (s *server) track(sess *session) bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.closed {
+		return false
+	}
+	s.sessions[sess] = struct{}{}
+	s.wg.Add(1)
+	return true
+}
+
+func (s *server) untrack(sess *session) {
+	s.mutex.Lock()
+	delete(s.sessions, sess)
+	s.mutex.Unlock()
+	s.wg.Done()
+}
+
+func newListener(c config) (net.Listener, error) {
+	// Setup optional TLS.
+	var tlsConfig *tls.Config
+	if c.TLS.IsEnabled() {
+		elasticTLSConfig, err := tlscommon.LoadTLSServerConfig(c.TLS)
+		if err != nil {
+			return nil, err
+		}
+
+		// NOTE: Passing an empty string disables checking the client certificate for a
+		// specific hostname.
+		tlsConfig = elasticTLSConfig.BuildServerConfig("")
+	}
+
+	// Start listener.
+	l, err := net.Listen("tcp", c.ListenAddress)
+	if err != nil {
+		return nil, err
+	}
+	// The connection limit is applied before TLS so that the accepted
+	// connections still expose their TLS connection state.
+	if c.MaxConnections > 0 {
+		l = netutil.LimitListener(l, c.MaxConnections)
+	}
+	if tlsConfig != nil {
+		l = tls.NewListener(l, tlsConfig)
+	}
+	return l, nil
+}
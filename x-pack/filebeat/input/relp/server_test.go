@@ -0,0 +1,342 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package relp
+
+import (
+	"bufio"
+	"crypto/rsa"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/elastic/beats/v7/libbeat/beat"
+	"github.com/elastic/elastic-agent-libs/logp/logptest"
+	"github.com/elastic/elastic-agent-libs/transport/tlscommon"
+	"github.com/elastic/elastic-agent-libs/transport/tlscommontest"
+)
+
+const testTimeout = 10 * time.Second
+
+func TestServer(t *testing.T) {
+	t.Run("responses after ack", func(t *testing.T) {
+		s, collect := startTestServer(t, testConfig())
+		client := dialTestClient(t, s.bindAddress)
+		client.open(t)
+
+		for i := 0; i < 3; i++ {
+			client.send(t, cmdSyslog, fmt.Sprintf("<13>message %d", i))
+		}
+		events := collect.await(t, 3)
+		for i, evt := range events {
+			assert.Equal(t, fmt.Sprintf("<13>message %d", i), evt.Fields["message"])
+			addr, err := evt.Fields.GetValue("log.source.address")
+			require.NoError(t, err)
+			assert.NotEmpty(t, addr)
+		}
+
+		// Nothing has been ACKed by the pipeline, so no response is expected.
+		client.expectNoFrame(t)
+
+		collect.ackAll()
+		for i := 0; i < 3; i++ {
+			f := client.read(t)
+			assert.Equal(t, i+2, f.txnr)
+			assert.Equal(t, cmdRsp, f.command)
+			assert.Equal(t, "200 OK", string(f.data))
+		}
+
+		client.send(t, cmdClose, "")
+		f := client.read(t)
+		assert.Equal(t, 5, f.txnr)
+		assert.Equal(t, cmdRsp, f.command)
+		assert.Empty(t, f.data)
+	})
+
+	t.Run("window", func(t *testing.T) {
+		c := testConfig()
+		c.WindowSize = 2
+		s, collect := startTestServer(t, c)
+		client := dialTestClient(t, s.bindAddress)
+		client.open(t)
+
+		for i := 0; i < 3; i++ {
+			client.send(t, cmdSyslog, fmt.Sprintf("<13>message %d", i))
+		}
+		collect.await(t, 2)
+		time.Sleep(100 * time.Millisecond)
+		assert.Len(t, collect.published(), 2, "window size exceeded")
+
+		collect.ackAll()
+		collect.await(t, 3)
+		collect.ackAll()
+		for i := 0; i < 3; i++ {
+			f := client.read(t)
+			assert.Equal(t, i+2, f.txnr)
+		}
+	})
+
+	t.Run("close waits for pending transactions", func(t *testing.T) {
+		s, collect := startTestServer(t, testConfig())
+		client := dialTestClient(t, s.bindAddress)
+		client.open(t)
+
+		client.send(t, cmdSyslog, "<13>message")
+		collect.await(t, 1)
+		client.send(t, cmdClose, "")
+		client.expectNoFrame(t)
+
+		collect.ackAll()
+		f := client.read(t)
+		assert.Equal(t, 2, f.txnr)
+		assert.Equal(t, "200 OK", string(f.data))
+		f = client.read(t)
+		assert.Equal(t, 3, f.txnr)
+		assert.Empty(t, f.data)
+	})
+
+	t.Run("syslog before open", func(t *testing.T) {
+		s, _ := startTestServer(t, testConfig())
+		client := dialTestClient(t, s.bindAddress)
+
+		client.send(t, cmdSyslog, "<13>message")
+		f := client.read(t)
+		assert.Equal(t, cmdRsp, f.command)
+		assert.True(t, strings.HasPrefix(string(f.data), "500 "), string(f.data))
+	})
+
+	t.Run("syslog not offered", func(t *testing.T) {
+		s, _ := startTestServer(t, testConfig())
+		client := dialTestClient(t, s.bindAddress)
+
+		client.send(t, cmdOpen, "relp_version=0\ncommands=other")
+		f := client.read(t)
+		assert.Equal(t, "500 required command syslog not offered", string(f.data))
+	})
+
+	t.Run("invalid txnr", func(t *testing.T) {
+		s, _ := startTestServer(t, testConfig())
+		client := dialTestClient(t, s.bindAddress)
+		client.open(t)
+
+		client.txnr++ // Skip a transaction number.
+		client.send(t, cmdSyslog, "<13>message")
+		f := client.read(t)
+		assert.Equal(t, "500 invalid txnr 3, expected 2", string(f.data))
+		f = client.read(t)
+		assert.Equal(t, cmdServerClose, f.command)
+	})
+
+	t.Run("tls client subject with max connections", func(t *testing.T) {
+		c := testConfig()
+		c.MaxConnections = 1
+		serverTLS, clientTLS := testTLSConfigs(t, "relp-client")
+		c.TLS = serverTLS
+		s, collect := startTestServer(t, c)
+		client := dialTestTLSClient(t, s.bindAddress, clientTLS)
+		client.open(t)
+
+		client.send(t, cmdSyslog, "<13>message")
+		events := collect.await(t, 1)
+		subject, err := events[0].Fields.GetValue("tls.client.subject")
+		require.NoError(t, err)
+		assert.Equal(t, "relp-client", subject)
+	})
+
+	t.Run("serverclose on shutdown", func(t *testing.T) {
+		s, collect := startTestServer(t, testConfig())
+		client := dialTestClient(t, s.bindAddress)
+		client.open(t)
+
+		client.send(t, cmdSyslog, "<13>message")
+		collect.await(t, 1)
+		require.NoError(t, s.Close())
+
+		f := client.read(t)
+		assert.Equal(t, 0, f.txnr)
+		assert.Equal(t, cmdServerClose, f.command)
+	})
+}
+
+func testConfig() config {
+	var c config
+	c.InitDefaults()
+	c.ListenAddress = "localhost:0"
+	return c
+}
+
+func startTestServer(t *testing.T, c config) (*server, *eventCollector) {
+	t.Helper()
+
+	collect := &eventCollector{}
+	s, err := newServer(c, logptest.NewTestingLogger(t, inputName), collect.publish, nil, nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { s.Close() })
+
+	done := make(chan error, 1)
+	go func() { done <- s.Run() }()
+	t.Cleanup(func() {
+		s.Close()
+		require.NoError(t, <-done)
+	})
+	return s, collect
+}
+
+type testClient struct {
+	conn   net.Conn
+	reader *bufio.Reader
+	txnr   int
+}
+
+func dialTestClient(t *testing.T, address string) *testClient {
+	t.Helper()
+
+	conn, err := net.DialTimeout("tcp", address, testTimeout)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	return &testClient{conn: conn, reader: bufio.NewReader(conn)}
+}
+
+func dialTestTLSClient(t *testing.T, address string, tlsConfig *tls.Config) *testClient {
+	t.Helper()
+
+	dialer := &net.Dialer{Timeout: testTimeout}
+	conn, err := tls.DialWithDialer(dialer, "tcp", address, tlsConfig)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	return &testClient{conn: conn, reader: bufio.NewReader(conn)}
+}
+
+// testTLSConfigs returns a server configuration requiring client certificates,
+// and a client configuration presenting a certificate with the commonName.
+func testTLSConfigs(t *testing.T, commonName string) (*tlscommon.ServerConfig, *tls.Config) {
+	t.Helper()
+
+	ca, err := tlscommontest.GenCA()
+	require.NoError(t, err)
+	serverCert, err := tlscommontest.GenSignedCert(ca, x509.KeyUsageDigitalSignature, false, "localhost", []string{"localhost"}, []net.IP{net.IPv4(127, 0, 0, 1)}, false)
+	require.NoError(t, err)
+	clientCert, err := tlscommontest.GenSignedCert(ca, x509.KeyUsageDigitalSignature, false, commonName, nil, nil, false)
+	require.NoError(t, err)
+
+	certPEM := func(cert tls.Certificate) string {
+		return string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Certificate[0]}))
+	}
+	keyPEM := func(cert tls.Certificate) string {
+		key := x509.MarshalPKCS1PrivateKey(cert.PrivateKey.(*rsa.PrivateKey))
+		return string(pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: key}))
+	}
+
+	clientAuth := tlscommon.TLSClientAuthRequired
+	serverConfig := &tlscommon.ServerConfig{
+		CAs: []string{certPEM(ca)},
+		Certificate: tlscommon.CertificateConfig{
+			Certificate: certPEM(serverCert),
+			Key:         keyPEM(serverCert),
+		},
+		ClientAuth: &clientAuth,
+	}
+
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.Leaf)
+	clientConfig := &tls.Config{
+		Certificates: []tls.Certificate{clientCert},
+		RootCAs:      roots,
+		ServerName:   "localhost",
+		MinVersion:   tls.VersionTLS12,
+	}
+	return serverConfig, clientConfig
+}
+
+func (c *testClient) open(t *testing.T) {
+	t.Helper()
+
+	c.send(t, cmdOpen, "relp_version=0\nrelp_software=test\ncommands=syslog")
+	f := c.read(t)
+	require.Equal(t, cmdRsp, f.command)
+	require.True(t, strings.HasPrefix(string(f.data), "200 OK"), string(f.data))
+	assert.Equal(t, "syslog", parseOffers(f.data)["commands"])
+}
+
+func (c *testClient) send(t *testing.T, command, data string) {
+	t.Helper()
+
+	c.txnr++
+	_, err := c.conn.Write(appendFrame(nil, c.txnr, command, []byte(data)))
+	require.NoError(t, err)
+}
+
+func (c *testClient) read(t *testing.T) frame {
+	t.Helper()
+
+	require.NoError(t, c.conn.SetReadDeadline(time.Now().Add(testTimeout)))
+	f, err := readFrame(c.reader, 1024)
+	require.NoError(t, err)
+	return f
+}
+
+func (c *testClient) expectNoFrame(t *testing.T) {
+	t.Helper()
+
+	require.NoError(t, c.conn.SetReadDeadline(time.Now().Add(100*time.Millisecond)))
+	_, err := c.reader.Peek(1)
+	var netErr net.Error
+	require.ErrorAs(t, err, &netErr, "unexpected frame received")
+	require.True(t, netErr.Timeout())
+}
+
+// eventCollector records published events and ACKs them on request, like
+// the pipeline does once events have been written by an output.
+type eventCollector struct {
+	sync.Mutex
+	events []beat.Event
+	acked  int
+}
+
+func (c *eventCollector) publish(evt beat.Event) {
+	c.Lock()
+	defer c.Unlock()
+
+	c.events = append(c.events, evt)
+}
+
+func (c *eventCollector) published() []beat.Event {
+	c.Lock()
+	defer c.Unlock()
+
+	events := make([]beat.Event, len(c.events))
+	copy(events, c.events)
+	return events
+}
+
+func (c *eventCollector) await(t *testing.T, n int) []beat.Event {
+	t.Helper()
+
+	var events []beat.Event
+	require.Eventually(t, func() bool {
+		events = c.published()
+		return len(events) >= n
+	}, testTimeout, 10*time.Millisecond)
+	return events
+}
+
+func (c *eventCollector) ackAll() {
+	c.Lock()
+	pending := c.events[c.acked:]
+	c.acked = len(c.events)
+	c.Unlock()
+
+	for _, evt := range pending {
+		evt.Private.(*txnACK).ACK()
+	}
+}
@@ -0,0 +1,317 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package relp
+
+import (
+	"bufio"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/elastic/beats/v7/libbeat/beat"
+	"github.com/elastic/elastic-agent-libs/logp"
+	"github.com/elastic/elastic-agent-libs/mapstr"
+)
+
+// writeTimeout bounds the time spent writing a response to a client.
+const writeTimeout = 10 * time.Second
+
+var (
+	errSessionNotOpen = errors.New("session not opened")
+	errShutdown       = errors.New("server is shutting down")
+)
+
+// session handles a single RELP connection. Frames are read sequentially by
+// run. syslog transactions are published and only answered from ack once the
+// event has been acknowledged, so a client retransmits every transaction
+// whose response it did not receive.
+type session struct {
+	conn       net.Conn
+	reader     *bufio.Reader
+	config     config
+	log        *logp.Logger
+	publish    func(beat.Event)
+	metrics    *inputMetrics
+	remoteAddr string
+
+	opened   bool
+	lastTxnr int
+
+	window  chan struct{}  // window limits the number of unanswered transactions.
+	pending sync.WaitGroup // pending tracks unanswered syslog transactions.
+
+	writeMutex sync.Mutex // writeMutex serializes writes to conn.
+	buf        []byte
+
+	done         chan struct{} // done is closed when the session is terminated.
+	shutdownOnce sync.Once
+}
+
+func newSession(conn net.Conn, c config, log *logp.Logger, pub func(beat.Event), metrics *inputMetrics) *session {
+	remoteAddr := conn.RemoteAddr().String()
+	return &session{
+		conn:       conn,
+		reader:     bufio.NewReader(conn),
+		config:     c,
+		log:        log.With("remote_address", remoteAddr),
+		publish:    pub,
+		metrics:    metrics,
+		remoteAddr: remoteAddr,
+		window:     make(chan struct{}, c.WindowSize),
+		done:       make(chan struct{}),
+	}
+}
+
+// run processes frames until the client closes the session, an error occurs
+// or the session is shut down.
+func (s *session) run() {
+	s.metrics.sessionsActive.Inc()
+	s.metrics.sessionsTotal.Inc()
+	defer s.metrics.sessionsActive.Dec()
+	defer s.close()
+
+	s.log.Debug("RELP connection accepted.")
+	err := s.serve()
+	switch {
+	case err == nil, errors.Is(err, errShutdown), s.isShutdown():
+		s.log.Debug("RELP session closed.")
+	case errors.Is(err, io.EOF):
+		s.log.Debug("RELP connection closed by peer.")
+	default:
+		s.metrics.errorsTotal.Inc()
+		s.log.Warnw("RELP session terminated.", "error", err)
+	}
+}
+
+func (s *session) serve() error {
+	maxDataLen := int(s.config.MaxMessageSize)
+	for {
+		if s.config.Timeout > 0 {
+			if err := s.conn.SetReadDeadline(time.Now().Add(s.config.Timeout)); err != nil {
+				return err
+			}
+		}
+
+		f, err := readFrame(s.reader, maxDataLen)
+		if err != nil {
+			if errors.Is(err, errFrameSyntax) {
+				s.abort(err)
+			}
+			return err
+		}
+
+		if err := s.checkTxnr(f.txnr); err != nil {
+			s.reject(f.txnr, err)
+			s.abort(err)
+			return err
+		}
+
+		switch f.command {
+		case cmdOpen:
+			if err := s.open(f); err != nil {
+				return err
+			}
+		case cmdSyslog:
+			if !s.opened {
+				s.reject(f.txnr, errSessionNotOpen)
+				return errSessionNotOpen
+			}
+			if err := s.syslog(f); err != nil {
+				return err
+			}
+		case cmdClose:
+			return s.closeSession(f)
+		default:
+			// librelp answers unknown commands with an error and keeps the
+			// session open.
+			s.reject(f.txnr, fmt.Errorf("command %q not supported", f.command))
+		}
+	}
+}
+
+// checkTxnr verifies that transaction numbers increase by one, wrapping
+// to 1 after the maximum value.
+func (s *session) checkTxnr(txnr int) error {
+	want := s.lastTxnr + 1
+	if want > maxTxnr {
+		want = 1
+	}
+	if txnr != want {
+		return fmt.Errorf("invalid txnr %d, expected %d", txnr, want)
+	}
+	s.lastTxnr = txnr
+	return nil
+}
+
+func (s *session) open(f frame) error {
+	if s.opened {
+		err := errors.New("session already opened")
+		s.reject(f.txnr, err)
+		return err
+	}
+
+	offers := parseOffers(f.data)
+	if v, ok := offers["relp_version"]; ok && v != relpVersion {
+		s.log.Debugw("Client offered a different RELP version, continuing with version "+relpVersion+".", "relp_version", v)
+	}
+	if !containsCommand(offers["commands"], cmdSyslog) {
+		err := errors.New("required command syslog not offered")
+		s.reject(f.txnr, err)
+		return err
+	}
+
+	s.opened = true
+	s.log.Debugw("RELP session opened.", "relp_software", offers["relp_software"])
+	return s.respond(f.txnr, openResponse())
+}
+
+func (s *session) syslog(f frame) error {
+	// Stop reading while the window is full. The client will do the same
+	// once its own window is exhausted.
+	select {
+	case s.window <- struct{}{}:
+	case <-s.done:
+		return errShutdown
+	}
+	s.pending.Add(1)
+	s.metrics.messagesReceivedTotal.Inc()
+
+	start := time.Now()
+	if len(f.data) == 0 {
+		// Nothing to publish, answer immediately.
+		s.ack(f.txnr, start)
+		return nil
+	}
+	s.publish(s.makeEvent(f, start))
+	return nil
+}
+
+// ack answers a syslog transaction once the corresponding event has been
+// acknowledged. It is called from the pipeline's ACK handler.
+func (s *session) ack(txnr int, start time.Time) {
+	if err := s.respond(txnr, []byte("200 OK")); err != nil {
+		s.log.Debugw("Failed to send RELP response.", "txnr", txnr, "error", err)
+	} else {
+		s.metrics.messagesACKedTotal.Inc()
+		s.metrics.messageProcessingTime.Update(time.Since(start).Nanoseconds())
+	}
+	<-s.window
+	s.pending.Done()
+}
+
+// closeSession waits for all outstanding transactions to be answered before
+// acknowledging the close command, as required by RELP.
+func (s *session) closeSession(f frame) error {
+	drained := make(chan struct{})
+	go func() {
+		s.pending.Wait()
+		close(drained)
+	}()
+	select {
+	case <-drained:
+	case <-s.done:
+		return errShutdown
+	}
+	return s.respond(f.txnr, nil)
+}
+
+// reject sends a negative response for a transaction. Failures are ignored as
+// the session is usually terminated afterwards.
+func (s *session) reject(txnr int, err error) {
+	s.respond(txnr, []byte("500 "+err.Error())) //nolint:errcheck // Best effort, see above.
+}
+
+func (s *session) respond(txnr int, data []byte) error {
+	return s.write(txnr, cmdRsp, data)
+}
+
+func (s *session) write(txnr int, command string, data []byte) error {
+	s.writeMutex.Lock()
+	defer s.writeMutex.Unlock()
+
+	if err := s.conn.SetWriteDeadline(time.Now().Add(writeTimeout)); err != nil {
+		return err
+	}
+	s.buf = appendFrame(s.buf[:0], txnr, command, data)
+	_, err := s.conn.Write(s.buf)
+	return err
+}
+
+// abort notifies the client that the session is being terminated because of
+// an error.
+func (s *session) abort(err error) {
+	s.log.Debugw("Aborting RELP session.", "error", err)
+	s.write(0, cmdServerClose, nil) //nolint:errcheck // Best effort, the connection is closed afterwards.
+}
+
+// shutdown terminates the session on server shutdown. The client is sent a
+// serverclose so that it reconnects and retransmits unanswered transactions.
+func (s *session) shutdown() {
+	s.shutdownOnce.Do(func() {
+		close(s.done)
+		s.write(0, cmdServerClose, nil) //nolint:errcheck // Best effort, the connection is closed afterwards.
+		s.conn.Close()
+	})
+}
+
+func (s *session) isShutdown() bool {
+	select {
+	case <-s.done:
+		return true
+	default:
+		return false
+	}
+}
+
+func (s *session) close() {
+	s.shutdownOnce.Do(func() {
+		close(s.done)
+		s.conn.Close()
+	})
+}
+
+func (s *session) makeEvent(f frame, start time.Time) beat.Event {
+	event := beat.Event{
+		Timestamp: start.UTC(),
+		Fields: mapstr.M{
+			"message": string(f.data),
+			"log": mapstr.M{
+				"source": mapstr.M{
+					"address": s.remoteAddr,
+				},
+			},
+		},
+		Private: &txnACK{session: s, txnr: f.txnr, start: start},
+	}
+
+	if tlsConn, ok := s.conn.(interface{ ConnectionState() tls.ConnectionState }); ok {
+		state := tlsConn.ConnectionState()
+		if len(state.PeerCertificates) > 0 {
+			event.Fields["tls"] = mapstr.M{
+				"client": mapstr.M{
+					"subject": state.PeerCertificates[0].Subject.CommonName,
+				},
+			}
+		}
+	}
+
+	return event
+}
+
+// containsCommand reports whether command is in the comma separated list of
+// commands offered by a client.
+func containsCommand(commands, command string) bool {
+	for _, c := range strings.Split(commands, ",") {
+		if strings.TrimSpace(c) == command {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,83 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package sql
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/elastic/elastic-agent-libs/transport/tlscommon"
+)
+
+const (
+	trackingNumeric   = "numeric"
+	trackingTimestamp = "timestamp"
+)
+
+type config struct {
+	// Driver is the name of the database driver: mysql, postgres, mssql or oracle.
+	Driver string `config:"driver" validate:"required"`
+	// Hosts are the connection strings of the databases to query. Each host
+	// keeps its own cursor.
+	Hosts    []string          `config:"hosts" validate:"required"`
+	Username string            `config:"username"`
+	Password string            `config:"password"`
+	TLS      *tlscommon.Config `config:"ssl"`
+
+	// Query is run every Interval. Every occurrence of the :sql_last_value
+	// placeholder is bound to the last value seen in TrackingColumn.
+	Query          string        `config:"sql_query" validate:"required"`
+	TrackingColumn string        `config:"tracking_column" validate:"required"`
+	TrackingType   string        `config:"tracking_type"`
+	TrackingStart  string        `config:"tracking_start"`
+	Interval       time.Duration `config:"interval" validate:"positive,nonzero"`
+	QueryTimeout   time.Duration `config:"query_timeout" validate:"positive"`
+
+	// Target is the field the row columns are written to. An empty target
+	// writes the columns to the root of the event.
+	Target string `config:"target"`
+}
+
+func defaultConfig() config {
+	return config{
+		TrackingType: trackingNumeric,
+		Interval:     time.Minute,
+		QueryTimeout: 30 * time.Second,
+		Target:       "sql",
+	}
+}
+
+func (c *config) Validate() error {
+	if !isSupportedDriver(c.Driver) {
+		return fmt.Errorf("unsupported driver %q", c.Driver)
+	}
+	if !strings.Contains(c.Query, lastValuePlaceholder) {
+		return fmt.Errorf("sql_query must contain the %s placeholder", lastValuePlaceholder)
+	}
+	switch c.TrackingType {
+	case trackingNumeric, trackingTimestamp:
+	default:
+		return fmt.Errorf("invalid tracking_type %q, must be one of %s or %s", c.TrackingType, trackingNumeric, trackingTimestamp)
+	}
+	if c.TrackingStart != "" {
+		if _, err := parseTrackingValue(c.TrackingType, c.TrackingStart); err != nil {
+			return fmt.Errorf("invalid tracking_start: %w", err)
+		}
+	}
+	if len(c.Hosts) == 0 {
+		return errors.New("at least one host must be configured")
+	}
+	return nil
+}
+
+func isSupportedDriver(driver string) bool {
+	switch driver {
+	case "mysql", "postgres", "postgresql", "mssql", "oracle":
+		return true
+	}
+	return false
+}
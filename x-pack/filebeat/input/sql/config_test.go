@@ -0,0 +1,112 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package sql
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	conf "github.com/elastic/elastic-agent-libs/config"
+)
+
+func TestConfig(t *testing.T) {
+	base := func(overrides map[string]interface{}) map[string]interface{} {
+		c := map[string]interface{}{
+			"driver":          "postgres",
+			"hosts":           []string{"postgres://localhost:5432/db"},
+			"sql_query":       "SELECT * FROM audit WHERE id > :sql_last_value ORDER BY id",
+			"tracking_column": "id",
+		}
+		for k, v := range overrides {
+			c[k] = v
+		}
+		return c
+	}
+
+	testCases := []struct {
+		name        string
+		userConfig  map[string]interface{}
+		check       func(t *testing.T, c config)
+		expectedErr string
+	}{
+		{
+			name:       "defaults",
+			userConfig: base(nil),
+			check: func(t *testing.T, c config) {
+				require.Equal(t, trackingNumeric, c.TrackingType)
+				require.Equal(t, time.Minute, c.Interval)
+				require.Equal(t, 30*time.Second, c.QueryTimeout)
+				require.Equal(t, "sql", c.Target)
+			},
+		},
+		{
+			name:       "empty target",
+			userConfig: base(map[string]interface{}{"target": ""}),
+			check: func(t *testing.T, c config) {
+				require.Equal(t, "", c.Target)
+			},
+		},
+		{
+			name: "timestamp tracking",
+			userConfig: base(map[string]interface{}{
+				"tracking_type":  "timestamp",
+				"tracking_start": "2024-01-02 03:04:05",
+			}),
+			check: func(t *testing.T, c config) {
+				require.Equal(t, trackingTimestamp, c.TrackingType)
+			},
+		},
+		{
+			name:        "unsupported driver",
+			userConfig:  base(map[string]interface{}{"driver": "sqlite"}),
+			expectedErr: `unsupported driver "sqlite"`,
+		},
+		{
+			name:        "missing placeholder",
+			userConfig:  base(map[string]interface{}{"sql_query": "SELECT * FROM audit"}),
+			expectedErr: "sql_query must contain the :sql_last_value placeholder",
+		},
+		{
+			name:        "invalid tracking type",
+			userConfig:  base(map[string]interface{}{"tracking_type": "uuid"}),
+			expectedErr: `invalid tracking_type "uuid"`,
+		},
+		{
+			name:        "invalid tracking start",
+			userConfig:  base(map[string]interface{}{"tracking_start": "yesterday"}),
+			expectedErr: "invalid tracking_start",
+		},
+		{
+			name:        "missing tracking column",
+			userConfig:  base(map[string]interface{}{"tracking_column": ""}),
+			expectedErr: "string value is not set accessing 'tracking_column'",
+		},
+		{
+			name:        "zero interval",
+			userConfig:  base(map[string]interface{}{"interval": "0s"}),
+			expectedErr: "zero value accessing 'interval'",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			c := conf.MustNewConfigFrom(tc.userConfig)
+
+			cfg := defaultConfig()
+			err := c.Unpack(&cfg)
+
+			if tc.expectedErr != "" {
+				require.Error(t, err, "expected error: %s", tc.expectedErr)
+				require.Contains(t, err.Error(), tc.expectedErr)
+				return
+			}
+
+			require.NoError(t, err)
+			tc.check(t, cfg)
+		})
+	}
+}
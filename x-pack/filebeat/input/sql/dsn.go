@@ -0,0 +1,27 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+//go:build !requirefips
+
+package sql
+
+import (
+	// Register the drivers not already imported by the sql query metricset.
+	_ "github.com/lib/pq"
+	_ "github.com/microsoft/go-mssqldb"
+
+	"github.com/elastic/beats/v7/metricbeat/mb"
+	"github.com/elastic/beats/v7/x-pack/metricbeat/module/sql/query"
+)
+
+// parseDSN builds the connection URI of host using the same driver specific
+// parsing as the sql query metricset.
+func parseDSN(cfg config, host string) (mb.HostData, error) {
+	return query.ParseConnectionDSN(query.ConnectionDetails{
+		Username: cfg.Username,
+		Password: cfg.Password,
+		Driver:   cfg.Driver,
+		TLS:      cfg.TLS,
+	}, host)
+}
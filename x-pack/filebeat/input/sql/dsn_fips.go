@@ -0,0 +1,17 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+//go:build requirefips
+
+package sql
+
+import (
+	"errors"
+
+	"github.com/elastic/beats/v7/metricbeat/mb"
+)
+
+func parseDSN(config, string) (mb.HostData, error) {
+	return mb.HostData{}, errors.New("the sql input is not supported in FIPS mode")
+}
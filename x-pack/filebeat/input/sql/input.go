@@ -0,0 +1,223 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package sql
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/elastic/go-concert/ctxtool"
+	"github.com/elastic/go-concert/timed"
+
+	v2 "github.com/elastic/beats/v7/filebeat/input/v2"
+	inputcursor "github.com/elastic/beats/v7/filebeat/input/v2/input-cursor"
+	"github.com/elastic/beats/v7/libbeat/beat"
+	"github.com/elastic/beats/v7/libbeat/feature"
+	"github.com/elastic/beats/v7/libbeat/management/status"
+	"github.com/elastic/beats/v7/libbeat/statestore"
+	sqlhelper "github.com/elastic/beats/v7/metricbeat/helper/sql"
+	conf "github.com/elastic/elastic-agent-libs/config"
+	"github.com/elastic/elastic-agent-libs/logp"
+	"github.com/elastic/elastic-agent-libs/mapstr"
+)
+
+const inputName = "sql"
+
+// Plugin returns the sql input plugin.
+func Plugin(log *logp.Logger, store statestore.States) v2.Plugin {
+	return v2.Plugin{
+		Name:      inputName,
+		Stability: feature.Beta,
+		Info:      "Collect rows from SQL databases using a tracking column",
+		Manager: &inputcursor.InputManager{
+			Logger:     log,
+			StateStore: store,
+			Type:       inputName,
+			Configure:  configure,
+		},
+	}
+}
+
+func configure(cfg *conf.C, _ *logp.Logger) ([]inputcursor.Source, inputcursor.Input, error) {
+	config := defaultConfig()
+	if err := cfg.Unpack(&config); err != nil {
+		return nil, nil, err
+	}
+
+	sources := make([]inputcursor.Source, 0, len(config.Hosts))
+	for i, host := range config.Hosts {
+		hostData, err := parseDSN(config, host)
+		if err != nil {
+			return nil, nil, fmt.Errorf("cannot parse hosts[%d]: %w", i, err)
+		}
+		sources = append(sources, &source{uri: hostData.URI, name: hostData.SanitizedURI})
+	}
+
+	return sources, &sqlInput{config: config, connect: connect}, nil
+}
+
+type source struct {
+	uri  string
+	name string
+}
+
+func (s *source) Name() string { return s.name }
+
+// database is the subset of the sql helper client used by the input.
+type database interface {
+	FetchTableMode(ctx context.Context, q string, args ...interface{}) ([]mapstr.M, error)
+	Close() error
+}
+
+func connect(driver, uri string, log *logp.Logger) (database, error) {
+	return sqlhelper.NewDBClient(driver, uri, log)
+}
+
+type sqlInput struct {
+	config  config
+	connect func(driver, uri string, log *logp.Logger) (database, error)
+}
+
+var _ inputcursor.Input = (*sqlInput)(nil)
+
+func (inp *sqlInput) Name() string { return inputName }
+
+func (inp *sqlInput) Test(src inputcursor.Source, ctx v2.TestContext) error {
+	db, err := inp.connect(inp.config.Driver, src.(*source).uri, ctx.Logger)
+	if err != nil {
+		return fmt.Errorf("cannot connect to %s: %w", src.Name(), err)
+	}
+	return db.Close()
+}
+
+func (inp *sqlInput) Run(env v2.Context, src inputcursor.Source, cursor inputcursor.Cursor, pub inputcursor.Publisher) error {
+	log := env.Logger
+	env.UpdateStatus(status.Starting, "")
+
+	last := initialTrackingValue(inp.config)
+	if !cursor.IsNew() {
+		var st state
+		if err := cursor.Unpack(&st); err != nil {
+			err = fmt.Errorf("cannot read cursor state: %w", err)
+			env.UpdateStatus(status.Failed, err.Error())
+			return err
+		}
+		v, err := parseTrackingValue(inp.config.TrackingType, st.LastValue)
+		if err != nil {
+			err = fmt.Errorf("cannot parse stored tracking value: %w", err)
+			env.UpdateStatus(status.Failed, err.Error())
+			return err
+		}
+		last = v
+	}
+	log.Infof("Starting sql input with %s greater than %s", inp.config.TrackingColumn, last)
+
+	q, params := bindQuery(inp.config.Driver, inp.config.Query)
+	metrics := newInputMetrics(env.MetricsRegistry)
+	ctx := ctxtool.FromCanceller(env.Cancelation)
+
+	var db database
+	defer func() {
+		if db != nil {
+			db.Close()
+		}
+	}()
+
+	env.UpdateStatus(status.Running, "")
+	for ctx.Err() == nil {
+		var err error
+		if db == nil {
+			db, err = inp.connect(inp.config.Driver, src.(*source).uri, log)
+			if err != nil {
+				db = nil
+				metrics.errorsTotal.Inc()
+				log.Errorw("Failed to connect to database", "error", err)
+				env.UpdateStatus(status.Degraded, fmt.Sprintf("Failed to connect to database: %v", err))
+			}
+		}
+		if db != nil {
+			last, err = inp.collect(ctx, db, q, params, last, pub, metrics)
+			switch {
+			case ctx.Err() != nil:
+			case err != nil:
+				metrics.errorsTotal.Inc()
+				log.Errorw("Failed to run query", "error", err)
+				env.UpdateStatus(status.Degraded, fmt.Sprintf("Failed to run query: %v", err))
+				// Reconnect on the next run in case the connection was lost.
+				db.Close()
+				db = nil
+			default:
+				env.UpdateStatus(status.Running, "")
+			}
+		}
+		_ = timed.Wait(ctx, inp.config.Interval)
+	}
+	env.UpdateStatus(status.Stopped, "")
+	return nil
+}
+
+// collect runs the query once and publishes the returned rows. It returns the
+// greatest tracking value published. The cursor is only updated with the last
+// row of the batch, so rows that are not ordered by the tracking column are
+// not skipped when the batch is interrupted.
+func (inp *sqlInput) collect(ctx context.Context, db database, q string, params int, last trackingValue, pub inputcursor.Publisher, metrics *inputMetrics) (trackingValue, error) {
+	args := make([]interface{}, params)
+	for i := range args {
+		args[i] = last.arg()
+	}
+
+	queryCtx, cancel := context.WithTimeout(ctx, inp.config.QueryTimeout)
+	defer cancel()
+
+	start := time.Now()
+	rows, err := db.FetchTableMode(queryCtx, q, args...)
+	metrics.executionsTotal.Inc()
+	metrics.queryTime.Update(time.Since(start).Nanoseconds())
+	if err != nil {
+		return last, err
+	}
+	metrics.rowsReceivedTotal.Add(uint64(len(rows)))
+
+	column := strings.ToLower(inp.config.TrackingColumn)
+	next := last
+	for _, row := range rows {
+		raw, ok := row[column]
+		if !ok {
+			return last, fmt.Errorf("tracking column %q not found in query results", inp.config.TrackingColumn)
+		}
+		v, err := parseTrackingValue(inp.config.TrackingType, raw)
+		if err != nil {
+			return last, fmt.Errorf("invalid value in tracking column %q: %w", inp.config.TrackingColumn, err)
+		}
+		if next.less(v) {
+			next = v
+		}
+	}
+
+	for i, row := range rows {
+		var cursor interface{}
+		if i == len(rows)-1 {
+			cursor = &state{LastValue: next.String()}
+		}
+		if err := pub.Publish(inp.createEvent(row), cursor); err != nil {
+			return last, err
+		}
+		metrics.eventsPublishedTotal.Inc()
+	}
+	return next, nil
+}
+
+func (inp *sqlInput) createEvent(row mapstr.M) beat.Event {
+	fields := row
+	if inp.config.Target != "" {
+		fields = mapstr.M{inp.config.Target: row}
+	}
+	return beat.Event{
+		Timestamp: time.Now(),
+		Fields:    fields,
+	}
+}
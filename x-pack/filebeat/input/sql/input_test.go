@@ -0,0 +1,159 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package sql
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/elastic/beats/v7/libbeat/beat"
+	"github.com/elastic/elastic-agent-libs/mapstr"
+	"github.com/elastic/elastic-agent-libs/monitoring"
+)
+
+type fakeDatabase struct {
+	rows  []mapstr.M
+	err   error
+	query string
+	args  []interface{}
+}
+
+func (db *fakeDatabase) FetchTableMode(_ context.Context, q string, args ...interface{}) ([]mapstr.M, error) {
+	db.query, db.args = q, args
+	return db.rows, db.err
+}
+
+func (db *fakeDatabase) Close() error { return nil }
+
+type publishedEvent struct {
+	event  beat.Event
+	cursor interface{}
+}
+
+type fakePublisher struct {
+	events []publishedEvent
+}
+
+func (p *fakePublisher) Publish(event beat.Event, cursor interface{}) error {
+	p.events = append(p.events, publishedEvent{event: event, cursor: cursor})
+	return nil
+}
+
+func TestCollect(t *testing.T) {
+	newInput := func(target string) *sqlInput {
+		cfg := defaultConfig()
+		cfg.Driver = "postgres"
+		cfg.Query = "SELECT * FROM audit WHERE id > :sql_last_value ORDER BY id"
+		cfg.TrackingColumn = "ID"
+		cfg.Target = target
+		return &sqlInput{config: cfg}
+	}
+
+	t.Run("publishes rows and advances cursor", func(t *testing.T) {
+		inp := newInput("sql")
+		db := &fakeDatabase{rows: []mapstr.M{
+			{"id": int64(11), "action": "login"},
+			{"id": int64(12), "action": "logout"},
+		}}
+		pub := &fakePublisher{}
+		metrics := newInputMetrics(monitoring.NewRegistry())
+
+		q, n := bindQuery(inp.config.Driver, inp.config.Query)
+		last, err := inp.collect(context.Background(), db, q, n, initialTrackingValue(inp.config), pub, metrics)
+		require.NoError(t, err)
+
+		assert.Equal(t, "SELECT * FROM audit WHERE id > $1 ORDER BY id", db.query)
+		assert.Equal(t, []interface{}{int64(0)}, db.args)
+		assert.Equal(t, "12", last.String())
+
+		require.Len(t, pub.events, 2)
+		assert.Equal(t, mapstr.M{"sql": mapstr.M{"id": int64(11), "action": "login"}}, pub.events[0].event.Fields)
+		assert.Nil(t, pub.events[0].cursor)
+		assert.Equal(t, &state{LastValue: "12"}, pub.events[1].cursor)
+
+		assert.Equal(t, uint64(1), metrics.executionsTotal.Get())
+		assert.Equal(t, uint64(2), metrics.rowsReceivedTotal.Get())
+		assert.Equal(t, uint64(2), metrics.eventsPublishedTotal.Get())
+
+		// The next run binds the last published value.
+		db.rows = nil
+		last, err = inp.collect(context.Background(), db, q, n, last, pub, metrics)
+		require.NoError(t, err)
+		assert.Equal(t, []interface{}{int64(12)}, db.args)
+		assert.Equal(t, "12", last.String())
+	})
+
+	t.Run("empty target writes to the event root", func(t *testing.T) {
+		inp := newInput("")
+		db := &fakeDatabase{rows: []mapstr.M{{"id": "5", "action": "login"}}}
+		pub := &fakePublisher{}
+
+		_, err := inp.collect(context.Background(), db, "", 0, initialTrackingValue(inp.config), pub, newInputMetrics(monitoring.NewRegistry()))
+		require.NoError(t, err)
+		require.Len(t, pub.events, 1)
+		assert.Equal(t, mapstr.M{"id": "5", "action": "login"}, pub.events[0].event.Fields)
+	})
+
+	t.Run("cursor never moves backwards", func(t *testing.T) {
+		inp := newInput("sql")
+		db := &fakeDatabase{rows: []mapstr.M{{"id": int64(3)}, {"id": int64(2)}}}
+		pub := &fakePublisher{}
+
+		last, err := inp.collect(context.Background(), db, "", 0, initialTrackingValue(inp.config), pub, newInputMetrics(monitoring.NewRegistry()))
+		require.NoError(t, err)
+		assert.Equal(t, "3", last.String())
+		require.Len(t, pub.events, 2)
+		assert.Equal(t, &state{LastValue: "3"}, pub.events[1].cursor)
+	})
+
+	t.Run("unordered rows only update the cursor at the end of the batch", func(t *testing.T) {
+		inp := newInput("sql")
+		db := &fakeDatabase{rows: []mapstr.M{{"id": int64(7)}, {"id": int64(9)}, {"id": int64(8)}}}
+		pub := &fakePublisher{}
+
+		last, err := inp.collect(context.Background(), db, "", 0, initialTrackingValue(inp.config), pub, newInputMetrics(monitoring.NewRegistry()))
+		require.NoError(t, err)
+		assert.Equal(t, "9", last.String())
+		require.Len(t, pub.events, 3)
+		assert.Nil(t, pub.events[0].cursor)
+		assert.Nil(t, pub.events[1].cursor)
+		assert.Equal(t, &state{LastValue: "9"}, pub.events[2].cursor)
+	})
+
+	t.Run("invalid tracking value publishes nothing", func(t *testing.T) {
+		inp := newInput("sql")
+		db := &fakeDatabase{rows: []mapstr.M{{"id": int64(1)}, {"id": "abc"}}}
+		pub := &fakePublisher{}
+
+		last, err := inp.collect(context.Background(), db, "", 0, initialTrackingValue(inp.config), pub, newInputMetrics(monitoring.NewRegistry()))
+		require.ErrorContains(t, err, `invalid value in tracking column "ID"`)
+		assert.Equal(t, "0", last.String())
+		assert.Empty(t, pub.events)
+	})
+
+	t.Run("missing tracking column", func(t *testing.T) {
+		inp := newInput("sql")
+		db := &fakeDatabase{rows: []mapstr.M{{"action": "login"}}}
+		pub := &fakePublisher{}
+
+		_, err := inp.collect(context.Background(), db, "", 0, initialTrackingValue(inp.config), pub, newInputMetrics(monitoring.NewRegistry()))
+		require.ErrorContains(t, err, `tracking column "ID" not found in query results`)
+		assert.Empty(t, pub.events)
+	})
+
+	t.Run("query error", func(t *testing.T) {
+		inp := newInput("sql")
+		db := &fakeDatabase{err: errors.New("relation does not exist")}
+		pub := &fakePublisher{}
+
+		last, err := inp.collect(context.Background(), db, "", 0, initialTrackingValue(inp.config), pub, newInputMetrics(monitoring.NewRegistry()))
+		require.ErrorContains(t, err, "relation does not exist")
+		assert.Equal(t, "0", last.String())
+	})
+}
@@ -0,0 +1,35 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package sql
+
+import (
+	"github.com/rcrowley/go-metrics"
+
+	"github.com/elastic/elastic-agent-libs/monitoring"
+	"github.com/elastic/elastic-agent-libs/monitoring/adapter"
+)
+
+type inputMetrics struct {
+	executionsTotal      *monitoring.Uint // Number of queries executed.
+	errorsTotal          *monitoring.Uint // Number of failed connections and queries.
+	rowsReceivedTotal    *monitoring.Uint // Number of rows returned by the queries.
+	eventsPublishedTotal *monitoring.Uint // Number of events published.
+	queryTime            metrics.Sample   // Histogram of the query execution times in nanoseconds.
+}
+
+func newInputMetrics(reg *monitoring.Registry) *inputMetrics {
+	out := &inputMetrics{
+		executionsTotal:      monitoring.NewUint(reg, "executions_total"),
+		errorsTotal:          monitoring.NewUint(reg, "errors_total"),
+		rowsReceivedTotal:    monitoring.NewUint(reg, "rows_received_total"),
+		eventsPublishedTotal: monitoring.NewUint(reg, "events_published_total"),
+		queryTime:            metrics.NewUniformSample(1024),
+	}
+
+	_ = adapter.NewGoMetrics(reg, "query_time", adapter.Accept).
+		Register("histogram", metrics.NewHistogram(out.queryTime))
+
+	return out
+}
@@ -0,0 +1,49 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package sql
+
+import (
+	"strconv"
+	"strings"
+)
+
+// lastValuePlaceholder is replaced in the configured query by the bind
+// parameter holding the last tracked value.
+const lastValuePlaceholder = ":sql_last_value"
+
+// bindQuery rewrites every occurrence of the :sql_last_value placeholder in
+// query to the bind parameter syntax of driver. It returns the rewritten query
+// and the number of parameters that must be passed to it.
+func bindQuery(driver, query string) (string, int) {
+	var (
+		b strings.Builder
+		n int
+	)
+	for {
+		i := strings.Index(query, lastValuePlaceholder)
+		if i < 0 {
+			b.WriteString(query)
+			return b.String(), n
+		}
+		n++
+		b.WriteString(query[:i])
+		b.WriteString(bindParameter(driver, n))
+		query = query[i+len(lastValuePlaceholder):]
+	}
+}
+
+// bindParameter returns the n-th (1-based) positional bind parameter for driver.
+func bindParameter(driver string, n int) string {
+	switch driver {
+	case "postgres", "postgresql":
+		return "$" + strconv.Itoa(n)
+	case "mssql":
+		return "@p" + strconv.Itoa(n)
+	case "oracle":
+		return ":" + strconv.Itoa(n)
+	default:
+		return "?"
+	}
+}
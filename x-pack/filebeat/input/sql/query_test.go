@@ -0,0 +1,38 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package sql
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBindQuery(t *testing.T) {
+	const query = "SELECT * FROM audit WHERE id > :sql_last_value OR (id = :sql_last_value AND seq > 0)"
+
+	testCases := []struct {
+		driver string
+		want   string
+	}{
+		{"mysql", "SELECT * FROM audit WHERE id > ? OR (id = ? AND seq > 0)"},
+		{"postgres", "SELECT * FROM audit WHERE id > $1 OR (id = $2 AND seq > 0)"},
+		{"postgresql", "SELECT * FROM audit WHERE id > $1 OR (id = $2 AND seq > 0)"},
+		{"mssql", "SELECT * FROM audit WHERE id > @p1 OR (id = @p2 AND seq > 0)"},
+		{"oracle", "SELECT * FROM audit WHERE id > :1 OR (id = :2 AND seq > 0)"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.driver, func(t *testing.T) {
+			got, n := bindQuery(tc.driver, query)
+			assert.Equal(t, tc.want, got)
+			assert.Equal(t, 2, n)
+		})
+	}
+
+	got, n := bindQuery("mysql", "SELECT 1")
+	assert.Equal(t, "SELECT 1", got)
+	assert.Zero(t, n)
+}
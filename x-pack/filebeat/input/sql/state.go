@@ -0,0 +1,169 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package sql
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+)
+
+// state is the cursor persisted in the registry for every host.
+type state struct {
+	LastValue string `json:"last_value" struct:"last_value"`
+}
+
+// timestampLayouts are the layouts accepted when parsing timestamps from the
+// registry, the configuration and string typed columns.
+var timestampLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02 15:04:05.999999999Z07:00",
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02",
+}
+
+// trackingValue is a value of the tracking column.
+type trackingValue struct {
+	kind    string
+	integer int64
+	float   float64
+	isFloat bool
+	time    time.Time
+}
+
+// initialTrackingValue returns the value the cursor starts from when the
+// registry holds no state for a host.
+func initialTrackingValue(cfg config) trackingValue {
+	if cfg.TrackingStart != "" {
+		// Already validated when the configuration was unpacked.
+		v, err := parseTrackingValue(cfg.TrackingType, cfg.TrackingStart)
+		if err == nil {
+			return v
+		}
+	}
+	if cfg.TrackingType == trackingTimestamp {
+		return trackingValue{kind: trackingTimestamp, time: time.Unix(0, 0).UTC()}
+	}
+	return trackingValue{kind: trackingNumeric}
+}
+
+// parseTrackingValue converts a column value or a stored cursor value into a
+// trackingValue of the given kind.
+func parseTrackingValue(kind string, v interface{}) (trackingValue, error) {
+	tv := trackingValue{kind: kind}
+	switch kind {
+	case trackingNumeric:
+		switch v := v.(type) {
+		case int:
+			tv.integer = int64(v)
+		case int8:
+			tv.integer = int64(v)
+		case int16:
+			tv.integer = int64(v)
+		case int32:
+			tv.integer = int64(v)
+		case int64:
+			tv.integer = v
+		case uint:
+			tv.integer = int64(v)
+		case uint8:
+			tv.integer = int64(v)
+		case uint16:
+			tv.integer = int64(v)
+		case uint32:
+			tv.integer = int64(v)
+		case uint64:
+			if v > math.MaxInt64 {
+				return tv, fmt.Errorf("numeric value %d overflows int64", v)
+			}
+			tv.integer = int64(v)
+		case float32:
+			tv.float, tv.isFloat = float64(v), true
+		case float64:
+			tv.float, tv.isFloat = v, true
+		case string:
+			if i, err := strconv.ParseInt(v, 10, 64); err == nil {
+				tv.integer = i
+				break
+			}
+			f, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				return tv, fmt.Errorf("%q is not a numeric value", v)
+			}
+			tv.float, tv.isFloat = f, true
+		default:
+			return tv, fmt.Errorf("unsupported numeric value type %T", v)
+		}
+	case trackingTimestamp:
+		switch v := v.(type) {
+		case time.Time:
+			tv.time = v
+		case string:
+			t, err := parseTimestamp(v)
+			if err != nil {
+				return tv, err
+			}
+			tv.time = t
+		default:
+			return tv, fmt.Errorf("unsupported timestamp value type %T", v)
+		}
+	default:
+		return tv, fmt.Errorf("unknown tracking type %q", kind)
+	}
+	return tv, nil
+}
+
+func parseTimestamp(s string) (time.Time, error) {
+	for _, layout := range timestampLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("%q is not a supported timestamp", s)
+}
+
+// less reports whether v sorts before o.
+func (v trackingValue) less(o trackingValue) bool {
+	if v.kind == trackingTimestamp {
+		return v.time.Before(o.time)
+	}
+	if !v.isFloat && !o.isFloat {
+		return v.integer < o.integer
+	}
+	return v.asFloat() < o.asFloat()
+}
+
+func (v trackingValue) asFloat() float64 {
+	if v.isFloat {
+		return v.float
+	}
+	return float64(v.integer)
+}
+
+// arg returns the value passed to the database driver as query parameter.
+func (v trackingValue) arg() interface{} {
+	switch {
+	case v.kind == trackingTimestamp:
+		return v.time
+	case v.isFloat:
+		return v.float
+	default:
+		return v.integer
+	}
+}
+
+// String returns the representation of v stored in the registry.
+func (v trackingValue) String() string {
+	switch {
+	case v.kind == trackingTimestamp:
+		return v.time.Format(time.RFC3339Nano)
+	case v.isFloat:
+		return strconv.FormatFloat(v.float, 'f', -1, 64)
+	default:
+		return strconv.FormatInt(v.integer, 10)
+	}
+}
@@ -0,0 +1,89 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package sql
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTrackingValue(t *testing.T) {
+	ts := time.Date(2024, 3, 4, 5, 6, 7, 800000000, time.UTC)
+
+	testCases := []struct {
+		name   string
+		kind   string
+		value  interface{}
+		want   string
+		arg    interface{}
+		errMsg string
+	}{
+		{name: "int64", kind: trackingNumeric, value: int64(42), want: "42", arg: int64(42)},
+		{name: "uint8", kind: trackingNumeric, value: uint8(7), want: "7", arg: int64(7)},
+		{name: "float", kind: trackingNumeric, value: 1.5, want: "1.5", arg: 1.5},
+		{name: "integer string", kind: trackingNumeric, value: "9007199254740993", want: "9007199254740993", arg: int64(9007199254740993)},
+		{name: "decimal string", kind: trackingNumeric, value: "10.25", want: "10.25", arg: 10.25},
+		{name: "not a number", kind: trackingNumeric, value: "abc", errMsg: `"abc" is not a numeric value`},
+		{name: "time", kind: trackingTimestamp, value: ts, want: "2024-03-04T05:06:07.8Z", arg: ts},
+		{name: "rfc3339 string", kind: trackingTimestamp, value: "2024-03-04T05:06:07.8Z", want: "2024-03-04T05:06:07.8Z", arg: ts},
+		{name: "sql datetime string", kind: trackingTimestamp, value: "2024-03-04 05:06:07.8", want: "2024-03-04T05:06:07.8Z", arg: ts},
+		{name: "bad timestamp", kind: trackingTimestamp, value: "noon", errMsg: `"noon" is not a supported timestamp`},
+		{name: "bad timestamp type", kind: trackingTimestamp, value: 12, errMsg: "unsupported timestamp value type int"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			v, err := parseTrackingValue(tc.kind, tc.value)
+			if tc.errMsg != "" {
+				require.ErrorContains(t, err, tc.errMsg)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, v.String())
+			assert.Equal(t, tc.arg, v.arg())
+
+			// The stored representation must parse back to the same value.
+			back, err := parseTrackingValue(tc.kind, v.String())
+			require.NoError(t, err)
+			assert.False(t, v.less(back))
+			assert.False(t, back.less(v))
+		})
+	}
+}
+
+func TestTrackingValueLess(t *testing.T) {
+	num := func(v interface{}) trackingValue {
+		tv, err := parseTrackingValue(trackingNumeric, v)
+		require.NoError(t, err)
+		return tv
+	}
+	assert.True(t, num(int64(1)).less(num(int64(2))))
+	assert.False(t, num(int64(2)).less(num(int64(1))))
+	assert.True(t, num(int64(1)).less(num(1.5)))
+	assert.True(t, num(1.5).less(num(int64(2))))
+
+	ts := func(s string) trackingValue {
+		tv, err := parseTrackingValue(trackingTimestamp, s)
+		require.NoError(t, err)
+		return tv
+	}
+	assert.True(t, ts("2024-01-01T00:00:00Z").less(ts("2024-01-01T00:00:01Z")))
+	assert.False(t, ts("2024-01-01T00:00:01Z").less(ts("2024-01-01T00:00:00Z")))
+}
+
+func TestInitialTrackingValue(t *testing.T) {
+	cfg := defaultConfig()
+	assert.Equal(t, "0", initialTrackingValue(cfg).String())
+
+	cfg.TrackingStart = "100"
+	assert.Equal(t, "100", initialTrackingValue(cfg).String())
+
+	cfg = defaultConfig()
+	cfg.TrackingType = trackingTimestamp
+	assert.Equal(t, "1970-01-01T00:00:00Z", initialTrackingValue(cfg).String())
+}
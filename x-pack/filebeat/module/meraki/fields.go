@@ -0,0 +1,23 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+// Code generated by beats/dev-tools/cmd/asset/asset.go - DO NOT EDIT.
+
+package meraki
+
+import (
+	"github.com/elastic/beats/v7/libbeat/asset"
+)
+
+func init() {
+	if err := asset.SetFields("filebeat", "meraki", asset.ModuleFieldsPri, AssetMeraki); err != nil {
+		panic(err)
+	}
+}
+
+// AssetMeraki returns asset data.
+// This is the base64 encoded zlib format compressed contents of module/meraki.
+func AssetMeraki() string {
+	return "eJy8l9FuqzgQhu/zFPMApzxALlbq9mi1Rzqtjrbd7mU02ANYAQ+yh0bdp1/ZgZZQIGkWKnIRGbC/Gf7fM76BPb1uoSKHe7MBECMlbeHOeMVw341q8sqZWgzbLfy2AQC4Z92UBBk7KNDq0tgcjFVchT/91+FAacG8ByzJid8AZIZK7bdxmvC7AYsV9SC6S15r2kLuuKl7o45KQk9bSEmwNz4C2V1/xBUhc1zNsSW9l4aQfVB2OVrzL4Z87Iw+eaaj3tPrgd3w3gxj+P34DpyBFNTx9VeK4xEUUirZ5h6Ek/OAYWg5xAesaAXIxpXLMX5HX6SMTsPff/2cgR3nsiQHdvvBrONanFJKf74PAjkX3QURnoqlJb4g9ydxftTFQmR9jVzH9lEOC6GNSqNlnMYRzP06PE+Y+1mMDkHTi1G0mCg9OYMr5fgxzg22qVJyXXBH/k/KoEK1DuL97R2g1o78W/aPgNMsI9voCnY5R/G1xjhH8zW+OEdRsaaVsnIfpr6UwwtK45MXLJuVlPIYVzjlAUc1OyEN6StgN3hkOVrtG7AtjSXg4MYs/E02YwHEpxfbYkK866Thz6ZCC45QY1pSnLHLSQxh+hOFRz92bAthPQWO96rc5p6SPAFPIsbmfqcKtDnpacKSXtYS888w9YDO2IxdFbshLL/BAZ0NDTw7UM6IUVhOo2o86b+765jJrEQRsnQl621IXgwafE3KZEbF9UAKFDAeLAtUWNekQRhYCnKtImeAozd2rU8rYxuh6f0rdCnXwT+8Vb52jb5dC/SQElkwFox4UI1zZKVzbEoZu36dPKAHT1amwxqKa4Rp2sVzTr6g/F0izQuzNiyFbTRv4UGNOSWzkOPVcWHG0Up5BezxFT8LPOeiTyDfDekORorAbRxwqQGtBksHiKXr5Bh8iuw4b2iH9SoKS703ejYbi3y+3x8f3/foGBCgUqERrNmM2azP+DWIkTB1jFqhl1DXr2EN+rJUzgtsYpP7BOxdu8wEY7t/kYXheXvIezCO9G685V84wSPt/wh5Sx1vR7jxE9owDsVW0Ex79hhHylwS2v8Xxz8FxcI3EYDx7zDJZor3pbar+Hmi/1v4Uz71mr/nXw9dv2WEboRvvJEzu3BN5MY7woVBf2iyYjLzfiR+/vUQl78AMH5GJetT3p664jJExdaSkq9WfAcX2kHF1pIS0snmvwEAo4CVYw=="
+}
@@ -58,8 +58,10 @@ import (
 	_ "github.com/elastic/beats/v7/x-pack/metricbeat/module/istio/mixer"
 	_ "github.com/elastic/beats/v7/x-pack/metricbeat/module/istio/pilot"
 	_ "github.com/elastic/beats/v7/x-pack/metricbeat/module/meraki"
+	_ "github.com/elastic/beats/v7/x-pack/metricbeat/module/meraki/clients"
 	_ "github.com/elastic/beats/v7/x-pack/metricbeat/module/meraki/device_health"
 	_ "github.com/elastic/beats/v7/x-pack/metricbeat/module/meraki/network_health"
+	_ "github.com/elastic/beats/v7/x-pack/metricbeat/module/meraki/wireless"
 	_ "github.com/elastic/beats/v7/x-pack/metricbeat/module/mssql"
 	_ "github.com/elastic/beats/v7/x-pack/metricbeat/module/mssql/performance"
 	_ "github.com/elastic/beats/v7/x-pack/metricbeat/module/mssql/transaction_log"
@@ -70,14 +72,20 @@ import (
 	_ "github.com/elastic/beats/v7/x-pack/metricbeat/module/oracle/sysmetric"
 	_ "github.com/elastic/beats/v7/x-pack/metricbeat/module/oracle/tablespace"
 	_ "github.com/elastic/beats/v7/x-pack/metricbeat/module/panw"
+	_ "github.com/elastic/beats/v7/x-pack/metricbeat/module/panw/dataplane"
 	_ "github.com/elastic/beats/v7/x-pack/metricbeat/module/panw/interfaces"
 	_ "github.com/elastic/beats/v7/x-pack/metricbeat/module/panw/routing"
+	_ "github.com/elastic/beats/v7/x-pack/metricbeat/module/panw/session"
 	_ "github.com/elastic/beats/v7/x-pack/metricbeat/module/panw/system"
+	_ "github.com/elastic/beats/v7/x-pack/metricbeat/module/panw/threat"
 	_ "github.com/elastic/beats/v7/x-pack/metricbeat/module/panw/vpn"
 	_ "github.com/elastic/beats/v7/x-pack/metricbeat/module/prometheus"
 	_ "github.com/elastic/beats/v7/x-pack/metricbeat/module/prometheus/collector"
 	_ "github.com/elastic/beats/v7/x-pack/metricbeat/module/prometheus/remote_write"
 	_ "github.com/elastic/beats/v7/x-pack/metricbeat/module/redisenterprise"
+	_ "github.com/elastic/beats/v7/x-pack/metricbeat/module/snmp"
+	_ "github.com/elastic/beats/v7/x-pack/metricbeat/module/snmp/get"
+	_ "github.com/elastic/beats/v7/x-pack/metricbeat/module/snmp/table"
 	_ "github.com/elastic/beats/v7/x-pack/metricbeat/module/sql"
 	_ "github.com/elastic/beats/v7/x-pack/metricbeat/module/sql/query"
 	_ "github.com/elastic/beats/v7/x-pack/metricbeat/module/stan"
@@ -0,0 +1,83 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package clients
+
+import (
+	"fmt"
+
+	"github.com/elastic/beats/v7/libbeat/common/cfgwarn"
+	"github.com/elastic/beats/v7/metricbeat/mb"
+	"github.com/elastic/beats/v7/x-pack/metricbeat/module/meraki"
+	"github.com/elastic/elastic-agent-libs/logp"
+
+	sdk "github.com/meraki/dashboard-api-go/v3/sdk"
+)
+
+func init() {
+	mb.Registry.MustAddMetricSet("meraki", "clients", New)
+}
+
+type MetricSet struct {
+	mb.BaseMetricSet
+	logger        *logp.Logger
+	client        *sdk.Client
+	organizations []string
+}
+
+func New(base mb.BaseMetricSet) (mb.MetricSet, error) {
+	cfgwarn.Beta("The meraki clients metricset is beta.")
+
+	logger := base.Logger().Named(base.FullyQualifiedName())
+
+	config := meraki.DefaultConfig()
+	if err := base.Module().UnpackConfig(config); err != nil {
+		return nil, err
+	}
+
+	if err := config.Validate(); err != nil {
+		return nil, fmt.Errorf("config validation failed: %w", err)
+	}
+
+	logger.Debugf("loaded config: BaseURL=%s, DebugMode=%s, Organizations=%v, Period=%s", config.BaseURL, config.DebugMode, config.Organizations, config.Period)
+	client, err := sdk.NewClientWithOptions(config.BaseURL, config.ApiKey, config.DebugMode, "Metricbeat Elastic")
+	if err != nil {
+		logger.Error("creating Meraki dashboard API client failed: %w", err)
+		return nil, err
+	}
+
+	return &MetricSet{
+		BaseMetricSet: base,
+		logger:        logger,
+		client:        client,
+		organizations: config.Organizations,
+	}, nil
+}
+
+func (m *MetricSet) Fetch(reporter mb.ReporterV2) error {
+	// only clients seen during the last collection period are counted
+	collectionPeriod := m.BaseMetricSet.Module().Config().Period
+
+	for _, org := range m.organizations {
+		networks, err := meraki.GetNetworks(m.client, org, "wireless", m.logger)
+		if err != nil {
+			return fmt.Errorf("GetNetworks failed; %w", err)
+		}
+
+		var ssids []*SSID
+		for _, network := range networks {
+			networkSSIDs, err := getSSIDClientCounts(m.client, network, collectionPeriod, m.logger)
+			if err != nil {
+				m.logger.Errorf("getSSIDClientCounts failed; %v", err)
+				// continue so we still report the client counts of the other networks
+				continue
+			}
+			ssids = append(ssids, networkSSIDs...)
+		}
+
+		reportSSIDMetrics(reporter, org, ssids)
+	}
+
+	return nil
+}
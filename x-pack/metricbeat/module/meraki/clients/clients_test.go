@@ -0,0 +1,107 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package clients
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/elastic/beats/v7/metricbeat/mb"
+	mbtest "github.com/elastic/beats/v7/metricbeat/mb/testing"
+	"github.com/elastic/elastic-agent-libs/mapstr"
+)
+
+const (
+	testNetworks = `[
+  {"id": "N_1", "name": "Office", "productTypes": ["appliance", "wireless"]},
+  {"id": "N_2", "name": "Warehouse", "productTypes": ["switch"]}
+]`
+
+	testClientsPage1 = `[
+  {"ssid": "corp", "status": "Online", "recentDeviceConnection": "Wireless", "usage": {"sent": 100.0, "recv": 400.0}},
+  {"ssid": "corp", "status": "Offline", "recentDeviceConnection": "Wireless", "usage": {"sent": 1.0, "recv": 3.0}},
+  {"status": "Online", "recentDeviceConnection": "Wired", "usage": {"sent": 9000.0, "recv": 9000.0}}
+]`
+
+	testClientsPage2 = `[
+  {"ssid": "guest", "status": "Online", "recentDeviceConnection": "Wireless", "usage": {"sent": 2.0, "recv": 6.0}}
+]`
+)
+
+func newTestServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/api/v1/organizations/123/networks":
+			_, _ = w.Write([]byte(testNetworks))
+		case "/api/v1/networks/N_1/clients":
+			assert.Equal(t, "300", r.URL.Query().Get("timespan"))
+			if r.URL.Query().Get("startingAfter") == "" {
+				w.Header().Set("Link", fmt.Sprintf("<%s/api/v1/networks/N_1/clients?startingAfter=k3>; rel=next", server.URL))
+				_, _ = w.Write([]byte(testClientsPage1))
+				return
+			}
+			_, _ = w.Write([]byte(testClientsPage2))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"errors": ["Not found"]}`))
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func getConfig(url string) map[string]interface{} {
+	return map[string]interface{}{
+		"module":        "meraki",
+		"metricsets":    []string{"clients"},
+		"period":        "300s",
+		"apiBaseURL":    url,
+		"apiKey":        "test-api-key",
+		"organizations": []string{"123"},
+	}
+}
+
+func TestFetch(t *testing.T) {
+	server := newTestServer(t)
+
+	f := mbtest.NewReportingMetricSetV2Error(t, getConfig(server.URL))
+	events, errs := mbtest.ReportingFetchV2Error(f)
+	require.Empty(t, errs)
+	require.Len(t, events, 2)
+
+	assertSSID(t, events[0], mapstr.M{
+		"organization_id": "123",
+		"network": mapstr.M{
+			"id":   "N_1",
+			"name": "Office",
+		},
+		"ssid": mapstr.M{
+			"name":    "corp",
+			"clients": mapstr.M{"total": 2, "online": 1},
+			"usage": mapstr.M{
+				"sent":     mapstr.M{"bytes": float64(101 * 1024)},
+				"received": mapstr.M{"bytes": float64(403 * 1024)},
+				"total":    mapstr.M{"bytes": float64(504 * 1024)},
+			},
+		},
+	})
+
+	name, err := events[1].ModuleFields.GetValue("ssid.name")
+	require.NoError(t, err)
+	assert.Equal(t, "guest", name)
+}
+
+func assertSSID(t *testing.T, event mb.Event, want mapstr.M) {
+	t.Helper()
+	assert.Equal(t, want, event.ModuleFields)
+}
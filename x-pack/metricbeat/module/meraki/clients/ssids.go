@@ -0,0 +1,151 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package clients
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/elastic/beats/v7/metricbeat/mb"
+	"github.com/elastic/beats/v7/x-pack/metricbeat/module/meraki"
+	"github.com/elastic/elastic-agent-libs/logp"
+	"github.com/elastic/elastic-agent-libs/mapstr"
+
+	"github.com/go-resty/resty/v2"
+	sdk "github.com/meraki/dashboard-api-go/v3/sdk"
+)
+
+// SSID contains the client counts of a wireless network SSID
+type SSID struct {
+	name        string
+	networkID   string
+	networkName string
+	clients     int
+	online      int
+	sentKB      float64
+	receivedKB  float64
+}
+
+// networkClient is a client returned by the /networks/{networkId}/clients
+// endpoint. The SDK response type models the list of clients as a single
+// object, so the response body is decoded with this type instead.
+type networkClient struct {
+	SSID                   string `json:"ssid,omitempty"`
+	Status                 string `json:"status,omitempty"`
+	RecentDeviceConnection string `json:"recentDeviceConnection,omitempty"`
+	Usage                  *struct {
+		Sent *float64 `json:"sent,omitempty"`
+		Recv *float64 `json:"recv,omitempty"`
+	} `json:"usage,omitempty"`
+}
+
+func getSSIDClientCounts(client *sdk.Client, network sdk.ResponseItemOrganizationsGetOrganizationNetworks, period time.Duration, logger *logp.Logger) ([]*SSID, error) {
+	ssids := make(map[string]*SSID)
+
+	path := fmt.Sprintf("/api/v1/networks/%s/clients", network.ID)
+	params := map[string]string{
+		"timespan": strconv.FormatFloat(period.Seconds(), 'f', -1, 64),
+		"perPage":  "1000",
+	}
+	setStart := func(s string) { params["startingAfter"] = s }
+
+	doRequest := func() ([]networkClient, *resty.Response, error) {
+		res, err := client.CustomCall.GetCustomCall(path, &params)
+		if err != nil {
+			return nil, res, err
+		}
+
+		var clients []networkClient
+		if err := json.Unmarshal(res.Body(), &clients); err != nil {
+			return nil, res, fmt.Errorf("failed to unmarshal response body: %w", err)
+		}
+		return clients, res, nil
+	}
+
+	onError := func(err error, res *resty.Response) error {
+		if res != nil {
+			return fmt.Errorf("GetNetworkClients for network %s failed; [%d] %s. %w", network.ID, res.StatusCode(), res.Body(), err)
+		}
+
+		return fmt.Errorf("GetNetworkClients for network %s failed; %w", network.ID, err)
+	}
+
+	onSuccess := func(clients []networkClient) error {
+		for _, c := range clients {
+			// wired clients have no SSID
+			if c.SSID == "" || c.RecentDeviceConnection == "Wired" {
+				continue
+			}
+
+			ssid, ok := ssids[c.SSID]
+			if !ok {
+				ssid = &SSID{
+					name:        c.SSID,
+					networkID:   network.ID,
+					networkName: network.Name,
+				}
+				ssids[c.SSID] = ssid
+			}
+
+			ssid.clients++
+			if c.Status == "Online" {
+				ssid.online++
+			}
+			if c.Usage != nil {
+				if c.Usage.Sent != nil {
+					ssid.sentKB += *c.Usage.Sent
+				}
+				if c.Usage.Recv != nil {
+					ssid.receivedKB += *c.Usage.Recv
+				}
+			}
+		}
+		return nil
+	}
+
+	err := meraki.NewPaginator(
+		setStart,
+		doRequest,
+		onError,
+		onSuccess,
+		logger,
+	).GetAllPages()
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*SSID, 0, len(ssids))
+	for _, ssid := range ssids {
+		result = append(result, ssid)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].name < result[j].name })
+
+	return result, nil
+}
+
+func reportSSIDMetrics(reporter mb.ReporterV2, organizationID string, ssids []*SSID) {
+	metrics := make([]mapstr.M, 0, len(ssids))
+	for _, ssid := range ssids {
+		if ssid == nil {
+			continue
+		}
+
+		metrics = append(metrics, mapstr.M{
+			"network.id":                ssid.networkID,
+			"network.name":              ssid.networkName,
+			"ssid.name":                 ssid.name,
+			"ssid.clients.total":        ssid.clients,
+			"ssid.clients.online":       ssid.online,
+			"ssid.usage.sent.bytes":     ssid.sentKB * 1024,
+			"ssid.usage.received.bytes": ssid.receivedKB * 1024,
+			"ssid.usage.total.bytes":    (ssid.sentKB + ssid.receivedKB) * 1024,
+		})
+	}
+
+	meraki.ReportMetricsForOrganization(reporter, organizationID, metrics)
+}
@@ -19,5 +19,5 @@ func init() {
 // AssetMeraki returns asset data.
 // This is the base64 encoded zlib format compressed contents of module/meraki.
 func AssetMeraki() string {
-	return "eJy0jkEOwiAURPc9xVygF2Dr2kNgGc0PFJoPtentTWowiG5cOMv5zOON8NwNZqr1MgBFSqDBSfKUcK6tMtBmGlxY7ABchcFlMwAYEe3MhgCg7AsNbprW5Vk074/UkeNdJr7qL8sj/fc1PbZFZ6rY8HaqeM99S+q6m5OZMUuKBkVXfrhGli2p/4us9DK/iD4CAAD///mxdNA="
+	return "eJy0lMGO2jAQhu88xbzA5gFy7O6hPXRVid4rY/+BEY4decaL8vZV8KYKaRYhkQBCip35/s/2yC90Rl9Ti2TOvCNSVo+aXllspJ/jaIKHEdR0gJodUcPwTuodEb1QMC0mBCLSvkNNxxRzVwYm7xNNikTY/RtcqLv+5uHjZw6dgof/m4kRfkZ/iWkaOnwdtwjCMdSkKWMRaT0jqFQa1fhZfWH7GI6zCQexiTu9ot9ze0Ci2IwsEiBQDKQn0H7/441cThyO12cbvYcdKqlD4uiqu1oxeA5YycvmlBDU91SwU8dljSzmiEoQtDr0CnlUpImpNVrTUtGN5LfhBRoC6NCXDfqUjc0z+1fEEyz4A25T+TFkiwVcu3Ij+98Du5xQOQAT3IqLGRfi8MEWm1wHgsTGr3AhjMAAvcR03kSW3YqiFx72S2QTUxtDKFfUH1Gj8yZaDvoKOgUbkWj/m73Ty3futMawhytINoMsGVW0nUr1tUDW08r5WU8IyvZRBXey3boKb99ffz0SHGTl3Pf9A7GSrb1t1GeiP2lN9pMuJaOKtlOpdn8HAOx0bOM="
 }
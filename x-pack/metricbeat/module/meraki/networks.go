@@ -0,0 +1,58 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package meraki
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/elastic/elastic-agent-libs/logp"
+
+	"github.com/go-resty/resty/v2"
+	sdk "github.com/meraki/dashboard-api-go/v3/sdk"
+)
+
+// GetNetworks returns the networks of the organization. When productType is not
+// empty only the networks containing that product type (e.g. "wireless") are returned.
+func GetNetworks(client *sdk.Client, organizationID string, productType string, logger *logp.Logger) ([]sdk.ResponseItemOrganizationsGetOrganizationNetworks, error) {
+	var networks []sdk.ResponseItemOrganizationsGetOrganizationNetworks
+
+	params := &sdk.GetOrganizationNetworksQueryParams{}
+	setStart := func(s string) { params.StartingAfter = s }
+
+	doRequest := func() (*sdk.ResponseOrganizationsGetOrganizationNetworks, *resty.Response, error) {
+		return client.Organizations.GetOrganizationNetworks(organizationID, params)
+	}
+
+	onError := func(err error, res *resty.Response) error {
+		if res != nil {
+			return fmt.Errorf("GetOrganizationNetworks failed; [%d] %s. %w", res.StatusCode(), res.Body(), err)
+		}
+
+		return fmt.Errorf("GetOrganizationNetworks failed; %w", err)
+	}
+
+	onSuccess := func(val *sdk.ResponseOrganizationsGetOrganizationNetworks) error {
+		if val == nil {
+			return nil
+		}
+		for _, network := range *val {
+			if productType == "" || slices.Contains(network.ProductTypes, productType) {
+				networks = append(networks, network)
+			}
+		}
+		return nil
+	}
+
+	err := NewPaginator(
+		setStart,
+		doRequest,
+		onError,
+		onSuccess,
+		logger,
+	).GetAllPages()
+
+	return networks, err
+}
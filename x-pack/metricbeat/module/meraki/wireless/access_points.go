@@ -0,0 +1,229 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package wireless
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/elastic/beats/v7/metricbeat/mb"
+	"github.com/elastic/beats/v7/x-pack/metricbeat/module/meraki"
+	"github.com/elastic/elastic-agent-libs/mapstr"
+
+	sdk "github.com/meraki/dashboard-api-go/v3/sdk"
+)
+
+// Serial is the unique identifier for all access points
+type Serial string
+
+// AccessPoint contains the wireless metrics of a Meraki access point
+type AccessPoint struct {
+	serial          Serial
+	mac             string
+	networkID       string
+	bandUtilization map[string]*sdk.ResponseItemOrganizationsGetOrganizationWirelessDevicesChannelUtilizationByDeviceByBand
+	connectionStats *sdk.ResponseItemWirelessGetNetworkWirelessDevicesConnectionStatsConnectionStats
+	latencyStats    *latencyStats
+}
+
+// latencyStats is the per traffic category latency returned by the
+// /networks/{networkId}/wireless/devices/latencyStats endpoint. The SDK response
+// type models the traffic categories other than background as strings, so the
+// response body is decoded with these types instead.
+type latencyStats struct {
+	BackgroundTraffic *trafficLatency `json:"backgroundTraffic,omitempty"`
+	BestEffortTraffic *trafficLatency `json:"bestEffortTraffic,omitempty"`
+	VideoTraffic      *trafficLatency `json:"videoTraffic,omitempty"`
+	VoiceTraffic      *trafficLatency `json:"voiceTraffic,omitempty"`
+}
+
+type trafficLatency struct {
+	Avg *float64 `json:"avg,omitempty"`
+}
+
+type deviceLatencyStats struct {
+	Serial       string        `json:"serial"`
+	LatencyStats *latencyStats `json:"latencyStats,omitempty"`
+}
+
+func getAccessPoint(accessPoints map[Serial]*AccessPoint, serial string, networkID string) *AccessPoint {
+	ap, ok := accessPoints[Serial(serial)]
+	if !ok {
+		ap = &AccessPoint{serial: Serial(serial), networkID: networkID}
+		accessPoints[Serial(serial)] = ap
+	}
+	if ap.networkID == "" {
+		ap.networkID = networkID
+	}
+	return ap
+}
+
+func getChannelUtilization(client *sdk.Client, organizationID string, accessPoints map[Serial]*AccessPoint) error {
+	res, err := client.Devices.GetOrganizationWirelessDevicesChannelUtilizationByDevice(organizationID, &sdk.GetOrganizationWirelessDevicesChannelUtilizationByDeviceQueryParams{
+		// The API requires the interval to be at least 300s, and the timespan can't be less than the interval.
+		// Since our max collection period is also 300s, we set both values to 300s.
+		Timespan: 300,
+		Interval: 300,
+	})
+	if err != nil {
+		if res != nil {
+			return fmt.Errorf("GetOrganizationWirelessDevicesChannelUtilizationByDevice failed; [%d] %s. %w", res.StatusCode(), res.Body(), err)
+		}
+		return fmt.Errorf("GetOrganizationWirelessDevicesChannelUtilizationByDevice failed; %w", err)
+	}
+
+	if res == nil {
+		return nil
+	}
+
+	var result sdk.ResponseOrganizationsGetOrganizationWirelessDevicesChannelUtilizationByDevice
+	if err := json.Unmarshal(res.Body(), &result); err != nil {
+		return fmt.Errorf("failed to unmarshal channel utilization response body: %w", err)
+	}
+
+	for _, d := range result {
+		if d.ByBand == nil {
+			continue
+		}
+
+		var networkID string
+		if d.Network != nil {
+			networkID = d.Network.ID
+		}
+
+		ap := getAccessPoint(accessPoints, d.Serial, networkID)
+		ap.mac = d.Mac
+		if ap.bandUtilization == nil {
+			ap.bandUtilization = make(map[string]*sdk.ResponseItemOrganizationsGetOrganizationWirelessDevicesChannelUtilizationByDeviceByBand)
+		}
+		for i := range *d.ByBand {
+			band := (*d.ByBand)[i]
+			ap.bandUtilization[band.Band] = &band
+		}
+	}
+
+	return nil
+}
+
+func getConnectionStats(client *sdk.Client, network sdk.ResponseItemOrganizationsGetOrganizationNetworks, accessPoints map[Serial]*AccessPoint, period time.Duration) error {
+	val, res, err := client.Wireless.GetNetworkWirelessDevicesConnectionStats(network.ID, &sdk.GetNetworkWirelessDevicesConnectionStatsQueryParams{
+		Timespan: period.Seconds(),
+	})
+	if err != nil {
+		if res != nil {
+			return fmt.Errorf("GetNetworkWirelessDevicesConnectionStats for network %s failed; [%d] %s. %w", network.ID, res.StatusCode(), res.Body(), err)
+		}
+		return fmt.Errorf("GetNetworkWirelessDevicesConnectionStats for network %s failed; %w", network.ID, err)
+	}
+
+	if val == nil {
+		return nil
+	}
+
+	for i := range *val {
+		stats := (*val)[i]
+		if stats.ConnectionStats == nil {
+			continue
+		}
+		getAccessPoint(accessPoints, stats.Serial, network.ID).connectionStats = stats.ConnectionStats
+	}
+
+	return nil
+}
+
+func getLatencyStats(client *sdk.Client, network sdk.ResponseItemOrganizationsGetOrganizationNetworks, accessPoints map[Serial]*AccessPoint, period time.Duration) error {
+	path := fmt.Sprintf("/api/v1/networks/%s/wireless/devices/latencyStats", network.ID)
+	res, err := client.CustomCall.GetCustomCall(path, &map[string]string{
+		"timespan": strconv.FormatFloat(period.Seconds(), 'f', -1, 64),
+		"fields":   "avg",
+	})
+	if err != nil {
+		if res != nil {
+			return fmt.Errorf("GetNetworkWirelessDevicesLatencyStats for network %s failed; [%d] %s. %w", network.ID, res.StatusCode(), res.Body(), err)
+		}
+		return fmt.Errorf("GetNetworkWirelessDevicesLatencyStats for network %s failed; %w", network.ID, err)
+	}
+
+	if res == nil {
+		return nil
+	}
+
+	var result []deviceLatencyStats
+	if err := json.Unmarshal(res.Body(), &result); err != nil {
+		return fmt.Errorf("failed to unmarshal latency stats response body for network %s: %w", network.ID, err)
+	}
+
+	for _, stats := range result {
+		if stats.LatencyStats == nil {
+			continue
+		}
+		getAccessPoint(accessPoints, stats.Serial, network.ID).latencyStats = stats.LatencyStats
+	}
+
+	return nil
+}
+
+func reportAccessPointMetrics(reporter mb.ReporterV2, organizationID string, networks []sdk.ResponseItemOrganizationsGetOrganizationNetworks, accessPoints map[Serial]*AccessPoint) {
+	networkNames := make(map[string]string, len(networks))
+	for _, network := range networks {
+		networkNames[network.ID] = network.Name
+	}
+
+	metrics := []mapstr.M{}
+	for _, ap := range accessPoints {
+		if ap == nil {
+			continue
+		}
+
+		metric := mapstr.M{
+			"device.serial":       string(ap.serial),
+			"device.mac":          ap.mac,
+			"device.network_id":   ap.networkID,
+			"device.network_name": networkNames[ap.networkID],
+		}
+
+		for band, v := range ap.bandUtilization {
+			// Avoid nested object mappings
+			metricBand := strings.ReplaceAll(band, ".", "_")
+			if v.Wifi != nil {
+				metric[fmt.Sprintf("wireless.channel_utilization.%s.utilization_80211", metricBand)] = v.Wifi.Percentage
+			}
+			if v.NonWifi != nil {
+				metric[fmt.Sprintf("wireless.channel_utilization.%s.utilization_non_80211", metricBand)] = v.NonWifi.Percentage
+			}
+			if v.Total != nil {
+				metric[fmt.Sprintf("wireless.channel_utilization.%s.utilization_total", metricBand)] = v.Total.Percentage
+			}
+		}
+
+		if ap.connectionStats != nil {
+			metric["wireless.connection_stats.assoc"] = ap.connectionStats.Assoc
+			metric["wireless.connection_stats.auth"] = ap.connectionStats.Auth
+			metric["wireless.connection_stats.dhcp"] = ap.connectionStats.Dhcp
+			metric["wireless.connection_stats.dns"] = ap.connectionStats.DNS
+			metric["wireless.connection_stats.success"] = ap.connectionStats.Success
+		}
+
+		if ap.latencyStats != nil {
+			for category, traffic := range map[string]*trafficLatency{
+				"background":  ap.latencyStats.BackgroundTraffic,
+				"best_effort": ap.latencyStats.BestEffortTraffic,
+				"video":       ap.latencyStats.VideoTraffic,
+				"voice":       ap.latencyStats.VoiceTraffic,
+			} {
+				if traffic != nil {
+					metric[fmt.Sprintf("wireless.latency.%s.avg.ms", category)] = traffic.Avg
+				}
+			}
+		}
+
+		metrics = append(metrics, metric)
+	}
+
+	meraki.ReportMetricsForOrganization(reporter, organizationID, metrics)
+}
@@ -0,0 +1,96 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package wireless
+
+import (
+	"fmt"
+
+	"github.com/elastic/beats/v7/libbeat/common/cfgwarn"
+	"github.com/elastic/beats/v7/metricbeat/mb"
+	"github.com/elastic/beats/v7/x-pack/metricbeat/module/meraki"
+	"github.com/elastic/elastic-agent-libs/logp"
+
+	sdk "github.com/meraki/dashboard-api-go/v3/sdk"
+)
+
+func init() {
+	mb.Registry.MustAddMetricSet("meraki", "wireless", New)
+}
+
+type MetricSet struct {
+	mb.BaseMetricSet
+	logger        *logp.Logger
+	client        *sdk.Client
+	organizations []string
+}
+
+func New(base mb.BaseMetricSet) (mb.MetricSet, error) {
+	cfgwarn.Beta("The meraki wireless metricset is beta.")
+
+	logger := base.Logger().Named(base.FullyQualifiedName())
+
+	config := meraki.DefaultConfig()
+	if err := base.Module().UnpackConfig(config); err != nil {
+		return nil, err
+	}
+
+	if err := config.Validate(); err != nil {
+		return nil, fmt.Errorf("config validation failed: %w", err)
+	}
+
+	logger.Debugf("loaded config: BaseURL=%s, DebugMode=%s, Organizations=%v, Period=%s", config.BaseURL, config.DebugMode, config.Organizations, config.Period)
+	client, err := sdk.NewClientWithOptions(config.BaseURL, config.ApiKey, config.DebugMode, "Metricbeat Elastic")
+	if err != nil {
+		logger.Error("creating Meraki dashboard API client failed: %w", err)
+		return nil, err
+	}
+
+	return &MetricSet{
+		BaseMetricSet: base,
+		logger:        logger,
+		client:        client,
+		organizations: config.Organizations,
+	}, nil
+}
+
+func (m *MetricSet) Fetch(reporter mb.ReporterV2) error {
+	// some metrics require a 'timespan' parameter; we match this to our
+	// collection interval to only collect new metric values
+	collectionPeriod := m.BaseMetricSet.Module().Config().Period
+
+	for _, org := range m.organizations {
+		networks, err := meraki.GetNetworks(m.client, org, "wireless", m.logger)
+		if err != nil {
+			return fmt.Errorf("GetNetworks failed; %w", err)
+		}
+
+		// Access points are uniquely identified by their serial number, which is
+		// used to associate the metrics returned by the different endpoints.
+		accessPoints := make(map[Serial]*AccessPoint)
+
+		err = getChannelUtilization(m.client, org, accessPoints)
+		if err != nil {
+			return fmt.Errorf("getChannelUtilization failed; %w", err)
+		}
+
+		// connection and latency stats are only available per network
+		for _, network := range networks {
+			err = getConnectionStats(m.client, network, accessPoints, collectionPeriod)
+			if err != nil {
+				m.logger.Errorf("getConnectionStats failed; %v", err)
+				// continue so we still report the rest of the wireless metrics
+			}
+
+			err = getLatencyStats(m.client, network, accessPoints, collectionPeriod)
+			if err != nil {
+				m.logger.Errorf("getLatencyStats failed; %v", err)
+			}
+		}
+
+		reportAccessPointMetrics(reporter, org, networks, accessPoints)
+	}
+
+	return nil
+}
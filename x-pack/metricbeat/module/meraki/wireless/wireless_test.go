@@ -0,0 +1,148 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package wireless
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	mbtest "github.com/elastic/beats/v7/metricbeat/mb/testing"
+)
+
+const (
+	testNetworks = `[
+  {"id": "N_1", "name": "Office", "productTypes": ["appliance", "wireless"]},
+  {"id": "N_2", "name": "Warehouse", "productTypes": ["switch"]}
+]`
+
+	testChannelUtilization = `[
+  {
+    "serial": "Q234-ABCD-0001",
+    "mac": "00:11:22:33:44:55",
+    "network": {"id": "N_1"},
+    "byBand": [
+      {"band": "2.4", "wifi": {"percentage": 45.0}, "nonWifi": {"percentage": 10.0}, "total": {"percentage": 55.0}},
+      {"band": "5", "wifi": {"percentage": 12.5}, "nonWifi": {"percentage": 2.5}, "total": {"percentage": 15.0}}
+    ]
+  }
+]`
+
+	testConnectionStats = `[
+  {"serial": "Q234-ABCD-0001", "connectionStats": {"assoc": 1, "auth": 4, "dhcp": 0, "dns": 2, "success": 120}},
+  {"serial": "Q234-ABCD-0002", "connectionStats": {"assoc": 0, "auth": 0, "dhcp": 0, "dns": 0, "success": 12}}
+]`
+
+	testLatencyStats = `[
+  {
+    "serial": "Q234-ABCD-0001",
+    "latencyStats": {
+      "backgroundTraffic": {"avg": 606.52},
+      "bestEffortTraffic": {"avg": 12.25},
+      "videoTraffic": {"avg": 8.0},
+      "voiceTraffic": {"avg": 3.5}
+    }
+  }
+]`
+)
+
+func newTestServer(t *testing.T, routes map[string]string) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, ok := routes[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"errors": ["Not found"]}`))
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(body))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func getConfig(url string) map[string]interface{} {
+	return map[string]interface{}{
+		"module":        "meraki",
+		"metricsets":    []string{"wireless"},
+		"period":        "300s",
+		"apiBaseURL":    url,
+		"apiKey":        "test-api-key",
+		"organizations": []string{"123"},
+	}
+}
+
+func TestFetch(t *testing.T) {
+	server := newTestServer(t, map[string]string{
+		"/api/v1/organizations/123/networks":                                     testNetworks,
+		"/api/v1/organizations/123/wireless/devices/channelUtilization/byDevice": testChannelUtilization,
+		"/api/v1/networks/N_1/wireless/devices/connectionStats":                  testConnectionStats,
+		"/api/v1/networks/N_1/wireless/devices/latencyStats":                     testLatencyStats,
+	})
+
+	f := mbtest.NewReportingMetricSetV2Error(t, getConfig(server.URL))
+	events, errs := mbtest.ReportingFetchV2Error(f)
+	require.Empty(t, errs)
+	require.Len(t, events, 2)
+
+	bySerial := make(map[string]int)
+	for i, event := range events {
+		serial, err := event.ModuleFields.GetValue("device.serial")
+		require.NoError(t, err)
+		bySerial[serial.(string)] = i
+	}
+
+	ap := events[bySerial["Q234-ABCD-0001"]].ModuleFields
+	assert.Equal(t, "123", ap["organization_id"])
+
+	for field, want := range map[string]interface{}{
+		"device.mac":          "00:11:22:33:44:55",
+		"device.network_id":   "N_1",
+		"device.network_name": "Office",
+		"wireless.channel_utilization.2_4.utilization_80211":     45.0,
+		"wireless.channel_utilization.2_4.utilization_non_80211": 10.0,
+		"wireless.channel_utilization.5.utilization_total":       15.0,
+		"wireless.connection_stats.auth":                         4,
+		"wireless.connection_stats.success":                      120,
+		"wireless.latency.background.avg.ms":                     606.52,
+		"wireless.latency.voice.avg.ms":                          3.5,
+	} {
+		got, err := ap.GetValue(field)
+		require.NoError(t, err, field)
+		switch v := got.(type) {
+		case *float64:
+			got = *v
+		case *int:
+			got = *v
+		}
+		assert.Equal(t, want, got, field)
+	}
+
+	// access points without channel utilization data are still reported
+	other := events[bySerial["Q234-ABCD-0002"]].ModuleFields
+	dhcp, err := other.GetValue("wireless.connection_stats.dhcp")
+	require.NoError(t, err)
+	assert.Equal(t, 0, *dhcp.(*int))
+	_, err = other.GetValue("wireless.latency")
+	assert.Error(t, err)
+}
+
+func TestFetchLatencyStatsError(t *testing.T) {
+	server := newTestServer(t, map[string]string{
+		"/api/v1/organizations/123/networks":                                     testNetworks,
+		"/api/v1/organizations/123/wireless/devices/channelUtilization/byDevice": testChannelUtilization,
+		"/api/v1/networks/N_1/wireless/devices/connectionStats":                  testConnectionStats,
+	})
+
+	// per-network failures are logged and the remaining metrics are still reported
+	f := mbtest.NewReportingMetricSetV2Error(t, getConfig(server.URL))
+	events, errs := mbtest.ReportingFetchV2Error(f)
+	require.Empty(t, errs)
+	assert.Len(t, events, 2)
+}
@@ -19,5 +19,5 @@ func init() {
 // AssetOpenai returns asset data.
 // This is the base64 encoded zlib format compressed contents of module/openai.
 func AssetOpenai() string {
-	return "eJzsWMtu4zgQvPsrGtnL7sHYuw8BjOwDAbKbIMlgjgIttmWOKVJDtuI4Xz+gJNp6UHZsyRMEGB5FsqpZrK6YmcIatzPQGSomJgAkSOIMrsoPVxMAgxKZxRkskNgEgKONjchIaDWD6wkAVLsh1TyXOAFYCpTczoq5KSiWYo3BDdpmOIPE6DyrvgRQmzh1rNyyBHdfQ3ButAv3I0hVjvsM1fwW5g+3JQekSEbEFpjiYImRsCRiW9vTLtGN3+BGp6lWsGAWqxXACIzWBBJfUDaW7xQyCVPijbnCIsEba/wZ17jdaNOea5zovgYDgqMisRRojlO6T6OQdoA8HctEtMbtgMO5m1nj9ti5PNGQI3mqo6cxyFlMePaZHqv9nvEgmUM9l+h5myHo5UGezOhvGNOAK3ooEY5dkScackWeqoHRbcVMolv/582KUdXXnDUiYV9WZ6IvXTrFXLcmAf5FhYbJGqXPk9bSUIjUizL4PUdLNiJNTHaW+RKlVklgslHl/3m6QONc4EEhZbx9A43YNkVX97KG76lDfO+BIGDhPZ9VLLMrHTDgyYxPFVSfE+u0sVaErxSRXqMaQ+dnhwBqp3ZFACUB5LaTF/tiksI3hPxy5ewoqoL6hWHxCnl0eX0KnpZMvVVhykQ//zv98cWi6UFqNV4odU+m8+lbYfbl1V/zu7vp3yBSlxnVNbmuCSXHPrSkDIfoGalVFdD5DXRiZqk8jYpDdG/xvLwqwfbO/Zi07GUtyouseBtslCfxhs0e7dHxozI6t2hGyOei/96RzanmKEfg+8/hhAhbHfh1JWyGBmyGGK+mpKdFJB1qwE25ZawW9BUM7cGLK9cmdE1vMdaKj9X1FZr7uRijPfRX82Pa/sK90LLmM76SM+RTYc2DlqRO0WfbscX6KV0Zr5hxrywzljH3gL+8WXhzzrlwG5gsnzqOw8Lvhm3AkjYswT+CRl1SNOaj6x+hcEq5EioZ8uTSRiRCHbgrvXBPz8B0ORGdJPEj28CyVXhY593/A6wVlpiiKNYcI6EITWaQxvsjNPcMcKM5wu2eYVD3/3xhd1KBkwpqUr1DZ+MOiS9MRpWJx5L30QP77vhkqppO/YWYPwIAAP//Z/rS7Q=="
+	return "eJzsWl1v2zYUffevuKhfNiDB3vMwwEjXoUC6Fk2GPQq0eG1zkUiNvIrr/PqBX7JkU3ZkydsKDPVLTfOccz9FXuUWnnF3B6pCycQMgAQVeAfv/BfvZgAaC2QG72CJxGYAHE2uRUVCyTv4eQYAYTeUitcFzgBWAgtu7tzaLUhWYovBfkm7Cu9grVVdhW8SqF2cNlZt2Bqbb1NwAMfCAU5Q+c/nCuXiIyy+fPQcUCJpkRtgkoMhRsKQyE1rz6FE+28O96oslYQlMxiMAEaglSIo8AWLzs8bD+k1k+KVWc9mgnd+E218xt1W6cO1jkWfWzAgOEoSK4H6PKVVMQnpEVCkY5XInnE3wjgbmWfcnbMrEo0xKVKdtUYjZzkhv5Toa9gfGU+SWdRLiZ52FYJaneSptPoTcxoRoi8e4VyIItGYEEWqDkZnwxw++PIzG6aRw3IHrCig0upFcNSmT5pbvVTWw8OnBgNog6GX5KpEAyutyhtQ0kXCN8UbYJI2WlUiB6WBvdYak8JKxbG4VNUnu7klRxhYqXRsSD2jNEmiwyZ7pqHaz5NFC6RS6ZIVwiAHlmtlTE8o0o21rVHIqqaj1SizUHKdWOwo/a0ul6htGBxWsPoGhMyLmgu5hpzlG+Rpd+yVqJqmk+LBzlF6YRNRtq0HjYy7HHWZ4nhmSQXK0FQJcq8MWdub3LyxmekENNkBGiulScg1CBqYKqxUtaReb3FVLws85y8n0iOBkJDXWqPMd1BLQScCFX6WQD9VuMfskS64Kd+rabZ2MNwZpCrQiv/pfsMoFCBnnbPQXunRwoiA/ooSNStalPEgNTByGv+q0ZDJSBErep04LN8jKJSMYy+1qlC7c9HY0H2OQJB4du/5jGSV2ajEk3cw42OA6nsEt2lzJQm/UebLfwI/P1kEkI23A0HsL7VB3itm7fKGkF9PTkMRBPVqca2PZ9f3j+M5cFOvKiyZKMbmx+8GdQ/SQeFlyZQdSBePnQGz+Xln3xzeLx4ebn8BUdp7VwiTvcKkOkeUyVlR4FRdKwg4uvwN7FmyLjNnhOl13LB+5cH2mdvLHBx8nW7Zy+rkZUa8jk6UR/GK3Rrt8eO/1aNrg3qC/uzq7w292R33J+DzJ/8EYWfjHP7YCFOhBlMh5ptbUreuJaXKIErc+i2zlLoLSjAqGFuDV/fcIaEteoO5ktz0cg6ru4Bm70c5GvOfK/sr10Jn3xye8BvZhHx0qZnMiiiMyEyVjges32VW5hum7XhJm17aYZmxB/w/N11uLjgXdgMr/FXHmmTgB822YEhptsYfZymVK8qmvHR9EBJvqZZCrsdcuZQWayFPxEot7XgvsewXskEu/sq2sDoQ3uyZpfQxY4QhJinLFcdMSEJdaSTUUzlyERngXnGEj3uGUdX/zzu2cRVYV0HLVW/ws7ZG4gsrspDEs5TmC9z7NQLH6vjOvKqP9Pc7cw6LZrK74KWQrZONfaXkxjh+stVFaZvYDIdnKfsuSfBGU1qJbxEDA1HLcGN248RwX+6NyogJpVTUmlIqDVstiFACqZ6ZZVumE5nlGsNrtiuLvUCbte3qus6Neduy/Eh6WjFvG3NvcZkZZDrfZL3P/Mv4DeoXe9MRHGGLS/As508Wdp/IMaPUsQtgUDN59FhgseJc9/DNdpc9DqO2QnK1Hct/H0ZOHg00k2t8m44WzFgR7/f/idyuFQnCspc/VxOPpt5G6ypgWl6LaPoVdHDmsLBvBuMfKYTnpa9iv/JJSUE97/SO3yqOeYK0hbicveyhwbEq1K5ESWM96u9Ee7zj1/Zt3uteS/4eAN6LT0w="
 }
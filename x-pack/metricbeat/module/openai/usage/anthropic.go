@@ -0,0 +1,219 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package usage
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/elastic/beats/v7/metricbeat/mb"
+	"github.com/elastic/elastic-agent-libs/mapstr"
+)
+
+const anthropicVersion = "2023-06-01"
+
+// anthropicProvider collects usage and cost from the usage and cost report
+// endpoints of the Anthropic Admin API, using admin API keys.
+type anthropicProvider struct {
+	config     Config
+	httpClient *RLHTTPClient
+	headers    map[string]string
+}
+
+func newAnthropicProvider(config Config, httpClient *RLHTTPClient) *anthropicProvider {
+	return &anthropicProvider{
+		config:     config,
+		httpClient: httpClient,
+		headers:    processHeaders(config.Headers),
+	}
+}
+
+type anthropicUsageResponse struct {
+	Data []struct {
+		StartingAt time.Time              `json:"starting_at"`
+		Results    []anthropicUsageResult `json:"results"`
+	} `json:"data"`
+	HasMore  bool    `json:"has_more"`
+	NextPage *string `json:"next_page"`
+}
+
+type anthropicUsageResult struct {
+	UncachedInputTokens int64 `json:"uncached_input_tokens"`
+	CacheCreation       struct {
+		Ephemeral1hInputTokens int64 `json:"ephemeral_1h_input_tokens"`
+		Ephemeral5mInputTokens int64 `json:"ephemeral_5m_input_tokens"`
+	} `json:"cache_creation"`
+	CacheReadInputTokens int64 `json:"cache_read_input_tokens"`
+	OutputTokens         int64 `json:"output_tokens"`
+	ServerToolUse        struct {
+		WebSearchRequests int64 `json:"web_search_requests"`
+	} `json:"server_tool_use"`
+	APIKeyID      *string `json:"api_key_id"`
+	WorkspaceID   *string `json:"workspace_id"`
+	Model         *string `json:"model"`
+	ServiceTier   *string `json:"service_tier"`
+	ContextWindow *string `json:"context_window"`
+}
+
+type anthropicCostResponse struct {
+	Data []struct {
+		StartingAt time.Time             `json:"starting_at"`
+		Results    []anthropicCostResult `json:"results"`
+	} `json:"data"`
+	HasMore  bool    `json:"has_more"`
+	NextPage *string `json:"next_page"`
+}
+
+type anthropicCostResult struct {
+	Currency      string  `json:"currency"`
+	Amount        string  `json:"amount"`
+	WorkspaceID   *string `json:"workspace_id"`
+	Description   *string `json:"description"`
+	CostType      *string `json:"cost_type"`
+	ContextWindow *string `json:"context_window"`
+	Model         *string `json:"model"`
+	ServiceTier   *string `json:"service_tier"`
+	TokenType     *string `json:"token_type"`
+}
+
+func (p *anthropicProvider) keys() []string {
+	return apiKeys(p.config)
+}
+
+// fetchDay retrieves the token usage and the cost of a specific date.
+func (p *anthropicProvider) fetchDay(ctx context.Context, apiKey string, date time.Time) ([]mb.Event, error) {
+	query := url.Values{}
+	query.Set("starting_at", date.Format(time.RFC3339))
+	query.Set("ending_at", date.AddDate(0, 0, 1).Format(time.RFC3339))
+	query.Set("bucket_width", "1d")
+	for _, group := range []string{"api_key_id", "workspace_id", "model", "service_tier", "context_window"} {
+		query.Add("group_by[]", group)
+	}
+
+	var events []mb.Event
+	for page := ""; ; {
+		var usageResponse anthropicUsageResponse
+		if err := p.get(ctx, "/usage_report/messages", query, page, apiKey, &usageResponse); err != nil {
+			return nil, fmt.Errorf("error fetching usage report: %w", err)
+		}
+		for _, bucket := range usageResponse.Data {
+			for _, result := range bucket.Results {
+				events = append(events, anthropicUsageEvent(bucket.StartingAt, result))
+			}
+		}
+		if !usageResponse.HasMore || usageResponse.NextPage == nil {
+			break
+		}
+		page = *usageResponse.NextPage
+	}
+
+	query = url.Values{}
+	query.Set("starting_at", date.Format(time.RFC3339))
+	query.Set("ending_at", date.AddDate(0, 0, 1).Format(time.RFC3339))
+	query.Add("group_by[]", "workspace_id")
+	query.Add("group_by[]", "description")
+
+	for page := ""; ; {
+		var costResponse anthropicCostResponse
+		if err := p.get(ctx, "/cost_report", query, page, apiKey, &costResponse); err != nil {
+			return nil, fmt.Errorf("error fetching cost report: %w", err)
+		}
+		for _, bucket := range costResponse.Data {
+			for _, result := range bucket.Results {
+				event, err := anthropicCostEvent(bucket.StartingAt, result)
+				if err != nil {
+					return nil, err
+				}
+				events = append(events, event)
+			}
+		}
+		if !costResponse.HasMore || costResponse.NextPage == nil {
+			break
+		}
+		page = *costResponse.NextPage
+	}
+
+	return events, nil
+}
+
+// get requests a page of a report of the Admin API.
+func (p *anthropicProvider) get(ctx context.Context, endpoint string, query url.Values, page, apiKey string, v any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.config.apiURL()+endpoint, nil)
+	if err != nil {
+		return fmt.Errorf("error creating request: %w", err)
+	}
+
+	q := url.Values{}
+	for k, v := range query {
+		q[k] = v
+	}
+	if page != "" {
+		q.Set("page", page)
+	}
+	req.URL.RawQuery = q.Encode()
+
+	req.Header.Add("x-api-key", apiKey)
+	req.Header.Add("anthropic-version", anthropicVersion)
+	for key, value := range p.headers {
+		req.Header.Add(key, value)
+	}
+
+	return getJSON(p.httpClient, req, p.config.Timeout, v)
+}
+
+func anthropicUsageEvent(timestamp time.Time, result anthropicUsageResult) mb.Event {
+	cacheCreation := result.CacheCreation.Ephemeral1hInputTokens + result.CacheCreation.Ephemeral5mInputTokens
+	input := result.UncachedInputTokens + cacheCreation + result.CacheReadInputTokens
+
+	return mb.Event{
+		Timestamp: timestamp.UTC(),
+		MetricSetFields: mapstr.M{
+			"model":      result.Model,
+			"api_key_id": result.APIKeyID,
+			"project_id": result.WorkspaceID,
+			"tokens":     tokenFields(input, result.OutputTokens, result.CacheReadInputTokens),
+			"anthropic": mapstr.M{
+				"uncached_input_tokens":       result.UncachedInputTokens,
+				"cache_creation_input_tokens": cacheCreation,
+				"cache_read_input_tokens":     result.CacheReadInputTokens,
+				"output_tokens":               result.OutputTokens,
+				"web_search_requests":         result.ServerToolUse.WebSearchRequests,
+				"service_tier":                result.ServiceTier,
+				"context_window":              result.ContextWindow,
+			},
+		},
+	}
+}
+
+func anthropicCostEvent(timestamp time.Time, result anthropicCostResult) (mb.Event, error) {
+	// Amounts are reported in the lowest currency units, as cents.
+	cents, err := strconv.ParseFloat(result.Amount, 64)
+	if err != nil {
+		return mb.Event{}, fmt.Errorf("error parsing cost amount %q: %w", result.Amount, err)
+	}
+
+	return mb.Event{
+		Timestamp: timestamp.UTC(),
+		MetricSetFields: mapstr.M{
+			"model":      result.Model,
+			"project_id": result.WorkspaceID,
+			"cost": mapstr.M{
+				"amount":   cents / 100,
+				"currency": result.Currency,
+			},
+			"anthropic": mapstr.M{
+				"description":    result.Description,
+				"cost_type":      result.CostType,
+				"token_type":     result.TokenType,
+				"service_tier":   result.ServiceTier,
+				"context_window": result.ContextWindow,
+			},
+		},
+	}, nil
+}
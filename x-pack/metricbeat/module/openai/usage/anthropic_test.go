@@ -0,0 +1,218 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+//go:build !integration
+
+package usage
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/time/rate"
+
+	"github.com/elastic/elastic-agent-libs/logp/logptest"
+	"github.com/elastic/elastic-agent-libs/mapstr"
+)
+
+const anthropicAdminKey = "sk-ant-admin01-test"
+
+func TestAnthropicFetchDay(t *testing.T) {
+	server := initAnthropicServer(t)
+	defer server.Close()
+
+	config := defaultConfig()
+	config.Provider = providerAnthropic
+	config.APIURL = server.URL + "/v1/organizations"
+	config.APIKeys = []apiKeyConfig{{Key: anthropicAdminKey}}
+	config.Headers = []string{"X-Test: anthropic"}
+	require.NoError(t, config.Validate())
+
+	p := newProvider(config, newClient(logptest.NewTestingLogger(t, ""), rate.NewLimiter(rate.Inf, 1), config.Timeout))
+	require.Equal(t, []string{anthropicAdminKey}, p.keys())
+
+	date := time.Date(2025, time.August, 1, 0, 0, 0, 0, time.UTC)
+	events, err := p.fetchDay(context.Background(), anthropicAdminKey, date)
+	require.NoError(t, err)
+	require.Len(t, events, 3)
+
+	for _, event := range events {
+		assert.Equal(t, date, event.Timestamp)
+	}
+
+	assert.Equal(t, mapstr.M{
+		"model":      ptr("claude-sonnet-4-20250514"),
+		"api_key_id": ptr("apikey_01"),
+		"project_id": ptr("wrkspc_01"),
+		"tokens": mapstr.M{
+			"input":  int64(3200),
+			"output": int64(500),
+			"cached": int64(200),
+		},
+		"anthropic": mapstr.M{
+			"uncached_input_tokens":       int64(1500),
+			"cache_creation_input_tokens": int64(1500),
+			"cache_read_input_tokens":     int64(200),
+			"output_tokens":               int64(500),
+			"web_search_requests":         int64(10),
+			"service_tier":                ptr("standard"),
+			"context_window":              ptr("0-200k"),
+		},
+	}, events[0].MetricSetFields)
+
+	// Second page of the usage report
+	assert.Equal(t, mapstr.M{
+		"input":  int64(100),
+		"output": int64(20),
+		"cached": int64(0),
+	}, events[1].MetricSetFields["tokens"])
+	assert.Equal(t, ptr("claude-3-5-haiku-20241022"), events[1].MetricSetFields["model"])
+	assert.Equal(t, (*string)(nil), events[1].MetricSetFields["api_key_id"])
+
+	assert.Equal(t, mapstr.M{
+		"model":      ptr("claude-sonnet-4-20250514"),
+		"project_id": ptr("wrkspc_01"),
+		"cost": mapstr.M{
+			"amount":   1.2378912,
+			"currency": "USD",
+		},
+		"anthropic": mapstr.M{
+			"description":    ptr("Claude Sonnet 4 Usage - Input Tokens"),
+			"cost_type":      ptr("tokens"),
+			"token_type":     ptr("uncached_input_tokens"),
+			"service_tier":   ptr("standard"),
+			"context_window": ptr("0-200k"),
+		},
+	}, events[2].MetricSetFields)
+}
+
+func TestAnthropicFetchDayError(t *testing.T) {
+	server := initAnthropicServer(t)
+	defer server.Close()
+
+	config := defaultConfig()
+	config.Provider = providerAnthropic
+	config.APIURL = server.URL + "/v1/organizations"
+	config.APIKeys = []apiKeyConfig{{Key: "sk-ant-REDACTED"}}
+
+	p := newProvider(config, newClient(logptest.NewTestingLogger(t, ""), rate.NewLimiter(rate.Inf, 1), config.Timeout))
+	_, err := p.fetchDay(context.Background(), "sk-ant-REDACTED", time.Date(2025, time.August, 1, 0, 0, 0, 0, time.UTC))
+	require.ErrorContains(t, err, "401 Unauthorized")
+}
+
+func initAnthropicServer(t *testing.T) *httptest.Server {
+	usagePage1 := []byte(`{
+  "data": [
+    {
+      "starting_at": "2025-08-01T00:00:00Z",
+      "ending_at": "2025-08-02T00:00:00Z",
+      "results": [
+        {
+          "uncached_input_tokens": 1500,
+          "cache_creation": {
+            "ephemeral_1h_input_tokens": 1000,
+            "ephemeral_5m_input_tokens": 500
+          },
+          "cache_read_input_tokens": 200,
+          "output_tokens": 500,
+          "server_tool_use": {
+            "web_search_requests": 10
+          },
+          "api_key_id": "apikey_01",
+          "workspace_id": "wrkspc_01",
+          "model": "claude-sonnet-4-20250514",
+          "service_tier": "standard",
+          "context_window": "0-200k"
+        }
+      ]
+    }
+  ],
+  "has_more": true,
+  "next_page": "page_2"
+}`)
+	usagePage2 := []byte(`{
+  "data": [
+    {
+      "starting_at": "2025-08-01T00:00:00Z",
+      "ending_at": "2025-08-02T00:00:00Z",
+      "results": [
+        {
+          "uncached_input_tokens": 100,
+          "cache_creation": {
+            "ephemeral_1h_input_tokens": 0,
+            "ephemeral_5m_input_tokens": 0
+          },
+          "cache_read_input_tokens": 0,
+          "output_tokens": 20,
+          "server_tool_use": {
+            "web_search_requests": 0
+          },
+          "api_key_id": null,
+          "workspace_id": null,
+          "model": "claude-3-5-haiku-20241022",
+          "service_tier": "standard",
+          "context_window": "0-200k"
+        }
+      ]
+    }
+  ],
+  "has_more": false,
+  "next_page": null
+}`)
+	cost := []byte(`{
+  "data": [
+    {
+      "starting_at": "2025-08-01T00:00:00Z",
+      "ending_at": "2025-08-02T00:00:00Z",
+      "results": [
+        {
+          "currency": "USD",
+          "amount": "123.78912",
+          "workspace_id": "wrkspc_01",
+          "description": "Claude Sonnet 4 Usage - Input Tokens",
+          "cost_type": "tokens",
+          "context_window": "0-200k",
+          "model": "claude-sonnet-4-20250514",
+          "service_tier": "standard",
+          "token_type": "uncached_input_tokens"
+        }
+      ]
+    }
+  ],
+  "has_more": false,
+  "next_page": null
+}`)
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("x-api-key") != anthropicAdminKey {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		assert.Equal(t, anthropicVersion, r.Header.Get("anthropic-version"))
+		assert.Equal(t, "anthropic", r.Header.Get("X-Test"))
+		assert.Equal(t, "2025-08-01T00:00:00Z", r.URL.Query().Get("starting_at"))
+		assert.Equal(t, "2025-08-02T00:00:00Z", r.URL.Query().Get("ending_at"))
+
+		switch r.URL.Path {
+		case "/v1/organizations/usage_report/messages":
+			assert.Equal(t, "1d", r.URL.Query().Get("bucket_width"))
+			assert.Equal(t, []string{"api_key_id", "workspace_id", "model", "service_tier", "context_window"}, r.URL.Query()["group_by[]"])
+			if r.URL.Query().Get("page") == "page_2" {
+				_, _ = w.Write(usagePage2)
+				return
+			}
+			_, _ = w.Write(usagePage1)
+		case "/v1/organizations/cost_report":
+			assert.Equal(t, []string{"workspace_id", "description"}, r.URL.Query()["group_by[]"])
+			_, _ = w.Write(cost)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+}
@@ -0,0 +1,188 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package usage
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/clientcredentials"
+
+	"github.com/elastic/beats/v7/metricbeat/mb"
+	"github.com/elastic/elastic-agent-libs/mapstr"
+)
+
+const azureMetricsAPIVersion = "2023-10-01"
+
+// Azure Monitor metrics of Azure OpenAI resources.
+const (
+	azurePromptTokens    = "ProcessedPromptTokens"
+	azureGeneratedTokens = "GeneratedTokens"
+	azureRequests        = "AzureOpenAIRequests"
+)
+
+// azureProvider collects the token usage of Azure OpenAI resources from the
+// Azure Monitor metrics API, authenticating with client credentials.
+type azureProvider struct {
+	config      Config
+	httpClient  *RLHTTPClient
+	headers     map[string]string
+	tokenSource oauth2.TokenSource
+}
+
+func newAzureProvider(config Config, httpClient *RLHTTPClient) *azureProvider {
+	credentials := clientcredentials.Config{
+		ClientID:     config.Azure.ClientID,
+		ClientSecret: config.Azure.ClientSecret,
+		TokenURL:     strings.TrimSuffix(config.Azure.ActiveDirectoryEndpoint, "/") + "/" + config.Azure.TenantID + "/oauth2/v2.0/token",
+		Scopes:       []string{strings.TrimSuffix(config.apiURL(), "/") + "/.default"},
+	}
+	ctx := context.WithValue(context.Background(), oauth2.HTTPClient, httpClient.client)
+
+	return &azureProvider{
+		config:      config,
+		httpClient:  httpClient,
+		headers:     processHeaders(config.Headers),
+		tokenSource: credentials.TokenSource(ctx),
+	}
+}
+
+type azureMetricsResponse struct {
+	Value []struct {
+		Name struct {
+			Value string `json:"value"`
+		} `json:"name"`
+		Timeseries []struct {
+			MetadataValues []struct {
+				Name struct {
+					Value string `json:"value"`
+				} `json:"name"`
+				Value string `json:"value"`
+			} `json:"metadatavalues"`
+			Data []struct {
+				TimeStamp time.Time `json:"timeStamp"`
+				Total     *float64  `json:"total"`
+			} `json:"data"`
+		} `json:"timeseries"`
+	} `json:"value"`
+}
+
+// azureUsage is the usage of a model deployment for a day.
+type azureUsage struct {
+	timestamp  time.Time
+	deployment string
+	model      string
+	values     map[string]int64
+}
+
+func (p *azureProvider) keys() []string {
+	return p.config.Azure.ResourceIDs
+}
+
+// fetchDay retrieves the daily token metrics of each model deployment of an
+// Azure OpenAI resource.
+func (p *azureProvider) fetchDay(ctx context.Context, resourceID string, date time.Time) ([]mb.Event, error) {
+	token, err := p.tokenSource.Token()
+	if err != nil {
+		return nil, fmt.Errorf("error getting Azure access token: %w", err)
+	}
+
+	u := strings.TrimSuffix(p.config.apiURL(), "/") + resourceID + "/providers/Microsoft.Insights/metrics"
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error creating request: %w", err)
+	}
+
+	q := url.Values{}
+	q.Set("api-version", azureMetricsAPIVersion)
+	q.Set("metricnames", strings.Join([]string{azurePromptTokens, azureGeneratedTokens, azureRequests}, ","))
+	q.Set("timespan", date.Format(time.RFC3339)+"/"+date.AddDate(0, 0, 1).Format(time.RFC3339))
+	q.Set("interval", "P1D")
+	q.Set("aggregation", "Total")
+	q.Set("$filter", "ModelDeploymentName eq '*' and ModelName eq '*'")
+	req.URL.RawQuery = q.Encode()
+
+	req.Header.Add("Authorization", "Bearer "+token.AccessToken)
+	for key, value := range p.headers {
+		req.Header.Add(key, value)
+	}
+
+	var metricsResponse azureMetricsResponse
+	if err := getJSON(p.httpClient, req, p.config.Timeout, &metricsResponse); err != nil {
+		return nil, err
+	}
+
+	return azureEvents(resourceID, metricsResponse), nil
+}
+
+// azureEvents joins the metrics of each model deployment and day in a single
+// event.
+func azureEvents(resourceID string, metricsResponse azureMetricsResponse) []mb.Event {
+	usages := map[string]*azureUsage{}
+	for _, metric := range metricsResponse.Value {
+		for _, series := range metric.Timeseries {
+			var deployment, model string
+			for _, metadata := range series.MetadataValues {
+				switch strings.ToLower(metadata.Name.Value) {
+				case "modeldeploymentname":
+					deployment = metadata.Value
+				case "modelname":
+					model = metadata.Value
+				}
+			}
+
+			for _, data := range series.Data {
+				if data.Total == nil {
+					continue
+				}
+				key := deployment + "/" + model + "/" + data.TimeStamp.String()
+				usage, ok := usages[key]
+				if !ok {
+					usage = &azureUsage{
+						timestamp:  data.TimeStamp,
+						deployment: deployment,
+						model:      model,
+						values:     map[string]int64{},
+					}
+					usages[key] = usage
+				}
+				usage.values[metric.Name.Value] += int64(*data.Total)
+			}
+		}
+	}
+
+	keys := make([]string, 0, len(usages))
+	for key := range usages {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	events := make([]mb.Event, 0, len(usages))
+	for _, key := range keys {
+		usage := usages[key]
+		events = append(events, mb.Event{
+			Timestamp: usage.timestamp.UTC(),
+			MetricSetFields: mapstr.M{
+				"model":      usage.model,
+				"project_id": resourceID,
+				"tokens": mapstr.M{
+					"input":  usage.values[azurePromptTokens],
+					"output": usage.values[azureGeneratedTokens],
+				},
+				"azure": mapstr.M{
+					"deployment":     usage.deployment,
+					"requests_total": usage.values[azureRequests],
+				},
+			},
+		})
+	}
+	return events
+}
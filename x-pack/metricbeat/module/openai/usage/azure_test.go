@@ -0,0 +1,204 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+//go:build !integration
+
+package usage
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/time/rate"
+
+	"github.com/elastic/elastic-agent-libs/logp/logptest"
+	"github.com/elastic/elastic-agent-libs/mapstr"
+)
+
+const azureResourceID = "/subscriptions/sub-id/resourceGroups/rg/providers/Microsoft.CognitiveServices/accounts/test-openai"
+
+func TestAzureFetchDay(t *testing.T) {
+	server := initAzureServer(t)
+	defer server.Close()
+
+	config := defaultConfig()
+	config.Provider = providerAzure
+	config.APIURL = server.URL
+	config.Azure = azureConfig{
+		TenantID:                "tenant-id",
+		ClientID:                "client-id",
+		ClientSecret:            "client-secret",
+		ActiveDirectoryEndpoint: server.URL,
+		ResourceIDs:             []string{azureResourceID},
+	}
+	require.NoError(t, config.Validate())
+
+	p := newProvider(config, newClient(logptest.NewTestingLogger(t, ""), rate.NewLimiter(rate.Inf, 1), config.Timeout))
+	require.Equal(t, []string{azureResourceID}, p.keys())
+
+	date := time.Date(2024, time.November, 4, 0, 0, 0, 0, time.UTC)
+	events, err := p.fetchDay(context.Background(), azureResourceID, date)
+	require.NoError(t, err)
+	require.Len(t, events, 2)
+
+	assert.Equal(t, date, events[0].Timestamp)
+	assert.Equal(t, mapstr.M{
+		"model":      "gpt-4o",
+		"project_id": azureResourceID,
+		"tokens": mapstr.M{
+			"input":  int64(118),
+			"output": int64(35),
+		},
+		"azure": mapstr.M{
+			"deployment":     "chat",
+			"requests_total": int64(3),
+		},
+	}, events[0].MetricSetFields)
+
+	assert.Equal(t, date, events[1].Timestamp)
+	assert.Equal(t, mapstr.M{
+		"model":      "gpt-4o-mini",
+		"project_id": azureResourceID,
+		"tokens": mapstr.M{
+			"input":  int64(31),
+			"output": int64(0),
+		},
+		"azure": mapstr.M{
+			"deployment":     "mini",
+			"requests_total": int64(1),
+		},
+	}, events[1].MetricSetFields)
+}
+
+func TestAzureConfigValidate(t *testing.T) {
+	config := defaultConfig()
+	config.Provider = providerAzure
+	config.Azure.ResourceIDs = []string{"test-openai"}
+
+	err := config.Validate()
+	require.Error(t, err)
+	assert.ErrorContains(t, err, "azure.tenant_id must be configured")
+	assert.ErrorContains(t, err, "azure.client_id must be configured")
+	assert.ErrorContains(t, err, "azure.client_secret must be configured")
+	assert.ErrorContains(t, err, "resource ID at position 0 must start with /subscriptions/")
+	assert.NotContains(t, err.Error(), "API key")
+}
+
+func initAzureServer(t *testing.T) *httptest.Server {
+	metrics := []byte(`{
+  "cost": 0,
+  "timespan": "2024-11-04T00:00:00Z/2024-11-05T00:00:00Z",
+  "interval": "P1D",
+  "value": [
+    {
+      "id": "` + azureResourceID + `/providers/Microsoft.Insights/metrics/ProcessedPromptTokens",
+      "type": "Microsoft.Insights/metrics",
+      "name": {"value": "ProcessedPromptTokens", "localizedValue": "Processed Prompt Tokens"},
+      "unit": "Count",
+      "timeseries": [
+        {
+          "metadatavalues": [
+            {"name": {"value": "modeldeploymentname", "localizedValue": "modeldeploymentname"}, "value": "chat"},
+            {"name": {"value": "modelname", "localizedValue": "modelname"}, "value": "gpt-4o"}
+          ],
+          "data": [{"timeStamp": "2024-11-04T00:00:00Z", "total": 118}]
+        },
+        {
+          "metadatavalues": [
+            {"name": {"value": "modeldeploymentname", "localizedValue": "modeldeploymentname"}, "value": "mini"},
+            {"name": {"value": "modelname", "localizedValue": "modelname"}, "value": "gpt-4o-mini"}
+          ],
+          "data": [{"timeStamp": "2024-11-04T00:00:00Z", "total": 31}]
+        }
+      ]
+    },
+    {
+      "id": "` + azureResourceID + `/providers/Microsoft.Insights/metrics/GeneratedTokens",
+      "type": "Microsoft.Insights/metrics",
+      "name": {"value": "GeneratedTokens", "localizedValue": "Generated Completion Tokens"},
+      "unit": "Count",
+      "timeseries": [
+        {
+          "metadatavalues": [
+            {"name": {"value": "modeldeploymentname", "localizedValue": "modeldeploymentname"}, "value": "chat"},
+            {"name": {"value": "modelname", "localizedValue": "modelname"}, "value": "gpt-4o"}
+          ],
+          "data": [{"timeStamp": "2024-11-04T00:00:00Z", "total": 35}]
+        },
+        {
+          "metadatavalues": [
+            {"name": {"value": "modeldeploymentname", "localizedValue": "modeldeploymentname"}, "value": "mini"},
+            {"name": {"value": "modelname", "localizedValue": "modelname"}, "value": "gpt-4o-mini"}
+          ],
+          "data": [{"timeStamp": "2024-11-04T00:00:00Z"}]
+        }
+      ]
+    },
+    {
+      "id": "` + azureResourceID + `/providers/Microsoft.Insights/metrics/AzureOpenAIRequests",
+      "type": "Microsoft.Insights/metrics",
+      "name": {"value": "AzureOpenAIRequests", "localizedValue": "Azure OpenAI Requests"},
+      "unit": "Count",
+      "timeseries": [
+        {
+          "metadatavalues": [
+            {"name": {"value": "modeldeploymentname", "localizedValue": "modeldeploymentname"}, "value": "chat"},
+            {"name": {"value": "modelname", "localizedValue": "modelname"}, "value": "gpt-4o"}
+          ],
+          "data": [{"timeStamp": "2024-11-04T00:00:00Z", "total": 3}]
+        },
+        {
+          "metadatavalues": [
+            {"name": {"value": "modeldeploymentname", "localizedValue": "modeldeploymentname"}, "value": "mini"},
+            {"name": {"value": "modelname", "localizedValue": "modelname"}, "value": "gpt-4o-mini"}
+          ],
+          "data": [{"timeStamp": "2024-11-04T00:00:00Z", "total": 1}]
+        }
+      ]
+    }
+  ]
+}`)
+
+	var serverURL string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/tenant-id/oauth2/v2.0/token":
+			require.NoError(t, r.ParseForm())
+			assert.Equal(t, "client_credentials", r.PostForm.Get("grant_type"))
+			assert.Equal(t, serverURL+"/.default", r.PostForm.Get("scope"))
+			clientID, clientSecret, _ := r.BasicAuth()
+			if clientID == "" {
+				clientID, clientSecret = r.PostForm.Get("client_id"), r.PostForm.Get("client_secret")
+			}
+			if clientID != "client-id" || clientSecret != "client-secret" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"access_token": "access-token", "token_type": "Bearer", "expires_in": 3599}`))
+		case azureResourceID + "/providers/Microsoft.Insights/metrics":
+			if r.Header.Get("Authorization") != "Bearer access-token" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			q := r.URL.Query()
+			assert.Equal(t, azureMetricsAPIVersion, q.Get("api-version"))
+			assert.Equal(t, "ProcessedPromptTokens,GeneratedTokens,AzureOpenAIRequests", q.Get("metricnames"))
+			assert.Equal(t, "2024-11-04T00:00:00Z/2024-11-05T00:00:00Z", q.Get("timespan"))
+			assert.Equal(t, "P1D", q.Get("interval"))
+			assert.Equal(t, "Total", q.Get("aggregation"))
+			assert.Equal(t, "ModelDeploymentName eq '*' and ModelName eq '*'", q.Get("$filter"))
+			_, _ = w.Write(metrics)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	serverURL = server.URL
+	return server
+}
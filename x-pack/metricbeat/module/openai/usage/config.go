@@ -8,16 +8,32 @@ import (
 	"errors"
 	"fmt"
 	"net/url"
+	"strings"
 	"time"
 )
 
+const (
+	providerOpenAI    = "openai"
+	providerAnthropic = "anthropic"
+	providerAzure     = "azure"
+)
+
+// defaultAPIURLs are the API URLs used when api_url is not configured.
+var defaultAPIURLs = map[string]string{
+	providerOpenAI:    "https://api.openai.com/v1/usage",
+	providerAnthropic: "https://api.anthropic.com/v1/organizations",
+	providerAzure:     "https://management.azure.com",
+}
+
 type Config struct {
-	APIKeys    []apiKeyConfig   `config:"api_keys" validate:"required"`
-	APIURL     string           `config:"api_url" validate:"required"`
+	Provider   string           `config:"provider"`
+	APIKeys    []apiKeyConfig   `config:"api_keys"`
+	APIURL     string           `config:"api_url"`
 	Headers    []string         `config:"headers"`
 	RateLimit  *rateLimitConfig `config:"rate_limit"`
 	Timeout    time.Duration    `config:"timeout" validate:"required"`
 	Collection collectionConfig `config:"collection"`
+	Azure      azureConfig      `config:"azure"`
 }
 
 type rateLimitConfig struct {
@@ -29,6 +45,16 @@ type apiKeyConfig struct {
 	Key string `config:"key" validate:"required"`
 }
 
+// azureConfig holds the settings of the azure provider, which collects the
+// usage of Azure OpenAI resources from Azure Monitor.
+type azureConfig struct {
+	TenantID                string   `config:"tenant_id"`
+	ClientID                string   `config:"client_id"`
+	ClientSecret            string   `config:"client_secret"`
+	ActiveDirectoryEndpoint string   `config:"active_directory_endpoint"`
+	ResourceIDs             []string `config:"resource_ids"`
+}
+
 type collectionConfig struct {
 	LookbackDays int  `config:"lookback_days"`
 	Realtime     bool `config:"realtime"`
@@ -36,8 +62,8 @@ type collectionConfig struct {
 
 func defaultConfig() Config {
 	return Config{
-		APIURL:  "https://api.openai.com/v1/usage",
-		Timeout: 30 * time.Second,
+		Provider: providerOpenAI,
+		Timeout:  30 * time.Second,
 		RateLimit: &rateLimitConfig{
 			Limit: ptr(12),
 			Burst: ptr(1),
@@ -46,18 +72,34 @@ func defaultConfig() Config {
 			LookbackDays: 0,     // 0 days
 			Realtime:     false, // avoid realtime collection by default
 		},
+		Azure: azureConfig{
+			ActiveDirectoryEndpoint: "https://login.microsoftonline.com",
+		},
 	}
 }
 
+// apiURL returns the configured API URL or the default one of the provider.
+func (c *Config) apiURL() string {
+	if c.APIURL != "" {
+		return c.APIURL
+	}
+	return defaultAPIURLs[c.Provider]
+}
+
 func (c *Config) Validate() error {
 	var errs []error
 
-	if len(c.APIKeys) == 0 {
-		errs = append(errs, errors.New("at least one API key must be configured"))
+	switch c.Provider {
+	case providerOpenAI, providerAnthropic:
+		if len(c.APIKeys) == 0 {
+			errs = append(errs, errors.New("at least one API key must be configured"))
+		}
+	case providerAzure:
+		errs = append(errs, c.Azure.validate()...)
+	default:
+		errs = append(errs, fmt.Errorf("unknown provider %q, must be one of %s, %s or %s", c.Provider, providerOpenAI, providerAnthropic, providerAzure))
 	}
-	if c.APIURL == "" {
-		errs = append(errs, errors.New("api_url cannot be empty"))
-	} else {
+	if c.APIURL != "" {
 		_, err := url.ParseRequestURI(c.APIURL)
 		if err != nil {
 			errs = append(errs, fmt.Errorf("invalid api_url format: %w", err))
@@ -92,3 +134,28 @@ func (c *Config) Validate() error {
 
 	return nil
 }
+
+func (c *azureConfig) validate() []error {
+	var errs []error
+	if c.TenantID == "" {
+		errs = append(errs, errors.New("azure.tenant_id must be configured"))
+	}
+	if c.ClientID == "" {
+		errs = append(errs, errors.New("azure.client_id must be configured"))
+	}
+	if c.ClientSecret == "" {
+		errs = append(errs, errors.New("azure.client_secret must be configured"))
+	}
+	if _, err := url.ParseRequestURI(c.ActiveDirectoryEndpoint); err != nil {
+		errs = append(errs, fmt.Errorf("invalid azure.active_directory_endpoint format: %w", err))
+	}
+	if len(c.ResourceIDs) == 0 {
+		errs = append(errs, errors.New("at least one Azure OpenAI resource ID must be configured"))
+	}
+	for i, id := range c.ResourceIDs {
+		if !strings.HasPrefix(id, "/subscriptions/") {
+			errs = append(errs, fmt.Errorf("resource ID at position %d must start with /subscriptions/", i))
+		}
+	}
+	return errs
+}
@@ -0,0 +1,238 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package usage
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/elastic/beats/v7/metricbeat/mb"
+	"github.com/elastic/elastic-agent-libs/mapstr"
+)
+
+// openaiProvider collects usage from the OpenAI usage API.
+type openaiProvider struct {
+	config     Config
+	httpClient *RLHTTPClient
+	headers    map[string]string
+}
+
+func newOpenAIProvider(config Config, httpClient *RLHTTPClient) *openaiProvider {
+	return &openaiProvider{
+		config:     config,
+		httpClient: httpClient,
+		headers:    processHeaders(config.Headers),
+	}
+}
+
+func (p *openaiProvider) keys() []string {
+	return apiKeys(p.config)
+}
+
+// fetchDay retrieves usage data for a specific date and API key.
+func (p *openaiProvider) fetchDay(ctx context.Context, apiKey string, date time.Time) ([]mb.Event, error) {
+	req, err := p.createRequest(ctx, date.Format(dateFormatForStateStore), apiKey)
+	if err != nil {
+		return nil, fmt.Errorf("error creating request: %w", err)
+	}
+
+	var usageResponse UsageResponse
+	if err := getJSON(p.httpClient, req, p.config.Timeout, &usageResponse); err != nil {
+		return nil, err
+	}
+
+	return processResponse(usageResponse), nil
+}
+
+// createRequest builds an HTTP request for the OpenAI usage API.
+func (p *openaiProvider) createRequest(ctx context.Context, dateStr, apiKey string) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.config.apiURL(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("error creating request: %w", err)
+	}
+
+	q := req.URL.Query()
+	q.Add("date", dateStr)
+	req.URL.RawQuery = q.Encode()
+
+	req.Header.Add("Authorization", fmt.Sprintf("Bearer %s", apiKey))
+	for key, value := range p.headers {
+		req.Header.Add(key, value)
+	}
+
+	return req, nil
+}
+
+// processResponse processes the usage data of the API response.
+func processResponse(usageResponse UsageResponse) []mb.Event {
+	var events []mb.Event
+	events = append(events, processUsageData(usageResponse.Data)...)
+	events = append(events, processDalleData(usageResponse.DalleApiData)...)
+	events = append(events, processWhisperData(usageResponse.WhisperApiData)...)
+	events = append(events, processTTSData(usageResponse.TtsApiData)...)
+
+	// Process additional data.
+	//
+	// NOTE(shmsr): During testing, could not get the usage data for the following
+	// and found no documentation, example responses, etc. That's why let's store them
+	// as it is so that we can use processors later on to process them as needed.
+	events = append(events, processFTData(usageResponse.FtData)...)
+	events = append(events, processAssistantCodeInterpreterData(usageResponse.AssistantCodeInterpreterData)...)
+	events = append(events, processRetrievalStorageData(usageResponse.RetrievalStorageData)...)
+
+	return events
+}
+
+func getBaseFields(data BaseData) mapstr.M {
+	return mapstr.M{
+		"organization_id":   data.OrganizationID,
+		"organization_name": data.OrganizationName,
+		"api_key_id":        data.ApiKeyID,
+		"api_key_name":      data.ApiKeyName,
+		"api_key_redacted":  data.ApiKeyRedacted,
+		"api_key_type":      data.ApiKeyType,
+		"project_id":        data.ProjectID,
+		"project_name":      data.ProjectName,
+	}
+}
+
+func processUsageData(data []UsageData) []mb.Event {
+	events := make([]mb.Event, 0, len(data))
+	for _, usage := range data {
+		event := mb.Event{
+			Timestamp: time.Unix(usage.AggregationTimestamp, 0).UTC(), // epoch time to time.Time (UTC)
+			MetricSetFields: mapstr.M{
+				"data": mapstr.M{
+					"requests_total":              usage.NRequests,
+					"operation":                   usage.Operation,
+					"snapshot_id":                 usage.SnapshotID,
+					"context_tokens_total":        usage.NContextTokensTotal,
+					"generated_tokens_total":      usage.NGeneratedTokensTotal,
+					"email":                       usage.Email,
+					"request_type":                usage.RequestType,
+					"cached_context_tokens_total": usage.NCachedContextTokensTotal,
+				},
+				"model":  usage.SnapshotID,
+				"tokens": tokenFields(int64(usage.NContextTokensTotal), int64(usage.NGeneratedTokensTotal), int64(usage.NCachedContextTokensTotal)),
+			},
+		}
+		event.MetricSetFields.DeepUpdate(getBaseFields(usage.BaseData))
+		events = append(events, event)
+	}
+	return events
+}
+
+func processDalleData(data []DalleData) []mb.Event {
+	events := make([]mb.Event, 0, len(data))
+	for _, dalle := range data {
+		event := mb.Event{
+			Timestamp: time.Unix(dalle.Timestamp, 0).UTC(), // epoch time to time.Time (UTC)
+			MetricSetFields: mapstr.M{
+				"dalle": mapstr.M{
+					"num_images":     dalle.NumImages,
+					"requests_total": dalle.NumRequests,
+					"image_size":     dalle.ImageSize,
+					"operation":      dalle.Operation,
+					"user_id":        dalle.UserID,
+					"model_id":       dalle.ModelID,
+				},
+				"model": dalle.ModelID,
+			},
+		}
+		event.MetricSetFields.DeepUpdate(getBaseFields(dalle.BaseData))
+		events = append(events, event)
+	}
+	return events
+}
+
+func processWhisperData(data []WhisperData) []mb.Event {
+	events := make([]mb.Event, 0, len(data))
+	for _, whisper := range data {
+		event := mb.Event{
+			Timestamp: time.Unix(whisper.Timestamp, 0).UTC(), // epoch time to time.Time (UTC)
+			MetricSetFields: mapstr.M{
+				"whisper": mapstr.M{
+					"model_id":       whisper.ModelID,
+					"num_seconds":    whisper.NumSeconds,
+					"requests_total": whisper.NumRequests,
+					"user_id":        whisper.UserID,
+				},
+				"model": whisper.ModelID,
+			},
+		}
+		event.MetricSetFields.DeepUpdate(getBaseFields(whisper.BaseData))
+		events = append(events, event)
+	}
+	return events
+}
+
+func processTTSData(data []TtsData) []mb.Event {
+	events := make([]mb.Event, 0, len(data))
+	for _, tts := range data {
+		event := mb.Event{
+			Timestamp: time.Unix(tts.Timestamp, 0).UTC(), // epoch time to time.Time (UTC)
+			MetricSetFields: mapstr.M{
+				"tts": mapstr.M{
+					"model_id":       tts.ModelID,
+					"num_characters": tts.NumCharacters,
+					"requests_total": tts.NumRequests,
+					"user_id":        tts.UserID,
+				},
+				"model": tts.ModelID,
+			},
+		}
+		event.MetricSetFields.DeepUpdate(getBaseFields(tts.BaseData))
+		events = append(events, event)
+	}
+
+	return events
+}
+
+func processFTData(data []interface{}) []mb.Event {
+	events := make([]mb.Event, 0, len(data))
+	for _, ft := range data {
+		event := mb.Event{
+			MetricSetFields: mapstr.M{
+				"ft_data": mapstr.M{
+					"original": ft,
+				},
+			},
+		}
+		events = append(events, event)
+	}
+	return events
+}
+
+func processAssistantCodeInterpreterData(data []interface{}) []mb.Event {
+	events := make([]mb.Event, 0, len(data))
+	for _, aci := range data {
+		event := mb.Event{
+			MetricSetFields: mapstr.M{
+				"assistant_code_interpreter": mapstr.M{
+					"original": aci,
+				},
+			},
+		}
+		events = append(events, event)
+	}
+	return events
+}
+
+func processRetrievalStorageData(data []interface{}) []mb.Event {
+	events := make([]mb.Event, 0, len(data))
+	for _, rs := range data {
+		event := mb.Event{
+			MetricSetFields: mapstr.M{
+				"retrieval_storage": mapstr.M{
+					"original": rs,
+				},
+			},
+		}
+		events = append(events, event)
+	}
+	return events
+}
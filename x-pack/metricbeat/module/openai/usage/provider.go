@@ -0,0 +1,84 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package usage
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/elastic/beats/v7/metricbeat/mb"
+	"github.com/elastic/elastic-agent-libs/mapstr"
+)
+
+// provider collects the usage of an LLM provider API.
+//
+// Besides their provider specific fields, events have the shared fields model,
+// tokens.input, tokens.output, tokens.cached, cost.amount and cost.currency
+// when the provider reports them, and api_key_id and project_id to identify
+// where the usage comes from.
+type provider interface {
+	// keys returns the identifiers the usage is collected for. The last
+	// processed date is stored per key.
+	keys() []string
+	// fetchDay returns the usage events of a key for the day starting at date.
+	fetchDay(ctx context.Context, key string, date time.Time) ([]mb.Event, error)
+}
+
+// newProvider returns the provider of the configuration.
+func newProvider(config Config, httpClient *RLHTTPClient) provider {
+	switch config.Provider {
+	case providerAnthropic:
+		return newAnthropicProvider(config, httpClient)
+	case providerAzure:
+		return newAzureProvider(config, httpClient)
+	default:
+		return newOpenAIProvider(config, httpClient)
+	}
+}
+
+// apiKeys returns the configured API keys.
+func apiKeys(config Config) []string {
+	keys := make([]string, 0, len(config.APIKeys))
+	for _, apiKey := range config.APIKeys {
+		keys = append(keys, apiKey.Key)
+	}
+	return keys
+}
+
+// getJSON executes the request and decodes the JSON response into v.
+func getJSON(httpClient *RLHTTPClient, req *http.Request, timeout time.Duration, v any) error {
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		var netErr net.Error
+		if errors.As(err, &netErr) && netErr.Timeout() {
+			return fmt.Errorf("request timed out with configured timeout: %v and error: %w", timeout, err)
+		}
+		return fmt.Errorf("error making request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("error response from API: status=%s", resp.Status)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("error decoding response: %w", err)
+	}
+	return nil
+}
+
+// tokenFields returns the shared token fields.
+func tokenFields(input, output, cached int64) mapstr.M {
+	return mapstr.M{
+		"input":  input,
+		"output": output,
+		"cached": cached,
+	}
+}
@@ -6,11 +6,7 @@ package usage
 
 import (
 	"context"
-	"encoding/json"
-	"errors"
 	"fmt"
-	"net"
-	"net/http"
 	"path"
 	"time"
 
@@ -20,7 +16,6 @@ import (
 	"github.com/elastic/beats/v7/libbeat/common/cfgwarn"
 	"github.com/elastic/beats/v7/metricbeat/mb"
 	"github.com/elastic/elastic-agent-libs/logp"
-	"github.com/elastic/elastic-agent-libs/mapstr"
 	"github.com/elastic/elastic-agent-libs/paths"
 )
 
@@ -38,12 +33,10 @@ func init() {
 // interface methods except for Fetch.
 type MetricSet struct {
 	mb.BaseMetricSet
-	httpClient   *RLHTTPClient
 	logger       *logp.Logger
 	config       Config
-	report       mb.ReporterV2
+	provider     provider
 	stateManager *stateManager
-	headers      map[string]string
 }
 
 // New creates a new instance of the MetricSet. New is responsible for unpacking
@@ -78,15 +71,15 @@ func New(base mb.BaseMetricSet) (mb.MetricSet, error) {
 
 	return &MetricSet{
 		BaseMetricSet: base,
-		httpClient:    httpClient,
 		logger:        logger,
 		config:        config,
+		provider:      newProvider(config, httpClient),
 		stateManager:  sm,
-		headers:       processHeaders(config.Headers),
 	}, nil
 }
 
-// Fetch collects OpenAI API usage data for the configured time range.
+// Fetch collects API usage data of the configured provider for the configured
+// time range.
 //
 // The collection process:
 // 1. Determines the time range based on realtime/non-realtime configuration
@@ -104,36 +97,36 @@ func (m *MetricSet) Fetch(report mb.ReporterV2) error {
 
 	startDate := endDate.AddDate(0, 0, -m.config.Collection.LookbackDays)
 
-	m.report = report
-	return m.fetchDateRange(startDate, endDate, m.httpClient)
+	return m.fetchDateRange(startDate, endDate, report)
 }
 
-// fetchDateRange retrieves OpenAI API usage data for each configured API key within a date range.
+// fetchDateRange retrieves API usage data for each key of the provider within
+// a date range.
 //
-// For each API key:
+// For each key:
 // 1. Retrieves last processed date from state store
 // 2. Adjusts collection range to avoid duplicates
 // 3. Collects daily usage data
 // 4. Updates state store with latest processed date
 // 5. Handles errors per day without failing entire range
-func (m *MetricSet) fetchDateRange(startDate, endDate time.Time, httpClient *RLHTTPClient) error {
+func (m *MetricSet) fetchDateRange(startDate, endDate time.Time, report mb.ReporterV2) error {
 	g, ctx := errgroup.WithContext(context.TODO())
 
-	for i := range m.config.APIKeys {
-		apiKey := m.config.APIKeys[i]
-		apiKeyIdx := i + 1
+	for i, key := range m.provider.keys() {
+		keyIdx := i + 1
 		g.Go(func() error {
-			lastProcessedDate, err := m.stateManager.GetLastProcessedDate(apiKey.Key)
+			startDate := startDate
+			lastProcessedDate, err := m.stateManager.GetLastProcessedDate(key)
 			if err == nil {
 				currentStartDate := lastProcessedDate.AddDate(0, 0, 1)
 				if currentStartDate.After(endDate) {
-					m.logger.Infof("Skipping API key #%d as current start date (%s) is after end date (%s)", apiKeyIdx, currentStartDate, endDate)
+					m.logger.Infof("Skipping key #%d as current start date (%s) is after end date (%s)", keyIdx, currentStartDate, endDate)
 					return nil
 				}
 				startDate = currentStartDate
 			}
 
-			m.logger.Debugf("Fetching data for API key #%d from %s to %s", apiKeyIdx, startDate, endDate)
+			m.logger.Debugf("Fetching data for key #%d from %s to %s", keyIdx, startDate, endDate)
 
 			for d := startDate; !d.After(endDate); d = d.AddDate(0, 0, 1) {
 				select {
@@ -141,14 +134,19 @@ func (m *MetricSet) fetchDateRange(startDate, endDate time.Time, httpClient *RLH
 					return ctx.Err()
 				default:
 					dateStr := d.Format(dateFormatForStateStore)
-					if err := m.fetchSingleDay(apiKeyIdx, dateStr, apiKey.Key, httpClient); err != nil {
+					events, err := m.provider.fetchDay(ctx, key, d)
+					if err != nil {
 						// If there's an error, log it and continue to the next day.
 						// In this case, we are not saving the state.
-						m.logger.Errorf("Error fetching data (api key #%d) for date %s: %v", apiKeyIdx, dateStr, err)
+						m.logger.Errorf("Error fetching data (key #%d) for date %s: %v", keyIdx, dateStr, err)
 						continue
 					}
-					if err := m.stateManager.SaveState(apiKey.Key, dateStr); err != nil {
-						m.logger.Errorf("Error storing state for API key: %v at index %d", err, apiKeyIdx)
+
+					m.logger.Infof("Fetching usage metrics (key #%d) for date: %s", keyIdx, dateStr)
+					m.processEvents(events, report)
+
+					if err := m.stateManager.SaveState(key, dateStr); err != nil {
+						m.logger.Errorf("Error storing state for key: %v at index %d", err, keyIdx)
 					}
 				}
 			}
@@ -163,225 +161,9 @@ func (m *MetricSet) fetchDateRange(startDate, endDate time.Time, httpClient *RLH
 	return nil
 }
 
-// fetchSingleDay retrieves usage data for a specific date and API key.
-func (m *MetricSet) fetchSingleDay(apiKeyIdx int, dateStr, apiKey string, httpClient *RLHTTPClient) error {
-	req, err := m.createRequest(dateStr, apiKey)
-	if err != nil {
-		return fmt.Errorf("error creating request: %w", err)
-	}
-
-	resp, err := httpClient.Do(req)
-	if err != nil {
-		var netErr net.Error
-		if errors.As(err, &netErr) && netErr.Timeout() {
-			return fmt.Errorf("request timed out with configured timeout: %v and error: %w", m.config.Timeout, err)
-		}
-		return fmt.Errorf("error making request: %w", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("error response from API: status=%s", resp.Status)
-	}
-
-	return m.processResponse(apiKeyIdx, resp, dateStr)
-}
-
-// createRequest builds an HTTP request for the OpenAI usage API.
-func (m *MetricSet) createRequest(dateStr, apiKey string) (*http.Request, error) {
-	req, err := http.NewRequestWithContext(context.TODO(), http.MethodGet, m.config.APIURL, nil)
-	if err != nil {
-		return nil, fmt.Errorf("error creating request: %w", err)
-	}
-
-	q := req.URL.Query()
-	q.Add("date", dateStr)
-	req.URL.RawQuery = q.Encode()
-
-	req.Header.Add("Authorization", fmt.Sprintf("Bearer %s", apiKey))
-	for key, value := range m.headers {
-		req.Header.Add(key, value)
-	}
-
-	return req, nil
-}
-
-// processResponse handles the API response and processes the usage data.
-func (m *MetricSet) processResponse(apiKeyIdx int, resp *http.Response, dateStr string) error {
-	var usageResponse UsageResponse
-	if err := json.NewDecoder(resp.Body).Decode(&usageResponse); err != nil {
-		return fmt.Errorf("error decoding response: %w", err)
-	}
-
-	m.logger.Infof("Fetching usage metrics (api key #%d) for date: %s", apiKeyIdx, dateStr)
-
-	m.processUsageData(usageResponse.Data)
-	m.processDalleData(usageResponse.DalleApiData)
-	m.processWhisperData(usageResponse.WhisperApiData)
-	m.processTTSData(usageResponse.TtsApiData)
-
-	// Process additional data.
-	//
-	// NOTE(shmsr): During testing, could not get the usage data for the following
-	// and found no documentation, example responses, etc. That's why let's store them
-	// as it is so that we can use processors later on to process them as needed.
-	m.processFTData(usageResponse.FtData)
-	m.processAssistantCodeInterpreterData(usageResponse.AssistantCodeInterpreterData)
-	m.processRetrievalStorageData(usageResponse.RetrievalStorageData)
-
-	return nil
-}
-
-func getBaseFields(data BaseData) mapstr.M {
-	return mapstr.M{
-		"organization_id":   data.OrganizationID,
-		"organization_name": data.OrganizationName,
-		"api_key_id":        data.ApiKeyID,
-		"api_key_name":      data.ApiKeyName,
-		"api_key_redacted":  data.ApiKeyRedacted,
-		"api_key_type":      data.ApiKeyType,
-		"project_id":        data.ProjectID,
-		"project_name":      data.ProjectName,
-	}
-}
-
-func (m *MetricSet) processUsageData(data []UsageData) {
-	events := make([]mb.Event, 0, len(data))
-	for _, usage := range data {
-		event := mb.Event{
-			Timestamp: time.Unix(usage.AggregationTimestamp, 0).UTC(), // epoch time to time.Time (UTC)
-			MetricSetFields: mapstr.M{
-				"data": mapstr.M{
-					"requests_total":              usage.NRequests,
-					"operation":                   usage.Operation,
-					"snapshot_id":                 usage.SnapshotID,
-					"context_tokens_total":        usage.NContextTokensTotal,
-					"generated_tokens_total":      usage.NGeneratedTokensTotal,
-					"email":                       usage.Email,
-					"request_type":                usage.RequestType,
-					"cached_context_tokens_total": usage.NCachedContextTokensTotal,
-				},
-			},
-		}
-		event.MetricSetFields.DeepUpdate(getBaseFields(usage.BaseData))
-		events = append(events, event)
-	}
-	m.processEvents(events)
-}
-
-func (m *MetricSet) processDalleData(data []DalleData) {
-	events := make([]mb.Event, 0, len(data))
-	for _, dalle := range data {
-		event := mb.Event{
-			Timestamp: time.Unix(dalle.Timestamp, 0).UTC(), // epoch time to time.Time (UTC)
-			MetricSetFields: mapstr.M{
-				"dalle": mapstr.M{
-					"num_images":     dalle.NumImages,
-					"requests_total": dalle.NumRequests,
-					"image_size":     dalle.ImageSize,
-					"operation":      dalle.Operation,
-					"user_id":        dalle.UserID,
-					"model_id":       dalle.ModelID,
-				},
-			},
-		}
-		event.MetricSetFields.DeepUpdate(getBaseFields(dalle.BaseData))
-		events = append(events, event)
-	}
-	m.processEvents(events)
-}
-
-func (m *MetricSet) processWhisperData(data []WhisperData) {
-	events := make([]mb.Event, 0, len(data))
-	for _, whisper := range data {
-		event := mb.Event{
-			Timestamp: time.Unix(whisper.Timestamp, 0).UTC(), // epoch time to time.Time (UTC)
-			MetricSetFields: mapstr.M{
-				"whisper": mapstr.M{
-					"model_id":       whisper.ModelID,
-					"num_seconds":    whisper.NumSeconds,
-					"requests_total": whisper.NumRequests,
-					"user_id":        whisper.UserID,
-				},
-			},
-		}
-		event.MetricSetFields.DeepUpdate(getBaseFields(whisper.BaseData))
-		events = append(events, event)
-	}
-	m.processEvents(events)
-}
-
-func (m *MetricSet) processTTSData(data []TtsData) {
-	events := make([]mb.Event, 0, len(data))
-	for _, tts := range data {
-		event := mb.Event{
-			Timestamp: time.Unix(tts.Timestamp, 0).UTC(), // epoch time to time.Time (UTC)
-			MetricSetFields: mapstr.M{
-				"tts": mapstr.M{
-					"model_id":       tts.ModelID,
-					"num_characters": tts.NumCharacters,
-					"requests_total": tts.NumRequests,
-					"user_id":        tts.UserID,
-				},
-			},
-		}
-		event.MetricSetFields.DeepUpdate(getBaseFields(tts.BaseData))
-		events = append(events, event)
-	}
-
-	m.processEvents(events)
-}
-
-func (m *MetricSet) processFTData(data []interface{}) {
-	events := make([]mb.Event, 0, len(data))
-	for _, ft := range data {
-		event := mb.Event{
-			MetricSetFields: mapstr.M{
-				"ft_data": mapstr.M{
-					"original": ft,
-				},
-			},
-		}
-		events = append(events, event)
-	}
-	m.processEvents(events)
-}
-
-func (m *MetricSet) processAssistantCodeInterpreterData(data []interface{}) {
-	events := make([]mb.Event, 0, len(data))
-	for _, aci := range data {
-		event := mb.Event{
-			MetricSetFields: mapstr.M{
-				"assistant_code_interpreter": mapstr.M{
-					"original": aci,
-				},
-			},
-		}
-		events = append(events, event)
-	}
-	m.processEvents(events)
-}
-
-func (m *MetricSet) processRetrievalStorageData(data []interface{}) {
-	events := make([]mb.Event, 0, len(data))
-	for _, rs := range data {
-		event := mb.Event{
-			MetricSetFields: mapstr.M{
-				"retrieval_storage": mapstr.M{
-					"original": rs,
-				},
-			},
-		}
-		events = append(events, event)
-	}
-	m.processEvents(events)
-}
-
-func (m *MetricSet) processEvents(events []mb.Event) {
-	if len(events) == 0 {
-		return
-	}
+func (m *MetricSet) processEvents(events []mb.Event, report mb.ReporterV2) {
 	for i := range events {
-		m.report.Event(events[i])
+		_, _ = events[i].MetricSetFields.Put("provider", m.config.Provider)
+		report.Event(events[i])
 	}
 }
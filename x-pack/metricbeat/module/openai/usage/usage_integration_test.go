@@ -54,6 +54,13 @@ func TestFetch(t *testing.T) {
 					"organization_name": "Personal",
 					"project_id":        (*string)(nil),
 					"project_name":      (*string)(nil),
+					"model":             "gpt-4o-realtime-preview-2024-10-01",
+					"provider":          "openai",
+					"tokens": mapstr.M{
+						"input":  int64(118),
+						"output": int64(35),
+						"cached": int64(0),
+					},
 				},
 				Index:             "",
 				ID:                "",
@@ -91,6 +98,13 @@ func TestFetch(t *testing.T) {
 					"organization_name": "Personal",
 					"project_id":        (*string)(nil),
 					"project_name":      (*string)(nil),
+					"model":             "gpt-4o-2024-08-06",
+					"provider":          "openai",
+					"tokens": mapstr.M{
+						"input":  int64(31),
+						"output": int64(12),
+						"cached": int64(0),
+					},
 				},
 				Index:             "",
 				ID:                "",
@@ -128,6 +142,13 @@ func TestFetch(t *testing.T) {
 					"organization_name": "Personal",
 					"project_id":        (*string)(nil),
 					"project_name":      (*string)(nil),
+					"model":             "ft:gpt-3.5-turbo-0125:personal:yay-renew:APjjyG8E:ckpt-step-84",
+					"provider":          "openai",
+					"tokens": mapstr.M{
+						"input":  int64(13),
+						"output": int64(9),
+						"cached": int64(0),
+					},
 				},
 				Index:             "",
 				ID:                "",
@@ -163,6 +184,8 @@ func TestFetch(t *testing.T) {
 					"organization_name": "Personal",
 					"project_id":        ptr("Default Project"),
 					"project_name":      ptr("Default Project"),
+					"model":             "dall-e-3",
+					"provider":          "openai",
 				},
 				Index:             "",
 				ID:                "",
@@ -196,6 +219,8 @@ func TestFetch(t *testing.T) {
 					"organization_name": "Personal",
 					"project_id":        (*string)(nil),
 					"project_name":      (*string)(nil),
+					"model":             "whisper-1",
+					"provider":          "openai",
 				},
 				Index:             "",
 				ID:                "",
@@ -229,6 +254,8 @@ func TestFetch(t *testing.T) {
 					"organization_name": "Personal",
 					"project_id":        ptr("Default Project"),
 					"project_name":      ptr("Default Project"),
+					"model":             "tts-1",
+					"provider":          "openai",
 				},
 				Index:             "",
 				ID:                "",
@@ -262,6 +289,8 @@ func TestFetch(t *testing.T) {
 					"organization_name": "Personal",
 					"project_id":        ptr("proj_fake_id"),
 					"project_name":      ptr("fake_proj"),
+					"model":             "tts-1",
+					"provider":          "openai",
 				},
 				Index:             "",
 				ID:                "",
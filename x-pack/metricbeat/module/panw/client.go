@@ -5,7 +5,6 @@
 package panw
 
 import (
-	"flag"
 	"fmt"
 
 	"github.com/PaloAltoNetworks/pango"
@@ -24,19 +23,24 @@ type PanwFirewallClient struct {
 	pango.Firewall
 }
 
-type PanwTestClient struct {
+type PanwPanoramaClient struct {
+	pango.Panorama
 }
 
-// Op is a mock function for testing that returns sample XML output based on the initial req parameter
-// XML output is stored in the testdata directory, one file per query string
-func (c *PanwTestClient) Op(req interface{}, vsys string, extras, ans interface{}) ([]byte, error) {
-	return nil, nil
-}
+// ClientFactory creates the client used by a metricset to query the PAN-OS XML
+// API. GetPanwClient is the factory used by default.
+type ClientFactory func(config *Config) (PanwClient, error)
 
+// GetPanwClient returns an initialized pango client for the firewall or Panorama
+// instance configured in the module.
 func GetPanwClient(config *Config) (PanwClient, error) {
-	// If running tests, return a test client
-	if flag.Lookup("test.v") != nil {
-		return &PanwTestClient{}, nil
+	if config.Panorama {
+		panorama := pango.Panorama{Client: pango.Client{Hostname: config.HostIp, ApiKey: config.ApiKey, Port: config.Port}}
+		err := panorama.Initialize()
+		if err != nil {
+			return nil, fmt.Errorf("error initializing panorama client: %w", err)
+		}
+		return &PanwPanoramaClient{Panorama: panorama}, nil
 	}
 
 	firewall := pango.Firewall{Client: pango.Client{Hostname: config.HostIp, ApiKey: config.ApiKey, Port: config.Port}}
@@ -17,6 +17,12 @@ type Config struct {
 	ApiKey    string `config:"api_key" validate:"required"`
 	Port      uint   `config:"port"`
 	DebugMode string `config:"api_debug_mode"`
+	// Panorama indicates that HostIp is a Panorama instance. The metricsets
+	// then collect metrics from every firewall managed by Panorama.
+	Panorama bool `config:"panorama"`
+	// Devices optionally restricts collection through Panorama to the
+	// firewalls with these serial numbers.
+	Devices []string `config:"devices"`
 }
 
 func NewConfig(base mb.BaseMetricSet) (*Config, error) {
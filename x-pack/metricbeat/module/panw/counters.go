@@ -0,0 +1,52 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package panw
+
+import (
+	"encoding/xml"
+	"fmt"
+)
+
+// GlobalCountersQuery returns the dataplane global counters. Counters whose
+// value is zero are omitted by the firewall.
+const GlobalCountersQuery = "<show><counter><global></global></counter></show>"
+
+type GlobalCountersResponse struct {
+	Status string               `xml:"status,attr"`
+	Result GlobalCountersResult `xml:"result"`
+}
+
+type GlobalCountersResult struct {
+	Counters []GlobalCounter `xml:"global>counters>entry"`
+}
+
+type GlobalCounter struct {
+	ID          int    `xml:"id"`
+	Name        string `xml:"name"`
+	Value       int64  `xml:"value"`
+	Rate        int64  `xml:"rate"`
+	Severity    string `xml:"severity"`
+	Category    string `xml:"category"`
+	Aspect      string `xml:"aspect"`
+	Description string `xml:"desc"`
+}
+
+// GetGlobalCounters queries and parses the dataplane global counters of the
+// target firewall.
+func GetGlobalCounters(target Target) ([]GlobalCounter, error) {
+	var response GlobalCountersResponse
+
+	output, err := target.Client.Op(GlobalCountersQuery, Vsys, nil, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error querying global counters: %w", err)
+	}
+
+	err = xml.Unmarshal(output, &response)
+	if err != nil {
+		return nil, fmt.Errorf("error unmarshaling global counters: %w", err)
+	}
+
+	return response.Result.Counters, nil
+}
@@ -0,0 +1,115 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package dataplane
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/elastic/beats/v7/libbeat/common/cfgwarn"
+	"github.com/elastic/beats/v7/metricbeat/mb"
+	"github.com/elastic/beats/v7/x-pack/metricbeat/module/panw"
+	"github.com/elastic/elastic-agent-libs/logp"
+)
+
+const (
+	metricsetName = "dataplane"
+	vsys          = ""
+)
+
+// MetricSet holds any configuration or state information. It must implement
+// the mb.MetricSet interface. And this is best achieved by embedding
+// mb.BaseMetricSet because it implements all of the required mb.MetricSet
+// interface methods except for Fetch.
+type MetricSet struct {
+	mb.BaseMetricSet
+	config *panw.Config
+	logger *logp.Logger
+	client panw.PanwClient
+}
+
+// init registers the MetricSet with the central registry as soon as the program
+// starts. The New function will be called later to instantiate an instance of
+// the MetricSet for each host is defined in the module's configuration. After the
+// MetricSet has been created then Fetch will begin to be called periodically.
+func init() {
+	mb.Registry.MustAddMetricSet(panw.ModuleName, metricsetName, New)
+}
+
+// New creates a new instance of the MetricSet. New is responsible for unpacking
+// any MetricSet specific configuration options if there are any.
+func New(base mb.BaseMetricSet) (mb.MetricSet, error) {
+	return NewWithClientFactory(panw.GetPanwClient)(base)
+}
+
+// NewWithClientFactory returns a MetricSetFactory creating metricsets that
+// query PAN-OS through the client returned by newClient.
+func NewWithClientFactory(newClient panw.ClientFactory) mb.MetricSetFactory {
+	return func(base mb.BaseMetricSet) (mb.MetricSet, error) {
+		cfgwarn.Beta("The panw dataplane metricset is beta.")
+
+		config, err := panw.NewConfig(base)
+		if err != nil {
+			return nil, err
+		}
+
+		logger := base.Logger().Named(base.FullyQualifiedName())
+
+		client, err := newClient(config)
+		if err != nil {
+			return nil, err
+		}
+
+		return &MetricSet{
+			BaseMetricSet: base,
+			config:        config,
+			logger:        logger,
+			client:        client,
+		}, nil
+	}
+}
+
+// Fetch method implements the data gathering and data conversion to the right
+// format. It publishes the event which is then forwarded to the output. In case
+// of an error set the Error field of mb.Event or simply call report.Error().
+func (m *MetricSet) Fetch(report mb.ReporterV2) error {
+	// accumulate errs and report them all at the end so that we don't
+	// stop processing events if one of the fetches fails
+	var errs []error
+
+	eventFetchers := []struct {
+		name string
+		fn   func(*MetricSet, panw.Target) ([]mb.Event, error)
+	}{
+		{"resource monitor", getResourceMonitorEvents},
+		{"drop counters", getDropCounterEvents},
+	}
+
+	targets, err := panw.GetTargets(m.config, m.client)
+	if err != nil {
+		return fmt.Errorf("error getting firewalls to query: %w", err)
+	}
+
+	for _, target := range targets {
+		for _, fetcher := range eventFetchers {
+			events, err := fetcher.fn(m, target)
+			if err != nil {
+				m.logger.Errorf("Error getting %s events from %s: %s", fetcher.name, target, err)
+				errs = append(errs, fmt.Errorf("%s: %w", target, err))
+			} else {
+				for _, event := range events {
+					report.Event(event)
+				}
+			}
+		}
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("error while fetching dataplane metrics: %w", errors.Join(errs...))
+	}
+
+	return nil
+
+}
@@ -0,0 +1,117 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package dataplane
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	mbtest "github.com/elastic/beats/v7/metricbeat/mb/testing"
+	"github.com/elastic/beats/v7/x-pack/metricbeat/module/panw"
+	"github.com/elastic/beats/v7/x-pack/metricbeat/module/panw/panwtest"
+	"github.com/elastic/elastic-agent-libs/mapstr"
+)
+
+func newTestServer(t *testing.T) *panwtest.Server {
+	server := panwtest.NewServer(t)
+	server.HandleFile(resourceMonitorQuery, filepath.Join("..", "_meta", "testdata", "resource_monitor.xml"))
+	server.HandleFile(panw.GlobalCountersQuery, filepath.Join("..", "_meta", "testdata", "counter_global.xml"))
+	return server
+}
+
+func newTestMetricSet(t *testing.T) (*MetricSet, panw.Target) {
+	server := newTestServer(t)
+	m, ok := server.NewMetricSet(server.Config(metricsetName), NewWithClientFactory).(*MetricSet)
+	require.True(t, ok)
+	return m, panw.Target{Client: m.client, HostIp: m.config.HostIp}
+}
+
+func TestFetch(t *testing.T) {
+	server := newTestServer(t)
+	f := server.NewMetricSet(server.Config(metricsetName), NewWithClientFactory)
+
+	events, errs := mbtest.ReportingFetchV2Error(f)
+	require.Empty(t, errs)
+	require.Len(t, events, 5)
+}
+
+func TestFetchError(t *testing.T) {
+	server := panwtest.NewServer(t)
+	server.HandleFile(resourceMonitorQuery, filepath.Join("..", "_meta", "testdata", "resource_monitor.xml"))
+	f := server.NewMetricSet(server.Config(metricsetName), NewWithClientFactory)
+
+	// The drop counters fail, but the resource monitor events are still reported.
+	events, errs := mbtest.ReportingFetchV2Error(f)
+	require.Len(t, errs, 1)
+	assert.ErrorContains(t, errs[0], "Unknown command")
+	require.Len(t, events, 2)
+}
+
+func TestResourceMonitorEvents(t *testing.T) {
+	m, target := newTestMetricSet(t)
+
+	events, err := getResourceMonitorEvents(m, target)
+	require.NoError(t, err)
+	require.Len(t, events, 2)
+
+	assert.Equal(t, mapstr.M{
+		"processor": "dp0",
+		"cpu": mapstr.M{
+			"cores":   4,
+			"average": mapstr.M{"pct": 0.25},
+			"maximum": mapstr.M{"pct": 0.72},
+		},
+		"resource": mapstr.M{
+			"session":                   mapstr.M{"pct": 0.25},
+			"packet_buffer":             mapstr.M{"pct": 0.03},
+			"packet_descriptor":         mapstr.M{"pct": 0.02},
+			"packet_descriptor_on_chip": mapstr.M{"pct": 0.04},
+		},
+	}, events[0].MetricSetFields)
+
+	// Only the most recent sample is reported.
+	assert.Equal(t, mapstr.M{
+		"processor": "dp1",
+		"cpu": mapstr.M{
+			"cores":   1,
+			"average": mapstr.M{"pct": 0.1},
+			"maximum": mapstr.M{"pct": 0.15},
+		},
+		"resource": mapstr.M{
+			"session":       mapstr.M{"pct": 0.01},
+			"packet_buffer": mapstr.M{"pct": 0.0},
+		},
+	}, events[1].MetricSetFields)
+}
+
+func TestDropCounterEvents(t *testing.T) {
+	m, target := newTestMetricSet(t)
+
+	events, err := getDropCounterEvents(m, target)
+	require.NoError(t, err)
+	require.Len(t, events, 3)
+
+	assert.Equal(t, mapstr.M{
+		"drop.id":          58,
+		"drop.reason":      "flow_rcv_dot1q_tag_err",
+		"drop.description": "Packets dropped: 802.1q tag not configured",
+		"drop.category":    "flow",
+		"drop.aspect":      "parse",
+		"drop.packets":     int64(1245),
+		"drop.rate":        int64(2),
+	}, events[0].MetricSetFields)
+	assert.Equal(t, "flow_policy_deny", events[1].MetricSetFields["drop.reason"])
+	assert.Equal(t, "ctd_threat_drop", events[2].MetricSetFields["drop.reason"])
+}
+
+func TestResourceFieldName(t *testing.T) {
+	assert.Equal(t, "session", resourceFieldName("session"))
+	assert.Equal(t, "packet_buffer", resourceFieldName("packet buffer"))
+	assert.Equal(t, "packet_descriptor_on_chip", resourceFieldName("packet descriptor (on-chip)"))
+	assert.Equal(t, "", resourceFieldName("()"))
+}
@@ -0,0 +1,44 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package dataplane
+
+import "encoding/xml"
+
+// Dataplane resource monitor
+type ResourceMonitorResponse struct {
+	Status string                `xml:"status,attr"`
+	Result ResourceMonitorResult `xml:"result"`
+}
+
+type ResourceMonitorResult struct {
+	DataProcessors DataProcessors `xml:"resource-monitor>data-processors"`
+}
+
+// DataProcessors holds one element per dataplane processor. The elements are
+// named after the processor, for example dp0 or dp1.
+type DataProcessors struct {
+	Processors []DataProcessor `xml:",any"`
+}
+
+type DataProcessor struct {
+	XMLName xml.Name
+	Second  ResourceSecond `xml:"second"`
+}
+
+type ResourceSecond struct {
+	CPULoadAverage      []CoreValue     `xml:"cpu-load-average>entry"`
+	CPULoadMaximum      []CoreValue     `xml:"cpu-load-maximum>entry"`
+	ResourceUtilization []ResourceValue `xml:"resource-utilization>entry"`
+}
+
+type CoreValue struct {
+	CoreID int    `xml:"coreid"`
+	Value  string `xml:"value"`
+}
+
+type ResourceValue struct {
+	Name  string `xml:"name"`
+	Value string `xml:"value"`
+}
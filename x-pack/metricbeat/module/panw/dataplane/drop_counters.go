@@ -0,0 +1,55 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package dataplane
+
+import (
+	"time"
+
+	"github.com/elastic/beats/v7/metricbeat/mb"
+	"github.com/elastic/beats/v7/x-pack/metricbeat/module/panw"
+	"github.com/elastic/elastic-agent-libs/mapstr"
+)
+
+const dropSeverity = "drop"
+
+func getDropCounterEvents(m *MetricSet, target panw.Target) ([]mb.Event, error) {
+	counters, err := panw.GetGlobalCounters(target)
+	if err != nil {
+		return nil, err
+	}
+
+	return formatDropCounterEvents(m, target, counters), nil
+}
+
+// formatDropCounterEvents creates one event per global counter recording
+// packets dropped by the dataplane, the name of the counter being the reason
+// of the drop.
+func formatDropCounterEvents(m *MetricSet, target panw.Target, counters []panw.GlobalCounter) []mb.Event {
+	events := make([]mb.Event, 0)
+	timestamp := time.Now().UTC()
+
+	for _, counter := range counters {
+		if counter.Severity != dropSeverity {
+			continue
+		}
+
+		event := mb.Event{
+			Timestamp: timestamp,
+			MetricSetFields: mapstr.M{
+				"drop.id":          counter.ID,
+				"drop.reason":      counter.Name,
+				"drop.description": counter.Description,
+				"drop.category":    counter.Category,
+				"drop.aspect":      counter.Aspect,
+				"drop.packets":     counter.Value,
+				"drop.rate":        counter.Rate,
+			},
+			RootFields: target.RootFields(),
+		}
+		events = append(events, event)
+	}
+
+	return events
+}
@@ -0,0 +1,110 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package dataplane
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/elastic/beats/v7/metricbeat/mb"
+	"github.com/elastic/beats/v7/x-pack/metricbeat/module/panw"
+	"github.com/elastic/elastic-agent-libs/mapstr"
+)
+
+// resourceMonitorQuery returns the dataplane utilization over the last second.
+const resourceMonitorQuery = "<show><running><resource-monitor><second><last>1</last></second></resource-monitor></running></show>"
+
+func getResourceMonitorEvents(m *MetricSet, target panw.Target) ([]mb.Event, error) {
+	var response ResourceMonitorResponse
+
+	output, err := target.Client.Op(resourceMonitorQuery, panw.Vsys, nil, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error querying resource monitor: %w", err)
+	}
+
+	err = xml.Unmarshal(output, &response)
+	if err != nil {
+		return nil, fmt.Errorf("error unmarshaling resource monitor: %w", err)
+	}
+
+	return formatResourceMonitorEvents(m, target, &response), nil
+}
+
+func formatResourceMonitorEvents(m *MetricSet, target panw.Target, response *ResourceMonitorResponse) []mb.Event {
+	if response == nil || len(response.Result.DataProcessors.Processors) == 0 {
+		return nil
+	}
+
+	events := make([]mb.Event, 0, len(response.Result.DataProcessors.Processors))
+	timestamp := time.Now().UTC()
+
+	for _, dp := range response.Result.DataProcessors.Processors {
+		fields := mapstr.M{
+			"processor": dp.XMLName.Local,
+		}
+
+		if len(dp.Second.CPULoadAverage) > 0 {
+			var sum int
+			for _, core := range dp.Second.CPULoadAverage {
+				sum += latestValue(m, core.Value)
+			}
+			_, _ = fields.Put("cpu.cores", len(dp.Second.CPULoadAverage))
+			_, _ = fields.Put("cpu.average.pct", float64(sum)/float64(len(dp.Second.CPULoadAverage))/100)
+		}
+
+		if len(dp.Second.CPULoadMaximum) > 0 {
+			var maximum int
+			for _, core := range dp.Second.CPULoadMaximum {
+				maximum = max(maximum, latestValue(m, core.Value))
+			}
+			_, _ = fields.Put("cpu.maximum.pct", float64(maximum)/100)
+		}
+
+		for _, resource := range dp.Second.ResourceUtilization {
+			name := resourceFieldName(resource.Name)
+			if name == "" {
+				continue
+			}
+			_, _ = fields.Put("resource."+name+".pct", float64(latestValue(m, resource.Value))/100)
+		}
+
+		event := mb.Event{
+			Timestamp:       timestamp,
+			MetricSetFields: fields,
+			RootFields:      target.RootFields(),
+		}
+		events = append(events, event)
+	}
+
+	return events
+}
+
+// latestValue returns the most recent sample of a resource monitor value. The
+// samples are comma separated, most recent first.
+func latestValue(m *MetricSet, value string) int {
+	first, _, _ := strings.Cut(value, ",")
+	v, err := strconv.Atoi(strings.TrimSpace(first))
+	if err != nil {
+		m.logger.Debugf("Failed to parse resource monitor value %q: %s", value, err)
+		return 0
+	}
+	return v
+}
+
+// resourceFieldName converts a resource utilization name such as
+// "packet descriptor (on-chip)" into a field name: packet_descriptor_on_chip.
+func resourceFieldName(name string) string {
+	name = strings.ToLower(name)
+	name = strings.Map(func(r rune) rune {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			return r
+		}
+		return ' '
+	}, name)
+	return strings.Join(strings.Fields(name), "_")
+}
@@ -19,5 +19,5 @@ func init() {
 // AssetPanw returns asset data.
 // This is the base64 encoded zlib format compressed contents of module/panw.
 func AssetPanw() string {
-	return "eJzUz0FOBCEQheE9p3iZfV+AnRfQSTwBDm8mZKAgUNhye9NGO21rXNtvSSVffibcOSyKk9kAGjTS4nR2Mp8M4NkuNRQNWSzOD4/T0zNS9j3SAJWRrtHiheoMcA2MvlkDABPEJa7uMh2FFreae/l8+VNfthW3ahBlvboL23r6zV+2j/zant7yfHOprBHf/TvHnKvf3XxIlPbxD62dP4Jr7hrkdpDaNpoyHST2tcg/LX0PAAD//4Q63bA="
+	return "eJzUmV1v2zoPx+/zKYhdt8auc/EAQwc8p8DZFuzttpMlOhEqSzoSnczn0x/Ib3H8Vrd21g0YBtR2+f+RoiiKvYVHzLdgmT5tAEiSwi282TF9erMBEOi5k5ak0VvYvft4++kLpEZkCjcADhUyj1uIkdgGIJGohN9uAABuQbMUG7vhEeUWt7B3JrPVk0nrAJcWO1aNYylrXgxZ7yn8r/UC4F4nxqUsuAYsNhkBHRB2lWWQ2hPTHIuniXR4YkqB9PBPhk6iADo4k+0PEXzSKgePBKcDavhRs/0IH6NmsUIRtZS7PrX9km32s1e9xxNuhX/3O2BCOPQeTDLsVjSoL/AoOT50o3gmecT8ZJx4Hk4jXpovU+AyrjEqo/ceyESbHhQjZhXTuOnSdDm7CTmD731tHKwzHL03DjKSSv5bZYYWYBl/RALhjAVuMk3o/NwVbay+NJwfWYr1Koo+7A0kxgH+ZKlVCMK+jQYxuM0ibhz6jlQZxxD7SYYsjdEFisLGBM64OjuiY3uMLKeOVMngOVMoHhJlWPeDcp9uwaLjqGmK9F2pAne7b6AMExOotS9HdEUuKuYJPHKjxbgXKfsp0yy9shd/yf0BPV14wUZ9eIYLDr3JHMfIo/fS6Cv78aVUAQpF8GJbPTeDGvByLz7EWZKguzL+rtCCUmtV/FrG/CIXznpXcuPB6Ad+kPbK7nzSt0GlqcjruBUKe0d1+Hzp8fxfmZip+lAI8SzJfHFYWBQQ55cslwTDZ0ebTXYPh8mq3QO8F6hJJrKECyQVazSq6JB5owcMTx1Zk8dWJXkDsqTJpd4XL0KQKr1xnpbZpVDvzz/MDgdnhHvj8qXad5WdXlDa53eizAlCWScxTsS8RU5Led4VViZpLHMeA059WowiVVm/KFXPTUZ3D3nZ68M9MUcoxokco3a/uCaORdc7ZGtZGepAwjj6FzerU91ktTKbIaeG113IFHU4hLdALsNN16IzGUm9/0Noq0R8Me3ERaDTq3i2b+pXnXU3sC/qvcrLawE6OEpHGVPgc0+YRjMDU2gMhqXryJiltrWU/ey9e06mfyh7WtBNxldh9uAza42j8zlWhyIapWGc5BEXAZ23Xmmsrj9+XJW4XVfy691uhmwmVpb99n6OrOTpyrr3dx/mCKeZIsmZp3XVG7MzEGJnmFgfoTE7A8E6FJKvBVBZQzFDuZjeKIViJe1a8Wz4RYdtq/mO7ERgJi4BT18Eem58DoKtJWycIVPwp6N1LdoMeVEN9GxGq5Vmbv1oMGatFJ6AG62Rh5/9YOvR1XyMF4p+beIAUsOjVCaWNE/bLpTeVa3WmFatc/S5X22RFl6xvl+0AFW2jYeIZ84NJ/dLNnA39auOZbwtuYSptsgimLpxUjKVNAxwA2/L4Ximi6+maknYhURXKHGNYYiRs8zjAGi4jPIDCggJX/0mFMh/WvHzT69K9y8XA+E4MA9sLBB1AMpvN09txd/jBkEHh4xeDDtxgbgzmlBTpQACqazaxV3h2+e/IZGK0IXZx/5ibOSjmdGovh+MRteBMVsXschtN7RPhbcXg6+57c95UNKhmKoXkQhDR6ceGvejUaBfP+wK/y8NwcCoa1yw9ZtLdX/Dada4sMcjOkmLhb9UdiYHV1InJsytwsTmVeZo46JHpjLsvX1Oln8PFjp6rzMi+8yoBzLUuTW+W715qm69zinx3wA1e/9h"
 }
@@ -18,12 +18,12 @@ const haInterfaceQuery = "<show><high-availability><all></all></high-availabilit
 
 var haLogger *logp.Logger
 
-func getHAInterfaceEvents(m *MetricSet) ([]mb.Event, error) {
+func getHAInterfaceEvents(m *MetricSet, target panw.Target) ([]mb.Event, error) {
 	// Set logger so all the parse functions have access
 	haLogger = m.logger
 	var response HAResponse
 
-	output, err := m.client.Op(haInterfaceQuery, panw.Vsys, nil, nil)
+	output, err := target.Client.Op(haInterfaceQuery, panw.Vsys, nil, nil)
 	if err != nil {
 		haLogger.Error("Error: %s", err)
 		return nil, err
@@ -35,25 +35,25 @@ func getHAInterfaceEvents(m *MetricSet) ([]mb.Event, error) {
 		return nil, err
 	}
 
-	events := formatHAInterfaceEvents(m, response.Result)
+	events := formatHAInterfaceEvents(m, target, response.Result)
 
 	return events, nil
 
 }
 
-func formatHAInterfaceEvents(m *MetricSet, input HAResult) []mb.Event {
+func formatHAInterfaceEvents(m *MetricSet, target panw.Target, input HAResult) []mb.Event {
 	events := make([]mb.Event, 0, len(input.Group.LinkMonitoring.Groups)+1)
 	group := input.Group
 
-	groupEvent := makeGroupEvent(m, input)
+	groupEvent := makeGroupEvent(m, target, input)
 	events = append(events, *groupEvent)
-	linkMonitorEvents := makeLinkMonitoringEvents(m, group.LinkMonitoring)
+	linkMonitorEvents := makeLinkMonitoringEvents(m, target, group.LinkMonitoring)
 	events = append(events, linkMonitorEvents...)
 
 	return events
 }
 
-func makeGroupEvent(m *MetricSet, input HAResult) *mb.Event {
+func makeGroupEvent(m *MetricSet, target panw.Target, input HAResult) *mb.Event {
 
 	group := input.Group
 	timestamp := time.Now().UTC()
@@ -129,13 +129,13 @@ func makeGroupEvent(m *MetricSet, input HAResult) *mb.Event {
 			"ha.peer_info.conn_ha1_backup.description": group.PeerInfo.ConnHA1Backup.Desc,
 			"ha.link_monitoring.enabled":               linkMonitoringEnabled,
 		},
-		RootFields: panw.MakeRootFields(m.config.HostIp),
+		RootFields: target.RootFields(),
 	}
 
 	return &event
 }
 
-func makeLinkMonitoringEvents(m *MetricSet, links HALinkMonitoring) []mb.Event {
+func makeLinkMonitoringEvents(m *MetricSet, target panw.Target, links HALinkMonitoring) []mb.Event {
 	if len(links.Groups) == 0 {
 		return nil
 	}
@@ -170,7 +170,7 @@ func makeLinkMonitoringEvents(m *MetricSet, links HALinkMonitoring) []mb.Event {
 					"ha.link_monitoring.group.interface.name":    interface_entry.Name,
 					"ha.link_monitoring.group.interface.status":  interface_entry.Status,
 				},
-				RootFields: panw.MakeRootFields(m.config.HostIp),
+				RootFields: target.RootFields(),
 			}
 		}
 
@@ -26,11 +26,11 @@ var interfaceTypes = map[int]string{
 
 const IFNetInterfaceQuery = "<show><interface>all</interface></show>"
 
-func getIFNetInterfaceEvents(m *MetricSet) ([]mb.Event, error) {
+func getIFNetInterfaceEvents(m *MetricSet, target panw.Target) ([]mb.Event, error) {
 
 	var response InterfaceResponse
 
-	output, err := m.client.Op(IFNetInterfaceQuery, panw.Vsys, nil, nil)
+	output, err := target.Client.Op(IFNetInterfaceQuery, panw.Vsys, nil, nil)
 	if err != nil {
 		m.logger.Error("Error: %s", err)
 		return nil, err
@@ -42,13 +42,13 @@ func getIFNetInterfaceEvents(m *MetricSet) ([]mb.Event, error) {
 		return nil, err
 	}
 
-	events := formatIFInterfaceEvents(m, response.Result)
+	events := formatIFInterfaceEvents(m, target, response.Result)
 
 	return events, nil
 
 }
 
-func formatIFInterfaceEvents(m *MetricSet, input InterfaceResult) []mb.Event {
+func formatIFInterfaceEvents(m *MetricSet, target panw.Target, input InterfaceResult) []mb.Event {
 	events := make([]mb.Event, 0, len(input.HW.Entries)+len(input.Ifnet.Entries))
 	timestamp := time.Now().UTC()
 
@@ -81,7 +81,7 @@ func formatIFInterfaceEvents(m *MetricSet, input InterfaceResult) []mb.Event {
 				"physical.full_state": entry.ST,
 				"physical.ae_member":  members,
 			},
-			RootFields: panw.MakeRootFields(m.config.HostIp),
+			RootFields: target.RootFields(),
 		}
 
 		events = append(events, event)
@@ -103,7 +103,7 @@ func formatIFInterfaceEvents(m *MetricSet, input InterfaceResult) []mb.Event {
 				"logical.dyn_addr": entry.DynAddr,
 				"logical.addr6":    entry.Addr6,
 			},
-			RootFields: panw.MakeRootFields(m.config.HostIp),
+			RootFields: target.RootFields(),
 		}
 
 		events = append(events, event)
@@ -0,0 +1,66 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package interfaces
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/elastic/beats/v7/metricbeat/mb"
+	mbtest "github.com/elastic/beats/v7/metricbeat/mb/testing"
+	"github.com/elastic/beats/v7/x-pack/metricbeat/module/panw/panwtest"
+)
+
+func newTestServer(t *testing.T) *panwtest.Server {
+	server := panwtest.NewServer(t)
+	server.HandleFile(IFNetInterfaceQuery, filepath.Join("..", "_meta", "testdata", "interfaces_all.xml"))
+	server.HandleFile(haInterfaceQuery, filepath.Join("..", "_meta", "testdata", "high_availability.xml"))
+	server.HandleFile(IPSecTunnelsQuery, filepath.Join("..", "_meta", "testdata", "vpn_tunnels.xml"))
+	return server
+}
+
+// eventsWith returns the events having the given metricset field.
+func eventsWith(events []mb.Event, field string) []mb.Event {
+	var matching []mb.Event
+	for _, event := range events {
+		if _, ok := event.MetricSetFields[field]; ok {
+			matching = append(matching, event)
+		}
+	}
+	return matching
+}
+
+func TestFetch(t *testing.T) {
+	server := newTestServer(t)
+	f := server.NewMetricSet(server.Config(metricsetName), NewWithClientFactory)
+
+	events, errs := mbtest.ReportingFetchV2Error(f)
+	require.Empty(t, errs)
+	require.Len(t, events, 7)
+
+	physical := eventsWith(events, "physical.name")
+	require.Len(t, physical, 2)
+	assert.Equal(t, "ae1", physical[1].MetricSetFields["physical.name"])
+
+	logical := eventsWith(events, "logical.name")
+	require.Len(t, logical, 2)
+	assert.Equal(t, "trust", logical[1].MetricSetFields["logical.zone"])
+
+	ha := eventsWith(events, "ha.mode")
+	require.Len(t, ha, 1)
+	assert.Equal(t, true, ha[0].MetricSetFields["ha.enabled"])
+	assert.Equal(t, "passive", ha[0].MetricSetFields["ha.peer_info.state"])
+
+	links := eventsWith(events, "ha.link_monitoring.group.name")
+	require.Len(t, links, 1)
+	assert.Equal(t, "ethernet1/1", links[0].MetricSetFields["ha.link_monitoring.group.interface.name"])
+
+	tunnels := eventsWith(events, "ipsec_tunnel.name")
+	require.Len(t, tunnels, 1)
+	assert.Equal(t, "site-b", tunnels[0].MetricSetFields["ipsec_tunnel.gw"])
+}
@@ -16,11 +16,11 @@ import (
 
 const IPSecTunnelsQuery = "<show><vpn><tunnel></tunnel></vpn></show>"
 
-func getIPSecTunnelEvents(m *MetricSet) ([]mb.Event, error) {
+func getIPSecTunnelEvents(m *MetricSet, target panw.Target) ([]mb.Event, error) {
 
 	var response TunnelsResponse
 
-	output, err := m.client.Op(IPSecTunnelsQuery, panw.Vsys, nil, nil)
+	output, err := target.Client.Op(IPSecTunnelsQuery, panw.Vsys, nil, nil)
 	if err != nil {
 		m.logger.Error("Error: %s", err)
 		return nil, fmt.Errorf("error querying IPSec tunnels: %w", err)
@@ -32,13 +32,13 @@ func getIPSecTunnelEvents(m *MetricSet) ([]mb.Event, error) {
 		return nil, fmt.Errorf("error unmarshaling IPSec tunnels response: %w", err)
 	}
 
-	events := formatIPSecTunnelEvents(m, response.Result.Entries)
+	events := formatIPSecTunnelEvents(m, target, response.Result.Entries)
 
 	return events, nil
 
 }
 
-func formatIPSecTunnelEvents(m *MetricSet, entries []TunnelsEntry) []mb.Event {
+func formatIPSecTunnelEvents(m *MetricSet, target panw.Target, entries []TunnelsEntry) []mb.Event {
 	if entries == nil {
 		return nil
 	}
@@ -69,7 +69,7 @@ func formatIPSecTunnelEvents(m *MetricSet, entries []TunnelsEntry) []mb.Event {
 				"ipsec_tunnel.life.sec":   entry.Life,
 				"ipsec_tunnel.kb":         entry.KB,
 			},
-			RootFields: panw.MakeRootFields(m.config.HostIp),
+			RootFields: target.RootFields(),
 		}
 
 		events = append(events, event)
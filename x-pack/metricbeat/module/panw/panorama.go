@@ -0,0 +1,148 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package panw
+
+import (
+	"encoding/xml"
+	"fmt"
+	"net/url"
+	"slices"
+
+	"github.com/elastic/elastic-agent-libs/mapstr"
+)
+
+const (
+	connectedDevicesQuery = "<show><devices><connected></connected></devices></show>"
+	deviceGroupsQuery     = "<show><devicegroups></devicegroups></show>"
+)
+
+// Device is a firewall managed by Panorama.
+type Device struct {
+	Serial      string
+	Hostname    string
+	IPAddress   string
+	DeviceGroup string
+}
+
+// Target is a firewall a metricset collects metrics from. When Device is set
+// the firewall is reached through Panorama.
+type Target struct {
+	Client PanwClient
+	HostIp string
+	Device *Device
+	// PanoramaIp is the address of the Panorama instance proxying the requests.
+	PanoramaIp string
+}
+
+// RootFields returns the root fields of the events collected from the target.
+func (t Target) RootFields() mapstr.M {
+	fields := MakeRootFields(t.HostIp)
+	if t.Device != nil {
+		fields["observer.serial_number"] = t.Device.Serial
+		fields["observer.hostname"] = t.Device.Hostname
+		fields["panw.panorama.device_group"] = t.Device.DeviceGroup
+		fields["panw.panorama.ip"] = t.PanoramaIp
+	}
+	return fields
+}
+
+// String identifies the target in log and error messages.
+func (t Target) String() string {
+	if t.Device != nil {
+		return fmt.Sprintf("%s (%s)", t.Device.Hostname, t.Device.Serial)
+	}
+	return t.HostIp
+}
+
+// GetTargets returns the firewalls to collect metrics from. Without Panorama
+// this is the configured firewall, otherwise it is every connected firewall
+// managed by Panorama, optionally restricted to config.Devices.
+func GetTargets(config *Config, client PanwClient) ([]Target, error) {
+	if !config.Panorama {
+		return []Target{{Client: client, HostIp: config.HostIp}}, nil
+	}
+
+	devices, err := GetPanoramaDevices(client)
+	if err != nil {
+		return nil, err
+	}
+
+	targets := make([]Target, 0, len(devices))
+	for i := range devices {
+		device := &devices[i]
+		if len(config.Devices) > 0 && !slices.Contains(config.Devices, device.Serial) {
+			continue
+		}
+		targets = append(targets, Target{
+			Client:     &panoramaDeviceClient{client: client, serial: device.Serial},
+			HostIp:     device.IPAddress,
+			Device:     device,
+			PanoramaIp: config.HostIp,
+		})
+	}
+	return targets, nil
+}
+
+// GetPanoramaDevices returns the firewalls connected to Panorama along with
+// the device group they belong to.
+func GetPanoramaDevices(client PanwClient) ([]Device, error) {
+	var devicesResponse ConnectedDevicesResponse
+	output, err := client.Op(connectedDevicesQuery, Vsys, nil, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error querying panorama connected devices: %w", err)
+	}
+	if err = xml.Unmarshal(output, &devicesResponse); err != nil {
+		return nil, fmt.Errorf("error unmarshaling panorama connected devices: %w", err)
+	}
+
+	var groupsResponse DeviceGroupsResponse
+	output, err = client.Op(deviceGroupsQuery, Vsys, nil, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error querying panorama device groups: %w", err)
+	}
+	if err = xml.Unmarshal(output, &groupsResponse); err != nil {
+		return nil, fmt.Errorf("error unmarshaling panorama device groups: %w", err)
+	}
+
+	groups := make(map[string]string)
+	for _, group := range groupsResponse.Result.DeviceGroups {
+		for _, device := range group.Devices {
+			groups[device.Serial] = group.Name
+		}
+	}
+
+	devices := make([]Device, 0, len(devicesResponse.Result.Devices))
+	for _, entry := range devicesResponse.Result.Devices {
+		devices = append(devices, Device{
+			Serial:      entry.Serial,
+			Hostname:    entry.Hostname,
+			IPAddress:   entry.IPAddress,
+			DeviceGroup: groups[entry.Serial],
+		})
+	}
+	return devices, nil
+}
+
+// panoramaDeviceClient sends operational commands to a firewall through
+// Panorama by setting the target parameter to the firewall serial number.
+type panoramaDeviceClient struct {
+	client PanwClient
+	serial string
+}
+
+func (c *panoramaDeviceClient) Op(req interface{}, vsys string, extras, ans interface{}) ([]byte, error) {
+	values := url.Values{}
+	switch extras := extras.(type) {
+	case nil:
+	case url.Values:
+		for k, v := range extras {
+			values[k] = v
+		}
+	default:
+		return nil, fmt.Errorf("unsupported extras type %T for panorama device %s", extras, c.serial)
+	}
+	values.Set("target", c.serial)
+	return c.client.Op(req, vsys, values, ans)
+}
@@ -0,0 +1,101 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package panw
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testConnectedDevices = `<response status="success"><result><devices>
+  <entry name="007051000000001"><serial>007051000000001</serial><hostname>fw-east</hostname><ip-address>10.0.0.1</ip-address><connected>yes</connected></entry>
+  <entry name="007051000000002"><serial>007051000000002</serial><hostname>fw-west</hostname><ip-address>10.0.0.2</ip-address><connected>yes</connected></entry>
+</devices></result></response>`
+
+	testDeviceGroups = `<response status="success"><result><devicegroups>
+  <entry name="branches"><devices><entry name="007051000000001"><serial>007051000000001</serial></entry></devices></entry>
+  <entry name="empty"></entry>
+</devicegroups></result></response>`
+)
+
+type recordedOp struct {
+	req    interface{}
+	extras interface{}
+}
+
+type fakePanoramaClient struct {
+	ops []recordedOp
+}
+
+func (c *fakePanoramaClient) Op(req interface{}, _ string, extras, _ interface{}) ([]byte, error) {
+	c.ops = append(c.ops, recordedOp{req: req, extras: extras})
+	switch req {
+	case connectedDevicesQuery:
+		return []byte(testConnectedDevices), nil
+	case deviceGroupsQuery:
+		return []byte(testDeviceGroups), nil
+	}
+	return []byte(`<response status="success"><result></result></response>`), nil
+}
+
+func TestGetTargets(t *testing.T) {
+	t.Run("firewall", func(t *testing.T) {
+		client := &fakePanoramaClient{}
+		targets, err := GetTargets(&Config{HostIp: "192.0.2.1"}, client)
+		require.NoError(t, err)
+		require.Len(t, targets, 1)
+		assert.Same(t, client, targets[0].Client)
+		assert.Equal(t, MakeRootFields("192.0.2.1"), targets[0].RootFields())
+		assert.Empty(t, client.ops)
+	})
+
+	t.Run("panorama", func(t *testing.T) {
+		client := &fakePanoramaClient{}
+		targets, err := GetTargets(&Config{HostIp: "192.0.2.1", Panorama: true}, client)
+		require.NoError(t, err)
+		require.Len(t, targets, 2)
+
+		assert.Equal(t, "fw-east (007051000000001)", targets[0].String())
+		fields := targets[0].RootFields()
+		assert.Equal(t, "10.0.0.1", fields["observer.ip"])
+		assert.Equal(t, "007051000000001", fields["observer.serial_number"])
+		assert.Equal(t, "fw-east", fields["observer.hostname"])
+		assert.Equal(t, "branches", fields["panw.panorama.device_group"])
+		assert.Equal(t, "192.0.2.1", fields["panw.panorama.ip"])
+
+		assert.Equal(t, "", targets[1].RootFields()["panw.panorama.device_group"])
+
+		_, err = targets[1].Client.Op("<show><system><info></info></system></show>", Vsys, nil, nil)
+		require.NoError(t, err)
+		last := client.ops[len(client.ops)-1]
+		assert.Equal(t, url.Values{"target": {"007051000000002"}}, last.extras)
+	})
+
+	t.Run("panorama devices filter", func(t *testing.T) {
+		client := &fakePanoramaClient{}
+		targets, err := GetTargets(&Config{HostIp: "192.0.2.1", Panorama: true, Devices: []string{"007051000000002"}}, client)
+		require.NoError(t, err)
+		require.Len(t, targets, 1)
+		assert.Equal(t, "fw-west", targets[0].Device.Hostname)
+	})
+}
+
+func TestPanoramaDeviceClientExtras(t *testing.T) {
+	client := &fakePanoramaClient{}
+	device := &panoramaDeviceClient{client: client, serial: "007051000000001"}
+
+	extras := url.Values{"vsys": {"vsys2"}}
+	_, err := device.Op("<show></show>", Vsys, extras, nil)
+	require.NoError(t, err)
+	assert.Equal(t, url.Values{"vsys": {"vsys2"}, "target": {"007051000000001"}}, client.ops[0].extras)
+	assert.Equal(t, url.Values{"vsys": {"vsys2"}}, extras, "caller extras must not be modified")
+
+	_, err = device.Op("<show></show>", Vsys, map[string]string{}, nil)
+	assert.ErrorContains(t, err, "unsupported extras type")
+}
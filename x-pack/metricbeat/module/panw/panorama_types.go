@@ -0,0 +1,35 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package panw
+
+type ConnectedDevicesResponse struct {
+	Status string                 `xml:"status,attr"`
+	Result ConnectedDevicesResult `xml:"result"`
+}
+
+type ConnectedDevicesResult struct {
+	Devices []PanoramaDevice `xml:"devices>entry"`
+}
+
+type PanoramaDevice struct {
+	Serial    string `xml:"serial"`
+	Hostname  string `xml:"hostname"`
+	IPAddress string `xml:"ip-address"`
+	Connected string `xml:"connected"`
+}
+
+type DeviceGroupsResponse struct {
+	Status string             `xml:"status,attr"`
+	Result DeviceGroupsResult `xml:"result"`
+}
+
+type DeviceGroupsResult struct {
+	DeviceGroups []DeviceGroup `xml:"devicegroups>entry"`
+}
+
+type DeviceGroup struct {
+	Name    string           `xml:"name,attr"`
+	Devices []PanoramaDevice `xml:"devices>entry"`
+}
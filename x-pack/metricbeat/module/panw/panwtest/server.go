@@ -0,0 +1,219 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+// Package panwtest provides a fake PAN-OS XML API server that can be used to
+// test the panw metricsets end-to-end through the pango client.
+package panwtest
+
+import (
+	"fmt"
+	"html"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/elastic/beats/v7/metricbeat/mb"
+	mbtest "github.com/elastic/beats/v7/metricbeat/mb/testing"
+	"github.com/elastic/beats/v7/x-pack/metricbeat/module/panw"
+)
+
+// APIKey is the API key accepted by the fake server.
+const APIKey = "panwtest-api-key"
+
+// SystemInfoQuery is the operational command issued by pango when the client
+// is initialized. The server answers it by default.
+const SystemInfoQuery = "<show><system><info></info></system></show>"
+
+const defaultSystemInfo = `<response status="success">
+  <result>
+    <system>
+      <hostname>PA-VM</hostname>
+      <ip-address>127.0.0.1</ip-address>
+      <model>PA-VM</model>
+      <serial>007054000012345</serial>
+      <sw-version>10.2.0</sw-version>
+      <multi-vsys>off</multi-vsys>
+    </system>
+  </result>
+</response>`
+
+type handlerKey struct {
+	target string
+	cmd    string
+}
+
+// Server is a fake PAN-OS XML API. It answers operational commands with the
+// responses registered for them and records every request it receives.
+type Server struct {
+	*httptest.Server
+
+	t testing.TB
+
+	mu        sync.Mutex
+	responses map[handlerKey]string
+	requests  []url.Values
+}
+
+// NewServer starts a new fake PAN-OS XML API server. The server is closed when
+// the test finishes.
+func NewServer(t testing.TB) *Server {
+	t.Helper()
+
+	s := &Server{
+		t: t,
+		responses: map[handlerKey]string{
+			{cmd: SystemInfoQuery}: defaultSystemInfo,
+		},
+	}
+	s.Server = httptest.NewTLSServer(http.HandlerFunc(s.serveHTTP))
+	t.Cleanup(s.Close)
+
+	return s
+}
+
+// Handle registers the response body returned for the operational command cmd.
+func (s *Server) Handle(cmd, body string) {
+	s.HandleTarget("", cmd, body)
+}
+
+// HandleFile registers the content of the file at path as the response body
+// returned for the operational command cmd.
+func (s *Server) HandleFile(cmd, path string) {
+	s.t.Helper()
+
+	body, err := os.ReadFile(path)
+	if err != nil {
+		s.t.Fatalf("failed to read response file %s: %v", path, err)
+	}
+	s.Handle(cmd, string(body))
+}
+
+// HandleTarget registers the response body returned for the operational
+// command cmd when it is proxied by Panorama to the firewall with the serial
+// number target. Commands without a target specific response fall back to the
+// response registered with Handle.
+func (s *Server) HandleTarget(target, cmd, body string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.responses[handlerKey{target: target, cmd: cmd}] = body
+}
+
+// Requests returns the form values of all the requests received so far.
+func (s *Server) Requests() []url.Values {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	requests := make([]url.Values, len(s.requests))
+	copy(requests, s.requests)
+	return requests
+}
+
+// Config returns a panw module configuration pointing to the server.
+func (s *Server) Config(metricsets ...string) map[string]interface{} {
+	s.t.Helper()
+
+	u, err := url.Parse(s.URL)
+	if err != nil {
+		s.t.Fatalf("failed to parse server URL: %v", err)
+	}
+	host, portStr, err := net.SplitHostPort(u.Host)
+	if err != nil {
+		s.t.Fatalf("failed to split server address: %v", err)
+	}
+	port, err := strconv.ParseUint(portStr, 10, 16)
+	if err != nil {
+		s.t.Fatalf("failed to parse server port: %v", err)
+	}
+
+	return map[string]interface{}{
+		"module":     "panw",
+		"metricsets": metricsets,
+		"host_ip":    host,
+		"port":       port,
+		"api_key":    APIKey,
+	}
+}
+
+// NewClient is a panw.ClientFactory returning clients connected to the server,
+// whatever the host, port and API key in config.
+func (s *Server) NewClient(config *panw.Config) (panw.PanwClient, error) {
+	s.t.Helper()
+
+	serverConfig := s.Config()
+	c := *config
+	c.HostIp = serverConfig["host_ip"].(string)
+	c.Port = uint(serverConfig["port"].(uint64))
+	c.ApiKey = APIKey
+	return panw.GetPanwClient(&c)
+}
+
+// NewMetricSet creates the metricset configured in config with the factory
+// returned by newMetricSet, injecting NewClient as its client factory.
+func (s *Server) NewMetricSet(config map[string]interface{}, newMetricSet func(panw.ClientFactory) mb.MetricSetFactory) mb.ReportingMetricSetV2Error {
+	s.t.Helper()
+
+	metricsets, ok := config["metricsets"].([]string)
+	if !ok || len(metricsets) != 1 {
+		s.t.Fatalf("config must contain exactly one metricset, got %v", config["metricsets"])
+	}
+	registry := mb.NewRegister()
+	if err := registry.AddMetricSet(panw.ModuleName, metricsets[0], newMetricSet(s.NewClient)); err != nil {
+		s.t.Fatalf("failed to register metricset: %v", err)
+	}
+
+	metricSet, ok := mbtest.NewMetricSetWithRegistry(s.t, config, registry).(mb.ReportingMetricSetV2Error)
+	if !ok {
+		s.t.Fatalf("metricset %s does not implement ReportingMetricSetV2Error", metricsets[0])
+	}
+	return metricSet
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	s.mu.Lock()
+	s.requests = append(s.requests, r.Form)
+	s.mu.Unlock()
+
+	if r.Form.Get("key") != APIKey {
+		writeError(w, http.StatusForbidden, "Invalid credentials.")
+		return
+	}
+	if typ := r.Form.Get("type"); typ != "op" {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("Unsupported request type %q.", typ))
+		return
+	}
+
+	target, cmd := r.Form.Get("target"), r.Form.Get("cmd")
+
+	s.mu.Lock()
+	body, ok := s.responses[handlerKey{target: target, cmd: cmd}]
+	if !ok {
+		body, ok = s.responses[handlerKey{cmd: cmd}]
+	}
+	s.mu.Unlock()
+
+	if !ok {
+		writeError(w, http.StatusOK, fmt.Sprintf("Unknown command %s.", cmd))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/xml; charset=UTF-8")
+	_, _ = w.Write([]byte(body))
+}
+
+func writeError(w http.ResponseWriter, status int, msg string) {
+	w.Header().Set("Content-Type", "application/xml; charset=UTF-8")
+	w.WriteHeader(status)
+	_, _ = fmt.Fprintf(w, `<response status="error"><msg><line>%s</line></msg></response>`, html.EscapeString(msg))
+}
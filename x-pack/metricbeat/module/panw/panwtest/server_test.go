@@ -0,0 +1,87 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package panwtest_test
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/elastic/beats/v7/x-pack/metricbeat/module/panw"
+	"github.com/elastic/beats/v7/x-pack/metricbeat/module/panw/panwtest"
+)
+
+const testQuery = "<show><session><info></info></session></show>"
+
+func newConfig(server *panwtest.Server) *panw.Config {
+	config := server.Config()
+	return &panw.Config{
+		HostIp: config["host_ip"].(string),
+		Port:   uint(config["port"].(uint64)),
+		ApiKey: config["api_key"].(string),
+	}
+}
+
+func TestServer(t *testing.T) {
+	server := panwtest.NewServer(t)
+	server.Handle(testQuery, `<response status="success"><result><num-max>10</num-max></result></response>`)
+
+	client, err := panw.GetPanwClient(newConfig(server))
+	require.NoError(t, err)
+
+	output, err := client.Op(testQuery, panw.Vsys, nil, nil)
+	require.NoError(t, err)
+	assert.Contains(t, string(output), "<num-max>10</num-max>")
+
+	_, err = client.Op("<show><unknown></unknown></show>", panw.Vsys, nil, nil)
+	assert.ErrorContains(t, err, "Unknown command")
+
+	requests := server.Requests()
+	require.NotEmpty(t, requests)
+	last := requests[len(requests)-1]
+	assert.Equal(t, "op", last.Get("type"))
+	assert.Equal(t, panwtest.APIKey, last.Get("key"))
+}
+
+func TestServerTarget(t *testing.T) {
+	server := panwtest.NewServer(t)
+	server.Handle(testQuery, `<response status="success"><result>panorama</result></response>`)
+	server.HandleTarget("007051000000001", testQuery, `<response status="success"><result>firewall</result></response>`)
+
+	client, err := panw.GetPanwClient(newConfig(server))
+	require.NoError(t, err)
+
+	output, err := client.Op(testQuery, panw.Vsys, nil, nil)
+	require.NoError(t, err)
+	assert.Contains(t, string(output), "panorama")
+
+	output, err = client.Op(testQuery, panw.Vsys, url.Values{"target": {"007051000000001"}}, nil)
+	require.NoError(t, err)
+	assert.Contains(t, string(output), "firewall")
+}
+
+func TestServerInvalidAPIKey(t *testing.T) {
+	server := panwtest.NewServer(t)
+	config := newConfig(server)
+	config.ApiKey = "invalid"
+
+	_, err := panw.GetPanwClient(config)
+	assert.ErrorContains(t, err, "Invalid credentials")
+}
+
+func TestServerNewClient(t *testing.T) {
+	server := panwtest.NewServer(t)
+	server.Handle(testQuery, `<response status="success"><result>injected</result></response>`)
+
+	// The client connects to the server whatever the configured host.
+	client, err := server.NewClient(&panw.Config{HostIp: "firewall.invalid", ApiKey: "unused"})
+	require.NoError(t, err)
+
+	output, err := client.Op(testQuery, panw.Vsys, nil, nil)
+	require.NoError(t, err)
+	assert.Contains(t, string(output), "injected")
+}
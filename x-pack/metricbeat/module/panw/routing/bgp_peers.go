@@ -19,12 +19,12 @@ const bgpPeersQuery = "<show><routing><protocol><bgp><peer></peer></bgp></protoc
 
 var bgpLogger *logp.Logger
 
-func getBGPEvents(m *MetricSet) ([]mb.Event, error) {
+func getBGPEvents(m *MetricSet, target panw.Target) ([]mb.Event, error) {
 	// Set logger so all the sub functions have access
 	bgpLogger = m.logger
 	var response BGPResponse
 
-	output, err := m.client.Op(bgpPeersQuery, panw.Vsys, nil, nil)
+	output, err := target.Client.Op(bgpPeersQuery, panw.Vsys, nil, nil)
 	if err != nil {
 		m.logger.Error("Error calling API: %s", err)
 		return nil, err
@@ -36,7 +36,7 @@ func getBGPEvents(m *MetricSet) ([]mb.Event, error) {
 		return nil, err
 	}
 
-	events := formatBGPEvents(m, response.Result.Entries)
+	events := formatBGPEvents(m, target, response.Result.Entries)
 
 	return events, nil
 }
@@ -70,7 +70,7 @@ func convertEntryBooleanFields(entry BGPEntry) map[string]bool {
 	return result
 }
 
-func formatBGPEvents(m *MetricSet, entries []BGPEntry) []mb.Event {
+func formatBGPEvents(m *MetricSet, target panw.Target, entries []BGPEntry) []mb.Event {
 	events := make([]mb.Event, 0, len(entries))
 	timestamp := time.Now().UTC()
 
@@ -129,7 +129,7 @@ func formatBGPEvents(m *MetricSet, entries []BGPEntry) []mb.Event {
 				"bgp.nexthop_thirdparty":     booleanFields["bgp.nexthop_thirdparty"],
 				"bgp.nexthop_peer":           booleanFields["bgp.nexthop_peer"],
 			},
-			RootFields: panw.MakeRootFields(m.config.HostIp),
+			RootFields: target.RootFields(),
 		}
 
 		events = append(events, event)
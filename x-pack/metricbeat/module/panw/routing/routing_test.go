@@ -0,0 +1,35 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package routing
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	mbtest "github.com/elastic/beats/v7/metricbeat/mb/testing"
+	"github.com/elastic/beats/v7/x-pack/metricbeat/module/panw/panwtest"
+)
+
+func TestFetch(t *testing.T) {
+	server := panwtest.NewServer(t)
+	server.HandleFile(bgpPeersQuery, filepath.Join("..", "_meta", "testdata", "bgp_peers.xml"))
+	f := server.NewMetricSet(server.Config(metricsetName), NewWithClientFactory)
+
+	events, errs := mbtest.ReportingFetchV2Error(f)
+	require.Empty(t, errs)
+	require.Len(t, events, 4)
+
+	fields := events[0].MetricSetFields
+	assert.Equal(t, "Site1-01", fields["bgp.peer_name"])
+	assert.Equal(t, "default", fields["bgp.virtual_router"])
+	assert.Equal(t, "Established", fields["bgp.status"])
+	assert.Equal(t, "1.1.1.1", fields["bgp.peer_ip"])
+	assert.Equal(t, "179", fields["bgp.peer_port"])
+	assert.Equal(t, false, fields["bgp.password_set"])
+	assert.Equal(t, "127.0.0.1", events[0].RootFields["observer.ip"])
+}
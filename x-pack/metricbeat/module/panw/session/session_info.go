@@ -0,0 +1,70 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package session
+
+import (
+	"encoding/xml"
+	"fmt"
+	"time"
+
+	"github.com/elastic/beats/v7/metricbeat/mb"
+	"github.com/elastic/beats/v7/x-pack/metricbeat/module/panw"
+	"github.com/elastic/elastic-agent-libs/mapstr"
+)
+
+const sessionInfoQuery = "<show><session><info></info></session></show>"
+
+func getSessionInfoEvents(m *MetricSet, target panw.Target) ([]mb.Event, error) {
+	var response SessionInfoResponse
+
+	output, err := target.Client.Op(sessionInfoQuery, panw.Vsys, nil, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error querying session info: %w", err)
+	}
+
+	err = xml.Unmarshal(output, &response)
+	if err != nil {
+		return nil, fmt.Errorf("error unmarshaling session info: %w", err)
+	}
+
+	return formatSessionInfoEvents(m, target, &response), nil
+}
+
+func formatSessionInfoEvents(m *MetricSet, target panw.Target, response *SessionInfoResponse) []mb.Event {
+	if response == nil {
+		return nil
+	}
+
+	info := response.Result
+	table := mapstr.M{
+		"max":       info.NumMax,
+		"active":    info.NumActive,
+		"tcp":       info.NumTCP,
+		"udp":       info.NumUDP,
+		"icmp":      info.NumICMP,
+		"multicast": info.NumMcast,
+		"broadcast": info.NumBcast,
+		"predict":   info.NumPredict,
+		"installed": info.NumInstalled,
+	}
+	if info.NumMax > 0 {
+		_, _ = table.Put("utilization.pct", float64(info.NumActive)/float64(info.NumMax))
+	}
+
+	event := mb.Event{
+		Timestamp: time.Now().UTC(),
+		MetricSetFields: mapstr.M{
+			"table": table,
+			"throughput": mapstr.M{
+				"cps":  info.CPS,
+				"kbps": info.KBPS,
+				"pps":  info.PPS,
+			},
+		},
+		RootFields: target.RootFields(),
+	}
+
+	return []mb.Event{event}
+}
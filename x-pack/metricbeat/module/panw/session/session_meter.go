@@ -0,0 +1,64 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package session
+
+import (
+	"encoding/xml"
+	"fmt"
+	"time"
+
+	"github.com/elastic/beats/v7/metricbeat/mb"
+	"github.com/elastic/beats/v7/x-pack/metricbeat/module/panw"
+	"github.com/elastic/elastic-agent-libs/mapstr"
+)
+
+const sessionMeterQuery = "<show><session><meter></meter></session></show>"
+
+func getSessionMeterEvents(m *MetricSet, target panw.Target) ([]mb.Event, error) {
+	var response SessionMeterResponse
+
+	output, err := target.Client.Op(sessionMeterQuery, panw.Vsys, nil, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error querying session meter: %w", err)
+	}
+
+	err = xml.Unmarshal(output, &response)
+	if err != nil {
+		return nil, fmt.Errorf("error unmarshaling session meter: %w", err)
+	}
+
+	return formatSessionMeterEvents(m, target, &response), nil
+}
+
+func formatSessionMeterEvents(m *MetricSet, target panw.Target, response *SessionMeterResponse) []mb.Event {
+	if response == nil || len(response.Result.Entries) == 0 {
+		return nil
+	}
+
+	events := make([]mb.Event, 0, len(response.Result.Entries))
+	timestamp := time.Now().UTC()
+
+	for _, entry := range response.Result.Entries {
+		vsys := mapstr.M{
+			"id":        entry.Vsys,
+			"current":   entry.Current,
+			"maximum":   entry.Maximum,
+			"throttled": entry.Throttled,
+		}
+		// A maximum of 0 means the virtual system has no session limit.
+		if entry.Maximum > 0 {
+			_, _ = vsys.Put("utilization.pct", float64(entry.Current)/float64(entry.Maximum))
+		}
+
+		event := mb.Event{
+			Timestamp:       timestamp,
+			MetricSetFields: mapstr.M{"vsys": vsys},
+			RootFields:      target.RootFields(),
+		}
+		events = append(events, event)
+	}
+
+	return events
+}
@@ -0,0 +1,140 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package session
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	mbtest "github.com/elastic/beats/v7/metricbeat/mb/testing"
+	"github.com/elastic/beats/v7/x-pack/metricbeat/module/panw"
+	"github.com/elastic/beats/v7/x-pack/metricbeat/module/panw/panwtest"
+	"github.com/elastic/elastic-agent-libs/mapstr"
+)
+
+func newTestServer(t *testing.T) *panwtest.Server {
+	server := panwtest.NewServer(t)
+	server.HandleFile(sessionInfoQuery, filepath.Join("..", "_meta", "testdata", "session_info.xml"))
+	server.HandleFile(sessionMeterQuery, filepath.Join("..", "_meta", "testdata", "session_meter.xml"))
+	return server
+}
+
+func newTestMetricSet(t *testing.T) (*MetricSet, panw.Target) {
+	server := newTestServer(t)
+	m, ok := server.NewMetricSet(server.Config(metricsetName), NewWithClientFactory).(*MetricSet)
+	require.True(t, ok)
+	return m, panw.Target{Client: m.client, HostIp: m.config.HostIp}
+}
+
+func TestFetch(t *testing.T) {
+	server := newTestServer(t)
+	f := server.NewMetricSet(server.Config(metricsetName), NewWithClientFactory)
+
+	events, errs := mbtest.ReportingFetchV2Error(f)
+	require.Empty(t, errs)
+	require.Len(t, events, 3)
+	for _, event := range events {
+		assert.Equal(t, "127.0.0.1", event.RootFields["observer.ip"])
+	}
+}
+
+func TestFetchPanorama(t *testing.T) {
+	server := newTestServer(t)
+	server.Handle("<show><devices><connected></connected></devices></show>", `<response status="success"><result><devices>
+  <entry name="007051000000001"><serial>007051000000001</serial><hostname>fw-east</hostname><ip-address>10.0.0.1</ip-address><connected>yes</connected></entry>
+  <entry name="007051000000002"><serial>007051000000002</serial><hostname>fw-west</hostname><ip-address>10.0.0.2</ip-address><connected>yes</connected></entry>
+</devices></result></response>`)
+	server.Handle("<show><devicegroups></devicegroups></show>", `<response status="success"><result><devicegroups>
+  <entry name="branches"><devices><entry name="007051000000002"><serial>007051000000002</serial></entry></devices></entry>
+</devicegroups></result></response>`)
+	server.HandleTarget("007051000000002", sessionMeterQuery, `<response status="success"><result><entry><vsys>1</vsys><current>10</current><maximum>100</maximum><throttled>0</throttled></entry></result></response>`)
+
+	config := server.Config(metricsetName)
+	config["panorama"] = true
+	config["devices"] = []string{"007051000000002"}
+	f := server.NewMetricSet(config, NewWithClientFactory)
+
+	events, errs := mbtest.ReportingFetchV2Error(f)
+	require.Empty(t, errs)
+	require.Len(t, events, 2)
+	for _, event := range events {
+		assert.Equal(t, "10.0.0.2", event.RootFields["observer.ip"])
+		assert.Equal(t, "007051000000002", event.RootFields["observer.serial_number"])
+		assert.Equal(t, "branches", event.RootFields["panw.panorama.device_group"])
+		assert.Equal(t, "127.0.0.1", event.RootFields["panw.panorama.ip"])
+	}
+	assert.Equal(t, mapstr.M{
+		"id":        1,
+		"current":   10,
+		"maximum":   100,
+		"throttled": 0,
+		"utilization": mapstr.M{
+			"pct": 0.1,
+		},
+	}, events[1].MetricSetFields["vsys"])
+
+	// Every request for the device metrics is proxied to the firewall.
+	var proxied int
+	for _, req := range server.Requests() {
+		if req.Get("cmd") == sessionInfoQuery || req.Get("cmd") == sessionMeterQuery {
+			assert.Equal(t, "007051000000002", req.Get("target"))
+			proxied++
+		}
+	}
+	assert.Equal(t, 2, proxied)
+}
+
+func TestSessionInfoEvents(t *testing.T) {
+	m, target := newTestMetricSet(t)
+
+	events, err := getSessionInfoEvents(m, target)
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+
+	fields := events[0].MetricSetFields
+	assert.Equal(t, mapstr.M{
+		"max":       262142,
+		"active":    65535,
+		"tcp":       53500,
+		"udp":       12010,
+		"icmp":      25,
+		"multicast": 2,
+		"broadcast": 3,
+		"predict":   1,
+		"installed": 48311232,
+		"utilization": mapstr.M{
+			"pct": float64(65535) / float64(262142),
+		},
+	}, fields["table"])
+	assert.Equal(t, mapstr.M{"cps": 412, "kbps": 183422, "pps": 24211}, fields["throughput"])
+	assert.Equal(t, "127.0.0.1", events[0].RootFields["observer.ip"])
+}
+
+func TestSessionMeterEvents(t *testing.T) {
+	m, target := newTestMetricSet(t)
+
+	events, err := getSessionMeterEvents(m, target)
+	require.NoError(t, err)
+	require.Len(t, events, 2)
+
+	assert.Equal(t, mapstr.M{
+		"id":        1,
+		"current":   60012,
+		"maximum":   0,
+		"throttled": 0,
+	}, events[0].MetricSetFields["vsys"])
+	assert.Equal(t, mapstr.M{
+		"id":        2,
+		"current":   5523,
+		"maximum":   10000,
+		"throttled": 17,
+		"utilization": mapstr.M{
+			"pct": 0.5523,
+		},
+	}, events[1].MetricSetFields["vsys"])
+}
@@ -0,0 +1,43 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package session
+
+// Session table information
+type SessionInfoResponse struct {
+	Status string            `xml:"status,attr"`
+	Result SessionInfoResult `xml:"result"`
+}
+
+type SessionInfoResult struct {
+	NumMax       int `xml:"num-max"`
+	NumActive    int `xml:"num-active"`
+	NumTCP       int `xml:"num-tcp"`
+	NumUDP       int `xml:"num-udp"`
+	NumICMP      int `xml:"num-icmp"`
+	NumMcast     int `xml:"num-mcast"`
+	NumBcast     int `xml:"num-bcast"`
+	NumPredict   int `xml:"num-predict"`
+	NumInstalled int `xml:"num-installed"`
+	CPS          int `xml:"cps"`
+	KBPS         int `xml:"kbps"`
+	PPS          int `xml:"pps"`
+}
+
+// Session usage per virtual system
+type SessionMeterResponse struct {
+	Status string             `xml:"status,attr"`
+	Result SessionMeterResult `xml:"result"`
+}
+
+type SessionMeterResult struct {
+	Entries []SessionMeterEntry `xml:"entry"`
+}
+
+type SessionMeterEntry struct {
+	Vsys      int `xml:"vsys"`
+	Current   int `xml:"current"`
+	Maximum   int `xml:"maximum"`
+	Throttled int `xml:"throttled"`
+}
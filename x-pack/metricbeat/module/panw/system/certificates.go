@@ -19,11 +19,11 @@ import (
 
 const certificatesQuery = "<show><sslmgr-store><config-certificate-info></config-certificate-info></sslmgr-store></show>"
 
-func getCertificateEvents(m *MetricSet) ([]mb.Event, error) {
+func getCertificateEvents(m *MetricSet, target panw.Target) ([]mb.Event, error) {
 
 	var response CertificateResponse
 
-	output, err := m.client.Op(certificatesQuery, panw.Vsys, nil, nil)
+	output, err := target.Client.Op(certificatesQuery, panw.Vsys, nil, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to execute query: %w", err)
 	}
@@ -37,7 +37,7 @@ func getCertificateEvents(m *MetricSet) ([]mb.Event, error) {
 		return nil, fmt.Errorf("empty result from XML response")
 	}
 
-	events, err := formatCertificateEvents(m, response.Result)
+	events, err := formatCertificateEvents(m, target, response.Result)
 	if err != nil {
 		return nil, fmt.Errorf("failed to format certificate events: %w", err)
 	}
@@ -45,7 +45,7 @@ func getCertificateEvents(m *MetricSet) ([]mb.Event, error) {
 	return events, nil
 }
 
-func formatCertificateEvents(m *MetricSet, input string) ([]mb.Event, error) {
+func formatCertificateEvents(m *MetricSet, target panw.Target, input string) ([]mb.Event, error) {
 	timestamp := time.Now().UTC()
 
 	certificates, err := parseCertificates(input)
@@ -71,7 +71,7 @@ func formatCertificateEvents(m *MetricSet, input string) ([]mb.Event, error) {
 				"certificate.db_name":             certificate.DBName,
 				"certificate.db_status":           certificate.DBStatus,
 			},
-			RootFields: panw.MakeRootFields(m.config.HostIp),
+			RootFields: target.RootFields(),
 		}
 
 		events = append(events, event)
@@ -16,11 +16,11 @@ import (
 
 const fansQuery = "<show><system><environmentals><fans></fans></environmentals></system></show>"
 
-func getFanEvents(m *MetricSet) ([]mb.Event, error) {
+func getFanEvents(m *MetricSet, target panw.Target) ([]mb.Event, error) {
 
 	var response FanResponse
 
-	output, err := m.client.Op(fansQuery, panw.Vsys, nil, nil)
+	output, err := target.Client.Op(fansQuery, panw.Vsys, nil, nil)
 	if err != nil {
 		return nil, fmt.Errorf("error querying fan data: %w", err)
 	}
@@ -30,10 +30,10 @@ func getFanEvents(m *MetricSet) ([]mb.Event, error) {
 		return nil, fmt.Errorf("error unmarshaling fan data: %w", err)
 	}
 
-	return formatFanEvents(m, &response), nil
+	return formatFanEvents(m, target, &response), nil
 }
 
-func formatFanEvents(m *MetricSet, response *FanResponse) []mb.Event {
+func formatFanEvents(m *MetricSet, target panw.Target, response *FanResponse) []mb.Event {
 	if response == nil || len(response.Result.Fan.Slots) == 0 {
 		return nil
 	}
@@ -57,7 +57,7 @@ func formatFanEvents(m *MetricSet, response *FanResponse) []mb.Event {
 					"fan.rpm":         entry.RPMs,
 					"fan.min_rpm":     entry.Min,
 				},
-				RootFields: panw.MakeRootFields(m.config.HostIp),
+				RootFields: target.RootFields(),
 			}
 			events = append(events, event)
 		}
@@ -27,12 +27,12 @@ const filesystemQuery = "<show><system><disk-space></disk-space></system></show>
 
 var filesystemLogger *logp.Logger
 
-func getFilesystemEvents(m *MetricSet) ([]mb.Event, error) {
+func getFilesystemEvents(m *MetricSet, target panw.Target) ([]mb.Event, error) {
 	// Set logger so all the parse functions have access
 	filesystemLogger = m.logger
 	var response FilesystemResponse
 
-	output, err := m.client.Op(filesystemQuery, panw.Vsys, nil, nil)
+	output, err := target.Client.Op(filesystemQuery, panw.Vsys, nil, nil)
 	if err != nil {
 		return nil, fmt.Errorf("error querying filesystem info: %w", err)
 	}
@@ -47,7 +47,7 @@ func getFilesystemEvents(m *MetricSet) ([]mb.Event, error) {
 	}
 
 	filesystems := getFilesystems(response.Result.Data)
-	events := formatFilesystemEvents(m, filesystems)
+	events := formatFilesystemEvents(m, target, filesystems)
 
 	return events, nil
 }
@@ -121,7 +121,7 @@ func convertToBytes(field string, value string) float64 {
 
 }
 
-func formatFilesystemEvents(m *MetricSet, filesystems []Filesystem) []mb.Event {
+func formatFilesystemEvents(m *MetricSet, target panw.Target, filesystems []Filesystem) []mb.Event {
 	if len(filesystems) == 0 {
 		return nil
 	}
@@ -145,7 +145,7 @@ func formatFilesystemEvents(m *MetricSet, filesystems []Filesystem) []mb.Event {
 				"filesystem.use_percent": used,
 				"filesystem.mounted":     filesystem.Mounted,
 			},
-			RootFields: panw.MakeRootFields(m.config.HostIp),
+			RootFields: target.RootFields(),
 		}
 
 		events = append(events, event)
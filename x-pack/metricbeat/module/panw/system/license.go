@@ -20,11 +20,11 @@ const (
 	panwDateFormat = "January 2, 2006"
 )
 
-func getLicenseEvents(m *MetricSet) ([]mb.Event, error) {
+func getLicenseEvents(m *MetricSet, target panw.Target) ([]mb.Event, error) {
 
 	var response LicenseResponse
 
-	output, err := m.client.Op(licenseQuery, panw.Vsys, nil, nil)
+	output, err := target.Client.Op(licenseQuery, panw.Vsys, nil, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to execute query: %w", err)
 	}
@@ -43,10 +43,10 @@ func getLicenseEvents(m *MetricSet) ([]mb.Event, error) {
 		return []mb.Event{}, nil
 	}
 
-	return formatLicenseEvents(m, response.Result.Licenses), nil
+	return formatLicenseEvents(m, target, response.Result.Licenses), nil
 }
 
-func formatLicenseEvents(m *MetricSet, licenses []License) []mb.Event {
+func formatLicenseEvents(m *MetricSet, target panw.Target, licenses []License) []mb.Event {
 	events := make([]mb.Event, 0, len(licenses))
 	timestamp := time.Now().UTC()
 
@@ -86,7 +86,7 @@ func formatLicenseEvents(m *MetricSet, licenses []License) []mb.Event {
 				"license.expired":       expired,
 				"license.auth_code":     license.AuthCode,
 			},
-			RootFields: panw.MakeRootFields(m.config.HostIp),
+			RootFields: target.RootFields(),
 		}
 		// only set the expires field if the license expires
 		if !neverExpires {
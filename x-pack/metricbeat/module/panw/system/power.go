@@ -17,11 +17,11 @@ import (
 const powerQuery = "<show><system><environmentals><power></power></environmentals></system></show>"
 
 // getPowerEvents retrieves power-related events from a PAN-OS device.
-func getPowerEvents(m *MetricSet) ([]mb.Event, error) {
+func getPowerEvents(m *MetricSet, target panw.Target) ([]mb.Event, error) {
 
 	var response PowerResponse
 
-	output, err := m.client.Op(powerQuery, panw.Vsys, nil, nil)
+	output, err := target.Client.Op(powerQuery, panw.Vsys, nil, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to execute operation: %w", err)
 	}
@@ -36,12 +36,12 @@ func getPowerEvents(m *MetricSet) ([]mb.Event, error) {
 		return nil, nil
 	}
 
-	events := formatPowerEvents(m, &response)
+	events := formatPowerEvents(m, target, &response)
 
 	return events, nil
 }
 
-func formatPowerEvents(m *MetricSet, response *PowerResponse) []mb.Event {
+func formatPowerEvents(m *MetricSet, target panw.Target, response *PowerResponse) []mb.Event {
 	events := make([]mb.Event, 0)
 	timestamp := time.Now().UTC()
 
@@ -58,7 +58,7 @@ func formatPowerEvents(m *MetricSet, response *PowerResponse) []mb.Event {
 					"power.minimum_volts": entry.MinimumVolts,
 					"power.maximum_volts": entry.MaximumVolts,
 				},
-				RootFields: panw.MakeRootFields(m.config.HostIp),
+				RootFields: target.RootFields(),
 			}
 			events = append(events, event)
 		}
@@ -22,12 +22,12 @@ const resourceQuery = "<show><system><resources></resources></system></show>"
 
 var resourcesLogger *logp.Logger
 
-func getResourceEvents(m *MetricSet) ([]mb.Event, error) {
+func getResourceEvents(m *MetricSet, target panw.Target) ([]mb.Event, error) {
 	// Set logger so all the parse functions have access
 	resourcesLogger = m.logger
 
 	var response ResourceResponse
-	output, err := m.client.Op(resourceQuery, panw.Vsys, nil, &response)
+	output, err := target.Client.Op(resourceQuery, panw.Vsys, nil, &response)
 	if err != nil {
 		return nil, fmt.Errorf("failed to execute operation: %w", err)
 	}
@@ -37,7 +37,7 @@ func getResourceEvents(m *MetricSet) ([]mb.Event, error) {
 		return nil, fmt.Errorf("failed to unmarshal XML response: %w", err)
 	}
 
-	events := formatResourceEvents(m, response.Result)
+	events := formatResourceEvents(m, target, response.Result)
 
 	return events, nil
 }
@@ -67,7 +67,7 @@ MiB Swap:   5961.0 total,   4403.5 free,   1557.6 used.   1530.0 avail Mem
 	1       20   0    2532    696    656 S   0.0   0.0   3:48.14 init
 	2       20   0       0      0      0 S   0.0   0.0   0:00.83 kthreadd
 */
-func formatResourceEvents(m *MetricSet, input string) []mb.Event {
+func formatResourceEvents(m *MetricSet, target panw.Target, input string) []mb.Event {
 	timestamp := time.Now().UTC()
 
 	events := make([]mb.Event, 0)
@@ -126,7 +126,7 @@ func formatResourceEvents(m *MetricSet, input string) []mb.Event {
 				"available": swapInfo.Available,
 			},
 		},
-		RootFields: panw.MakeRootFields(m.config.HostIp),
+		RootFields: target.RootFields(),
 	}
 
 	events = append(events, event)
@@ -41,27 +41,34 @@ func init() {
 // New creates a new instance of the MetricSet. New is responsible for unpacking
 // any MetricSet specific configuration options if there are any.
 func New(base mb.BaseMetricSet) (mb.MetricSet, error) {
-	cfgwarn.Beta("The panw system metricset is beta.")
+	return NewWithClientFactory(panw.GetPanwClient)(base)
+}
 
-	config, err := panw.NewConfig(base)
-	if err != nil {
-		return nil, err
-	}
+// NewWithClientFactory returns a MetricSetFactory creating metricsets that
+// query PAN-OS through the client returned by newClient.
+func NewWithClientFactory(newClient panw.ClientFactory) mb.MetricSetFactory {
+	return func(base mb.BaseMetricSet) (mb.MetricSet, error) {
+		cfgwarn.Beta("The panw system metricset is beta.")
 
-	logger := base.Logger().Named(base.FullyQualifiedName())
+		config, err := panw.NewConfig(base)
+		if err != nil {
+			return nil, err
+		}
 
-	//client := &pango.Firewall{Client: pango.Client{Hostname: config.HostIp, ApiKey: config.ApiKey}}
-	client, err := panw.GetPanwClient(config)
-	if err != nil {
-		return nil, err
-	}
+		logger := base.Logger().Named(base.FullyQualifiedName())
 
-	return &MetricSet{
-		BaseMetricSet: base,
-		config:        config,
-		logger:        logger,
-		client:        client,
-	}, nil
+		client, err := newClient(config)
+		if err != nil {
+			return nil, err
+		}
+
+		return &MetricSet{
+			BaseMetricSet: base,
+			config:        config,
+			logger:        logger,
+			client:        client,
+		}, nil
+	}
 }
 
 // Fetch method implements the data gathering and data conversion to the right
@@ -74,7 +81,7 @@ func (m *MetricSet) Fetch(report mb.ReporterV2) error {
 
 	eventFetchers := []struct {
 		name string
-		fn   func(*MetricSet) ([]mb.Event, error)
+		fn   func(*MetricSet, panw.Target) ([]mb.Event, error)
 	}{
 		{"certificates", getCertificateEvents},
 		{"resources", getResourceEvents},
@@ -85,14 +92,21 @@ func (m *MetricSet) Fetch(report mb.ReporterV2) error {
 		{"filesystem", getFilesystemEvents},
 	}
 
-	for _, fetcher := range eventFetchers {
-		events, err := fetcher.fn(m)
-		if err != nil {
-			m.logger.Errorf("Error getting %s events: %s", fetcher.name, err)
-			errs = append(errs, err)
-		} else {
-			for _, event := range events {
-				report.Event(event)
+	targets, err := panw.GetTargets(m.config, m.client)
+	if err != nil {
+		return fmt.Errorf("error getting firewalls to query: %w", err)
+	}
+
+	for _, target := range targets {
+		for _, fetcher := range eventFetchers {
+			events, err := fetcher.fn(m, target)
+			if err != nil {
+				m.logger.Errorf("Error getting %s events from %s: %s", fetcher.name, target, err)
+				errs = append(errs, fmt.Errorf("%s: %w", target, err))
+			} else {
+				for _, event := range events {
+					report.Event(event)
+				}
 			}
 		}
 	}
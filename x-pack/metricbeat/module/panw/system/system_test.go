@@ -0,0 +1,95 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package system
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/elastic/beats/v7/metricbeat/mb"
+	mbtest "github.com/elastic/beats/v7/metricbeat/mb/testing"
+	"github.com/elastic/beats/v7/x-pack/metricbeat/module/panw/panwtest"
+	"github.com/elastic/elastic-agent-libs/mapstr"
+)
+
+func newTestServer(t *testing.T) *panwtest.Server {
+	server := panwtest.NewServer(t)
+	for query, file := range map[string]string{
+		certificatesQuery: "system_certificates.xml",
+		resourceQuery:     "system_resources.xml",
+		powerQuery:        "system_power.xml",
+		fansQuery:         "system_fans.xml",
+		thermalQuery:      "system_thermal.xml",
+		licenseQuery:      "system_license.xml",
+		filesystemQuery:   "system_disk_space.xml",
+	} {
+		server.HandleFile(query, filepath.Join("..", "_meta", "testdata", file))
+	}
+	return server
+}
+
+// eventsWith returns the events having the given metricset field.
+func eventsWith(events []mb.Event, field string) []mb.Event {
+	var matching []mb.Event
+	for _, event := range events {
+		if _, ok := event.MetricSetFields[field]; ok {
+			matching = append(matching, event)
+		}
+	}
+	return matching
+}
+
+func TestFetch(t *testing.T) {
+	server := newTestServer(t)
+	f := server.NewMetricSet(server.Config(metricsetName), NewWithClientFactory)
+
+	events, errs := mbtest.ReportingFetchV2Error(f)
+	require.Empty(t, errs)
+	require.Len(t, events, 14)
+
+	for _, event := range events {
+		assert.Equal(t, "127.0.0.1", event.RootFields["observer.ip"])
+	}
+
+	certificates := eventsWith(events, "certificate.name")
+	require.Len(t, certificates, 2)
+	assert.Equal(t, "/C=US/O=Example/CN=firewall.example.com", certificates[0].MetricSetFields["certificate.db_name"])
+	assert.Equal(t, "", certificates[0].MetricSetFields["certificate.issuer_key_hash"])
+	assert.Equal(t, "2B", certificates[1].MetricSetFields["certificate.db_serial_no"])
+
+	resources := eventsWith(events, "uptime")
+	require.Len(t, resources, 1)
+	assert.Equal(t, mapstr.M{"days": 108, "hours": 1, "minutes": 38}, resources[0].MetricSetFields["uptime"])
+	assert.Equal(t, 189, resources[0].MetricSetFields["tasks"].(mapstr.M)["total"])
+	assert.Equal(t, 73.0, resources[0].MetricSetFields["cpu"].(mapstr.M)["user"])
+
+	power := eventsWith(events, "power.slot_number")
+	require.Len(t, power, 2)
+	assert.Equal(t, true, power[1].MetricSetFields["power.alarm"])
+
+	fans := eventsWith(events, "fan.rpm")
+	require.Len(t, fans, 2)
+	assert.Equal(t, 4010, fans[0].MetricSetFields["fan.rpm"])
+
+	thermal := eventsWith(events, "thermal.degress_celsius")
+	require.Len(t, thermal, 2)
+	assert.Equal(t, 48.25, thermal[1].MetricSetFields["thermal.degress_celsius"])
+
+	licenses := eventsWith(events, "license.feature")
+	require.Len(t, licenses, 2)
+	assert.Equal(t, "2025-05-27T00:00:00Z", licenses[0].MetricSetFields["license.expires"])
+	assert.Equal(t, true, licenses[0].MetricSetFields["license.expired"])
+	assert.Equal(t, true, licenses[1].MetricSetFields["license.never_expires"])
+	assert.NotContains(t, licenses[1].MetricSetFields, "license.expires")
+
+	filesystems := eventsWith(events, "filesystem.name")
+	require.Len(t, filesystems, 3)
+	assert.Equal(t, "/opt/pancfg", filesystems[2].MetricSetFields["filesystem.mounted"])
+	assert.Equal(t, float64(64*KBytes), filesystems[1].MetricSetFields["filesystem.used"])
+	assert.Equal(t, int64(51), filesystems[2].MetricSetFields["filesystem.use_percent"])
+}
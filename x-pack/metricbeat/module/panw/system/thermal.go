@@ -15,10 +15,10 @@ import (
 
 const thermalQuery = "<show><system><environmentals><thermal></thermal></environmentals></system></show>"
 
-func getThermalEvents(m *MetricSet) ([]mb.Event, error) {
+func getThermalEvents(m *MetricSet, target panw.Target) ([]mb.Event, error) {
 	var response ThermalResponse
 
-	output, err := m.client.Op(thermalQuery, panw.Vsys, nil, nil)
+	output, err := target.Client.Op(thermalQuery, panw.Vsys, nil, nil)
 	if err != nil {
 		m.logger.Error("Error: %s", err)
 		return nil, err
@@ -30,13 +30,13 @@ func getThermalEvents(m *MetricSet) ([]mb.Event, error) {
 		return nil, err
 	}
 
-	events := formatThermalEvents(m, &response)
+	events := formatThermalEvents(m, target, &response)
 
 	return events, nil
 
 }
 
-func formatThermalEvents(m *MetricSet, response *ThermalResponse) []mb.Event {
+func formatThermalEvents(m *MetricSet, target panw.Target, response *ThermalResponse) []mb.Event {
 	if response == nil || len(response.Result.Thermal.Slots) == 0 {
 		return nil
 	}
@@ -63,7 +63,7 @@ func formatThermalEvents(m *MetricSet, response *ThermalResponse) []mb.Event {
 					"thermal.minimum_temp":    entry.MinimumTemp,
 					"thermal.maximum_temp":    entry.MaximumTemp,
 				},
-				RootFields: panw.MakeRootFields(m.config.HostIp),
+				RootFields: target.RootFields(),
 			}
 
 			events = append(events, event)
@@ -0,0 +1,72 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package threat
+
+import (
+	"strings"
+	"time"
+
+	"github.com/elastic/beats/v7/metricbeat/mb"
+	"github.com/elastic/beats/v7/x-pack/metricbeat/module/panw"
+	"github.com/elastic/elastic-agent-libs/mapstr"
+)
+
+const (
+	counterTypeThreat       = "threat"
+	counterTypeURLFiltering = "url_filtering"
+)
+
+func getThreatCounterEvents(m *MetricSet, target panw.Target) ([]mb.Event, error) {
+	counters, err := panw.GetGlobalCounters(target)
+	if err != nil {
+		return nil, err
+	}
+
+	return formatThreatCounterEvents(m, target, counters), nil
+}
+
+// counterType classifies a global counter as a content threat detection or
+// URL filtering counter. It returns an empty string for other counters.
+func counterType(counter panw.GlobalCounter) string {
+	switch {
+	case counter.Category == "url" || strings.HasPrefix(counter.Name, "url_"):
+		return counterTypeURLFiltering
+	case counter.Category == "ctd":
+		return counterTypeThreat
+	default:
+		return ""
+	}
+}
+
+func formatThreatCounterEvents(m *MetricSet, target panw.Target, counters []panw.GlobalCounter) []mb.Event {
+	events := make([]mb.Event, 0)
+	timestamp := time.Now().UTC()
+
+	for _, counter := range counters {
+		typ := counterType(counter)
+		if typ == "" {
+			continue
+		}
+
+		event := mb.Event{
+			Timestamp: timestamp,
+			MetricSetFields: mapstr.M{
+				"counter.type":        typ,
+				"counter.id":          counter.ID,
+				"counter.name":        counter.Name,
+				"counter.description": counter.Description,
+				"counter.category":    counter.Category,
+				"counter.severity":    counter.Severity,
+				"counter.aspect":      counter.Aspect,
+				"counter.value":       counter.Value,
+				"counter.rate":        counter.Rate,
+			},
+			RootFields: target.RootFields(),
+		}
+		events = append(events, event)
+	}
+
+	return events
+}
@@ -0,0 +1,60 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package threat
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	mbtest "github.com/elastic/beats/v7/metricbeat/mb/testing"
+	"github.com/elastic/beats/v7/x-pack/metricbeat/module/panw"
+	"github.com/elastic/beats/v7/x-pack/metricbeat/module/panw/panwtest"
+	"github.com/elastic/elastic-agent-libs/mapstr"
+)
+
+func newTestServer(t *testing.T) *panwtest.Server {
+	server := panwtest.NewServer(t)
+	server.HandleFile(panw.GlobalCountersQuery, filepath.Join("..", "_meta", "testdata", "counter_global.xml"))
+	return server
+}
+
+func TestFetch(t *testing.T) {
+	server := newTestServer(t)
+	f := server.NewMetricSet(server.Config(metricsetName), NewWithClientFactory)
+
+	events, errs := mbtest.ReportingFetchV2Error(f)
+	require.Empty(t, errs)
+	require.Len(t, events, 3)
+}
+
+func TestThreatCounterEvents(t *testing.T) {
+	server := newTestServer(t)
+	m, ok := server.NewMetricSet(server.Config(metricsetName), NewWithClientFactory).(*MetricSet)
+	require.True(t, ok)
+	target := panw.Target{Client: m.client, HostIp: m.config.HostIp}
+
+	events, err := getThreatCounterEvents(m, target)
+	require.NoError(t, err)
+	require.Len(t, events, 3)
+
+	assert.Equal(t, mapstr.M{
+		"counter.type":        "threat",
+		"counter.id":          2611,
+		"counter.name":        "ctd_pkt_slowpath",
+		"counter.description": "Number of Packets processed by content threat detection",
+		"counter.category":    "ctd",
+		"counter.severity":    "info",
+		"counter.aspect":      "pktproc",
+		"counter.value":       int64(3121),
+		"counter.rate":        int64(5),
+	}, events[0].MetricSetFields)
+	assert.Equal(t, "ctd_threat_drop", events[1].MetricSetFields["counter.name"])
+	assert.Equal(t, "threat", events[1].MetricSetFields["counter.type"])
+	assert.Equal(t, "url_db_request", events[2].MetricSetFields["counter.name"])
+	assert.Equal(t, "url_filtering", events[2].MetricSetFields["counter.type"])
+}
@@ -16,10 +16,10 @@ import (
 
 const gpSessionsQuery = "<show><global-protect-gateway><current-user></current-user></global-protect-gateway></show>"
 
-func getGlobalProtectSessionEvents(m *MetricSet) ([]mb.Event, error) {
+func getGlobalProtectSessionEvents(m *MetricSet, target panw.Target) ([]mb.Event, error) {
 	var response GPSessionsResponse
 
-	output, err := m.client.Op(gpSessionsQuery, panw.Vsys, nil, nil)
+	output, err := target.Client.Op(gpSessionsQuery, panw.Vsys, nil, nil)
 	if err != nil {
 		m.logger.Error("Error: %s", err)
 		return nil, fmt.Errorf("error querying GlobalProtect sessions: %w", err)
@@ -31,13 +31,13 @@ func getGlobalProtectSessionEvents(m *MetricSet) ([]mb.Event, error) {
 		return nil, fmt.Errorf("error unmarshaling GlobalProtect sessions response: %w", err)
 	}
 
-	events := formatGPSessionEvents(m, response.Result.Sessions)
+	events := formatGPSessionEvents(m, target, response.Result.Sessions)
 
 	return events, nil
 
 }
 
-func formatGPSessionEvents(m *MetricSet, sessions []GPSession) []mb.Event {
+func formatGPSessionEvents(m *MetricSet, target panw.Target, sessions []GPSession) []mb.Event {
 	if len(sessions) == 0 {
 		return nil
 	}
@@ -78,7 +78,7 @@ func formatGPSessionEvents(m *MetricSet, sessions []GPSession) []mb.Event {
 				"globalprotect.session.request_get_config":     session.RequestGetConfig,
 				"globalprotect.session.request_sslvpn_connect": session.RequestSSLVPNConnect,
 			},
-			RootFields: panw.MakeRootFields(m.config.HostIp),
+			RootFields: target.RootFields(),
 		}
 
 		events = append(events, event)
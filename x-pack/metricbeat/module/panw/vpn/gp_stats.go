@@ -16,11 +16,11 @@ import (
 
 const gpStatsQuery = "<show><global-protect-gateway><statistics></statistics></global-protect-gateway></show>"
 
-func getGlobalProtectStatsEvents(m *MetricSet) ([]mb.Event, error) {
+func getGlobalProtectStatsEvents(m *MetricSet, target panw.Target) ([]mb.Event, error) {
 
 	var response GPStatsResponse
 
-	output, err := m.client.Op(gpStatsQuery, panw.Vsys, nil, nil)
+	output, err := target.Client.Op(gpStatsQuery, panw.Vsys, nil, nil)
 	if err != nil {
 		m.logger.Error("Error: %s", err)
 		return nil, fmt.Errorf("error querying GlobalProtect statistics: %w", err)
@@ -32,13 +32,13 @@ func getGlobalProtectStatsEvents(m *MetricSet) ([]mb.Event, error) {
 		return nil, fmt.Errorf("error unmarshaling GlobalProtect statistics response: %w", err)
 	}
 
-	events := formatGPStatsEvents(m, response)
+	events := formatGPStatsEvents(m, target, response)
 
 	return events, nil
 
 }
 
-func formatGPStatsEvents(m *MetricSet, response GPStatsResponse) []mb.Event {
+func formatGPStatsEvents(m *MetricSet, target panw.Target, response GPStatsResponse) []mb.Event {
 
 	if len(response.Result.Gateways) == 0 {
 		return nil
@@ -60,7 +60,7 @@ func formatGPStatsEvents(m *MetricSet, response GPStatsResponse) []mb.Event {
 				"globalprotect.total_current_users":    totalCurrent,
 				"globalprotect.total_previous_users":   totalPrevious,
 			},
-			RootFields: panw.MakeRootFields(m.config.HostIp),
+			RootFields: target.RootFields(),
 		}
 
 		events = append(events, event)
@@ -0,0 +1,37 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package vpn
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	mbtest "github.com/elastic/beats/v7/metricbeat/mb/testing"
+	"github.com/elastic/beats/v7/x-pack/metricbeat/module/panw/panwtest"
+)
+
+func TestFetch(t *testing.T) {
+	server := panwtest.NewServer(t)
+	server.HandleFile(gpSessionsQuery, filepath.Join("..", "_meta", "testdata", "globalprotect_current_user.xml"))
+	server.HandleFile(gpStatsQuery, filepath.Join("..", "_meta", "testdata", "globalprotect_statistics.xml"))
+	f := server.NewMetricSet(server.Config(metricsetName), NewWithClientFactory)
+
+	events, errs := mbtest.ReportingFetchV2Error(f)
+	require.Empty(t, errs)
+	require.Len(t, events, 2)
+
+	session := events[0].MetricSetFields
+	assert.Equal(t, "jdoe", session["globalprotect.session.username"])
+	assert.Equal(t, true, session["globalprotect.session.is_local"])
+	assert.Equal(t, "10.200.0.10", session["globalprotect.session.virtual_ip"])
+
+	stats := events[1].MetricSetFields
+	assert.Equal(t, "gp-gateway", stats["globalprotect.gateway.name"])
+	assert.Equal(t, 1, stats["globalprotect.gateway.current_users"])
+	assert.Equal(t, 12, stats["globalprotect.total_previous_users"])
+}
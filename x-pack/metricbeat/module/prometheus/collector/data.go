@@ -151,6 +151,21 @@ func (g *typedGenerator) GeneratePromEvents(mf *p.MetricFamily) []collector.Prom
 			*/
 		}
 
+		nativeHistogram := metric.GetNativeHistogram()
+		if nativeHistogram == nil {
+			nativeHistogram = metric.GetGaugeNativeHistogram()
+		}
+		if nativeHistogram != nil {
+			events = append(events, collector.PromEvent{
+				Data: mapstr.M{
+					name: mapstr.M{
+						"histogram": NativeHistogramToES(g.counterCache, name, labels, nativeHistogram),
+					},
+				},
+				Labels: labels,
+			})
+		}
+
 		untyped := metric.GetUnknown()
 		if untyped != nil {
 			if !math.IsNaN(untyped.GetValue()) && !math.IsInf(untyped.GetValue(), 0) {
@@ -91,3 +91,42 @@ func PromHistogramToES(cc CounterCache, name string, labels mapstr.M, histogram
 
 	return res
 }
+
+// NativeHistogramToES takes a Prometheus native histogram and converts it to an ES histogram.
+//
+// Native histograms have sparse buckets with explicit lower and upper bounds,
+// so unlike classic histograms their buckets are neither cumulative nor contiguous:
+//
+//   - values are the midpoints of the buckets, the finite bound is used for buckets
+//     with an infinite one
+//   - counts are the increase of the bucket counts since the previous fetch, as for
+//     classic histograms, or the bucket counts as-is for gauge histograms
+func NativeHistogramToES(cc CounterCache, name string, labels mapstr.M, histogram *p.NativeHistogram) mapstr.M {
+	values := make([]float64, 0, len(histogram.Buckets))
+	counts := make([]uint64, 0, len(histogram.Buckets))
+
+	for _, bucket := range histogram.Buckets {
+		lower, upper := float64(bucket.Lower), float64(bucket.Upper)
+		switch {
+		case math.IsInf(upper, 1):
+			values = append(values, lower)
+		case math.IsInf(lower, -1):
+			values = append(values, upper)
+		default:
+			values = append(values, lower+(upper-lower)/2.0)
+		}
+
+		count := uint64(math.Round(float64(bucket.Count)))
+		if !histogram.IsGaugeHistogram {
+			// New buckets and buckets after a reset count as zero, as for
+			// classic histograms.
+			count, _ = cc.RateUint64(name+labels.String()+fmt.Sprintf("%f:%f", lower, upper), count)
+		}
+		counts = append(counts, count)
+	}
+
+	return mapstr.M{
+		"values": values,
+		"counts": counts,
+	}
+}
@@ -8,9 +8,11 @@
 package collector
 
 import (
+	"math"
 	"testing"
 	"time"
 
+	"github.com/prometheus/common/model"
 	"github.com/stretchr/testify/assert"
 	"google.golang.org/protobuf/proto"
 
@@ -464,3 +466,126 @@ func TestPromHistogramToES(t *testing.T) {
 		})
 	}
 }
+
+// TestNativeHistogramToES tests that calling NativeHistogramToES multiple
+// times with the same cache produces each time the expected results.
+func TestNativeHistogramToES(t *testing.T) {
+	type sample struct {
+		histogram p.NativeHistogram
+		expected  mapstr.M
+	}
+
+	cases := map[string]struct {
+		samples []sample
+	}{
+		"rated buckets": {
+			samples: []sample{
+				{
+					histogram: p.NativeHistogram{
+						SampleHistogram: model.SampleHistogram{
+							Count: 6,
+							Sum:   4.5,
+							Buckets: model.HistogramBuckets{
+								{Boundaries: 3, Lower: -0.001, Upper: 0.001, Count: 1},
+								{Boundaries: 0, Lower: 0.5, Upper: 1, Count: 2},
+								{Boundaries: 0, Lower: 4, Upper: 8, Count: 3},
+							},
+						},
+					},
+					expected: mapstr.M{
+						"counts": []uint64{0, 0, 0},
+						"values": []float64{0, 0.75, 6},
+					},
+				},
+				{
+					histogram: p.NativeHistogram{
+						SampleHistogram: model.SampleHistogram{
+							Count: 10,
+							Sum:   12.5,
+							Buckets: model.HistogramBuckets{
+								{Boundaries: 3, Lower: -0.001, Upper: 0.001, Count: 1},
+								{Boundaries: 0, Lower: 0.5, Upper: 1, Count: 4},
+								// New bucket on the go
+								{Boundaries: 0, Lower: 1, Upper: 2, Count: 1},
+								{Boundaries: 0, Lower: 4, Upper: 8, Count: 4},
+							},
+						},
+					},
+					expected: mapstr.M{
+						"counts": []uint64{0, 2, 0, 1},
+						"values": []float64{0, 0.75, 1.5, 6},
+					},
+				},
+			},
+		},
+		"infinite bounds": {
+			samples: []sample{
+				{
+					histogram: p.NativeHistogram{
+						SampleHistogram: model.SampleHistogram{
+							Count: 3,
+							Buckets: model.HistogramBuckets{
+								{Boundaries: 0, Lower: model.FloatString(math.Inf(-1)), Upper: -4, Count: 1},
+								{Boundaries: 0, Lower: 4, Upper: model.FloatString(math.Inf(1)), Count: 2},
+							},
+						},
+						IsGaugeHistogram: true,
+					},
+					expected: mapstr.M{
+						"counts": []uint64{1, 2},
+						"values": []float64{-4, 4},
+					},
+				},
+			},
+		},
+		"gauge histogram": {
+			samples: []sample{
+				{
+					histogram: p.NativeHistogram{
+						SampleHistogram: model.SampleHistogram{
+							Count: 3,
+							Buckets: model.HistogramBuckets{
+								{Boundaries: 0, Lower: 1, Upper: 2, Count: 3},
+							},
+						},
+						IsGaugeHistogram: true,
+					},
+					expected: mapstr.M{
+						"counts": []uint64{3},
+						"values": []float64{1.5},
+					},
+				},
+				{
+					histogram: p.NativeHistogram{
+						SampleHistogram: model.SampleHistogram{
+							Count: 1,
+							Buckets: model.HistogramBuckets{
+								{Boundaries: 0, Lower: 1, Upper: 2, Count: 1},
+							},
+						},
+						IsGaugeHistogram: true,
+					},
+					expected: mapstr.M{
+						"counts": []uint64{1},
+						"values": []float64{1.5},
+					},
+				},
+			},
+		},
+	}
+
+	metricName := "somemetric"
+	labels := mapstr.M{}
+
+	for title, c := range cases {
+		t.Run(title, func(t *testing.T) {
+			cache := NewCounterCache(120 * time.Minute)
+
+			for i, s := range c.samples {
+				t.Logf("#%d: %+v", i, s.histogram)
+				result := NativeHistogramToES(cache, metricName, labels, &s.histogram)
+				assert.EqualValues(t, s.expected, result)
+			}
+		})
+	}
+}
@@ -112,17 +112,24 @@ func (g remoteWriteTypedGenerator) GenerateEvents(metrics model.Samples) map[str
 
 		labels := mapstr.M{}
 		val := float64(metric.Value)
-		if math.IsNaN(val) || math.IsInf(val, 0) {
+		if metric.Histogram == nil && (math.IsNaN(val) || math.IsInf(val, 0)) {
 			continue
 		}
 
 		name := string(metric.Metric["__name__"])
 		delete(metric.Metric, "__name__")
+		isGaugeHistogram := metric.Metric[rw.TypeLabel] == rw.GaugeHistogramType
+		delete(metric.Metric, rw.TypeLabel)
 
 		for k, v := range metric.Metric {
 			labels[string(k)] = v
 		}
 
+		if metric.Histogram != nil {
+			g.processNativeHistogram(eventList, name, labels, metric, isGaugeHistogram)
+			continue
+		}
+
 		promType := g.findMetricType(name, labels)
 
 		labelsHash := labels.String() + metric.Timestamp.Time().String()
@@ -258,6 +265,40 @@ func (g *remoteWriteTypedGenerator) processPromHistograms(eventList map[string]m
 	}
 }
 
+// processNativeHistogram converts a native histogram to ES histogram, and its
+// sum and count to counters as they are for classic histograms, or to gauges
+// for gauge histograms
+func (g *remoteWriteTypedGenerator) processNativeHistogram(eventList map[string]mb.Event, name string, labels mapstr.M, metric *model.Sample, isGaugeHistogram bool) {
+	labelsHash := labels.String() + metric.Timestamp.Time().String()
+	if _, ok := eventList[labelsHash]; !ok {
+		eventList[labelsHash] = mb.Event{
+			RootFields:   mapstr.M{},
+			ModuleFields: mapstr.M{},
+			Timestamp:    metric.Timestamp.Time(),
+		}
+
+		// Add labels
+		if len(labels) > 0 {
+			eventList[labelsHash].ModuleFields["labels"] = labels
+		}
+	}
+
+	histogram := &p.NativeHistogram{SampleHistogram: *metric.Histogram, IsGaugeHistogram: isGaugeHistogram}
+	data := mapstr.M{
+		name: mapstr.M{
+			"histogram": collector.NativeHistogramToES(g.counterCache, name, labels, histogram),
+		},
+	}
+	if isGaugeHistogram {
+		data[name+"_sum"] = mapstr.M{"value": histogram.GetSampleSum()}
+		data[name+"_count"] = mapstr.M{"value": histogram.GetSampleCount()}
+	} else {
+		data[name+"_sum"] = g.rateCounterFloat64(name+"_sum", labels, histogram.GetSampleSum())
+		data[name+"_count"] = g.rateCounterFloat64(name+"_count", labels, histogram.GetSampleCount())
+	}
+	eventList[labelsHash].ModuleFields.Update(data)
+}
+
 // findMetricType evaluates the type of the metric by check the metricname format in order to handle it properly
 func (g *remoteWriteTypedGenerator) findMetricType(metricName string, labels mapstr.M) string {
 	leLabel := false
@@ -14,6 +14,7 @@ import (
 	"github.com/stretchr/testify/assert"
 
 	p "github.com/elastic/beats/v7/metricbeat/helper/prometheus"
+	rw "github.com/elastic/beats/v7/metricbeat/module/prometheus/remote_write"
 	xcollector "github.com/elastic/beats/v7/x-pack/metricbeat/module/prometheus/collector"
 	"github.com/elastic/elastic-agent-libs/mapstr"
 )
@@ -1450,3 +1451,158 @@ func TestMetricsCount(t *testing.T) {
 		})
 	}
 }
+
+func TestGenerateEventsNativeHistogram(t *testing.T) {
+	counters := xcollector.NewCounterCache(1 * time.Second)
+
+	g := remoteWriteTypedGenerator{
+		counterCache: counters,
+		rateCounters: true,
+	}
+
+	g.counterCache.Start()
+	timestamp := model.Time(424242)
+	labels := mapstr.M{
+		"handler": model.LabelValue("/metrics"),
+	}
+
+	// first fetch
+	metrics := model.Samples{
+		&model.Sample{
+			Metric: map[model.LabelName]model.LabelValue{
+				"__name__": "http_request_duration_seconds",
+				"handler":  "/metrics",
+			},
+			Histogram: &model.SampleHistogram{
+				Count: 6,
+				Sum:   4.5,
+				Buckets: model.HistogramBuckets{
+					{Boundaries: 3, Lower: -0.001, Upper: 0.001, Count: 1},
+					{Boundaries: 0, Lower: 0.5, Upper: 1, Count: 2},
+					{Boundaries: 0, Lower: 4, Upper: 8, Count: 3},
+				},
+			},
+			Timestamp: timestamp,
+		},
+	}
+	events := g.GenerateEvents(metrics)
+
+	expected := mapstr.M{
+		"http_request_duration_seconds": mapstr.M{
+			"histogram": mapstr.M{
+				"values": []float64{0, 0.75, 6},
+				"counts": []uint64{0, 0, 0},
+			},
+		},
+		"http_request_duration_seconds_sum": mapstr.M{
+			"counter": float64(4.5),
+			"rate":    float64(0),
+		},
+		"http_request_duration_seconds_count": mapstr.M{
+			"counter": float64(6),
+			"rate":    float64(0),
+		},
+		"labels": labels,
+	}
+
+	assert.Equal(t, len(events), 1)
+	e := events[labels.String()+timestamp.Time().String()]
+	assert.EqualValues(t, e.ModuleFields, expected)
+
+	// repeat in order to test the rate
+	metrics = model.Samples{
+		&model.Sample{
+			Metric: map[model.LabelName]model.LabelValue{
+				"__name__": "http_request_duration_seconds",
+				"handler":  "/metrics",
+			},
+			Histogram: &model.SampleHistogram{
+				Count: 9,
+				Sum:   10.5,
+				Buckets: model.HistogramBuckets{
+					{Boundaries: 3, Lower: -0.001, Upper: 0.001, Count: 1},
+					{Boundaries: 0, Lower: 0.5, Upper: 1, Count: 3},
+					{Boundaries: 0, Lower: 4, Upper: 8, Count: 5},
+				},
+			},
+			Timestamp: timestamp,
+		},
+	}
+	events = g.GenerateEvents(metrics)
+
+	expected = mapstr.M{
+		"http_request_duration_seconds": mapstr.M{
+			"histogram": mapstr.M{
+				"values": []float64{0, 0.75, 6},
+				"counts": []uint64{0, 1, 2},
+			},
+		},
+		"http_request_duration_seconds_sum": mapstr.M{
+			"counter": float64(10.5),
+			"rate":    float64(6),
+		},
+		"http_request_duration_seconds_count": mapstr.M{
+			"counter": float64(9),
+			"rate":    float64(3),
+		},
+		"labels": labels,
+	}
+
+	assert.Equal(t, len(events), 1)
+	e = events[labels.String()+timestamp.Time().String()]
+	assert.EqualValues(t, e.ModuleFields, expected)
+}
+
+// TestGenerateEventsNativeGaugeHistogram tests that the buckets, sum and count
+// of native gauge histograms are reported as they are
+func TestGenerateEventsNativeGaugeHistogram(t *testing.T) {
+	counters := xcollector.NewCounterCache(1 * time.Second)
+
+	g := remoteWriteTypedGenerator{
+		counterCache: counters,
+		rateCounters: true,
+	}
+
+	g.counterCache.Start()
+	timestamp := model.Time(424242)
+
+	for _, count := range []float64{3, 2} {
+		metrics := model.Samples{
+			&model.Sample{
+				Metric: map[model.LabelName]model.LabelValue{
+					"__name__":   "temperature_delta",
+					rw.TypeLabel: rw.GaugeHistogramType,
+				},
+				Histogram: &model.SampleHistogram{
+					Count: model.FloatString(count + 1),
+					Sum:   -3,
+					Buckets: model.HistogramBuckets{
+						{Boundaries: 0, Lower: -1, Upper: -0.5, Count: 1},
+						{Boundaries: 0, Lower: 0.5, Upper: 1, Count: model.FloatString(count)},
+					},
+				},
+				Timestamp: timestamp,
+			},
+		}
+		events := g.GenerateEvents(metrics)
+
+		expected := mapstr.M{
+			"temperature_delta": mapstr.M{
+				"histogram": mapstr.M{
+					"values": []float64{-0.75, 0.75},
+					"counts": []uint64{1, uint64(count)},
+				},
+			},
+			"temperature_delta_sum": mapstr.M{
+				"value": float64(-3),
+			},
+			"temperature_delta_count": mapstr.M{
+				"value": count + 1,
+			},
+		}
+
+		assert.Equal(t, len(events), 1)
+		e := events[mapstr.M{}.String()+timestamp.Time().String()]
+		assert.EqualValues(t, expected, e.ModuleFields)
+	}
+}
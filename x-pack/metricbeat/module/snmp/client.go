@@ -0,0 +1,127 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package snmp
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gosnmp/gosnmp"
+
+	"github.com/elastic/beats/v7/metricbeat/mb"
+	"github.com/elastic/beats/v7/metricbeat/mb/parse"
+)
+
+// HostParser parses the hosts of the snmp metricsets. Hosts can be given as
+// host, host:port or udp://host:port. tcp:// selects the TCP transport.
+var HostParser = parse.URLHostParserBuilder{DefaultScheme: "udp"}.Build()
+
+var authProtocols = map[string]gosnmp.SnmpV3AuthProtocol{
+	"MD5":    gosnmp.MD5,
+	"SHA":    gosnmp.SHA,
+	"SHA224": gosnmp.SHA224,
+	"SHA256": gosnmp.SHA256,
+	"SHA384": gosnmp.SHA384,
+	"SHA512": gosnmp.SHA512,
+}
+
+var privProtocols = map[string]gosnmp.SnmpV3PrivProtocol{
+	"DES":     gosnmp.DES,
+	"AES":     gosnmp.AES,
+	"AES192":  gosnmp.AES192,
+	"AES256":  gosnmp.AES256,
+	"AES192C": gosnmp.AES192C,
+	"AES256C": gosnmp.AES256C,
+}
+
+// NewClient returns a connected SNMP client for the host of the metricset.
+func NewClient(base mb.BaseMetricSet, config *Config) (*gosnmp.GoSNMP, error) {
+	transport, host, port, err := splitHost(base.HostData())
+	if err != nil {
+		return nil, err
+	}
+
+	client := &gosnmp.GoSNMP{
+		Target:         host,
+		Port:           port,
+		Transport:      transport,
+		Community:      config.Community,
+		Timeout:        base.Module().Config().Timeout,
+		Retries:        config.Retries,
+		MaxOids:        gosnmp.MaxOids,
+		MaxRepetitions: config.MaxRepetitions,
+	}
+	if client.Timeout <= 0 {
+		client.Timeout = 5 * time.Second
+	}
+
+	switch config.Version {
+	case "1":
+		client.Version = gosnmp.Version1
+	case "2c":
+		client.Version = gosnmp.Version2c
+	case "3":
+		client.Version = gosnmp.Version3
+		setV3Security(client, config.V3)
+	}
+
+	if err := client.Connect(); err != nil {
+		return nil, fmt.Errorf("failed to connect to %s: %w", base.HostData().SanitizedURI, err)
+	}
+	return client, nil
+}
+
+func setV3Security(client *gosnmp.GoSNMP, config *V3Config) {
+	params := &gosnmp.UsmSecurityParameters{UserName: config.Username}
+
+	switch strings.ToLower(config.SecurityLevel) {
+	case "authpriv":
+		client.MsgFlags = gosnmp.AuthPriv
+		params.PrivacyProtocol = privProtocols[strings.ToUpper(config.PrivProtocol)]
+		params.PrivacyPassphrase = config.PrivPassword
+		fallthrough
+	case "authnopriv":
+		if client.MsgFlags == 0 {
+			client.MsgFlags = gosnmp.AuthNoPriv
+		}
+		params.AuthenticationProtocol = authProtocols[strings.ToUpper(config.AuthProtocol)]
+		params.AuthenticationPassphrase = config.AuthPassword
+	default:
+		client.MsgFlags = gosnmp.NoAuthNoPriv
+	}
+
+	client.SecurityModel = gosnmp.UserSecurityModel
+	client.SecurityParameters = params
+	client.ContextName = config.ContextName
+}
+
+func splitHost(hostData mb.HostData) (transport, host string, port uint16, err error) {
+	u, err := url.Parse(hostData.URI)
+	if err != nil {
+		return "", "", 0, fmt.Errorf("failed to parse host %s: %w", hostData.SanitizedURI, err)
+	}
+
+	switch u.Scheme {
+	case "udp", "tcp":
+		transport = u.Scheme
+	default:
+		return "", "", 0, fmt.Errorf("unsupported scheme %q in host %s, must be udp or tcp", u.Scheme, hostData.SanitizedURI)
+	}
+
+	host, portStr, err := net.SplitHostPort(u.Host)
+	if err != nil {
+		// No port in the host.
+		return transport, u.Hostname(), DefaultPort, nil
+	}
+	p, err := strconv.ParseUint(portStr, 10, 16)
+	if err != nil {
+		return "", "", 0, fmt.Errorf("invalid port in host %s: %w", hostData.SanitizedURI, err)
+	}
+	return transport, host, uint16(p), nil
+}
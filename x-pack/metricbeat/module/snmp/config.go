@@ -0,0 +1,139 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package snmp
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const (
+	// ModuleName is the name of the module.
+	ModuleName = "snmp"
+
+	// DefaultPort is the port used when the host does not specify one.
+	DefaultPort = 161
+)
+
+// Config contains the connection settings shared by all the snmp metricsets.
+type Config struct {
+	Version        string    `config:"version"`
+	Community      string    `config:"community"`
+	Retries        int       `config:"retries"`
+	MaxRepetitions uint32    `config:"max_repetitions"`
+	V3             *V3Config `config:"v3"`
+	// MIBPaths are the MIB files, or directories containing them, used to
+	// resolve OIDs given by name and to name the collected fields.
+	MIBPaths []string `config:"mib_paths"`
+}
+
+// V3Config contains the SNMPv3 user based security model settings.
+type V3Config struct {
+	Username      string `config:"username"`
+	SecurityLevel string `config:"security_level"`
+	AuthProtocol  string `config:"auth_protocol"`
+	AuthPassword  string `config:"auth_password"`
+	PrivProtocol  string `config:"priv_protocol"`
+	PrivPassword  string `config:"priv_password"`
+	ContextName   string `config:"context_name"`
+}
+
+// OIDConfig maps an OID to an event field.
+type OIDConfig struct {
+	// OID is the numeric OID, or the MIB name of the object, e.g.
+	// "SNMPv2-MIB::sysUpTime.0". Names require mib_paths.
+	OID string `config:"oid" validate:"required"`
+	// Field is the name of the event field. It defaults to the MIB name of
+	// the object.
+	Field string `config:"field"`
+	// Conversion overrides how octet strings are converted: "string",
+	// "hex" or "hwaddr".
+	Conversion string `config:"conversion"`
+	// Rate reports the per second rate of change of the value in the
+	// <field>_per_sec field, taking counter wraps into account.
+	Rate bool `config:"rate"`
+}
+
+var conversions = map[string]bool{
+	"":       true,
+	"string": true,
+	"hex":    true,
+	"hwaddr": true,
+}
+
+func defaultConfig() Config {
+	return Config{
+		Version:        "2c",
+		Community:      "public",
+		Retries:        1,
+		MaxRepetitions: 10,
+	}
+}
+
+// NewConfig returns the module configuration with the defaults applied.
+func NewConfig(unpack func(interface{}) error) (*Config, error) {
+	config := defaultConfig()
+	if err := unpack(&config); err != nil {
+		return nil, err
+	}
+	return &config, nil
+}
+
+// Validate validates the module configuration.
+func (c *Config) Validate() error {
+	switch c.Version {
+	case "1", "2c":
+		if c.Community == "" {
+			return fmt.Errorf("community is required for SNMP version %s", c.Version)
+		}
+	case "3":
+		if c.V3 == nil || c.V3.Username == "" {
+			return errors.New("v3.username is required for SNMP version 3")
+		}
+		return c.V3.Validate()
+	default:
+		return fmt.Errorf("unsupported SNMP version %q, must be 1, 2c or 3", c.Version)
+	}
+	return nil
+}
+
+// Validate validates the SNMPv3 settings.
+func (c *V3Config) Validate() error {
+	level := strings.ToLower(c.SecurityLevel)
+	switch level {
+	case "", "noauthnopriv":
+		return nil
+	case "authnopriv", "authpriv":
+	default:
+		return fmt.Errorf("unsupported v3.security_level %q, must be noAuthNoPriv, authNoPriv or authPriv", c.SecurityLevel)
+	}
+
+	if _, ok := authProtocols[strings.ToUpper(c.AuthProtocol)]; !ok {
+		return fmt.Errorf("unsupported v3.auth_protocol %q", c.AuthProtocol)
+	}
+	if c.AuthPassword == "" {
+		return errors.New("v3.auth_password is required when authentication is enabled")
+	}
+	if level == "authnopriv" {
+		return nil
+	}
+
+	if _, ok := privProtocols[strings.ToUpper(c.PrivProtocol)]; !ok {
+		return fmt.Errorf("unsupported v3.priv_protocol %q", c.PrivProtocol)
+	}
+	if c.PrivPassword == "" {
+		return errors.New("v3.priv_password is required when privacy is enabled")
+	}
+	return nil
+}
+
+// Validate validates the OID mapping.
+func (c *OIDConfig) Validate() error {
+	if !conversions[c.Conversion] {
+		return fmt.Errorf("unsupported conversion %q for OID %s, must be string, hex or hwaddr", c.Conversion, c.OID)
+	}
+	return nil
+}
@@ -0,0 +1,23 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+// Code generated by beats/dev-tools/cmd/asset/asset.go - DO NOT EDIT.
+
+package snmp
+
+import (
+	"github.com/elastic/beats/v7/libbeat/asset"
+)
+
+func init() {
+	if err := asset.SetFields("metricbeat", "snmp", asset.ModuleFieldsPri, AssetSnmp); err != nil {
+		panic(err)
+	}
+}
+
+// AssetSnmp returns asset data.
+// This is the base64 encoded zlib format compressed contents of module/snmp.
+func AssetSnmp() string {
+	return "eJzkU8FunEAMvfMVTzlWWT4AVTmkuXDYpEqlXpeBMey0wxjNGNH9+4phQSRQbQ65FeZkW8/Pfs8H/KZLhuDaLgHEiKUMP56P3xPAkyUVKENJohJAU6i86cSwy/CQAIiVaFn3lhKgNmR1yMbMAU61tAADkEtHGRrP/TWwAwfgp7I9BXRsLWnUntupiWrISUinslUjYNWtIVliOx3jez/V/O3TWXPiGnImhEpZ5fGSPwVU7GrT9J40jEPBRofiynGX6Jrs3Ze7N/GZMJe/qFrPMf5T8DRWnDrlVfsOdHyHdVU2Cjuw17fKLLvmVo3mvowKAx9a2LI0cA213tJL/nQfxdIoLzASUEQxCwQSMa4B+zlzzB83qNOEESHFqxIKUJ7gqWMvVxW+RsSHU0f+FKgq0o1PRL0d59Oc8srD4pNo29hp45Mp+mGnjNPuWmVf4hvCPKuWZo6RSGTreUBJoxkChNNdHsZp+vN5RPIRbmbiebiPREJfHowmJ6Y25APkrAQ1W8tDzFds+9bF80v/x7v6FudfXBb3Fp1LGr3T5GPYbURWTi+HtwGVMxn/j1Occsf88dbxbVD3j/HvAGJCwb4="
+}
@@ -0,0 +1,140 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package get
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gosnmp/gosnmp"
+
+	"github.com/elastic/beats/v7/libbeat/common/cfgwarn"
+	"github.com/elastic/beats/v7/metricbeat/mb"
+	"github.com/elastic/beats/v7/x-pack/metricbeat/module/snmp"
+	"github.com/elastic/elastic-agent-libs/mapstr"
+)
+
+func init() {
+	mb.Registry.MustAddMetricSet(snmp.ModuleName, "get", New,
+		mb.WithHostParser(snmp.HostParser),
+	)
+}
+
+type config struct {
+	OIDs []snmp.OIDConfig `config:"oids" validate:"required"`
+}
+
+// MetricSet gets a list of scalar OIDs from an SNMP agent and reports them
+// in a single event.
+type MetricSet struct {
+	mb.BaseMetricSet
+	client   *gosnmp.GoSNMP
+	mappings []snmp.Mapping
+	byOID    map[string]snmp.Mapping
+	rates    *snmp.RateCalculator
+	// hasRates is set when rates are enabled, sysUpTime is then fetched to
+	// detect agent restarts.
+	hasRates bool
+}
+
+// New creates a new instance of the MetricSet.
+func New(base mb.BaseMetricSet) (mb.MetricSet, error) {
+	cfgwarn.Beta("The snmp get metricset is beta.")
+
+	moduleConfig, err := snmp.NewConfig(base.Module().UnpackConfig)
+	if err != nil {
+		return nil, err
+	}
+	var config config
+	if err := base.Module().UnpackConfig(&config); err != nil {
+		return nil, err
+	}
+
+	var mib *snmp.MIB
+	if len(moduleConfig.MIBPaths) > 0 {
+		mib, err = snmp.LoadMIBs(moduleConfig.MIBPaths)
+		if err != nil {
+			return nil, err
+		}
+	}
+	mappings, err := snmp.ResolveMappings(mib, config.OIDs)
+	if err != nil {
+		return nil, err
+	}
+	byOID := make(map[string]snmp.Mapping, len(mappings))
+	for _, m := range mappings {
+		byOID[m.Numeric] = m
+	}
+
+	client, err := snmp.NewClient(base, moduleConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	return &MetricSet{
+		BaseMetricSet: base,
+		client:        client,
+		mappings:      mappings,
+		byOID:         byOID,
+		rates:         snmp.NewRateCalculator(),
+		hasRates:      snmp.HasRates(mappings),
+	}, nil
+}
+
+// Fetch gets the configured OIDs and reports their values. OIDs that do not
+// exist in the agent are omitted from the event.
+func (m *MetricSet) Fetch(r mb.ReporterV2) error {
+	now := time.Now()
+	fields := mapstr.M{}
+	if m.hasRates {
+		m.rates.UpdateUptime(m.client)
+	}
+
+	for start := 0; start < len(m.mappings); start += m.client.MaxOids {
+		end := start + m.client.MaxOids
+		if end > len(m.mappings) {
+			end = len(m.mappings)
+		}
+		oids := make([]string, 0, end-start)
+		for _, mapping := range m.mappings[start:end] {
+			oids = append(oids, "."+mapping.Numeric)
+		}
+
+		packet, err := m.client.Get(oids)
+		if err != nil {
+			return fmt.Errorf("SNMP get failed: %w", err)
+		}
+		if packet.Error != gosnmp.NoError {
+			// ErrorIndex is 1-based.
+			oid := ""
+			if i := int(packet.ErrorIndex) - 1; i >= 0 && i < len(oids) {
+				oid = oids[i]
+			}
+			return fmt.Errorf("SNMP get failed with %s for OID %s", packet.Error, oid)
+		}
+
+		for _, pdu := range packet.Variables {
+			numeric := strings.TrimPrefix(pdu.Name, ".")
+			mapping, ok := m.byOID[numeric]
+			if !ok {
+				continue
+			}
+			mapping.Put(fields, pdu, m.rates, numeric, now)
+		}
+	}
+
+	if len(fields) == 0 {
+		return errors.New("none of the configured OIDs were found")
+	}
+	r.Event(mb.Event{MetricSetFields: fields})
+	return nil
+}
+
+// Close closes the connection to the agent.
+func (m *MetricSet) Close() error {
+	return m.client.Conn.Close()
+}
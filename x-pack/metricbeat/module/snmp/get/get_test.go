@@ -0,0 +1,171 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package get
+
+import (
+	"math"
+	"testing"
+
+	"github.com/gosnmp/gosnmp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	mbtest "github.com/elastic/beats/v7/metricbeat/mb/testing"
+	"github.com/elastic/beats/v7/x-pack/metricbeat/module/snmp/snmptest"
+	"github.com/elastic/elastic-agent-libs/mapstr"
+)
+
+func newAgent(t *testing.T) *snmptest.Agent {
+	agent := snmptest.NewAgent(t)
+	agent.Set("1.3.6.1.2.1.1.1.0", gosnmp.OctetString, "Linux router 6.1.0")
+	agent.Set("1.3.6.1.2.1.1.3.0", gosnmp.TimeTicks, uint32(123456))
+	agent.Set("1.3.6.1.2.1.1.5.0", gosnmp.OctetString, "router-1")
+	agent.Set("1.3.6.1.2.1.2.1.0", gosnmp.Integer, 2)
+	agent.Set("1.3.6.1.2.1.31.1.1.1.6.1", gosnmp.Counter64, uint64(1000))
+	agent.Set("1.3.6.1.4.1.99999.1.1.0", gosnmp.OctetString, "test-device")
+	agent.Set("1.3.6.1.4.1.99999.1.2.0", gosnmp.Counter32, uint32(math.MaxUint32-10))
+	return agent
+}
+
+func getConfig(agent *snmptest.Agent) map[string]interface{} {
+	config := agent.Config("get")
+	config["oids"] = []map[string]interface{}{
+		{"oid": "1.3.6.1.2.1.1.1.0", "field": "sys.descr"},
+		{"oid": ".1.3.6.1.2.1.1.3.0", "field": "sys.uptime"},
+		{"oid": "1.3.6.1.2.1.1.5.0", "field": "sys.name"},
+		{"oid": "1.3.6.1.2.1.2.1.0", "field": "interfaces"},
+		{"oid": "1.3.6.1.2.1.31.1.1.1.6.1", "field": "uplink.in_octets", "rate": true},
+	}
+	return config
+}
+
+func TestFetch(t *testing.T) {
+	agent := newAgent(t)
+
+	f := mbtest.NewReportingMetricSetV2Error(t, getConfig(agent))
+	events, errs := mbtest.ReportingFetchV2Error(f)
+	require.Empty(t, errs)
+	require.Len(t, events, 1)
+
+	assert.Equal(t, mapstr.M{
+		"sys": mapstr.M{
+			"descr":  "Linux router 6.1.0",
+			"uptime": uint64(123456),
+			"name":   "router-1",
+		},
+		"interfaces": int64(2),
+		"uplink": mapstr.M{
+			"in_octets": uint64(1000),
+		},
+	}, events[0].MetricSetFields)
+
+	agent.Set("1.3.6.1.2.1.31.1.1.1.6.1", gosnmp.Counter64, uint64(5000))
+	events, errs = mbtest.ReportingFetchV2Error(f)
+	require.Empty(t, errs)
+	require.Len(t, events, 1)
+
+	rate, err := events[0].MetricSetFields.GetValue("uplink.in_octets_per_sec")
+	require.NoError(t, err)
+	assert.Greater(t, rate, float64(0))
+}
+
+func TestFetchWithMIBs(t *testing.T) {
+	agent := newAgent(t)
+
+	config := agent.Config("get")
+	config["mib_paths"] = []string{"../testdata"}
+	config["oids"] = []map[string]interface{}{
+		{"oid": "TEST-MIB::testName.0"},
+		{"oid": "testPackets.0", "rate": true},
+	}
+
+	f := mbtest.NewReportingMetricSetV2Error(t, config)
+	events, errs := mbtest.ReportingFetchV2Error(f)
+	require.Empty(t, errs)
+	require.Len(t, events, 1)
+	assert.Equal(t, mapstr.M{
+		"testName":    "test-device",
+		"testPackets": uint64(math.MaxUint32 - 10),
+	}, events[0].MetricSetFields)
+
+	// The counter wraps between fetches.
+	agent.Set("1.3.6.1.4.1.99999.1.2.0", gosnmp.Counter32, uint32(100))
+	events, errs = mbtest.ReportingFetchV2Error(f)
+	require.Empty(t, errs)
+	require.Len(t, events, 1)
+
+	assert.Equal(t, uint64(100), events[0].MetricSetFields["testPackets"])
+	rate, ok := events[0].MetricSetFields["testPackets_per_sec"].(float64)
+	require.True(t, ok, "rate is reported after a counter wrap")
+	assert.Greater(t, rate, float64(0))
+}
+
+func TestFetchAgentRestart(t *testing.T) {
+	agent := newAgent(t)
+
+	config := agent.Config("get")
+	config["oids"] = []map[string]interface{}{
+		{"oid": "1.3.6.1.4.1.99999.1.2.0", "field": "packets", "rate": true},
+	}
+	f := mbtest.NewReportingMetricSetV2Error(t, config)
+	_, errs := mbtest.ReportingFetchV2Error(f)
+	require.Empty(t, errs)
+
+	// The counter is lower because the agent restarted, not because it wrapped.
+	agent.Set("1.3.6.1.2.1.1.3.0", gosnmp.TimeTicks, uint32(100))
+	agent.Set("1.3.6.1.4.1.99999.1.2.0", gosnmp.Counter32, uint32(100))
+	events, errs := mbtest.ReportingFetchV2Error(f)
+	require.Empty(t, errs)
+	require.Len(t, events, 1)
+	assert.Equal(t, mapstr.M{"packets": uint64(100)}, events[0].MetricSetFields)
+}
+
+func TestFetchMissingOID(t *testing.T) {
+	for _, version := range []string{"1", "2c"} {
+		t.Run("v"+version, func(t *testing.T) {
+			agent := newAgent(t)
+
+			config := getConfig(agent)
+			config["version"] = version
+			config["oids"] = []map[string]interface{}{
+				{"oid": "1.3.6.1.2.1.1.5.0", "field": "sys.name"},
+				{"oid": "1.3.6.1.2.1.1.6.0", "field": "sys.location"},
+			}
+
+			f := mbtest.NewReportingMetricSetV2Error(t, config)
+			events, errs := mbtest.ReportingFetchV2Error(f)
+			if version == "1" {
+				// SNMPv1 fails the whole request.
+				require.Len(t, errs, 1)
+				assert.ErrorContains(t, errs[0], "NoSuchName for OID .1.3.6.1.2.1.1.6.0")
+				return
+			}
+			require.Empty(t, errs)
+			require.Len(t, events, 1)
+			assert.Equal(t, mapstr.M{"sys": mapstr.M{"name": "router-1"}}, events[0].MetricSetFields)
+		})
+	}
+}
+
+func TestFetchWrongCommunity(t *testing.T) {
+	agent := newAgent(t)
+
+	config := getConfig(agent)
+	config["community"] = "wrong"
+	config["timeout"] = "100ms"
+
+	f := mbtest.NewReportingMetricSetV2Error(t, config)
+	_, errs := mbtest.ReportingFetchV2Error(f)
+	require.Len(t, errs, 1)
+	assert.ErrorContains(t, errs[0], "SNMP get failed")
+}
+
+func TestData(t *testing.T) {
+	agent := newAgent(t)
+
+	f := mbtest.NewReportingMetricSetV2Error(t, getConfig(agent))
+	err := mbtest.WriteEventsReporterV2Error(f, t, "")
+	require.NoError(t, err)
+}
@@ -0,0 +1,115 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package snmp
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gosnmp/gosnmp"
+
+	"github.com/elastic/elastic-agent-libs/mapstr"
+)
+
+// Mapping is an OID mapping with its OID resolved to a numeric OID and its
+// field name set.
+type Mapping struct {
+	OIDConfig
+	// Numeric is the numeric OID, without leading dot.
+	Numeric string
+}
+
+// ResolveMappings resolves the OIDs of the mappings and sets the field of
+// the mappings without one to the MIB name of their object.
+func ResolveMappings(mib *MIB, configs []OIDConfig) ([]Mapping, error) {
+	mappings := make([]Mapping, 0, len(configs))
+	for _, c := range configs {
+		oid, err := mib.Resolve(c.OID)
+		if err != nil {
+			return nil, err
+		}
+
+		m := Mapping{OIDConfig: c, Numeric: oid}
+		if m.Field == "" {
+			name, suffix, ok := mib.Name(oid)
+			if !ok {
+				return nil, fmt.Errorf("field is required for OID %s", c.OID)
+			}
+			m.Field = FieldName(name, suffix)
+		}
+		mappings = append(mappings, m)
+	}
+	return mappings, nil
+}
+
+// FieldName returns the field name of the object name with the instance
+// sub-identifiers suffix. Scalar instances (".0") are omitted.
+func FieldName(name, suffix string) string {
+	if suffix == "" || suffix == "0" {
+		return name
+	}
+	return name + "_" + strings.ReplaceAll(suffix, ".", "_")
+}
+
+// Put puts the value of pdu in fields, and its rate when enabled. Key
+// identifies the value for the rate calculation.
+func (m Mapping) Put(fields mapstr.M, pdu gosnmp.SnmpPDU, rates *RateCalculator, key string, now time.Time) {
+	value, ok := ConvertValue(pdu, m.Conversion)
+	if !ok {
+		return
+	}
+	_, _ = fields.Put(m.Field, value)
+
+	if !m.Rate {
+		return
+	}
+	n, ok := value.(uint64)
+	if !ok {
+		i, isInt := value.(int64)
+		if !isInt || i < 0 {
+			return
+		}
+		n = uint64(i)
+	}
+	if rate, ok := rates.Rate(key, pdu.Type, n, now); ok {
+		_, _ = fields.Put(m.Field+"_per_sec", rate)
+	}
+}
+
+// HasRates returns whether the rate is enabled for any of the mappings.
+func HasRates(mappings []Mapping) bool {
+	for _, m := range mappings {
+		if m.Rate {
+			return true
+		}
+	}
+	return false
+}
+
+// CompareOIDs compares two numeric OIDs by their sub-identifiers. The result
+// is -1 if a sorts before b, 0 if they are equal and +1 otherwise.
+func CompareOIDs(a, b string) int {
+	as := strings.Split(strings.TrimPrefix(a, "."), ".")
+	bs := strings.Split(strings.TrimPrefix(b, "."), ".")
+	for i := 0; i < len(as) && i < len(bs); i++ {
+		x, _ := strconv.ParseUint(as[i], 10, 32)
+		y, _ := strconv.ParseUint(bs[i], 10, 32)
+		switch {
+		case x < y:
+			return -1
+		case x > y:
+			return 1
+		}
+	}
+	switch {
+	case len(as) < len(bs):
+		return -1
+	case len(as) > len(bs):
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,376 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package snmp
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// MIB resolves object names to OIDs and OIDs to object names using the
+// definitions loaded from MIB files. Only the OID assignments are parsed, the
+// syntax of the objects is ignored.
+type MIB struct {
+	// oids maps "MODULE::name" and "name" to the numeric OID.
+	oids map[string]string
+	// names maps numeric OIDs to object names.
+	names map[string]string
+}
+
+// wellKnown are the nodes defined by the SMI modules, so they can be resolved
+// when those modules are not loaded.
+var wellKnown = map[string]string{
+	"ccitt":           "0",
+	"iso":             "1",
+	"joint-iso-ccitt": "2",
+	"zeroDotZero":     "0.0",
+	"org":             "1.3",
+	"dod":             "1.3.6",
+	"internet":        "1.3.6.1",
+	"directory":       "1.3.6.1.1",
+	"mgmt":            "1.3.6.1.2",
+	"mib-2":           "1.3.6.1.2.1",
+	"transmission":    "1.3.6.1.2.1.10",
+	"experimental":    "1.3.6.1.3",
+	"private":         "1.3.6.1.4",
+	"enterprises":     "1.3.6.1.4.1",
+	"security":        "1.3.6.1.5",
+	"snmpV2":          "1.3.6.1.6",
+	"snmpDomains":     "1.3.6.1.6.1",
+	"snmpProxys":      "1.3.6.1.6.2",
+	"snmpModules":     "1.3.6.1.6.3",
+}
+
+// definitionKeywords are the macros whose value is an OID assignment.
+var definitionKeywords = map[string]bool{
+	"OBJECT-TYPE":        true,
+	"OBJECT-IDENTITY":    true,
+	"MODULE-IDENTITY":    true,
+	"NOTIFICATION-TYPE":  true,
+	"OBJECT-GROUP":       true,
+	"NOTIFICATION-GROUP": true,
+	"MODULE-COMPLIANCE":  true,
+	"AGENT-CAPABILITIES": true,
+}
+
+// definition is an OID assignment that has not been resolved yet.
+type definition struct {
+	module string
+	name   string
+	parent string
+	// arcs are the sub-identifiers appended to the parent OID.
+	arcs []string
+	// named are the intermediate nodes named in the assignment, e.g.
+	// org(3) in { iso org(3) dod(6) 1 }, by their index in arcs.
+	named map[int]string
+}
+
+// LoadMIBs loads the MIB files in paths. Directories are loaded
+// non-recursively.
+func LoadMIBs(paths []string) (*MIB, error) {
+	var defs []definition
+	for _, path := range paths {
+		files, err := mibFiles(path)
+		if err != nil {
+			return nil, err
+		}
+		for _, file := range files {
+			data, err := os.ReadFile(file)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read MIB file: %w", err)
+			}
+			defs = append(defs, parseMIB(string(data))...)
+		}
+	}
+
+	mib := &MIB{oids: map[string]string{}, names: map[string]string{}}
+	// The well-known nodes are only used to resolve parents, they are not
+	// used to name fields.
+	for name, oid := range wellKnown {
+		mib.oids[name] = oid
+	}
+
+	// Definitions can refer to parents defined later or in other files,
+	// resolve them until no more progress is made.
+	for len(defs) > 0 {
+		var pending []definition
+		for _, def := range defs {
+			parent, ok := mib.oids[def.parent]
+			if !ok {
+				pending = append(pending, def)
+				continue
+			}
+			mib.add(def, parent)
+		}
+		if len(pending) == len(defs) {
+			break
+		}
+		defs = pending
+	}
+
+	return mib, nil
+}
+
+func mibFiles(path string) ([]string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load MIBs: %w", err)
+	}
+	if !info.IsDir() {
+		return []string{path}, nil
+	}
+
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load MIBs: %w", err)
+	}
+	var files []string
+	for _, e := range entries {
+		if e.Type().IsRegular() && !strings.HasPrefix(e.Name(), ".") {
+			files = append(files, filepath.Join(path, e.Name()))
+		}
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+func (m *MIB) add(def definition, parent string) {
+	oid := parent
+	for i, arc := range def.arcs {
+		oid += "." + arc
+		if name, ok := def.named[i]; ok {
+			if _, exists := m.oids[name]; !exists {
+				m.oids[name] = oid
+			}
+			if _, exists := m.names[oid]; !exists {
+				m.names[oid] = name
+			}
+		}
+	}
+
+	m.oids[def.module+"::"+def.name] = oid
+	if _, exists := m.oids[def.name]; !exists {
+		m.oids[def.name] = oid
+	}
+	m.names[oid] = def.name
+}
+
+// Resolve returns the numeric OID of name. Name can be a numeric OID, an
+// object name, optionally qualified with its module, and followed by
+// numeric sub-identifiers, e.g. "IF-MIB::ifDescr.1".
+func (m *MIB) Resolve(name string) (string, error) {
+	name = strings.TrimPrefix(name, ".")
+	if isNumericOID(name) {
+		return name, nil
+	}
+	if m == nil {
+		return "", fmt.Errorf("cannot resolve %q without mib_paths", name)
+	}
+
+	key, suffix := name, ""
+	object := name
+	if i := strings.Index(name, "::"); i >= 0 {
+		object = name[i+2:]
+	}
+	if j := strings.Index(object, "."); j >= 0 {
+		suffix = object[j:]
+		key = strings.TrimSuffix(name, suffix)
+		if !isNumericOID(suffix[1:]) {
+			return "", fmt.Errorf("invalid OID %q", name)
+		}
+	}
+
+	oid, ok := m.oids[key]
+	if !ok {
+		return "", fmt.Errorf("unknown MIB object %q", key)
+	}
+	return oid + suffix, nil
+}
+
+// Name returns the name of the closest object defining oid and the remaining
+// sub-identifiers, e.g. "ifDescr" and "1" for 1.3.6.1.2.1.2.2.1.2.1.
+func (m *MIB) Name(oid string) (name, suffix string, ok bool) {
+	if m == nil {
+		return "", "", false
+	}
+	oid = strings.TrimPrefix(oid, ".")
+	for prefix := oid; prefix != ""; {
+		if name, ok := m.names[prefix]; ok {
+			return name, strings.TrimPrefix(oid[len(prefix):], "."), true
+		}
+		i := strings.LastIndex(prefix, ".")
+		if i < 0 {
+			break
+		}
+		prefix = prefix[:i]
+	}
+	return "", "", false
+}
+
+func isNumericOID(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, part := range strings.Split(s, ".") {
+		if _, err := strconv.ParseUint(part, 10, 32); err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// parseMIB returns the OID assignments of the modules in a MIB file.
+func parseMIB(data string) []definition {
+	tokens := tokenize(data)
+
+	var (
+		defs   []definition
+		module string
+	)
+	for i := 0; i < len(tokens); i++ {
+		tok := tokens[i]
+		switch {
+		case tok == "DEFINITIONS" && i > 0:
+			module = tokens[i-1]
+		case tok == "IMPORTS":
+			// Imported names are not definitions.
+			for i < len(tokens) && tokens[i] != ";" {
+				i++
+			}
+		case tok == "MACRO":
+			// Skip macro definitions, they contain ::= that are not assignments.
+			for i < len(tokens) && tokens[i] != "END" {
+				i++
+			}
+		case tok == "OBJECT" && i+2 < len(tokens) && tokens[i+1] == "IDENTIFIER" && tokens[i+2] == "::=" && i > 0:
+			def, next, err := parseAssignment(tokens, i+3)
+			if err == nil && isObjectName(tokens[i-1]) {
+				def.module, def.name = module, tokens[i-1]
+				defs = append(defs, def)
+			}
+			i = next - 1
+		case definitionKeywords[tok] && i > 0 && isObjectName(tokens[i-1]):
+			name := tokens[i-1]
+			j := i + 1
+			for j < len(tokens) && tokens[j] != "::=" {
+				j++
+			}
+			def, next, err := parseAssignment(tokens, j+1)
+			if err == nil {
+				def.module, def.name = module, name
+				defs = append(defs, def)
+			}
+			i = next - 1
+		}
+	}
+	return defs
+}
+
+// parseAssignment parses an OID value like { parent 1 } or
+// { iso org(3) dod(6) 1 } starting at tokens[i].
+func parseAssignment(tokens []string, i int) (definition, int, error) {
+	if i >= len(tokens) || tokens[i] != "{" {
+		return definition{}, i, errors.New("not an OID assignment")
+	}
+	i++
+
+	var def definition
+	for ; i < len(tokens) && tokens[i] != "}"; i++ {
+		tok := tokens[i]
+		// name(n) form.
+		if i+3 < len(tokens) && tokens[i+1] == "(" && tokens[i+3] == ")" {
+			if def.parent == "" && len(def.arcs) == 0 {
+				def.parent = tok
+				i += 3
+				continue
+			}
+			if def.named == nil {
+				def.named = map[int]string{}
+			}
+			def.named[len(def.arcs)] = tok
+			def.arcs = append(def.arcs, tokens[i+2])
+			i += 3
+			continue
+		}
+		if def.parent == "" && len(def.arcs) == 0 && !isNumericOID(tok) {
+			def.parent = tok
+			continue
+		}
+		if !isNumericOID(tok) {
+			return definition{}, i, fmt.Errorf("unexpected %q in OID assignment", tok)
+		}
+		def.arcs = append(def.arcs, tok)
+	}
+	if def.parent == "" || len(def.arcs) == 0 {
+		return definition{}, i + 1, errors.New("incomplete OID assignment")
+	}
+	return def, i + 1, nil
+}
+
+// isObjectName reports whether s is a valid object name. Object names start
+// with a lowercase letter, which excludes the keywords.
+func isObjectName(s string) bool {
+	if s == "" || !unicode.IsLower(rune(s[0])) {
+		return false
+	}
+	for _, r := range s {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '-' && r != '_' {
+			return false
+		}
+	}
+	return true
+}
+
+// tokenize splits a MIB into tokens, dropping comments and quoted strings.
+func tokenize(data string) []string {
+	var tokens []string
+	for i := 0; i < len(data); {
+		c := data[i]
+		switch {
+		case c == '-' && i+1 < len(data) && data[i+1] == '-':
+			// Comments end at the end of the line or at the next "--".
+			i += 2
+			for i < len(data) && data[i] != '\n' {
+				if data[i] == '-' && i+1 < len(data) && data[i+1] == '-' {
+					i += 2
+					break
+				}
+				i++
+			}
+		case c == '"':
+			i++
+			for i < len(data) && data[i] != '"' {
+				i++
+			}
+			i++
+		case c == ':' && strings.HasPrefix(data[i:], "::="):
+			tokens = append(tokens, "::=")
+			i += 3
+		case strings.ContainsRune("{}(),;|", rune(c)):
+			tokens = append(tokens, string(c))
+			i++
+		case unicode.IsSpace(rune(c)):
+			i++
+		default:
+			j := i
+			for j < len(data) && !unicode.IsSpace(rune(data[j])) && !strings.ContainsRune("{}(),;|\"", rune(data[j])) &&
+				!strings.HasPrefix(data[j:], "::=") && !strings.HasPrefix(data[j:], "--") {
+				j++
+			}
+			if j == i {
+				j++
+			}
+			tokens = append(tokens, data[i:j])
+			i = j
+		}
+	}
+	return tokens
+}
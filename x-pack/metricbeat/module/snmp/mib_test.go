@@ -0,0 +1,108 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package snmp
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadMIBs(t *testing.T) {
+	mib, err := LoadMIBs([]string{"testdata"})
+	require.NoError(t, err)
+
+	for name, want := range map[string]string{
+		"1.3.6.1.2.1.1.5.0":           "1.3.6.1.2.1.1.5.0",
+		".1.3.6.1.2.1.1.5.0":          "1.3.6.1.2.1.1.5.0",
+		"testMIB":                     "1.3.6.1.4.1.99999",
+		"testName.0":                  "1.3.6.1.4.1.99999.1.1.0",
+		"TEST-MIB::testPackets.0":     "1.3.6.1.4.1.99999.1.2.0",
+		"testEntry":                   "1.3.6.1.4.1.99999.1.3.1",
+		"TEST-MIB::testInBytes":       "1.3.6.1.4.1.99999.1.3.1.3",
+		"testInBytes.12":              "1.3.6.1.4.1.99999.1.3.1.3.12",
+		"testExtra":                   "1.3.6.1.4.1.99999.2",
+		"TEST-EXTRA-MIB::testExtra":   "1.3.6.1.4.1.99999.2",
+		"testExtraName.0":             "1.3.6.1.4.1.99999.2.1.0",
+		"enterprises":                 "1.3.6.1.4.1",
+		"TEST-MIB::testDescr.1.2.3.4": "1.3.6.1.4.1.99999.1.3.1.2.1.2.3.4",
+	} {
+		got, err := mib.Resolve(name)
+		if assert.NoError(t, err, name) {
+			assert.Equal(t, want, got, name)
+		}
+	}
+
+	for _, name := range []string{
+		"unknownObject",
+		"OTHER-MIB::testName",
+		"testName.x",
+	} {
+		_, err := mib.Resolve(name)
+		assert.Error(t, err, name)
+	}
+
+	name, suffix, ok := mib.Name("1.3.6.1.4.1.99999.1.3.1.2.7")
+	assert.True(t, ok)
+	assert.Equal(t, "testDescr", name)
+	assert.Equal(t, "7", suffix)
+
+	name, suffix, ok = mib.Name(".1.3.6.1.4.1.99999.1.2.0")
+	assert.True(t, ok)
+	assert.Equal(t, "testPackets", name)
+	assert.Equal(t, "0", suffix)
+
+	// Well-known nodes are not used to name OIDs.
+	_, _, ok = mib.Name("1.3.6.1.2.1.1.5.0")
+	assert.False(t, ok)
+}
+
+func TestResolveWithoutMIBs(t *testing.T) {
+	var mib *MIB
+
+	oid, err := mib.Resolve("1.3.6.1.2.1.1.5.0")
+	require.NoError(t, err)
+	assert.Equal(t, "1.3.6.1.2.1.1.5.0", oid)
+
+	_, err = mib.Resolve("SNMPv2-MIB::sysName.0")
+	assert.ErrorContains(t, err, "without mib_paths")
+}
+
+func TestResolveMappings(t *testing.T) {
+	mib, err := LoadMIBs([]string{"testdata/TEST-MIB.txt", "testdata/TEST-EXTRA-MIB.txt"})
+	require.NoError(t, err)
+
+	mappings, err := ResolveMappings(mib, []OIDConfig{
+		{OID: "testName.0"},
+		{OID: "testDescr.5"},
+		{OID: "1.3.6.1.4.1.99999.1.2.0", Field: "packets", Rate: true},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []Mapping{
+		{OIDConfig: OIDConfig{OID: "testName.0", Field: "testName"}, Numeric: "1.3.6.1.4.1.99999.1.1.0"},
+		{OIDConfig: OIDConfig{OID: "testDescr.5", Field: "testDescr_5"}, Numeric: "1.3.6.1.4.1.99999.1.3.1.2.5"},
+		{OIDConfig: OIDConfig{OID: "1.3.6.1.4.1.99999.1.2.0", Field: "packets", Rate: true}, Numeric: "1.3.6.1.4.1.99999.1.2.0"},
+	}, mappings)
+
+	_, err = ResolveMappings(nil, []OIDConfig{{OID: "1.3.6.1.2.1.1.5.0"}})
+	assert.ErrorContains(t, err, "field is required")
+}
+
+func TestParseMIBSkipsMacros(t *testing.T) {
+	defs := parseMIB(`SNMPv2-SMI DEFINITIONS ::= BEGIN
+OBJECT-TYPE MACRO ::=
+BEGIN
+    VALUE NOTATION ::= value (VALUE ObjectName)
+    example ::= { foo 1 }
+END
+internet OBJECT IDENTIFIER ::= { iso org(3) dod(6) 1 }
+END`)
+	require.Len(t, defs, 1)
+	assert.Equal(t, "internet", defs[0].name)
+	assert.Equal(t, "SNMPv2-SMI", defs[0].module)
+	assert.Equal(t, "iso", defs[0].parent)
+	assert.Equal(t, []string{"3", "6", "1"}, defs[0].arcs)
+}
@@ -0,0 +1,111 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package snmp
+
+import (
+	"math"
+	"sync"
+	"time"
+
+	"github.com/gosnmp/gosnmp"
+)
+
+type sample struct {
+	value     uint64
+	timestamp time.Time
+}
+
+// SysUpTimeOID is the OID of SNMPv2-MIB::sysUpTime.0, the time in hundredths
+// of a second since the agent started.
+const SysUpTimeOID = "1.3.6.1.2.1.1.3.0"
+
+// RateCalculator calculates the per second rate of change of numeric values
+// between fetches. Counter32 values that are lower than the previous sample
+// are considered to have wrapped once, unless the agent restarted; for other
+// types, Counter64 included, a decrease resets the rate.
+type RateCalculator struct {
+	mu      sync.Mutex
+	samples map[string]sample
+
+	uptime    uint32
+	hasUptime bool
+}
+
+// NewRateCalculator returns a new RateCalculator.
+func NewRateCalculator() *RateCalculator {
+	return &RateCalculator{samples: map[string]sample{}}
+}
+
+// Rate records the value of the key and returns its per second rate since the
+// previous sample. It returns false when there is no previous sample or the
+// rate cannot be calculated.
+func (c *RateCalculator) Rate(key string, t gosnmp.Asn1BER, value uint64, now time.Time) (float64, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	prev, ok := c.samples[key]
+	c.samples[key] = sample{value: value, timestamp: now}
+	if !ok {
+		return 0, false
+	}
+
+	elapsed := now.Sub(prev.timestamp).Seconds()
+	if elapsed <= 0 {
+		return 0, false
+	}
+
+	var delta uint64
+	switch {
+	case value >= prev.value:
+		delta = value - prev.value
+	case t == gosnmp.Counter32 && prev.value <= math.MaxUint32:
+		delta = math.MaxUint32 - prev.value + value + 1
+	default:
+		// Counter64 values don't wrap in practice, a decrease means that
+		// the agent or the device restarted.
+		return 0, false
+	}
+
+	return float64(delta) / elapsed, true
+}
+
+// SetUptime records the sysUpTime of the agent. When it is lower than the
+// previous one the agent restarted, and all the samples are forgotten so that
+// lower Counter32 values are not taken for wraps.
+func (c *RateCalculator) SetUptime(ticks uint32) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.hasUptime && ticks < c.uptime {
+		c.samples = map[string]sample{}
+	}
+	c.uptime, c.hasUptime = ticks, true
+}
+
+// UpdateUptime gets the sysUpTime of the agent and records it with SetUptime.
+// Agents that don't report it are ignored, restarts are then not detected.
+func (c *RateCalculator) UpdateUptime(client *gosnmp.GoSNMP) {
+	packet, err := client.Get([]string{"." + SysUpTimeOID})
+	if err != nil || packet.Error != gosnmp.NoError || len(packet.Variables) != 1 {
+		return
+	}
+	if pdu := packet.Variables[0]; pdu.Type == gosnmp.TimeTicks {
+		if ticks, ok := pdu.Value.(uint32); ok {
+			c.SetUptime(ticks)
+		}
+	}
+}
+
+// Expire removes the samples that were not updated since before.
+func (c *RateCalculator) Expire(before time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for k, s := range c.samples {
+		if s.timestamp.Before(before) {
+			delete(c.samples, k)
+		}
+	}
+}
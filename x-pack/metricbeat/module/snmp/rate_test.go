@@ -0,0 +1,78 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package snmp
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/gosnmp/gosnmp"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRate(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	for _, tc := range []struct {
+		name        string
+		typ         gosnmp.Asn1BER
+		first, then uint64
+		want        float64
+		ok          bool
+	}{
+		{name: "increase", typ: gosnmp.Counter32, first: 100, then: 1100, want: 100, ok: true},
+		{name: "unchanged", typ: gosnmp.Counter64, first: 100, then: 100, want: 0, ok: true},
+		{name: "counter32 wrap", typ: gosnmp.Counter32, first: math.MaxUint32 - 9, then: 90, want: 10, ok: true},
+		{name: "counter64 reset", typ: gosnmp.Counter64, first: math.MaxUint64 - 49, then: 50, ok: false},
+		{name: "gauge decrease", typ: gosnmp.Gauge32, first: 100, then: 50, ok: false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			c := NewRateCalculator()
+
+			_, ok := c.Rate("key", tc.typ, tc.first, start)
+			assert.False(t, ok, "first sample has no rate")
+
+			rate, ok := c.Rate("key", tc.typ, tc.then, start.Add(10*time.Second))
+			assert.Equal(t, tc.ok, ok)
+			assert.Equal(t, tc.want, rate)
+		})
+	}
+}
+
+func TestRateUptime(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	c := NewRateCalculator()
+
+	c.SetUptime(1000)
+	c.Rate("key", gosnmp.Counter32, 100, start)
+	c.SetUptime(2000)
+	rate, ok := c.Rate("key", gosnmp.Counter32, 200, start.Add(10*time.Second))
+	assert.True(t, ok)
+	assert.Equal(t, float64(10), rate)
+
+	// The agent restarted, the lower value is not a wrap.
+	c.SetUptime(500)
+	_, ok = c.Rate("key", gosnmp.Counter32, 50, start.Add(20*time.Second))
+	assert.False(t, ok)
+	rate, ok = c.Rate("key", gosnmp.Counter32, 150, start.Add(30*time.Second))
+	assert.True(t, ok)
+	assert.Equal(t, float64(10), rate)
+}
+
+func TestRateExpire(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	c := NewRateCalculator()
+
+	c.Rate("a", gosnmp.Counter32, 1, start)
+	c.Rate("b", gosnmp.Counter32, 1, start)
+	c.Rate("a", gosnmp.Counter32, 2, start.Add(time.Second))
+	c.Expire(start.Add(time.Second))
+
+	_, ok := c.Rate("a", gosnmp.Counter32, 3, start.Add(2*time.Second))
+	assert.True(t, ok)
+	_, ok = c.Rate("b", gosnmp.Counter32, 3, start.Add(2*time.Second))
+	assert.False(t, ok, "expired sample")
+}
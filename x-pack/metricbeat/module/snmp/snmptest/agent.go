@@ -0,0 +1,223 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+// Package snmptest provides an in-process SNMP v1/v2c agent simulator that can
+// be used to test the snmp metricsets end-to-end through the gosnmp client.
+package snmptest
+
+import (
+	"errors"
+	"net"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/gosnmp/gosnmp"
+
+	"github.com/elastic/beats/v7/x-pack/metricbeat/module/snmp"
+)
+
+// Community is the community accepted by the agent.
+const Community = "snmptest"
+
+// Agent is a simulated SNMP agent. It answers GET, GETNEXT and GETBULK
+// requests from the variables set on it and counts the requests it receives.
+// Requests with a wrong community are dropped, like real agents do.
+type Agent struct {
+	t    testing.TB
+	conn net.PacketConn
+
+	mu       sync.Mutex
+	oids     []string // Sorted in OID order.
+	values   map[string]gosnmp.SnmpPDU
+	requests map[gosnmp.PDUType]int
+}
+
+// NewAgent starts a new agent listening on a random local UDP port. The agent
+// is stopped when the test finishes.
+func NewAgent(t testing.TB) *Agent {
+	t.Helper()
+
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+
+	a := &Agent{
+		t:        t,
+		conn:     conn,
+		values:   map[string]gosnmp.SnmpPDU{},
+		requests: map[gosnmp.PDUType]int{},
+	}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		a.serve()
+	}()
+	t.Cleanup(func() {
+		conn.Close()
+		<-done
+	})
+
+	return a
+}
+
+// Set sets the value of the variable oid. Value must be of the Go type used by
+// gosnmp to encode typ, e.g. int for Integer, uint32 for Counter32 and
+// uint64 for Counter64.
+func (a *Agent) Set(oid string, typ gosnmp.Asn1BER, value interface{}) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	oid = strings.TrimPrefix(oid, ".")
+	if _, ok := a.values[oid]; !ok {
+		i := sort.Search(len(a.oids), func(i int) bool { return snmp.CompareOIDs(a.oids[i], oid) >= 0 })
+		a.oids = append(a.oids, "")
+		copy(a.oids[i+1:], a.oids[i:])
+		a.oids[i] = oid
+	}
+	a.values[oid] = gosnmp.SnmpPDU{Name: "." + oid, Type: typ, Value: value}
+}
+
+// Host returns the address of the agent as a metricbeat host.
+func (a *Agent) Host() string {
+	return "udp://" + a.conn.LocalAddr().String()
+}
+
+// Requests returns the number of requests of type pduType received so far.
+func (a *Agent) Requests(pduType gosnmp.PDUType) int {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	return a.requests[pduType]
+}
+
+// Config returns a snmp module configuration pointing to the agent.
+func (a *Agent) Config(metricsets ...string) map[string]interface{} {
+	return map[string]interface{}{
+		"module":     "snmp",
+		"metricsets": metricsets,
+		"hosts":      []string{a.Host()},
+		"version":    "2c",
+		"community":  Community,
+		"timeout":    "1s",
+		"retries":    0,
+	}
+}
+
+func (a *Agent) serve() {
+	buf := make([]byte, 65535)
+	for {
+		n, addr, err := a.conn.ReadFrom(buf)
+		if err != nil {
+			if !errors.Is(err, net.ErrClosed) {
+				a.t.Logf("snmptest: read failed: %v", err)
+			}
+			return
+		}
+
+		decoder := &gosnmp.GoSNMP{}
+		req, err := decoder.SnmpDecodePacket(buf[:n])
+		if err != nil {
+			a.t.Logf("snmptest: failed to decode request: %v", err)
+			continue
+		}
+		if req.Version == gosnmp.Version3 || req.Community != Community {
+			continue
+		}
+
+		resp, err := a.handle(req).MarshalMsg()
+		if err != nil {
+			a.t.Logf("snmptest: failed to encode response: %v", err)
+			continue
+		}
+		if _, err := a.conn.WriteTo(resp, addr); err != nil {
+			a.t.Logf("snmptest: write failed: %v", err)
+		}
+	}
+}
+
+func (a *Agent) handle(req *gosnmp.SnmpPacket) *gosnmp.SnmpPacket {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.requests[req.PDUType]++
+
+	resp := &gosnmp.SnmpPacket{
+		Version:   req.Version,
+		Community: req.Community,
+		PDUType:   gosnmp.GetResponse,
+		RequestID: req.RequestID,
+	}
+
+	switch req.PDUType {
+	case gosnmp.GetRequest:
+		for i, v := range req.Variables {
+			pdu, ok := a.values[strings.TrimPrefix(v.Name, ".")]
+			if !ok {
+				if req.Version == gosnmp.Version1 {
+					return noSuchName(resp, req, i)
+				}
+				pdu = gosnmp.SnmpPDU{Name: v.Name, Type: gosnmp.NoSuchObject}
+			}
+			resp.Variables = append(resp.Variables, pdu)
+		}
+	case gosnmp.GetNextRequest:
+		for i, v := range req.Variables {
+			pdu, ok := a.next(v.Name)
+			if !ok && req.Version == gosnmp.Version1 {
+				return noSuchName(resp, req, i)
+			}
+			resp.Variables = append(resp.Variables, pdu)
+		}
+	case gosnmp.GetBulkRequest:
+		nonRepeaters := int(req.NonRepeaters)
+		if nonRepeaters > len(req.Variables) {
+			nonRepeaters = len(req.Variables)
+		}
+		for _, v := range req.Variables[:nonRepeaters] {
+			pdu, _ := a.next(v.Name)
+			resp.Variables = append(resp.Variables, pdu)
+		}
+		repeaters := req.Variables[nonRepeaters:]
+		names := make([]string, len(repeaters))
+		for i, v := range repeaters {
+			names[i] = v.Name
+		}
+		for r := 0; r < int(req.MaxRepetitions) && len(names) > 0; r++ {
+			end := true
+			for i, name := range names {
+				pdu, ok := a.next(name)
+				resp.Variables = append(resp.Variables, pdu)
+				names[i] = pdu.Name
+				end = end && !ok
+			}
+			if end {
+				break
+			}
+		}
+	default:
+		resp.Error = gosnmp.GenErr
+	}
+
+	return resp
+}
+
+// next returns the variable following name, or endOfMibView.
+func (a *Agent) next(name string) (gosnmp.SnmpPDU, bool) {
+	oid := strings.TrimPrefix(name, ".")
+	i := sort.Search(len(a.oids), func(i int) bool { return snmp.CompareOIDs(a.oids[i], oid) > 0 })
+	if i == len(a.oids) {
+		return gosnmp.SnmpPDU{Name: name, Type: gosnmp.EndOfMibView}, false
+	}
+	return a.values[a.oids[i]], true
+}
+
+func noSuchName(resp, req *gosnmp.SnmpPacket, index int) *gosnmp.SnmpPacket {
+	resp.Error = gosnmp.NoSuchName
+	resp.ErrorIndex = uint8(index + 1)
+	resp.Variables = req.Variables
+	return resp
+}
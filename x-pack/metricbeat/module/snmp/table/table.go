@@ -0,0 +1,255 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package table
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gosnmp/gosnmp"
+
+	"github.com/elastic/beats/v7/libbeat/common/cfgwarn"
+	"github.com/elastic/beats/v7/metricbeat/mb"
+	"github.com/elastic/beats/v7/x-pack/metricbeat/module/snmp"
+	"github.com/elastic/elastic-agent-libs/mapstr"
+)
+
+func init() {
+	mb.Registry.MustAddMetricSet(snmp.ModuleName, "table", New,
+		mb.WithHostParser(snmp.HostParser),
+	)
+}
+
+type tableConfig struct {
+	// Name of the table, used as the name of the group of fields of its
+	// columns.
+	Name string `config:"name" validate:"required"`
+	// OID of the table entry, walked entirely when no columns are given.
+	OID string `config:"oid"`
+	// Columns are the columns of the table to collect.
+	Columns []snmp.OIDConfig `config:"columns"`
+}
+
+func (c *tableConfig) Validate() error {
+	switch {
+	case c.Name == "name" || c.Name == "index":
+		return fmt.Errorf("table name %q is reserved", c.Name)
+	case strings.Contains(c.Name, "."):
+		return fmt.Errorf("table name %q cannot contain dots", c.Name)
+	case c.OID == "" && len(c.Columns) == 0:
+		return fmt.Errorf("table %s requires an oid or columns", c.Name)
+	}
+	return nil
+}
+
+type config struct {
+	// WalkMode is "walk" to use GETNEXT requests or "bulkwalk" to use
+	// GETBULK requests. It defaults to bulkwalk, or walk for SNMP version 1.
+	WalkMode string        `config:"walk_mode"`
+	Tables   []tableConfig `config:"tables" validate:"required"`
+}
+
+type table struct {
+	name string
+	// oid is the numeric OID of the table entry when no columns are
+	// configured.
+	oid     string
+	columns []snmp.Mapping
+}
+
+// MetricSet walks SNMP tables and reports one event per table row.
+type MetricSet struct {
+	mb.BaseMetricSet
+	client *gosnmp.GoSNMP
+	mib    *snmp.MIB
+	walk   func(rootOid string) ([]gosnmp.SnmpPDU, error)
+	tables []table
+	rates  *snmp.RateCalculator
+	// hasRates is set when rates are enabled, sysUpTime is then fetched to
+	// detect agent restarts.
+	hasRates bool
+}
+
+// New creates a new instance of the MetricSet.
+func New(base mb.BaseMetricSet) (mb.MetricSet, error) {
+	cfgwarn.Beta("The snmp table metricset is beta.")
+
+	moduleConfig, err := snmp.NewConfig(base.Module().UnpackConfig)
+	if err != nil {
+		return nil, err
+	}
+	var config config
+	if err := base.Module().UnpackConfig(&config); err != nil {
+		return nil, err
+	}
+
+	switch config.WalkMode {
+	case "":
+		config.WalkMode = "bulkwalk"
+		if moduleConfig.Version == "1" {
+			config.WalkMode = "walk"
+		}
+	case "walk":
+	case "bulkwalk":
+		if moduleConfig.Version == "1" {
+			return nil, errors.New("walk_mode bulkwalk requires SNMP version 2c or 3")
+		}
+	default:
+		return nil, fmt.Errorf("unsupported walk_mode %q, must be walk or bulkwalk", config.WalkMode)
+	}
+
+	var mib *snmp.MIB
+	if len(moduleConfig.MIBPaths) > 0 {
+		mib, err = snmp.LoadMIBs(moduleConfig.MIBPaths)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	tables := make([]table, 0, len(config.Tables))
+	hasRates := false
+	for _, c := range config.Tables {
+		t := table{name: c.Name}
+		if len(c.Columns) > 0 {
+			t.columns, err = snmp.ResolveMappings(mib, c.Columns)
+		} else {
+			t.oid, err = mib.Resolve(c.OID)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("table %s: %w", c.Name, err)
+		}
+		hasRates = hasRates || snmp.HasRates(t.columns)
+		tables = append(tables, t)
+	}
+
+	client, err := snmp.NewClient(base, moduleConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	m := &MetricSet{
+		BaseMetricSet: base,
+		client:        client,
+		mib:           mib,
+		tables:        tables,
+		rates:         snmp.NewRateCalculator(),
+		hasRates:      hasRates,
+	}
+	m.walk = client.BulkWalkAll
+	if config.WalkMode == "walk" {
+		m.walk = client.WalkAll
+	}
+	return m, nil
+}
+
+// Fetch walks the configured tables and reports one event per row.
+func (m *MetricSet) Fetch(r mb.ReporterV2) error {
+	now := time.Now()
+	if m.hasRates {
+		m.rates.UpdateUptime(m.client)
+	}
+
+	var errs []error
+	for _, t := range m.tables {
+		rows, err := m.fetchTable(t, now)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to walk table %s: %w", t.name, err))
+			continue
+		}
+
+		indexes := make([]string, 0, len(rows))
+		for index := range rows {
+			indexes = append(indexes, index)
+		}
+		sort.Slice(indexes, func(i, j int) bool {
+			return snmp.CompareOIDs(indexes[i], indexes[j]) < 0
+		})
+		for _, index := range indexes {
+			if !r.Event(mb.Event{MetricSetFields: mapstr.M{
+				"name":  t.name,
+				"index": index,
+				t.name:  rows[index],
+			}}) {
+				return nil
+			}
+		}
+	}
+
+	// Forget the rows that no longer exist, only when all the tables were
+	// walked so a failed walk does not reset their rates.
+	if len(errs) == 0 {
+		m.rates.Expire(now)
+	}
+	return errors.Join(errs...)
+}
+
+// fetchTable returns the fields of the rows of the table by row index.
+func (m *MetricSet) fetchTable(t table, now time.Time) (map[string]mapstr.M, error) {
+	rows := map[string]mapstr.M{}
+	row := func(index string) mapstr.M {
+		fields, ok := rows[index]
+		if !ok {
+			fields = mapstr.M{}
+			rows[index] = fields
+		}
+		return fields
+	}
+
+	if t.oid == "" {
+		for _, column := range t.columns {
+			pdus, err := m.walk("." + column.Numeric)
+			if err != nil {
+				return nil, err
+			}
+			for _, pdu := range pdus {
+				index, ok := childOf(pdu.Name, column.Numeric)
+				if !ok {
+					continue
+				}
+				key := t.name + "/" + column.Numeric + "." + index
+				column.Put(row(index), pdu, m.rates, key, now)
+			}
+		}
+		return rows, nil
+	}
+
+	pdus, err := m.walk("." + t.oid)
+	if err != nil {
+		return nil, err
+	}
+	for _, pdu := range pdus {
+		rest, ok := childOf(pdu.Name, t.oid)
+		if !ok {
+			continue
+		}
+		// The first sub-identifier after the entry is the column number,
+		// the remaining ones are the row index.
+		column, index, ok := strings.Cut(rest, ".")
+		if !ok {
+			continue
+		}
+		field := "column_" + column
+		if name, suffix, ok := m.mib.Name(t.oid + "." + column); ok && suffix == "" {
+			field = name
+		}
+		mapping := snmp.Mapping{OIDConfig: snmp.OIDConfig{Field: field}}
+		mapping.Put(row(index), pdu, m.rates, t.name+"/"+t.oid+"."+rest, now)
+	}
+	return rows, nil
+}
+
+// childOf returns the sub-identifiers of oid under parent.
+func childOf(oid, parent string) (string, bool) {
+	rest, ok := strings.CutPrefix(strings.TrimPrefix(oid, "."), parent+".")
+	return rest, ok && rest != ""
+}
+
+// Close closes the connection to the agent.
+func (m *MetricSet) Close() error {
+	return m.client.Conn.Close()
+}
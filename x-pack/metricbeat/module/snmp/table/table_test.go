@@ -0,0 +1,222 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package table
+
+import (
+	"testing"
+
+	"github.com/gosnmp/gosnmp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/elastic/beats/v7/metricbeat/mb"
+	mbtest "github.com/elastic/beats/v7/metricbeat/mb/testing"
+	"github.com/elastic/beats/v7/x-pack/metricbeat/module/snmp/snmptest"
+	conf "github.com/elastic/elastic-agent-libs/config"
+	"github.com/elastic/elastic-agent-libs/logp/logptest"
+	"github.com/elastic/elastic-agent-libs/mapstr"
+)
+
+const (
+	ifDescr       = "1.3.6.1.2.1.2.2.1.2"
+	ifPhysAddress = "1.3.6.1.2.1.2.2.1.6"
+	ifInOctets    = "1.3.6.1.2.1.2.2.1.10"
+)
+
+func newAgent(t *testing.T) *snmptest.Agent {
+	agent := snmptest.NewAgent(t)
+	for index, descr := range map[string]string{"1": "lo", "2": "eth0", "10": "eth1"} {
+		agent.Set(ifDescr+"."+index, gosnmp.OctetString, descr)
+		agent.Set(ifPhysAddress+"."+index, gosnmp.OctetString, []byte{0x00, 0x1b, 0x21, 0x3c, 0x4d, 0x5e})
+		agent.Set(ifInOctets+"."+index, gosnmp.Counter32, uint32(1000))
+	}
+	// Not part of the table.
+	agent.Set("1.3.6.1.2.1.4.1.0", gosnmp.Integer, 1)
+	return agent
+}
+
+func getConfig(agent *snmptest.Agent) map[string]interface{} {
+	config := agent.Config("table")
+	config["tables"] = []map[string]interface{}{
+		{
+			"name": "interfaces",
+			"columns": []map[string]interface{}{
+				{"oid": ifDescr, "field": "name"},
+				{"oid": ifPhysAddress, "field": "mac", "conversion": "hwaddr"},
+				{"oid": ifInOctets, "field": "in.bytes", "rate": true},
+			},
+		},
+	}
+	return config
+}
+
+func TestFetch(t *testing.T) {
+	for _, tc := range []struct {
+		version  string
+		walkMode string
+		bulk     bool
+	}{
+		{version: "1", bulk: false},
+		{version: "2c", bulk: true},
+		{version: "2c", walkMode: "walk", bulk: false},
+	} {
+		t.Run("v"+tc.version+" "+tc.walkMode, func(t *testing.T) {
+			agent := newAgent(t)
+
+			config := getConfig(agent)
+			config["version"] = tc.version
+			if tc.walkMode != "" {
+				config["walk_mode"] = tc.walkMode
+			}
+
+			f := mbtest.NewReportingMetricSetV2Error(t, config)
+			events, errs := mbtest.ReportingFetchV2Error(f)
+			require.Empty(t, errs)
+			require.Len(t, events, 3)
+
+			assert.Equal(t, mapstr.M{
+				"name":  "interfaces",
+				"index": "2",
+				"interfaces": mapstr.M{
+					"name": "eth0",
+					"mac":  "00:1b:21:3c:4d:5e",
+					"in":   mapstr.M{"bytes": uint64(1000)},
+				},
+			}, events[1].MetricSetFields)
+
+			// Rows are reported in index order.
+			var indexes []interface{}
+			for _, e := range events {
+				indexes = append(indexes, e.MetricSetFields["index"])
+			}
+			assert.Equal(t, []interface{}{"1", "2", "10"}, indexes)
+
+			if tc.bulk {
+				assert.NotZero(t, agent.Requests(gosnmp.GetBulkRequest))
+				assert.Zero(t, agent.Requests(gosnmp.GetNextRequest))
+			} else {
+				assert.Zero(t, agent.Requests(gosnmp.GetBulkRequest))
+				assert.NotZero(t, agent.Requests(gosnmp.GetNextRequest))
+			}
+		})
+	}
+}
+
+func TestFetchRates(t *testing.T) {
+	agent := newAgent(t)
+
+	f := mbtest.NewReportingMetricSetV2Error(t, getConfig(agent))
+	_, errs := mbtest.ReportingFetchV2Error(f)
+	require.Empty(t, errs)
+
+	agent.Set(ifInOctets+".1", gosnmp.Counter32, uint32(2000))
+	// Wraps.
+	agent.Set(ifInOctets+".2", gosnmp.Counter32, uint32(10))
+	// New row.
+	agent.Set(ifDescr+".11", gosnmp.OctetString, "eth2")
+	agent.Set(ifInOctets+".11", gosnmp.Counter32, uint32(10))
+
+	events, errs := mbtest.ReportingFetchV2Error(f)
+	require.Empty(t, errs)
+	require.Len(t, events, 4)
+
+	for _, e := range events {
+		index := e.MetricSetFields["index"]
+		rate, err := e.MetricSetFields.GetValue("interfaces.in.bytes_per_sec")
+		switch index {
+		case "1", "2":
+			require.NoError(t, err, index)
+			assert.Greater(t, rate, float64(0), index)
+		case "10":
+			require.NoError(t, err, index)
+			assert.Equal(t, float64(0), rate)
+		case "11":
+			assert.ErrorIs(t, err, mapstr.ErrKeyNotFound, "new rows have no rate")
+		}
+	}
+}
+
+func TestFetchWithMIBs(t *testing.T) {
+	agent := snmptest.NewAgent(t)
+	entry := "1.3.6.1.4.1.99999.1.3.1"
+	agent.Set(entry+".1.5", gosnmp.Integer, 5)
+	agent.Set(entry+".2.5", gosnmp.OctetString, "port 5")
+	agent.Set(entry+".3.5", gosnmp.Counter32, uint32(42))
+	agent.Set(entry+".4.5", gosnmp.Integer, 1)
+
+	config := agent.Config("table")
+	config["mib_paths"] = []string{"../testdata"}
+	config["tables"] = []map[string]interface{}{
+		{"name": "ports", "oid": "TEST-MIB::testEntry"},
+		{"name": "descriptions", "columns": []map[string]interface{}{{"oid": "testDescr"}}},
+	}
+
+	f := mbtest.NewReportingMetricSetV2Error(t, config)
+	events, errs := mbtest.ReportingFetchV2Error(f)
+	require.Empty(t, errs)
+	require.Len(t, events, 2)
+
+	assert.Equal(t, mapstr.M{
+		"name":  "ports",
+		"index": "5",
+		"ports": mapstr.M{
+			"testIndex":   int64(5),
+			"testDescr":   "port 5",
+			"testInBytes": uint64(42),
+			// Columns missing in the MIB are named by number.
+			"column_4": int64(1),
+		},
+	}, events[0].MetricSetFields)
+	assert.Equal(t, mapstr.M{
+		"name":         "descriptions",
+		"index":        "5",
+		"descriptions": mapstr.M{"testDescr": "port 5"},
+	}, events[1].MetricSetFields)
+}
+
+func TestConfigErrors(t *testing.T) {
+	agent := snmptest.NewAgent(t)
+
+	for name, tc := range map[string]struct {
+		settings map[string]interface{}
+		err      string
+	}{
+		"reserved name": {
+			settings: map[string]interface{}{"tables": []map[string]interface{}{{"name": "index", "oid": ifDescr}}},
+			err:      `table name "index" is reserved`,
+		},
+		"no oid": {
+			settings: map[string]interface{}{"tables": []map[string]interface{}{{"name": "interfaces"}}},
+			err:      "table interfaces requires an oid or columns",
+		},
+		"bulkwalk v1": {
+			settings: map[string]interface{}{"version": "1", "walk_mode": "bulkwalk", "tables": []map[string]interface{}{{"name": "interfaces", "oid": ifDescr}}},
+			err:      "walk_mode bulkwalk requires SNMP version 2c or 3",
+		},
+		"name without MIBs": {
+			settings: map[string]interface{}{"tables": []map[string]interface{}{{"name": "interfaces", "oid": "IF-MIB::ifEntry"}}},
+			err:      "without mib_paths",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			config := agent.Config("table")
+			for k, v := range tc.settings {
+				config[k] = v
+			}
+			c, err := conf.NewConfigFrom(config)
+			require.NoError(t, err)
+			_, _, err = mb.NewModule(c, mb.Registry, logptest.NewTestingLogger(t, ""))
+			assert.ErrorContains(t, err, tc.err)
+		})
+	}
+}
+
+func TestData(t *testing.T) {
+	agent := newAgent(t)
+
+	f := mbtest.NewReportingMetricSetV2Error(t, getConfig(agent))
+	err := mbtest.WriteEventsReporterV2Error(f, t, "")
+	require.NoError(t, err)
+}
@@ -0,0 +1,69 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package snmp
+
+import (
+	"bytes"
+	"encoding/hex"
+	"net"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/gosnmp/gosnmp"
+)
+
+// ConvertValue converts the value of a variable binding to the value reported
+// in events. It returns false for the variables that carry no value, like
+// noSuchObject, noSuchInstance or endOfMibView.
+func ConvertValue(pdu gosnmp.SnmpPDU, conversion string) (interface{}, bool) {
+	switch pdu.Type {
+	case gosnmp.Integer:
+		return gosnmp.ToBigInt(pdu.Value).Int64(), true
+	case gosnmp.Counter32, gosnmp.Gauge32, gosnmp.TimeTicks, gosnmp.Uinteger32, gosnmp.Counter64:
+		return gosnmp.ToBigInt(pdu.Value).Uint64(), true
+	case gosnmp.OpaqueFloat:
+		v, ok := pdu.Value.(float32)
+		return float64(v), ok
+	case gosnmp.OpaqueDouble:
+		v, ok := pdu.Value.(float64)
+		return v, ok
+	case gosnmp.IPAddress:
+		v, ok := pdu.Value.(string)
+		return v, ok && v != ""
+	case gosnmp.ObjectIdentifier:
+		v, ok := pdu.Value.(string)
+		return strings.TrimPrefix(v, "."), ok
+	case gosnmp.OctetString, gosnmp.BitString, gosnmp.Opaque:
+		b, ok := pdu.Value.([]byte)
+		if !ok {
+			return nil, false
+		}
+		return convertBytes(b, conversion), true
+	default:
+		return nil, false
+	}
+}
+
+func convertBytes(b []byte, conversion string) string {
+	switch conversion {
+	case "hwaddr":
+		return net.HardwareAddr(b).String()
+	case "hex":
+		return hex.EncodeToString(b)
+	case "string":
+		return string(bytes.TrimRight(b, "\x00"))
+	}
+
+	// Octet strings are used both for text and binary data like MAC
+	// addresses, only report them as text when they are printable.
+	s := bytes.TrimRight(b, "\x00")
+	if utf8.Valid(s) && strings.IndexFunc(string(s), func(r rune) bool {
+		return !unicode.IsPrint(r) && !unicode.IsSpace(r)
+	}) < 0 {
+		return string(s)
+	}
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,43 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package snmp
+
+import (
+	"testing"
+
+	"github.com/gosnmp/gosnmp"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConvertValue(t *testing.T) {
+	for _, tc := range []struct {
+		name       string
+		pdu        gosnmp.SnmpPDU
+		conversion string
+		want       interface{}
+		ok         bool
+	}{
+		{name: "integer", pdu: gosnmp.SnmpPDU{Type: gosnmp.Integer, Value: -5}, want: int64(-5), ok: true},
+		{name: "counter32", pdu: gosnmp.SnmpPDU{Type: gosnmp.Counter32, Value: uint(7)}, want: uint64(7), ok: true},
+		{name: "timeticks", pdu: gosnmp.SnmpPDU{Type: gosnmp.TimeTicks, Value: uint32(100)}, want: uint64(100), ok: true},
+		{name: "counter64", pdu: gosnmp.SnmpPDU{Type: gosnmp.Counter64, Value: uint64(1 << 40)}, want: uint64(1 << 40), ok: true},
+		{name: "text", pdu: gosnmp.SnmpPDU{Type: gosnmp.OctetString, Value: []byte("eth0\x00")}, want: "eth0", ok: true},
+		{name: "binary", pdu: gosnmp.SnmpPDU{Type: gosnmp.OctetString, Value: []byte{0x00, 0x1b, 0x21, 0x3c, 0x4d, 0x5e}}, want: "001b213c4d5e", ok: true},
+		{name: "hwaddr", pdu: gosnmp.SnmpPDU{Type: gosnmp.OctetString, Value: []byte{0x00, 0x1b, 0x21, 0x3c, 0x4d, 0x5e}}, conversion: "hwaddr", want: "00:1b:21:3c:4d:5e", ok: true},
+		{name: "hex", pdu: gosnmp.SnmpPDU{Type: gosnmp.OctetString, Value: []byte("ab")}, conversion: "hex", want: "6162", ok: true},
+		{name: "oid", pdu: gosnmp.SnmpPDU{Type: gosnmp.ObjectIdentifier, Value: ".1.3.6.1.4.1.99999"}, want: "1.3.6.1.4.1.99999", ok: true},
+		{name: "ip", pdu: gosnmp.SnmpPDU{Type: gosnmp.IPAddress, Value: "10.0.0.1"}, want: "10.0.0.1", ok: true},
+		{name: "no such instance", pdu: gosnmp.SnmpPDU{Type: gosnmp.NoSuchInstance}, ok: false},
+		{name: "end of mib", pdu: gosnmp.SnmpPDU{Type: gosnmp.EndOfMibView}, ok: false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			got, ok := ConvertValue(tc.pdu, tc.conversion)
+			assert.Equal(t, tc.ok, ok)
+			if tc.ok {
+				assert.Equal(t, tc.want, got)
+			}
+		})
+	}
+}
@@ -37,6 +37,12 @@ func ParseDSN(mod mb.Module, host string) (mb.HostData, error) {
 		return mb.HostData{}, fmt.Errorf("error parsing config file: %w", err)
 	}
 
+	return ParseConnectionDSN(config, host)
+}
+
+// ParseConnectionDSN builds the connection URI for host using the driver
+// specific parser selected by config.Driver.
+func ParseConnectionDSN(config ConnectionDetails, host string) (mb.HostData, error) {
 	if config.Driver == "oracle" {
 		return oracleParseDSN(config, host)
 	}
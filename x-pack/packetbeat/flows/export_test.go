@@ -0,0 +1,154 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+//go:build !integration
+
+// Package flows tests the flows exported by packetbeat with the NetFlow
+// decoder of filebeat.
+package flows
+
+import (
+	"bytes"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/elastic/beats/v7/libbeat/beat"
+	"github.com/elastic/beats/v7/libbeat/common"
+	"github.com/elastic/beats/v7/packetbeat/config"
+	"github.com/elastic/beats/v7/packetbeat/flows/export"
+	"github.com/elastic/beats/v7/x-pack/filebeat/input/netflow/decoder"
+	"github.com/elastic/beats/v7/x-pack/filebeat/input/netflow/decoder/fields"
+	"github.com/elastic/beats/v7/x-pack/filebeat/input/netflow/decoder/record"
+	"github.com/elastic/elastic-agent-libs/logp/logptest"
+	"github.com/elastic/elastic-agent-libs/mapstr"
+)
+
+// enterpriseID is the private enterprise number reserved for documentation.
+const enterpriseID = 32473
+
+var enterpriseFields = fields.FieldDict{
+	fields.Key{EnterpriseID: enterpriseID, FieldID: 1}: &fields.Field{Name: "communityId", Decoder: fields.String},
+	fields.Key{EnterpriseID: enterpriseID, FieldID: 2}: &fields.Field{Name: "sourceProcessId", Decoder: fields.Unsigned32},
+	fields.Key{EnterpriseID: enterpriseID, FieldID: 3}: &fields.Field{Name: "sourceProcessName", Decoder: fields.String},
+	fields.Key{EnterpriseID: enterpriseID, FieldID: 4}: &fields.Field{Name: "sourceProcessExecutable", Decoder: fields.String},
+	fields.Key{EnterpriseID: enterpriseID, FieldID: 5}: &fields.Field{Name: "destinationProcessId", Decoder: fields.Unsigned32},
+	fields.Key{EnterpriseID: enterpriseID, FieldID: 6}: &fields.Field{Name: "destinationProcessName", Decoder: fields.String},
+	fields.Key{EnterpriseID: enterpriseID, FieldID: 7}: &fields.Field{Name: "destinationProcessExecutable", Decoder: fields.String},
+}
+
+var start = time.UnixMilli(1542292881250).UTC()
+
+func flowEvents() []beat.Event {
+	return []beat.Event{
+		{Fields: mapstr.M{
+			"event": mapstr.M{"start": common.Time(start), "end": common.Time(start.Add(3 * time.Second))},
+			"flow":  mapstr.M{"id": common.NetString("flow-1"), "final": true, "vlan": uint64(171)},
+			"network": mapstr.M{
+				"transport":    "tcp",
+				"community_id": "1:t9T66/2c66NQyftAEsr4aMZv4Hc=",
+			},
+			"source": mapstr.M{
+				"ip": "203.0.113.3", "port": uint16(38901), "mac": "01-02-03-04-05-06",
+				"bytes": uint64(10), "packets": uint64(1),
+				"process": mapstr.M{"pid": 1234, "name": "curl", "executable": "/usr/bin/curl"},
+			},
+			"destination": mapstr.M{
+				"ip": "198.51.100.2", "port": uint16(80), "mac": "06-05-04-03-02-01",
+				"bytes": uint64(460), "packets": uint64(2),
+			},
+		}},
+		{Fields: mapstr.M{
+			"event":       mapstr.M{"start": common.Time(start), "end": common.Time(start.Add(time.Second))},
+			"flow":        mapstr.M{"id": common.NetString("flow-2"), "final": false},
+			"network":     mapstr.M{"transport": "udp"},
+			"source":      mapstr.M{"ip": "2001:db8::1", "port": uint16(5353), "bytes": uint64(60), "packets": uint64(1)},
+			"destination": mapstr.M{"ip": "2001:db8::2", "port": uint16(53)},
+		}},
+	}
+}
+
+// exportRecords exports the flow events and returns the flow records
+// decoded from the messages received by the collector.
+func exportRecords(t *testing.T, cfg config.FlowsExport, events []beat.Event) []record.Record {
+	t.Helper()
+	collector, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	require.NoError(t, err)
+	defer collector.Close()
+
+	cfg.Hosts = []string{collector.LocalAddr().String()}
+	exporter, err := export.New(&cfg, false)
+	require.NoError(t, err)
+	defer exporter.Close()
+	exporter.Export(events)
+
+	dec, err := decoder.NewDecoder(decoder.NewConfig(logptest.NewTestingLogger(t, "")).
+		WithProtocols("v9", "ipfix").
+		WithCustomFields(enterpriseFields))
+	require.NoError(t, err)
+
+	var records []record.Record
+	buf := make([]byte, 65536)
+	for len(records) < len(events) {
+		require.NoError(t, collector.SetReadDeadline(time.Now().Add(5*time.Second)))
+		n, addr, err := collector.ReadFromUDP(buf)
+		require.NoError(t, err)
+		recs, err := dec.Read(bytes.NewBuffer(buf[:n]), addr)
+		require.NoError(t, err)
+		records = append(records, recs...)
+	}
+	return records
+}
+
+func TestExportIPFIX(t *testing.T) {
+	records := exportRecords(t, config.FlowsExport{EnterpriseID: enterpriseID}, flowEvents())
+	require.Len(t, records, 2)
+
+	ipv4 := records[0].Fields
+	assert.Equal(t, net.IP{203, 0, 113, 3}, ipv4["sourceIPv4Address"])
+	assert.Equal(t, net.IP{198, 51, 100, 2}, ipv4["destinationIPv4Address"])
+	assert.Equal(t, uint64(38901), ipv4["sourceTransportPort"])
+	assert.Equal(t, uint64(80), ipv4["destinationTransportPort"])
+	assert.Equal(t, uint64(6), ipv4["protocolIdentifier"])
+	assert.Equal(t, uint64(171), ipv4["vlanId"])
+	assert.Equal(t, net.HardwareAddr{1, 2, 3, 4, 5, 6}, ipv4["sourceMacAddress"])
+	assert.Equal(t, start, ipv4["flowStartMilliseconds"])
+	assert.Equal(t, start.Add(3*time.Second), ipv4["flowEndMilliseconds"])
+	assert.Equal(t, uint64(470), ipv4["octetDeltaCount"])
+	assert.Equal(t, uint64(3), ipv4["packetDeltaCount"])
+	assert.Equal(t, uint64(10), ipv4["initiatorOctets"])
+	assert.Equal(t, uint64(460), ipv4["responderOctets"])
+	assert.Equal(t, uint64(1), ipv4["initiatorPackets"])
+	assert.Equal(t, uint64(2), ipv4["responderPackets"])
+	assert.Equal(t, uint64(1), ipv4["flowEndReason"])
+	assert.Equal(t, "1:t9T66/2c66NQyftAEsr4aMZv4Hc=", ipv4["communityId"])
+	assert.Equal(t, uint64(1234), ipv4["sourceProcessId"])
+	assert.Equal(t, "curl", ipv4["sourceProcessName"])
+	assert.Equal(t, "/usr/bin/curl", ipv4["sourceProcessExecutable"])
+	assert.Equal(t, uint64(0), ipv4["destinationProcessId"])
+
+	ipv6 := records[1].Fields
+	assert.Equal(t, net.ParseIP("2001:db8::1"), ipv6["sourceIPv6Address"])
+	assert.Equal(t, net.ParseIP("2001:db8::2"), ipv6["destinationIPv6Address"])
+	assert.Equal(t, uint64(17), ipv6["protocolIdentifier"])
+	assert.Equal(t, uint64(2), ipv6["flowEndReason"])
+	assert.Equal(t, "", ipv6["communityId"])
+}
+
+func TestExportNetflow9(t *testing.T) {
+	records := exportRecords(t, config.FlowsExport{Protocol: "netflow9", EnterpriseID: enterpriseID}, flowEvents())
+	require.Len(t, records, 2)
+
+	ipv4 := records[0].Fields
+	assert.Equal(t, net.IP{203, 0, 113, 3}, ipv4["sourceIPv4Address"])
+	assert.Equal(t, uint64(38901), ipv4["sourceTransportPort"])
+	assert.Equal(t, uint64(10), ipv4["initiatorOctets"])
+	assert.Equal(t, uint64(460), ipv4["responderOctets"])
+	assert.NotContains(t, ipv4, "communityId")
+
+	assert.Equal(t, net.ParseIP("2001:db8::1"), records[1].Fields["sourceIPv6Address"])
+}